@@ -0,0 +1,36 @@
+#!/bin/bash
+# Starts the example, lets the collector scrape the test server and checks
+# that all 10 published records arrived with the expected fields.
+# Usage: ./verify.sh [scrape-seconds]
+#
+# Set KEEP_RUNNING=1 to leave the containers running afterwards.
+
+set -euo pipefail
+
+SCRAPE_SECONDS="${1:-30}"
+cd "$(dirname "$0")"
+
+cleanup() {
+    if [ "${KEEP_RUNNING:-0}" != "1" ]; then
+        docker compose down --volumes --remove-orphans >/dev/null 2>&1 || true
+    fi
+}
+trap cleanup EXIT
+
+rm -rf output
+mkdir -p output
+
+echo "=== Building and starting test server and collector ==="
+docker compose up --build --detach
+
+echo "Letting the collector scrape for ${SCRAPE_SECONDS}s..."
+sleep "$SCRAPE_SECONDS"
+docker compose logs otelcol | tail -n 20
+
+# Stopping the collector flushes the file exporter
+docker compose stop otelcol
+
+docker run --rm \
+    -v "$(pwd)/output:/output:ro" \
+    -v "$(pwd)/../../testserver:/testserver:ro" \
+    alpine sh -c "apk add --no-cache -q jq bash && bash /testserver/validate.sh /output/logs.json /testserver/expected/records.json"
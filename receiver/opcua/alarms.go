@@ -0,0 +1,191 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// conditionField is a field of Alarm & Condition events selected after
+// logEventFields
+type conditionField struct {
+	// typeID is the event type that defines the field
+	typeID uint32
+
+	// path is the "/" separated browse path of the field. Empty selects the
+	// NodeId of the condition itself.
+	path string
+
+	// attribute is the log attribute the value is put in
+	attribute string
+}
+
+// conditionFields are the condition state fields of alarm events, in the
+// order of the event field lists after logEventFields
+var conditionFields = []conditionField{
+	{typeID: id.BaseEventType, path: "EventType", attribute: "opcua.event_type"},
+	{typeID: id.ConditionType, path: "", attribute: "opcua.condition.id"},
+	{typeID: id.ConditionType, path: "ConditionName", attribute: "opcua.condition.name"},
+	{typeID: id.ConditionType, path: "Retain", attribute: "opcua.condition.retain"},
+	{typeID: id.ConditionType, path: "EnabledState/Id", attribute: "opcua.condition.enabled"},
+	{typeID: id.AcknowledgeableConditionType, path: "AckedState/Id", attribute: "opcua.condition.acked"},
+	{typeID: id.AcknowledgeableConditionType, path: "ConfirmedState/Id", attribute: "opcua.condition.confirmed"},
+	{typeID: id.AlarmConditionType, path: "ActiveState/Id", attribute: "opcua.condition.active"},
+}
+
+// alarmSubscriber is implemented by clients that can receive Alarm &
+// Condition events
+type alarmSubscriber interface {
+	// subscribeAlarms subscribes to the condition events of the notifiers
+	// and requests the state of the retained conditions. It calls
+	// onSubscribed and deliver like eventSubscriber.subscribeEvents.
+	subscribeAlarms(ctx context.Context, cfg SubscriptionConfig, notifiers []string, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error
+}
+
+// alarmEvents subscribes to alarms in place of LogObject events
+type alarmEvents struct {
+	subscriber alarmSubscriber
+	notifiers  []string
+}
+
+func (a alarmEvents) subscribeEvents(ctx context.Context, cfg SubscriptionConfig, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	return a.subscriber.subscribeAlarms(ctx, cfg, a.notifiers, onSubscribed, deliver)
+}
+
+// alarmEventFilter selects the log record fields and the condition state of
+// the events of AcknowledgeableConditionType and its subtypes, which include
+// every AlarmConditionType
+func alarmEventFilter() *ua.EventFilter {
+	filter := logEventFilter()
+	for _, field := range conditionFields {
+		clause := &ua.SimpleAttributeOperand{
+			TypeDefinitionID: ua.NewNumericNodeID(0, field.typeID),
+			AttributeID:      ua.AttributeIDValue,
+		}
+		if field.path == "" {
+			clause.AttributeID = ua.AttributeIDNodeID
+		} else {
+			for _, name := range strings.Split(field.path, "/") {
+				clause.BrowsePath = append(clause.BrowsePath, &ua.QualifiedName{Name: name})
+			}
+		}
+		filter.SelectClauses = append(filter.SelectClauses, clause)
+	}
+	filter.WhereClause = &ua.ContentFilter{Elements: []*ua.ContentFilterElement{{
+		FilterOperator: ua.FilterOperatorOfType,
+		FilterOperands: []*ua.ExtensionObject{ua.NewExtensionObject(&ua.LiteralOperand{
+			Value: ua.MustVariant(ua.NewNumericNodeID(0, id.AcknowledgeableConditionType)),
+		})},
+	}}}
+	return filter
+}
+
+// subscribeAlarms subscribes to the condition events of the notifier nodes,
+// usually the Server object, which reports the events of all conditions
+func (c *opcuaClient) subscribeAlarms(ctx context.Context, cfg SubscriptionConfig, notifiers []string, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	session, err := c.session()
+	if err != nil {
+		return err
+	}
+	nodeIDs := make([]*ua.NodeID, len(notifiers))
+	for i, notifier := range notifiers {
+		nodeID, err := resolveNodeID(notifier, session.Namespaces())
+		if err != nil {
+			return newDiscoveryError(notifier, fmt.Errorf("invalid alarm notifier: %w", err))
+		}
+		nodeIDs[i] = nodeID
+	}
+
+	return c.monitorEvents(ctx, cfg, eventMonitor{
+		kind:      "alarm",
+		notifiers: nodeIDs,
+		filter:    alarmEventFilter(),
+		records:   alarmRecords,
+		monitored: c.refreshConditions,
+	}, onSubscribed, deliver)
+}
+
+// refreshConditions calls ConditionRefresh, which makes the server send an
+// event for every retained condition, so a new subscription starts with the
+// alarms that are active or unacknowledged. Servers without the method only
+// report state changes from now on.
+func (c *opcuaClient) refreshConditions(ctx context.Context, session *opcua.Client, sub *opcua.Subscription) error {
+	result, err := session.Call(ctx, &ua.CallMethodRequest{
+		ObjectID:       ua.NewNumericNodeID(0, id.ConditionType),
+		MethodID:       ua.NewNumericNodeID(0, id.ConditionType_ConditionRefresh),
+		InputArguments: []*ua.Variant{ua.MustVariant(sub.SubscriptionID)},
+	})
+	if err != nil {
+		if isConnectionError(err) {
+			return newConnectionError(c.config.Endpoint, fmt.Errorf("ConditionRefresh failed: %w", err))
+		}
+		c.logger.Warn("ConditionRefresh failed, reporting condition changes only", zap.Error(err))
+		return nil
+	}
+	if result.StatusCode != ua.StatusOK {
+		c.logger.Warn("ConditionRefresh failed, reporting condition changes only", zap.Error(result.StatusCode))
+	}
+	return nil
+}
+
+// alarmRecords converts the condition events of a notification to log
+// records with the condition state as attributes. The client handle of an
+// event is the index of its notifier. Events below minSeverity are dropped.
+func alarmRecords(list *ua.EventNotificationList, notifiers []*ua.NodeID, minSeverity uint16) []model.LogRecord {
+	records := getRecordSlice()
+	for _, event := range list.Events {
+		if event == nil || int(event.ClientHandle) >= len(notifiers) {
+			continue
+		}
+		record := eventRecord(event.EventFields)
+		if record.Severity < minSeverity {
+			putAttributeMap(record.Attributes)
+			continue
+		}
+		record.LogObjectID = notifiers[event.ClientHandle].String()
+		record.Attributes["opcua.condition.severity"] = int64(record.Severity)
+		for i, field := range conditionFields {
+			if value, ok := conditionValue(event.EventFields, len(logEventFields)+i); ok {
+				record.Attributes[field.attribute] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// conditionValue returns the value of an event field as an attribute value;
+// ok is false for fields the event does not have
+func conditionValue(fields []*ua.Variant, i int) (interface{}, bool) {
+	if i >= len(fields) || fields[i] == nil {
+		return nil, false
+	}
+	switch v := fields[i].Value().(type) {
+	case nil:
+		return nil, false
+	case bool, string:
+		return v, true
+	case *ua.NodeID:
+		if v == nil {
+			return nil, false
+		}
+		return v.String(), true
+	case *ua.LocalizedText:
+		if v == nil {
+			return nil, false
+		}
+		return v.Text, true
+	default:
+		return fmt.Sprintf("%v", v), true
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+func TestAlarmsConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			modify: func(*Config) {},
+		},
+		{
+			name:   "namespace URI",
+			modify: func(cfg *Config) { cfg.Alarms.Notifiers = []string{"nsu=http://vendor.com/UA/;s=Line1"} },
+		},
+		{
+			name:    "no notifiers",
+			modify:  func(cfg *Config) { cfg.Alarms.Notifiers = nil },
+			wantErr: "at least one notifier must be specified",
+		},
+		{
+			name:    "browse path",
+			modify:  func(cfg *Config) { cfg.Alarms.Notifiers = []string{"Objects/Server"} },
+			wantErr: "invalid notifiers[0]",
+		},
+		{
+			name:    "zero queue size",
+			modify:  func(cfg *Config) { cfg.Subscription.QueueSize = 0 },
+			wantErr: "queue_size must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Mode = collectionModeAlarms
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAlarmEventFilter(t *testing.T) {
+	filter := alarmEventFilter()
+	require.Len(t, filter.SelectClauses, len(logEventFields)+len(conditionFields))
+
+	// The log record fields come first, so eventRecord reads them as usual
+	for i, field := range logEventFields {
+		assert.Equal(t, field, filter.SelectClauses[i].BrowsePath[0].Name)
+	}
+
+	conditionID := filter.SelectClauses[len(logEventFields)+1]
+	assert.Equal(t, ua.NewNumericNodeID(0, id.ConditionType), conditionID.TypeDefinitionID)
+	assert.Empty(t, conditionID.BrowsePath)
+	assert.Equal(t, ua.AttributeIDNodeID, conditionID.AttributeID)
+
+	active := filter.SelectClauses[len(filter.SelectClauses)-1]
+	assert.Equal(t, ua.NewNumericNodeID(0, id.AlarmConditionType), active.TypeDefinitionID)
+	require.Len(t, active.BrowsePath, 2)
+	assert.Equal(t, "ActiveState", active.BrowsePath[0].Name)
+	assert.Equal(t, "Id", active.BrowsePath[1].Name)
+
+	require.NotNil(t, filter.WhereClause)
+	require.Len(t, filter.WhereClause.Elements, 1)
+	element := filter.WhereClause.Elements[0]
+	assert.Equal(t, ua.FilterOperatorOfType, element.FilterOperator)
+	require.Len(t, element.FilterOperands, 1)
+	operand, ok := element.FilterOperands[0].Value.(*ua.LiteralOperand)
+	require.True(t, ok)
+	assert.Equal(t, ua.NewNumericNodeID(0, id.AcknowledgeableConditionType), operand.Value.Value())
+}
+
+func TestAlarmRecords(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	notifiers := []*ua.NodeID{ua.NewNumericNodeID(0, id.Server)}
+	fields := func(severity uint16, active, acked bool) []*ua.Variant {
+		return []*ua.Variant{
+			ua.MustVariant(t0),
+			ua.MustVariant(severity),
+			ua.MustVariant(ua.NewLocalizedText("Temperature high")),
+			ua.MustVariant("Boiler1"),
+			ua.MustVariant(ua.NewStringNodeID(2, "Boiler1")),
+			ua.MustVariant(ua.NewNumericNodeID(0, id.ExclusiveLevelAlarmType)),
+			ua.MustVariant(ua.NewStringNodeID(2, "Boiler1.TempHigh")),
+			ua.MustVariant("TempHigh"),
+			ua.MustVariant(true),
+			ua.MustVariant(true),
+			ua.MustVariant(acked),
+			nil,
+			ua.MustVariant(active),
+		}
+	}
+
+	list := &ua.EventNotificationList{Events: []*ua.EventFieldList{
+		{ClientHandle: 0, EventFields: fields(700, true, false)},
+		{ClientHandle: 0, EventFields: fields(50, true, false)},
+		{ClientHandle: 1, EventFields: fields(700, true, false)},
+		{ClientHandle: 0, EventFields: fields(700, false, true)[:len(logEventFields)+4]},
+	}}
+
+	records := alarmRecords(list, notifiers, 101)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, t0, records[0].Timestamp)
+	assert.Equal(t, uint16(700), records[0].Severity)
+	assert.Equal(t, "Temperature high", records[0].Message)
+	assert.Equal(t, "Boiler1", records[0].SourceName)
+	assert.Equal(t, "i=2253", records[0].LogObjectID)
+	assert.Equal(t, map[string]interface{}{
+		"opcua.event_type":         "i=9482",
+		"opcua.condition.id":       "ns=2;s=Boiler1.TempHigh",
+		"opcua.condition.name":     "TempHigh",
+		"opcua.condition.retain":   true,
+		"opcua.condition.enabled":  true,
+		"opcua.condition.acked":    false,
+		"opcua.condition.active":   true,
+		"opcua.condition.severity": int64(700),
+	}, records[0].Attributes)
+
+	// Fields the event does not have are left out
+	assert.Equal(t, map[string]interface{}{
+		"opcua.event_type":         "i=9482",
+		"opcua.condition.id":       "ns=2;s=Boiler1.TempHigh",
+		"opcua.condition.name":     "TempHigh",
+		"opcua.condition.retain":   true,
+		"opcua.condition.severity": int64(700),
+	}, records[1].Attributes)
+}
+
+// alarmClient is a windowClient whose alarm subscription delivers scripted
+// notifications
+type alarmClient struct {
+	windowClient
+	notifications [][]model.LogRecord
+	notifiers     []string
+}
+
+func (c *alarmClient) subscribeAlarms(ctx context.Context, _ SubscriptionConfig, notifiers []string, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	c.notifiers = notifiers
+	if err := onSubscribed(ctx); err != nil {
+		return err
+	}
+	for _, records := range c.notifications {
+		deliver(records)
+	}
+	return nil
+}
+
+func TestReceiverAlarms(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Mode = collectionModeAlarms
+	client := &alarmClient{}
+	cfg.Client = client
+	r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	sink := new(consumertest.LogsSink)
+	r.nextLogs = sink
+	r.scraper.client = client
+	r.scraper.clock = newFakeClock(t0)
+
+	subscriber, ok := r.eventSubscriber()
+	require.True(t, ok)
+
+	// A refreshed condition older than the receiver is delivered as well
+	client.notifications = [][]model.LogRecord{{{
+		Timestamp:   t0.Add(-time.Hour),
+		Severity:    700,
+		Message:     "Temperature high",
+		LogObjectID: "i=2253",
+		Attributes:  map[string]interface{}{"opcua.condition.active": true},
+	}}}
+	var caughtUp time.Time
+	require.NoError(t, r.subscribe(ctx, subscriber, &caughtUp))
+
+	// Alarms are not caught up with GetRecords
+	assert.Empty(t, client.windows)
+	assert.Equal(t, []string{"i=2253"}, client.notifiers)
+	require.Equal(t, 1, sink.LogRecordCount())
+	record := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	active, ok := record.Attributes().Get("opcua.condition.active")
+	require.True(t, ok)
+	assert.True(t, active.Bool())
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// Attribute renames are rolled out in three steps so that downstream dashboards
+// can migrate gradually:
+//
+//	default:                                   old names only
+//	emitNewAttributeNames:                     old and new names
+//	emitNewAttributeNames + dropOldAttributeNames: new names only
+var (
+	emitNewAttributeNamesGate = featuregate.GlobalRegistry().MustRegister(
+		"receiver.opcua.emitNewAttributeNames",
+		featuregate.StageAlpha,
+		featuregate.WithRegisterDescription("When enabled, log attributes scheduled for renaming are also emitted under their new names."),
+		featuregate.WithRegisterFromVersion("v0.2.0"),
+	)
+	dropOldAttributeNamesGate = featuregate.GlobalRegistry().MustRegister(
+		"receiver.opcua.dropOldAttributeNames",
+		featuregate.StageAlpha,
+		featuregate.WithRegisterDescription("When enabled together with receiver.opcua.emitNewAttributeNames, the deprecated attribute names are no longer emitted."),
+		featuregate.WithRegisterFromVersion("v0.2.0"),
+	)
+)
+
+// attributeMigration describes a log attribute that is being renamed
+type attributeMigration struct {
+	oldName string
+	newName string
+}
+
+// attributeMigrations lists the pending attribute renames. Add an entry here when an
+// attribute name changes; remove it once the old name is no longer supported.
+var attributeMigrations []attributeMigration
+
+// attributeMigrator applies attributeMigrations according to the feature gates
+type attributeMigrator struct {
+	migrations   []attributeMigration
+	emitNewNames bool
+	dropOldNames bool
+}
+
+// newAttributeMigrator captures the current feature gate state
+func newAttributeMigrator(migrations []attributeMigration) attributeMigrator {
+	return attributeMigrator{
+		migrations:   migrations,
+		emitNewNames: emitNewAttributeNamesGate.IsEnabled(),
+		dropOldNames: dropOldAttributeNamesGate.IsEnabled(),
+	}
+}
+
+// apply copies renamed attributes to their new names and, when requested,
+// removes the old names
+func (m attributeMigrator) apply(attrs pcommon.Map) {
+	if !m.emitNewNames {
+		return
+	}
+
+	for _, migration := range m.migrations {
+		value, ok := attrs.Get(migration.oldName)
+		if !ok {
+			continue
+		}
+		value.CopyTo(attrs.PutEmpty(migration.newName))
+		if m.dropOldNames {
+			attrs.Remove(migration.oldName)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/featuregate"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// setFeatureGateForTest enables or disables a feature gate for the duration of a test
+func setFeatureGateForTest(t *testing.T, gate *featuregate.Gate, enabled bool) {
+	t.Helper()
+	original := gate.IsEnabled()
+	require.NoError(t, featuregate.GlobalRegistry().Set(gate.ID(), enabled))
+	t.Cleanup(func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(gate.ID(), original))
+	})
+}
+
+func TestAttributeMigration(t *testing.T) {
+	migrations := []attributeMigration{
+		{oldName: "opcua.source.name", newName: "opcua.log.source.name"},
+	}
+
+	tests := []struct {
+		name    string
+		emitNew bool
+		dropOld bool
+		wantOld bool
+		wantNew bool
+	}{
+		{name: "old names only (default)", wantOld: true},
+		{name: "both names", emitNew: true, wantOld: true, wantNew: true},
+		{name: "new names only", emitNew: true, dropOld: true, wantNew: true},
+		{name: "drop without emit keeps old names", dropOld: true, wantOld: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFeatureGateForTest(t, emitNewAttributeNamesGate, tt.emitNew)
+			setFeatureGateForTest(t, dropOldAttributeNamesGate, tt.dropOld)
+
+			transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+			transformer.migrator = newAttributeMigrator(migrations)
+
+			logs := transformer.TransformLogs([]testdata.OPCUALogRecord{
+				{Timestamp: time.Now(), Severity: 150, Message: "probe", SourceName: "Boiler"},
+			})
+			attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+
+			oldVal, ok := attrs.Get("opcua.source.name")
+			assert.Equal(t, tt.wantOld, ok)
+			if ok {
+				assert.Equal(t, "Boiler", oldVal.Str())
+			}
+
+			newVal, ok := attrs.Get("opcua.log.source.name")
+			assert.Equal(t, tt.wantNew, ok)
+			if ok {
+				assert.Equal(t, "Boiler", newVal.Str())
+			}
+		})
+	}
+}
+
+func TestAttributeMigrationMissingAttribute(t *testing.T) {
+	setFeatureGateForTest(t, emitNewAttributeNamesGate, true)
+
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer.migrator = newAttributeMigrator([]attributeMigration{
+		{oldName: "opcua.source.name", newName: "opcua.log.source.name"},
+	})
+
+	logs := transformer.TransformLogs([]testdata.OPCUALogRecord{
+		{Timestamp: time.Now(), Severity: 150, Message: "no source"},
+	})
+	attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+
+	_, ok := attrs.Get("opcua.log.source.name")
+	assert.False(t, ok, "absent attributes should not be created under the new name")
+}
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+)
+
+// userTokenType returns the user identity token type of the configured authentication
+func (c *opcuaClient) userTokenType() ua.UserTokenType {
+	return c.config.Auth.tokenType()
+}
+
+// tokenType returns the user identity token type of the auth type
+func (cfg AuthConfig) tokenType() ua.UserTokenType {
+	switch cfg.Type {
+	case "username_password":
+		return ua.UserTokenTypeUserName
+	case "certificate":
+		return ua.UserTokenTypeCertificate
+	default:
+		return ua.UserTokenTypeAnonymous
+	}
+}
+
+// offersUserToken reports whether the endpoint accepts user identity tokens of the given type
+func offersUserToken(ep *ua.EndpointDescription, tokenType ua.UserTokenType) bool {
+	for _, policy := range ep.UserIdentityTokens {
+		if policy.TokenType == tokenType {
+			return true
+		}
+	}
+	return false
+}
+
+// userIdentityOptions returns the options presenting the configured user
+// identity. Certificate authentication presents the application certificate,
+// from the certificate provider or from cert_file and key_file, as X.509 user
+// identity.
+func (c *opcuaClient) userIdentityOptions(ctx context.Context) ([]opcua.Option, error) {
+	switch c.userTokenType() {
+	case ua.UserTokenTypeUserName:
+		return []opcua.Option{opcua.AuthUsername(c.config.Auth.Username, string(c.config.Auth.Password))}, nil
+	case ua.UserTokenTypeCertificate:
+		var cert []byte
+		var key *rsa.PrivateKey
+		var err error
+		if c.certProvider != nil {
+			cert, key, err = c.certProvider.ApplicationCertificate(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get application certificate: %w", err)
+			}
+		} else if cert, key, err = c.config.TLS.applicationCertificate(); err != nil {
+			return nil, err
+		}
+		return []opcua.Option{
+			opcua.Certificate(cert),
+			opcua.PrivateKey(key),
+			opcua.AuthCertificate(cert),
+			opcua.AuthPrivateKey(key),
+		}, nil
+	default:
+		return []opcua.Option{opcua.AuthAnonymous()}, nil
+	}
+}
+
+// loadCertificateFiles loads a certificate and its RSA private key, each
+// either PEM or DER encoded
+func loadCertificateFiles(certFile, keyFile string) ([]byte, *rsa.PrivateKey, error) {
+	cert, err := readPEMOrDER(certFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+	if _, err := x509.ParseCertificate(cert); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate %s: %w", certFile, err)
+	}
+
+	der, err := readPEMOrDER(keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load private key: %w", err)
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return cert, key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key %s: %w", keyFile, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("private key %s is not an RSA key", keyFile)
+	}
+	return cert, key, nil
+}
+
+// readPEMOrDER returns the DER bytes of the first PEM block in a file, or the
+// file contents if it is not PEM encoded
+func readPEMOrDER(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by configuration
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		return block.Bytes, nil
+	}
+	return data, nil
+}
+
+// isAuthenticationError reports whether the server rejected the user identity
+// of a session, which retrying with the same configuration does not fix
+func isAuthenticationError(err error) bool {
+	var status ua.StatusCode
+	if !errors.As(err, &status) {
+		return false
+	}
+	switch status {
+	case ua.StatusBadUserAccessDenied,
+		ua.StatusBadIdentityTokenRejected,
+		ua.StatusBadIdentityTokenInvalid,
+		ua.StatusBadUserSignatureInvalid:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestMockServerAuthenticate(t *testing.T) {
+	trusted, _ := newTestCertificate(t, "operator", false, nil, nil)
+	stranger, _ := newTestCertificate(t, "stranger", false, nil, nil)
+
+	tests := []struct {
+		name      string
+		configure func(s *testdata.MockServer)
+		identity  testdata.Identity
+		expected  ua.StatusCode
+	}{
+		{name: "anonymous by default", expected: ua.StatusOK},
+		{
+			name:      "anonymous disabled",
+			configure: func(s *testdata.MockServer) { s.SetAnonymousAccess(false) },
+			expected:  ua.StatusBadIdentityTokenRejected,
+		},
+		{
+			name:     "no users configured",
+			identity: testdata.Identity{Username: "operator", Password: "secret"},
+			expected: ua.StatusBadIdentityTokenRejected,
+		},
+		{
+			name:      "valid user",
+			configure: func(s *testdata.MockServer) { s.AddUser("operator", "secret") },
+			identity:  testdata.Identity{Username: "operator", Password: "secret"},
+			expected:  ua.StatusOK,
+		},
+		{
+			name:      "wrong password",
+			configure: func(s *testdata.MockServer) { s.AddUser("operator", "secret") },
+			identity:  testdata.Identity{Username: "operator", Password: "guess"},
+			expected:  ua.StatusBadUserAccessDenied,
+		},
+		{
+			name:      "unknown user",
+			configure: func(s *testdata.MockServer) { s.AddUser("operator", "secret") },
+			identity:  testdata.Identity{Username: "intruder", Password: "secret"},
+			expected:  ua.StatusBadUserAccessDenied,
+		},
+		{
+			name:      "trusted certificate",
+			configure: func(s *testdata.MockServer) { s.TrustUserCertificate(trusted.Raw) },
+			identity:  testdata.Identity{Certificate: trusted.Raw},
+			expected:  ua.StatusOK,
+		},
+		{
+			name:      "untrusted certificate",
+			configure: func(s *testdata.MockServer) { s.TrustUserCertificate(trusted.Raw) },
+			identity:  testdata.Identity{Certificate: stranger.Raw},
+			expected:  ua.StatusBadIdentityTokenRejected,
+		},
+		{
+			name:     "malformed certificate",
+			identity: testdata.Identity{Certificate: []byte{0x01}},
+			expected: ua.StatusBadIdentityTokenInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			srv := testdata.NewMockServer("", zap.NewNop())
+			require.NoError(t, srv.Start(ctx))
+			if tt.configure != nil {
+				tt.configure(srv)
+			}
+
+			client := testdata.NewMockClient(srv, zap.NewNop())
+			client.SetIdentity(tt.identity)
+			err := client.Connect(ctx)
+			if tt.expected == ua.StatusOK {
+				require.NoError(t, err)
+				assert.True(t, client.IsConnected())
+			} else {
+				require.ErrorIs(t, err, tt.expected)
+				assert.True(t, isAuthenticationError(err))
+				assert.False(t, client.IsConnected())
+			}
+
+			attempts := srv.AuthAttempts()
+			require.Len(t, attempts, 1)
+			assert.Equal(t, tt.expected, attempts[0].Status)
+		})
+	}
+}
+
+// writeCertificateFiles writes a certificate and its key as PEM files for cert_file and key_file
+func writeCertificateFiles(t *testing.T, cert *x509.Certificate, key *rsa.PrivateKey) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return certFile, keyFile
+}
+
+func TestClientWireAuthentication(t *testing.T) {
+	ws := startWireServer(t, withSecureEndpoints(), withUserAuthentication())
+	ws.AddLogRecords(wireRecords(3))
+	ws.AddUser("operator", "s3cret")
+
+	trusted, trustedKey := newTestCertificate(t, "opcua-receiver", false, nil, nil)
+	stranger, strangerKey := newTestCertificate(t, "stranger", false, nil, nil)
+	ws.TrustUserCertificate(trusted.Raw)
+	certFile, keyFile := writeCertificateFiles(t, trusted, trustedKey)
+
+	tests := []struct {
+		name      string
+		configure func(cfg *Config, c *opcuaClient)
+		expected  ua.StatusCode
+		identity  testdata.Identity
+	}{
+		{
+			name:     "anonymous",
+			expected: ua.StatusOK,
+		},
+		{
+			name: "username and password",
+			configure: func(cfg *Config, _ *opcuaClient) {
+				cfg.Auth = AuthConfig{Type: "username_password", Username: "operator", Password: "s3cret"}
+			},
+			expected: ua.StatusOK,
+			identity: testdata.Identity{Username: "operator", Password: "s3cret"},
+		},
+		{
+			name: "wrong password",
+			configure: func(cfg *Config, _ *opcuaClient) {
+				cfg.Auth = AuthConfig{Type: "username_password", Username: "operator", Password: "guess"}
+			},
+			expected: ua.StatusBadUserAccessDenied,
+			identity: testdata.Identity{Username: "operator", Password: "guess"},
+		},
+		{
+			name: "trusted certificate from provider",
+			configure: func(cfg *Config, c *opcuaClient) {
+				cfg.Auth.Type = "certificate"
+				c.certProvider = &testCertProvider{cert: trusted.Raw, key: trustedKey, trusted: []*x509.Certificate{ws.cert}}
+			},
+			expected: ua.StatusOK,
+			identity: testdata.Identity{Certificate: trusted.Raw},
+		},
+		{
+			name: "trusted certificate from files",
+			configure: func(cfg *Config, c *opcuaClient) {
+				c.certProvider = nil
+				cfg.Auth.Type = "certificate"
+				cfg.TLS.CertFile = certFile
+				cfg.TLS.KeyFile = keyFile
+			},
+			expected: ua.StatusOK,
+			identity: testdata.Identity{Certificate: trusted.Raw},
+		},
+		{
+			name: "untrusted certificate",
+			configure: func(cfg *Config, c *opcuaClient) {
+				cfg.Auth.Type = "certificate"
+				c.certProvider = &testCertProvider{cert: stranger.Raw, key: strangerKey}
+			},
+			expected: ua.StatusBadIdentityTokenRejected,
+			identity: testdata.Identity{Certificate: stranger.Raw},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := ws.newWireConfig()
+			cfg.SecurityPolicy = "Basic256Sha256"
+			cfg.SecurityMode = "SignAndEncrypt"
+			c := newOPCUAClient(cfg, zap.NewNop())
+			c.certProvider = &testCertProvider{cert: trusted.Raw, key: trustedKey, trusted: []*x509.Certificate{ws.cert}}
+			if tt.configure != nil {
+				tt.configure(cfg, c)
+			}
+			attempts := len(ws.AuthAttempts())
+
+			err := c.Connect(ctx)
+			if tt.expected != ua.StatusOK {
+				require.ErrorIs(t, err, tt.expected)
+				assert.ErrorContains(t, err, "rejected "+cfg.Auth.Type+" authentication")
+				assert.True(t, isAuthenticationError(err))
+				assert.False(t, c.IsConnected())
+			} else {
+				require.NoError(t, err)
+				defer func() {
+					assert.NoError(t, c.Disconnect(ctx))
+				}()
+				records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+				require.NoError(t, err)
+				assert.Len(t, records, 3)
+			}
+
+			// The server saw the configured identity, with the password decrypted
+			got := ws.AuthAttempts()
+			require.Len(t, got, attempts+1)
+			assert.Equal(t, tt.identity, got[attempts].Identity)
+			assert.Equal(t, tt.expected, got[attempts].Status)
+		})
+	}
+}
+
+func TestClientWireAnonymousRejected(t *testing.T) {
+	ws := startWireServer(t)
+	ws.SetAnonymousAccess(false)
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	err := c.Connect(context.Background())
+	require.ErrorIs(t, err, ua.StatusBadIdentityTokenRejected)
+	assert.ErrorContains(t, err, "rejected anonymous authentication")
+}
+
+func TestClientWireUserTokenNotOffered(t *testing.T) {
+	// gopcua offers user name tokens on secured endpoints only
+	ws := startWireServer(t, withUserAuthentication())
+	ws.AddUser("operator", "s3cret")
+
+	cfg := ws.newWireConfig()
+	cfg.Auth = AuthConfig{Type: "username_password", Username: "operator", Password: "s3cret"}
+	c := newOPCUAClient(cfg, zap.NewNop())
+	err := c.Connect(context.Background())
+	require.ErrorContains(t, err, "does not accept")
+	assert.False(t, isAuthenticationError(err))
+	assert.Empty(t, ws.AuthAttempts(), "the client must not fall back to an anonymous session")
+}
+
+func TestIsAuthenticationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"access denied", ua.StatusBadUserAccessDenied, true},
+		{"wrapped token rejected", fmt.Errorf("activate: %w", ua.StatusBadIdentityTokenRejected), true},
+		{"token invalid", ua.StatusBadIdentityTokenInvalid, true},
+		{"secure channel closed", ua.StatusBadSecureChannelClosed, false},
+		{"plain error", errors.New("dial tcp: connection refused"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isAuthenticationError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,295 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+)
+
+var benchmarkSizes = []int{1000, 10000, 100000}
+
+// benchClient serves pre-encoded LogRecord ExtensionObjects and decodes them on
+// every GetRecords call, so the benchmark covers the codec as well as the transformer.
+type benchClient struct {
+	decoder *opcuaClient
+	objects []*ua.ExtensionObject
+}
+
+func (c *benchClient) Connect(_ context.Context) error { return nil }
+
+func (c *benchClient) Disconnect(_ context.Context) error { return nil }
+
+func (c *benchClient) IsConnected() bool { return true }
+
+func (c *benchClient) GetRecords(_ context.Context, _, _ time.Time, _ int) ([]testdata.OPCUALogRecord, error) {
+	return c.decoder.parseExtensionObjectArray(c.objects)
+}
+
+// benchmarkExtensionObjects returns n binary-encoded LogRecord ExtensionObjects with
+// all optional fields populated.
+func benchmarkExtensionObjects(b *testing.B, n int) []*ua.ExtensionObject {
+	b.Helper()
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	objects := make([]*ua.ExtensionObject, n)
+	for i := 0; i < n; i++ {
+		lr := &client.LogRecordExtObj{
+			Time:         base.Add(time.Duration(i) * time.Millisecond),
+			Severity:     uint16(1 + i%1000), //nolint:gosec
+			Message:      fmt.Sprintf("Benchmark log message %d", i),
+			SourceNode:   ua.NewNumericNodeID(1, uint32(100+i%5)), //nolint:gosec
+			SourceName:   "BenchmarkSource",
+			TraceIDBytes: fixedTraceIDBytes(),
+			SpanID:       uint64(i + 1), //nolint:gosec
+			AdditionalData: map[string]interface{}{
+				"component": "benchmark",
+				"index":     int32(i), //nolint:gosec
+			},
+		}
+		encoded, err := lr.Encode()
+		if err != nil {
+			b.Fatal(err)
+		}
+		objects[i] = &ua.ExtensionObject{
+			TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+			Value:  encoded,
+		}
+	}
+	return objects
+}
+
+// BenchmarkScrape drives the full scrape → decode → transform path.
+func BenchmarkScrape(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("records=%d", n), func(b *testing.B) {
+			config := createDefaultConfig().(*Config)
+			settings := componenttest.NewNopTelemetrySettings()
+			scr := &scraper{
+				config:      config,
+				settings:    settings,
+				telemetry:   newTestTelemetryBuilder(b, settings),
+				transformer: newRecordTransformer(config.Endpoint, config.Resource.ServiceName, ""),
+				client: &benchClient{
+					decoder: newTestClient(),
+					objects: benchmarkExtensionObjects(b, n),
+				},
+			}
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				logs, err := scr.scrape(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if logs.LogRecordCount() != n {
+					b.Fatalf("expected %d records, got %d", n, logs.LogRecordCount())
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkDecode measures the ExtensionObject codec on its own. The records
+// are released after every iteration like the scraper does.
+func BenchmarkDecode(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("records=%d", n), func(b *testing.B) {
+			c := newTestClient()
+			objects := benchmarkExtensionObjects(b, n)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				records, err := c.parseExtensionObjectArray(objects)
+				if err != nil {
+					b.Fatal(err)
+				}
+				releaseRecords(records)
+			}
+		})
+	}
+}
+
+// BenchmarkTransform measures the OPC UA → plog conversion on its own.
+func BenchmarkTransform(b *testing.B) {
+	for _, n := range benchmarkSizes {
+		b.Run(fmt.Sprintf("records=%d", n), func(b *testing.B) {
+			records, err := newTestClient().parseExtensionObjectArray(benchmarkExtensionObjects(b, n))
+			if err != nil {
+				b.Fatal(err)
+			}
+			transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				transformer.TransformLogs(records)
+			}
+		})
+	}
+}
+
+// benchmarkLogRecord returns a decoded record like those of benchmarkExtensionObjects
+func benchmarkLogRecord(tb testing.TB) testdata.OPCUALogRecord {
+	tb.Helper()
+	return testdata.OPCUALogRecord{
+		Timestamp:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:        300,
+		Message:         "Benchmark log message",
+		SourceName:      "BenchmarkSource",
+		SourceNamespace: 1,
+		SourceIDType:    "Numeric",
+		SourceID:        "100",
+		TraceID:         "0102030405060708090a0b0c0d0e0f10",
+		SpanID:          "0102030405060708",
+		TraceFlags:      1,
+		Attributes: map[string]interface{}{
+			"component": "benchmark",
+			"index":     int32(7),
+		},
+	}
+}
+
+// BenchmarkTransformLogRecord measures the conversion of a single record.
+func BenchmarkTransformLogRecord(b *testing.B) {
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	record := benchmarkLogRecord(b)
+	logRecords := plog.NewLogRecordSlice()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		transformer.transformLogRecord(record, logRecords.AppendEmpty())
+		if logRecords.Len() == 1000 {
+			logRecords = plog.NewLogRecordSlice()
+		}
+	}
+}
+
+// attributeBenchmarkValues covers the typed and the formatted putAttribute paths.
+var attributeBenchmarkValues = []struct {
+	name  string
+	value interface{}
+}{
+	{"string", "value"},
+	{"int", 42},
+	{"int64", int64(42)},
+	{"float64", 3.14},
+	{"bool", true},
+	{"int32", int32(42)},
+	{"bytes", []byte{0x01, 0x02, 0x03}},
+}
+
+// BenchmarkPutAttribute measures putAttribute per value type.
+func BenchmarkPutAttribute(b *testing.B) {
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	for _, tt := range attributeBenchmarkValues {
+		b.Run(tt.name, func(b *testing.B) {
+			attrs := pcommon.NewMap()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				transformer.putAttribute(attrs, "key", tt.value)
+			}
+		})
+	}
+}
+
+// BenchmarkSetTraceContext measures the hex decoding of trace and span IDs.
+func BenchmarkSetTraceContext(b *testing.B) {
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	logRecord := plog.NewLogRecord()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		transformer.setTraceContext(logRecord, "0102030405060708090a0b0c0d0e0f10", "0102030405060708", 1)
+	}
+}
+
+// Allocation ceilings of the transformer hot path. Lower them when an
+// optimization lands so it cannot silently regress.
+const (
+	// maxTransformAllocsPerRecord covers the record itself, its body, six
+	// attributes and their map growth, and the trace context
+	maxTransformAllocsPerRecord = 25
+	// maxSetTraceContextAllocs covers decoding the trace and span ID
+	maxSetTraceContextAllocs = 2
+	// maxFormattedAttributeAllocs is the overhead of formatting a value
+	// without a typed attribute on top of putting the string
+	maxFormattedAttributeAllocs = 2
+)
+
+func TestTransformerAllocations(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable with the race detector")
+	}
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+
+	t.Run("TransformLogs", func(t *testing.T) {
+		const n = 100
+		records := make([]testdata.OPCUALogRecord, n)
+		for i := range records {
+			records[i] = benchmarkLogRecord(t)
+		}
+		allocs := testing.AllocsPerRun(10, func() {
+			transformer.TransformLogs(records)
+		})
+		assert.LessOrEqual(t, allocs/n, float64(maxTransformAllocsPerRecord), "allocations per record")
+	})
+
+	t.Run("setTraceContext", func(t *testing.T) {
+		logRecord := plog.NewLogRecord()
+		allocs := testing.AllocsPerRun(100, func() {
+			transformer.setTraceContext(logRecord, "0102030405060708090a0b0c0d0e0f10", "0102030405060708", 1)
+		})
+		assert.LessOrEqual(t, allocs, float64(maxSetTraceContextAllocs))
+	})
+
+	// Typed values cost no more than putting them directly; the key exists
+	// after the first run, so only the value is replaced
+	direct := map[string]func(pcommon.Map){
+		"string":  func(m pcommon.Map) { m.PutStr("key", "value") },
+		"int":     func(m pcommon.Map) { m.PutInt("key", 42) },
+		"int64":   func(m pcommon.Map) { m.PutInt("key", 42) },
+		"float64": func(m pcommon.Map) { m.PutDouble("key", 3.14) },
+		"bool":    func(m pcommon.Map) { m.PutBool("key", true) },
+	}
+	for _, tt := range attributeBenchmarkValues {
+		t.Run("putAttribute/"+tt.name, func(t *testing.T) {
+			attrs := pcommon.NewMap()
+			allocs := testing.AllocsPerRun(100, func() {
+				transformer.putAttribute(attrs, "key", tt.value)
+			})
+
+			baseline := pcommon.NewMap()
+			put, typed := direct[tt.name]
+			if !typed {
+				put = func(m pcommon.Map) { m.PutStr("key", "formatted") }
+			}
+			expected := testing.AllocsPerRun(100, func() {
+				put(baseline)
+			})
+			if !typed {
+				expected += maxFormattedAttributeAllocs
+			}
+			assert.LessOrEqual(t, allocs, expected)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/recording"
+)
+
+// captureCall appends a GetRecords round trip to the capture file when
+// capture.directory is configured. The file is opened on the first call after
+// connecting. Capture failures are logged and never affect collection.
+func (c *opcuaClient) captureCall(req *ua.CallMethodRequest, result *ua.CallMethodResult, callErr error) {
+	if c.config.Capture.Directory == "" {
+		return
+	}
+
+	c.captureMu.Lock()
+	defer c.captureMu.Unlock()
+
+	if c.capture == nil {
+		w, err := recording.Create(c.config.Capture.Directory)
+		if err != nil {
+			c.logger.Warn("Failed to create GetRecords capture file", zap.Error(err))
+			return
+		}
+		c.capture = w
+		c.logger.Info("Capturing GetRecords calls", zap.String("path", w.Path()))
+	}
+
+	call, err := recording.NewCall(c.config.Endpoint, req, result, callErr)
+	if err == nil {
+		err = c.capture.Write(call)
+	}
+	if err != nil {
+		c.logger.Warn("Failed to capture GetRecords call", zap.Error(err))
+	}
+}
+
+// closeCapture closes the capture file, if one is open
+func (c *opcuaClient) closeCapture() {
+	c.captureMu.Lock()
+	defer c.captureMu.Unlock()
+
+	if c.capture == nil {
+		return
+	}
+	if err := c.capture.Close(); err != nil {
+		c.logger.Warn("Failed to close GetRecords capture file", zap.Error(err))
+	}
+	c.capture = nil
+}
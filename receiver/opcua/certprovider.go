@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"go.opentelemetry.io/collector/component"
+)
+
+// CertificateProvider is implemented by extensions that manage the OPC UA
+// application instance certificate and trust list on behalf of one or more
+// receivers, so they share one identity and one rotation workflow.
+//
+// Both methods are called on every (re)connect, so a provider can rotate
+// certificates without restarting the collector.
+type CertificateProvider interface {
+	component.Component
+
+	// ApplicationCertificate returns the DER-encoded application instance
+	// certificate and its RSA private key.
+	ApplicationCertificate(ctx context.Context) ([]byte, *rsa.PrivateKey, error)
+
+	// TrustedCertificates returns the server certificates or CAs trusted by the
+	// application. An empty trust list accepts any server certificate.
+	TrustedCertificates(ctx context.Context) ([]*x509.Certificate, error)
+}
+
+// getCertificateProvider looks up the certificate provider extension with the given ID
+func getCertificateProvider(host component.Host, id component.ID) (CertificateProvider, error) {
+	ext, ok := host.GetExtensions()[id]
+	if !ok {
+		return nil, fmt.Errorf("certificate provider extension %q not found", id)
+	}
+	provider, ok := ext.(CertificateProvider)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a certificate provider", id)
+	}
+	return provider, nil
+}
+
+// certificateOptions returns the client options for the provider's application certificate
+func certificateOptions(ctx context.Context, provider CertificateProvider) ([]opcua.Option, error) {
+	cert, key, err := provider.ApplicationCertificate(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application certificate: %w", err)
+	}
+	if len(cert) == 0 || key == nil {
+		return nil, errors.New("certificate provider returned no application certificate")
+	}
+	return []opcua.Option{
+		opcua.Certificate(cert),
+		opcua.PrivateKey(key),
+	}, nil
+}
+
+// verifyServerCertificate checks the DER-encoded server certificate against the
+// provider's trust list. The certificate is accepted if it is in the trust list
+// or chains up to a trusted CA, and only within its validity period.
+func verifyServerCertificate(ctx context.Context, provider CertificateProvider, serverCert []byte) error {
+	trusted, err := provider.TrustedCertificates(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get trust list: %w", err)
+	}
+	if len(trusted) == 0 {
+		_, err := parseServerCertificate(serverCert, time.Now())
+		return err
+	}
+	return verifyTrusted(serverCert, trusted)
+}
+
+// verifyTrusted checks the DER-encoded server certificate against a trust
+// list the same way
+func verifyTrusted(serverCert []byte, trusted []*x509.Certificate) error {
+	cert, err := parseServerCertificate(serverCert, time.Now())
+	if err != nil {
+		return err
+	}
+
+	roots := x509.NewCertPool()
+	for _, t := range trusted {
+		if t.Equal(cert) {
+			return nil
+		}
+		roots.AddCert(t)
+	}
+
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("server certificate is not trusted: %s: %w", describeCertificate(cert), err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+type testCertProvider struct {
+	component.StartFunc
+	component.ShutdownFunc
+	cert    []byte
+	key     *rsa.PrivateKey
+	trusted []*x509.Certificate
+	err     error
+}
+
+func (p *testCertProvider) ApplicationCertificate(context.Context) ([]byte, *rsa.PrivateKey, error) {
+	return p.cert, p.key, p.err
+}
+
+func (p *testCertProvider) TrustedCertificates(context.Context) ([]*x509.Certificate, error) {
+	return p.trusted, p.err
+}
+
+// newTestCertificate creates a certificate signed by parent, or a self-signed
+// certificate when parent is nil
+func newTestCertificate(t *testing.T, cn string, isCA bool, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// newExpiredCertificate creates a self-signed certificate that expired an
+// hour ago
+func newExpiredCertificate(t *testing.T, cn string) *x509.Certificate {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-48 * time.Hour),
+		NotAfter:     time.Now().Add(-time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert
+}
+
+func TestVerifyServerCertificate(t *testing.T) {
+	ca, caKey := newTestCertificate(t, "Plant CA", true, nil, nil)
+	signed, _ := newTestCertificate(t, "PLC-1", false, ca, caKey)
+	selfSigned, _ := newTestCertificate(t, "PLC-2", false, nil, nil)
+	stranger, _ := newTestCertificate(t, "Unknown", false, nil, nil)
+	expired := newExpiredCertificate(t, "PLC-3")
+
+	tests := []struct {
+		name       string
+		trusted    []*x509.Certificate
+		serverCert []byte
+		wantErr    bool
+	}{
+		{name: "empty trust list accepts any", trusted: nil, serverCert: stranger.Raw},
+		{name: "signed by trusted CA", trusted: []*x509.Certificate{ca}, serverCert: signed.Raw},
+		{name: "trusted self-signed", trusted: []*x509.Certificate{selfSigned}, serverCert: selfSigned.Raw},
+		{name: "untrusted", trusted: []*x509.Certificate{ca, selfSigned}, serverCert: stranger.Raw, wantErr: true},
+		{name: "missing server certificate", trusted: []*x509.Certificate{ca}, serverCert: nil, wantErr: true},
+		{name: "expired trusted certificate", trusted: []*x509.Certificate{expired}, serverCert: expired.Raw, wantErr: true},
+		{name: "expired with empty trust list", trusted: nil, serverCert: expired.Raw, wantErr: true},
+		{name: "malformed server certificate", trusted: []*x509.Certificate{ca}, serverCert: []byte{0x01}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &testCertProvider{trusted: tt.trusted}
+			err := verifyServerCertificate(context.Background(), provider, tt.serverCert)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCertificateOptions(t *testing.T) {
+	cert, key := newTestCertificate(t, "Collector", false, nil, nil)
+
+	opts, err := certificateOptions(context.Background(), &testCertProvider{cert: cert.Raw, key: key})
+	require.NoError(t, err)
+	assert.Len(t, opts, 2)
+
+	_, err = certificateOptions(context.Background(), &testCertProvider{})
+	assert.Error(t, err)
+
+	_, err = certificateOptions(context.Background(), &testCertProvider{err: errors.New("vault unavailable")})
+	assert.ErrorContains(t, err, "vault unavailable")
+}
+
+func TestGetCertificateProvider(t *testing.T) {
+	id := component.MustNewID("opcua_pki")
+
+	_, err := getCertificateProvider(componenttest.NewNopHost(), id)
+	assert.ErrorContains(t, err, "not found")
+
+	host := &extensionHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{id: newMemoryStorage()},
+	}
+	_, err = getCertificateProvider(host, id)
+	assert.ErrorContains(t, err, "not a certificate provider")
+
+	provider := &testCertProvider{}
+	host.extensions[id] = provider
+	got, err := getCertificateProvider(host, id)
+	require.NoError(t, err)
+	assert.Same(t, provider, got)
+}
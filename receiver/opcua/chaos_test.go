@@ -0,0 +1,333 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/server"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// chaosProxy forwards TCP connections to the wire server and can drop all of
+// them at once, as a failing network or a server restart does
+type chaosProxy struct {
+	listener net.Listener
+	upstream string
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	drops  int
+	closed bool
+}
+
+// newChaosProxy starts a proxy on a free local port forwarding to upstream
+func newChaosProxy(t *testing.T, upstream string) *chaosProxy {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	p := &chaosProxy{
+		listener: listener,
+		upstream: upstream,
+		conns:    make(map[net.Conn]struct{}),
+	}
+	go p.serve()
+	t.Cleanup(p.close)
+	return p
+}
+
+// port returns the port the proxy listens on
+func (p *chaosProxy) port() int {
+	return p.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (p *chaosProxy) serve() {
+	for {
+		client, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		server, err := net.Dial("tcp", p.upstream)
+		if err != nil {
+			_ = client.Close()
+			continue
+		}
+		if !p.track(client, server) {
+			return
+		}
+		go p.pipe(client, server)
+		go p.pipe(server, client)
+	}
+}
+
+// track registers a proxied connection pair. It returns false once the proxy is closed.
+func (p *chaosProxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+		return false
+	}
+	for _, conn := range conns {
+		p.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+// pipe copies from src to dst and closes both ends when either side fails
+func (p *chaosProxy) pipe(dst, src net.Conn) {
+	_, _ = io.Copy(dst, src)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range []net.Conn{dst, src} {
+		_ = conn.Close()
+		delete(p.conns, conn)
+	}
+}
+
+// dropConnections closes all proxied connections without a goodbye. New
+// connections are still accepted, so clients can reconnect.
+func (p *chaosProxy) dropConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for conn := range p.conns {
+		_ = conn.Close()
+		delete(p.conns, conn)
+	}
+	p.drops++
+}
+
+// dropCount returns how often the connections were dropped
+func (p *chaosProxy) dropCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.drops
+}
+
+func (p *chaosProxy) close() {
+	_ = p.listener.Close()
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.dropConnections()
+}
+
+// withChaosProxy routes clients of the wire server through a chaosProxy, so
+// FaultConnectionDrop drops their TCP connections
+func withChaosProxy() wireServerOption {
+	return func(t *testing.T, ws *wireServer) []server.Option {
+		_, port, err := net.SplitHostPort(ws.endpoint[len("opc.tcp://"):])
+		require.NoError(t, err)
+		ws.proxy = newChaosProxy(t, net.JoinHostPort("127.0.0.1", port))
+		ws.endpoint = "opc.tcp://127.0.0.1:" + strconv.Itoa(ws.proxy.port())
+		return nil
+	}
+}
+
+// chaosScrape scrapes until a scrape succeeds and returns the records of all
+// scrapes. Failed scrapes must not emit records.
+func chaosScrape(t *testing.T, scr *scraper, attempts int) (plog.Logs, int) {
+	t.Helper()
+	ctx := context.Background()
+	all := plog.NewLogs()
+	for failures := 0; failures < attempts; failures++ {
+		logs, err := scr.scrape(ctx)
+		if err != nil {
+			assert.Equal(t, 0, logs.LogRecordCount(), "failed scrape emitted records")
+			continue
+		}
+		logs.ResourceLogs().MoveAndAppendTo(all.ResourceLogs())
+		return all, failures
+	}
+	t.Fatalf("no successful scrape in %d attempts", attempts)
+	return all, attempts
+}
+
+// assertExactlyOnce checks that every wire record was emitted exactly once;
+// wireRecords gives each record a distinct timestamp
+func assertExactlyOnce(t *testing.T, logs plog.Logs, expected int) {
+	t.Helper()
+	seen := make(map[pcommon.Timestamp]int)
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				seen[lrs.At(k).Timestamp()]++
+			}
+		}
+	}
+
+	assert.Len(t, seen, expected, "records lost")
+	for ts, n := range seen {
+		assert.Equal(t, 1, n, "record %s emitted %d times", ts, n)
+	}
+}
+
+func TestChaosConnectionDropMidPagination(t *testing.T) {
+	tests := []struct {
+		name   string
+		faults []testdata.Fault
+	}{
+		{
+			name:   "between first and second page",
+			faults: []testdata.Fault{{Kind: testdata.FaultConnectionDrop, Call: 2}},
+		},
+		{
+			name:   "before the last page",
+			faults: []testdata.Fault{{Kind: testdata.FaultConnectionDrop, Call: 4}},
+		},
+		{
+			name: "again after reconnecting",
+			faults: []testdata.Fault{
+				{Kind: testdata.FaultConnectionDrop, Call: 2},
+				{Kind: testdata.FaultConnectionDrop, Call: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const recordCount = 10
+			ws := startWireServer(t, withChaosProxy())
+			ws.AddLogRecords(wireRecords(recordCount))
+			// Four pages of at most three records
+			ws.SetMaxPageSize(3)
+			for _, fault := range tt.faults {
+				ws.InjectFault(fault)
+			}
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.RequestTimeout = time.Second
+			scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+			require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+			defer func() {
+				require.NoError(t, scr.shutdown(ctx))
+			}()
+
+			logs, failures := chaosScrape(t, scr, 5)
+			assert.Equal(t, len(tt.faults), ws.proxy.dropCount())
+			// A scrape racing the reconnect may fail once more, but never emits records
+			assert.GreaterOrEqual(t, failures, len(tt.faults))
+
+			// Nothing is left over for the next scrape
+			next, err := scr.scrape(ctx)
+			require.NoError(t, err)
+			next.ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+
+			assertExactlyOnce(t, logs, recordCount)
+		})
+	}
+}
+
+func TestChaosConnectionDropAcrossLogObjects(t *testing.T) {
+	secondObjectID := ua.NewNumericNodeID(1, 2000)
+	secondMethodID := ua.NewNumericNodeID(1, 2001)
+
+	ws := startWireServer(t, withChaosProxy())
+	require.NoError(t, ws.AddLogObject(secondObjectID, secondMethodID))
+	records := wireRecords(12)
+	ws.AddLogRecords(records[:5])
+	require.NoError(t, ws.AddLogRecordsTo(secondObjectID, records[5:]...))
+	ws.SetMaxPageSize(2)
+	// Calls 1-3 read the first LogObject completely, call 5 is the second
+	// page of the second one
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultConnectionDrop, Call: 5, LogObject: secondObjectID})
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.RequestTimeout = time.Second
+	cfg.LogObjectPaths = []string{ws.logObjectID.String(), secondObjectID.String()}
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	logs, failures := chaosScrape(t, scr, 5)
+	assert.GreaterOrEqual(t, failures, 1)
+	assertExactlyOnce(t, logs, len(records))
+}
+
+func TestChaosContinuationPointRejectedMidRead(t *testing.T) {
+	rejected := func(call int) testdata.Fault {
+		return testdata.Fault{Kind: testdata.FaultStatus, Call: call, Status: ua.StatusBadContinuationPointInvalid}
+	}
+
+	tests := []struct {
+		name        string
+		faults      []testdata.Fault
+		expectedErr string
+	}{
+		{
+			name:   "third page rejected",
+			faults: []testdata.Fault{rejected(3)},
+		},
+		{
+			name:   "rejected after every restart",
+			faults: []testdata.Fault{rejected(2), rejected(4), rejected(6)},
+		},
+		{
+			name:        "restarts exhausted",
+			faults:      []testdata.Fault{rejected(2), rejected(4), rejected(6), rejected(8)},
+			expectedErr: "continuation point invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const recordCount = 10
+			ws := startWireServer(t)
+			ws.AddLogRecords(wireRecords(recordCount))
+			ws.SetMaxPageSize(3)
+			for _, fault := range tt.faults {
+				ws.InjectFault(fault)
+			}
+			ctx := context.Background()
+
+			c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				_ = c.Disconnect(ctx)
+			}()
+
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+			if tt.expectedErr != "" {
+				require.ErrorContains(t, err, tt.expectedErr)
+				assert.Empty(t, records)
+				return
+			}
+			require.NoError(t, err)
+
+			// Pages read before the rejection are discarded, not returned twice
+			seen := make(map[time.Time]int)
+			for _, record := range records {
+				seen[record.Timestamp]++
+			}
+			assert.Len(t, seen, recordCount)
+			assert.Len(t, records, recordCount)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+)
+
+const (
+	// checkpointClientName is the name of the storage client holding the checkpoint
+	checkpointClientName = "checkpoint"
+	// checkpointKey is the storage key of the checkpoint
+	checkpointKey = "scrape.checkpoint"
+)
+
+// checkpoint is the scrape position persisted in the storage extension, so a
+// restarted collector continues with the collection window after the last
+// one instead of reading the server's whole log again.
+//
+// Continuation points are not part of it: they belong to the session that
+// received them, and the server releases them when the session closes. A
+// window the record budget ran out in is kept with the position each
+// LogObject read it to instead, so a restart continues every LogObject after
+// its last record rather than reading the window again.
+type checkpoint struct {
+	// LastCollectTime is the end of the last collection window
+	LastCollectTime time.Time `json:"last_collect_time"`
+	// ResumeEnd is the end of the window after LastCollectTime that was not
+	// read completely yet
+	ResumeEnd time.Time `json:"resume_end,omitzero"`
+	// Positions holds how far each LogObject read the window up to ResumeEnd,
+	// and where the LogObjects skipped because their read failed continue
+	Positions map[string]readPosition `json:"positions,omitempty"`
+	// LastSuccess is the time of the last successful scrape
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	// LogObjects holds the time of the last successful read of each LogObject
+	LogObjects map[string]time.Time `json:"log_objects,omitempty"`
+}
+
+// checkpointStore reads and writes the checkpoint of a receiver
+type checkpointStore struct {
+	client storage.Client
+}
+
+// newCheckpointStore opens the checkpoint storage client of the receiver
+func newCheckpointStore(ctx context.Context, host component.Host, storageID component.ID, receiverID component.ID) (*checkpointStore, error) {
+	client, err := getStorageClient(ctx, host, storageID, receiverID, checkpointClientName)
+	if err != nil {
+		return nil, err
+	}
+	return &checkpointStore{client: client}, nil
+}
+
+// load returns the stored checkpoint; ok is false if none was stored yet
+func (s *checkpointStore) load(ctx context.Context) (cp checkpoint, ok bool, err error) {
+	data, err := s.client.Get(ctx, checkpointKey)
+	if err != nil {
+		return checkpoint{}, false, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	if data == nil {
+		return checkpoint{}, false, nil
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return checkpoint{}, false, fmt.Errorf("invalid checkpoint: %w", err)
+	}
+	return cp, true, nil
+}
+
+// save stores the checkpoint
+func (s *checkpointStore) save(ctx context.Context, cp checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	if err := s.client.Set(ctx, checkpointKey, data); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// close closes the storage client
+func (s *checkpointStore) close(ctx context.Context) error {
+	return s.client.Close(ctx)
+}
+
+// checkpoint returns the scrape position of the scraper
+func (s *scraper) checkpoint() checkpoint {
+	s.successMu.Lock()
+	defer s.successMu.Unlock()
+	return checkpoint{
+		LastCollectTime: s.lastCollectTime,
+		ResumeEnd:       s.resumeEnd,
+		Positions:       maps.Clone(s.positions),
+		LastSuccess:     s.lastSuccess,
+		LogObjects:      maps.Clone(s.logObjectSuccess),
+	}
+}
+
+// restoreCheckpoint continues from the scrape position of a previous run
+func (s *scraper) restoreCheckpoint(cp checkpoint) {
+	s.successMu.Lock()
+	defer s.successMu.Unlock()
+	s.lastCollectTime = cp.LastCollectTime
+	s.resumeEnd = cp.ResumeEnd
+	s.positions = maps.Clone(cp.Positions)
+	s.lastSuccess = cp.LastSuccess
+	s.logObjectSuccess = maps.Clone(cp.LogObjects)
+}
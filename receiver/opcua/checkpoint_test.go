@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestCheckpointStore(t *testing.T) {
+	ctx := context.Background()
+	storageExt := newMemoryStorage()
+	host := newStorageHost(storageExt)
+	id := component.MustNewID("opcua")
+
+	store, err := newCheckpointStore(ctx, host, testStorageID, id)
+	require.NoError(t, err)
+	_, ok, err := store.load(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	saved := checkpoint{
+		LastCollectTime: t0,
+		LastSuccess:     t0,
+		LogObjects:      map[string]time.Time{"ns=1;i=1000": t0.Add(-time.Minute)},
+	}
+	require.NoError(t, store.save(ctx, saved))
+	require.NoError(t, store.close(ctx))
+
+	reopened, err := newCheckpointStore(ctx, host, testStorageID, id)
+	require.NoError(t, err)
+	loaded, ok, err := reopened.load(ctx)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, saved.LastCollectTime.Equal(loaded.LastCollectTime))
+	assert.True(t, saved.LastSuccess.Equal(loaded.LastSuccess))
+	require.Len(t, loaded.LogObjects, 1)
+	assert.True(t, t0.Add(-time.Minute).Equal(loaded.LogObjects["ns=1;i=1000"]))
+
+	storageExt.data[checkpointKey] = []byte("{")
+	_, _, err = reopened.load(ctx)
+	assert.ErrorContains(t, err, "invalid checkpoint")
+}
+
+func TestReceiverCheckpointResume(t *testing.T) {
+	ctx := context.Background()
+	host := newStorageHost(newMemoryStorage())
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	// newReceiver creates a receiver restored from the checkpoint in host,
+	// collecting from a client that records the requested windows
+	newReceiver := func(now time.Time) (*opcuaReceiver, *windowClient) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.StorageID = &testStorageID
+		client := &windowClient{}
+		cfg.Client = client
+
+		r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+		require.NoError(t, err)
+		r.nextLogs = consumertest.NewNop()
+		r.scraper.client = client
+		r.scraper.clock = newFakeClock(now)
+		require.NoError(t, r.openCheckpoint(ctx, host))
+		t.Cleanup(func() {
+			assert.NoError(t, r.checkpoints.close(ctx))
+		})
+		return r, client
+	}
+
+	first, client := newReceiver(t0)
+	require.NoError(t, first.collectAndConsume(ctx))
+	require.Len(t, client.windows, 1)
+	assert.True(t, client.windows[0].start.IsZero())
+
+	// The restarted receiver continues with the window after the last one
+	restarted, client := newReceiver(t0.Add(time.Hour))
+	require.NoError(t, restarted.collectAndConsume(ctx))
+	require.Len(t, client.windows, 1)
+	assert.True(t, t0.Equal(client.windows[0].start), "window start %s", client.windows[0].start)
+	assert.True(t, t0.Add(time.Hour).Equal(client.windows[0].end))
+
+	// A failed scrape leaves the checkpoint where it was
+	failing, client := newReceiver(t0.Add(2 * time.Hour))
+	client.err = errors.New("server unavailable")
+	require.Error(t, failing.collectAndConsume(ctx))
+	again, client := newReceiver(t0.Add(3 * time.Hour))
+	require.NoError(t, again.collectAndConsume(ctx))
+	require.Len(t, client.windows, 1)
+	assert.True(t, t0.Add(time.Hour).Equal(client.windows[0].start))
+}
+
+func TestReceiverCheckpointResumeWindow(t *testing.T) {
+	ctx := context.Background()
+	host := newStorageHost(newMemoryStorage())
+	ws := startWireServer(t)
+
+	// Three records share a timestamp, so the first scrape stops between them
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	record := func(ts time.Time, message string) testdata.OPCUALogRecord {
+		return testdata.OPCUALogRecord{Timestamp: ts, Severity: 150, Message: message, Attributes: map[string]interface{}{}}
+	}
+	ws.AddLogRecords([]testdata.OPCUALogRecord{
+		record(base, "r0"),
+		record(base, "r1"),
+		record(base, "r2"),
+		record(base.Add(time.Minute), "r3"),
+		record(base.Add(2*time.Minute), "r4"),
+	})
+
+	// newReceiver creates a receiver restored from the checkpoint in host
+	// that reads two records per scrape
+	newReceiver := func() (*opcuaReceiver, *consumertest.LogsSink) {
+		cfg := ws.newWireConfig()
+		cfg.StorageID = &testStorageID
+		cfg.MaxRecordsPerCall = 2
+		r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+		require.NoError(t, err)
+		sink := new(consumertest.LogsSink)
+		r.nextLogs = sink
+		require.NoError(t, r.openCheckpoint(ctx, host))
+		require.NoError(t, r.scraper.start(ctx, componenttest.NewNopHost()))
+		t.Cleanup(func() {
+			assert.NoError(t, r.scraper.shutdown(ctx))
+			assert.NoError(t, r.checkpoints.close(ctx))
+		})
+		return r, sink
+	}
+	messages := func(sink *consumertest.LogsSink) []string {
+		var messages []string
+		for _, logs := range sink.AllLogs() {
+			lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			for i := 0; i < lrs.Len(); i++ {
+				messages = append(messages, lrs.At(i).Body().Str())
+			}
+		}
+		return messages
+	}
+
+	first, sink := newReceiver()
+	require.NoError(t, first.collectAndConsume(ctx))
+	assert.Equal(t, []string{"r0", "r1"}, messages(sink))
+	require.False(t, first.scraper.resumeEnd.IsZero())
+
+	// The restarted receiver has no continuation point, but continues the
+	// window after the last record read instead of reading it again
+	restarted, sink := newReceiver()
+	assert.True(t, first.scraper.resumeEnd.Equal(restarted.scraper.resumeEnd))
+	require.NoError(t, restarted.collectAndConsume(ctx))
+	require.NoError(t, restarted.collectAndConsume(ctx))
+	assert.Equal(t, []string{"r2", "r3", "r4"}, messages(sink))
+	assert.True(t, restarted.scraper.resumeEnd.IsZero())
+	assert.Empty(t, restarted.scraper.positions)
+}
@@ -5,17 +5,50 @@ package opcua
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
 	"github.com/gopcua/opcua/ua"
+	"github.com/gopcua/opcua/uacp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
-	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/recording"
 )
 
+// LogRecord is a log record read from an OPC UA LogObject (Part 26 §5.4), as
+// returned by OPCUAClient.GetRecords
+type LogRecord = model.LogRecord
+
+// OPCUAClient defines the interface for OPC UA client operations.
+// The receiver uses its built-in gopcua client unless Config.Client supplies
+// another implementation, for example one wrapping the connection with custom
+// authentication, caching or a simulation.
+//
+// GetRecords hands the returned records over to the caller, which reuses
+// their slice and attribute maps once they were transformed. Implementations
+// must not keep references to returned records.
+type OPCUAClient interface {
+	// Connect establishes the session; it is called once when the receiver starts
+	Connect(ctx context.Context) error
+	// Disconnect closes the session; it is called once when the receiver shuts down
+	Disconnect(ctx context.Context) error
+	// IsConnected reports whether the session is established
+	IsConnected() bool
+	// GetRecords returns at most maxRecords records logged between startTime and endTime
+	GetRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int) ([]LogRecord, error)
+}
+
 // opcuaClient implements the OPCUAClient interface using the gopcua library
 type opcuaClient struct {
 	config       *Config
@@ -23,92 +56,315 @@ type opcuaClient struct {
 	client       *opcua.Client
 	mu           sync.Mutex
 	logObjectIDs []*ua.NodeID // Support multiple LogObject nodes
+	certProvider CertificateProvider
+
+	// Application description and session name announced to the server
+	identity clientIdentity
+
+	// endpoint is the server endpoint the current session was opened on
+	endpoint *ua.EndpointDescription
+
+	// activeEndpoint is the endpoint URL of the current session: endpoint,
+	// or one of the backup endpoints of redundancy; guarded by mu
+	activeEndpoint string
+
+	// Return to the primary endpoint after a failover, see redundancy.go
+	failback *failbackMonitor
+
+	// GetRecords capture file, see capture.go
+	captureMu sync.Mutex
+	capture   *recording.Writer
+
+	// GetRecords response dumps, see debug_dump.go
+	dumpMu      sync.Mutex
+	dumpLimiter dumpLimiter
+	dumpSeq     int
+
+	// Suppression of recurring warnings, see log_suppression.go
+	warnings warningLimiter
+
+	// MaxReturnRecords limits of LogObjects whose responses were too large, see get_records.go
+	pageLimitMu sync.Mutex
+	pageLimits  map[string]uint32
+
+	// NodeIDs of the variable metrics given as browse paths, see variable_metrics.go
+	variableMu  sync.Mutex
+	variableIDs map[string]*ua.NodeID
+
+	// Query the record budget of the last GetRecords call ran out in, and
+	// the positions of the LogObjects to read from without it, see
+	// get_records.go; guarded by mu
+	carried   *carriedQuery
+	positions map[string]readPosition
+
+	// TypeID of LogRecord ExtensionObjects, resolved from log_record_type_id
+	// on connect; nil accepts any TypeID
+	logRecordTypeID atomic.Pointer[ua.NodeID]
+
+	// Description of the server, read on every connect; see server_info.go
+	info atomic.Pointer[serverInfo]
+
+	// Smallest server limit bounding the GetRecords calls at a time, read on
+	// connect if LogObjects are read concurrently; 0 if unlimited. See
+	// concurrent_reads.go
+	readLimit atomic.Uint32
+
+	// Recovery of lost sessions, see reconnect.go
+	reconnect *reconnectManager
+
+	// Keepalive reads of the server state between scrapes, see keepalive.go
+	watchdog *sessionWatchdog
+
+	// Listener for connections of the server if reverse_connect is set, see
+	// reverse.go; created on the first connect and guarded by mu
+	reverse *reverseConnector
+
+	// telemetry receives the connect, call and browse durations, if set
+	telemetry *metadata.TelemetryBuilder
+
+	// onLogObjectRead is called with the outcome of each LogObject read by
+	// GetRecords, if set. Reads that fail the whole call are not reported.
+	onLogObjectRead func(logObjectRead)
 }
 
 // newOPCUAClient creates a new OPC UA client
 func newOPCUAClient(config *Config, logger *zap.Logger) *opcuaClient {
-	return &opcuaClient{
-		config: config,
-		logger: logger,
-	}
+	c := &opcuaClient{
+		config:   config,
+		logger:   logger,
+		identity: newClientIdentity(config.ClientIdentity, ""),
+	}
+	c.warnings.interval = config.LogSuppressionInterval
+	c.reconnect = newReconnectManager(config.Reconnect, logger, c.Connect, c.IsConnected)
+	c.watchdog = newSessionWatchdog(config.KeepAlive, logger, c.readServerState, c.recoverSession, c.keepAliveIdle)
+	c.failback = newFailbackMonitor(config.Redundancy.FailbackInterval, logger, c.onBackup, c.primaryReachable, c.Connect)
+	return c
 }
 
-// Connect establishes connection to the OPC UA server
+// Connect establishes connection to the OPC UA server, trying the backup
+// endpoints of redundancy in order if endpoint cannot be connected to. A
+// previous session is closed first so reconnecting never leaks a secure channel.
 func (c *opcuaClient) Connect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	endpoints := c.config.endpoints()
+	var errs []error
+	for i, endpoint := range endpoints {
+		err := c.connectEndpoint(ctx, endpoint)
+		if err == nil {
+			if i > 0 {
+				c.logger.Warn("Connected to backup OPC UA server",
+					zap.String("primary_endpoint", c.config.Endpoint),
+					zap.String("backup_endpoint", endpoint))
+			}
+			c.activeEndpoint = endpoint
+			if len(endpoints) > 1 {
+				c.failback.start()
+			}
+			return nil
+		}
+		if len(endpoints) == 1 {
+			return err
+		}
+		errs = append(errs, err)
+		if ctx.Err() != nil {
+			break
+		}
+		c.logger.Warn("Failed to connect to OPC UA server, trying the next endpoint",
+			zap.String("endpoint", endpoint),
+			zap.Error(err))
+	}
+	return errors.Join(errs...)
+}
+
+// connectEndpoint establishes a session with the server at endpoint. c.mu
+// must be held.
+func (c *opcuaClient) connectEndpoint(ctx context.Context, endpoint string) error {
+	if c.client != nil {
+		if err := c.client.Close(ctx); err != nil {
+			c.logger.Debug("Failed to close previous OPC UA session", zap.Error(err))
+		}
+		c.client = nil
+	}
+
+	start := time.Now()
+	endpointURL := endpoint
+	var dialer *uacp.Dialer
+	if c.config.ReverseConnect.ListenAddress != "" {
+		// The server opens the connections, so the dialer settings do not
+		// apply to the loopback dials of the reverse connector
+		if c.reverse == nil {
+			reverse, err := newReverseConnector(c.config, c.logger)
+			if err != nil {
+				return newConnectionError(endpoint, err)
+			}
+			c.reverse = reverse
+		}
+		endpointURL = c.reverse.localEndpoint()
+		dialer = &uacp.Dialer{}
+	} else {
+		var err error
+		dialer, err = newDialer(c.config.Dialer)
+		if err != nil {
+			return fmt.Errorf("failed to create dialer: %w", err)
+		}
+	}
+
 	// Build connection options
-	endpoints, err := opcua.GetEndpoints(ctx, c.config.Endpoint)
+	endpoints, err := opcua.GetEndpoints(ctx, endpointURL, opcua.Dialer(dialer))
 	if err != nil {
-		return fmt.Errorf("failed to get endpoints: %w", err)
+		return newConnectionError(endpoint, fmt.Errorf("failed to get endpoints: %w", err))
 	}
 
 	if len(endpoints) == 0 {
-		return fmt.Errorf("no endpoints available at %s", c.config.Endpoint)
+		return newConnectionError(endpoint, fmt.Errorf("no endpoints available at %s", endpoint))
 	}
 
 	// Select appropriate endpoint based on security settings
 	ep := c.selectEndpoint(endpoints)
 	if ep == nil {
-		return fmt.Errorf("no suitable endpoint found for security settings")
+		return newConnectionError(endpoint, fmt.Errorf("no suitable endpoint found for security settings"))
+	}
+
+	// Present the user identity the endpoint is asked for, otherwise gopcua
+	// falls back to an anonymous session
+	tokenType := c.userTokenType()
+	if tokenType != ua.UserTokenTypeAnonymous && !offersUserToken(ep, tokenType) {
+		return newConnectionError(endpoint, fmt.Errorf("endpoint %s (%s) does not accept %s user tokens, check auth.type and security_policy",
+			ep.EndpointURL, ep.SecurityMode, tokenType))
 	}
 
 	// Build client options
 	opts := []opcua.Option{
-		opcua.SecurityFromEndpoint(ep, ua.UserTokenTypeAnonymous),
+		opcua.SecurityFromEndpoint(ep, tokenType),
+		opcua.Dialer(dialer),
 	}
+	opts = append(opts, c.identity.options()...)
+	opts = append(opts, c.config.transportOptions()...)
 
-	// Add authentication
-	switch c.config.Auth.Type {
-	case "username_password":
-		opts = append(opts, opcua.AuthUsername(c.config.Auth.Username, c.config.Auth.Password))
-	case "certificate":
-		if c.config.TLS.CertFile != "" && c.config.TLS.KeyFile != "" {
-			opts = append(opts, opcua.CertificateFile(c.config.TLS.CertFile))
-			opts = append(opts, opcua.PrivateKeyFile(c.config.TLS.KeyFile))
+	// Use the application identity and trust list of the shared certificate
+	// provider, otherwise the CAs and trusted_directory of the tls section
+	if c.certProvider == nil && ep.SecurityMode != ua.MessageSecurityModeNone {
+		if err := verifyServerCA(ctx, c.config.TLS, ep.ServerCertificate); err != nil {
+			return newConnectionError(endpoint, err)
+		}
+		// The secure channel is signed with the application certificate
+		// whatever the user identity
+		if c.config.TLS.hasApplicationCertificate() {
+			cert, key, err := c.config.TLS.applicationCertificate()
+			if err != nil {
+				return err
+			}
+			opts = append(opts, opcua.Certificate(cert), opcua.PrivateKey(key))
 		}
-	case "anonymous":
-		opts = append(opts, opcua.AuthAnonymous())
 	}
+	if c.certProvider != nil {
+		if ep.SecurityMode != ua.MessageSecurityModeNone {
+			if err := verifyServerCertificate(ctx, c.certProvider, ep.ServerCertificate); err != nil {
+				return newConnectionError(endpoint, err)
+			}
+		}
+		certOpts, err := certificateOptions(ctx, c.certProvider)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, certOpts...)
+	}
+
+	// Add authentication
+	authOpts, err := c.userIdentityOptions(ctx)
+	if err != nil {
+		return err
+	}
+	opts = append(opts, authOpts...)
 
 	// Add request timeout
 	opts = append(opts, opcua.RequestTimeout(c.config.RequestTimeout))
 
+	// Preferred locales of LocalizedText, sent when activating the session
+	if len(c.config.LocaleIDs) > 0 {
+		opts = append(opts, opcua.Locales(c.config.LocaleIDs...))
+	}
+
+	// Lost sessions are recovered by the reconnect manager with backoff
+	// instead of gopcua's fixed interval
+	opts = append(opts, opcua.AutoReconnect(false))
+
 	// Create client using the configured endpoint URL (not the discovered one,
 	// which may contain the server's internal hostname instead of the network-reachable name).
-	client, err := opcua.NewClient(c.config.Endpoint, opts...)
+	client, err := opcua.NewClient(endpointURL, opts...)
 	if err != nil {
-		return fmt.Errorf("failed to create OPC UA client: %w", err)
+		return newConnectionError(endpoint, fmt.Errorf("failed to create OPC UA client: %w", err))
 	}
 
 	c.client = client
+	c.endpoint = ep
+	// Continuation points are released with the session they belong to
+	c.carried = nil
+
+	// Browse paths may resolve differently on a restarted server
+	c.variableMu.Lock()
+	c.variableIDs = nil
+	c.variableMu.Unlock()
 
 	// Connect with timeout
 	connectCtx, cancel := context.WithTimeout(ctx, c.config.ConnectionTimeout)
 	defer cancel()
 
 	if err := c.client.Connect(connectCtx); err != nil {
-		return fmt.Errorf("failed to connect to OPC UA server: %w", err)
+		if isAuthenticationError(err) {
+			return newConnectionError(endpoint, fmt.Errorf("OPC UA server rejected %s authentication: %w", c.config.Auth.Type, err))
+		}
+		return newConnectionError(endpoint, fmt.Errorf("failed to connect to OPC UA server: %w", err))
 	}
+	if c.telemetry != nil {
+		c.telemetry.OpcuaConnectDuration.Record(ctx, time.Since(start).Seconds())
+	}
+	c.watchdog.start()
 
 	c.logger.Info("Connected to OPC UA server",
 		zap.String("endpoint", ep.EndpointURL),
 		zap.String("security_policy", ep.SecurityPolicyURI),
 		zap.String("security_mode", ep.SecurityMode.String()))
+	c.logSession()
+
+	info := readServerInfo(ctx, c.client, ep, c.config.RequestTimeout, c.logger)
+	c.info.Store(&info)
+
+	// The limits may differ on a restarted or backup server
+	c.readLimit.Store(0)
+	if c.config.concurrentReads() > 1 {
+		c.readLimit.Store(readConcurrencyLimit(ctx, c.client, c.config.RequestTimeout, c.logger))
+	}
+
+	if c.config.LogRecordTypeID != "" {
+		typeID, err := resolveNodeID(c.config.LogRecordTypeID, c.client.Namespaces())
+		if err != nil {
+			return newDiscoveryError(c.config.LogRecordTypeID, fmt.Errorf("failed to resolve log_record_type_id: %w", err))
+		}
+		c.logRecordTypeID.Store(typeID)
+	}
+
+	// Alarms mode reads condition events and needs no LogObjects
+	if c.config.Mode == collectionModeAlarms {
+		return nil
+	}
 
 	// Discover LogObject nodes from configured paths
 	if err := c.discoverLogObjects(ctx); err != nil {
-		c.logger.Warn("Failed to discover LogObject nodes from configured paths", zap.Error(err))
+		c.warnings.Warn(c.logger, "discover_log_objects", "Failed to discover LogObject nodes from configured paths", zap.Error(err))
 		// Fallback: try standard ServerLog node (NodeID 2042 in namespace 0)
 		c.logger.Info("Attempting to use default ServerLog node as fallback")
 		if err := c.tryDefaultServerLog(ctx); err != nil {
-			return fmt.Errorf("failed to discover any LogObject nodes: %w", err)
+			return newDiscoveryError("", fmt.Errorf("failed to discover any LogObject nodes: %w", err))
 		}
+	} else {
+		c.warnings.Clear(c.logger, "discover_log_objects")
 	}
 
 	if len(c.logObjectIDs) == 0 {
-		return fmt.Errorf("no LogObject nodes found")
+		return newDiscoveryError("", fmt.Errorf("no LogObject nodes found"))
 	}
 
 	c.logger.Info("Successfully discovered LogObject nodes",
@@ -119,12 +375,24 @@ func (c *opcuaClient) Connect(ctx context.Context) error {
 
 // Disconnect closes the connection to the OPC UA server
 func (c *opcuaClient) Disconnect(ctx context.Context) error {
+	c.watchdog.stop()
+	c.failback.stop()
+	c.reconnect.stop()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	c.closeCapture()
+	if c.reverse != nil {
+		// Closing the session first lets it end over the bridged connection
+		defer func() {
+			c.reverse.close()
+			c.reverse = nil
+		}()
+	}
 	if c.client != nil {
 		if err := c.client.Close(ctx); err != nil {
-			return fmt.Errorf("failed to disconnect from OPC UA server: %w", err)
+			return newConnectionError(c.config.Endpoint, fmt.Errorf("failed to disconnect from OPC UA server: %w", err))
 		}
 		c.client = nil
 		c.logger.Info("Disconnected from OPC UA server")
@@ -140,67 +408,125 @@ func (c *opcuaClient) IsConnected() bool {
 	return c.client != nil && c.client.State() == opcua.Connected
 }
 
+// session returns the current OPC UA session. Calls made without holding c.mu
+// must use it instead of reading c.client, which Connect and Disconnect replace.
+func (c *opcuaClient) session() (*opcua.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil {
+		return nil, newConnectionError(c.config.Endpoint, errNotConnected)
+	}
+	return c.client, nil
+}
+
 // GetRecords retrieves log records from all configured LogObject nodes
-func (c *opcuaClient) GetRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int) ([]testdata.OPCUALogRecord, error) {
+func (c *opcuaClient) GetRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int) ([]model.LogRecord, error) {
+	return c.getRecords(ctx, startTime, endTime, maxRecords, nil)
+}
+
+// streamRecords reads the records like GetRecords and passes them to emit
+// page by page, see recordStreamer
+func (c *opcuaClient) streamRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int, emit func([]model.LogRecord)) error {
+	records, err := c.getRecords(ctx, startTime, endTime, maxRecords, emit)
+	putRecordSlice(records)
+	return err
+}
+
+// getRecords reads the records of all LogObjects, returning them or passing
+// the pages to emit if it is set
+func (c *opcuaClient) getRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int, emit func([]model.LogRecord)) ([]model.LogRecord, error) {
 	c.mu.Lock()
-	client := c.client
+	connected := c.client != nil
 	logObjectIDs := c.logObjectIDs
+	carried := c.carried
+	c.carried = nil
+	positions := c.positions
 	c.mu.Unlock()
 
-	if client == nil {
-		return nil, fmt.Errorf("client not connected")
+	if !connected {
+		return nil, newConnectionError(c.config.Endpoint, errNotConnected)
 	}
 
 	if len(logObjectIDs) == 0 {
-		return nil, fmt.Errorf("no LogObject nodes configured")
-	}
-
-	// Collect records from all LogObject nodes
-	var allRecords []testdata.OPCUALogRecord
-	recordsPerNode := maxRecords / len(logObjectIDs)
-	if recordsPerNode < 1 {
-		recordsPerNode = 1
+		return nil, newDiscoveryError("", fmt.Errorf("no LogObject nodes configured"))
 	}
 
 	// Convert minimum severity from config
 	minSeverity := c.getMinSeverityValue()
 
-	for _, logObjectID := range logObjectIDs {
-		// Call GetRecords with pagination support
-		continuationPoint := []byte(nil)
-		nodeRecords := 0
-
-		for {
-			records, nextContinuationPoint, err := c.callGetRecordsMethod(
-				ctx,
-				logObjectID,
-				startTime,
-				endTime,
-				uint32(recordsPerNode-nodeRecords),
-				minSeverity,
-				continuationPoint,
-			)
-
-			if err != nil {
-				c.logger.Warn("Failed to call GetRecords method on LogObject",
-					zap.String("node_id", logObjectID.String()),
-					zap.Error(err))
-				break
+	q := &recordQuery{
+		logObjectIDs: logObjectIDs,
+		startTime:    startTime,
+		endTime:      endTime,
+		minSeverity:  minSeverity,
+		reads:        make([]logObjectRead, len(logObjectIDs)),
+		pending:      make([][]byte, len(logObjectIDs)),
+		unstarted:    make([]bool, len(logObjectIDs)),
+		from:         make([]readPosition, len(logObjectIDs)),
+		records:      getRecordSlice(),
+		emit:         emit,
+		budget:       maxRecords,
+	}
+	fail := func(err error) ([]model.LogRecord, error) {
+		c.reportLogObjectReads(q.reads)
+		releaseRecords(q.records)
+		return nil, err
+	}
+
+	// A query of the same window the last call ran out of budget in resumes
+	// the LogObjects that had records left or were not read, and skips the
+	// finished ones. Without continuation points, the LogObjects are read
+	// from their positions, skipping the done ones.
+	resume := carried.resumes(startTime, endTime)
+	jobs := make([]int, 0, len(logObjectIDs))
+	for i, logObjectID := range logObjectIDs {
+		q.reads[i].logObjectID = logObjectID.String()
+		position, ok := positions[q.reads[i].logObjectID]
+		switch {
+		case resume:
+			continuationPoint, ok := carried.continuationPoints[q.reads[i].logObjectID]
+			if !ok {
+				continue
 			}
+			q.pending[i] = continuationPoint
+		case position.Done:
+			continue
+		}
+		q.from[i] = readPosition{Start: startTime}
+		if ok {
+			q.from[i] = position
+		}
+		q.unstarted[i] = true
+		jobs = append(jobs, i)
+	}
 
-			allRecords = append(allRecords, records...)
-			nodeRecords += len(records)
+	// Every LogObject gets an equal share of the budget left, so the quota a
+	// LogObject does not use goes to the ones read after it
+	if err := c.readPass(ctx, q, jobs); err != nil {
+		return fail(err)
+	}
 
-			// Check if we have more records via continuation point
-			if len(nextContinuationPoint) == 0 || nodeRecords >= recordsPerNode {
-				break
+	// LogObjects stopped by their quota, and those the budget ran out before,
+	// continue with the budget the others returned, shared equally, until it
+	// is spent or they are exhausted
+	for q.budget > 0 {
+		jobs = jobs[:0]
+		for i, next := range q.pending {
+			if len(next) > 0 || q.unstarted[i] {
+				jobs = append(jobs, i)
 			}
-
-			continuationPoint = nextContinuationPoint
+		}
+		if len(jobs) == 0 {
+			break
+		}
+		if err := c.readPass(ctx, q, jobs); err != nil {
+			return fail(err)
 		}
 	}
 
-	return allRecords, nil
+	c.carryOver(startTime, endTime, logObjectIDs, q.pending, q.unstarted)
+	c.reportLogObjectReads(q.reads)
+	return q.records, nil
 }
 
 // selectEndpoint selects an appropriate endpoint based on security configuration
@@ -267,7 +593,7 @@ func (c *opcuaClient) discoverLogObjects(ctx context.Context) error {
 
 		nodeID, err := c.translateBrowsePathToNodeID(ctx, path)
 		if err != nil {
-			c.logger.Warn("Failed to resolve LogObject path",
+			c.warnings.Warn(c.logger, "resolve_path/"+path, "Failed to resolve LogObject path",
 				zap.String("path", path),
 				zap.Error(err))
 			errors = append(errors, fmt.Errorf("path %s: %w", path, err))
@@ -276,7 +602,7 @@ func (c *opcuaClient) discoverLogObjects(ctx context.Context) error {
 
 		// Verify the node exists and is accessible
 		if err := c.verifyNodeExists(ctx, nodeID); err != nil {
-			c.logger.Warn("LogObject node not accessible",
+			c.warnings.Warn(c.logger, "verify_node/"+path, "LogObject node not accessible",
 				zap.String("path", path),
 				zap.String("node_id", nodeID.String()),
 				zap.Error(err))
@@ -287,6 +613,8 @@ func (c *opcuaClient) discoverLogObjects(ctx context.Context) error {
 		c.logger.Info("Discovered LogObject node",
 			zap.String("path", path),
 			zap.String("node_id", nodeID.String()))
+		c.warnings.Clear(c.logger, "resolve_path/"+path)
+		c.warnings.Clear(c.logger, "verify_node/"+path)
 		discoveredNodes = append(discoveredNodes, nodeID)
 	}
 
@@ -325,7 +653,9 @@ func (c *opcuaClient) verifyNodeExists(ctx context.Context, nodeID *ua.NodeID) e
 		},
 	}
 
+	start := time.Now()
 	resp, err := c.client.Read(ctx, req)
+	c.recordBrowseDuration(ctx, "Read", start)
 	if err != nil {
 		return fmt.Errorf("failed to read node: %w", err)
 	}
@@ -341,50 +671,118 @@ func (c *opcuaClient) verifyNodeExists(ctx context.Context, nodeID *ua.NodeID) e
 	return nil
 }
 
+// serverLogPaths are browse paths of the standard ServerLog node, used as
+// i=2042 when the server does not resolve them, as for the default
+// "Objects/ServerLog"
+var serverLogPaths = map[string]bool{
+	"Objects/ServerLog":        true,
+	"Objects/Server/ServerLog": true,
+	"ServerLog":                true,
+	"Objects/Server/ServerDiagnostics/ServerLog": true,
+}
+
 // translateBrowsePathToNodeID converts a browse path string or NodeID string to a NodeID
 func (c *opcuaClient) translateBrowsePathToNodeID(ctx context.Context, path string) (*ua.NodeID, error) {
-	// First, try to parse as a NodeID string (e.g., "ns=0;i=2042" or "i=2042")
-	if nodeID, err := ua.ParseNodeID(path); err == nil {
+	// First, try to parse as a NodeID string (e.g., "ns=0;i=2042" or "i=2042").
+	// A namespace URI is mapped to the index the server uses in this session.
+	if isNodeID(path) {
+		nodeID, err := resolveNodeID(path, c.client.Namespaces())
+		if err != nil {
+			return nil, err
+		}
 		c.logger.Debug("Parsed path as NodeID", zap.String("path", path), zap.String("node_id", nodeID.String()))
 		return nodeID, nil
 	}
 
-	// Otherwise, treat as browse path and use known mappings
-	nodeID, err := c.resolveBrowsePath(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to resolve browse path %s: %w", path, err)
+	// Otherwise resolve the browse path against the address space
+	nodeID, err := translateBrowsePath(ctx, c.client, path, c.recordBrowseDuration)
+	if err == nil {
+		return nodeID, nil
+	}
+	var status ua.StatusCode
+	if serverLogPaths[path] && errors.As(err, &status) {
+		nodeID = ua.NewNumericNodeID(0, 2042)
+		c.logger.Debug("Browse path not found, using the standard ServerLog node",
+			zap.String("path", path),
+			zap.String("node_id", nodeID.String()))
+		return nodeID, nil
 	}
+	return nil, fmt.Errorf("failed to resolve browse path %s: %w", path, err)
+}
 
-	return nodeID, nil
+// isNodeID reports whether s is written as a NodeID rather than a browse
+// path. ua.ParseNodeID alone takes any other text for a string NodeID.
+func isNodeID(s string) bool {
+	for _, prefix := range []string{"ns=", namespaceURIPrefix, "i=", "s=", "g=", "b="} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
-// resolveBrowsePath resolves known browse paths to NodeIDs
-func (c *opcuaClient) resolveBrowsePath(path string) (*ua.NodeID, error) {
-	// Map of known browse paths to their NodeIDs
-	knownPaths := map[string]*ua.NodeID{
-		"Objects/ServerLog":        ua.NewNumericNodeID(0, 2042),
-		"Objects/Server/ServerLog": ua.NewNumericNodeID(0, 2042),
-		"ServerLog":                ua.NewNumericNodeID(0, 2042),
-		"Objects/Server/ServerDiagnostics/ServerLog": ua.NewNumericNodeID(0, 2042),
+// parseBrowsePath splits a browse path below the Objects folder into
+// qualified names. Elements are "name" in namespace 0 or "ns:name"; a
+// leading "Objects" element is optional.
+func parseBrowsePath(path string) ([]*ua.QualifiedName, error) {
+	elements := strings.Split(strings.Trim(path, "/"), "/")
+	if elements[0] == "Objects" {
+		elements = elements[1:]
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("browse path %s has no elements below Objects", path)
 	}
 
-	// Check if path matches a known mapping
-	if nodeID, ok := knownPaths[path]; ok {
-		c.logger.Debug("Resolved browse path using known mapping",
-			zap.String("path", path),
-			zap.String("node_id", nodeID.String()))
-		return nodeID, nil
+	names := make([]*ua.QualifiedName, len(elements))
+	for i, element := range elements {
+		name := &ua.QualifiedName{Name: element}
+		if prefix, rest, ok := strings.Cut(element, ":"); ok {
+			if ns, err := strconv.ParseUint(prefix, 10, 16); err == nil {
+				name = &ua.QualifiedName{NamespaceIndex: uint16(ns), Name: rest}
+			}
+		}
+		if name.Name == "" {
+			return nil, fmt.Errorf("browse path %s has an empty element", path)
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// translateBrowsePath resolves a browse path below the Objects folder with
+// the TranslateBrowsePathsToNodeIDs service. record receives the duration
+// of the call.
+func translateBrowsePath(ctx context.Context, session *opcua.Client, path string, record func(context.Context, string, time.Time)) (*ua.NodeID, error) {
+	names, err := parseBrowsePath(path)
+	if err != nil {
+		return nil, err
 	}
+	start := time.Now()
+	nodeID, err := session.Node(ua.NewNumericNodeID(0, id.ObjectsFolder)).TranslateBrowsePathsToNodeIDs(ctx, names)
+	record(ctx, "TranslateBrowsePathsToNodeIDs", start)
+	if err != nil {
+		return nil, err
+	}
+	return nodeID, nil
+}
 
-	// For unknown paths, try to browse the address space
-	// This is a simplified implementation - a full implementation would use
-	// the TranslateBrowsePathsToNodeIDs service
-	return nil, fmt.Errorf("unknown browse path: %s (use NodeID format like 'ns=0;i=2042' or add to known paths)", path)
+// recordBrowseDuration records the round-trip time of a discovery request
+// started at start, if telemetry is set
+func (c *opcuaClient) recordBrowseDuration(ctx context.Context, service string, start time.Time) {
+	if c.telemetry != nil {
+		c.telemetry.OpcuaBrowseDuration.Record(ctx, time.Since(start).Seconds(),
+			metric.WithAttributes(attribute.String("opcua.service", service)))
+	}
 }
 
 // findGetRecordsMethod browses the children of a LogObject node to find a method
 // named "GetRecords". Returns the method's NodeID or an error if not found.
 func (c *opcuaClient) findGetRecordsMethod(ctx context.Context, logObjectID *ua.NodeID) (*ua.NodeID, error) {
+	client, err := c.session()
+	if err != nil {
+		return nil, err
+	}
+
 	// Try browsing with HasComponent first, then fall back to all references
 	referenceTypes := []*ua.NodeID{
 		ua.NewNumericNodeID(0, 47), // HasComponent
@@ -407,7 +805,9 @@ func (c *opcuaClient) findGetRecordsMethod(ctx context.Context, logObjectID *ua.
 			NodesToBrowse: []*ua.BrowseDescription{desc},
 		}
 
-		resp, err := c.client.Browse(ctx, req)
+		start := time.Now()
+		resp, err := client.Browse(ctx, req)
+		c.recordBrowseDuration(ctx, "Browse", start)
 		if err != nil {
 			c.logger.Debug("Browse for GetRecords failed", zap.Error(err))
 			continue
@@ -429,7 +829,7 @@ func (c *opcuaClient) findGetRecordsMethod(ctx context.Context, logObjectID *ua.
 		}
 	}
 
-	return nil, fmt.Errorf("GetRecords method not found under %s", logObjectID.String())
+	return nil, newDiscoveryError(logObjectID.String(), fmt.Errorf("GetRecords method not found under %s", logObjectID.String()))
 }
 
 // readLogRecords reads log records from the OPC UA server
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"github.com/gopcua/opcua"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultApplicationName is the application name announced without
+	// client_identity.application_name, followed by the collector version
+	defaultApplicationName = "otelcol-opcua"
+
+	// defaultProductURI is the product URI announced without client_identity.product_uri
+	defaultProductURI = "urn:opentelemetry:collector:opcua-receiver"
+)
+
+// clientIdentity is the application description and session name the
+// client announces in CreateSession
+type clientIdentity struct {
+	applicationName string
+	productURI      string
+	sessionName     string
+}
+
+// newClientIdentity resolves the configured identity, filling in the defaults.
+// version is the collector version and may be empty.
+func newClientIdentity(cfg ClientIdentityConfig, version string) clientIdentity {
+	id := clientIdentity{
+		applicationName: cfg.ApplicationName,
+		productURI:      cfg.ProductURI,
+		sessionName:     cfg.SessionName,
+	}
+	if id.applicationName == "" {
+		id.applicationName = defaultApplicationName
+		if version != "" {
+			id.applicationName += "/" + version
+		}
+	}
+	if id.productURI == "" {
+		id.productURI = defaultProductURI
+	}
+	if id.sessionName == "" {
+		id.sessionName = id.applicationName
+	}
+	return id
+}
+
+// options returns the client options announcing the identity. The
+// application URI is left to the application certificate, which it must match.
+func (id clientIdentity) options() []opcua.Option {
+	return []opcua.Option{
+		opcua.ApplicationName(id.applicationName),
+		opcua.ProductURI(id.productURI),
+		opcua.SessionName(id.sessionName),
+	}
+}
+
+// logSession logs the identity and the session parameters negotiated with the
+// server at debug level. c.mu must be held.
+func (c *opcuaClient) logSession() {
+	fields := []zap.Field{
+		zap.String("application_name", c.identity.applicationName),
+		zap.String("product_uri", c.identity.productURI),
+		zap.String("session_name", c.identity.sessionName),
+	}
+	if session := c.client.Session(); session != nil {
+		fields = append(fields, zap.Duration("session_timeout", session.RevisedTimeout()))
+	}
+	c.logger.Debug("OPC UA session established", fields...)
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestNewClientIdentity(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ClientIdentityConfig
+		version  string
+		expected clientIdentity
+	}{
+		{
+			name:    "defaults",
+			version: "0.145.0",
+			expected: clientIdentity{
+				applicationName: "otelcol-opcua/0.145.0",
+				productURI:      "urn:opentelemetry:collector:opcua-receiver",
+				sessionName:     "otelcol-opcua/0.145.0",
+			},
+		},
+		{
+			name: "defaults without version",
+			expected: clientIdentity{
+				applicationName: "otelcol-opcua",
+				productURI:      "urn:opentelemetry:collector:opcua-receiver",
+				sessionName:     "otelcol-opcua",
+			},
+		},
+		{
+			name:    "application name",
+			config:  ClientIdentityConfig{ApplicationName: "Line 3 log collector"},
+			version: "0.145.0",
+			expected: clientIdentity{
+				applicationName: "Line 3 log collector",
+				productURI:      "urn:opentelemetry:collector:opcua-receiver",
+				sessionName:     "Line 3 log collector",
+			},
+		},
+		{
+			name: "configured",
+			config: ClientIdentityConfig{
+				ApplicationName: "Line 3 log collector",
+				ProductURI:      "urn:example:collector",
+				SessionName:     "line3-logs",
+			},
+			expected: clientIdentity{
+				applicationName: "Line 3 log collector",
+				productURI:      "urn:example:collector",
+				sessionName:     "line3-logs",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, newClientIdentity(tt.config, tt.version))
+		})
+	}
+}
+
+func TestClientWireSessionIdentity(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+
+	core, observed := observer.New(zap.DebugLevel)
+	cfg := ws.newWireConfig()
+	cfg.ClientIdentity.SessionName = "line3-logs"
+
+	c := newOPCUAClient(cfg, zap.New(core))
+	c.identity = newClientIdentity(cfg.ClientIdentity, "0.145.0")
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	sessions := observed.FilterMessage("OPC UA session established").All()
+	require.Len(t, sessions, 1)
+	fields := sessions[0].ContextMap()
+	assert.Equal(t, "otelcol-opcua/0.145.0", fields["application_name"])
+	assert.Equal(t, "urn:opentelemetry:collector:opcua-receiver", fields["product_uri"])
+	assert.Equal(t, "line3-logs", fields["session_name"])
+	assert.Contains(t, fields, "session_timeout")
+}
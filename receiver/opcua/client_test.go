@@ -0,0 +1,1214 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/recording"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// wireRecords returns records spread over one hour with every optional field populated
+func wireRecords(n int) []testdata.OPCUALogRecord {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	records := make([]testdata.OPCUALogRecord, n)
+	for i := range records {
+		records[i] = testdata.OPCUALogRecord{
+			Timestamp:       base.Add(time.Duration(i) * time.Minute),
+			Severity:        uint16(1 + (i*97)%1000), //nolint:gosec
+			Message:         "wire record",
+			SourceName:      "Pump",
+			SourceNamespace: 1,
+			SourceIDType:    "Numeric",
+			SourceID:        "100",
+			TraceID:         "0102030405060708090a0b0c0d0e0f10",
+			SpanID:          "0102030405060708",
+			TraceFlags:      1,
+			Attributes:      map[string]interface{}{"component": "pump"},
+		}
+	}
+	return records
+}
+
+func TestClientWireSelectEndpoint(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+
+	endpoints, err := opcua.GetEndpoints(ctx, ws.endpoint)
+	require.NoError(t, err)
+	require.NotEmpty(t, endpoints)
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	ep := c.selectEndpoint(endpoints)
+	require.NotNil(t, ep)
+	assert.Equal(t, ua.SecurityPolicyURINone, ep.SecurityPolicyURI)
+	assert.Equal(t, ua.MessageSecurityModeNone, ep.SecurityMode)
+}
+
+func TestClientWireConnectAndDiscover(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	assert.True(t, c.IsConnected())
+	require.Len(t, c.logObjectIDs, 1)
+	assert.True(t, ws.logObjectID.Equal(c.logObjectIDs[0]))
+
+	methodID, err := c.findGetRecordsMethod(ctx, c.logObjectIDs[0])
+	require.NoError(t, err)
+	assert.True(t, ws.methodID.Equal(methodID))
+}
+
+func TestClientWireDiscoverBrowsePath(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+	ws.AddBrowsePath("2:DeviceSet/3:PLC1/2:DeviceLog", ws.logObjectID)
+
+	core, logs := observer.New(zap.WarnLevel)
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{"Objects/2:DeviceSet/3:PLC1/2:DeviceLog", "Objects/2:DeviceSet/3:PLC2/2:DeviceLog"}
+	c := newOPCUAClient(cfg, zap.New(core))
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	require.Len(t, c.logObjectIDs, 1)
+	assert.True(t, ws.logObjectID.Equal(c.logObjectIDs[0]))
+
+	// The path the server does not know is skipped with a warning
+	unresolved := logs.FilterMessage("Failed to resolve LogObject path").All()
+	require.Len(t, unresolved, 1)
+	assert.Equal(t, "Objects/2:DeviceSet/3:PLC2/2:DeviceLog", unresolved[0].ContextMap()["path"])
+	assert.Contains(t, unresolved[0].ContextMap()["error"], "BadNoMatch")
+}
+
+func TestIsNodeID(t *testing.T) {
+	for _, s := range []string{"ns=2;i=1000", "i=2042", "s=PLC1/DeviceLog", "g=72962B91-FA75-4AE6-8D28-B404DC7DAF63", "b=YWJj"} {
+		assert.True(t, isNodeID(s), s)
+	}
+	// ua.ParseNodeID accepts these as string NodeIDs in namespace 0
+	for _, s := range []string{"Objects/ServerLog", "2:DeviceSet/3:PLC1/2:DeviceLog", "ServerLog"} {
+		assert.False(t, isNodeID(s), s)
+	}
+}
+
+func TestParseBrowsePath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected []*ua.QualifiedName
+		wantErr  string
+	}{
+		{
+			path:     "Objects/2:Machine/2:Temperature",
+			expected: []*ua.QualifiedName{{NamespaceIndex: 2, Name: "Machine"}, {NamespaceIndex: 2, Name: "Temperature"}},
+		},
+		{
+			path:     "/Server/ServerStatus/",
+			expected: []*ua.QualifiedName{{Name: "Server"}, {Name: "ServerStatus"}},
+		},
+		{
+			path:     "3:Line:1/Counter",
+			expected: []*ua.QualifiedName{{NamespaceIndex: 3, Name: "Line:1"}, {Name: "Counter"}},
+		},
+		{
+			path:     "Axis:X",
+			expected: []*ua.QualifiedName{{Name: "Axis:X"}},
+		},
+		{
+			path:    "Objects",
+			wantErr: "no elements below Objects",
+		},
+		{
+			path:    "2:Machine//Temperature",
+			wantErr: "empty element",
+		},
+		{
+			path:    "2:",
+			wantErr: "empty element",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			names, err := parseBrowsePath(tt.path)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestClientWireGetRecords(t *testing.T) {
+	ws := startWireServer(t)
+	records := wireRecords(3)
+	records[0].ParentSpanID = "1112131415161718"
+	ws.AddLogRecords(records)
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	got, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+
+	first := got[0]
+	assert.True(t, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC).Equal(first.Timestamp))
+	assert.Equal(t, uint16(1), first.Severity)
+	assert.Equal(t, "wire record", first.Message)
+	assert.Equal(t, "Pump", first.SourceName)
+	assert.Equal(t, uint16(1), first.SourceNamespace)
+	assert.Equal(t, "Numeric", first.SourceIDType)
+	assert.Equal(t, "100", first.SourceID)
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", first.TraceID)
+	assert.Equal(t, "0102030405060708", first.SpanID)
+	assert.Equal(t, "1112131415161718", first.ParentSpanID)
+	assert.Empty(t, got[1].ParentSpanID)
+	assert.Equal(t, "pump", first.Attributes["component"])
+	assert.Equal(t, ws.logObjectID.String(), first.LogObjectID)
+}
+
+func TestClientWireVendorAdditionalData(t *testing.T) {
+	ws := startWireServer(t)
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	records := testdata.NewSeededGenerator(34, base).VendorRecords(12)
+	// Distinct timestamps identify the records on the client side
+	for i := range records {
+		records[i].Timestamp = base.Add(time.Duration(i) * time.Minute)
+	}
+	ws.AddLogRecords(records)
+	ws.SetMaxPageSize(5)
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	got, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+	require.NoError(t, err)
+	require.Len(t, got, len(records))
+
+	byTime := make(map[time.Time]testdata.OPCUALogRecord, len(records))
+	for _, record := range records {
+		byTime[record.Timestamp.UTC()] = record
+	}
+	for _, record := range got {
+		want, ok := byTime[record.Timestamp.UTC()]
+		require.True(t, ok, "unexpected record at %s", record.Timestamp)
+		assert.Equal(t, want.Message, record.Message)
+		require.Len(t, record.Attributes, len(want.Attributes))
+		for k, v := range want.Attributes {
+			require.Contains(t, record.Attributes, k)
+			if isVariantScalar(v) {
+				assert.Equal(t, v, record.Attributes[k], "key %q mismatch", k)
+			}
+		}
+	}
+}
+
+func TestClientWirePagination(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(10))
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	var pages []int
+	var continuationPoint []byte
+	for {
+		records, next, err := c.callGetRecordsMethod(
+			ctx, c.logObjectIDs[0], time.Time{}, time.Now(), 4, 1, continuationPoint)
+		require.NoError(t, err)
+		pages = append(pages, len(records))
+		if len(next) == 0 {
+			break
+		}
+		continuationPoint = next
+	}
+	assert.Equal(t, []int{4, 4, 2}, pages)
+
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+	require.NoError(t, err)
+	assert.Len(t, records, 10)
+}
+
+func TestClientWireCarriedContinuationPoint(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(10))
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	// Calls with the same window continue where the budget ran out
+	end := time.Now()
+	var timestamps []time.Time
+	var sizes []int
+	for range 3 {
+		records, err := c.GetRecords(ctx, time.Time{}, end, 4)
+		require.NoError(t, err)
+		sizes = append(sizes, len(records))
+		for _, record := range records {
+			timestamps = append(timestamps, record.Timestamp)
+		}
+		if !c.unfinishedQuery() {
+			break
+		}
+	}
+	assert.Equal(t, []int{4, 4, 2}, sizes)
+	assert.Len(t, timestamps, 10)
+	for i := 1; i < len(timestamps); i++ {
+		assert.True(t, timestamps[i].After(timestamps[i-1]), "record %d read twice or out of order", i)
+	}
+
+	requests := ws.Requests()
+	require.Len(t, requests, 3)
+	assert.Empty(t, requests[0].ContinuationPoint)
+	assert.NotEmpty(t, requests[1].ContinuationPoint)
+	assert.NotEmpty(t, requests[2].ContinuationPoint)
+
+	// Another window starts over
+	_, err := c.GetRecords(ctx, time.Time{}, end, 4)
+	require.NoError(t, err)
+	require.True(t, c.unfinishedQuery())
+	_, err = c.GetRecords(ctx, time.Time{}, end.Add(time.Second), 4)
+	require.NoError(t, err)
+	last, ok := ws.LastRequest()
+	require.True(t, ok)
+	assert.Empty(t, last.ContinuationPoint)
+
+	// A new session drops the continuation points of the old one
+	require.True(t, c.unfinishedQuery())
+	require.NoError(t, c.Connect(ctx))
+	assert.False(t, c.unfinishedQuery())
+}
+
+func TestClientWireSeverityFilter(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(20))
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.Filter.MinSeverity = "Error"
+	c := newOPCUAClient(cfg, zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	minSeverity := c.getMinSeverityValue()
+	for _, r := range records {
+		assert.GreaterOrEqual(t, r.Severity, minSeverity)
+	}
+}
+
+func TestScraperWire(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(5))
+	ctx := context.Background()
+
+	settings := componenttest.NewNopTelemetrySettings()
+	scr, err := newScraper(ws.newWireConfig(), settings)
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, logs.LogRecordCount())
+
+	// The second scrape only covers the time since the first one
+	logs, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.LogRecordCount())
+}
+
+func TestScraperWireMaxLogRecords(t *testing.T) {
+	ws := startWireServer(t)
+	ws.SetMaxPageSize(3)
+	ws.AddLogRecords(wireRecords(10))
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.Filter.MaxLogRecords = 4
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// The records over the cap are read by the next scrapes
+	var counts []int
+	for i := 0; i < 4; i++ {
+		logs, err := scr.scrape(ctx)
+		require.NoError(t, err)
+		counts = append(counts, logs.LogRecordCount())
+	}
+	assert.Equal(t, []int{4, 4, 2, 0}, counts)
+}
+
+func TestScraperWireMaxLogRecordsManyLogObjects(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(2))
+	paths := []string{ws.logObjectID.String()}
+	for i := 2; i <= 5; i++ {
+		logObjectID := ua.NewNumericNodeID(1, uint32(i*1000))
+		require.NoError(t, ws.AddLogObject(logObjectID, ua.NewNumericNodeID(1, uint32(i*1000+1))))
+		require.NoError(t, ws.AddLogRecordsTo(logObjectID, wireRecords(2)...))
+		paths = append(paths, logObjectID.String())
+	}
+	ctx := context.Background()
+
+	core, observed := observer.New(zap.WarnLevel)
+	settings := componenttest.NewNopTelemetrySettings()
+	settings.Logger = zap.New(core)
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = paths
+	cfg.Filter.MaxLogRecords = 3
+	scr, err := newScraper(cfg, settings)
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// With fewer records allowed than LogObjects, the LogObjects the budget
+	// does not reach are read by the next scrapes instead of over-reading
+	total := 0
+	for i := 0; i < 10; i++ {
+		logs, err := scr.scrape(ctx)
+		require.NoError(t, err)
+		count := logs.LogRecordCount()
+		assert.LessOrEqual(t, count, 3)
+		if count == 0 {
+			break
+		}
+		total += count
+	}
+	assert.Equal(t, 10, total)
+	assert.Empty(t, observed.FilterMessageSnippet("max_log_records").All(), "no record is truncated")
+}
+
+func TestScraperWireStatusWriteBack(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+
+	core, observed := observer.New(zap.WarnLevel)
+	settings := componenttest.NewNopTelemetrySettings()
+	settings.Logger = zap.New(core)
+	cfg := ws.newWireConfig()
+	cfg.StatusWriteBack.NodeID = ws.statusNodeID.String()
+	cfg.StatusWriteBack.Interval = time.Minute
+
+	scr, err := newScraper(cfg, settings)
+	require.NoError(t, err)
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// The first successful collection is written, the next one within the
+	// interval is not
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{t0, t0.Add(time.Minute)}, ws.StatusWrites())
+	assert.Empty(t, observed.All())
+
+	// A rejected write is logged and does not fail the scrape
+	cfg.StatusWriteBack.NodeID = "ns=1;i=2001"
+	clk.Advance(time.Minute)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	warnings := observed.FilterMessage("Failed to write collection status to the server").All()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].ContextMap()["error"], "StatusBadNodeIDUnknown")
+	assert.Len(t, ws.StatusWrites(), 2)
+}
+
+func TestScraperWireSeverityDictionary(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i, severity := range []uint16{10, 20, 30, 450} {
+		ws.AddLogRecords([]testdata.OPCUALogRecord{
+			{Timestamp: base.Add(time.Duration(i) * time.Second), Severity: severity, Message: "proprietary severity"},
+		})
+	}
+	ws.SetSeverityDictionary(ua.MustVariant([]*ua.ExtensionObject{
+		ua.NewExtensionObject(&ua.EnumValueType{Value: 10, DisplayName: ua.NewLocalizedText("Info")}),
+		ua.NewExtensionObject(&ua.EnumValueType{Value: 20, DisplayName: ua.NewLocalizedText("Warnung")}),
+		ua.NewExtensionObject(&ua.EnumValueType{Value: 30, DisplayName: ua.NewLocalizedText("Error")}),
+	}))
+
+	cfg := ws.newWireConfig()
+	cfg.SeverityDictionary.NodeID = ws.dictionaryNodeID.String()
+	cfg.SeverityDictionary.Entries = []SeverityDictionaryEntry{{Severity: 30, Text: "Stop", Number: 21}}
+
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 4, lrs.Len())
+
+	// Server entries with well-known names, server entries with unknown
+	// names, configured overrides and values outside the dictionary
+	expected := []struct {
+		number plog.SeverityNumber
+		text   string
+	}{
+		{plog.SeverityNumberInfo, "Info"},
+		{plog.SeverityNumberDebug, "Warnung"},
+		{plog.SeverityNumberFatal, "Stop"},
+		{plog.SeverityNumberFatal, "Emergency"},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.number, lrs.At(i).SeverityNumber(), "record %d", i)
+		assert.Equal(t, e.text, lrs.At(i).SeverityText(), "record %d", i)
+	}
+}
+
+func TestScraperWireSeverityDictionaryUnreadable(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords([]testdata.OPCUALogRecord{
+		{Timestamp: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), Severity: 30, Message: "proprietary severity"},
+	})
+	ctx := context.Background()
+
+	core, observed := observer.New(zap.WarnLevel)
+	settings := componenttest.NewNopTelemetrySettings()
+	settings.Logger = zap.New(core)
+	cfg := ws.newWireConfig()
+	cfg.SeverityDictionary.NodeID = ws.dictionaryNodeID.String()
+	cfg.SeverityDictionary.Entries = []SeverityDictionaryEntry{{Severity: 30, Text: "Warning"}}
+
+	scr, err := newScraper(cfg, settings)
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	warnings := observed.FilterMessage("Failed to read the severity dictionary from the server, using the configured entries").All()
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0].ContextMap()["error"], "StatusBadNodeIDUnknown")
+
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, plog.SeverityNumberWarn, lr.SeverityNumber())
+	assert.Equal(t, "Warning", lr.SeverityText())
+}
+
+func TestClientWireFaults(t *testing.T) {
+	tests := []struct {
+		name          string
+		fault         testdata.Fault
+		expectedPages []int
+	}{
+		{
+			name:          "too many operations",
+			fault:         testdata.Fault{Kind: testdata.FaultTooManyOperations, Call: 1},
+			expectedPages: nil,
+		},
+		{
+			name:          "secure channel closed mid-pagination",
+			fault:         testdata.Fault{Kind: testdata.FaultSecureChannelClosed, Call: 2},
+			expectedPages: []int{4},
+		},
+		{
+			name:          "malformed record body",
+			fault:         testdata.Fault{Kind: testdata.FaultMalformedRecord, Call: 1},
+			expectedPages: nil,
+		},
+		{
+			name:          "slow response within request timeout",
+			fault:         testdata.Fault{Kind: testdata.FaultSlowResponse, Delay: 100 * time.Millisecond},
+			expectedPages: []int{4, 4, 2},
+		},
+		{
+			name:          "request timeout",
+			fault:         testdata.Fault{Kind: testdata.FaultTimeout, Call: 1, Delay: 3 * time.Second},
+			expectedPages: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			ws.AddLogRecords(wireRecords(10))
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.RequestTimeout = time.Second
+			c := newOPCUAClient(cfg, zap.NewNop())
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				_ = c.Disconnect(ctx)
+			}()
+
+			ws.InjectFault(tt.fault)
+
+			var pages []int
+			var continuationPoint []byte
+			for {
+				records, next, err := c.callGetRecordsMethod(
+					ctx, c.logObjectIDs[0], time.Time{}, time.Now(), 4, 1, continuationPoint)
+				if err != nil {
+					break
+				}
+				pages = append(pages, len(records))
+				if len(next) == 0 {
+					break
+				}
+				continuationPoint = next
+			}
+
+			assert.Equal(t, tt.expectedPages, pages)
+		})
+	}
+}
+
+func TestClientWireContinuationPointExpired(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry testdata.ContinuationPointExpiry
+		// expire runs between the first page and the call using its continuation point
+		expire func(t *testing.T, ws *wireServer, c *opcuaClient)
+	}{
+		{
+			name:   "after N calls",
+			expiry: testdata.ContinuationPointExpiry{MaxCalls: 1},
+			expire: func(t *testing.T, _ *wireServer, c *opcuaClient) {
+				// Another reader's call in between uses up the continuation point's budget
+				_, _, err := c.callGetRecordsMethod(context.Background(), c.logObjectIDs[0], time.Time{}, time.Now(), 4, 1, nil)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name:   "after TTL",
+			expiry: testdata.ContinuationPointExpiry{TTL: 50 * time.Millisecond},
+			expire: func(*testing.T, *wireServer, *opcuaClient) {
+				time.Sleep(100 * time.Millisecond)
+			},
+		},
+		{
+			name: "invalidated by server",
+			expire: func(_ *testing.T, ws *wireServer, _ *opcuaClient) {
+				ws.InvalidateContinuationPoints()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			ws.AddLogRecords(wireRecords(10))
+			ws.SetContinuationPointExpiry(tt.expiry)
+			ctx := context.Background()
+
+			c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+
+			first, cp, err := c.callGetRecordsMethod(ctx, c.logObjectIDs[0], time.Time{}, time.Now(), 4, 1, nil)
+			require.NoError(t, err)
+			require.NotEmpty(t, cp)
+
+			tt.expire(t, ws, c)
+			callsBefore := ws.CallCount()
+
+			// The expired continuation point is rejected without a retry;
+			// restarting the read is up to readLogObject
+			records, next, err := c.callGetRecordsMethod(ctx, c.logObjectIDs[0], time.Time{}, time.Now(), 4, 1, cp)
+			require.ErrorIs(t, err, errContinuationPointInvalid)
+			assert.Equal(t, 1, ws.CallCount()-callsBefore)
+			assert.Empty(t, records)
+			assert.Empty(t, next)
+			assert.Len(t, first, 4)
+		})
+	}
+}
+
+// TestClientWireContinuationPointAlwaysRejected verifies that a server
+// rejecting every continuation point fails the read after a bounded number of
+// restarts instead of retrying forever, and that a rejection without a
+// continuation point is not retried
+func TestClientWireContinuationPointAlwaysRejected(t *testing.T) {
+	rejected := func(call int) testdata.Fault {
+		return testdata.Fault{Kind: testdata.FaultStatus, Call: call, Status: ua.StatusBadContinuationPointInvalid}
+	}
+
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(10))
+	ws.SetMaxPageSize(4)
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	// Every restart reads the first page again and is rejected on the second
+	callsBefore := ws.CallCount()
+	for call := 2; call <= 2*(1+maxPaginationRestarts); call += 2 {
+		ws.InjectFault(rejected(callsBefore + call))
+	}
+	_, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+	require.ErrorIs(t, err, errContinuationPointInvalid)
+	assert.Equal(t, 2*(1+maxPaginationRestarts), ws.CallCount()-callsBefore)
+
+	// A first page rejected as if it had a continuation point skips the
+	// LogObject instead of repeating the same request
+	callsBefore = ws.CallCount()
+	ws.InjectFault(rejected(callsBefore + 1))
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Equal(t, 1, ws.CallCount()-callsBefore)
+
+	// A cancelled context ends the backoff
+	callsBefore = ws.CallCount()
+	ws.InjectFault(rejected(callsBefore + 2))
+	waiting, cancel := context.WithTimeout(ctx, paginationRestartBackoff/2)
+	defer cancel()
+	_, err = c.GetRecords(waiting, time.Time{}, time.Now(), 100)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientWireResponseTooLarge(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(20))
+	ws.SetMaxResponseRecords(6)
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	// MaxReturnRecords is halved from 16 to 8 to 4, which the server accepts
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 16)
+	require.NoError(t, err)
+	assert.Len(t, records, 16)
+	assert.Equal(t, uint32(4), c.pageSize(ws.logObjectID.String(), 16))
+
+	// The next read starts with the size that worked
+	callsBefore := ws.CallCount()
+	records, err = c.GetRecords(ctx, time.Time{}, time.Now(), 20)
+	require.NoError(t, err)
+	assert.Len(t, records, 20)
+	assert.Equal(t, 5, ws.CallCount()-callsBefore)
+}
+
+func TestClientWireResponseTooLargeAtOneRecord(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(5))
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, Status: ua.StatusBadResponseTooLarge})
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	var reads []logObjectRead
+	c.onLogObjectRead = func(read logObjectRead) { reads = append(reads, read) }
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	// Halving stops at one record, after which the LogObject is skipped
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 8)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Equal(t, 4, ws.CallCount())
+	require.Len(t, reads, 1)
+	require.ErrorIs(t, reads[0].err, ua.StatusBadResponseTooLarge)
+}
+
+func TestClientWireContinuationPointWithinExpiry(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(10))
+	ws.SetContinuationPointExpiry(testdata.ContinuationPointExpiry{MaxCalls: 1, TTL: time.Minute})
+	ctx := context.Background()
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	var pages []int
+	var continuationPoint []byte
+	for {
+		records, next, err := c.callGetRecordsMethod(
+			ctx, c.logObjectIDs[0], time.Time{}, time.Now(), 4, 1, continuationPoint)
+		require.NoError(t, err)
+		pages = append(pages, len(records))
+		if len(next) == 0 {
+			break
+		}
+		continuationPoint = next
+	}
+	assert.Equal(t, []int{4, 4, 2}, pages)
+	assert.Equal(t, 3, ws.CallCount(), "no continuation point should have been rejected")
+}
+
+func TestClientWireMultipleLogObjects(t *testing.T) {
+	secondObjectID := ua.NewNumericNodeID(1, 2000)
+	secondMethodID := ua.NewNumericNodeID(1, 2001)
+
+	tests := []struct {
+		name           string
+		fault          *testdata.Fault
+		expectedCounts map[string]int
+	}{
+		{
+			name: "quota split across nodes",
+			expectedCounts: map[string]int{
+				"ns=1;i=1000": 5,
+				"ns=1;i=2000": 5,
+			},
+		},
+		{
+			// The quota of the skipped node goes to the healthy one
+			name:  "failure on one node",
+			fault: &testdata.Fault{Kind: testdata.FaultTooManyOperations, LogObject: secondObjectID},
+			expectedCounts: map[string]int{
+				"ns=1;i=1000": 10,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			require.NoError(t, ws.AddLogObject(secondObjectID, secondMethodID))
+			ws.AddLogRecords(wireRecords(10))
+			require.NoError(t, ws.AddLogRecordsTo(secondObjectID, wireRecords(10)...))
+			if tt.fault != nil {
+				ws.InjectFault(*tt.fault)
+			}
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.LogObjectPaths = []string{ws.logObjectID.String(), secondObjectID.String()}
+			c := newOPCUAClient(cfg, zap.NewNop())
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 10)
+			require.NoError(t, err)
+
+			counts := map[string]int{}
+			for _, r := range records {
+				counts[r.LogObjectID]++
+			}
+			assert.Equal(t, tt.expectedCounts, counts)
+		})
+	}
+}
+
+// TestClientWireQuotaRedistribution verifies that quota unused by LogObjects
+// with few records goes to LogObjects that have more, in both directions
+func TestClientWireQuotaRedistribution(t *testing.T) {
+	secondObjectID := ua.NewNumericNodeID(1, 2000)
+	thirdObjectID := ua.NewNumericNodeID(1, 3000)
+
+	tests := []struct {
+		name           string
+		counts         [3]int
+		maxRecords     int
+		expectedCounts map[string]int
+	}{
+		{
+			name:           "sparse node first",
+			counts:         [3]int{1, 20, 20},
+			maxRecords:     12,
+			expectedCounts: map[string]int{"ns=1;i=1000": 1, "ns=1;i=2000": 5, "ns=1;i=3000": 6},
+		},
+		{
+			name:           "sparse node last",
+			counts:         [3]int{20, 20, 1},
+			maxRecords:     12,
+			expectedCounts: map[string]int{"ns=1;i=1000": 5, "ns=1;i=2000": 6, "ns=1;i=3000": 1},
+		},
+		{
+			name:           "budget above all records",
+			counts:         [3]int{3, 4, 5},
+			maxRecords:     100,
+			expectedCounts: map[string]int{"ns=1;i=1000": 3, "ns=1;i=2000": 4, "ns=1;i=3000": 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			ws.SetMaxPageSize(2)
+			require.NoError(t, ws.AddLogObject(secondObjectID, ua.NewNumericNodeID(1, 2001)))
+			require.NoError(t, ws.AddLogObject(thirdObjectID, ua.NewNumericNodeID(1, 3001)))
+			ws.AddLogRecords(wireRecords(tt.counts[0]))
+			require.NoError(t, ws.AddLogRecordsTo(secondObjectID, wireRecords(tt.counts[1])...))
+			require.NoError(t, ws.AddLogRecordsTo(thirdObjectID, wireRecords(tt.counts[2])...))
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.LogObjectPaths = []string{ws.logObjectID.String(), secondObjectID.String(), thirdObjectID.String()}
+			c := newOPCUAClient(cfg, zap.NewNop())
+			var reads []logObjectRead
+			c.onLogObjectRead = func(read logObjectRead) { reads = append(reads, read) }
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), tt.maxRecords)
+			require.NoError(t, err)
+
+			counts := map[string]int{}
+			for _, r := range records {
+				counts[r.LogObjectID]++
+			}
+			assert.Equal(t, tt.expectedCounts, counts)
+
+			// Each LogObject is reported once, with the records of both passes
+			require.Len(t, reads, 3)
+			for _, read := range reads {
+				assert.Equal(t, tt.expectedCounts[read.logObjectID], read.records, read.logObjectID)
+			}
+		})
+	}
+}
+
+func TestClientWireSecureSelectEndpoint(t *testing.T) {
+	ws := startWireServer(t, withSecureEndpoints())
+	ctx := context.Background()
+
+	endpoints, err := opcua.GetEndpoints(ctx, ws.endpoint)
+	require.NoError(t, err)
+	require.Len(t, endpoints, 3)
+
+	tests := []struct {
+		policy       string
+		mode         string
+		expectedURI  string
+		expectedMode ua.MessageSecurityMode
+	}{
+		{"None", "None", ua.SecurityPolicyURINone, ua.MessageSecurityModeNone},
+		{"Basic256Sha256", "Sign", ua.SecurityPolicyURIBasic256Sha256, ua.MessageSecurityModeSign},
+		{"Basic256Sha256", "SignAndEncrypt", ua.SecurityPolicyURIBasic256Sha256, ua.MessageSecurityModeSignAndEncrypt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+tt.mode, func(t *testing.T) {
+			cfg := ws.newWireConfig()
+			cfg.SecurityPolicy = tt.policy
+			cfg.SecurityMode = tt.mode
+
+			ep := newOPCUAClient(cfg, zap.NewNop()).selectEndpoint(endpoints)
+			require.NotNil(t, ep)
+			assert.Equal(t, tt.expectedURI, ep.SecurityPolicyURI)
+			assert.Equal(t, tt.expectedMode, ep.SecurityMode)
+			if tt.expectedMode != ua.MessageSecurityModeNone {
+				assert.Equal(t, ws.cert.Raw, ep.ServerCertificate)
+			}
+		})
+	}
+}
+
+func TestClientWireSecureSession(t *testing.T) {
+	ws := startWireServer(t, withSecureEndpoints())
+	ws.AddLogRecords(wireRecords(3))
+
+	clientCert, clientKey := newTestCertificate(t, "opcua-receiver", false, nil, nil)
+	stranger, _ := newTestCertificate(t, "stranger", true, nil, nil)
+
+	tests := []struct {
+		name    string
+		mode    string
+		trusted []*x509.Certificate
+		wantErr bool
+	}{
+		{name: "sign", mode: "Sign", trusted: []*x509.Certificate{ws.cert}},
+		{name: "sign and encrypt", mode: "SignAndEncrypt", trusted: []*x509.Certificate{ws.cert}},
+		{name: "untrusted server certificate", mode: "SignAndEncrypt", trusted: []*x509.Certificate{stranger}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := ws.newWireConfig()
+			cfg.SecurityPolicy = "Basic256Sha256"
+			cfg.SecurityMode = tt.mode
+
+			c := newOPCUAClient(cfg, zap.NewNop())
+			c.certProvider = &testCertProvider{cert: clientCert.Raw, key: clientKey, trusted: tt.trusted}
+
+			err := c.Connect(ctx)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.False(t, c.IsConnected())
+				return
+			}
+			require.NoError(t, err)
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+			require.NoError(t, err)
+			assert.Len(t, records, 3)
+		})
+	}
+}
+
+func TestClientWireRequestCapture(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(10))
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.Filter.MinSeverity = "Error"
+	c := newOPCUAClient(cfg, zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	records, err := c.GetRecords(ctx, start, end, 1000)
+	require.NoError(t, err)
+	assert.Len(t, records, 6)
+
+	req, ok := ws.LastRequest()
+	require.True(t, ok)
+	assert.True(t, ws.logObjectID.Equal(req.ObjectID))
+	assert.True(t, ws.methodID.Equal(req.MethodID))
+	assert.True(t, start.Equal(req.StartTime))
+	assert.True(t, end.Equal(req.EndTime))
+	assert.Equal(t, uint32(1000), req.MaxRecords)
+	assert.Equal(t, uint16(301), req.MinSeverity)
+	assert.Equal(t, uint32(0x1F), req.LogRecordMask)
+	assert.Empty(t, req.ContinuationPoint)
+
+	// Each page must carry the continuation point returned by the previous one
+	ws.ClearRequests()
+	var returned [][]byte
+	var continuationPoint []byte
+	for {
+		_, next, err := c.callGetRecordsMethod(ctx, c.logObjectIDs[0], start, end, 2, 301, continuationPoint)
+		require.NoError(t, err)
+		if len(next) == 0 {
+			break
+		}
+		returned = append(returned, next)
+		continuationPoint = next
+	}
+
+	requests := ws.RequestsFor(ws.logObjectID)
+	require.Len(t, requests, 3)
+	assert.Empty(t, requests[0].ContinuationPoint)
+	assert.Equal(t, returned[0], requests[1].ContinuationPoint)
+	assert.Equal(t, returned[1], requests[2].ContinuationPoint)
+	assert.Len(t, ws.Requests(), 3)
+}
+
+func TestClientWireRecordFields(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(3))
+
+	tests := []struct {
+		name     string
+		modify   func(fields *RecordFieldsConfig)
+		wantMask uint32
+	}{
+		{
+			name:     "all fields by default",
+			modify:   func(*RecordFieldsConfig) {},
+			wantMask: 0x1F,
+		},
+		{
+			name: "without trace context",
+			modify: func(fields *RecordFieldsConfig) {
+				fields.TraceContext = false
+			},
+			wantMask: 0x17,
+		},
+		{
+			name: "no optional fields",
+			modify: func(fields *RecordFieldsConfig) {
+				*fields = RecordFieldsConfig{}
+			},
+			wantMask: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := ws.newWireConfig()
+			tt.modify(&cfg.RecordFields)
+			c := newOPCUAClient(cfg, zap.NewNop())
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+
+			ws.ClearRequests()
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+			require.NoError(t, err)
+			assert.Len(t, records, 3)
+
+			req, ok := ws.LastRequest()
+			require.True(t, ok)
+			assert.Equal(t, tt.wantMask, req.LogRecordMask)
+		})
+	}
+}
+
+func TestClientWireCaptureAndReplay(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	ctx := context.Background()
+
+	// exchange pages through the records, then a failed method call and a
+	// failed Call service, and returns what the client saw
+	exchange := func(t *testing.T, c *opcuaClient) ([][]string, [][]byte, []error) {
+		var pages [][]string
+		var continuationPoints [][]byte
+		var continuationPoint []byte
+		for {
+			records, next, err := c.callGetRecordsMethod(ctx, c.logObjectIDs[0], start, end, 4, 1, continuationPoint)
+			require.NoError(t, err)
+			var page []string
+			for _, r := range records {
+				page = append(page, fmt.Sprintf("%s %d %s %s", r.Timestamp.Format(time.RFC3339), r.Severity, r.Message, r.TraceID))
+			}
+			pages = append(pages, page)
+			continuationPoints = append(continuationPoints, next)
+			if len(next) == 0 {
+				break
+			}
+			continuationPoint = next
+		}
+
+		var errs []error
+		for i := 0; i < 2; i++ {
+			_, _, err := c.callGetRecordsMethod(ctx, c.logObjectIDs[0], start, end, 4, 1, nil)
+			errs = append(errs, err)
+		}
+		return pages, continuationPoints, errs
+	}
+
+	// Capture against a live server
+	live := startWireServer(t)
+	live.AddLogRecords(wireRecords(10))
+	live.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, Call: 4, Status: ua.StatusBadOutOfService})
+	live.InjectFault(testdata.Fault{Kind: testdata.FaultTooManyOperations, Call: 5})
+
+	dir := t.TempDir()
+	cfg := live.newWireConfig()
+	cfg.Capture.Directory = dir
+	c := newOPCUAClient(cfg, zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	wantPages, wantCPs, wantErrs := exchange(t, c)
+	require.NoError(t, c.Disconnect(ctx))
+	require.Len(t, wantPages, 3)
+	require.Len(t, wantErrs, 2)
+	require.Error(t, wantErrs[0])
+	require.Error(t, wantErrs[1])
+
+	files, err := filepath.Glob(filepath.Join(dir, "getrecords-*.jsonl"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	calls, err := recording.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Len(t, calls, 5)
+	assert.Equal(t, live.logObjectID.String(), calls[0].ObjectID)
+	assert.Equal(t, ua.StatusBadOutOfService.Error(), calls[3].Status)
+	assert.Empty(t, calls[4].Result)
+	assert.Equal(t, uint32(ua.StatusBadTooManyOperations), calls[4].ServiceStatus)
+
+	// Replay against an empty server: the client must see exactly the same
+	replay := startWireServer(t)
+	require.NoError(t, replay.LoadRecording(files[0]))
+	assert.Equal(t, 5, replay.ReplayRemaining())
+
+	c = newOPCUAClient(replay.newWireConfig(), zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+	gotPages, gotCPs, gotErrs := exchange(t, c)
+	assert.Equal(t, wantPages, gotPages)
+	assert.Equal(t, wantCPs, gotCPs)
+	require.Len(t, gotErrs, 2)
+	assert.Equal(t, wantErrs[0].Error(), gotErrs[0].Error())
+	assert.ErrorIs(t, gotErrs[1], ua.StatusBadTooManyOperations)
+	assert.Zero(t, replay.ReplayRemaining())
+	assert.Zero(t, replay.LogRecordsCountOf(replay.logObjectID))
+}
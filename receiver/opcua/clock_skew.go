@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// serverClock is implemented by clients that can read the current time of
+// the server
+type serverClock interface {
+	// serverTime reads Server/ServerStatus/CurrentTime
+	serverTime(ctx context.Context) (time.Time, error)
+}
+
+// serverTime reads Server/ServerStatus/CurrentTime
+func (c *opcuaClient) serverTime(ctx context.Context) (time.Time, error) {
+	session, err := c.session()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	resp, err := session.Read(ctx, &ua.ReadRequest{
+		TimestampsToReturn: ua.TimestampsToReturnNeither,
+		NodesToRead: []*ua.ReadValueID{
+			{NodeID: ua.NewNumericNodeID(0, id.Server_ServerStatus_CurrentTime), AttributeID: ua.AttributeIDValue},
+		},
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to read server time: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		return time.Time{}, errors.New("failed to read server time: no results returned")
+	}
+	result := resp.Results[0]
+	if result.Status != ua.StatusOK {
+		return time.Time{}, fmt.Errorf("failed to read server time: %w", result.Status)
+	}
+	if result.Value == nil {
+		return time.Time{}, errors.New("failed to read server time: no value returned")
+	}
+
+	t, ok := result.Value.Value().(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected server time type %T", result.Value.Value())
+	}
+	return t, nil
+}
+
+// clockSkew returns how far the server clock is ahead of the collector's,
+// negative if it is behind. The server time is compared to the middle of the
+// read, so the round trip does not count as skew. If the server time cannot
+// be read, the skew last measured is kept.
+func (s *scraper) clockSkew(ctx context.Context) time.Duration {
+	reader, ok := s.client.(serverClock)
+	if !ok {
+		return 0
+	}
+
+	before := s.now()
+	serverTime, err := reader.serverTime(ctx)
+	if err != nil {
+		s.errorLog.Warn(s.settings.Logger, "clock_skew", "Failed to read the server time, keeping the last clock skew",
+			zap.Duration("skew", time.Duration(s.skew.Load())),
+			zap.Error(err))
+		return time.Duration(s.skew.Load())
+	}
+	s.errorLog.Clear(s.settings.Logger, "clock_skew")
+	after := s.now()
+
+	skew := serverTime.Sub(before.Add(after.Sub(before) / 2))
+	s.skew.Store(int64(skew))
+	s.skewMeasured.Store(true)
+	return skew
+}
+
+// registerClockSkewCallback reports the clock skew measured by the last
+// scrape in milliseconds
+func (s *scraper) registerClockSkewCallback() error {
+	endpoint := attribute.String("opcua.endpoint", s.config.Endpoint)
+	return s.telemetry.RegisterOpcuaTimeSkewMsCallback(func(_ context.Context, o metric.Int64Observer) error {
+		if s.skewMeasured.Load() {
+			o.Observe(time.Duration(s.skew.Load()).Milliseconds(), metric.WithAttributes(endpoint))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadatatest"
+)
+
+// skewClient is a windowClient whose server clock runs skew ahead of clk.
+// Reading the server time fails while timeErr is set.
+type skewClient struct {
+	windowClient
+	clk     *fakeClock
+	skew    time.Duration
+	timeErr error
+}
+
+func (c *skewClient) serverTime(context.Context) (time.Time, error) {
+	if c.timeErr != nil {
+		return time.Time{}, c.timeErr
+	}
+	return c.clk.Now().Add(c.skew), nil
+}
+
+func TestScraperClockSkew(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	client := &skewClient{clk: clk, skew: 90 * time.Second}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CorrectClockSkew = true
+	cfg.Client = client
+	scr, err := newScraper(cfg, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// Nothing is reported before the skew is measured
+	_, err = tel.GetMetric("otelcol_opcua_time_skew_ms")
+	require.Error(t, err)
+
+	// The window ends at the server time of a clock running ahead
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A failed read keeps the last skew
+	client.timeErr = errors.New("BadUserAccessDenied")
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A clock set back does not move the window backwards
+	client.timeErr = nil
+	client.skew = -time.Minute
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []timeWindow{
+		{start: time.Time{}, end: t0.Add(90 * time.Second)},
+		{start: t0.Add(90 * time.Second), end: t0.Add(120 * time.Second)},
+		{start: t0.Add(120 * time.Second), end: t0.Add(120 * time.Second)},
+	}, client.windows)
+
+	metadatatest.AssertEqualOpcuaTimeSkewMs(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: -60000, Attributes: attribute.NewSet(attribute.String("opcua.endpoint", cfg.Endpoint))}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+func TestScraperClockSkewDisabled(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	client := &skewClient{clk: clk, skew: 90 * time.Second}
+
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:      &Config{MaxRecordsPerCall: 100},
+		settings:    settings,
+		transformer: newRecordTransformer("opc.tcp://test:4840", "opcua-server", ""),
+		client:      client,
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		clock:       clk,
+	}
+
+	_, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []timeWindow{{start: time.Time{}, end: t0}}, client.windows)
+}
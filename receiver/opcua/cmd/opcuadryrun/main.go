@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command opcuadryrun checks a receiver configuration against its OPC UA
+// server without running a collector. It connects and negotiates security as
+// the receiver would, lists the resolved LogObjects and their GetRecords
+// methods, prints a small sample of records with the attributes the receiver
+// would emit, and exits.
+//
+// Usage:
+//
+//	go run ./cmd/opcuadryrun -config config.yaml -receiver opcua/line1 -sample 5
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/dryrun"
+)
+
+// options holds the command line flags
+type options struct {
+	configFile string
+	receiverID string
+	sample     int
+	verbose    bool
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseFlags parses the command line flags
+func parseFlags(args []string) (*options, error) {
+	fs := flag.NewFlagSet("opcuadryrun", flag.ContinueOnError)
+	opts := &options{}
+
+	fs.StringVar(&opts.configFile, "config", "config.yaml", "Collector configuration file")
+	fs.StringVar(&opts.receiverID, "receiver", "opcua", "ID of the receiver in the receivers section")
+	fs.IntVar(&opts.sample, "sample", dryrun.DefaultSampleSize, "Number of records to read per LogObject")
+	fs.BoolVar(&opts.verbose, "verbose", false, "Log the client's connection and discovery steps")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if opts.sample <= 0 {
+		return nil, errors.New("-sample must be positive")
+	}
+	return opts, nil
+}
+
+func run(ctx context.Context, opts *options) error {
+	cfg, err := loadConfig(opts.configFile, opts.receiverID)
+	if err != nil {
+		return err
+	}
+
+	logger := zap.NewNop()
+	if opts.verbose {
+		if logger, err = zap.NewDevelopment(); err != nil {
+			return err
+		}
+		defer func() { _ = logger.Sync() }()
+	}
+
+	return dryrun.Run(ctx, cfg, os.Stdout, opts.sample, logger)
+}
+
+// loadConfig reads the receiver receiverID from a collector configuration
+// file. ${env:NAME} and ${NAME} references are expanded from the environment.
+func loadConfig(path, receiverID string) (*opcua.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration: %w", err)
+	}
+	expanded := os.Expand(string(data), func(name string) string {
+		return os.Getenv(strings.TrimPrefix(name, "env:"))
+	})
+
+	var file struct {
+		Receivers map[string]map[string]any `yaml:"receivers"`
+	}
+	if err := yaml.Unmarshal([]byte(expanded), &file); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+	raw, ok := file.Receivers[receiverID]
+	if !ok {
+		return nil, fmt.Errorf("receiver %q not found in %s", receiverID, path)
+	}
+
+	cfg := opcua.NewFactory().CreateDefaultConfig().(*opcua.Config)
+	if err := confmap.NewFromStringMap(raw).Unmarshal(cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode receiver %q: %w", receiverID, err)
+	}
+	return cfg, nil
+}
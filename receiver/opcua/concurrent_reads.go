@@ -0,0 +1,177 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// maxConcurrentReads bounds max_concurrent_reads
+const maxConcurrentReads = 64
+
+// recordQuery is the state of a GetRecords call across its LogObject reads
+type recordQuery struct {
+	logObjectIDs []*ua.NodeID
+	startTime    time.Time
+	endTime      time.Time
+	minSeverity  uint16
+
+	// reads are the outcomes of the LogObject reads and pending their
+	// continuation points, both by LogObject
+	reads   []logObjectRead
+	pending [][]byte
+
+	// unstarted marks the LogObjects of the query no read was started for
+	// yet, and from is the position each LogObject is read from without a
+	// continuation point, both by LogObject
+	unstarted []bool
+	from      []readPosition
+
+	// records are the records read so far, in the order the reads were
+	// started; they stay empty if emit receives the pages
+	records []model.LogRecord
+	emit    func([]model.LogRecord)
+
+	// budget is the number of records left to read; a running read holds
+	// its share until it returns the part it did not use
+	budget int
+}
+
+// readPass reads the LogObjects at the indexes of jobs, resuming from their
+// pending continuation points, with up to concurrentReads reads at a time
+// over the session. Each read gets an equal share of the budget left to the
+// reads not started yet, and the share a read does not use goes back to the
+// reads started after it; read one at a time, a LogObject thus gets the
+// quota the ones before it left. A read keeps the quota it started with:
+// budget returned while it runs goes to the reads started later, not to it.
+// No read is started once the budget is spent, so the reads never reserve
+// more than the budget; the LogObjects left are marked in unstarted.
+//
+// A LogObject whose read fails is skipped like in a serial read. A read
+// that fails the whole call, such as an interrupted pagination, is returned
+// once the running reads are done; the reads of the pass are discarded then.
+func (c *opcuaClient) readPass(ctx context.Context, q *recordQuery, jobs []int) error {
+	results := make([][]model.LogRecord, len(jobs))
+	errs := make([]error, len(jobs))
+	workers := make(chan struct{}, c.concurrentReads())
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for k, i := range jobs {
+		workers <- struct{}{}
+		mu.Lock()
+		if q.budget <= 0 {
+			mu.Unlock()
+			<-workers
+			break
+		}
+		quota := max(q.budget/(len(jobs)-k), 1)
+		q.budget -= quota
+		mu.Unlock()
+		q.unstarted[i] = false
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+			// Every read has its own LogObject, so only the budget is shared
+			before := q.reads[i].records
+			records, next, err := c.readLogObject(ctx, &q.reads[i], q.logObjectIDs[i], q.from[i], q.endTime, quota, q.minSeverity, q.pending[i], q.emit)
+			results[k], errs[k] = records, err
+			q.pending[i] = next
+
+			mu.Lock()
+			defer mu.Unlock()
+			q.budget += quota - (q.reads[i].records - before)
+		}()
+	}
+	wg.Wait()
+
+	var failed error
+	for k, i := range jobs {
+		if errs[k] != nil {
+			q.reads[i] = logObjectRead{}
+			if failed == nil {
+				failed = errs[k]
+			}
+		}
+	}
+	for _, records := range results {
+		if failed != nil {
+			releaseRecords(records)
+			continue
+		}
+		q.records = append(q.records, records...)
+		putRecordSlice(records)
+	}
+	return failed
+}
+
+// concurrentReads returns the number of LogObjects read at a time:
+// max_concurrent_reads, capped by the resource profile and by the limits of
+// the server read on connect
+func (c *opcuaClient) concurrentReads() int {
+	workers := c.config.concurrentReads()
+	if limit := c.readLimit.Load(); limit > 0 && int(limit) < workers {
+		workers = int(limit)
+	}
+	return workers
+}
+
+// readLimitNodes are the server limits bounding the GetRecords calls that
+// run at a time: each call is a method call, and every read paging through
+// a LogObject holds a continuation point until it is done
+var readLimitNodes = []uint32{
+	id.Server_ServerCapabilities_OperationLimits_MaxNodesPerMethodCall,
+	id.Server_ServerCapabilities_MaxQueryContinuationPoints,
+	id.Server_ServerCapabilities_MaxHistoryContinuationPoints,
+}
+
+// readConcurrencyLimit reads the OperationLimits and ServerCapabilities of
+// readLimitNodes and returns the smallest of them. It is 0 if the server has
+// no limit or exposes none of them.
+func readConcurrencyLimit(ctx context.Context, client *opcua.Client, timeout time.Duration, logger *zap.Logger) uint32 {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	nodes := make([]*ua.ReadValueID, len(readLimitNodes))
+	for i, node := range readLimitNodes {
+		nodes[i] = &ua.ReadValueID{NodeID: ua.NewNumericNodeID(0, node), AttributeID: ua.AttributeIDValue}
+	}
+	resp, err := client.Read(ctx, &ua.ReadRequest{
+		TimestampsToReturn: ua.TimestampsToReturnNeither,
+		NodesToRead:        nodes,
+	})
+	if err != nil {
+		logger.Debug("Failed to read the server limits, reading LogObjects with max_concurrent_reads", zap.Error(err))
+		return 0
+	}
+
+	var limit uint32
+	for _, result := range resp.Results {
+		if result == nil || result.Status != ua.StatusOK || result.Value == nil {
+			continue
+		}
+		var value uint32
+		switch v := result.Value.Value().(type) {
+		case uint16:
+			value = uint32(v)
+		case uint32:
+			value = v
+		}
+		// Zero means no limit
+		if value > 0 && (limit == 0 || value < limit) {
+			limit = value
+		}
+	}
+	return limit
+}
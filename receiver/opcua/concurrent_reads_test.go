@@ -0,0 +1,233 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestClientWireConcurrentReads(t *testing.T) {
+	failingObjectID := ua.NewNumericNodeID(1, 3000)
+	logObjectIDs := []*ua.NodeID{ua.NewNumericNodeID(1, 2000), failingObjectID, ua.NewNumericNodeID(1, 4000)}
+
+	// Reads finishing early return their unused quota to the reads started
+	// after them, so only the total is fixed while the budget is spent
+	tests := []struct {
+		name           string
+		counts         [4]int
+		maxRecords     int
+		expectedTotal  int
+		expectedCounts map[string]int
+	}{
+		{
+			name:          "budget spent",
+			counts:        [4]int{20, 20, 20, 20},
+			maxRecords:    12,
+			expectedTotal: 12,
+		},
+		{
+			name:          "unused quota redistributed",
+			counts:        [4]int{1, 20, 20, 20},
+			maxRecords:    12,
+			expectedTotal: 12,
+		},
+		{
+			name:           "budget above all records",
+			counts:         [4]int{3, 4, 20, 5},
+			maxRecords:     100,
+			expectedTotal:  12,
+			expectedCounts: map[string]int{"ns=1;i=1000": 3, "ns=1;i=2000": 4, "ns=1;i=4000": 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			ws.SetMaxPageSize(2)
+			ws.AddLogRecords(wireRecords(tt.counts[0]))
+			paths := []string{ws.logObjectID.String()}
+			for i, logObjectID := range logObjectIDs {
+				methodID := ua.NewNumericNodeID(1, logObjectID.IntID()+1)
+				require.NoError(t, ws.AddLogObject(logObjectID, methodID))
+				require.NoError(t, ws.AddLogRecordsTo(logObjectID, wireRecords(tt.counts[i+1])...))
+				paths = append(paths, logObjectID.String())
+			}
+			// The failing LogObject is skipped without affecting the others
+			ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, LogObject: failingObjectID, Status: ua.StatusBadNodeIDUnknown})
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.LogObjectPaths = paths
+			cfg.MaxConcurrentReads = 4
+			c := newOPCUAClient(cfg, zap.NewNop())
+			var mu sync.Mutex
+			var reads []logObjectRead
+			c.onLogObjectRead = func(read logObjectRead) {
+				mu.Lock()
+				defer mu.Unlock()
+				reads = append(reads, read)
+			}
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+			assert.Equal(t, 4, c.concurrentReads())
+
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), tt.maxRecords)
+			require.NoError(t, err)
+
+			require.Len(t, records, tt.expectedTotal)
+			counts := map[string]int{}
+			for _, r := range records {
+				counts[r.LogObjectID]++
+			}
+			assert.NotContains(t, counts, failingObjectID.String())
+			if tt.expectedCounts != nil {
+				assert.Equal(t, tt.expectedCounts, counts)
+
+				// Read in one pass, records keep the order of the LogObjects
+				var sequence []string
+				for _, r := range records {
+					if len(sequence) == 0 || sequence[len(sequence)-1] != r.LogObjectID {
+						sequence = append(sequence, r.LogObjectID)
+					}
+				}
+				assert.Equal(t, []string{"ns=1;i=1000", "ns=1;i=2000", "ns=1;i=4000"}, sequence)
+			}
+
+			require.Len(t, reads, 4)
+			for _, read := range reads {
+				if read.logObjectID == failingObjectID.String() {
+					assert.Error(t, read.err)
+					continue
+				}
+				assert.NoError(t, read.err)
+				assert.Equal(t, counts[read.logObjectID], read.records, read.logObjectID)
+			}
+		})
+	}
+}
+
+func TestClientWireConcurrentReadsLimit(t *testing.T) {
+	uint16Limit := func(v uint16) *ua.DataValue {
+		return &ua.DataValue{EncodingMask: ua.DataValueValue, Value: ua.MustVariant(v)}
+	}
+	uint32Limit := func(v uint32) *ua.DataValue {
+		return &ua.DataValue{EncodingMask: ua.DataValueValue, Value: ua.MustVariant(v)}
+	}
+	tests := []struct {
+		name            string
+		limits          map[uint32]*ua.DataValue
+		resourceProfile string
+		expected        int
+	}{
+		{
+			name:     "no server limits",
+			expected: 8,
+		},
+		{
+			name:     "capped by MaxNodesPerMethodCall",
+			limits:   map[uint32]*ua.DataValue{id.Server_ServerCapabilities_OperationLimits_MaxNodesPerMethodCall: uint32Limit(3)},
+			expected: 3,
+		},
+		{
+			name: "capped by the smallest limit",
+			limits: map[uint32]*ua.DataValue{
+				id.Server_ServerCapabilities_OperationLimits_MaxNodesPerMethodCall: uint32Limit(6),
+				id.Server_ServerCapabilities_MaxQueryContinuationPoints:            uint16Limit(2),
+				id.Server_ServerCapabilities_MaxHistoryContinuationPoints:          uint16Limit(5),
+			},
+			expected: 2,
+		},
+		{
+			name: "zero is unlimited",
+			limits: map[uint32]*ua.DataValue{
+				id.Server_ServerCapabilities_OperationLimits_MaxNodesPerMethodCall: uint32Limit(0),
+				id.Server_ServerCapabilities_MaxQueryContinuationPoints:            uint16Limit(0),
+			},
+			expected: 8,
+		},
+		{
+			name:            "minimal resource profile",
+			limits:          map[uint32]*ua.DataValue{id.Server_ServerCapabilities_MaxQueryContinuationPoints: uint16Limit(4)},
+			resourceProfile: resourceProfileMinimal,
+			expected:        1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			ws.AddLogRecords(wireRecords(10))
+			for node, limit := range tt.limits {
+				ws.SetVariable(ua.NewNumericNodeID(0, node), "", limit)
+			}
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.MaxConcurrentReads = 8
+			if tt.resourceProfile != "" {
+				cfg.ResourceProfile = tt.resourceProfile
+			}
+			c := newOPCUAClient(cfg, zap.NewNop())
+			require.NoError(t, c.Connect(ctx))
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+			assert.Equal(t, tt.expected, c.concurrentReads())
+
+			// The reads work within the limit
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+			require.NoError(t, err)
+			assert.Len(t, records, 10)
+		})
+	}
+}
+
+func TestConfigConcurrentReads(t *testing.T) {
+	tests := []struct {
+		name               string
+		maxConcurrentReads int
+		resourceProfile    string
+		expected           int
+	}{
+		{
+			name:     "default",
+			expected: 1,
+		},
+		{
+			name:               "max_concurrent_reads",
+			maxConcurrentReads: 8,
+			expected:           8,
+		},
+		{
+			name:               "minimal resource profile",
+			maxConcurrentReads: 8,
+			resourceProfile:    resourceProfileMinimal,
+			expected:           1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.MaxConcurrentReads = tt.maxConcurrentReads
+			if tt.resourceProfile != "" {
+				cfg.ResourceProfile = tt.resourceProfile
+			}
+			assert.Equal(t, tt.expected, cfg.concurrentReads())
+		})
+	}
+}
@@ -6,8 +6,21 @@ package opcua
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/scraper/scraperhelper"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
 )
 
 // Config defines configuration for the OPC UA receiver
@@ -27,26 +40,321 @@ type Config struct {
 	// LogObjectPaths are the paths to browse for LogObject nodes
 	LogObjectPaths []string `mapstructure:"log_object_paths"`
 
-	// CollectionInterval is the interval between log collection attempts
-	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+	// LogRecordTypeID is the TypeId of the LogRecord ExtensionObjects returned
+	// by GetRecords, usually with a namespace URI such as
+	// "nsu=http://vendor.com/UA/;i=5001". Empty accepts any TypeId.
+	LogRecordTypeID string `mapstructure:"log_record_type_id"`
+
+	// RecordFields selects the optional LogRecord fields GetRecords returns
+	RecordFields RecordFieldsConfig `mapstructure:"record_fields"`
+
+	// Mode is how records are collected: "poll" calls GetRecords every
+	// collection_interval, "subscribe" receives them as events of the
+	// LogObjects, "alarms" receives Alarm & Condition events instead and
+	// "pubsub" receives DataSets published with OPC UA PubSub
+	Mode string `mapstructure:"mode"`
+
+	// Subscription contains the event subscription settings of subscribe
+	// and alarms mode
+	Subscription SubscriptionConfig `mapstructure:"subscription"`
+
+	// Alarms contains the settings of alarms mode
+	Alarms AlarmsConfig `mapstructure:"alarms"`
+
+	// PubSub contains the settings of pubsub mode
+	PubSub PubSubConfig `mapstructure:"pubsub"`
+
+	// ControllerConfig holds the scrape schedule of poll mode:
+	// collection_interval, initial_delay and timeout. In subscribe and alarms mode
+	// collection_interval is the delay before subscribing again after a failure.
+	scraperhelper.ControllerConfig `mapstructure:",squash"`
 
 	// MaxRecordsPerCall is the maximum number of records to retrieve per GetRecords call
 	MaxRecordsPerCall int `mapstructure:"max_records_per_call"`
 
+	// MaxConcurrentReads is the number of LogObjects read at a time over the
+	// session, capped by the MaxNodesPerMethodCall operation limit and the
+	// continuation point capabilities of the server. Zero reads one at a time.
+	MaxConcurrentReads int `mapstructure:"max_concurrent_reads"`
+
+	// FlushSize is the number of records handed to the logs consumer at a
+	// time while a scrape pages through its window. Zero hands over the
+	// records of a scrape at once.
+	FlushSize int `mapstructure:"flush_size"`
+
+	// MaxCatchupDuration bounds the collection window after downtime; a
+	// longer backlog is read in windows of this length, one per scrape. Zero
+	// reads it in a single window.
+	MaxCatchupDuration time.Duration `mapstructure:"max_catchup_duration"`
+
+	// StartAt is where a receiver without a checkpoint starts collecting:
+	// "beginning" for the whole log history, "end" for the records logged
+	// from the start on, or a duration to backfill
+	StartAt string `mapstructure:"start_at"`
+
+	// ResourceProfile tunes buffers and page sizes for the available memory:
+	// "default", or "minimal" for gateways with less than 128 MB for the collector
+	ResourceProfile string `mapstructure:"resource_profile"`
+
 	// Filter contains log filtering options
 	Filter FilterConfig `mapstructure:"filter"`
 
+	// Reconnect contains the backoff used to recover a lost session
+	Reconnect ReconnectConfig `mapstructure:"reconnect"`
+
+	// KeepAlive contains the reads of the server state that detect a dead
+	// session between scrapes
+	KeepAlive KeepAliveConfig `mapstructure:"keepalive"`
+
+	// Redundancy contains the backup endpoints of a redundant server pair
+	Redundancy RedundancyConfig `mapstructure:"redundancy"`
+
+	// Discovery contains the Local Discovery Server the servers to collect
+	// from are found at, instead of endpoint
+	Discovery DiscoveryConfig `mapstructure:"discovery"`
+
 	// ConnectionTimeout is the timeout for establishing OPC UA connection
 	ConnectionTimeout time.Duration `mapstructure:"connection_timeout"`
 
 	// RequestTimeout is the timeout for individual OPC UA requests
 	RequestTimeout time.Duration `mapstructure:"request_timeout"`
 
+	// LocaleIDs are the locales, in order of preference, the session is
+	// activated with. Servers return LocalizedText, such as the record
+	// message, in the first locale they support.
+	LocaleIDs []string `mapstructure:"locale_ids"`
+
+	// CorrectClockSkew reads the server's CurrentTime on every scrape and
+	// ends the collection window at the server time instead of the
+	// collector's
+	CorrectClockSkew bool `mapstructure:"correct_clock_skew"`
+
+	// LogSuppressionInterval is the interval a recurring warning, such as a
+	// failing LogObject, is logged at most once per. Repeats are summarized
+	// with the next warning. Zero logs every occurrence.
+	LogSuppressionInterval time.Duration `mapstructure:"log_suppression_interval"`
+
 	// TLS contains TLS/certificate configuration
 	TLS TLSConfig `mapstructure:"tls"`
 
+	// GDS enrolls the application certificate and trust list with a Global
+	// Discovery Server instead of tls
+	GDS GDSConfig `mapstructure:"gds"`
+
 	// Resource contains resource-level OTel attributes attached to every log record.
 	Resource ResourceConfig `mapstructure:"resource"`
+
+	// ResourceAttributes enables or disables individual resource attributes.
+	ResourceAttributes metadata.ResourceAttributesConfig `mapstructure:"resource_attributes"`
+
+	// ResourcePerSource emits the log records of every SourceNode, or
+	// SourceName if they have none, under a resource of their own
+	ResourcePerSource bool `mapstructure:"resource_per_source"`
+
+	// BodyFormat is the log body: "string" is the message with AdditionalData
+	// in attributes, "map" a map of the message, source, event type and
+	// AdditionalData
+	BodyFormat string `mapstructure:"body_format"`
+
+	// AttributeMappings renames AdditionalData entries, keyed by the name the
+	// server uses, to the attribute names they are emitted under
+	AttributeMappings map[string]string `mapstructure:"attribute_mappings"`
+
+	// Metrics are variables read on every collection and sent to the metrics
+	// pipeline as gauges or sums
+	Metrics []VariableMetricConfig `mapstructure:"metrics"`
+
+	// DerivedMetrics contains options for metrics derived from the collected logs
+	DerivedMetrics DerivedMetricsConfig `mapstructure:"derived_metrics"`
+
+	// Traces contains options for the spans reconstructed from trace context
+	Traces TracesConfig `mapstructure:"traces"`
+
+	// Diagnostics contains options for log records describing the receiver's own failures
+	Diagnostics DiagnosticsConfig `mapstructure:"diagnostics"`
+
+	// Health contains the error rate at which the receiver reports itself degraded
+	Health HealthConfig `mapstructure:"health"`
+
+	// SeverityDictionary maps the proprietary severity scale of a server to
+	// severity text and numbers
+	SeverityDictionary SeverityDictionaryConfig `mapstructure:"severity_dictionary"`
+
+	// SeverityMapping replaces the Part 26 ranges SeverityNumbers are derived
+	// from. It must cover the severity values 1–1000 without overlaps.
+	SeverityMapping []SeverityRangeConfig `mapstructure:"severity_mapping"`
+
+	// StatusWriteBack contains the server node the time of the last successful
+	// collection is written to
+	StatusWriteBack StatusWriteBackConfig `mapstructure:"status_write_back"`
+
+	// ClientIdentity is how the receiver identifies itself in the session, so
+	// server-side audit trails attribute its activity to the collector
+	ClientIdentity ClientIdentityConfig `mapstructure:"client_identity"`
+
+	// Dialer contains low-level network settings for the TCP connection to the server
+	Dialer DialerConfig `mapstructure:"dialer"`
+
+	// ReverseConnect lets the server open the connection to the receiver, for
+	// servers behind firewalls that allow no inbound connections
+	ReverseConnect ReverseConnectConfig `mapstructure:"reverse_connect"`
+
+	// StorageID is the ID of a storage extension used to spool transformed log
+	// batches until the next consumer accepts them and to keep the scrape
+	// checkpoint across restarts. Both are disabled when nil.
+	StorageID *component.ID `mapstructure:"storage"`
+
+	// MaxSpooledBatches is the number of undelivered batches the spool keeps;
+	// the oldest batch is dropped when a new one would exceed it. Zero keeps
+	// all batches.
+	MaxSpooledBatches int `mapstructure:"max_spooled_batches"`
+
+	// Capture records raw GetRecords calls for reproducing issues offline
+	Capture CaptureConfig `mapstructure:"capture"`
+
+	// DebugDump logs redacted GetRecords responses for troubleshooting decode issues
+	DebugDump DebugDumpConfig `mapstructure:"debug_dump"`
+
+	// Client replaces the built-in OPC UA client when set from code. It cannot
+	// be set in the collector configuration. The TLS, auth and connection
+	// settings are left to the supplied client.
+	Client OPCUAClient `mapstructure:"-"`
+}
+
+// SubscriptionConfig defines the subscription on the LogObject events used
+// in subscribe mode
+type SubscriptionConfig struct {
+	// PublishingInterval is the interval the server sends queued events at
+	PublishingInterval time.Duration `mapstructure:"publishing_interval"`
+
+	// QueueSize is the number of events the server queues per LogObject
+	// between publishes. Older events are discarded when it overflows.
+	QueueSize uint32 `mapstructure:"queue_size"`
+}
+
+// AlarmsConfig defines the Alarm & Condition events collected in alarms mode
+type AlarmsConfig struct {
+	// Notifiers are the NodeIDs of the event notifiers whose condition events
+	// are collected. The Server object "i=2253" reports the events of all
+	// conditions of the server.
+	Notifiers []string `mapstructure:"notifiers"`
+}
+
+// PubSubConfig defines how DataSets published with OPC UA PubSub (Part 14)
+// are received in pubsub mode
+type PubSubConfig struct {
+	// Transport is "mqtt" to subscribe at a broker or "udp" to receive UADP
+	// datagrams
+	Transport string `mapstructure:"transport"`
+
+	// Address is the broker URL, such as "tcp://broker:1883", or the UDP
+	// address to listen on, such as "239.0.0.1:4840" to join a multicast group
+	Address string `mapstructure:"address"`
+
+	// Topic is the MQTT topic the DataSets are published to. MQTT wildcards
+	// are allowed.
+	Topic string `mapstructure:"topic"`
+
+	// QoS is the MQTT quality of service of the subscription, 0 or 1
+	QoS byte `mapstructure:"qos"`
+
+	// Encoding is the message mapping: "json" or "uadp". Empty stands for
+	// "json" over MQTT and "uadp" over UDP.
+	Encoding string `mapstructure:"encoding"`
+
+	// ClientID is the MQTT client identifier. Empty lets the broker assign one.
+	ClientID string `mapstructure:"client_id"`
+
+	// Username for the broker
+	Username string `mapstructure:"username"`
+
+	// Password for the broker. It is printed and marshaled as [REDACTED].
+	Password configopaque.String `mapstructure:"password"`
+
+	// TLS is the TLS configuration of ssl:// and tls:// broker URLs
+	TLS configtls.ClientConfig `mapstructure:"tls"`
+
+	// DataSet describes the published DataSets
+	DataSet DataSetConfig `mapstructure:"dataset"`
+}
+
+// DataSetConfig describes the DataSets received in pubsub mode
+type DataSetConfig struct {
+	// Fields are the field names of the DataSetMetaData in field order. UADP
+	// messages carry no names, so their fields are named by position. Fields
+	// named Time, Severity, Message, SourceName and SourceNode fill the log
+	// record, the others become attributes.
+	Fields []string `mapstructure:"fields"`
+
+	// PublisherID, if set, drops the messages of other publishers
+	PublisherID string `mapstructure:"publisher_id"`
+
+	// WriterIDs, if set, drops the DataSetMessages of other DataSetWriters
+	WriterIDs []uint16 `mapstructure:"writer_ids"`
+}
+
+// ReconnectConfig defines the exponential backoff between the attempts to
+// recover a lost session
+type ReconnectConfig struct {
+	// InitialInterval is the wait before the first retry after the immediate
+	// reconnect failed. Zero values of the settings stand for the defaults.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+
+	// MaxInterval caps the wait between retries
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+
+	// Multiplier grows the wait after each failed retry
+	Multiplier float64 `mapstructure:"multiplier"`
+
+	// RandomizationFactor spreads each wait randomly by up to this fraction
+	// in both directions, so that receivers do not reconnect in lockstep
+	RandomizationFactor float64 `mapstructure:"randomization_factor"`
+
+	// MaxRetries is the number of failed attempts after which the receiver
+	// gives up, 0 retries forever
+	MaxRetries int `mapstructure:"max_retries"`
+}
+
+// KeepAliveConfig defines the reads of Server/ServerStatus/State between
+// scrapes that detect a half-open session before the next collection
+type KeepAliveConfig struct {
+	// Interval is the time between two reads. Zero disables the keepalive.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// Timeout bounds each read
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// MaxFailures is the number of reads in a row that fail, or find the
+	// server not running, before the session is rebuilt
+	MaxFailures int `mapstructure:"max_failures"`
+}
+
+// RedundancyConfig defines the backup servers of a redundant server set
+// (OPC UA Part 4, 6.6). The receiver connects to endpoint and fails over to
+// the backup endpoints in order when it cannot.
+type RedundancyConfig struct {
+	// BackupEndpoints are the endpoint URLs of the backup servers
+	BackupEndpoints []string `mapstructure:"backup_endpoints"`
+
+	// FailbackInterval is the interval the primary endpoint is checked in
+	// while connected to a backup
+	FailbackInterval time.Duration `mapstructure:"failback_interval"`
+}
+
+// DiscoveryConfig defines the discovery of servers at a Local Discovery
+// Server (OPC UA Part 12). The receiver collects from every registered server
+// whose ApplicationUri matches, as if each had a receiver of its own.
+type DiscoveryConfig struct {
+	// Endpoint is the URL of the Local Discovery Server. Discovery is
+	// disabled when empty.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// ApplicationURIPattern is a regular expression the ApplicationUri of a
+	// server must match to be collected from. Empty matches every server.
+	ApplicationURIPattern string `mapstructure:"application_uri_pattern"`
+
+	// Interval is the interval the registered servers are queried in
+	Interval time.Duration `mapstructure:"interval"`
 }
 
 // AuthConfig defines authentication configuration
@@ -57,8 +365,9 @@ type AuthConfig struct {
 	// Username for username/password authentication
 	Username string `mapstructure:"username"`
 
-	// Password for username/password authentication
-	Password string `mapstructure:"password"`
+	// Password for username/password authentication. It is printed and
+	// marshaled as [REDACTED].
+	Password configopaque.String `mapstructure:"password"`
 }
 
 // FilterConfig defines log filtering options
@@ -67,9 +376,51 @@ type FilterConfig struct {
 	MinSeverity string `mapstructure:"min_severity"`
 
 	// MaxLogRecords is the maximum total number of log records to collect
+	// per scrape across all LogObjects; zero does not cap the scrape
 	MaxLogRecords int `mapstructure:"max_log_records"`
 }
 
+// RecordFieldsConfig selects the optional LogRecord fields requested with
+// the LogRecordMask of GetRecords. Skipping unused fields shrinks responses,
+// and a server that rejects a mask bit it does not support still answers.
+type RecordFieldsConfig struct {
+	// EventType requests the EventType of each record
+	EventType bool `mapstructure:"event_type"`
+
+	// SourceNode requests the NodeID of the node that emitted the record
+	SourceNode bool `mapstructure:"source_node"`
+
+	// SourceName requests the name of the source of the record
+	SourceName bool `mapstructure:"source_name"`
+
+	// TraceContext requests the trace and span id of the record
+	TraceContext bool `mapstructure:"trace_context"`
+
+	// AdditionalData requests the vendor specific name-value pairs
+	AdditionalData bool `mapstructure:"additional_data"`
+}
+
+// mask returns the LogRecordMask of the selected fields
+func (cfg RecordFieldsConfig) mask() uint32 {
+	var mask uint32
+	if cfg.EventType {
+		mask |= logRecordMaskEventType
+	}
+	if cfg.SourceNode {
+		mask |= logRecordMaskSourceNode
+	}
+	if cfg.SourceName {
+		mask |= logRecordMaskSourceName
+	}
+	if cfg.TraceContext {
+		mask |= logRecordMaskTraceContext
+	}
+	if cfg.AdditionalData {
+		mask |= logRecordMaskAdditionalData
+	}
+	return mask
+}
+
 // ResourceConfig defines the OTel resource attributes that are emitted with every log record.
 type ResourceConfig struct {
 	// ServiceName sets the resource attribute service.name.
@@ -81,39 +432,433 @@ type ResourceConfig struct {
 	ServiceNamespace string `mapstructure:"service_namespace"`
 }
 
-// TLSConfig defines TLS/certificate configuration
+// DerivedMetricsConfig defines metrics computed from the collected log records
+type DerivedMetricsConfig struct {
+	// Enabled reports otelcol_opcua_records_by_severity, counting records per
+	// severity band and LogObject, through the receiver's internal telemetry.
+	// The metrics signal carries the same counts as opcua.log.records whether
+	// or not it is set; this is for collectors without a metrics pipeline.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// TracesConfig defines how spans are reconstructed from the trace context of
+// the collected log records
+type TracesConfig struct {
+	// ParentSpan is how the ParentSpanId of a record relates its span to the
+	// span it was called from: "parent" sets it as the parent span, "link"
+	// adds a span link to it for parents that are not exported to the same
+	// backend, and "none" ignores it
+	ParentSpan string `mapstructure:"parent_span"`
+
+	// SpanEvents adds every record to its span as a span event
+	SpanEvents bool `mapstructure:"span_events"`
+}
+
+// Validate checks the traces configuration
+func (cfg TracesConfig) Validate() error {
+	switch cfg.ParentSpan {
+	case "", parentSpanParent, parentSpanLink, parentSpanNone:
+		return nil
+	}
+	return fmt.Errorf("invalid parent_span: %s, must be one of: [%s %s %s]", cfg.ParentSpan, parentSpanParent, parentSpanLink, parentSpanNone)
+}
+
+// VariableMetricConfig defines a metric read from a variable node
+type VariableMetricConfig struct {
+	// Node is the NodeID of the variable (e.g., ns=2;s=Machine.Temperature) or
+	// its browse path below the Objects folder (e.g., Objects/2:Machine/2:Temperature)
+	Node string `mapstructure:"node"`
+
+	// Name is the metric name
+	Name string `mapstructure:"name"`
+
+	// Description is the metric description
+	Description string `mapstructure:"description"`
+
+	// Unit is the UCUM unit of the metric (e.g., Cel, By, {parts})
+	Unit string `mapstructure:"unit"`
+
+	// Type is "gauge" for sampled values or "sum" for a monotonic counter
+	Type string `mapstructure:"type"`
+
+	// ValueType is "int" or "double". Empty follows the data type of the variable.
+	ValueType string `mapstructure:"value_type"`
+}
+
+// DiagnosticsConfig defines log records the receiver emits about its own failures
+// and about records lost on the server
+type DiagnosticsConfig struct {
+	// FailureRecords emits a log record for each LogObject skipped by a scrape
+	// because its GetRecords call failed, carrying the status code and error class
+	FailureRecords bool `mapstructure:"failure_records"`
+
+	// GapRecords emits a Warning record when the earliest record read from a
+	// LogObject is newer than the start of the collection window by more than
+	// GapThreshold, as happens when a bounded server log buffer overwrote
+	// records between scrapes. Servers that log rarely report false gaps.
+	GapRecords bool `mapstructure:"gap_records"`
+
+	// GapThreshold is the shortest span without records reported as a gap
+	GapThreshold time.Duration `mapstructure:"gap_threshold"`
+}
+
+// HealthConfig defines when a rising scrape error rate marks the receiver degraded
+type HealthConfig struct {
+	// ErrorRateWindow is the sliding window the scrape error rate is computed over
+	ErrorRateWindow time.Duration `mapstructure:"error_rate_window"`
+
+	// ErrorRateThreshold is the fraction of failed scrapes in the window (0–1)
+	// at which the receiver is degraded. Zero disables health tracking.
+	ErrorRateThreshold float64 `mapstructure:"error_rate_threshold"`
+
+	// MinScrapes is the number of scrapes the window must hold before the
+	// receiver can be degraded, so a single failure after startup is a blip
+	MinScrapes int `mapstructure:"min_scrapes"`
+}
+
+// ClientIdentityConfig defines the application description and session name
+// the receiver announces when it creates a session
+type ClientIdentityConfig struct {
+	// ApplicationName is the client application name. Empty uses
+	// "otelcol-opcua/<collector version>".
+	ApplicationName string `mapstructure:"application_name"`
+
+	// ProductURI identifies the client product. Empty uses
+	// "urn:opentelemetry:collector:opcua-receiver".
+	ProductURI string `mapstructure:"product_uri"`
+
+	// SessionName is the name of the session. Empty uses the application name.
+	SessionName string `mapstructure:"session_name"`
+}
+
+// SeverityDictionaryConfig defines the severity text and numbers of a server
+// whose severity values do not follow the Part 26 ranges
+type SeverityDictionaryConfig struct {
+	// NodeID is a variable holding the server's dictionary in EnumValues or
+	// EnumStrings form, read when the receiver connects. Empty disables it.
+	NodeID string `mapstructure:"node_id"`
+
+	// Entries map individual severity values. They take precedence over the
+	// dictionary read from NodeID and are used alone if it cannot be read.
+	Entries []SeverityDictionaryEntry `mapstructure:"entries"`
+}
+
+// SeverityDictionaryEntry maps one severity value of a server
+type SeverityDictionaryEntry struct {
+	// Severity is the value sent by the server
+	Severity uint16 `mapstructure:"severity"`
+
+	// Text is the severity text of the value. Empty keeps the Part 26 text.
+	Text string `mapstructure:"text"`
+
+	// Number is the OpenTelemetry SeverityNumber (1–24) of the value. Zero
+	// derives it from Text if that is a well-known name such as "Warning",
+	// and keeps the Part 26 number otherwise.
+	Number int `mapstructure:"number"`
+}
+
+// SeverityRangeConfig maps a range of severity values to a SeverityNumber
+type SeverityRangeConfig struct {
+	// Min is the first severity value of the range
+	Min uint16 `mapstructure:"min"`
+
+	// Max is the last severity value of the range
+	Max uint16 `mapstructure:"max"`
+
+	// Number is the OpenTelemetry SeverityNumber (1–24) of the range
+	Number int `mapstructure:"number"`
+
+	// Text is the severity text of the range. Empty keeps the Part 26 text.
+	Text string `mapstructure:"text"`
+}
+
+// StatusWriteBackConfig defines where the receiver writes the time of its last
+// successful collection, so the server can tell that its logs are collected
+type StatusWriteBackConfig struct {
+	// NodeID is the writable DateTime variable the time is written to.
+	// Write-back is disabled when empty.
+	NodeID string `mapstructure:"node_id"`
+
+	// Interval is the minimum time between two writes. Zero writes after
+	// every successful collection.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// DialerConfig defines low-level settings of the TCP connection to the server
+type DialerConfig struct {
+	// KeepAlive is the TCP keep-alive period. Zero uses the operating system
+	// default; a negative value disables keep-alives.
+	KeepAlive time.Duration `mapstructure:"keep_alive"`
+
+	// LocalAddress is the local IP address connections originate from
+	LocalAddress string `mapstructure:"local_address"`
+
+	// Interface is the name of the network interface connections originate from.
+	// Its first IPv4 address is used, falling back to IPv6.
+	Interface string `mapstructure:"interface"`
+
+	// DSCP is the Differentiated Services Code Point (0–63) outgoing packets are
+	// marked with. Zero leaves the marking unchanged.
+	DSCP int `mapstructure:"dscp"`
+}
+
+// GDSConfig defines certificate management by a Global Discovery Server with
+// the pull model of OPC UA Part 12
+type GDSConfig struct {
+	// Endpoint is the endpoint URL of the GDS. Certificate management is
+	// disabled when empty.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Auth is the user identity the application registers with
+	Auth AuthConfig `mapstructure:"auth"`
+
+	// ApplicationURI identifies the application at the GDS and in the
+	// issued certificate
+	ApplicationURI string `mapstructure:"application_uri"`
+
+	// Directory keeps the certificates, keys, trust list and ApplicationId
+	// across restarts
+	Directory string `mapstructure:"directory"`
+
+	// RenewBefore is how long before its expiry the certificate is renewed
+	RenewBefore time.Duration `mapstructure:"renew_before"`
+
+	// CheckInterval is the interval the trust list is pulled and the
+	// certificate checked in
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// ReverseConnectConfig defines reverse connect (OPC UA Part 6, 7.1.3): the
+// server connects to the receiver and announces itself with a ReverseHello
+type ReverseConnectConfig struct {
+	// ListenAddress is the host:port the receiver accepts the connections of
+	// the server on. Reverse connect is disabled when empty.
+	ListenAddress string `mapstructure:"listen_address"`
+
+	// ServerURI is the ApplicationUri of the server. Connections are accepted
+	// if their ReverseHello carries it; when empty, the ReverseHello must carry
+	// endpoint as its EndpointUrl instead.
+	ServerURI string `mapstructure:"server_uri"`
+}
+
+// CaptureConfig defines recording of GetRecords calls
+type CaptureConfig struct {
+	// Directory receives one JSON Lines file per connection holding every
+	// GetRecords request and response in OPC UA binary encoding. Capture is
+	// disabled when empty.
+	Directory string `mapstructure:"directory"`
+}
+
+// DebugDumpConfig defines redacted dumps of raw GetRecords responses
+type DebugDumpConfig struct {
+	// Enabled dumps the output arguments and ExtensionObject bodies of every
+	// GetRecords response, subject to MaxBytes and MaxPerMinute
+	Enabled bool `mapstructure:"enabled"`
+
+	// Directory receives one text file per dump. Dumps are logged when empty.
+	Directory string `mapstructure:"directory"`
+
+	// RedactFields are AdditionalData names whose values are masked in
+	// addition to credential-like names such as password or token
+	RedactFields []string `mapstructure:"redact_fields"`
+
+	// MaxBytes truncates a dump to this many bytes
+	MaxBytes int `mapstructure:"max_bytes"`
+
+	// MaxPerMinute is the number of dumps written per minute; further
+	// responses are skipped until the minute is over
+	MaxPerMinute int `mapstructure:"max_per_minute"`
+}
+
+// TLSConfig defines the application certificate and the trust in the server
+// certificate. The collector's TLS client settings are reused: the
+// certificate and key are the application instance certificate, the CAs
+// verify the certificate of Sign and SignAndEncrypt endpoints. Settings of
+// the TLS handshake, such as min_version, do not apply to opc.tcp.
 type TLSConfig struct {
-	// CertFile is the path to the client certificate file
-	CertFile string `mapstructure:"cert_file"`
+	configtls.ClientConfig `mapstructure:",squash"`
 
-	// KeyFile is the path to the client private key file
-	KeyFile string `mapstructure:"key_file"`
+	// TrustedDirectory holds trusted server certificates and CAs, one or more
+	// per file, such as the trusted/certs folder of an OPC UA PKI
+	TrustedDirectory string `mapstructure:"trusted_directory"`
 
-	// CAFile is the path to the CA certificate file
-	CAFile string `mapstructure:"ca_file"`
+	// CertificateProvider is the ID of an extension implementing CertificateProvider
+	// that supplies the application certificate and trust list instead of the files above
+	CertificateProvider *component.ID `mapstructure:"certificate_provider"`
 
-	// InsecureSkipVerify skips certificate verification (for testing only)
-	InsecureSkipVerify bool `mapstructure:"insecure_skip_verify"`
+	// GenerateCertificate creates a self-signed application certificate at
+	// cert_file and key_file if there is none
+	GenerateCertificate GenerateCertificateConfig `mapstructure:"generate_certificate"`
 }
 
+// GenerateCertificateConfig defines the self-signed application certificate
+// created at startup, for deployments without a PKI
+type GenerateCertificateConfig struct {
+	// Enabled creates the certificate if cert_file and key_file do not exist
+	// or the stored certificate has expired
+	Enabled bool `mapstructure:"enabled"`
+
+	// ApplicationURI is the URI the certificate is issued for. Empty uses
+	// "urn:<hostname>:otelcol-opcua".
+	ApplicationURI string `mapstructure:"application_uri"`
+
+	// Hosts are the DNS names and IP addresses of the subject alternative
+	// name. Empty uses the host name.
+	Hosts []string `mapstructure:"hosts"`
+
+	// KeySize is the size of the RSA key in bits
+	KeySize int `mapstructure:"key_size"`
+
+	// Validity is how long the certificate is valid
+	Validity time.Duration `mapstructure:"validity"`
+}
+
+// validAuthTypes are the values of auth.type and gds.auth.type
+var validAuthTypes = []string{"anonymous", "username_password", "certificate"}
+
 // Validate validates the configuration
 func (cfg *Config) Validate() error {
-	if cfg.Endpoint == "" {
-		return errors.New("endpoint must be specified")
-	}
+	// PubSub mode opens no session, and discovery finds the endpoints
+	if cfg.Mode != collectionModePubSub && cfg.Discovery.Endpoint == "" {
+		if cfg.Endpoint == "" {
+			return errors.New("endpoint must be specified")
+		}
 
-	if !strings.HasPrefix(cfg.Endpoint, "opc.tcp://") {
-		return fmt.Errorf("endpoint must start with opc.tcp://, got: %s", cfg.Endpoint)
+		if !strings.HasPrefix(cfg.Endpoint, "opc.tcp://") {
+			return fmt.Errorf("endpoint must start with opc.tcp://, got: %s", cfg.Endpoint)
+		}
 	}
 
 	if cfg.CollectionInterval < 1*time.Second {
 		return fmt.Errorf("collection_interval must be at least 1 second, got: %s", cfg.CollectionInterval)
 	}
 
+	if err := cfg.ControllerConfig.Validate(); err != nil {
+		return err
+	}
+
 	if cfg.MaxRecordsPerCall < 1 || cfg.MaxRecordsPerCall > 10000 {
 		return fmt.Errorf("max_records_per_call must be between 1 and 10000, got: %d", cfg.MaxRecordsPerCall)
 	}
 
+	if cfg.MaxConcurrentReads < 0 || cfg.MaxConcurrentReads > maxConcurrentReads {
+		return fmt.Errorf("max_concurrent_reads must be between 0 and %d, got: %d", maxConcurrentReads, cfg.MaxConcurrentReads)
+	}
+
+	if cfg.FlushSize < 0 {
+		return fmt.Errorf("flush_size must not be negative, got: %d", cfg.FlushSize)
+	}
+
+	if cfg.MaxSpooledBatches < 0 {
+		return fmt.Errorf("max_spooled_batches must not be negative, got: %d", cfg.MaxSpooledBatches)
+	}
+
+	if _, err := parseStartAt(cfg.StartAt); err != nil {
+		return err
+	}
+
+	// Shorter windows would fall further behind with every scrape
+	if cfg.MaxCatchupDuration != 0 && cfg.MaxCatchupDuration <= cfg.CollectionInterval {
+		return fmt.Errorf("max_catchup_duration must be 0 or longer than collection_interval (%s), got: %s", cfg.CollectionInterval, cfg.MaxCatchupDuration)
+	}
+
+	switch cfg.Mode {
+	case "", collectionModePoll:
+	case collectionModeSubscribe:
+		if err := cfg.Subscription.Validate(); err != nil {
+			return fmt.Errorf("invalid subscription: %w", err)
+		}
+	case collectionModeAlarms:
+		if err := cfg.Subscription.Validate(); err != nil {
+			return fmt.Errorf("invalid subscription: %w", err)
+		}
+		if err := cfg.Alarms.Validate(); err != nil {
+			return fmt.Errorf("invalid alarms: %w", err)
+		}
+	case collectionModePubSub:
+		if err := cfg.PubSub.Validate(); err != nil {
+			return fmt.Errorf("invalid pubsub: %w", err)
+		}
+		if cfg.PubSub.Address == "" {
+			return errors.New("pubsub address must be specified")
+		}
+		if cfg.PubSub.Transport == pubsubTransportMQTT && cfg.PubSub.Topic == "" {
+			return errors.New("pubsub topic must be specified")
+		}
+		if len(cfg.Metrics) > 0 {
+			return errors.New("metrics read variables through a session and are not available in pubsub mode")
+		}
+	default:
+		return fmt.Errorf("invalid mode: %s, must be one of: [poll subscribe alarms pubsub]", cfg.Mode)
+	}
+
+	for i, locale := range cfg.LocaleIDs {
+		if strings.TrimSpace(locale) == "" {
+			return fmt.Errorf("locale_ids[%d] must not be empty", i)
+		}
+	}
+
+	if err := cfg.Reconnect.Validate(); err != nil {
+		return fmt.Errorf("invalid reconnect: %w", err)
+	}
+
+	if err := cfg.KeepAlive.Validate(); err != nil {
+		return fmt.Errorf("invalid keepalive: %w", err)
+	}
+
+	if cfg.KeepAlive.Interval > 0 && cfg.Mode == collectionModePubSub {
+		return errors.New("keepalive is not available in pubsub mode")
+	}
+
+	if err := cfg.Redundancy.validate(cfg.Endpoint); err != nil {
+		return fmt.Errorf("invalid redundancy: %w", err)
+	}
+
+	if len(cfg.Redundancy.BackupEndpoints) > 0 {
+		if cfg.Mode == collectionModePubSub {
+			return errors.New("redundancy is not available in pubsub mode")
+		}
+		if cfg.ReverseConnect.ListenAddress != "" {
+			return errors.New("redundancy is not available with reverse_connect")
+		}
+	}
+
+	if err := cfg.Discovery.Validate(); err != nil {
+		return fmt.Errorf("invalid discovery: %w", err)
+	}
+
+	if cfg.Discovery.Endpoint != "" {
+		switch {
+		case cfg.Mode == collectionModePubSub:
+			return errors.New("discovery is not available in pubsub mode")
+		case cfg.ReverseConnect.ListenAddress != "":
+			return errors.New("discovery is not available with reverse_connect")
+		case len(cfg.Redundancy.BackupEndpoints) > 0:
+			return errors.New("discovery is not available with redundancy")
+		case cfg.Client != nil:
+			return errors.New("discovery is not available with a custom client")
+		}
+	}
+
+	switch cfg.ResourceProfile {
+	case "", resourceProfileDefault:
+	case resourceProfileMinimal:
+		if cfg.DebugDump.Enabled {
+			return errors.New("debug_dump is not available with resource_profile minimal")
+		}
+		if cfg.Capture.Directory != "" {
+			return errors.New("capture is not available with resource_profile minimal")
+		}
+	default:
+		return fmt.Errorf("invalid resource_profile: %s, must be one of: [default minimal]", cfg.ResourceProfile)
+	}
+
+	switch cfg.BodyFormat {
+	case "", bodyFormatString, bodyFormatMap:
+	default:
+		return fmt.Errorf("invalid body_format: %s, must be one of: [string map]", cfg.BodyFormat)
+	}
+
 	validSecurityPolicies := []string{"None", "Basic256", "Basic256Sha256", "Aes128_Sha256_RsaOaep", "Aes256_Sha256_RsaPss"}
 	if !contains(validSecurityPolicies, cfg.SecurityPolicy) {
 		return fmt.Errorf("invalid security_policy: %s, must be one of: %v", cfg.SecurityPolicy, validSecurityPolicies)
@@ -124,7 +869,6 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid security_mode: %s, must be one of: %v", cfg.SecurityMode, validSecurityModes)
 	}
 
-	validAuthTypes := []string{"anonymous", "username_password", "certificate"}
 	if !contains(validAuthTypes, cfg.Auth.Type) {
 		return fmt.Errorf("invalid auth type: %s, must be one of: %v", cfg.Auth.Type, validAuthTypes)
 	}
@@ -136,8 +880,51 @@ func (cfg *Config) Validate() error {
 	}
 
 	if cfg.Auth.Type == "certificate" {
+		if cfg.TLS.CertificateProvider == nil && cfg.GDS.Endpoint == "" && !cfg.TLS.hasApplicationCertificate() {
+			return errors.New("cert_file and key_file are required for certificate authentication unless cert_pem and key_pem, certificate_provider or gds are set")
+		}
+	}
+
+	if err := cfg.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid tls: %w", err)
+	}
+
+	if cfg.TLS.TrustedDirectory != "" && cfg.TLS.CertificateProvider != nil {
+		return errors.New("tls trusted_directory and certificate_provider are mutually exclusive")
+	}
+
+	if err := cfg.TLS.GenerateCertificate.Validate(); err != nil {
+		return fmt.Errorf("invalid tls generate_certificate: %w", err)
+	}
+
+	if cfg.TLS.GenerateCertificate.Enabled {
 		if cfg.TLS.CertFile == "" || cfg.TLS.KeyFile == "" {
-			return errors.New("cert_file and key_file are required for certificate authentication")
+			return errors.New("tls cert_file and key_file are required to store the generated certificate")
+		}
+		if cfg.TLS.CertificateProvider != nil {
+			return errors.New("tls generate_certificate and certificate_provider are mutually exclusive")
+		}
+	}
+
+	if err := cfg.GDS.Validate(); err != nil {
+		return fmt.Errorf("invalid gds: %w", err)
+	}
+
+	if cfg.GDS.Endpoint != "" {
+		if cfg.Mode == collectionModePubSub {
+			return errors.New("gds is not available in pubsub mode")
+		}
+		if cfg.TLS.CertificateProvider != nil || cfg.TLS.hasApplicationCertificate() {
+			return errors.New("gds and the application certificate of tls are mutually exclusive")
+		}
+		if cfg.TLS.TrustedDirectory != "" {
+			return errors.New("gds and tls trusted_directory are mutually exclusive")
+		}
+		if cfg.GDS.ApplicationURI == "" {
+			return errors.New("gds application_uri must be specified")
+		}
+		if cfg.GDS.Directory == "" {
+			return errors.New("gds directory must be specified")
 		}
 	}
 
@@ -146,6 +933,10 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid min_severity: %s, must be one of: Trace, Debug, Info, Warn, Error, Fatal", cfg.Filter.MinSeverity)
 	}
 
+	if cfg.LogSuppressionInterval < 0 {
+		return fmt.Errorf("log_suppression_interval must be non-negative, got: %s", cfg.LogSuppressionInterval)
+	}
+
 	if cfg.Filter.MaxLogRecords < 0 {
 		return fmt.Errorf("max_log_records must be non-negative, got: %d", cfg.Filter.MaxLogRecords)
 	}
@@ -153,6 +944,477 @@ func (cfg *Config) Validate() error {
 	if len(cfg.LogObjectPaths) == 0 {
 		return errors.New("at least one log_object_path must be specified")
 	}
+	for i, path := range cfg.LogObjectPaths {
+		if isNodeID(path) {
+			if err := validateNodeID(path); err != nil {
+				return fmt.Errorf("invalid log_object_paths[%d]: %w", i, err)
+			}
+		}
+	}
+
+	if cfg.LogRecordTypeID != "" {
+		if err := validateNodeID(cfg.LogRecordTypeID); err != nil {
+			return fmt.Errorf("invalid log_record_type_id: %w", err)
+		}
+	}
+
+	if err := cfg.Dialer.Validate(); err != nil {
+		return fmt.Errorf("invalid dialer: %w", err)
+	}
+
+	if err := cfg.ReverseConnect.Validate(); err != nil {
+		return fmt.Errorf("invalid reverse_connect: %w", err)
+	}
+
+	if cfg.ReverseConnect.ListenAddress != "" && cfg.Mode == collectionModePubSub {
+		return errors.New("reverse_connect is not available in pubsub mode")
+	}
+
+	if err := cfg.DebugDump.Validate(); err != nil {
+		return fmt.Errorf("invalid debug_dump: %w", err)
+	}
+
+	names := make(map[string]bool, len(cfg.Metrics))
+	for i, metric := range cfg.Metrics {
+		if err := metric.Validate(); err != nil {
+			return fmt.Errorf("invalid metrics[%d]: %w", i, err)
+		}
+		if names[metric.Name] {
+			return fmt.Errorf("invalid metrics[%d]: duplicate name %s", i, metric.Name)
+		}
+		names[metric.Name] = true
+	}
+
+	if cfg.Diagnostics.GapRecords && cfg.Diagnostics.GapThreshold <= 0 {
+		return fmt.Errorf("diagnostics.gap_threshold must be positive, got: %s", cfg.Diagnostics.GapThreshold)
+	}
+
+	if err := cfg.Health.Validate(); err != nil {
+		return fmt.Errorf("invalid health: %w", err)
+	}
+
+	if err := cfg.Traces.Validate(); err != nil {
+		return fmt.Errorf("invalid traces: %w", err)
+	}
+
+	if err := validateAttributeMappings(cfg.AttributeMappings); err != nil {
+		return fmt.Errorf("invalid attribute_mappings: %w", err)
+	}
+
+	if err := cfg.SeverityDictionary.Validate(); err != nil {
+		return fmt.Errorf("invalid severity_dictionary: %w", err)
+	}
+
+	if err := validateSeverityMapping(cfg.SeverityMapping); err != nil {
+		return fmt.Errorf("invalid severity_mapping: %w", err)
+	}
+
+	if err := cfg.StatusWriteBack.Validate(); err != nil {
+		return fmt.Errorf("invalid status_write_back: %w", err)
+	}
+
+	return nil
+}
+
+// Validate validates the subscription configuration
+func (cfg *SubscriptionConfig) Validate() error {
+	if cfg.PublishingInterval <= 0 {
+		return fmt.Errorf("publishing_interval must be positive, got: %s", cfg.PublishingInterval)
+	}
+
+	if cfg.QueueSize < 1 {
+		return errors.New("queue_size must be at least 1")
+	}
+
+	return nil
+}
+
+// minSeverityValue converts min_severity to the lowest OPC UA severity
+// value that is collected
+func (cfg *FilterConfig) minSeverityValue() uint16 {
+	switch cfg.MinSeverity {
+	case "Trace":
+		return 51
+	case "Debug":
+		return 1
+	case "Info":
+		return 101
+	case "Warn", "Warning":
+		return 201
+	case "Error":
+		return 301
+	case "Fatal", "Emergency":
+		return 401
+	default:
+		return 101 // Default to Info
+	}
+}
+
+// Validate validates the alarms configuration
+func (cfg *AlarmsConfig) Validate() error {
+	if len(cfg.Notifiers) == 0 {
+		return errors.New("at least one notifier must be specified")
+	}
+	for i, notifier := range cfg.Notifiers {
+		if err := validateNodeID(notifier); err != nil {
+			return fmt.Errorf("invalid notifiers[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the PubSub configuration
+func (cfg *PubSubConfig) Validate() error {
+	switch cfg.Transport {
+	case pubsubTransportMQTT:
+		if cfg.QoS > 1 {
+			return fmt.Errorf("qos must be 0 or 1, got: %d", cfg.QoS)
+		}
+	case pubsubTransportUDP:
+		if cfg.encoding() != pubsubEncodingUADP {
+			return errors.New("udp transport requires uadp encoding")
+		}
+	default:
+		return fmt.Errorf("invalid transport: %s, must be one of: [mqtt udp]", cfg.Transport)
+	}
+
+	switch cfg.encoding() {
+	case pubsubEncodingJSON, pubsubEncodingUADP:
+	default:
+		return fmt.Errorf("invalid encoding: %s, must be one of: [json uadp]", cfg.Encoding)
+	}
+
+	if err := cfg.TLS.Validate(); err != nil {
+		return fmt.Errorf("invalid tls: %w", err)
+	}
+
+	return nil
+}
+
+// encoding returns the message mapping, defaulting by transport
+func (cfg *PubSubConfig) encoding() string {
+	if cfg.Encoding != "" {
+		return cfg.Encoding
+	}
+	if cfg.Transport == pubsubTransportUDP {
+		return pubsubEncodingUADP
+	}
+	return pubsubEncodingJSON
+}
+
+// Validate validates the reconnect configuration. Zero values stand for
+// the defaults.
+func (cfg *ReconnectConfig) Validate() error {
+	if cfg.InitialInterval < 0 {
+		return fmt.Errorf("initial_interval must not be negative, got: %s", cfg.InitialInterval)
+	}
+
+	if cfg.MaxInterval < 0 {
+		return fmt.Errorf("max_interval must not be negative, got: %s", cfg.MaxInterval)
+	}
+
+	if cfg.InitialInterval > 0 && cfg.MaxInterval > 0 && cfg.MaxInterval < cfg.InitialInterval {
+		return fmt.Errorf("max_interval must be at least initial_interval, got: %s", cfg.MaxInterval)
+	}
+
+	if cfg.Multiplier != 0 && cfg.Multiplier < 1 {
+		return fmt.Errorf("multiplier must be at least 1, got: %g", cfg.Multiplier)
+	}
+
+	if cfg.RandomizationFactor < 0 || cfg.RandomizationFactor > 1 {
+		return fmt.Errorf("randomization_factor must be between 0 and 1, got: %g", cfg.RandomizationFactor)
+	}
+
+	if cfg.MaxRetries < 0 {
+		return fmt.Errorf("max_retries must not be negative, got: %d", cfg.MaxRetries)
+	}
+
+	return nil
+}
+
+// Validate validates the keepalive configuration
+func (cfg *KeepAliveConfig) Validate() error {
+	if cfg.Interval < 0 {
+		return fmt.Errorf("interval must not be negative, got: %s", cfg.Interval)
+	}
+
+	if cfg.Interval == 0 {
+		return nil
+	}
+
+	if cfg.Interval < time.Second {
+		return fmt.Errorf("interval must be at least 1 second, got: %s", cfg.Interval)
+	}
+
+	if cfg.Timeout <= 0 || cfg.Timeout > cfg.Interval {
+		return fmt.Errorf("timeout must be positive and at most interval, got: %s", cfg.Timeout)
+	}
+
+	if cfg.MaxFailures < 1 {
+		return fmt.Errorf("max_failures must be at least 1, got: %d", cfg.MaxFailures)
+	}
+
+	return nil
+}
+
+// validate validates the redundancy configuration against the primary endpoint
+func (cfg *RedundancyConfig) validate(primary string) error {
+	if len(cfg.BackupEndpoints) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{primary: true}
+	for i, endpoint := range cfg.BackupEndpoints {
+		if !strings.HasPrefix(endpoint, "opc.tcp://") {
+			return fmt.Errorf("backup_endpoints[%d] must start with opc.tcp://, got: %s", i, endpoint)
+		}
+		if seen[endpoint] {
+			return fmt.Errorf("backup_endpoints[%d] duplicates another endpoint: %s", i, endpoint)
+		}
+		seen[endpoint] = true
+	}
+
+	if cfg.FailbackInterval < time.Second {
+		return fmt.Errorf("failback_interval must be at least 1 second, got: %s", cfg.FailbackInterval)
+	}
+
+	return nil
+}
+
+// reservedAttributes are the log attributes the receiver sets itself, which
+// attribute_mappings must not rename AdditionalData entries to
+var reservedAttributes = []string{
+	"opcua.source.name",
+	"opcua.source.namespace",
+	"opcua.source.id_type",
+	"opcua.source.id",
+	"opcua.source.namespace_uri",
+	"opcua.message.locale",
+	"opcua.severity_band",
+}
+
+// validateAttributeMappings validates the renames of AdditionalData entries
+func validateAttributeMappings(mappings map[string]string) error {
+	targets := make(map[string]string, len(mappings))
+	for name, target := range mappings {
+		if name == "" {
+			return errors.New("attribute name must not be empty")
+		}
+		if target == "" {
+			return fmt.Errorf("%s: target name must not be empty", name)
+		}
+		if slices.Contains(reservedAttributes, target) {
+			return fmt.Errorf("%s: target name %s is set by the receiver", name, target)
+		}
+		if other, ok := targets[target]; ok {
+			// Report the pair in a stable order, map iteration is random
+			first, second := min(name, other), max(name, other)
+			return fmt.Errorf("%s and %s are both mapped to %s", first, second, target)
+		}
+		targets[target] = name
+	}
+	return nil
+}
+
+// validateSeverityMapping validates that the ranges map every severity value
+// from 1 to 1000 to exactly one SeverityNumber
+func validateSeverityMapping(ranges []SeverityRangeConfig) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	for _, r := range ranges {
+		if r.Min < 1 || r.Max > 1000 || r.Min > r.Max {
+			return fmt.Errorf("range %d–%d must lie within 1–1000 with min at most max", r.Min, r.Max)
+		}
+		if r.Number < 1 || r.Number > 24 {
+			return fmt.Errorf("number of range %d–%d must be between 1 and 24, got: %d", r.Min, r.Max, r.Number)
+		}
+	}
+
+	sorted := slices.Clone(ranges)
+	slices.SortFunc(sorted, func(a, b SeverityRangeConfig) int { return int(a.Min) - int(b.Min) })
+	next := uint16(1)
+	for _, r := range sorted {
+		switch {
+		case r.Min < next:
+			return fmt.Errorf("range %d–%d overlaps another range", r.Min, r.Max)
+		case r.Min > next:
+			return fmt.Errorf("severity values %d–%d are not mapped", next, r.Min-1)
+		}
+		next = r.Max + 1
+	}
+	if next <= 1000 {
+		return fmt.Errorf("severity values %d–1000 are not mapped", next)
+	}
+
+	return nil
+}
+
+// Validate validates the discovery configuration
+func (cfg *DiscoveryConfig) Validate() error {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(cfg.Endpoint, "opc.tcp://") {
+		return fmt.Errorf("endpoint must start with opc.tcp://, got: %s", cfg.Endpoint)
+	}
+
+	if _, err := regexp.Compile(cfg.ApplicationURIPattern); err != nil {
+		return fmt.Errorf("application_uri_pattern is not a valid regular expression: %w", err)
+	}
+
+	if cfg.Interval < time.Second {
+		return fmt.Errorf("interval must be at least 1 second, got: %s", cfg.Interval)
+	}
+
+	return nil
+}
+
+// Validate validates a variable metric
+func (cfg *VariableMetricConfig) Validate() error {
+	if cfg.Node == "" {
+		return errors.New("node must be specified")
+	}
+	if isNodeID(cfg.Node) {
+		if err := validateNodeID(cfg.Node); err != nil {
+			return fmt.Errorf("invalid node: %w", err)
+		}
+	}
+
+	if cfg.Name == "" {
+		return errors.New("name must be specified")
+	}
+
+	switch cfg.Type {
+	case "", metricTypeGauge, metricTypeSum:
+	default:
+		return fmt.Errorf("invalid type: %s, must be one of: [gauge sum]", cfg.Type)
+	}
+
+	switch cfg.ValueType {
+	case "", valueTypeInt, valueTypeDouble:
+	default:
+		return fmt.Errorf("invalid value_type: %s, must be one of: [int double]", cfg.ValueType)
+	}
+
+	return nil
+}
+
+// Validate validates the dialer configuration
+func (cfg *DialerConfig) Validate() error {
+	if cfg.LocalAddress != "" && cfg.Interface != "" {
+		return errors.New("local_address and interface are mutually exclusive")
+	}
+
+	if cfg.LocalAddress != "" && net.ParseIP(cfg.LocalAddress) == nil {
+		return fmt.Errorf("local_address must be an IP address, got: %s", cfg.LocalAddress)
+	}
+
+	if cfg.DSCP < 0 || cfg.DSCP > 63 {
+		return fmt.Errorf("dscp must be between 0 and 63, got: %d", cfg.DSCP)
+	}
+
+	if cfg.DSCP != 0 && runtime.GOOS == "windows" {
+		return errors.New("dscp is not supported on windows, use a QoS policy instead")
+	}
+
+	return nil
+}
+
+// Validate validates the GDS configuration
+func (cfg *GDSConfig) Validate() error {
+	if cfg.Endpoint == "" {
+		return nil
+	}
+
+	if !strings.HasPrefix(cfg.Endpoint, "opc.tcp://") {
+		return fmt.Errorf("endpoint must start with opc.tcp://, got: %s", cfg.Endpoint)
+	}
+
+	if !contains(validAuthTypes, cfg.Auth.Type) {
+		return fmt.Errorf("invalid auth type: %s, must be one of: %v", cfg.Auth.Type, validAuthTypes)
+	}
+
+	if cfg.Auth.Type == "username_password" && (cfg.Auth.Username == "" || cfg.Auth.Password == "") {
+		return errors.New("username and password are required for username_password authentication")
+	}
+
+	if cfg.ApplicationURI != "" {
+		if _, err := url.Parse(cfg.ApplicationURI); err != nil {
+			return fmt.Errorf("invalid application_uri: %w", err)
+		}
+	}
+
+	if cfg.RenewBefore <= 0 {
+		return fmt.Errorf("renew_before must be positive, got: %s", cfg.RenewBefore)
+	}
+
+	if cfg.CheckInterval < time.Minute {
+		return fmt.Errorf("check_interval must be at least 1 minute, got: %s", cfg.CheckInterval)
+	}
+
+	return nil
+}
+
+// validKeySizes are the RSA key sizes of generate_certificate.key_size
+var validKeySizes = []int{2048, 3072, 4096}
+
+// Validate validates the generated certificate settings
+func (cfg *GenerateCertificateConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.ApplicationURI != "" {
+		uri, err := url.Parse(cfg.ApplicationURI)
+		if err != nil {
+			return fmt.Errorf("invalid application_uri: %w", err)
+		}
+		if uri.Scheme == "" {
+			return fmt.Errorf("application_uri must be an absolute URI, got: %s", cfg.ApplicationURI)
+		}
+	}
+
+	if !slices.Contains(validKeySizes, cfg.KeySize) {
+		return fmt.Errorf("invalid key_size: %d, must be one of: %v", cfg.KeySize, validKeySizes)
+	}
+
+	if cfg.Validity < 24*time.Hour {
+		return fmt.Errorf("validity must be at least 24h, got: %s", cfg.Validity)
+	}
+
+	return nil
+}
+
+// Validate validates the reverse connect configuration
+func (cfg *ReverseConnectConfig) Validate() error {
+	if cfg.ListenAddress == "" {
+		return nil
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.ListenAddress); err != nil {
+		return fmt.Errorf("listen_address must be host:port, got: %s", cfg.ListenAddress)
+	}
+
+	return nil
+}
+
+// Validate validates the debug dump configuration
+func (cfg *DebugDumpConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.MaxBytes < 1 {
+		return fmt.Errorf("max_bytes must be positive, got: %d", cfg.MaxBytes)
+	}
+
+	if cfg.MaxPerMinute < 1 {
+		return fmt.Errorf("max_per_minute must be positive, got: %d", cfg.MaxPerMinute)
+	}
 
 	return nil
 }
@@ -166,3 +1428,67 @@ func contains(slice []string, item string) bool {
 	}
 	return false
 }
+
+// Validate validates the health configuration
+func (cfg *HealthConfig) Validate() error {
+	if cfg.ErrorRateThreshold < 0 || cfg.ErrorRateThreshold > 1 {
+		return fmt.Errorf("error_rate_threshold must be between 0 and 1, got: %g", cfg.ErrorRateThreshold)
+	}
+
+	if cfg.ErrorRateThreshold == 0 {
+		return nil
+	}
+
+	if cfg.ErrorRateWindow <= 0 {
+		return fmt.Errorf("error_rate_window must be positive, got: %s", cfg.ErrorRateWindow)
+	}
+
+	if cfg.MinScrapes < 1 {
+		return fmt.Errorf("min_scrapes must be positive, got: %d", cfg.MinScrapes)
+	}
+
+	return nil
+}
+
+// Validate validates the status write-back configuration
+func (cfg *StatusWriteBackConfig) Validate() error {
+	if cfg.NodeID == "" {
+		return nil
+	}
+
+	if _, err := ua.ParseNodeID(cfg.NodeID); err != nil {
+		return fmt.Errorf("node_id must be a NodeID, got: %s", cfg.NodeID)
+	}
+
+	if cfg.Interval < 0 {
+		return fmt.Errorf("interval must not be negative, got: %s", cfg.Interval)
+	}
+
+	return nil
+}
+
+// Validate validates the severity dictionary configuration
+func (cfg *SeverityDictionaryConfig) Validate() error {
+	if cfg.NodeID != "" {
+		if _, err := ua.ParseNodeID(cfg.NodeID); err != nil {
+			return fmt.Errorf("node_id must be a NodeID, got: %s", cfg.NodeID)
+		}
+	}
+
+	seen := make(map[uint16]bool, len(cfg.Entries))
+	for _, e := range cfg.Entries {
+		if seen[e.Severity] {
+			return fmt.Errorf("duplicate entry for severity %d", e.Severity)
+		}
+		seen[e.Severity] = true
+
+		if e.Number < 0 || e.Number > 24 {
+			return fmt.Errorf("number of severity %d must be between 1 and 24, got: %d", e.Severity, e.Number)
+		}
+		if e.Text == "" && e.Number == 0 {
+			return fmt.Errorf("entry for severity %d needs a text or number", e.Severity)
+		}
+	}
+
+	return nil
+}
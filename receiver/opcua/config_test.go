@@ -4,14 +4,20 @@
 package opcua
 
 import (
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/scraper/scraperhelper"
 )
 
 func TestConfigValidate(t *testing.T) {
+	certProviderID := component.MustNewID("opcua_pki")
+
 	tests := []struct {
 		name    string
 		config  *Config
@@ -21,16 +27,16 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "valid config with defaults",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "None",
-				SecurityMode:       "None",
-				Auth:               AuthConfig{Type: "anonymous"},
-				LogObjectPaths:     []string{"Objects/ServerLog"},
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  1000,
-				ConnectionTimeout:  30 * time.Second,
-				RequestTimeout:     10 * time.Second,
-				Filter:             FilterConfig{MinSeverity: "Info"},
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "anonymous"},
+				LogObjectPaths:    []string{"Objects/ServerLog"},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				ConnectionTimeout: 30 * time.Second,
+				RequestTimeout:    10 * time.Second,
+				Filter:            FilterConfig{MinSeverity: "Info"},
 			},
 			wantErr: false,
 		},
@@ -53,8 +59,8 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "collection interval too short",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				CollectionInterval: 500 * time.Millisecond,
+				Endpoint:         "opc.tcp://localhost:4840",
+				ControllerConfig: scraperhelper.ControllerConfig{CollectionInterval: 500 * time.Millisecond},
 			},
 			wantErr: true,
 			errMsg:  "collection_interval must be at least 1 second",
@@ -62,9 +68,9 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "max records too low",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  0,
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 0,
 			},
 			wantErr: true,
 			errMsg:  "max_records_per_call must be between 1 and 10000",
@@ -72,9 +78,9 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "max records too high",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  15000,
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 15000,
 			},
 			wantErr: true,
 			errMsg:  "max_records_per_call must be between 1 and 10000",
@@ -82,10 +88,10 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "invalid security policy",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "InvalidPolicy",
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  1000,
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "InvalidPolicy",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
 			},
 			wantErr: true,
 			errMsg:  "invalid security_policy",
@@ -93,11 +99,11 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "invalid security mode",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "None",
-				SecurityMode:       "InvalidMode",
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  1000,
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "InvalidMode",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
 			},
 			wantErr: true,
 			errMsg:  "invalid security_mode",
@@ -105,13 +111,13 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "username_password auth without credentials",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "None",
-				SecurityMode:       "None",
-				Auth:               AuthConfig{Type: "username_password"},
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  1000,
-				LogObjectPaths:     []string{"Objects/ServerLog"},
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "username_password"},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LogObjectPaths:    []string{"Objects/ServerLog"},
 			},
 			wantErr: true,
 			errMsg:  "username and password are required",
@@ -119,45 +125,166 @@ func TestConfigValidate(t *testing.T) {
 		{
 			name: "certificate auth without cert files",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "None",
-				SecurityMode:       "None",
-				Auth:               AuthConfig{Type: "certificate"},
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  1000,
-				LogObjectPaths:     []string{"Objects/ServerLog"},
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "certificate"},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LogObjectPaths:    []string{"Objects/ServerLog"},
 			},
 			wantErr: true,
 			errMsg:  "cert_file and key_file are required",
 		},
+		{
+			name: "certificate auth with certificate provider",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "certificate"},
+				TLS:               TLSConfig{CertificateProvider: &certProviderID},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LogObjectPaths:    []string{"Objects/ServerLog"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "trusted directory with certificate provider",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "anonymous"},
+				TLS:               TLSConfig{TrustedDirectory: "pki/trusted/certs", CertificateProvider: &certProviderID},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LogObjectPaths:    []string{"Objects/ServerLog"},
+			},
+			wantErr: true,
+			errMsg:  "trusted_directory and certificate_provider are mutually exclusive",
+		},
 		{
 			name: "invalid severity level",
 			config: &Config{
-				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "None",
-				SecurityMode:       "None",
-				Auth:               AuthConfig{Type: "anonymous"},
-				CollectionInterval: 30 * time.Second,
-				MaxRecordsPerCall:  1000,
-				LogObjectPaths:     []string{"Objects/ServerLog"},
-				Filter:             FilterConfig{MinSeverity: "InvalidLevel"},
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "anonymous"},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LogObjectPaths:    []string{"Objects/ServerLog"},
+				Filter:            FilterConfig{MinSeverity: "InvalidLevel"},
 			},
 			wantErr: true,
 			errMsg:  "invalid min_severity",
 		},
+		{
+			name: "negative log suppression interval",
+			config: &Config{
+				Endpoint:               "opc.tcp://localhost:4840",
+				SecurityPolicy:         "None",
+				SecurityMode:           "None",
+				Auth:                   AuthConfig{Type: "anonymous"},
+				ControllerConfig:       scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall:      1000,
+				LogObjectPaths:         []string{"Objects/ServerLog"},
+				LogSuppressionInterval: -time.Second,
+			},
+			wantErr: true,
+			errMsg:  "log_suppression_interval must be non-negative",
+		},
 		{
 			name: "no log object paths",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				SecurityPolicy:    "None",
+				SecurityMode:      "None",
+				Auth:              AuthConfig{Type: "anonymous"},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LogObjectPaths:    []string{},
+			},
+			wantErr: true,
+			errMsg:  "at least one log_object_path must be specified",
+		},
+		{
+			name: "max catchup duration not longer than collection interval",
 			config: &Config{
 				Endpoint:           "opc.tcp://localhost:4840",
-				SecurityPolicy:     "None",
-				SecurityMode:       "None",
-				Auth:               AuthConfig{Type: "anonymous"},
-				CollectionInterval: 30 * time.Second,
+				ControllerConfig:   scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
 				MaxRecordsPerCall:  1000,
-				LogObjectPaths:     []string{},
+				MaxCatchupDuration: 30 * time.Second,
 			},
 			wantErr: true,
-			errMsg:  "at least one log_object_path must be specified",
+			errMsg:  "max_catchup_duration must be 0 or longer than collection_interval",
+		},
+		{
+			name: "max concurrent reads too high",
+			config: &Config{
+				Endpoint:           "opc.tcp://localhost:4840",
+				ControllerConfig:   scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall:  1000,
+				MaxConcurrentReads: 65,
+			},
+			wantErr: true,
+			errMsg:  "max_concurrent_reads must be between 0 and 64",
+		},
+		{
+			name: "negative flush size",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				FlushSize:         -1,
+			},
+			wantErr: true,
+			errMsg:  "flush_size must not be negative",
+		},
+		{
+			name: "negative max spooled batches",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				MaxSpooledBatches: -1,
+			},
+			wantErr: true,
+			errMsg:  "max_spooled_batches must not be negative",
+		},
+		{
+			name: "invalid traces parent span",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				Traces:            TracesConfig{ParentSpan: "child"},
+			},
+			wantErr: true,
+			errMsg:  "invalid traces: invalid parent_span: child, must be one of: [parent link none]",
+		},
+		{
+			name: "invalid body format",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				BodyFormat:        "json",
+			},
+			wantErr: true,
+			errMsg:  "invalid body_format: json",
+		},
+		{
+			name: "empty locale id",
+			config: &Config{
+				Endpoint:          "opc.tcp://localhost:4840",
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+				MaxRecordsPerCall: 1000,
+				LocaleIDs:         []string{"de-DE", " "},
+			},
+			wantErr: true,
+			errMsg:  "locale_ids[1] must not be empty",
 		},
 		{
 			name: "valid config with all security options",
@@ -170,11 +297,12 @@ func TestConfigValidate(t *testing.T) {
 					Username: "user",
 					Password: "pass",
 				},
-				LogObjectPaths:     []string{"Objects/ServerLog", "Objects/DeviceLog"},
-				CollectionInterval: 60 * time.Second,
-				MaxRecordsPerCall:  500,
-				ConnectionTimeout:  30 * time.Second,
-				RequestTimeout:     10 * time.Second,
+				LogObjectPaths:    []string{"Objects/ServerLog", "Objects/DeviceLog"},
+				ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 60 * time.Second},
+				MaxRecordsPerCall: 500,
+				ConnectionTimeout: 30 * time.Second,
+				RequestTimeout:    10 * time.Second,
+				LocaleIDs:         []string{"de-DE", "en"},
 				Filter: FilterConfig{
 					MinSeverity:   "Warn",
 					MaxLogRecords: 5000,
@@ -197,6 +325,194 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestDialerConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  DialerConfig
+		wantErr string
+	}{
+		{name: "empty", config: DialerConfig{}},
+		{name: "keep alive and local address", config: DialerConfig{KeepAlive: 15 * time.Second, LocalAddress: "10.0.0.5"}},
+		{name: "interface", config: DialerConfig{Interface: "eth1"}},
+		{name: "local address and interface", config: DialerConfig{LocalAddress: "10.0.0.5", Interface: "eth1"}, wantErr: "mutually exclusive"},
+		{name: "local address with port", config: DialerConfig{LocalAddress: "10.0.0.5:1234"}, wantErr: "must be an IP address"},
+		{name: "dscp out of range", config: DialerConfig{DSCP: 64}, wantErr: "dscp must be between 0 and 63"},
+		{name: "negative dscp", config: DialerConfig{DSCP: -1}, wantErr: "dscp must be between 0 and 63"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDebugDumpConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  DebugDumpConfig
+		wantErr string
+	}{
+		{name: "disabled", config: DebugDumpConfig{}},
+		{name: "enabled", config: DebugDumpConfig{Enabled: true, MaxBytes: 1024, MaxPerMinute: 1}},
+		{name: "zero max bytes", config: DebugDumpConfig{Enabled: true, MaxPerMinute: 1}, wantErr: "max_bytes must be positive"},
+		{name: "zero max per minute", config: DebugDumpConfig{Enabled: true, MaxBytes: 1024}, wantErr: "max_per_minute must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHealthConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  HealthConfig
+		wantErr string
+	}{
+		{name: "disabled", config: HealthConfig{}},
+		{name: "enabled", config: HealthConfig{ErrorRateWindow: time.Minute, ErrorRateThreshold: 0.5, MinScrapes: 3}},
+		{name: "threshold above one", config: HealthConfig{ErrorRateWindow: time.Minute, ErrorRateThreshold: 1.5, MinScrapes: 3}, wantErr: "error_rate_threshold must be between 0 and 1"},
+		{name: "negative threshold", config: HealthConfig{ErrorRateThreshold: -0.1}, wantErr: "error_rate_threshold must be between 0 and 1"},
+		{name: "zero window", config: HealthConfig{ErrorRateThreshold: 0.5, MinScrapes: 3}, wantErr: "error_rate_window must be positive"},
+		{name: "zero min scrapes", config: HealthConfig{ErrorRateWindow: time.Minute, ErrorRateThreshold: 0.5}, wantErr: "min_scrapes must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestStatusWriteBackConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  StatusWriteBackConfig
+		wantErr string
+	}{
+		{name: "disabled", config: StatusWriteBackConfig{Interval: -time.Second}},
+		{name: "enabled", config: StatusWriteBackConfig{NodeID: "ns=2;s=Collector.LastCollection", Interval: time.Minute}},
+		{name: "every collection", config: StatusWriteBackConfig{NodeID: "ns=2;i=5001"}},
+		{name: "invalid node id", config: StatusWriteBackConfig{NodeID: "Objects/Status"}, wantErr: "node_id must be a NodeID"},
+		{name: "negative interval", config: StatusWriteBackConfig{NodeID: "ns=2;i=5001", Interval: -time.Second}, wantErr: "interval must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSeverityDictionaryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  SeverityDictionaryConfig
+		wantErr string
+	}{
+		{name: "disabled"},
+		{name: "server dictionary", config: SeverityDictionaryConfig{NodeID: "ns=2;s=Log.SeverityNames"}},
+		{
+			name: "entries",
+			config: SeverityDictionaryConfig{Entries: []SeverityDictionaryEntry{
+				{Severity: 1, Text: "Info"},
+				{Severity: 2, Number: 13},
+				{Severity: 3, Text: "Störung", Number: 17},
+			}},
+		},
+		{name: "invalid node id", config: SeverityDictionaryConfig{NodeID: "Objects/Severities"}, wantErr: "node_id must be a NodeID"},
+		{
+			name:    "duplicate severity",
+			config:  SeverityDictionaryConfig{Entries: []SeverityDictionaryEntry{{Severity: 1, Text: "Info"}, {Severity: 1, Text: "Debug"}}},
+			wantErr: "duplicate entry for severity 1",
+		},
+		{
+			name:    "number out of range",
+			config:  SeverityDictionaryConfig{Entries: []SeverityDictionaryEntry{{Severity: 1, Number: 25}}},
+			wantErr: "must be between 1 and 24",
+		},
+		{
+			name:    "empty entry",
+			config:  SeverityDictionaryConfig{Entries: []SeverityDictionaryEntry{{Severity: 1}}},
+			wantErr: "needs a text or number",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidateAttributeMappings(t *testing.T) {
+	tests := []struct {
+		name     string
+		mappings map[string]string
+		wantErr  string
+	}{
+		{name: "none"},
+		{name: "renames", mappings: map[string]string{"sensor_id": "device.id", "LineNo": "production.line"}},
+		{name: "empty name", mappings: map[string]string{"": "device.id"}, wantErr: "attribute name must not be empty"},
+		{name: "empty target", mappings: map[string]string{"sensor_id": ""}, wantErr: "sensor_id: target name must not be empty"},
+		{
+			name:     "reserved target",
+			mappings: map[string]string{"source": "opcua.source.name"},
+			wantErr:  "target name opcua.source.name is set by the receiver",
+		},
+		{
+			name:     "same target",
+			mappings: map[string]string{"sensor_id": "device.id", "SensorID": "device.id"},
+			wantErr:  "SensorID and sensor_id are both mapped to device.id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAttributeMappings(tt.mappings)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestDefaultConfig(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig()
@@ -212,11 +528,35 @@ func TestDefaultConfig(t *testing.T) {
 	assert.Equal(t, []string{"Objects/ServerLog"}, opcuaCfg.LogObjectPaths)
 	assert.Equal(t, 30*time.Second, opcuaCfg.CollectionInterval)
 	assert.Equal(t, 1000, opcuaCfg.MaxRecordsPerCall)
+	assert.Equal(t, 1, opcuaCfg.MaxConcurrentReads)
+	assert.Equal(t, 1000, opcuaCfg.MaxSpooledBatches)
 	assert.Equal(t, "Info", opcuaCfg.Filter.MinSeverity)
+	assert.Equal(t, "string", opcuaCfg.BodyFormat)
 	assert.Equal(t, "opcua-server", opcuaCfg.Resource.ServiceName)
 	assert.Equal(t, "", opcuaCfg.Resource.ServiceNamespace)
+	assert.Equal(t, TracesConfig{ParentSpan: "parent", SpanEvents: true}, opcuaCfg.Traces)
 
 	// Validate default config
 	err := opcuaCfg.Validate()
 	assert.NoError(t, err)
 }
+
+func TestAuthPasswordRedacted(t *testing.T) {
+	const secret = "s3cret-p4ss"
+	cfg := createDefaultConfig().(*Config)
+	cfg.Auth = AuthConfig{Type: "username_password", Username: "operator", Password: secret}
+	require.NoError(t, cfg.Validate())
+
+	for _, format := range []string{"%v", "%+v", "%#v"} {
+		assert.NotContains(t, fmt.Sprintf(format, cfg), secret, format)
+		assert.NotContains(t, fmt.Sprintf(format, cfg.Auth), secret, format)
+	}
+
+	out, err := json.Marshal(cfg.Auth)
+	require.NoError(t, err)
+	assert.NotContains(t, string(out), secret)
+	assert.Contains(t, string(out), "[REDACTED]")
+
+	// The client still authenticates with the actual password
+	assert.Equal(t, secret, string(cfg.Auth.Password))
+}
@@ -0,0 +1,538 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build conformance
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"text/tabwriter"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// The conformance suite runs a checklist of Part 26 interactions against the server
+// named by OPCUA_CONFORMANCE_ENDPOINT and prints a report. It is skipped when the
+// variable is unset. Optional variables:
+//
+//	OPCUA_CONFORMANCE_LOG_OBJECT       LogObject NodeId or browse path (default: discovery)
+//	OPCUA_CONFORMANCE_SECURITY_POLICY  security policy, e.g. Basic256Sha256 (default: None)
+//	OPCUA_CONFORMANCE_SECURITY_MODE    security mode, e.g. SignAndEncrypt (default: None)
+//	OPCUA_CONFORMANCE_USERNAME         user name for username_password authentication
+//	OPCUA_CONFORMANCE_PASSWORD         password for username_password authentication
+//	OPCUA_CONFORMANCE_REPORT           file the report is also written to
+const (
+	conformanceEndpointEnv       = "OPCUA_CONFORMANCE_ENDPOINT"
+	conformanceLogObjectEnv      = "OPCUA_CONFORMANCE_LOG_OBJECT"
+	conformanceSecurityPolicyEnv = "OPCUA_CONFORMANCE_SECURITY_POLICY"
+	conformanceSecurityModeEnv   = "OPCUA_CONFORMANCE_SECURITY_MODE"
+	conformanceUsernameEnv       = "OPCUA_CONFORMANCE_USERNAME"
+	conformancePasswordEnv       = "OPCUA_CONFORMANCE_PASSWORD"
+	conformanceReportEnv         = "OPCUA_CONFORMANCE_REPORT"
+
+	// conformancePageSize is the MaxReturnRecords used to read the baseline
+	conformancePageSize = 1000
+	// conformanceSmallPage is the MaxReturnRecords used by the paging checks
+	conformanceSmallPage = 3
+	// conformanceMaxPages bounds continuation point loops against misbehaving servers
+	conformanceMaxPages = 10000
+	// conformanceAllFields is the LogRecordMask requesting every optional field
+	conformanceAllFields = uint32(0x1F)
+)
+
+// errConformanceSkip marks a check whose prerequisites are not met
+var errConformanceSkip = errors.New("skipped")
+
+// conformanceStatus is the outcome of a single check
+type conformanceStatus string
+
+const (
+	conformancePass conformanceStatus = "PASS"
+	conformanceFail conformanceStatus = "FAIL"
+	conformanceWarn conformanceStatus = "WARN"
+	conformanceSkip conformanceStatus = "SKIP"
+)
+
+// conformanceCheck is one entry of the checklist. Advisory checks cover behavior the
+// receiver tolerates either way; their failures are reported as WARN.
+type conformanceCheck struct {
+	id       string
+	name     string
+	ref      string
+	advisory bool
+	run      func(ctx context.Context, s *conformanceSession) (string, error)
+}
+
+// conformanceResult is the outcome of a check as printed in the report
+type conformanceResult struct {
+	check  conformanceCheck
+	status conformanceStatus
+	detail string
+}
+
+// conformanceSession is the state shared by the checks of one run
+type conformanceSession struct {
+	cfg      *Config
+	client   *opcuaClient
+	objectID *ua.NodeID
+	methodID *ua.NodeID
+	// endTime is fixed at the start of the run so every read sees the same window
+	endTime  time.Time
+	baseline []model.LogRecord
+}
+
+// conformanceChecks is the Part 26 checklist in execution order; later checks use
+// the session state established by earlier ones.
+var conformanceChecks = []conformanceCheck{
+	{
+		id:   "C01",
+		name: "Endpoint discovery",
+		ref:  "Part 4 §5.4.4",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			endpoints, err := opcua.GetEndpoints(ctx, s.cfg.Endpoint)
+			if err != nil {
+				return "", err
+			}
+			if len(endpoints) == 0 {
+				return "", errors.New("server returned no endpoints")
+			}
+			ep := newOPCUAClient(s.cfg, zap.NewNop()).selectEndpoint(endpoints)
+			if ep == nil {
+				return "", fmt.Errorf("none of %d endpoints matches security policy %q mode %q",
+					len(endpoints), s.cfg.SecurityPolicy, s.cfg.SecurityMode)
+			}
+			return fmt.Sprintf("%d endpoints, selected %s %s",
+				len(endpoints), strings.TrimPrefix(ep.SecurityPolicyURI, "http://opcfoundation.org/UA/SecurityPolicy#"), ep.SecurityMode), nil
+		},
+	},
+	{
+		id:   "C02",
+		name: "Session and LogObject discovery",
+		ref:  "Part 26 §5.2",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			c := newOPCUAClient(s.cfg, zap.NewNop())
+			if err := c.Connect(ctx); err != nil {
+				return "", err
+			}
+			s.client = c
+			if len(c.logObjectIDs) == 0 {
+				return "", errors.New("no LogObject found")
+			}
+			s.objectID = c.logObjectIDs[0]
+			return "LogObject " + s.objectID.String(), nil
+		},
+	},
+	{
+		id:   "C03",
+		name: "GetRecords method is a component of the LogObject",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.objectID == nil {
+				return "", errConformanceSkip
+			}
+			methodID, err := s.client.findGetRecordsMethod(ctx, s.objectID)
+			if err != nil {
+				return "", err
+			}
+			s.methodID = methodID
+			return "method " + methodID.String(), nil
+		},
+	},
+	{
+		id:   "C04",
+		name: "GetRecords returns Good with two output arguments",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.methodID == nil {
+				return "", errConformanceSkip
+			}
+			result, err := s.call(ctx, time.Time{}, s.endTime, conformancePageSize, 0, nil)
+			if err != nil {
+				return "", err
+			}
+			if result.StatusCode != ua.StatusOK {
+				return "", fmt.Errorf("status %v", result.StatusCode)
+			}
+			if len(result.OutputArguments) != 2 {
+				return "", fmt.Errorf("%d output arguments", len(result.OutputArguments))
+			}
+			records, err := s.client.parseLogRecordsDataType(result.OutputArguments[0])
+			if err != nil {
+				return "", fmt.Errorf("decode LogRecords: %w", err)
+			}
+			return fmt.Sprintf("%d records in first page", len(records)), nil
+		},
+	},
+	{
+		id:   "C05",
+		name: "Continuation points read the full history",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.methodID == nil {
+				return "", errConformanceSkip
+			}
+			records, pages, err := s.readAll(ctx, time.Time{}, s.endTime, conformancePageSize, 0)
+			if err != nil {
+				return "", err
+			}
+			s.baseline = append([]model.LogRecord{}, records...)
+			return fmt.Sprintf("%d records in %d pages", len(records), pages), nil
+		},
+	},
+	{
+		id:   "C06",
+		name: "MaxReturnRecords is honored",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.baseline == nil {
+				return "", errConformanceSkip
+			}
+			result, err := s.call(ctx, time.Time{}, s.endTime, 1, 0, nil)
+			if err != nil {
+				return "", err
+			}
+			if result.StatusCode != ua.StatusOK || len(result.OutputArguments) != 2 {
+				return "", fmt.Errorf("status %v with %d output arguments", result.StatusCode, len(result.OutputArguments))
+			}
+			records, err := s.client.parseLogRecordsDataType(result.OutputArguments[0])
+			if err != nil {
+				return "", err
+			}
+			if len(records) > 1 {
+				return "", fmt.Errorf("returned %d records for MaxReturnRecords=1", len(records))
+			}
+			if len(s.baseline) > 1 && len(continuationPointOf(result)) == 0 {
+				return "", errors.New("no continuation point although more records are available")
+			}
+			return fmt.Sprintf("%d of %d records", len(records), len(s.baseline)), nil
+		},
+	},
+	{
+		id:   "C07",
+		name: "Small pages return the same records as large pages",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.baseline == nil {
+				return "", errConformanceSkip
+			}
+			records, pages, err := s.readAll(ctx, time.Time{}, s.endTime, conformanceSmallPage, 0)
+			if err != nil {
+				return "", err
+			}
+			if len(records) != len(s.baseline) {
+				return "", fmt.Errorf("%d records in pages of %d, %d in pages of %d",
+					len(records), conformanceSmallPage, len(s.baseline), conformancePageSize)
+			}
+			for i := range records {
+				if !conformanceSameRecord(records[i], s.baseline[i]) {
+					return "", fmt.Errorf("record %d differs between page sizes", i)
+				}
+			}
+			return fmt.Sprintf("%d pages of at most %d", pages, conformanceSmallPage), nil
+		},
+	},
+	{
+		id:       "C08",
+		name:     "Records are ordered by time",
+		ref:      "Part 26 §5.3",
+		advisory: true,
+		run: func(_ context.Context, s *conformanceSession) (string, error) {
+			if len(s.baseline) < 2 {
+				return "", errConformanceSkip
+			}
+			for i := 1; i < len(s.baseline); i++ {
+				if s.baseline[i].Timestamp.Before(s.baseline[i-1].Timestamp) {
+					return "", fmt.Errorf("record %d (%s) is older than record %d (%s)",
+						i, s.baseline[i].Timestamp.Format(time.RFC3339Nano), i-1, s.baseline[i-1].Timestamp.Format(time.RFC3339Nano))
+				}
+			}
+			return "ascending", nil
+		},
+	},
+	{
+		id:   "C09",
+		name: "StartTime and EndTime bound the result",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if len(s.baseline) < 2 {
+				return "", errConformanceSkip
+			}
+			start := s.baseline[len(s.baseline)/2].Timestamp
+			end := s.baseline[len(s.baseline)-1].Timestamp
+			if !end.After(start) {
+				return "", errConformanceSkip
+			}
+			records, _, err := s.readAll(ctx, start, end, conformancePageSize, 0)
+			if err != nil {
+				return "", err
+			}
+			for i, r := range records {
+				if r.Timestamp.Before(start) || r.Timestamp.After(end) {
+					return "", fmt.Errorf("record %d at %s is outside [%s, %s]",
+						i, r.Timestamp.Format(time.RFC3339Nano), start.Format(time.RFC3339Nano), end.Format(time.RFC3339Nano))
+				}
+			}
+			return fmt.Sprintf("%d records in [%s, %s]", len(records), start.Format(time.RFC3339), end.Format(time.RFC3339)), nil
+		},
+	},
+	{
+		id:   "C10",
+		name: "MinimumSeverity filters records",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if len(s.baseline) == 0 {
+				return "", errConformanceSkip
+			}
+			var minSeverity uint16
+			for _, r := range s.baseline {
+				minSeverity = max(minSeverity, r.Severity)
+			}
+			want := 0
+			for _, r := range s.baseline {
+				if r.Severity >= minSeverity {
+					want++
+				}
+			}
+			records, _, err := s.readAll(ctx, time.Time{}, s.endTime, conformancePageSize, minSeverity)
+			if err != nil {
+				return "", err
+			}
+			for i, r := range records {
+				if r.Severity < minSeverity {
+					return "", fmt.Errorf("record %d has severity %d below %d", i, r.Severity, minSeverity)
+				}
+			}
+			if len(records) != want {
+				return "", fmt.Errorf("%d records at severity >= %d, baseline has %d", len(records), minSeverity, want)
+			}
+			return fmt.Sprintf("%d records at severity >= %d", len(records), minSeverity), nil
+		},
+	},
+	{
+		id:   "C11",
+		name: "Unknown continuation point is rejected",
+		ref:  "Part 26 §5.3",
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.methodID == nil {
+				return "", errConformanceSkip
+			}
+			result, err := s.call(ctx, time.Time{}, s.endTime, conformancePageSize, 0, []byte("opcua-receiver-conformance"))
+			if err != nil {
+				return "", err
+			}
+			if result.StatusCode != ua.StatusBadContinuationPointInvalid {
+				return "", fmt.Errorf("status %v, want %v", result.StatusCode, ua.StatusBadContinuationPointInvalid)
+			}
+			return result.StatusCode.Error(), nil
+		},
+	},
+	{
+		id:       "C12",
+		name:     "EndTime before StartTime is rejected",
+		ref:      "Part 26 §5.3",
+		advisory: true,
+		run: func(ctx context.Context, s *conformanceSession) (string, error) {
+			if s.methodID == nil {
+				return "", errConformanceSkip
+			}
+			result, err := s.call(ctx, s.endTime, s.endTime.Add(-time.Hour), conformancePageSize, 0, nil)
+			if err != nil {
+				return "", err
+			}
+			if result.StatusCode != ua.StatusBadInvalidArgument {
+				return "", fmt.Errorf("status %v, want %v", result.StatusCode, ua.StatusBadInvalidArgument)
+			}
+			return result.StatusCode.Error(), nil
+		},
+	},
+}
+
+// call invokes GetRecords directly so the checks see the raw status code
+func (s *conformanceSession) call(
+	ctx context.Context,
+	startTime, endTime time.Time,
+	maxRecords uint32,
+	minSeverity uint16,
+	continuationPoint []byte,
+) (*ua.CallMethodResult, error) {
+	return s.client.client.Call(ctx, &ua.CallMethodRequest{
+		ObjectID: s.objectID,
+		MethodID: s.methodID,
+		InputArguments: []*ua.Variant{
+			ua.MustVariant(startTime),
+			ua.MustVariant(endTime),
+			ua.MustVariant(maxRecords),
+			ua.MustVariant(minSeverity),
+			ua.MustVariant(conformanceAllFields),
+			ua.MustVariant(continuationPoint),
+		},
+	})
+}
+
+// readAll follows continuation points until the server reports the end of the result
+func (s *conformanceSession) readAll(
+	ctx context.Context,
+	startTime, endTime time.Time,
+	pageSize uint32,
+	minSeverity uint16,
+) ([]model.LogRecord, int, error) {
+	var all []model.LogRecord
+	var continuationPoint []byte
+	for pages := 1; pages <= conformanceMaxPages; pages++ {
+		result, err := s.call(ctx, startTime, endTime, pageSize, minSeverity, continuationPoint)
+		if err != nil {
+			return nil, pages, err
+		}
+		if result.StatusCode != ua.StatusOK {
+			return nil, pages, fmt.Errorf("page %d: status %v", pages, result.StatusCode)
+		}
+		if len(result.OutputArguments) != 2 {
+			return nil, pages, fmt.Errorf("page %d: %d output arguments", pages, len(result.OutputArguments))
+		}
+		records, err := s.client.parseLogRecordsDataType(result.OutputArguments[0])
+		if err != nil {
+			return nil, pages, fmt.Errorf("page %d: %w", pages, err)
+		}
+		if uint32(len(records)) > pageSize { //nolint:gosec
+			return nil, pages, fmt.Errorf("page %d: %d records for MaxReturnRecords=%d", pages, len(records), pageSize)
+		}
+		all = append(all, records...)
+
+		continuationPoint = continuationPointOf(result)
+		if len(continuationPoint) == 0 {
+			return all, pages, nil
+		}
+	}
+	return nil, conformanceMaxPages, fmt.Errorf("no end of result after %d pages", conformanceMaxPages)
+}
+
+// continuationPointOf returns the ContinuationPointOut of a GetRecords result
+func continuationPointOf(result *ua.CallMethodResult) []byte {
+	if len(result.OutputArguments) < 2 || result.OutputArguments[1] == nil {
+		return nil
+	}
+	cp, _ := result.OutputArguments[1].Value().([]byte)
+	return cp
+}
+
+// conformanceSameRecord compares the fields a server must reproduce between reads
+func conformanceSameRecord(a, b model.LogRecord) bool {
+	return a.Timestamp.Equal(b.Timestamp) &&
+		a.Severity == b.Severity &&
+		a.Message == b.Message &&
+		a.SourceName == b.SourceName
+}
+
+// newConformanceConfig builds the receiver config from the environment
+func newConformanceConfig(endpoint string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	if path := os.Getenv(conformanceLogObjectEnv); path != "" {
+		cfg.LogObjectPaths = []string{path}
+	}
+	if policy := os.Getenv(conformanceSecurityPolicyEnv); policy != "" {
+		cfg.SecurityPolicy = policy
+	}
+	if mode := os.Getenv(conformanceSecurityModeEnv); mode != "" {
+		cfg.SecurityMode = mode
+	}
+	if username := os.Getenv(conformanceUsernameEnv); username != "" {
+		cfg.Auth.Type = "username_password"
+		cfg.Auth.Username = username
+		cfg.Auth.Password = configopaque.String(os.Getenv(conformancePasswordEnv))
+	}
+	return cfg
+}
+
+// writeConformanceReport prints the checklist results as a table
+func writeConformanceReport(w io.Writer, endpoint string, s *conformanceSession, results []conformanceResult) {
+	logObject := "-"
+	if s.objectID != nil {
+		logObject = s.objectID.String()
+	}
+	fmt.Fprintf(w, "OPC UA Part 26 conformance report\n")
+	fmt.Fprintf(w, "Endpoint:  %s\n", endpoint)
+	fmt.Fprintf(w, "LogObject: %s\n", logObject)
+	fmt.Fprintf(w, "Date:      %s\n\n", s.endTime.Format(time.RFC3339))
+
+	counts := map[conformanceStatus]int{}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCHECK\tREF\tRESULT\tDETAIL")
+	for _, r := range results {
+		counts[r.status]++
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", r.check.id, r.check.name, r.check.ref, r.status, r.detail)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\n%d passed, %d failed, %d warnings, %d skipped\n",
+		counts[conformancePass], counts[conformanceFail], counts[conformanceWarn], counts[conformanceSkip])
+}
+
+func TestConformance(t *testing.T) {
+	endpoint := os.Getenv(conformanceEndpointEnv)
+	if endpoint == "" {
+		t.Skipf("%s not set", conformanceEndpointEnv)
+	}
+	ctx := context.Background()
+
+	s := &conformanceSession{
+		cfg:     newConformanceConfig(endpoint),
+		endTime: time.Now().UTC(),
+	}
+	defer func() {
+		if s.client != nil {
+			_ = s.client.Disconnect(ctx)
+		}
+	}()
+
+	results := make([]conformanceResult, 0, len(conformanceChecks))
+	for _, check := range conformanceChecks {
+		t.Run(check.id, func(t *testing.T) {
+			callCtx, cancel := context.WithTimeout(ctx, s.cfg.RequestTimeout+s.cfg.ConnectionTimeout)
+			defer cancel()
+
+			detail, err := check.run(callCtx, s)
+			result := conformanceResult{check: check, status: conformancePass, detail: detail}
+			switch {
+			case errors.Is(err, errConformanceSkip):
+				result.status, result.detail = conformanceSkip, "prerequisite not met"
+			case err != nil && check.advisory:
+				result.status, result.detail = conformanceWarn, err.Error()
+			case err != nil:
+				result.status, result.detail = conformanceFail, err.Error()
+			}
+			results = append(results, result)
+
+			switch result.status {
+			case conformanceSkip:
+				t.Skip(result.detail)
+			case conformanceWarn:
+				t.Log(result.detail)
+			case conformanceFail:
+				t.Error(result.detail)
+			}
+		})
+	}
+
+	var report strings.Builder
+	writeConformanceReport(&report, endpoint, s, results)
+	fmt.Print(report.String())
+	if path := os.Getenv(conformanceReportEnv); path != "" {
+		if err := os.WriteFile(path, []byte(report.String()), 0o600); err != nil {
+			t.Errorf("write report: %v", err)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+)
+
+// redactedValue replaces masked values in debug dumps
+const redactedValue = "***"
+
+// credentialFieldNames are name fragments of AdditionalData entries that are
+// always masked in debug dumps
+var credentialFieldNames = []string{"password", "passwd", "secret", "token", "apikey", "api_key", "credential", "authorization"}
+
+// dumpLimiter allows a fixed number of dumps per minute
+type dumpLimiter struct {
+	window  time.Time
+	count   int
+	skipped int
+}
+
+// allow reports whether a dump may be written at now and how many were
+// skipped since the last allowed one
+func (l *dumpLimiter) allow(now time.Time, perMinute int) (bool, int) {
+	if now.Sub(l.window) >= time.Minute {
+		l.window = now
+		l.count = 0
+	}
+	if l.count >= perMinute {
+		l.skipped++
+		return false, 0
+	}
+	l.count++
+	skipped := l.skipped
+	l.skipped = 0
+	return true, skipped
+}
+
+// dumpResponse writes a redacted dump of a successful GetRecords response
+// when debug_dump is enabled. Dump failures are logged and never affect collection.
+func (c *opcuaClient) dumpResponse(req *ua.CallMethodRequest, result *ua.CallMethodResult) {
+	cfg := c.config.DebugDump
+	if !cfg.Enabled || result == nil {
+		return
+	}
+
+	c.dumpMu.Lock()
+	defer c.dumpMu.Unlock()
+
+	now := time.Now()
+	ok, skipped := c.dumpLimiter.allow(now, cfg.MaxPerMinute)
+	if !ok {
+		return
+	}
+	if skipped > 0 {
+		c.logger.Info("Skipped GetRecords response dumps over max_per_minute", zap.Int("skipped", skipped))
+	}
+
+	dump := newResponseDumper(cfg.RedactFields, string(c.config.Auth.Password)).dump(c.config.Endpoint, req, result)
+	dump = truncateDump(dump, cfg.MaxBytes)
+
+	if cfg.Directory == "" {
+		c.logger.Info("GetRecords response dump", zap.String("dump", dump))
+		return
+	}
+
+	if err := os.MkdirAll(cfg.Directory, 0o750); err != nil {
+		c.logger.Warn("Failed to create debug dump directory", zap.Error(err))
+		return
+	}
+	c.dumpSeq++
+	name := fmt.Sprintf("getrecords-dump-%s-%04d.txt", now.UTC().Format("20060102T150405.000000000Z"), c.dumpSeq)
+	if err := os.WriteFile(filepath.Join(cfg.Directory, name), []byte(dump), 0o600); err != nil {
+		c.logger.Warn("Failed to write GetRecords response dump", zap.Error(err))
+	}
+}
+
+// truncateDump cuts dump to maxBytes, marking how much was left out
+func truncateDump(dump string, maxBytes int) string {
+	if len(dump) <= maxBytes {
+		return dump
+	}
+	return fmt.Sprintf("%s\n... truncated %d bytes", dump[:maxBytes], len(dump)-maxBytes)
+}
+
+// responseDumper renders GetRecords responses as text with sensitive values masked
+type responseDumper struct {
+	redact   map[string]bool
+	password string
+	buf      strings.Builder
+}
+
+func newResponseDumper(redactFields []string, password string) *responseDumper {
+	d := &responseDumper{redact: make(map[string]bool, len(redactFields)), password: password}
+	for _, name := range redactFields {
+		d.redact[strings.ToLower(name)] = true
+	}
+	return d
+}
+
+// masked reports whether the AdditionalData value of name is redacted
+func (d *responseDumper) masked(name string) bool {
+	lower := strings.ToLower(name)
+	if d.redact[lower] {
+		return true
+	}
+	for _, fragment := range credentialFieldNames {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+// scrub masks the configured password in dump, in plain text and in hex
+// encoded bodies
+func (d *responseDumper) scrub(dump string) string {
+	if d.password == "" {
+		return dump
+	}
+	for _, secret := range []string{d.password, hex.EncodeToString([]byte(d.password))} {
+		dump = strings.ReplaceAll(dump, secret, strings.Repeat("*", len(secret)))
+	}
+	return dump
+}
+
+func (d *responseDumper) printf(indent int, format string, args ...interface{}) {
+	d.buf.WriteString(strings.Repeat("  ", indent))
+	fmt.Fprintf(&d.buf, format, args...)
+	d.buf.WriteByte('\n')
+}
+
+// dump renders the response; req identifies the LogObject and method
+func (d *responseDumper) dump(endpoint string, req *ua.CallMethodRequest, result *ua.CallMethodResult) string {
+	d.printf(0, "GetRecords response endpoint=%s object_id=%s method_id=%s status=%s",
+		endpoint, req.ObjectID, req.MethodID, result.StatusCode)
+	for i, arg := range result.OutputArguments {
+		d.variant(1, fmt.Sprintf("output[%d]", i), arg)
+	}
+	return d.scrub(d.buf.String())
+}
+
+func (d *responseDumper) variant(indent int, label string, v *ua.Variant) {
+	if v == nil {
+		d.printf(indent, "%s: null", label)
+		return
+	}
+
+	switch value := v.Value().(type) {
+	case []*ua.ExtensionObject:
+		d.printf(indent, "%s: ExtensionObject[%d]", label, len(value))
+		for i, obj := range value {
+			d.extensionObject(indent+1, fmt.Sprintf("[%d]", i), obj)
+		}
+	case *ua.ExtensionObject:
+		d.extensionObject(indent, label, value)
+	case []byte:
+		d.printf(indent, "%s: ByteString[%d] %s", label, len(value), hex.EncodeToString(value))
+	default:
+		d.printf(indent, "%s: %T %s", label, value, d.value(value))
+	}
+}
+
+func (d *responseDumper) extensionObject(indent int, label string, obj *ua.ExtensionObject) {
+	if obj == nil {
+		d.printf(indent, "%s: null", label)
+		return
+	}
+
+	typeID := ""
+	if obj.TypeID != nil {
+		typeID = obj.TypeID.String()
+	}
+
+	switch body := obj.Value.(type) {
+	case *client.LogRecordExtObj:
+		d.logRecord(indent, label, typeID, body)
+	case []byte:
+		// Bodies of unknown types are decoded like a LogRecord when possible so
+		// that AdditionalData can be masked; otherwise they are dumped as is
+		lr := &client.LogRecordExtObj{}
+		if _, err := lr.Decode(body); err == nil {
+			d.logRecord(indent, label, typeID, lr)
+			return
+		}
+		d.printf(indent, "%s: type_id=%s undecodable body[%d] %s", label, typeID, len(body), hex.EncodeToString(body))
+	default:
+		d.printf(indent, "%s: type_id=%s %T %s", label, typeID, body, d.value(body))
+	}
+}
+
+// logRecord renders a LogRecord with masked AdditionalData and the binary
+// body re-encoded from the masked record
+func (d *responseDumper) logRecord(indent int, label, typeID string, lr *client.LogRecordExtObj) {
+	masked := *lr
+	masked.AdditionalData = make(map[string]interface{}, len(lr.AdditionalData))
+	for name, value := range lr.AdditionalData {
+		if d.masked(name) {
+			value = redactedValue
+		}
+		masked.AdditionalData[name] = value
+	}
+
+	d.printf(indent, "%s: type_id=%s %s", label, typeID, masked.String())
+	names := make([]string, 0, len(masked.AdditionalData))
+	for name := range masked.AdditionalData {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := masked.AdditionalData[name]
+		d.printf(indent+1, "%q: %T %s", name, value, d.value(value))
+	}
+
+	buf := getCodecBuffer()
+	defer putCodecBuffer(buf)
+	body, err := masked.AppendEncode(*buf)
+	if err != nil {
+		d.printf(indent+1, "body: encoding failed: %v", err)
+		return
+	}
+	*buf = body
+	d.printf(indent+1, "body[%d]: %s", len(body), hex.EncodeToString(body))
+}
+
+// value renders a decoded value, masking nested maps like AdditionalData
+func (d *responseDumper) value(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return hex.EncodeToString(v)
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts := make([]string, len(names))
+		for i, name := range names {
+			if d.masked(name) {
+				parts[i] = fmt.Sprintf("%q: %s", name, redactedValue)
+			} else {
+				parts[i] = fmt.Sprintf("%q: %s", name, d.value(v[name]))
+			}
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = d.value(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
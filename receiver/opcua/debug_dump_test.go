@@ -0,0 +1,157 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+)
+
+func dumpTestResult(t *testing.T, bodies ...interface{}) (*ua.CallMethodRequest, *ua.CallMethodResult) {
+	t.Helper()
+	objects := make([]*ua.ExtensionObject, len(bodies))
+	for i, body := range bodies {
+		objects[i] = &ua.ExtensionObject{
+			EncodingMask: ua.ExtensionObjectBinary,
+			TypeID:       ua.NewFourByteExpandedNodeID(0, 5001),
+			Value:        body,
+		}
+	}
+	req := &ua.CallMethodRequest{ObjectID: ua.NewNumericNodeID(1, 1000), MethodID: ua.NewNumericNodeID(1, 1001)}
+	return req, &ua.CallMethodResult{
+		StatusCode:      ua.StatusOK,
+		OutputArguments: []*ua.Variant{ua.MustVariant(objects), ua.MustVariant([]byte{0xca, 0xfe})},
+	}
+}
+
+func TestResponseDumperRedacts(t *testing.T) {
+	record := &client.LogRecordExtObj{
+		Time:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity: 100,
+		Message:  "login by operator",
+		AdditionalData: map[string]interface{}{
+			"User":         "operator",
+			"UserPassword": "hunter2",
+			"AccessToken":  "abc123",
+			"SerialNumber": "SN-4711",
+			"Counter":      int32(7),
+		},
+	}
+	raw, err := record.Encode()
+	require.NoError(t, err)
+
+	req, result := dumpTestResult(t, record, raw, []byte{0x01, 0x02})
+	dump := newResponseDumper([]string{"serialnumber"}, "s3cr3t-pw").dump("opc.tcp://plc:4840", req, result)
+
+	assert.Contains(t, dump, "object_id=ns=1;i=1000 method_id=ns=1;i=1001")
+	assert.Contains(t, dump, `"User": string operator`)
+	assert.Contains(t, dump, `"Counter": int32 7`)
+	assert.Contains(t, dump, "undecodable body[2] 0102")
+	assert.Contains(t, dump, "ByteString[2] cafe")
+	for _, secret := range []string{"hunter2", "abc123", "SN-4711"} {
+		assert.NotContains(t, dump, secret)
+		assert.NotContains(t, dump, hex.EncodeToString([]byte(secret)), "re-encoded body must not hold %s", secret)
+	}
+	// Both the decoded and the raw body are masked
+	assert.Equal(t, 6, strings.Count(dump, ": string "+redactedValue))
+
+	// The configured password is masked wherever it appears
+	req, result = dumpTestResult(t, []byte("xx s3cr3t-pw xx"))
+	dump = newResponseDumper(nil, "s3cr3t-pw").dump("opc.tcp://plc:4840", req, result)
+	assert.NotContains(t, dump, hex.EncodeToString([]byte("s3cr3t-pw")))
+}
+
+func TestTruncateDump(t *testing.T) {
+	assert.Equal(t, "short", truncateDump("short", 10))
+	assert.Equal(t, "0123456789\n... truncated 5 bytes", truncateDump("0123456789abcde", 10))
+}
+
+func TestDumpLimiter(t *testing.T) {
+	var l dumpLimiter
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		ok, _ := l.allow(now, 2)
+		assert.True(t, ok)
+	}
+	ok, _ := l.allow(now.Add(30*time.Second), 2)
+	assert.False(t, ok)
+	ok, _ = l.allow(now.Add(59*time.Second), 2)
+	assert.False(t, ok)
+
+	ok, skipped := l.allow(now.Add(time.Minute), 2)
+	assert.True(t, ok)
+	assert.Equal(t, 2, skipped)
+}
+
+func TestClientWireDebugDump(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("log", func(t *testing.T) {
+		ws := startWireServer(t)
+		records := wireRecords(3)
+		records[0].Attributes["api_key"] = "k-123"
+		ws.AddLogRecords(records)
+
+		cfg := ws.newWireConfig()
+		cfg.DebugDump = DebugDumpConfig{Enabled: true, MaxBytes: 64 * 1024, MaxPerMinute: 1}
+		core, logs := observer.New(zapcore.InfoLevel)
+		c := newOPCUAClient(cfg, zap.New(core))
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		for i := 0; i < 3; i++ {
+			got, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+			require.NoError(t, err)
+			require.Len(t, got, 3)
+		}
+
+		dumps := logs.FilterMessage("GetRecords response dump").All()
+		require.Len(t, dumps, 1, "max_per_minute limits the dumps")
+		dump := dumps[0].ContextMap()["dump"].(string)
+		assert.Contains(t, dump, "ExtensionObject[3]")
+		assert.Contains(t, dump, `"component": string pump`)
+		assert.NotContains(t, dump, "k-123")
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		ws := startWireServer(t)
+		ws.AddLogRecords(wireRecords(20))
+
+		cfg := ws.newWireConfig()
+		cfg.DebugDump = DebugDumpConfig{Enabled: true, Directory: filepath.Join(t.TempDir(), "dumps"), MaxBytes: 512, MaxPerMinute: 10}
+		c := newOPCUAClient(cfg, zap.NewNop())
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		_, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+		require.NoError(t, err)
+
+		files, err := os.ReadDir(cfg.DebugDump.Directory)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		dump, err := os.ReadFile(filepath.Join(cfg.DebugDump.Directory, files[0].Name()))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(dump), "GetRecords response endpoint="+cfg.Endpoint))
+		assert.Contains(t, string(dump), "... truncated")
+	})
+}
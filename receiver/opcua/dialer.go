@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/gopcua/opcua/uacp"
+)
+
+// newDialer builds the uacp.Dialer used for the TCP connection to the server
+// from the dialer configuration
+func newDialer(cfg DialerConfig) (*uacp.Dialer, error) {
+	netDialer := &net.Dialer{
+		KeepAlive: cfg.KeepAlive,
+	}
+
+	localIP, err := cfg.localIP()
+	if err != nil {
+		return nil, err
+	}
+	if localIP != nil {
+		netDialer.LocalAddr = &net.TCPAddr{IP: localIP}
+	}
+
+	if cfg.DSCP != 0 {
+		dscp := cfg.DSCP
+		netDialer.Control = func(network, _ string, c syscall.RawConn) error {
+			var sockErr error
+			if err := c.Control(func(fd uintptr) {
+				sockErr = setDSCP(fd, network, dscp)
+			}); err != nil {
+				return err
+			}
+			if sockErr != nil {
+				return fmt.Errorf("failed to set DSCP %d: %w", dscp, sockErr)
+			}
+			return nil
+		}
+	}
+
+	return &uacp.Dialer{Dialer: netDialer}, nil
+}
+
+// localIP returns the local address outgoing connections are bound to, or nil
+// to let the operating system choose
+func (cfg DialerConfig) localIP() (net.IP, error) {
+	if cfg.LocalAddress != "" {
+		ip := net.ParseIP(cfg.LocalAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid local_address: %s", cfg.LocalAddress)
+		}
+		return ip, nil
+	}
+
+	if cfg.Interface == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(cfg.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find interface %q: %w", cfg.Interface, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get addresses of interface %q: %w", cfg.Interface, err)
+	}
+
+	// Prefer IPv4 as OT networks rarely route IPv6
+	var fallback net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("interface %q has no usable address", cfg.Interface)
+	}
+	return fallback, nil
+}
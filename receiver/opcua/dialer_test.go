@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDialer(t *testing.T) {
+	d, err := newDialer(DialerConfig{KeepAlive: 10 * time.Second, LocalAddress: "127.0.0.1"})
+	require.NoError(t, err)
+	require.NotNil(t, d.Dialer)
+
+	assert.Equal(t, 10*time.Second, d.Dialer.KeepAlive)
+	assert.Equal(t, &net.TCPAddr{IP: net.ParseIP("127.0.0.1")}, d.Dialer.LocalAddr)
+	assert.Nil(t, d.Dialer.Control)
+}
+
+func TestNewDialerDefaults(t *testing.T) {
+	d, err := newDialer(DialerConfig{})
+	require.NoError(t, err)
+
+	assert.Nil(t, d.Dialer.LocalAddr)
+	assert.Nil(t, d.Dialer.Control)
+}
+
+func TestNewDialerUnknownInterface(t *testing.T) {
+	_, err := newDialer(DialerConfig{Interface: "does-not-exist0"})
+	assert.ErrorContains(t, err, "does-not-exist0")
+}
+
+func TestDialerLocalIPFromInterface(t *testing.T) {
+	ifaces, err := net.Interfaces()
+	require.NoError(t, err)
+
+	var loopback string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback != 0 {
+			loopback = iface.Name
+			break
+		}
+	}
+	if loopback == "" {
+		t.Skip("no loopback interface available")
+	}
+
+	ip, err := DialerConfig{Interface: loopback}.localIP()
+	require.NoError(t, err)
+	assert.True(t, ip.IsLoopback())
+}
+
+func TestDialerDSCP(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("DSCP marking is not supported on windows")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	d, err := newDialer(DialerConfig{DSCP: 46}) // Expedited Forwarding
+	require.NoError(t, err)
+	require.NotNil(t, d.Dialer.Control)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := d.Dialer.DialContext(ctx, "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	require.NoError(t, conn.Close())
+}
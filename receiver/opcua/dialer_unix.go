@@ -0,0 +1,19 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !windows
+
+package opcua
+
+import (
+	"syscall"
+)
+
+// setDSCP marks outgoing packets on the socket with the given DSCP value
+func setDSCP(fd uintptr, network string, dscp int) error {
+	tos := dscp << 2
+	if network == "tcp6" {
+		return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS, tos)
+	}
+	return syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, tos)
+}
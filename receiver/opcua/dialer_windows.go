@@ -0,0 +1,16 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build windows
+
+package opcua
+
+import (
+	"errors"
+)
+
+// setDSCP is not supported on Windows, where DSCP marking is configured
+// through Group Policy QoS rules instead of per-socket options
+func setDSCP(_ uintptr, _ string, _ int) error {
+	return errors.New("DSCP marking is not supported on windows, use a QoS policy instead")
+}
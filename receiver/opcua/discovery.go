@@ -0,0 +1,239 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// discoveredServer is a server registered at the Local Discovery Server
+type discoveredServer struct {
+	applicationURI string
+	endpoint       string
+}
+
+// serverDiscovery queries the Local Discovery Server every
+// discovery.interval and collects from every registered server whose
+// ApplicationUri matches, with a receiver of its own per server. The receivers
+// share the consumers and the settings of the discovery receiver apart from
+// endpoint.
+type serverDiscovery struct {
+	parent  *opcuaReceiver
+	pattern *regexp.Regexp
+	logger  *zap.Logger
+
+	// find returns the servers registered at the LDS
+	find func(context.Context) ([]*ua.ApplicationDescription, error)
+
+	mu        sync.Mutex
+	receivers map[string]*opcuaReceiver // by ApplicationUri
+}
+
+// newServerDiscovery creates the discovery of the receiver r, which does nothing until it runs
+func newServerDiscovery(r *opcuaReceiver) (*serverDiscovery, error) {
+	var pattern *regexp.Regexp
+	if r.config.Discovery.ApplicationURIPattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(r.config.Discovery.ApplicationURIPattern); err != nil {
+			return nil, fmt.Errorf("invalid discovery application_uri_pattern: %w", err)
+		}
+	}
+
+	d := &serverDiscovery{
+		parent:    r,
+		pattern:   pattern,
+		logger:    r.settings.Logger,
+		receivers: make(map[string]*opcuaReceiver),
+	}
+	d.find = d.findServers
+	return d, nil
+}
+
+// run queries the LDS right away and then every interval until ctx is done
+func (d *serverDiscovery) run(ctx context.Context, host component.Host) {
+	ticker := time.NewTicker(d.parent.config.Discovery.Interval)
+	defer ticker.Stop()
+
+	for {
+		d.discover(ctx, host)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// discover starts receivers for the matching servers that have none and
+// shuts down the receivers of servers that are no longer registered. A
+// receiver that fails to start is tried again with the next discovery.
+func (d *serverDiscovery) discover(ctx context.Context, host component.Host) {
+	applications, err := d.find(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Warn("Failed to query OPC UA discovery server",
+				zap.String("discovery_endpoint", d.parent.config.Discovery.Endpoint),
+				zap.Error(err))
+		}
+		return
+	}
+	servers := d.match(applications)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for uri, r := range d.receivers {
+		if _, ok := servers[uri]; ok {
+			continue
+		}
+		d.logger.Info("OPC UA server no longer registered, stopping collection",
+			zap.String("application_uri", uri),
+			zap.String("endpoint", r.config.Endpoint))
+		if err := r.Shutdown(ctx); err != nil {
+			d.logger.Warn("Failed to stop collection from OPC UA server",
+				zap.String("application_uri", uri),
+				zap.Error(err))
+		}
+		delete(d.receivers, uri)
+	}
+
+	uris := make([]string, 0, len(servers))
+	for uri := range servers {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	for _, uri := range uris {
+		if _, ok := d.receivers[uri]; ok {
+			continue
+		}
+		server := servers[uri]
+		r, err := d.startReceiver(ctx, host, server)
+		if err != nil {
+			d.logger.Warn("Failed to start collection from discovered OPC UA server",
+				zap.String("application_uri", uri),
+				zap.String("endpoint", server.endpoint),
+				zap.Error(err))
+			continue
+		}
+		d.logger.Info("Discovered OPC UA server",
+			zap.String("application_uri", uri),
+			zap.String("endpoint", server.endpoint))
+		d.receivers[uri] = r
+	}
+}
+
+// match returns the servers among applications whose ApplicationUri matches
+// application_uri_pattern and that have an opc.tcp discovery URL, by
+// ApplicationUri. Clients and discovery servers are skipped.
+func (d *serverDiscovery) match(applications []*ua.ApplicationDescription) map[string]discoveredServer {
+	servers := make(map[string]discoveredServer, len(applications))
+	for _, app := range applications {
+		if app == nil || app.ApplicationURI == "" {
+			continue
+		}
+		if app.ApplicationType != ua.ApplicationTypeServer && app.ApplicationType != ua.ApplicationTypeClientAndServer {
+			continue
+		}
+		if d.pattern != nil && !d.pattern.MatchString(app.ApplicationURI) {
+			continue
+		}
+		for _, url := range app.DiscoveryURLs {
+			if strings.HasPrefix(url, "opc.tcp://") {
+				servers[app.ApplicationURI] = discoveredServer{applicationURI: app.ApplicationURI, endpoint: url}
+				break
+			}
+		}
+	}
+	return servers
+}
+
+// startReceiver starts collecting from server with a receiver that is
+// configured like the discovery receiver but connects to the server's
+// discovery URL. It has an ID of its own so its spool and checkpoint are
+// kept apart from those of the other servers.
+func (d *serverDiscovery) startReceiver(ctx context.Context, host component.Host, server discoveredServer) (*opcuaReceiver, error) {
+	parent := d.parent
+	cfg := *parent.config
+	cfg.Endpoint = server.endpoint
+	cfg.Discovery = DiscoveryConfig{}
+
+	settings := parent.settings
+	settings.ID = component.NewIDWithName(parent.settings.ID.Type(), discoveredName(parent.settings.ID.Name(), server.applicationURI))
+	settings.Logger = parent.settings.Logger.With(zap.String("application_uri", server.applicationURI))
+
+	r, err := newOPCUAReceiver(&cfg, settings)
+	if err != nil {
+		return nil, err
+	}
+	r.scraper.clientFactory = parent.scraper.clientFactory
+	r.nextLogs = parent.nextLogs
+	r.nextMetrics = parent.nextMetrics
+	r.nextTraces = parent.nextTraces
+
+	// The receiver outlives this discovery round and is shut down explicitly
+	if err := r.Start(context.WithoutCancel(ctx), host); err != nil {
+		return nil, errors.Join(err, r.Shutdown(ctx))
+	}
+	return r, nil
+}
+
+// shutdown stops collecting from all discovered servers, which shut down
+// concurrently
+func (d *serverDiscovery) shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var (
+		wg     sync.WaitGroup
+		errsMu sync.Mutex
+		errs   []error
+	)
+	for uri, r := range d.receivers {
+		wg.Go(func() {
+			if err := r.Shutdown(ctx); err != nil {
+				errsMu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", uri, err))
+				errsMu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	clear(d.receivers)
+	return errors.Join(errs...)
+}
+
+// findServers calls FindServers on the Local Discovery Server within
+// connection_timeout
+func (d *serverDiscovery) findServers(ctx context.Context) ([]*ua.ApplicationDescription, error) {
+	dialer, err := newDialer(d.parent.config.Dialer)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.parent.config.ConnectionTimeout)
+	defer cancel()
+	return opcua.FindServers(ctx, d.parent.config.Discovery.Endpoint, opcua.Dialer(dialer))
+}
+
+// discoveredName returns the component name of the receiver of the server
+// with applicationURI, below the name of the discovery receiver
+func discoveredName(parent, applicationURI string) string {
+	if parent == "" {
+		return applicationURI
+	}
+	return parent + "/" + applicationURI
+}
@@ -0,0 +1,242 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+)
+
+func TestDiscoveryConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "disabled by default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "enabled without endpoint",
+			modify: func(cfg *Config) {
+				cfg.Endpoint = ""
+				cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+				cfg.Discovery.ApplicationURIPattern = "^urn:plant:"
+			},
+		},
+		{
+			name:    "endpoint without opc.tcp scheme",
+			modify:  func(cfg *Config) { cfg.Discovery.Endpoint = "http://lds:4840" },
+			wantErr: "invalid discovery: endpoint must start with opc.tcp://",
+		},
+		{
+			name: "invalid pattern",
+			modify: func(cfg *Config) {
+				cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+				cfg.Discovery.ApplicationURIPattern = "urn:(plant"
+			},
+			wantErr: "application_uri_pattern is not a valid regular expression",
+		},
+		{
+			name: "interval below one second",
+			modify: func(cfg *Config) {
+				cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+				cfg.Discovery.Interval = 10 * time.Millisecond
+			},
+			wantErr: "interval must be at least 1 second",
+		},
+		{
+			name: "redundancy",
+			modify: func(cfg *Config) {
+				cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+				cfg.Redundancy.BackupEndpoints = []string{"opc.tcp://backup:4840"}
+			},
+			wantErr: "discovery is not available with redundancy",
+		},
+		{
+			name: "reverse connect",
+			modify: func(cfg *Config) {
+				cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+				cfg.ReverseConnect.ListenAddress = "0.0.0.0:4843"
+			},
+			wantErr: "discovery is not available with reverse_connect",
+		},
+		{
+			name: "custom client",
+			modify: func(cfg *Config) {
+				cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+				cfg.Client = &windowClient{}
+			},
+			wantErr: "discovery is not available with a custom client",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServerDiscoveryMatch(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+	cfg.Discovery.ApplicationURIPattern = "^urn:plant:line-"
+	r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+
+	servers := r.discovery.match([]*ua.ApplicationDescription{
+		{
+			ApplicationURI:  "urn:plant:line-1",
+			ApplicationType: ua.ApplicationTypeServer,
+			DiscoveryURLs:   []string{"https://line-1:443", "opc.tcp://line-1:4840"},
+		},
+		{
+			ApplicationURI:  "urn:plant:line-2",
+			ApplicationType: ua.ApplicationTypeClientAndServer,
+			DiscoveryURLs:   []string{"opc.tcp://line-2:4840"},
+		},
+		{
+			// Not matching the pattern
+			ApplicationURI:  "urn:office:printer",
+			ApplicationType: ua.ApplicationTypeServer,
+			DiscoveryURLs:   []string{"opc.tcp://printer:4840"},
+		},
+		{
+			// The LDS itself
+			ApplicationURI:  "urn:plant:line-lds",
+			ApplicationType: ua.ApplicationTypeDiscoveryServer,
+			DiscoveryURLs:   []string{"opc.tcp://lds:4840"},
+		},
+		{
+			// No opc.tcp URL
+			ApplicationURI:  "urn:plant:line-3",
+			ApplicationType: ua.ApplicationTypeServer,
+			DiscoveryURLs:   []string{"https://line-3:443"},
+		},
+		nil,
+	})
+
+	assert.Equal(t, map[string]discoveredServer{
+		"urn:plant:line-1": {applicationURI: "urn:plant:line-1", endpoint: "opc.tcp://line-1:4840"},
+		"urn:plant:line-2": {applicationURI: "urn:plant:line-2", endpoint: "opc.tcp://line-2:4840"},
+	}, servers)
+}
+
+// fakeLDS is a Local Discovery Server with the given servers registered
+type fakeLDS struct {
+	mu      sync.Mutex
+	servers []*ua.ApplicationDescription
+	err     error
+}
+
+func (f *fakeLDS) register(uri, endpoint string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.servers = append(f.servers, &ua.ApplicationDescription{
+		ApplicationURI:  uri,
+		ApplicationType: ua.ApplicationTypeServer,
+		DiscoveryURLs:   []string{endpoint},
+	})
+}
+
+func (f *fakeLDS) unregister(uri string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, server := range f.servers {
+		if server.ApplicationURI == uri {
+			f.servers = append(f.servers[:i], f.servers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *fakeLDS) findServers(context.Context) ([]*ua.ApplicationDescription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.servers, f.err
+}
+
+func TestServerDiscoveryStartsReceiverPerServer(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Discovery.Endpoint = "opc.tcp://lds:4840"
+	// No scrape runs during the test
+	cfg.InitialDelay = time.Hour
+	set := receivertest.NewNopSettings(metadata.Type)
+	r, err := newOPCUAReceiver(cfg, set)
+	require.NoError(t, err)
+	r.nextLogs = consumertest.NewNop()
+
+	var (
+		mu        sync.Mutex
+		connected []string
+	)
+	r.scraper.clientFactory = func(_ component.Host, cfg *Config, _ component.TelemetrySettings) (OPCUAClient, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		connected = append(connected, cfg.Endpoint)
+		return &windowClient{}, nil
+	}
+
+	lds := &fakeLDS{}
+	lds.register("urn:plant:line-1", "opc.tcp://line-1:4840")
+	lds.register("urn:plant:line-2", "opc.tcp://line-2:4840")
+	r.discovery.find = lds.findServers
+
+	ctx := context.Background()
+	host := componenttest.NewNopHost()
+	r.discovery.discover(ctx, host)
+
+	require.Len(t, r.discovery.receivers, 2)
+	line1 := r.discovery.receivers["urn:plant:line-1"]
+	require.NotNil(t, line1)
+	assert.Equal(t, "opc.tcp://line-1:4840", line1.config.Endpoint)
+	assert.Empty(t, line1.config.Discovery.Endpoint)
+	assert.Equal(t, component.NewIDWithName(metadata.Type, "urn:plant:line-1"), line1.settings.ID)
+	assert.Equal(t, []string{"opc.tcp://line-1:4840", "opc.tcp://line-2:4840"}, connected)
+
+	// A failed query keeps collecting from the known servers
+	lds.err = errors.New("connection refused")
+	r.discovery.discover(ctx, host)
+	assert.Len(t, r.discovery.receivers, 2)
+	lds.err = nil
+
+	// Unregistered servers are no longer collected from, and new ones are
+	lds.unregister("urn:plant:line-1")
+	lds.register("urn:plant:line-3", "opc.tcp://line-3:4840")
+	r.discovery.discover(ctx, host)
+	assert.Len(t, r.discovery.receivers, 2)
+	assert.NotContains(t, r.discovery.receivers, "urn:plant:line-1")
+	assert.Contains(t, r.discovery.receivers, "urn:plant:line-3")
+
+	require.NoError(t, r.discovery.shutdown(ctx))
+	assert.Empty(t, r.discovery.receivers)
+}
+
+func TestDiscoveredName(t *testing.T) {
+	assert.Equal(t, "urn:plant:line-1", discoveredName("", "urn:plant:line-1"))
+	assert.Equal(t, "plant/urn:plant:line-1", discoveredName("plant", "urn:plant:line-1"))
+}
@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/dryrun"
+)
+
+func init() {
+	dryrun.Run = func(ctx context.Context, cfg component.Config, out io.Writer, sampleSize int, logger *zap.Logger) error {
+		c, ok := cfg.(*Config)
+		if !ok {
+			return fmt.Errorf("unexpected configuration type %T", cfg)
+		}
+		return dryRun(ctx, c, out, sampleSize, logger)
+	}
+}
+
+// dryRun connects to the server of cfg, lists the resolved LogObjects and
+// their GetRecords methods, reads up to sampleSize of the oldest records of
+// each LogObject and writes them to out as the receiver would emit them. It
+// disconnects before returning and is meant for commissioning a server, see
+// cmd/opcuadryrun. Warnings of the client are logged to logger, which may be nil.
+func dryRun(ctx context.Context, cfg *Config, out io.Writer, sampleSize int, logger *zap.Logger) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if cfg.TLS.CertificateProvider != nil {
+		return errors.New("tls.certificate_provider requires a running collector and is not supported by the dry run")
+	}
+	if sampleSize <= 0 {
+		sampleSize = dryrun.DefaultSampleSize
+	}
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+
+	client := newOPCUAClient(cfg, logger)
+	if err := ensureGeneratedCertificate(&cfg.TLS, client.identity.applicationName, time.Now(), logger); err != nil {
+		return fmt.Errorf("failed to generate application certificate: %w", err)
+	}
+	if cfg.GDS.Endpoint != "" {
+		// The dry run does not enroll, it uses what the collector stored
+		provider := newGDSProvider(cfg, client.identity, logger)
+		if err := provider.load(); err != nil {
+			return err
+		}
+		client.certProvider = provider
+	}
+	if err := client.Connect(ctx); err != nil {
+		return err
+	}
+	defer func() {
+		if err := client.Disconnect(ctx); err != nil {
+			logger.Warn("Failed to disconnect from OPC UA server", zap.Error(err))
+		}
+	}()
+
+	p := &dryRunPrinter{out: out}
+	p.printf("Endpoint:          %s\n", cfg.Endpoint)
+	if ep := client.endpoint; ep != nil {
+		p.printf("Server endpoint:   %s\n", ep.EndpointURL)
+		p.printf("Security policy:   %s\n", ep.SecurityPolicyURI)
+		p.printf("Security mode:     %s\n", ep.SecurityMode)
+	}
+	p.printf("Authentication:    %s\n", cfg.Auth.Type)
+	p.printf("Application name:  %s\n", client.identity.applicationName)
+	p.printf("Session name:      %s\n", client.identity.sessionName)
+
+	transformer := newTransformerFromConfig(cfg)
+	transformer.serverInfo = client.serverInfo
+	if nodeID := cfg.SeverityDictionary.NodeID; nodeID != "" {
+		server, err := client.readSeverityDictionary(ctx, nodeID)
+		if err != nil {
+			p.printf("Severity dictionary: %v\n", err)
+		} else {
+			transformer.severities = server.merge(transformer.severities)
+			p.printf("Severity dictionary: %d entries read from %s\n", len(server), nodeID)
+		}
+	}
+
+	p.printf("\nResource attributes:\n")
+	p.printAttributes("  ", transformer.buildResource().Attributes())
+
+	end := time.Now()
+	minSeverity := client.getMinSeverityValue()
+	for _, id := range client.logObjectIDs {
+		p.printf("\nLogObject %s\n", id)
+		if methodID, err := client.findGetRecordsMethod(ctx, id); err != nil {
+			p.printf("  GetRecords: %v\n", err)
+		} else {
+			p.printf("  GetRecords: %s\n", methodID)
+		}
+
+		records, _, err := client.callGetRecordsMethod(ctx, id, time.Time{}, end, uint32(sampleSize), minSeverity, nil) //nolint:gosec
+		if err != nil {
+			p.printf("  Records: %v\n", err)
+			continue
+		}
+		p.printf("  Records: %d (at most %d of the oldest with severity >= %s)\n", len(records), sampleSize, cfg.Filter.MinSeverity)
+
+		logs := transformer.TransformLogs(records)
+		if logs.ResourceLogs().Len() == 0 {
+			continue
+		}
+		lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := range lrs.Len() {
+			lr := lrs.At(i)
+			p.printf("  [%d] %s %s (%d) %q\n", i+1,
+				lr.Timestamp().AsTime().UTC().Format(time.RFC3339Nano),
+				lr.SeverityText(), lr.SeverityNumber(), lr.Body().AsString())
+			if !lr.TraceID().IsEmpty() {
+				p.printf("      trace_id: %s span_id: %s\n", lr.TraceID(), lr.SpanID())
+			}
+			p.printAttributes("      ", lr.Attributes())
+		}
+	}
+
+	return p.err
+}
+
+// dryRunPrinter writes the dry run report and keeps the first write error
+type dryRunPrinter struct {
+	out io.Writer
+	err error
+}
+
+func (p *dryRunPrinter) printf(format string, args ...any) {
+	if p.err != nil {
+		return
+	}
+	_, p.err = fmt.Fprintf(p.out, format, args...)
+}
+
+func (p *dryRunPrinter) printAttributes(indent string, attrs pcommon.Map) {
+	for k, v := range attrs.All() {
+		p.printf("%s%s: %s\n", indent, k, v.AsString())
+	}
+}
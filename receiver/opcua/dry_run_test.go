@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+)
+
+func TestDryRunWire(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(5))
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	require.NoError(t, dryRun(ctx, ws.newWireConfig(), &out, 3, nil))
+	report := out.String()
+
+	assert.Contains(t, report, "Security policy:   "+ua.SecurityPolicyURINone)
+	assert.Contains(t, report, "Application name:  otelcol-opcua\n")
+	assert.Contains(t, report, "service.name: opcua-server")
+	assert.Contains(t, report, "LogObject "+ws.logObjectID.String()+"\n")
+	assert.Contains(t, report, "GetRecords: "+ws.methodID.String()+"\n")
+	assert.Contains(t, report, "Records: 3 (at most 3 of the oldest with severity >= Debug)")
+	assert.Contains(t, report, `[1] 2025-01-15T10:00:00Z Debug (5) "wire record"`)
+	assert.Contains(t, report, "trace_id: 0102030405060708090a0b0c0d0e0f10 span_id: 0102030405060708")
+	assert.Contains(t, report, "opcua.source.name: Pump")
+	assert.Contains(t, report, "component: pump")
+	assert.Equal(t, 3, strings.Count(report, "wire record"))
+}
+
+func TestDryRunErrors(t *testing.T) {
+	ctx := context.Background()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = ""
+	err := dryRun(ctx, cfg, &bytes.Buffer{}, 1, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid configuration")
+
+	cfg = createDefaultConfig().(*Config)
+	id := component.MustNewID("file_certificates")
+	cfg.TLS.CertificateProvider = &id
+	err = dryRun(ctx, cfg, &bytes.Buffer{}, 1, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tls.certificate_provider")
+
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(1))
+	err = dryRun(ctx, ws.newWireConfig(), failingWriter{}, 1, nil)
+	assert.ErrorIs(t, err, errWriteFailed)
+}
+
+var errWriteFailed = errors.New("write failed")
+
+// failingWriter rejects every write
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errWriteFailed
+}
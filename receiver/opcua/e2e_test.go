@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build e2e
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.uber.org/zap"
+)
+
+// The C# test server (testserver/) implements the Part 26 GetRecords method on a
+// ServerLog object in its own namespace and serves 10 fixed records.
+const (
+	e2eServerLogPath    = "ns=2;i=1000"
+	e2eFixedRecordCount = 10
+)
+
+// startTestServer builds and starts the C# reference Part 26 server in a container
+// and returns the opc.tcp endpoint reachable from the test process.
+func startTestServer(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+
+	req := testcontainers.ContainerRequest{
+		FromDockerfile: testcontainers.FromDockerfile{
+			Context:    "../../testserver",
+			Dockerfile: "Dockerfile",
+		},
+		ExposedPorts: []string{"4840/tcp"},
+		WaitingFor:   wait.ForLog("Server started").WithStartupTimeout(5 * time.Minute),
+	}
+
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	testcontainers.CleanupContainer(t, container)
+	require.NoError(t, err)
+
+	host, err := container.Host(ctx)
+	require.NoError(t, err)
+	port, err := container.MappedPort(ctx, "4840/tcp")
+	require.NoError(t, err)
+
+	return fmt.Sprintf("opc.tcp://%s:%s/TestServer", host, port.Port())
+}
+
+// newE2EConfig returns a config that collects every severity from the test server.
+func newE2EConfig(endpoint string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.LogObjectPaths = []string{e2eServerLogPath}
+	cfg.Filter.MinSeverity = "Debug"
+	cfg.RequestTimeout = 15 * time.Second
+	return cfg
+}
+
+func TestE2E(t *testing.T) {
+	endpoint := startTestServer(t)
+	ctx := context.Background()
+
+	t.Run("security negotiation", func(t *testing.T) {
+		endpoints, err := opcua.GetEndpoints(ctx, endpoint)
+		require.NoError(t, err)
+		require.NotEmpty(t, endpoints)
+
+		c := newOPCUAClient(newE2EConfig(endpoint), zap.NewNop())
+		ep := c.selectEndpoint(endpoints)
+		require.NotNil(t, ep)
+		assert.Equal(t, ua.SecurityPolicyURINone, ep.SecurityPolicyURI)
+		assert.Equal(t, ua.MessageSecurityModeNone, ep.SecurityMode)
+	})
+
+	t.Run("connect and discover", func(t *testing.T) {
+		c := newOPCUAClient(newE2EConfig(endpoint), zap.NewNop())
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		assert.True(t, c.IsConnected())
+		require.Len(t, c.logObjectIDs, 1)
+		assert.Equal(t, e2eServerLogPath, c.logObjectIDs[0].String())
+
+		methodID, err := c.findGetRecordsMethod(ctx, c.logObjectIDs[0])
+		require.NoError(t, err)
+		assert.Equal(t, "ns=2;i=1001", methodID.String())
+	})
+
+	t.Run("get records", func(t *testing.T) {
+		c := newOPCUAClient(newE2EConfig(endpoint), zap.NewNop())
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+		require.NoError(t, err)
+		require.Len(t, records, e2eFixedRecordCount)
+
+		first := records[0]
+		assert.True(t, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC).Equal(first.Timestamp))
+		assert.Equal(t, uint16(150), first.Severity)
+		assert.Equal(t, "System startup initiated", first.Message)
+		assert.Equal(t, "SystemComponent", first.SourceName)
+		assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", first.TraceID)
+		assert.Equal(t, "0102030405060708", first.SpanID)
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		c := newOPCUAClient(newE2EConfig(endpoint), zap.NewNop())
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		const pageSize = 4
+		var pages []int
+		var continuationPoint []byte
+		for {
+			records, next, err := c.callGetRecordsMethod(
+				ctx, c.logObjectIDs[0], time.Time{}, time.Now(), pageSize, 1, continuationPoint)
+			require.NoError(t, err)
+			pages = append(pages, len(records))
+			if len(next) == 0 {
+				break
+			}
+			continuationPoint = next
+		}
+
+		assert.Equal(t, []int{4, 4, 2}, pages)
+	})
+
+	t.Run("severity filter", func(t *testing.T) {
+		cfg := newE2EConfig(endpoint)
+		cfg.Filter.MinSeverity = "Fatal"
+		c := newOPCUAClient(cfg, zap.NewNop())
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+		require.NoError(t, err)
+		for _, r := range records {
+			assert.GreaterOrEqual(t, r.Severity, uint16(401))
+		}
+		assert.Len(t, records, 2)
+	})
+}
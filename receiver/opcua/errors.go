@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// errNotConnected is wrapped in a connectionError when a call needs a session but there is none
+var errNotConnected = errors.New("client not connected")
+
+// The error types below classify the failures of the OPC UA client so the
+// receiver can branch with errors.As instead of matching messages. They wrap the
+// underlying error and keep its message. Status is the OPC UA status code
+// found in the wrapped error chain, or StatusOK if there is none.
+
+// connectionError is a failure to establish, authenticate or keep the session
+// with the server
+type connectionError struct {
+	Endpoint string
+	Status   ua.StatusCode
+	Err      error
+}
+
+func (e *connectionError) Error() string { return e.Err.Error() }
+
+func (e *connectionError) Unwrap() error { return e.Err }
+
+// discoveryError is a failure to find the LogObject nodes or their GetRecords method
+type discoveryError struct {
+	// NodeID is the LogObject node or browse path being resolved, empty if
+	// the failure concerns all of them
+	NodeID string
+	Status ua.StatusCode
+	Err    error
+}
+
+func (e *discoveryError) Error() string { return e.Err.Error() }
+
+func (e *discoveryError) Unwrap() error { return e.Err }
+
+// methodCallError is a failed GetRecords call, either of the Call service or
+// with a bad status code of the method itself
+type methodCallError struct {
+	ObjectID string
+	MethodID string
+	Status   ua.StatusCode
+	Err      error
+}
+
+func (e *methodCallError) Error() string { return e.Err.Error() }
+
+func (e *methodCallError) Unwrap() error { return e.Err }
+
+// decodeError is a GetRecords result that cannot be decoded into log records
+type decodeError struct {
+	// TypeID is the type of the undecodable value, the ExtensionObject TypeID if known
+	TypeID string
+	// Reason classifies the failure, derived from Err if empty (see decodeReason)
+	Reason string
+	Err    error
+}
+
+func (e *decodeError) Error() string { return e.Err.Error() }
+
+func (e *decodeError) Unwrap() error { return e.Err }
+
+func newConnectionError(endpoint string, err error) error {
+	return &connectionError{Endpoint: endpoint, Status: statusCode(err), Err: err}
+}
+
+func newDiscoveryError(nodeID string, err error) error {
+	return &discoveryError{NodeID: nodeID, Status: statusCode(err), Err: err}
+}
+
+func newMethodCallError(objectID, methodID *ua.NodeID, err error) error {
+	e := &methodCallError{Status: statusCode(err), Err: err}
+	if objectID != nil {
+		e.ObjectID = objectID.String()
+	}
+	if methodID != nil {
+		e.MethodID = methodID.String()
+	}
+	return e
+}
+
+func newDecodeError(typeID string, err error) error {
+	return &decodeError{TypeID: typeID, Err: err}
+}
+
+// Reasons a record is dropped while decoding a GetRecords result
+const (
+	// decodeReasonUnknownTypeID is an ExtensionObject of an unregistered type without a body
+	decodeReasonUnknownTypeID = "unknown_type_id"
+	// decodeReasonTruncatedBody is a binary body that ended before the record was complete
+	decodeReasonTruncatedBody = "truncated_body"
+	// decodeReasonBadVariantType is a value of a type that cannot hold a log record
+	decodeReasonBadVariantType = "bad_variant_type"
+	// decodeReasonInvalidBody is any other malformed record
+	decodeReasonInvalidBody = "invalid_body"
+)
+
+// decodeReason classifies a decode failure for the dropped records counter
+func decodeReason(err error) string {
+	var decodeErr *decodeError
+	switch {
+	case errors.As(err, &decodeErr) && decodeErr.Reason != "":
+		return decodeErr.Reason
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return decodeReasonTruncatedBody
+	default:
+		return decodeReasonInvalidBody
+	}
+}
+
+// statusCode returns the first OPC UA status code in the error chain, or StatusOK
+func statusCode(err error) ua.StatusCode {
+	var status ua.StatusCode
+	if errors.As(err, &status) {
+		return status
+	}
+	return ua.StatusOK
+}
+
+// statusName returns the name of an OPC UA status code without the Status
+// prefix, such as BadInternalError, or its hex value if the code is unknown
+func statusName(status ua.StatusCode) string {
+	if desc, ok := ua.StatusCodes[status]; ok {
+		return strings.TrimPrefix(desc.Name, "Status")
+	}
+	return fmt.Sprintf("0x%08X", uint32(status))
+}
+
+// failureStatus returns the status code name of err, empty if it has none
+func failureStatus(err error) string {
+	if status := statusCode(err); status != ua.StatusOK {
+		return statusName(status)
+	}
+	return ""
+}
+
+// errorClass names the class of err for logs and telemetry
+func errorClass(err error) string {
+	var (
+		connErr      *connectionError
+		discoveryErr *discoveryError
+		callErr      *methodCallError
+		decodeErr    *decodeError
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &connErr):
+		return "connection"
+	case errors.As(err, &discoveryErr):
+		return "discovery"
+	case errors.As(err, &callErr):
+		return "method_call"
+	case errors.As(err, &decodeErr):
+		return "decode"
+	default:
+		return "other"
+	}
+}
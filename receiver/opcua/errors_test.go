@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestErrorClass(t *testing.T) {
+	objectID := ua.NewNumericNodeID(1, 1000)
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"plain", errors.New("boom"), "other"},
+		{"connection", newConnectionError("opc.tcp://plc:4840", errNotConnected), "connection"},
+		{"discovery", newDiscoveryError("ns=1;i=1000", errors.New("not found")), "discovery"},
+		{"method call", newMethodCallError(objectID, nil, ua.StatusBadInternalError), "method_call"},
+		{"decode", newDecodeError("i=1", errors.New("short buffer")), "decode"},
+		{"wrapped", fmt.Errorf("failed to get records: %w", newDecodeError("", errors.New("short buffer"))), "decode"},
+		{
+			name:     "discovery caused by a lost connection",
+			err:      newDiscoveryError("ns=1;i=1000", newConnectionError("opc.tcp://plc:4840", errNotConnected)),
+			expected: "connection",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, errorClass(tt.err))
+		})
+	}
+}
+
+func TestStatusName(t *testing.T) {
+	assert.Equal(t, "BadInternalError", statusName(ua.StatusBadInternalError))
+	assert.Equal(t, "BadContinuationPointInvalid", statusName(ua.StatusBadContinuationPointInvalid))
+	assert.Equal(t, "0x80FF0000", statusName(ua.StatusCode(0x80FF0000)))
+
+	assert.Equal(t, "BadTimeout", failureStatus(fmt.Errorf("call: %w", ua.StatusBadTimeout)))
+	assert.Empty(t, failureStatus(errors.New("boom")))
+}
+
+func TestDecodeReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"explicit reason", &decodeError{Reason: decodeReasonUnknownTypeID, Err: errors.New("nil value")}, "unknown_type_id"},
+		{"truncated", newDecodeError("i=1", fmt.Errorf("failed to manually decode ExtensionObject body: %w", io.ErrUnexpectedEOF)), "truncated_body"},
+		{"other", newDecodeError("i=1", errors.New("invalid variant")), "invalid_body"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, decodeReason(tt.err))
+		})
+	}
+}
+
+func TestTypedErrorsKeepMessageAndStatus(t *testing.T) {
+	cause := fmt.Errorf("GetRecords method call failed with status: %w", ua.StatusBadInternalError)
+	err := newMethodCallError(ua.NewNumericNodeID(1, 1000), ua.NewNumericNodeID(1, 1001), cause)
+
+	assert.Equal(t, cause.Error(), err.Error())
+	assert.ErrorIs(t, err, ua.StatusBadInternalError)
+
+	var callErr *methodCallError
+	require.ErrorAs(t, err, &callErr)
+	assert.Equal(t, ua.StatusBadInternalError, callErr.Status)
+	assert.Equal(t, "ns=1;i=1000", callErr.ObjectID)
+	assert.Equal(t, "ns=1;i=1001", callErr.MethodID)
+
+	// Failures without a status code carry StatusOK
+	var connErr *connectionError
+	require.ErrorAs(t, newConnectionError("opc.tcp://plc:4840", errNotConnected), &connErr)
+	assert.Equal(t, ua.StatusOK, connErr.Status)
+	assert.Equal(t, "opc.tcp://plc:4840", connErr.Endpoint)
+}
+
+func TestClientWireErrorClasses(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("server unreachable", func(t *testing.T) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.Endpoint = fmt.Sprintf("opc.tcp://127.0.0.1:%d", freePort(t))
+		cfg.ConnectionTimeout = time.Second
+
+		err := newOPCUAClient(cfg, zap.NewNop()).Connect(ctx)
+		var connErr *connectionError
+		require.ErrorAs(t, err, &connErr)
+		assert.Equal(t, cfg.Endpoint, connErr.Endpoint)
+	})
+
+	t.Run("authentication rejected", func(t *testing.T) {
+		ws := startWireServer(t)
+		ws.SetAnonymousAccess(false)
+
+		err := newOPCUAClient(ws.newWireConfig(), zap.NewNop()).Connect(ctx)
+		var connErr *connectionError
+		require.ErrorAs(t, err, &connErr)
+		assert.Equal(t, ua.StatusBadIdentityTokenRejected, connErr.Status)
+	})
+
+	t.Run("not connected", func(t *testing.T) {
+		ws := startWireServer(t)
+		_, err := newOPCUAClient(ws.newWireConfig(), zap.NewNop()).GetRecords(ctx, time.Time{}, time.Now(), 100)
+		var connErr *connectionError
+		require.ErrorAs(t, err, &connErr)
+		assert.ErrorIs(t, err, errNotConnected)
+	})
+
+	t.Run("method call failed mid-pagination", func(t *testing.T) {
+		ws := startWireServer(t)
+		ws.AddLogRecords(wireRecords(10))
+		ws.SetMaxPageSize(3)
+		ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, Call: 2, Status: ua.StatusBadInternalError})
+
+		c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+		require.NoError(t, c.Connect(ctx))
+		defer func() {
+			assert.NoError(t, c.Disconnect(ctx))
+		}()
+
+		_, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+		var callErr *methodCallError
+		require.ErrorAs(t, err, &callErr)
+		assert.Equal(t, ua.StatusBadInternalError, callErr.Status)
+		assert.Equal(t, ws.logObjectID.String(), callErr.ObjectID)
+		assert.Equal(t, "method_call", errorClass(err))
+	})
+}
@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// logsExpectation describes the transformer configuration an expected
+// plog.Logs is built for. It spells out the documented mapping instead of
+// calling the transformer, so a diff against the transformer output catches
+// mapping regressions.
+type logsExpectation struct {
+	endpoint           string
+	serviceName        string
+	serviceNamespace   string
+	resourceAttributes metadata.ResourceAttributesConfig
+	migrations         []attributeMigration
+	emitNewNames       bool
+	dropOldNames       bool
+}
+
+// expectationOption configures a logsExpectation like the matching transformer setting
+type expectationOption func(*logsExpectation)
+
+// withExpectedEndpoint sets the server endpoint, "opc.tcp://test:4840" by default
+func withExpectedEndpoint(endpoint string) expectationOption {
+	return func(e *logsExpectation) {
+		e.endpoint = endpoint
+	}
+}
+
+// withExpectedService sets service.name and service.namespace
+func withExpectedService(name, namespace string) expectationOption {
+	return func(e *logsExpectation) {
+		e.serviceName = name
+		e.serviceNamespace = namespace
+	}
+}
+
+// withExpectedResourceAttributes sets the resource_attributes enable flags
+func withExpectedResourceAttributes(cfg metadata.ResourceAttributesConfig) expectationOption {
+	return func(e *logsExpectation) {
+		e.resourceAttributes = cfg
+	}
+}
+
+// withExpectedMigrations applies attribute renames as the feature gates would
+func withExpectedMigrations(migrations []attributeMigration, emitNewNames, dropOldNames bool) expectationOption {
+	return func(e *logsExpectation) {
+		e.migrations = migrations
+		e.emitNewNames = emitNewNames
+		e.dropOldNames = dropOldNames
+	}
+}
+
+// expectedLogs builds the plog.Logs TransformLogs produces for records with
+// the given options. The defaults match
+// newRecordTransformer("opc.tcp://test:4840", "opcua-server", "").
+func expectedLogs(records []testdata.OPCUALogRecord, opts ...expectationOption) plog.Logs {
+	e := &logsExpectation{
+		endpoint:           "opc.tcp://test:4840",
+		serviceName:        "opcua-server",
+		resourceAttributes: metadata.DefaultResourceAttributesConfig(),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	logs := plog.NewLogs()
+	if len(records) == 0 {
+		return logs
+	}
+
+	rl := logs.ResourceLogs().AppendEmpty()
+	e.resource(rl.Resource().Attributes())
+
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("github.com/bruegth/opentelemetry-collector-opcua-receiver")
+	sl.Scope().SetVersion("0.1.0")
+	for _, record := range records {
+		e.logRecord(record, sl.LogRecords().AppendEmpty())
+	}
+	return logs
+}
+
+func (e *logsExpectation) resource(attrs pcommon.Map) {
+	cfg := e.resourceAttributes
+	if cfg.ServiceName.Enabled {
+		attrs.PutStr("service.name", e.serviceName)
+	}
+	if cfg.ServiceNamespace.Enabled && e.serviceNamespace != "" {
+		attrs.PutStr("service.namespace", e.serviceNamespace)
+	}
+	if cfg.OpcuaServerEndpoint.Enabled {
+		attrs.PutStr("opcua.server.endpoint", e.endpoint)
+	}
+
+	u, err := url.Parse(e.endpoint)
+	if err != nil || u.Host == "" {
+		return
+	}
+	host, port, err := net.SplitHostPort(u.Host)
+	if err != nil {
+		host = u.Host
+	}
+	if cfg.ServerAddress.Enabled {
+		attrs.PutStr("server.address", host)
+	}
+	if p, err := strconv.ParseInt(port, 10, 64); err == nil && cfg.ServerPort.Enabled {
+		attrs.PutInt("server.port", p)
+	}
+}
+
+// expectedSeverities is the Part 26 severity table, upper bound inclusive
+var expectedSeverities = []struct {
+	upTo   uint16
+	number plog.SeverityNumber
+	text   string
+}{
+	{0, plog.SeverityNumberUnspecified, "Unspecified"},
+	{50, plog.SeverityNumberDebug, "Debug"},
+	{100, plog.SeverityNumberInfo, "Information"},
+	{150, plog.SeverityNumberInfo4, "Notice"},
+	{200, plog.SeverityNumberWarn, "Warning"},
+	{250, plog.SeverityNumberError, "Error"},
+	{300, plog.SeverityNumberError2, "Critical"},
+	{400, plog.SeverityNumberError3, "Alert"},
+	{1000, plog.SeverityNumberFatal, "Emergency"},
+}
+
+func (e *logsExpectation) logRecord(record testdata.OPCUALogRecord, lr plog.LogRecord) {
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(record.Timestamp))
+	lr.SetSeverityNumber(plog.SeverityNumberUnspecified)
+	lr.SetSeverityText("Unspecified")
+	for _, s := range expectedSeverities {
+		if record.Severity <= s.upTo {
+			lr.SetSeverityNumber(s.number)
+			lr.SetSeverityText(s.text)
+			break
+		}
+	}
+	lr.Body().SetStr(record.Message)
+
+	attrs := lr.Attributes()
+	if record.SourceName != "" {
+		attrs.PutStr("opcua.source.name", record.SourceName)
+	}
+	if record.SourceIDType != "" {
+		attrs.PutInt("opcua.source.namespace", int64(record.SourceNamespace))
+		attrs.PutStr("opcua.source.id_type", record.SourceIDType)
+		attrs.PutStr("opcua.source.id", record.SourceID)
+	}
+	for key, value := range record.Attributes {
+		switch v := value.(type) {
+		case string:
+			attrs.PutStr(key, v)
+		case int:
+			attrs.PutInt(key, int64(v))
+		case int64:
+			attrs.PutInt(key, v)
+		case float64:
+			attrs.PutDouble(key, v)
+		case bool:
+			attrs.PutBool(key, v)
+		default:
+			attrs.PutStr(key, fmt.Sprintf("%v", v))
+		}
+	}
+
+	if e.emitNewNames {
+		for _, m := range e.migrations {
+			if value, ok := attrs.Get(m.oldName); ok {
+				value.CopyTo(attrs.PutEmpty(m.newName))
+				if e.dropOldNames {
+					attrs.Remove(m.oldName)
+				}
+			}
+		}
+	}
+
+	if record.TraceID == "" || record.SpanID == "" {
+		return
+	}
+	if b, err := hex.DecodeString(record.TraceID); err == nil && len(b) == 16 {
+		lr.SetTraceID(pcommon.TraceID(b))
+	}
+	if b, err := hex.DecodeString(record.SpanID); err == nil && len(b) == 8 {
+		lr.SetSpanID(pcommon.SpanID(b))
+	}
+	lr.SetFlags(plog.DefaultLogRecordFlags.WithIsSampled(record.TraceFlags&0x01 != 0))
+}
+
+// requireLogs diffs actual against the expected logs for records. The
+// observed timestamp is the collector clock and is ignored.
+func requireLogs(t *testing.T, records []testdata.OPCUALogRecord, actual plog.Logs, opts ...expectationOption) {
+	t.Helper()
+	require.NoError(t, plogtest.CompareLogs(expectedLogs(records, opts...), actual, plogtest.IgnoreObservedTimestamp()))
+}
@@ -5,32 +5,74 @@ package opcua
 
 import (
 	"context"
+	"fmt"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper/scraperhelper"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/sharedcomponent"
 )
 
 var (
 	// Type is the type of this receiver
-	Type = component.MustNewType("opcua")
+	Type = metadata.Type
 
 	// Stability level of the receiver
-	stability = component.StabilityLevelAlpha
+	stability = metadata.LogsStability
+
+	// receivers shares one receiver instance, and therefore one OPC UA connection,
+	// between all signals created from the same config
+	receivers = sharedcomponent.NewMap[*Config, *opcuaReceiver]()
 )
 
+// ClientFactory creates the OPC UA client of a receiver. It is called when the
+// receiver starts; host gives access to the collector's extensions.
+type ClientFactory func(host component.Host, cfg *Config, settings component.TelemetrySettings) (OPCUAClient, error)
+
+// FactoryOption customizes the receivers created by NewFactory
+type FactoryOption func(*opcuaFactory)
+
+// WithClientFactory makes the receivers create their OPC UA client with f
+// instead of the built-in gopcua client, for example to reuse a session
+// established by the embedding distribution. Config.Client, when set, takes
+// precedence.
+func WithClientFactory(f ClientFactory) FactoryOption {
+	return func(factory *opcuaFactory) {
+		factory.clientFactory = f
+	}
+}
+
+// opcuaFactory holds the options the receivers are created with
+type opcuaFactory struct {
+	clientFactory ClientFactory
+}
+
 // NewFactory creates a factory for OPC UA receiver
-func NewFactory() receiver.Factory {
+func NewFactory(opts ...FactoryOption) receiver.Factory {
+	f := &opcuaFactory{}
+	for _, opt := range opts {
+		opt(f)
+	}
 	return receiver.NewFactory(
 		Type,
 		createDefaultConfig,
-		receiver.WithLogs(createLogsReceiver, stability),
+		receiver.WithLogs(f.createLogsReceiver, stability),
+		receiver.WithMetrics(f.createMetricsReceiver, metadata.MetricsStability),
+		receiver.WithTraces(f.createTracesReceiver, metadata.TracesStability),
 	)
 }
 
 // createDefaultConfig creates the default configuration for the receiver
 func createDefaultConfig() component.Config {
+	controller := scraperhelper.NewDefaultControllerConfig()
+	controller.CollectionInterval = 30 * time.Second
+
 	return &Config{
 		Endpoint:       "opc.tcp://localhost:4840",
 		SecurityPolicy: "None",
@@ -38,32 +80,165 @@ func createDefaultConfig() component.Config {
 		Auth: AuthConfig{
 			Type: "anonymous",
 		},
-		LogObjectPaths:     []string{"Objects/ServerLog"},
-		CollectionInterval: 30 * time.Second,
-		MaxRecordsPerCall:  1000,
-		ConnectionTimeout:  30 * time.Second,
-		RequestTimeout:     10 * time.Second,
+		LogObjectPaths:         []string{"Objects/ServerLog"},
+		Mode:                   collectionModePoll,
+		ControllerConfig:       controller,
+		MaxRecordsPerCall:      1000,
+		MaxConcurrentReads:     1,
+		MaxSpooledBatches:      1000,
+		StartAt:                startAtBeginning,
+		ResourceProfile:        resourceProfileDefault,
+		BodyFormat:             bodyFormatString,
+		ConnectionTimeout:      30 * time.Second,
+		RequestTimeout:         10 * time.Second,
+		LogSuppressionInterval: 5 * time.Minute,
 		Filter: FilterConfig{
 			MinSeverity:   "Info",
 			MaxLogRecords: 10000,
 		},
+		RecordFields: RecordFieldsConfig{
+			EventType:      true,
+			SourceNode:     true,
+			SourceName:     true,
+			TraceContext:   true,
+			AdditionalData: true,
+		},
 		TLS: TLSConfig{
-			InsecureSkipVerify: false,
+			ClientConfig: configtls.NewDefaultClientConfig(),
+			GenerateCertificate: GenerateCertificateConfig{
+				KeySize:  2048,
+				Validity: 365 * 24 * time.Hour,
+			},
+		},
+		GDS: GDSConfig{
+			Auth: AuthConfig{
+				Type: "anonymous",
+			},
+			RenewBefore:   30 * 24 * time.Hour,
+			CheckInterval: time.Hour,
 		},
 		Resource: ResourceConfig{
 			ServiceName: "opcua-server",
 		},
+		ResourceAttributes: metadata.DefaultResourceAttributesConfig(),
+		Subscription: SubscriptionConfig{
+			PublishingInterval: time.Second,
+			QueueSize:          1000,
+		},
+		Alarms: AlarmsConfig{
+			Notifiers: []string{"i=2253"},
+		},
+		PubSub: PubSubConfig{
+			Transport: pubsubTransportMQTT,
+			QoS:       1,
+			TLS:       configtls.NewDefaultClientConfig(),
+			DataSet: DataSetConfig{
+				Fields: slices.Clone(logEventFields),
+			},
+		},
+		Reconnect: ReconnectConfig{
+			InitialInterval:     defaultReconnectInitialInterval,
+			MaxInterval:         defaultReconnectMaxInterval,
+			Multiplier:          defaultReconnectMultiplier,
+			RandomizationFactor: defaultReconnectRandomizationFactor,
+		},
+		KeepAlive: KeepAliveConfig{
+			Timeout:     5 * time.Second,
+			MaxFailures: 2,
+		},
+		Redundancy: RedundancyConfig{
+			FailbackInterval: time.Minute,
+		},
+		Discovery: DiscoveryConfig{
+			Interval: 5 * time.Minute,
+		},
+		Health: HealthConfig{
+			ErrorRateWindow:    10 * time.Minute,
+			ErrorRateThreshold: 0.5,
+			MinScrapes:         3,
+		},
+		Diagnostics: DiagnosticsConfig{
+			GapThreshold: time.Minute,
+		},
+		Traces: TracesConfig{
+			ParentSpan: parentSpanParent,
+			SpanEvents: true,
+		},
+		StatusWriteBack: StatusWriteBackConfig{
+			Interval: time.Minute,
+		},
+		DebugDump: DebugDumpConfig{
+			MaxBytes:     64 * 1024,
+			MaxPerMinute: 10,
+		},
 	}
 }
 
 // createLogsReceiver creates a logs receiver based on the config
-func createLogsReceiver(
-	ctx context.Context,
+func (f *opcuaFactory) createLogsReceiver(
+	_ context.Context,
 	set receiver.Settings,
 	cfg component.Config,
 	nextConsumer consumer.Logs,
 ) (receiver.Logs, error) {
-	receiverConfig := cfg.(*Config)
+	if nextConsumer == nil {
+		return nil, fmt.Errorf("nil nextConsumer")
+	}
+
+	r, err := f.loadOrCreateReceiver(cfg.(*Config), set)
+	if err != nil {
+		return nil, err
+	}
+	r.Unwrap().nextLogs = nextConsumer
+	return r, nil
+}
+
+// createMetricsReceiver creates a metrics receiver based on the config
+func (f *opcuaFactory) createMetricsReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Metrics,
+) (receiver.Metrics, error) {
+	if nextConsumer == nil {
+		return nil, fmt.Errorf("nil nextConsumer")
+	}
+
+	r, err := f.loadOrCreateReceiver(cfg.(*Config), set)
+	if err != nil {
+		return nil, err
+	}
+	r.Unwrap().nextMetrics = nextConsumer
+	return r, nil
+}
+
+// createTracesReceiver creates a traces receiver based on the config
+func (f *opcuaFactory) createTracesReceiver(
+	_ context.Context,
+	set receiver.Settings,
+	cfg component.Config,
+	nextConsumer consumer.Traces,
+) (receiver.Traces, error) {
+	if nextConsumer == nil {
+		return nil, fmt.Errorf("nil nextConsumer")
+	}
+
+	r, err := f.loadOrCreateReceiver(cfg.(*Config), set)
+	if err != nil {
+		return nil, err
+	}
+	r.Unwrap().nextTraces = nextConsumer
+	return r, nil
+}
 
-	return newLogsReceiver(receiverConfig, set, nextConsumer)
+// loadOrCreateReceiver returns the receiver shared by all signals of the given config
+func (f *opcuaFactory) loadOrCreateReceiver(cfg *Config, set receiver.Settings) (*sharedcomponent.Component[*opcuaReceiver], error) {
+	return receivers.LoadOrStore(cfg, func() (*opcuaReceiver, error) {
+		r, err := newOPCUAReceiver(cfg, set)
+		if err != nil {
+			return nil, err
+		}
+		r.scraper.clientFactory = f.clientFactory
+		return r, nil
+	})
 }
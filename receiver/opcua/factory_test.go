@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/sharedcomponent"
+)
+
+func TestFactorySignals(t *testing.T) {
+	factory := NewFactory()
+
+	assert.Equal(t, metadata.LogsStability, factory.LogsStability())
+	assert.Equal(t, metadata.MetricsStability, factory.MetricsStability())
+	assert.Equal(t, metadata.TracesStability, factory.TracesStability())
+}
+
+func TestFactorySharesReceiver(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	set := receivertest.NewNopSettings(metadata.Type)
+	ctx := context.Background()
+
+	logs, err := factory.CreateLogs(ctx, set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	metrics, err := factory.CreateMetrics(ctx, set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	traces, err := factory.CreateTraces(ctx, set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+
+	assert.Same(t, logs, metrics)
+	assert.Same(t, logs, traces)
+
+	shared := logs.(*sharedcomponent.Component[*opcuaReceiver]).Unwrap()
+	assert.NotNil(t, shared.nextLogs)
+	assert.NotNil(t, shared.nextMetrics)
+	assert.NotNil(t, shared.nextTraces)
+
+	// Shutting down removes the shared instance so a fresh one is created next time
+	require.NoError(t, logs.Shutdown(ctx))
+	other, err := factory.CreateLogs(ctx, set, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotSame(t, logs, other)
+	require.NoError(t, other.Shutdown(ctx))
+}
+
+func TestFactoryNilConsumer(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	set := receivertest.NewNopSettings(metadata.Type)
+
+	_, err := factory.CreateLogs(context.Background(), set, cfg, nil)
+	assert.Error(t, err)
+	_, err = factory.CreateMetrics(context.Background(), set, cfg, nil)
+	assert.Error(t, err)
+	_, err = factory.CreateTraces(context.Background(), set, cfg, nil)
+	assert.Error(t, err)
+}
+
+func TestFactoryInvalidConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = ""
+
+	_, err := factory.CreateMetrics(context.Background(), receivertest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	assert.Error(t, err)
+}
+
+func TestFactoryClientFactory(t *testing.T) {
+	client := &windowClient{}
+	var gotCfg *Config
+	factory := NewFactory(WithClientFactory(func(_ component.Host, cfg *Config, _ component.TelemetrySettings) (OPCUAClient, error) {
+		gotCfg = cfg
+		return client, nil
+	}))
+	cfg := factory.CreateDefaultConfig()
+	ctx := context.Background()
+
+	logs, err := factory.CreateLogs(ctx, receivertest.NewNopSettings(metadata.Type), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, logs.Start(ctx, componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, logs.Shutdown(ctx)) })
+
+	assert.Same(t, cfg, gotCfg)
+	assert.Same(t, client, logs.(*sharedcomponent.Component[*opcuaReceiver]).Unwrap().scraper.client)
+}
+
+func TestFactoryClientFactoryError(t *testing.T) {
+	factory := NewFactory(WithClientFactory(func(component.Host, *Config, component.TelemetrySettings) (OPCUAClient, error) {
+		return nil, errors.New("no session")
+	}))
+	ctx := context.Background()
+
+	logs, err := factory.CreateLogs(ctx, receivertest.NewNopSettings(metadata.Type), factory.CreateDefaultConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+	err = logs.Start(ctx, componenttest.NewNopHost())
+	require.ErrorContains(t, err, "no session")
+}
+
+func TestReceiverPollsWithController(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(3))
+	cfg := ws.newWireConfig()
+	cfg.InitialDelay = 0
+	cfg.Timeout = 10 * time.Second
+	ctx := context.Background()
+
+	logsSink := new(consumertest.LogsSink)
+	metricsSink := new(consumertest.MetricsSink)
+	factory := NewFactory()
+	set := receivertest.NewNopSettings(metadata.Type)
+	logs, err := factory.CreateLogs(ctx, set, cfg, logsSink)
+	require.NoError(t, err)
+	_, err = factory.CreateMetrics(ctx, set, cfg, metricsSink)
+	require.NoError(t, err)
+
+	require.NoError(t, logs.Start(ctx, componenttest.NewNopHost()))
+	r := logs.(*sharedcomponent.Component[*opcuaReceiver]).Unwrap()
+	require.NotNil(t, r.controller, "poll mode runs on the scraper controller")
+
+	// The first scrape runs right away and feeds every signal
+	require.Eventually(t, func() bool {
+		return logsSink.LogRecordCount() == 3 && len(metricsSink.AllMetrics()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, logs.Shutdown(ctx))
+	assert.Equal(t, 3, logsSink.LogRecordCount())
+}
+
+func TestReceiverPollShutdownReturnsPromptly(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(1))
+	cfg := ws.newWireConfig()
+	cfg.InitialDelay = 0
+	ctx := context.Background()
+
+	sink := new(consumertest.LogsSink)
+	logs, err := NewFactory().CreateLogs(ctx, receivertest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, logs.Start(ctx, componenttest.NewNopHost()))
+	r := logs.(*sharedcomponent.Component[*opcuaReceiver]).Unwrap()
+	assert.Nil(t, r.cancel, "poll mode runs no goroutine of its own")
+
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	// Nothing is waited for beyond the running scrape
+	start := time.Now()
+	require.NoError(t, logs.Shutdown(ctx))
+	assert.Less(t, time.Since(start), time.Second)
+}
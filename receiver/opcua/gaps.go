@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// gapSeverity is the Part 26 severity of diagnostic gap records (Warning)
+const gapSeverity = 200
+
+// recordGap is a span at the start of a collection window that a LogObject
+// returned no records for
+type recordGap struct {
+	logObjectID string
+	start       time.Time
+	end         time.Time
+}
+
+// detectGaps returns, per LogObject, the span between windowStart and the
+// earliest record read from it when that span exceeds threshold. LogObjects
+// without records and the first collection, which has no window start, are
+// not checked.
+func detectGaps(windowStart time.Time, records []model.LogRecord, threshold time.Duration) []recordGap {
+	return gapsBefore(windowStart, addEarliest(nil, records), threshold)
+}
+
+// addEarliest keeps the timestamp of the earliest record per LogObject in
+// earliest, which is created if nil, and returns it
+func addEarliest(earliest map[string]time.Time, records []model.LogRecord) map[string]time.Time {
+	if earliest == nil {
+		earliest = make(map[string]time.Time)
+	}
+	for _, record := range records {
+		if t, ok := earliest[record.LogObjectID]; !ok || record.Timestamp.Before(t) {
+			earliest[record.LogObjectID] = record.Timestamp
+		}
+	}
+	return earliest
+}
+
+// gapsBefore returns the gaps between windowStart and the earliest record
+// timestamps per LogObject, see detectGaps
+func gapsBefore(windowStart time.Time, earliest map[string]time.Time, threshold time.Duration) []recordGap {
+	if windowStart.IsZero() {
+		return nil
+	}
+
+	var gaps []recordGap
+	for logObjectID, t := range earliest {
+		if t.Sub(windowStart) > threshold {
+			gaps = append(gaps, recordGap{logObjectID: logObjectID, start: windowStart, end: t})
+		}
+	}
+	sort.Slice(gaps, func(i, j int) bool {
+		return gaps[i].logObjectID < gaps[j].logObjectID
+	})
+	return gaps
+}
+
+// gapRecords reports the gaps before the earliest record timestamps of a
+// collection that started at windowStart, by LogObject, and creates a
+// diagnostic log record for each
+func (s *scraper) gapRecords(ctx context.Context, windowStart time.Time, earliest map[string]time.Time) []model.LogRecord {
+	gaps := gapsBefore(windowStart, earliest, s.config.Diagnostics.GapThreshold)
+	if len(gaps) == 0 {
+		return nil
+	}
+
+	result := make([]model.LogRecord, 0, len(gaps))
+	for _, gap := range gaps {
+		s.telemetry.OpcuaRecordGaps.Add(ctx, 1,
+			metric.WithAttributes(attribute.String("opcua.log_object", gap.logObjectID)))
+		s.errorLog.Warn(s.settings.Logger, "gap/"+gap.logObjectID,
+			"No records from LogObject at the start of the collection window, the server may have overwritten them",
+			zap.String("node_id", gap.logObjectID),
+			zap.Time("gap_start", gap.start),
+			zap.Time("gap_end", gap.end))
+
+		result = append(result, model.LogRecord{
+			Timestamp: s.now(),
+			Severity:  gapSeverity,
+			Message: fmt.Sprintf("No records from LogObject %s between %s and %s, the server may have overwritten them",
+				gap.logObjectID, gap.start.Format(time.RFC3339Nano), gap.end.Format(time.RFC3339Nano)),
+			SourceName:  "opcua receiver",
+			LogObjectID: gap.logObjectID,
+			Attributes: map[string]interface{}{
+				"opcua.log_object": gap.logObjectID,
+				"opcua.gap.start":  gap.start.Format(time.RFC3339Nano),
+				"opcua.gap.end":    gap.end.Format(time.RFC3339Nano),
+			},
+		})
+	}
+	return result
+}
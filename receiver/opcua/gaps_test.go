@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+func TestDetectGaps(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	record := func(logObjectID string, offset time.Duration) model.LogRecord {
+		return model.LogRecord{LogObjectID: logObjectID, Timestamp: start.Add(offset)}
+	}
+
+	tests := []struct {
+		name        string
+		windowStart time.Time
+		records     []model.LogRecord
+		want        []recordGap
+	}{
+		{
+			name:    "first collection",
+			records: []model.LogRecord{record("ns=1;i=1000", time.Hour)},
+		},
+		{
+			name:        "no records",
+			windowStart: start,
+		},
+		{
+			name:        "earliest record within threshold",
+			windowStart: start,
+			records:     []model.LogRecord{record("ns=1;i=1000", 2*time.Minute), record("ns=1;i=1000", 30*time.Second)},
+		},
+		{
+			name:        "earliest record at threshold",
+			windowStart: start,
+			records:     []model.LogRecord{record("ns=1;i=1000", time.Minute)},
+		},
+		{
+			name:        "gaps per LogObject",
+			windowStart: start,
+			records: []model.LogRecord{
+				record("ns=1;i=2000", 5*time.Minute),
+				record("ns=1;i=1000", 10*time.Second),
+				record("ns=1;i=3000", 3*time.Minute),
+				record("ns=1;i=3000", 2*time.Minute),
+			},
+			want: []recordGap{
+				{logObjectID: "ns=1;i=2000", start: start, end: start.Add(5 * time.Minute)},
+				{logObjectID: "ns=1;i=3000", start: start, end: start.Add(2 * time.Minute)},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, detectGaps(tt.windowStart, tt.records, time.Minute))
+		})
+	}
+}
@@ -0,0 +1,856 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+const (
+	// gdsNamespaceURI is the namespace of the GDS information model (Part 12)
+	gdsNamespaceURI = "http://opcfoundation.org/UA/GDS/"
+
+	// gdsDirectoryID is the Directory object, which has the methods of
+	// DirectoryType and CertificateDirectoryType
+	gdsDirectoryID = "nsu=" + gdsNamespaceURI + ";i=141"
+
+	// gdsApplicationRecordEncodingID is the DefaultBinary encoding of
+	// ApplicationRecordDataType in the GDS namespace
+	gdsApplicationRecordEncodingID = 134
+
+	// gdsKeySize is the size of the RSA keys generated for the application
+	// certificate
+	gdsKeySize = 2048
+
+	// gdsBootstrapValidity is the validity of the self-signed certificate
+	// used until the GDS issued one
+	gdsBootstrapValidity = 365 * 24 * time.Hour
+
+	// gdsFileReadSize is the number of bytes read from the trust list file per call
+	gdsFileReadSize = 64 * 1024
+
+	// fileOpenModeRead opens a FileType object for reading, see Part 5, C.2.1
+	fileOpenModeRead = 1
+)
+
+// Files of the GDS credentials in gds.directory
+const (
+	gdsBootstrapCertFile = "bootstrap.der"
+	gdsBootstrapKeyFile  = "bootstrap_key.pem"
+	gdsCertFile          = "certificate.der"
+	gdsKeyFile           = "private_key.pem"
+	gdsTrustListFile     = "trust_list.bin"
+	gdsApplicationIDFile = "application_id"
+)
+
+// applicationRecord is the ApplicationRecordDataType of the GDS namespace,
+// which gopcua does not know
+type applicationRecord struct {
+	ApplicationID      *ua.NodeID
+	ApplicationURI     string
+	ApplicationType    ua.ApplicationType
+	ApplicationNames   []*ua.LocalizedText
+	ProductURI         string
+	DiscoveryURLs      []string
+	ServerCapabilities []string
+}
+
+// gdsDirectory is the part of a GDS session the provider uses, see gdsSession
+type gdsDirectory interface {
+	// registerApplication registers the application and returns its ApplicationId
+	registerApplication(ctx context.Context, record *applicationRecord) (*ua.NodeID, error)
+
+	// applicationID returns the ApplicationId in a form that stays valid
+	// across sessions
+	applicationID(id *ua.NodeID) string
+
+	// resolveApplicationID parses an ApplicationId returned by applicationID
+	resolveApplicationID(s string) (*ua.NodeID, error)
+
+	// startSigningRequest submits a certificate signing request and returns its RequestId
+	startSigningRequest(ctx context.Context, applicationID *ua.NodeID, csr []byte) (*ua.NodeID, error)
+
+	// finishRequest returns the certificate of a request, or an error with
+	// StatusBadNothingToDo while it is pending
+	finishRequest(ctx context.Context, applicationID, requestID *ua.NodeID) ([]byte, error)
+
+	// trustList reads the trust list of the application
+	trustList(ctx context.Context, applicationID *ua.NodeID) (*ua.TrustListDataType, error)
+
+	close(ctx context.Context) error
+}
+
+// gdsPendingRequest is a signing request the GDS has not completed yet
+type gdsPendingRequest struct {
+	id  *ua.NodeID
+	key *rsa.PrivateKey
+}
+
+// gdsProvider is the certificate provider of gds: it enrolls the client
+// with a Global Discovery Server using the pull model of Part 12 and renews
+// the application certificate and trust list before they expire. The
+// credentials are kept in gds.directory, so a restart does not enroll again.
+type gdsProvider struct {
+	config   GDSConfig
+	identity clientIdentity
+	logger   *zap.Logger
+
+	// timeout bounds a check, from connecting to the GDS to closing the session
+	timeout time.Duration
+
+	// connect opens a session with the GDS using the given application certificate
+	connect func(ctx context.Context, cert []byte, key *rsa.PrivateKey) (gdsDirectory, error)
+
+	// renewed is called after a new certificate or trust list was stored, if set
+	renewed func(ctx context.Context)
+
+	now func() time.Time
+
+	mu            sync.Mutex
+	bootstrapCert []byte
+	bootstrapKey  *rsa.PrivateKey
+	cert          []byte
+	key           *rsa.PrivateKey
+	trusted       []*x509.Certificate
+	applicationID string
+	pending       *gdsPendingRequest
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ CertificateProvider = (*gdsProvider)(nil)
+
+// newGDSProvider creates the provider for the gds settings
+func newGDSProvider(config *Config, identity clientIdentity, logger *zap.Logger) *gdsProvider {
+	p := &gdsProvider{
+		config:   config.GDS,
+		identity: identity,
+		logger:   logger.With(zap.String("gds_endpoint", config.GDS.Endpoint)),
+		timeout:  config.ConnectionTimeout,
+		now:      time.Now,
+	}
+	p.connect = func(ctx context.Context, cert []byte, key *rsa.PrivateKey) (gdsDirectory, error) {
+		return openGDSSession(ctx, config, identity, p.logger, cert, key, p.trustedCertificates())
+	}
+	return p
+}
+
+// Start loads the stored credentials, enrolls if there are none or they are
+// due for renewal and starts the periodic check. A GDS that cannot be
+// reached is retried with the next check instead of failing the start.
+func (p *gdsProvider) Start(ctx context.Context, _ component.Host) error {
+	if err := os.MkdirAll(p.config.Directory, 0o700); err != nil {
+		return fmt.Errorf("failed to create gds directory: %w", err)
+	}
+	if err := p.load(); err != nil {
+		return err
+	}
+	if err := p.ensureBootstrap(); err != nil {
+		return err
+	}
+
+	// The client has not connected yet, so renewed is not called for the
+	// first check
+	checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	p.check(checkCtx)
+	cancel()
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.run(runCtx)
+	return nil
+}
+
+// Shutdown stops the periodic check
+func (p *gdsProvider) Shutdown(context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+	return nil
+}
+
+// ApplicationCertificate returns the certificate issued by the GDS, or the
+// self-signed bootstrap certificate until the GDS issued one
+func (p *gdsProvider) ApplicationCertificate(context.Context) ([]byte, *rsa.PrivateKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cert != nil {
+		return p.cert, p.key, nil
+	}
+	if p.bootstrapCert != nil {
+		return p.bootstrapCert, p.bootstrapKey, nil
+	}
+	return nil, nil, errors.New("no application certificate enrolled with the GDS")
+}
+
+// TrustedCertificates returns the trusted certificates of the trust list
+// pulled from the GDS
+func (p *gdsProvider) TrustedCertificates(context.Context) ([]*x509.Certificate, error) {
+	return p.trustedCertificates(), nil
+}
+
+func (p *gdsProvider) trustedCertificates() []*x509.Certificate {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.trusted
+}
+
+// run checks the credentials every check_interval until ctx is cancelled
+func (p *gdsProvider) run(ctx context.Context) {
+	defer close(p.done)
+	ticker := time.NewTicker(p.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+			if p.check(checkCtx) && p.renewed != nil {
+				p.renewed(checkCtx)
+			}
+			cancel()
+		}
+	}
+}
+
+// check pulls the trust list and renews the certificate if it is missing,
+// due for renewal or a signing request is pending. It reports whether the
+// stored credentials changed.
+func (p *gdsProvider) check(ctx context.Context) bool {
+	cert, key, err := p.ApplicationCertificate(ctx)
+	if err != nil {
+		p.logger.Warn("Cannot connect to the GDS", zap.Error(err))
+		return false
+	}
+	dir, err := p.connect(ctx, cert, key)
+	if err != nil {
+		p.logger.Warn("Failed to connect to the GDS", zap.Error(err))
+		return false
+	}
+	defer func() {
+		if err := dir.close(ctx); err != nil {
+			p.logger.Debug("Failed to close GDS session", zap.Error(err))
+		}
+	}()
+
+	changed, err := p.pull(ctx, dir)
+	if err != nil {
+		p.logger.Warn("Failed to update credentials from the GDS", zap.Error(err))
+	}
+	return changed
+}
+
+// pull runs the pull model against an open session and reports whether the
+// stored certificate or trust list changed
+func (p *gdsProvider) pull(ctx context.Context, dir gdsDirectory) (bool, error) {
+	applicationID, err := p.register(ctx, dir)
+	if err != nil {
+		return false, err
+	}
+
+	renewedCert := false
+	if p.dueForRenewal() {
+		if renewedCert, err = p.renew(ctx, dir, applicationID); err != nil {
+			return false, err
+		}
+	}
+
+	trustList, err := dir.trustList(ctx, applicationID)
+	if err != nil {
+		return renewedCert, fmt.Errorf("failed to read trust list: %w", err)
+	}
+	updatedTrust, err := p.storeTrustList(trustList)
+	if err != nil {
+		return renewedCert, err
+	}
+	return renewedCert || updatedTrust, nil
+}
+
+// register returns the stored ApplicationId, registering the application
+// with the GDS first if there is none
+func (p *gdsProvider) register(ctx context.Context, dir gdsDirectory) (*ua.NodeID, error) {
+	p.mu.Lock()
+	stored := p.applicationID
+	p.mu.Unlock()
+	if stored != "" {
+		id, err := dir.resolveApplicationID(stored)
+		if err == nil {
+			return id, nil
+		}
+		p.logger.Warn("Registering again, stored ApplicationId is invalid",
+			zap.String("application_id", stored), zap.Error(err))
+	}
+
+	id, err := dir.registerApplication(ctx, &applicationRecord{
+		ApplicationID:    ua.NewNumericNodeID(0, 0),
+		ApplicationURI:   p.config.ApplicationURI,
+		ApplicationType:  ua.ApplicationTypeClient,
+		ApplicationNames: []*ua.LocalizedText{ua.NewLocalizedText(p.identity.applicationName)},
+		ProductURI:       p.identity.productURI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register application: %w", err)
+	}
+
+	stored = dir.applicationID(id)
+	if err := writeFileAtomic(filepath.Join(p.config.Directory, gdsApplicationIDFile), []byte(stored)); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	p.applicationID = stored
+	p.mu.Unlock()
+	p.logger.Info("Registered application with the GDS",
+		zap.String("application_uri", p.config.ApplicationURI),
+		zap.String("application_id", stored))
+	return id, nil
+}
+
+// dueForRenewal reports whether a certificate has to be requested
+func (p *gdsProvider) dueForRenewal() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.cert == nil || p.pending != nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(p.cert)
+	if err != nil {
+		return true
+	}
+	return !p.now().Before(cert.NotAfter.Add(-p.config.RenewBefore))
+}
+
+// renew requests a certificate for a new key, or completes the pending
+// request, and stores it. It reports false while the request is pending
+// approval at the GDS.
+func (p *gdsProvider) renew(ctx context.Context, dir gdsDirectory, applicationID *ua.NodeID) (bool, error) {
+	p.mu.Lock()
+	pending := p.pending
+	p.mu.Unlock()
+
+	if pending == nil {
+		key, err := rsa.GenerateKey(rand.Reader, gdsKeySize)
+		if err != nil {
+			return false, fmt.Errorf("failed to generate key: %w", err)
+		}
+		csr, err := p.certificateRequest(key)
+		if err != nil {
+			return false, err
+		}
+		requestID, err := dir.startSigningRequest(ctx, applicationID, csr)
+		if err != nil {
+			return false, fmt.Errorf("failed to start signing request: %w", err)
+		}
+		pending = &gdsPendingRequest{id: requestID, key: key}
+		p.mu.Lock()
+		p.pending = pending
+		p.mu.Unlock()
+	}
+
+	cert, err := dir.finishRequest(ctx, applicationID, pending.id)
+	if errors.Is(err, ua.StatusBadNothingToDo) {
+		p.logger.Info("Certificate request is pending approval at the GDS",
+			zap.String("request_id", pending.id.String()))
+		return false, nil
+	}
+	if err != nil {
+		// The request is not retried, a new one is started with the next check
+		p.mu.Lock()
+		p.pending = nil
+		p.mu.Unlock()
+		return false, fmt.Errorf("failed to finish signing request: %w", err)
+	}
+
+	parsed, err := x509.ParseCertificate(cert)
+	if err != nil {
+		return false, fmt.Errorf("GDS returned an invalid certificate: %w", err)
+	}
+	if err := p.storeCertificate(cert, pending.key); err != nil {
+		return false, err
+	}
+	p.logger.Info("Received application certificate from the GDS",
+		zap.String("subject", parsed.Subject.String()),
+		zap.Time("not_after", parsed.NotAfter))
+	return true, nil
+}
+
+// certificateRequest creates a signing request for key carrying the
+// application URI, as Part 6, 6.2.2 requires of application certificates
+func (p *gdsProvider) certificateRequest(key *rsa.PrivateKey) ([]byte, error) {
+	uri, err := url.Parse(p.config.ApplicationURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid application_uri: %w", err)
+	}
+	template := &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: p.identity.applicationName},
+		URIs:    []*url.URL{uri},
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		template.DNSNames = []string{hostname}
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, template, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate request: %w", err)
+	}
+	return csr, nil
+}
+
+// storeCertificate writes the issued certificate and its key to the
+// directory and uses them from the next connect
+func (p *gdsProvider) storeCertificate(cert []byte, key *rsa.PrivateKey) error {
+	if err := writeKeyFile(filepath.Join(p.config.Directory, gdsKeyFile), key); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(p.config.Directory, gdsCertFile), cert); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.cert, p.key = cert, key
+	p.pending = nil
+	p.mu.Unlock()
+	return nil
+}
+
+// storeTrustList writes the trust list to the directory if it changed and
+// reports whether it did
+func (p *gdsProvider) storeTrustList(trustList *ua.TrustListDataType) (bool, error) {
+	encoded, err := ua.Encode(trustList)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode trust list: %w", err)
+	}
+	path := filepath.Join(p.config.Directory, gdsTrustListFile)
+	if stored, err := os.ReadFile(path); err == nil && string(stored) == string(encoded) {
+		return false, nil
+	}
+
+	trusted, err := parseTrustList(trustList)
+	if err != nil {
+		return false, err
+	}
+	if err := writeFileAtomic(path, encoded); err != nil {
+		return false, err
+	}
+	p.mu.Lock()
+	p.trusted = trusted
+	p.mu.Unlock()
+	p.logger.Info("Updated trust list from the GDS", zap.Int("trusted_certificates", len(trusted)))
+	return true, nil
+}
+
+// load reads the credentials stored in the directory
+func (p *gdsProvider) load() error {
+	dir := p.config.Directory
+	bootstrapCert, bootstrapKey, err := loadStoredCertificate(dir, gdsBootstrapCertFile, gdsBootstrapKeyFile)
+	if err != nil {
+		return err
+	}
+	cert, key, err := loadStoredCertificate(dir, gdsCertFile, gdsKeyFile)
+	if err != nil {
+		return err
+	}
+
+	var trusted []*x509.Certificate
+	if b, err := os.ReadFile(filepath.Join(dir, gdsTrustListFile)); err == nil {
+		trustList := new(ua.TrustListDataType)
+		if _, err := ua.Decode(b, trustList); err != nil {
+			return fmt.Errorf("failed to decode stored trust list: %w", err)
+		}
+		if trusted, err = parseTrustList(trustList); err != nil {
+			return err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read stored trust list: %w", err)
+	}
+
+	applicationID, err := os.ReadFile(filepath.Join(dir, gdsApplicationIDFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read stored application id: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bootstrapCert, p.bootstrapKey = bootstrapCert, bootstrapKey
+	p.cert, p.key = cert, key
+	p.trusted = trusted
+	p.applicationID = strings.TrimSpace(string(applicationID))
+	return nil
+}
+
+// ensureBootstrap creates the self-signed certificate the first session with
+// the GDS is opened with, unless it exists. The GDS must trust it, or accept
+// it together with the user credentials of gds.auth.
+func (p *gdsProvider) ensureBootstrap() error {
+	p.mu.Lock()
+	exists := p.bootstrapCert != nil
+	p.mu.Unlock()
+	if exists {
+		return nil
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, gdsKeySize)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	now := p.now()
+	cert, err := selfSignedCertificate(key, p.identity.applicationName, p.config.ApplicationURI, localHosts(), now.Add(-time.Hour), now.Add(gdsBootstrapValidity))
+	if err != nil {
+		return err
+	}
+	dir := p.config.Directory
+	if err := writeKeyFile(filepath.Join(dir, gdsBootstrapKeyFile), key); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, gdsBootstrapCertFile), cert); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.bootstrapCert, p.bootstrapKey = cert, key
+	p.mu.Unlock()
+	return nil
+}
+
+// parseTrustList returns the trusted certificates of a trust list
+func parseTrustList(trustList *ua.TrustListDataType) ([]*x509.Certificate, error) {
+	trusted := make([]*x509.Certificate, 0, len(trustList.TrustedCertificates))
+	for i, der := range trustList.TrustedCertificates {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted certificate %d in trust list: %w", i, err)
+		}
+		trusted = append(trusted, cert)
+	}
+	return trusted, nil
+}
+
+// loadStoredCertificate loads a certificate and key of the directory, or
+// nothing if either file does not exist
+func loadStoredCertificate(dir, certFile, keyFile string) ([]byte, *rsa.PrivateKey, error) {
+	certPath, keyPath := filepath.Join(dir, certFile), filepath.Join(dir, keyFile)
+	for _, path := range []string{certPath, keyPath} {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil, nil
+		}
+	}
+	return loadCertificateFiles(certPath, keyPath)
+}
+
+// writeKeyFile writes an RSA private key as PEM, readable by the owner only
+func writeKeyFile(path string, key *rsa.PrivateKey) error {
+	return writeFileAtomic(path, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+// writeFileAtomic replaces a credential file, so a crash never leaves a
+// partly written credential behind
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// gdsSession is a session with the GDS
+type gdsSession struct {
+	client     *opcua.Client
+	logger     *zap.Logger
+	namespaces []string
+	directory  *ua.NodeID
+	methods    map[string]*ua.NodeID
+}
+
+// openGDSSession connects to the GDS with the application certificate. The
+// most secure SignAndEncrypt endpoint is used, and its certificate must be
+// in the trust list once one was pulled.
+func openGDSSession(ctx context.Context, config *Config, identity clientIdentity, logger *zap.Logger, cert []byte, key *rsa.PrivateKey, trusted []*x509.Certificate) (*gdsSession, error) {
+	gds := config.GDS
+	endpoints, err := opcua.GetEndpoints(ctx, gds.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get endpoints: %w", err)
+	}
+	tokenType := gds.Auth.tokenType()
+	var ep *ua.EndpointDescription
+	for _, candidate := range endpoints {
+		if candidate.SecurityMode != ua.MessageSecurityModeSignAndEncrypt {
+			continue
+		}
+		if tokenType != ua.UserTokenTypeAnonymous && !offersUserToken(candidate, tokenType) {
+			continue
+		}
+		if ep == nil || candidate.SecurityLevel > ep.SecurityLevel {
+			ep = candidate
+		}
+	}
+	if ep == nil {
+		return nil, fmt.Errorf("GDS offers no SignAndEncrypt endpoint accepting %s user tokens", tokenType)
+	}
+	if len(trusted) > 0 {
+		if err := verifyTrusted(ep.ServerCertificate, trusted); err != nil {
+			return nil, fmt.Errorf("GDS certificate: %w", err)
+		}
+	}
+
+	opts := []opcua.Option{
+		opcua.SecurityFromEndpoint(ep, tokenType),
+		opcua.Certificate(cert),
+		opcua.PrivateKey(key),
+		opcua.ApplicationURI(gds.ApplicationURI),
+		opcua.ApplicationName(identity.applicationName),
+		opcua.ProductURI(identity.productURI),
+		opcua.SessionName(identity.sessionName),
+		opcua.RequestTimeout(config.RequestTimeout),
+		opcua.AutoReconnect(false),
+	}
+	switch tokenType {
+	case ua.UserTokenTypeUserName:
+		opts = append(opts, opcua.AuthUsername(gds.Auth.Username, string(gds.Auth.Password)))
+	case ua.UserTokenTypeCertificate:
+		opts = append(opts, opcua.AuthCertificate(cert), opcua.AuthPrivateKey(key))
+	default:
+		opts = append(opts, opcua.AuthAnonymous())
+	}
+
+	client, err := opcua.NewClient(gds.Endpoint, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GDS client: %w", err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+
+	s := &gdsSession{client: client, logger: logger, namespaces: client.Namespaces()}
+	if s.directory, err = resolveNodeID(gdsDirectoryID, s.namespaces); err != nil {
+		_ = client.Close(ctx)
+		return nil, fmt.Errorf("server is not a GDS: %w", err)
+	}
+	if s.methods, err = s.browseMethods(ctx, s.directory); err != nil {
+		_ = client.Close(ctx)
+		return nil, fmt.Errorf("failed to browse GDS directory: %w", err)
+	}
+	return s, nil
+}
+
+func (s *gdsSession) close(ctx context.Context) error {
+	return s.client.Close(ctx)
+}
+
+// browseMethods returns the methods of an object by browse name. The
+// method NodeIDs of the Directory object are not standardized.
+func (s *gdsSession) browseMethods(ctx context.Context, objectID *ua.NodeID) (map[string]*ua.NodeID, error) {
+	resp, err := s.client.Browse(ctx, &ua.BrowseRequest{
+		NodesToBrowse: []*ua.BrowseDescription{{
+			NodeID:          objectID,
+			BrowseDirection: ua.BrowseDirectionForward,
+			ReferenceTypeID: ua.NewNumericNodeID(0, 47), // HasComponent
+			IncludeSubtypes: true,
+			NodeClassMask:   uint32(ua.NodeClassMethod),
+			ResultMask:      uint32(ua.BrowseResultMaskBrowseName),
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) == 0 {
+		return nil, errors.New("browse returned no results")
+	}
+	if status := resp.Results[0].StatusCode; status != ua.StatusOK {
+		return nil, status
+	}
+	methods := make(map[string]*ua.NodeID, len(resp.Results[0].References))
+	for _, ref := range resp.Results[0].References {
+		methods[ref.BrowseName.Name] = ref.NodeID.NodeID
+	}
+	return methods, nil
+}
+
+// call calls a method of objectID found by browseMethods
+func (s *gdsSession) call(ctx context.Context, objectID *ua.NodeID, methods map[string]*ua.NodeID, name string, args ...interface{}) ([]*ua.Variant, error) {
+	methodID, ok := methods[name]
+	if !ok {
+		return nil, fmt.Errorf("method %s not found", name)
+	}
+	inputs := make([]*ua.Variant, len(args))
+	for i, arg := range args {
+		v, err := ua.NewVariant(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %d of %s: %w", i, name, err)
+		}
+		inputs[i] = v
+	}
+	res, err := s.client.Call(ctx, &ua.CallMethodRequest{
+		ObjectID:       objectID,
+		MethodID:       methodID,
+		InputArguments: inputs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", name, err)
+	}
+	if res.StatusCode != ua.StatusOK {
+		return nil, fmt.Errorf("%s failed: %w", name, res.StatusCode)
+	}
+	return res.OutputArguments, nil
+}
+
+func (s *gdsSession) registerApplication(ctx context.Context, record *applicationRecord) (*ua.NodeID, error) {
+	ns := s.gdsNamespace()
+	if ns < 0 {
+		return nil, errors.New("server has no GDS namespace")
+	}
+	out, err := s.call(ctx, s.directory, s.methods, "RegisterApplication", &ua.ExtensionObject{
+		EncodingMask: ua.ExtensionObjectBinary,
+		TypeID:       ua.NewNumericExpandedNodeID(uint16(ns), gdsApplicationRecordEncodingID), //nolint:gosec
+		Value:        record,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return outputNodeID(out, 0)
+}
+
+// gdsNamespace returns the index of the GDS namespace, or -1
+func (s *gdsSession) gdsNamespace() int {
+	for i, uri := range s.namespaces {
+		if uri == gdsNamespaceURI {
+			return i
+		}
+	}
+	return -1
+}
+
+func (s *gdsSession) applicationID(id *ua.NodeID) string {
+	ns := int(id.Namespace())
+	if ns == 0 || ns >= len(s.namespaces) {
+		return id.String()
+	}
+	_, identifier, _ := strings.Cut(id.String(), ";")
+	return namespaceURIPrefix + s.namespaces[ns] + ";" + identifier
+}
+
+func (s *gdsSession) resolveApplicationID(id string) (*ua.NodeID, error) {
+	return resolveNodeID(id, s.namespaces)
+}
+
+func (s *gdsSession) startSigningRequest(ctx context.Context, applicationID *ua.NodeID, csr []byte) (*ua.NodeID, error) {
+	// Null CertificateGroupId and CertificateTypeId request the default
+	// application group and RsaSha256ApplicationCertificateType
+	out, err := s.call(ctx, s.directory, s.methods, "StartSigningRequest",
+		applicationID, ua.NewNumericNodeID(0, 0), ua.NewNumericNodeID(0, 0), csr)
+	if err != nil {
+		return nil, err
+	}
+	return outputNodeID(out, 0)
+}
+
+// finishRequest returns the issued certificate. The private key output is
+// empty for a signing request and the issuer certificates are not used, as
+// servers verify the certificate against their own trust list.
+func (s *gdsSession) finishRequest(ctx context.Context, applicationID, requestID *ua.NodeID) ([]byte, error) {
+	out, err := s.call(ctx, s.directory, s.methods, "FinishRequest", applicationID, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("FinishRequest returned no certificate")
+	}
+	cert, ok := out[0].Value().([]byte)
+	if !ok || len(cert) == 0 {
+		return nil, errors.New("FinishRequest returned no certificate")
+	}
+	return cert, nil
+}
+
+// trustList reads the TrustList file object the GDS returns for the application
+func (s *gdsSession) trustList(ctx context.Context, applicationID *ua.NodeID) (*ua.TrustListDataType, error) {
+	out, err := s.call(ctx, s.directory, s.methods, "GetTrustList", applicationID, ua.NewNumericNodeID(0, 0))
+	if err != nil {
+		return nil, err
+	}
+	trustListID, err := outputNodeID(out, 0)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := s.browseMethods(ctx, trustListID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to browse trust list: %w", err)
+	}
+
+	out, err = s.call(ctx, trustListID, methods, "Open", byte(fileOpenModeRead))
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, errors.New("Open returned no file handle")
+	}
+	handle, ok := out[0].Value().(uint32)
+	if !ok {
+		return nil, fmt.Errorf("Open returned a %T file handle", out[0].Value())
+	}
+	defer func() {
+		if _, err := s.call(ctx, trustListID, methods, "Close", handle); err != nil {
+			s.logger.Debug("Failed to close trust list", zap.Error(err))
+		}
+	}()
+
+	var content []byte
+	for {
+		out, err := s.call(ctx, trustListID, methods, "Read", handle, int32(gdsFileReadSize))
+		if err != nil {
+			return nil, err
+		}
+		var chunk []byte
+		if len(out) > 0 {
+			chunk, _ = out[0].Value().([]byte)
+		}
+		content = append(content, chunk...)
+		if len(chunk) < gdsFileReadSize {
+			break
+		}
+	}
+
+	trustList := new(ua.TrustListDataType)
+	if _, err := ua.Decode(content, trustList); err != nil {
+		return nil, fmt.Errorf("invalid trust list: %w", err)
+	}
+	return trustList, nil
+}
+
+// outputNodeID returns output argument i as a NodeID
+func outputNodeID(out []*ua.Variant, i int) (*ua.NodeID, error) {
+	if i >= len(out) {
+		return nil, fmt.Errorf("missing output argument %d", i)
+	}
+	id, ok := out[i].Value().(*ua.NodeID)
+	if !ok {
+		return nil, fmt.Errorf("output argument %d is a %T, not a NodeID", i, out[i].Value())
+	}
+	return id, nil
+}
@@ -0,0 +1,310 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+)
+
+func TestGDSConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "disabled",
+			modify: func(*Config) {},
+		},
+		{
+			name:   "enabled",
+			modify: func(*Config) {},
+		},
+		{
+			name: "username",
+			modify: func(cfg *Config) {
+				cfg.GDS.Auth = AuthConfig{Type: "username_password", Username: "enroll", Password: "secret"}
+			},
+		},
+		{
+			name: "certificate user token",
+			modify: func(cfg *Config) {
+				cfg.Auth.Type = "certificate"
+				cfg.SecurityMode = "SignAndEncrypt"
+				cfg.SecurityPolicy = "Basic256Sha256"
+			},
+		},
+		{
+			name:    "endpoint scheme",
+			modify:  func(cfg *Config) { cfg.GDS.Endpoint = "http://gds:58810" },
+			wantErr: "endpoint must start with opc.tcp://",
+		},
+		{
+			name:    "password missing",
+			modify:  func(cfg *Config) { cfg.GDS.Auth = AuthConfig{Type: "username_password", Username: "enroll"} },
+			wantErr: "username and password are required",
+		},
+		{
+			name:    "no application URI",
+			modify:  func(cfg *Config) { cfg.GDS.ApplicationURI = "" },
+			wantErr: "gds application_uri must be specified",
+		},
+		{
+			name:    "no directory",
+			modify:  func(cfg *Config) { cfg.GDS.Directory = "" },
+			wantErr: "gds directory must be specified",
+		},
+		{
+			name:    "short check interval",
+			modify:  func(cfg *Config) { cfg.GDS.CheckInterval = time.Second },
+			wantErr: "check_interval must be at least 1 minute",
+		},
+		{
+			name: "tls certificate",
+			modify: func(cfg *Config) {
+				cfg.TLS.CertFile = "client.der"
+				cfg.TLS.KeyFile = "client.pem"
+			},
+			wantErr: "gds and the application certificate of tls are mutually exclusive",
+		},
+		{
+			name: "certificate provider",
+			modify: func(cfg *Config) {
+				id := component.MustNewID("opcua_pki")
+				cfg.TLS.CertificateProvider = &id
+			},
+			wantErr: "gds and the application certificate of tls are mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			if tt.name != "disabled" {
+				cfg.GDS.Endpoint = "opc.tcp://gds:58810"
+				cfg.GDS.ApplicationURI = "urn:collector-1:otelcol-opcua"
+				cfg.GDS.Directory = "/var/lib/otelcol/gds"
+			}
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// fakeGDS is a GDS directory whose CA signs every request
+type fakeGDS struct {
+	t      *testing.T
+	ca     *x509.Certificate
+	caKey  *rsa.PrivateKey
+	now    time.Time
+	serial int64
+
+	// pendingFinishes is the number of FinishRequest calls answered as pending
+	pendingFinishes int
+
+	registrations int
+	requests      int
+	trusted       *ua.TrustListDataType
+	csr           *x509.CertificateRequest
+}
+
+func newFakeGDS(t *testing.T, now time.Time) *fakeGDS {
+	ca, caKey := newWireServerCertificate(t)
+	return &fakeGDS{
+		t:     t,
+		ca:    ca,
+		caKey: caKey,
+		now:   now,
+		trusted: &ua.TrustListDataType{
+			SpecifiedLists:      15,
+			TrustedCertificates: [][]byte{ca.Raw},
+		},
+	}
+}
+
+func (g *fakeGDS) registerApplication(_ context.Context, record *applicationRecord) (*ua.NodeID, error) {
+	g.registrations++
+	assert.Equal(g.t, ua.ApplicationTypeClient, record.ApplicationType)
+	return ua.NewStringNodeID(2, record.ApplicationURI), nil
+}
+
+func (g *fakeGDS) applicationID(id *ua.NodeID) string {
+	return id.String()
+}
+
+func (g *fakeGDS) resolveApplicationID(s string) (*ua.NodeID, error) {
+	return ua.ParseNodeID(s)
+}
+
+func (g *fakeGDS) startSigningRequest(_ context.Context, _ *ua.NodeID, csr []byte) (*ua.NodeID, error) {
+	g.requests++
+	request, err := x509.ParseCertificateRequest(csr)
+	require.NoError(g.t, err)
+	require.NoError(g.t, request.CheckSignature())
+	g.csr = request
+	return ua.NewNumericNodeID(2, uint32(g.requests)), nil //nolint:gosec
+}
+
+func (g *fakeGDS) finishRequest(_ context.Context, _, _ *ua.NodeID) ([]byte, error) {
+	if g.pendingFinishes > 0 {
+		g.pendingFinishes--
+		return nil, ua.StatusBadNothingToDo
+	}
+	g.serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(g.serial),
+		Subject:      g.csr.Subject,
+		URIs:         g.csr.URIs,
+		NotBefore:    g.now.Add(-time.Hour),
+		NotAfter:     g.now.Add(90 * 24 * time.Hour),
+	}
+	return x509.CreateCertificate(rand.Reader, template, g.ca, g.csr.PublicKey, g.caKey)
+}
+
+func (g *fakeGDS) trustList(context.Context, *ua.NodeID) (*ua.TrustListDataType, error) {
+	return g.trusted, nil
+}
+
+func (g *fakeGDS) close(context.Context) error {
+	return nil
+}
+
+func newTestGDSProvider(t *testing.T, dir string, gds *fakeGDS) *gdsProvider {
+	cfg := createDefaultConfig().(*Config)
+	cfg.GDS.Endpoint = "opc.tcp://gds:58810"
+	cfg.GDS.ApplicationURI = "urn:collector-1:otelcol-opcua"
+	cfg.GDS.Directory = dir
+	p := newGDSProvider(cfg, newClientIdentity(cfg.ClientIdentity, ""), zap.NewNop())
+	p.now = func() time.Time { return gds.now }
+	p.connect = func(context.Context, []byte, *rsa.PrivateKey) (gdsDirectory, error) {
+		return gds, nil
+	}
+	t.Cleanup(func() {
+		assert.NoError(t, p.Shutdown(context.Background()))
+	})
+	return p
+}
+
+func TestGDSProviderEnroll(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "gds")
+	gds := newFakeGDS(t, time.Now())
+
+	p := newTestGDSProvider(t, dir, gds)
+	require.NoError(t, p.Start(ctx, componenttest.NewNopHost()))
+	assert.Equal(t, 1, gds.registrations)
+	assert.Equal(t, 1, gds.requests)
+
+	// The request names the application by its URI
+	require.Len(t, gds.csr.URIs, 1)
+	assert.Equal(t, "urn:collector-1:otelcol-opcua", gds.csr.URIs[0].String())
+
+	der, key, err := p.ApplicationCertificate(ctx)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	assert.Equal(t, gds.ca.Subject.String(), cert.Issuer.String())
+	assert.Equal(t, key.Public(), cert.PublicKey)
+
+	trusted, err := p.TrustedCertificates(ctx)
+	require.NoError(t, err)
+	require.Len(t, trusted, 1)
+	assert.True(t, gds.ca.Equal(trusted[0]))
+
+	for _, name := range []string{gdsBootstrapCertFile, gdsBootstrapKeyFile, gdsCertFile, gdsKeyFile, gdsTrustListFile, gdsApplicationIDFile} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		require.NoError(t, err, name)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), name)
+	}
+
+	// A restart uses the stored credentials without enrolling again
+	restarted := newTestGDSProvider(t, dir, gds)
+	require.NoError(t, restarted.Start(ctx, componenttest.NewNopHost()))
+	assert.Equal(t, 1, gds.registrations)
+	assert.Equal(t, 1, gds.requests)
+	stored, _, err := restarted.ApplicationCertificate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, der, stored)
+}
+
+func TestGDSProviderPendingRequest(t *testing.T) {
+	ctx := context.Background()
+	gds := newFakeGDS(t, time.Now())
+	gds.pendingFinishes = 1
+
+	p := newTestGDSProvider(t, t.TempDir(), gds)
+	require.NoError(t, p.Start(ctx, componenttest.NewNopHost()))
+
+	// Until the request is approved, the self-signed certificate is used
+	der, _, err := p.ApplicationCertificate(ctx)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	assert.Equal(t, cert.Subject.String(), cert.Issuer.String())
+
+	// The next check finishes the same request
+	assert.True(t, p.check(ctx))
+	assert.Equal(t, 1, gds.requests)
+	der, _, err = p.ApplicationCertificate(ctx)
+	require.NoError(t, err)
+	cert, err = x509.ParseCertificate(der)
+	require.NoError(t, err)
+	assert.Equal(t, gds.ca.Subject.String(), cert.Issuer.String())
+}
+
+func TestGDSProviderRenewal(t *testing.T) {
+	ctx := context.Background()
+	gds := newFakeGDS(t, time.Now())
+
+	p := newTestGDSProvider(t, t.TempDir(), gds)
+	require.NoError(t, p.Start(ctx, componenttest.NewNopHost()))
+	first, firstKey, err := p.ApplicationCertificate(ctx)
+	require.NoError(t, err)
+
+	// Nothing changes before renew_before
+	gds.now = gds.now.Add(30 * 24 * time.Hour)
+	assert.False(t, p.check(ctx))
+	assert.Equal(t, 1, gds.requests)
+
+	// Within renew_before of the expiry, a certificate for a new key is requested
+	gds.now = gds.now.Add(31 * 24 * time.Hour)
+	assert.True(t, p.check(ctx))
+	assert.Equal(t, 2, gds.requests)
+	renewed, renewedKey, err := p.ApplicationCertificate(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, renewed)
+	assert.NotEqual(t, firstKey.N, renewedKey.N)
+
+	// An updated trust list is taken over as well
+	other, _ := newWireServerCertificate(t)
+	gds.trusted = &ua.TrustListDataType{
+		SpecifiedLists:      15,
+		TrustedCertificates: [][]byte{gds.ca.Raw, other.Raw},
+	}
+	assert.True(t, p.check(ctx))
+	trusted, err := p.TrustedCertificates(ctx)
+	require.NoError(t, err)
+	assert.Len(t, trusted, 2)
+}
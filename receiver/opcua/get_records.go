@@ -5,16 +5,153 @@ package opcua
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"time"
 
 	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
-	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
 )
 
-// callGetRecordsMethod invokes the OPC UA Part 26 GetRecords method on a LogObject
+// LogRecordMask bits of the optional LogRecord fields, OPC UA Part 26 §5.3
+const (
+	logRecordMaskEventType uint32 = 1 << iota
+	logRecordMaskSourceNode
+	logRecordMaskSourceName
+	logRecordMaskTraceContext
+	logRecordMaskAdditionalData
+)
+
+// queryResumer is implemented by clients that keep the continuation points
+// of a GetRecords query the record budget ran out in, so the next call with
+// the same window reads the records left instead of dropping them
+type queryResumer interface {
+	// unfinishedQuery reports whether the last GetRecords call left records
+	// to be read by calling it again with the same window
+	unfinishedQuery() bool
+}
+
+// readPosition is how far a LogObject was read in a collection window. It
+// lets a read continue without a continuation point, such as after a
+// restart: the read starts at Start and drops the first Skip records
+// stamped at Start, as the server returns the records of a LogObject in the
+// order of their time.
+type readPosition struct {
+	// Start is the time of the last record read
+	Start time.Time `json:"start"`
+	// Skip is the number of records stamped at Start read already
+	Skip int `json:"skip,omitempty"`
+	// Done is set once the LogObject was read up to the window end
+	Done bool `json:"done,omitempty"`
+}
+
+// advance moves p past a record stamped at ts. A record older than p, which
+// a server sorting its records would not return, leaves p.
+func (p readPosition) advance(ts time.Time) readPosition {
+	switch {
+	case ts.After(p.Start):
+		return readPosition{Start: ts, Skip: 1}
+	case ts.Equal(p.Start):
+		p.Skip++
+	}
+	return p
+}
+
+// positionReader is implemented by clients that can read each LogObject
+// from a position of its own instead of the window start
+type positionReader interface {
+	// setReadPositions sets the positions, by LogObject, the next GetRecords
+	// call reads from unless it resumes carried continuation points. A
+	// LogObject whose position is done is not read.
+	setReadPositions(positions map[string]readPosition)
+}
+
+// setReadPositions implements positionReader
+func (c *opcuaClient) setReadPositions(positions map[string]readPosition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.positions = positions
+}
+
+// skipRead drops the records of a page that a read from a position with skip
+// records left to skip read before, and returns the page and the records
+// still to skip. A record stamped after start ends skipping.
+func skipRead(records []model.LogRecord, start time.Time, skip int) ([]model.LogRecord, int) {
+	n := 0
+	for n < skip && n < len(records) && records[n].Timestamp.Equal(start) {
+		putAttributeMap(records[n].Attributes)
+		n++
+	}
+	if n < len(records) {
+		skip = n
+	}
+	kept := copy(records, records[n:])
+	clear(records[kept:])
+	return records[:kept], skip - n
+}
+
+// carriedQuery is a GetRecords query the record budget ran out in
+type carriedQuery struct {
+	startTime time.Time
+	endTime   time.Time
+	// continuationPoints of the LogObjects with records left, by LogObject;
+	// nil for a LogObject whose read was not started
+	continuationPoints map[string][]byte
+}
+
+// resumes reports whether a query of the window from startTime to endTime
+// continues q
+func (q *carriedQuery) resumes(startTime, endTime time.Time) bool {
+	return q != nil && q.startTime.Equal(startTime) && q.endTime.Equal(endTime)
+}
+
+// carryOver keeps the continuation points of the LogObjects a GetRecords
+// call stopped in, and the LogObjects it did not start, for the next call
+// with the same window
+func (c *opcuaClient) carryOver(startTime, endTime time.Time, logObjectIDs []*ua.NodeID, pending [][]byte, unstarted []bool) {
+	continuationPoints := make(map[string][]byte)
+	for i, next := range pending {
+		if len(next) > 0 || unstarted[i] {
+			continuationPoints[logObjectIDs[i].String()] = next
+		}
+	}
+	if len(continuationPoints) == 0 {
+		return
+	}
+	c.logger.Debug("Record budget spent, resuming LogObjects with the next scrape",
+		zap.Time("start_time", startTime),
+		zap.Time("end_time", endTime),
+		zap.Int("log_objects", len(continuationPoints)))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.carried = &carriedQuery{startTime: startTime, endTime: endTime, continuationPoints: continuationPoints}
+}
+
+// unfinishedQuery reports whether continuation points were carried over
+func (c *opcuaClient) unfinishedQuery() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.carried != nil
+}
+
+// errContinuationPointInvalid is wrapped in the methodCallError callGetRecordsMethod
+// returns when the server rejects a continuation point as unknown, expired or
+// already used
+var errContinuationPointInvalid = errors.New("continuation point invalid")
+
+// callGetRecordsMethod invokes the OPC UA Part 26 GetRecords method on a
+// LogObject once and returns one page of records and the continuation point
+// for the next page. A rejected continuation point is returned as
+// errContinuationPointInvalid; readLogObject decides whether to restart.
 func (c *opcuaClient) callGetRecordsMethod(
 	ctx context.Context,
 	logObjectID *ua.NodeID,
@@ -22,23 +159,24 @@ func (c *opcuaClient) callGetRecordsMethod(
 	maxRecords uint32,
 	minSeverity uint16,
 	continuationPoint []byte,
-) ([]testdata.OPCUALogRecord, []byte, error) {
+) ([]model.LogRecord, []byte, error) {
 
 	// Find the GetRecords method NodeID by browsing the LogObject's children.
 	getRecordsMethodID, err := c.findGetRecordsMethod(ctx, logObjectID)
 	if err != nil {
-		c.logger.Warn("Could not discover GetRecords method via browsing, using standard ID ns=0;i=11550",
+		c.warnings.Warn(c.logger, "find_method/"+logObjectID.String(),
+			"Could not discover GetRecords method via browsing, using standard ID ns=0;i=11550",
 			zap.String("log_object_id", logObjectID.String()),
 			zap.Error(err))
 		getRecordsMethodID = ua.NewNumericNodeID(0, 11550)
 	} else {
+		c.warnings.Clear(c.logger, "find_method/"+logObjectID.String())
 		c.logger.Debug("Using discovered GetRecords method",
 			zap.String("method_id", getRecordsMethodID.String()))
 	}
 
-	// Build LogRecordMask - request all optional fields
-	// Bit 0: EventType, Bit 1: SourceNode, Bit 2: SourceName, Bit 3: TraceContext, Bit 4: AdditionalData
-	logRecordMask := uint32(0x1F) // All bits set (0b11111)
+	// Request only the optional fields selected in record_fields
+	logRecordMask := c.config.RecordFields.mask()
 
 	// Build input arguments according to OPC UA Part 26 §5.3
 	inputArgs := []*ua.Variant{
@@ -63,36 +201,48 @@ func (c *opcuaClient) callGetRecordsMethod(
 		zap.Time("end_time", endTime),
 		zap.Uint32("max_records", maxRecords),
 		zap.Uint16("min_severity", minSeverity),
+		zap.Uint32("record_mask", logRecordMask),
 		zap.Bool("has_continuation_point", len(continuationPoint) > 0))
 
-	// Execute the Call service
-	result, err := c.client.Call(ctx, req)
+	// Execute the Call service on the session current at call time
+	session, err := c.session()
 	if err != nil {
-		return nil, nil, fmt.Errorf("Call service failed: %w", err)
+		return nil, nil, err
+	}
+	start := time.Now()
+	result, err := session.Call(ctx, req)
+	if c.telemetry != nil {
+		c.telemetry.OpcuaCallDuration.Record(ctx, time.Since(start).Seconds(),
+			metric.WithAttributes(attribute.String("opcua.log_object", logObjectID.String())))
 	}
+	c.captureCall(req, result, err)
+	if err != nil {
+		err = fmt.Errorf("Call service failed: %w", err)
+		if isConnectionError(err) {
+			return nil, nil, newConnectionError(c.config.Endpoint, err)
+		}
+		return nil, nil, newMethodCallError(logObjectID, getRecordsMethodID, err)
+	}
+	c.dumpResponse(req, result)
 
 	// Check for method call errors
 	if result.StatusCode != ua.StatusOK {
 		// Check for specific error codes
 		switch result.StatusCode {
 		case ua.StatusBadInvalidArgument:
-			return nil, nil, fmt.Errorf("invalid argument: EndTime < StartTime or invalid severity range")
+			err = fmt.Errorf("invalid argument: EndTime < StartTime or invalid severity range: %w", result.StatusCode)
 		case ua.StatusBadContinuationPointInvalid:
-			c.logger.Warn("Continuation point invalid, restarting query without continuation point")
-			// Retry without continuation point
-			if len(continuationPoint) > 0 {
-				return c.callGetRecordsMethod(ctx, logObjectID, startTime, endTime, maxRecords, minSeverity, nil)
-			}
-			return nil, nil, fmt.Errorf("continuation point invalid")
+			err = fmt.Errorf("%w: %w", errContinuationPointInvalid, result.StatusCode)
 		default:
-			return nil, nil, fmt.Errorf("GetRecords method call failed with status: %v", result.StatusCode)
+			err = fmt.Errorf("GetRecords method call failed with status: %w", result.StatusCode)
 		}
+		return nil, nil, newMethodCallError(logObjectID, getRecordsMethodID, err)
 	}
 
 	// Parse output arguments
 	// Expected: [0] = LogRecordsDataTypeResults, [1] = ContinuationPointOut
 	if len(result.OutputArguments) < 2 {
-		return nil, nil, fmt.Errorf("unexpected number of output arguments: %d (expected 2)", len(result.OutputArguments))
+		return nil, nil, newDecodeError("", fmt.Errorf("unexpected number of output arguments: %d (expected 2)", len(result.OutputArguments)))
 	}
 
 	// Parse LogRecords array from first output argument
@@ -116,10 +266,257 @@ func (c *opcuaClient) callGetRecordsMethod(
 	return logRecords, nextContinuationPoint, nil
 }
 
+// logObjectRead is the outcome of reading one LogObject
+type logObjectRead struct {
+	logObjectID string
+	records     int
+	pages       int
+	// err is set if the LogObject was skipped because its read failed
+	err error
+	// finished is set once the read reached the end of the window
+	finished bool
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler
+func (r logObjectRead) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("node_id", r.logObjectID)
+	enc.AddInt("records", r.records)
+	enc.AddInt("pages", r.pages)
+	if r.err != nil {
+		enc.AddString("error_class", errorClass(r.err))
+		enc.AddString("error", r.err.Error())
+	}
+	return nil
+}
+
+// reportLogObjectReads passes the outcome of the LogObject reads to
+// onLogObjectRead, leaving out LogObjects that were not read
+func (c *opcuaClient) reportLogObjectReads(reads []logObjectRead) {
+	if c.onLogObjectRead == nil {
+		return
+	}
+	for _, read := range reads {
+		if read.logObjectID != "" {
+			c.onLogObjectRead(read)
+		}
+	}
+}
+
+// maxPaginationRestarts bounds how often a read of one LogObject restarts
+// after the server rejected its continuation point
+const maxPaginationRestarts = 3
+
+// paginationRestartBackoff is the wait before the first restart after a
+// rejected continuation point; it doubles with every further restart
+const paginationRestartBackoff = 100 * time.Millisecond
+
+// waitPaginationRestart waits the backoff before the given restart, counted
+// from 1, and returns early with the context's error when it is done
+func waitPaginationRestart(ctx context.Context, restart int) error {
+	timer := time.NewTimer(paginationRestartBackoff << (restart - 1))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// readLogObject reads up to maxRecords records of a LogObject, following
+// continuation points, and adds the pages and records read to read. It
+// returns the continuation point to resume from if it stopped at maxRecords.
+//
+// A failed first call skips the LogObject. A failure in the middle of the
+// pagination or a lost connection fails the read instead: returning the pages
+// read so far would let the scraper move its window past the records that
+// were not read yet. They are read again on the next collection. A rejected
+// continuation point restarts the read and discards the pages read so far, so
+// no record is returned twice. It restarts at most maxPaginationRestarts times
+// with a growing backoff, so a server that keeps rejecting continuation points
+// fails the read instead of being retried forever.
+//
+// A read resumed from continuationPoint cannot restart without returning
+// records twice. If its continuation point is rejected, it stops with the
+// records read so far, like a read that reached maxRecords.
+//
+// If emit is set, every page is passed to it instead of being returned, and
+// a read that passed pages on stops like a resumed one.
+//
+// A read without continuation point starts at the position from, dropping
+// the records it skips.
+func (c *opcuaClient) readLogObject(
+	ctx context.Context,
+	read *logObjectRead,
+	logObjectID *ua.NodeID,
+	from readPosition,
+	endTime time.Time,
+	maxRecords int,
+	minSeverity uint16,
+	continuationPoint []byte,
+	emit func([]model.LogRecord),
+) ([]model.LogRecord, []byte, error) {
+	nodeRecords := getRecordSlice()
+	resumed := len(continuationPoint) > 0
+	restarts := 0
+	pages := 0
+	emitted := 0
+	skip := 0
+	if !resumed {
+		skip = from.Skip
+	}
+
+	for {
+		pageSize := c.pageSize(logObjectID.String(), maxRecords-len(nodeRecords)-emitted)
+		records, nextContinuationPoint, err := c.callGetRecordsMethod(
+			ctx,
+			logObjectID,
+			from.Start,
+			endTime,
+			pageSize,
+			minSeverity,
+			continuationPoint,
+		)
+
+		switch {
+		case err == nil:
+		case isResponseTooLarge(err) && pageSize > 1:
+			c.logger.Warn("GetRecords response too large, halving MaxReturnRecords for LogObject",
+				zap.String("node_id", logObjectID.String()),
+				zap.Uint32("max_return_records", pageSize),
+				zap.Uint32("retry_with", pageSize/2))
+			c.limitPageSize(logObjectID.String(), pageSize/2)
+			continue
+		case errors.Is(err, errContinuationPointInvalid) && (resumed || emitted > 0):
+			c.logger.Warn("Continuation point invalid, stopping resumed read of LogObject",
+				zap.String("node_id", logObjectID.String()),
+				zap.Int("records", len(nodeRecords)+emitted))
+			read.records += len(nodeRecords) + emitted
+			read.pages += pages
+			read.finished = true
+			return nodeRecords, nil, nil
+		case errors.Is(err, errContinuationPointInvalid) && len(continuationPoint) > 0 && restarts < maxPaginationRestarts:
+			restarts++
+			c.logger.Warn("Continuation point invalid, restarting read of LogObject",
+				zap.String("node_id", logObjectID.String()),
+				zap.Int("restart", restarts),
+				zap.Int("discarded_records", len(nodeRecords)))
+			if err := waitPaginationRestart(ctx, restarts); err != nil {
+				releaseRecords(nodeRecords)
+				return nil, nil, err
+			}
+			releaseRecords(nodeRecords)
+			nodeRecords = getRecordSlice()
+			continuationPoint = nil
+			skip = from.Skip
+			continue
+		case len(continuationPoint) > 0 || isConnectionError(err) || !c.IsConnected():
+			err = fmt.Errorf("reading LogObject %s interrupted after %d records: %w", logObjectID, len(nodeRecords)+emitted, err)
+			releaseRecords(nodeRecords)
+			return nil, nil, err
+		default:
+			c.warnings.Warn(c.logger, "get_records/"+logObjectID.String(), "Failed to call GetRecords method on LogObject",
+				zap.String("node_id", logObjectID.String()),
+				zap.String("error_class", errorClass(err)),
+				zap.String("status_code", failureStatus(err)),
+				zap.Error(err))
+			read.pages += pages
+			read.err = err
+			putRecordSlice(nodeRecords)
+			return nil, nil, nil
+		}
+		c.warnings.Clear(c.logger, "get_records/"+logObjectID.String())
+		pages++
+
+		if skip > 0 {
+			records, skip = skipRead(records, from.Start, skip)
+		}
+		for i := range records {
+			records[i].LogObjectID = logObjectID.String()
+		}
+		if emit != nil {
+			emitted += len(records)
+			emit(records)
+		} else {
+			nodeRecords = append(nodeRecords, records...)
+			putRecordSlice(records)
+		}
+
+		// Check if we have more records via continuation point
+		if len(nextContinuationPoint) == 0 || len(nodeRecords)+emitted >= maxRecords {
+			read.records += len(nodeRecords) + emitted
+			read.pages += pages
+			read.finished = len(nextContinuationPoint) == 0
+			return nodeRecords, nextContinuationPoint, nil
+		}
+		continuationPoint = nextContinuationPoint
+	}
+}
+
+// pageSize returns the MaxReturnRecords of the next GetRecords call on a
+// LogObject with remaining records left to read, capped at the size the
+// LogObject was limited to after a response that was too large
+func (c *opcuaClient) pageSize(logObjectID string, remaining int) uint32 {
+	size := uint32(remaining) //nolint:gosec
+	c.pageLimitMu.Lock()
+	defer c.pageLimitMu.Unlock()
+	if limit, ok := c.pageLimits[logObjectID]; ok && limit < size {
+		return limit
+	}
+	return size
+}
+
+// limitPageSize caps MaxReturnRecords of a LogObject for the lifetime of the
+// client, so the size that works is not rediscovered on every scrape
+func (c *opcuaClient) limitPageSize(logObjectID string, size uint32) {
+	c.pageLimitMu.Lock()
+	defer c.pageLimitMu.Unlock()
+	if c.pageLimits == nil {
+		c.pageLimits = make(map[string]uint32)
+	}
+	c.pageLimits[logObjectID] = size
+}
+
+// isResponseTooLarge reports whether the server rejected a call because its
+// response would exceed the message size limits
+func isResponseTooLarge(err error) bool {
+	return errors.Is(err, ua.StatusBadResponseTooLarge) || errors.Is(err, ua.StatusBadEncodingLimitsExceeded)
+}
+
+// isConnectionError reports whether err means the connection or session to
+// the server is gone, as opposed to a rejected call
+func isConnectionError(err error) bool {
+	var connErr *connectionError
+	if errors.As(err, &connErr) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var status ua.StatusCode
+	if errors.As(err, &status) {
+		switch status {
+		case ua.StatusBadConnectionClosed,
+			ua.StatusBadSecureChannelClosed,
+			ua.StatusBadSessionClosed,
+			ua.StatusBadSessionIDInvalid,
+			ua.StatusBadNotConnected,
+			ua.StatusBadServerNotConnected,
+			ua.StatusBadCommunicationError:
+			return true
+		}
+	}
+	return false
+}
+
 // parseLogRecordsDataType parses the LogRecordsDataType variant into LogRecord structures
-func (c *opcuaClient) parseLogRecordsDataType(variant *ua.Variant) ([]testdata.OPCUALogRecord, error) {
+func (c *opcuaClient) parseLogRecordsDataType(variant *ua.Variant) ([]model.LogRecord, error) {
 	if variant == nil {
-		return []testdata.OPCUALogRecord{}, nil
+		return []model.LogRecord{}, nil
 	}
 
 	// The LogRecordsDataType contains an array of LogRecord ExtensionObjects
@@ -132,24 +529,27 @@ func (c *opcuaClient) parseLogRecordsDataType(variant *ua.Variant) ([]testdata.O
 	case []*ua.ExtensionObject:
 		return c.parseExtensionObjectArray(v)
 	case nil:
-		return []testdata.OPCUALogRecord{}, nil
+		return []model.LogRecord{}, nil
 	default:
-		c.logger.Warn("Unexpected LogRecords data type",
+		c.warnings.Warn(c.logger, fmt.Sprintf("records_type/%T", value), "Unexpected LogRecords data type",
 			zap.String("type", fmt.Sprintf("%T", value)))
-		return []testdata.OPCUALogRecord{}, nil
+		c.recordDropped(decodeReasonBadVariantType)
+		return []model.LogRecord{}, nil
 	}
 }
 
 // parseLogRecordArray parses an array of log records
-func (c *opcuaClient) parseLogRecordArray(records []interface{}) ([]testdata.OPCUALogRecord, error) {
-	var result []testdata.OPCUALogRecord
+func (c *opcuaClient) parseLogRecordArray(records []interface{}) ([]model.LogRecord, error) {
+	result := getRecordSlice()
 
 	for i, record := range records {
 		logRecord, err := c.parseLogRecord(record)
 		if err != nil {
-			c.logger.Warn("Failed to parse log record",
+			c.warnings.Warn(c.logger, fmt.Sprintf("parse_record/%T", record), "Failed to parse log record",
 				zap.Int("index", i),
+				zap.String("reason", decodeReason(err)),
 				zap.Error(err))
+			c.recordDropped(decodeReason(err))
 			continue
 		}
 		result = append(result, logRecord)
@@ -159,8 +559,8 @@ func (c *opcuaClient) parseLogRecordArray(records []interface{}) ([]testdata.OPC
 }
 
 // parseExtensionObjectArray parses an array of ExtensionObjects containing LogRecords
-func (c *opcuaClient) parseExtensionObjectArray(objects []*ua.ExtensionObject) ([]testdata.OPCUALogRecord, error) {
-	var result []testdata.OPCUALogRecord
+func (c *opcuaClient) parseExtensionObjectArray(objects []*ua.ExtensionObject) ([]model.LogRecord, error) {
+	result := getRecordSlice()
 
 	for i, obj := range objects {
 		if obj == nil {
@@ -169,9 +569,11 @@ func (c *opcuaClient) parseExtensionObjectArray(objects []*ua.ExtensionObject) (
 
 		logRecord, err := c.parseLogRecordFromExtensionObject(obj)
 		if err != nil {
-			c.logger.Warn("Failed to parse ExtensionObject",
+			c.warnings.Warn(c.logger, "parse_extension_object/"+obj.TypeID.String(), "Failed to parse ExtensionObject",
 				zap.Int("index", i),
+				zap.String("reason", decodeReason(err)),
 				zap.Error(err))
+			c.recordDropped(decodeReason(err))
 			continue
 		}
 		result = append(result, logRecord)
@@ -180,53 +582,85 @@ func (c *opcuaClient) parseExtensionObjectArray(objects []*ua.ExtensionObject) (
 	return result, nil
 }
 
+// recordDropped counts a record dropped while decoding a GetRecords result
+func (c *opcuaClient) recordDropped(reason string) {
+	if c.telemetry == nil {
+		return
+	}
+	c.telemetry.OpcuaRecordsDropped.Add(context.Background(), 1,
+		metric.WithAttributes(attribute.String("opcua.decode_reason", reason)))
+}
+
 // parseLogRecord parses a single LogRecord from interface{}
-func (c *opcuaClient) parseLogRecord(data interface{}) (testdata.OPCUALogRecord, error) {
+func (c *opcuaClient) parseLogRecord(data interface{}) (model.LogRecord, error) {
 	// Try to extract fields from a map or struct
 	if m, ok := data.(map[string]interface{}); ok {
 		return c.parseLogRecordFromMap(m)
 	}
 
-	return testdata.OPCUALogRecord{}, fmt.Errorf("unsupported log record format: %T", data)
+	return model.LogRecord{}, &decodeError{
+		TypeID: fmt.Sprintf("%T", data),
+		Reason: decodeReasonBadVariantType,
+		Err:    fmt.Errorf("unsupported log record format: %T", data),
+	}
 }
 
 // parseLogRecordFromExtensionObject parses LogRecord from an ExtensionObject.
-// The ExtensionObject's binary body is automatically decoded by gopcua into a
-// LogRecordExtObj if the type was registered (see log_record_type.go).
-func (c *opcuaClient) parseLogRecordFromExtensionObject(obj *ua.ExtensionObject) (testdata.OPCUALogRecord, error) {
+// The ExtensionObject's body is automatically decoded by gopcua into a
+// LogRecordExtObj if the type was registered (see internal/client/log_record.go).
+// Bodies in the binary and in the JSON encoding are both accepted.
+func (c *opcuaClient) parseLogRecordFromExtensionObject(obj *ua.ExtensionObject) (model.LogRecord, error) {
 	c.logger.Debug("Parsing LogRecord from ExtensionObject",
 		zap.String("type_id", obj.TypeID.String()))
 
+	// With log_record_type_id set, other types are not LogRecords
+	if typeID := c.logRecordTypeID.Load(); typeID != nil && !typeID.Equal(obj.TypeID.NodeID) {
+		return model.LogRecord{}, &decodeError{
+			TypeID: obj.TypeID.String(),
+			Reason: decodeReasonUnknownTypeID,
+			Err:    fmt.Errorf("ExtensionObject TypeID %s is not log_record_type_id %s", obj.TypeID.String(), typeID),
+		}
+	}
+
 	// Check if gopcua successfully decoded the ExtensionObject into our registered type
-	if lr, ok := obj.Value.(*LogRecordExtObj); ok && lr != nil {
+	if lr, ok := obj.Value.(*client.LogRecordExtObj); ok && lr != nil {
 		return logRecordExtObjToRecord(lr), nil
 	}
 
-	// Fallback: if the Value is raw bytes (type not registered due to namespace mismatch),
-	// manually decode the binary body using our LogRecordExtObj decoder.
+	// Fallback: if the Value is raw bytes (type not registered due to namespace mismatch,
+	// or the TypeID of the JSON encoding), manually decode the body using our
+	// LogRecordExtObj decoder, which detects the encoding.
 	if raw, ok := obj.Value.([]byte); ok && len(raw) > 0 {
-		c.logger.Debug("Falling back to manual binary decoding for ExtensionObject",
+		c.logger.Debug("Falling back to manual decoding for ExtensionObject",
 			zap.String("type_id", obj.TypeID.String()),
 			zap.Int("body_len", len(raw)))
-		lr := &LogRecordExtObj{}
+		lr := &client.LogRecordExtObj{}
 		if _, err := lr.Decode(raw); err != nil {
-			return testdata.OPCUALogRecord{}, fmt.Errorf("failed to manually decode ExtensionObject body: %w", err)
+			return model.LogRecord{}, newDecodeError(obj.TypeID.String(), fmt.Errorf("failed to manually decode ExtensionObject body: %w", err))
 		}
 		return logRecordExtObjToRecord(lr), nil
 	}
 
 	if obj.Value == nil {
-		return testdata.OPCUALogRecord{}, fmt.Errorf("ExtensionObject Value is nil (unknown TypeID %s)", obj.TypeID.String())
+		return model.LogRecord{}, &decodeError{
+			TypeID: obj.TypeID.String(),
+			Reason: decodeReasonUnknownTypeID,
+			Err:    fmt.Errorf("ExtensionObject Value is nil (unknown TypeID %s)", obj.TypeID.String()),
+		}
 	}
 
-	return testdata.OPCUALogRecord{}, fmt.Errorf("unsupported ExtensionObject value type: %T", obj.Value)
+	return model.LogRecord{}, &decodeError{
+		TypeID: obj.TypeID.String(),
+		Reason: decodeReasonBadVariantType,
+		Err:    fmt.Errorf("unsupported ExtensionObject value type: %T", obj.Value),
+	}
 }
 
 // logRecordExtObjToRecord converts a decoded LogRecordExtObj into an OPCUALogRecord,
 // mapping source NodeId components, trace context, and additional data attributes.
-func logRecordExtObjToRecord(lr *LogRecordExtObj) testdata.OPCUALogRecord {
+func logRecordExtObjToRecord(lr *client.LogRecordExtObj) model.LogRecord {
 	ns, idType, id := nodeIDComponents(lr.SourceNode)
-	record := testdata.OPCUALogRecord{
+	record := model.LogRecord{
 		Timestamp:       lr.Time,
 		Severity:        lr.Severity,
 		Message:         lr.Message,
@@ -234,13 +668,24 @@ func logRecordExtObjToRecord(lr *LogRecordExtObj) testdata.OPCUALogRecord {
 		SourceNamespace: ns,
 		SourceIDType:    idType,
 		SourceID:        id,
-		Attributes:      make(map[string]interface{}),
+		Attributes:      getAttributeMap(),
+
+		SourceNamespaceURI: lr.SourceNamespaceURI,
+		MessageLocale:      lr.MessageLocale,
+	}
+
+	// The null NodeId i=0 stands for an absent EventType
+	if lr.EventTypeNode != nil {
+		if eventType := lr.EventTypeNode.String(); eventType != "i=0" {
+			record.EventType = eventType
+		}
 	}
 
 	// Populate trace context (SpanID == 0 signals no trace context)
 	if lr.SpanID != 0 {
 		record.TraceID = lr.TraceIDHex()
 		record.SpanID = lr.SpanIDHex()
+		record.ParentSpanID = lr.ParentSpanIDHex()
 		record.TraceFlags = 0x01 // sampled
 	}
 
@@ -253,9 +698,9 @@ func logRecordExtObjToRecord(lr *LogRecordExtObj) testdata.OPCUALogRecord {
 }
 
 // parseLogRecordFromMap parses LogRecord from a map structure
-func (c *opcuaClient) parseLogRecordFromMap(m map[string]interface{}) (testdata.OPCUALogRecord, error) {
-	record := testdata.OPCUALogRecord{
-		Attributes: make(map[string]interface{}),
+func (c *opcuaClient) parseLogRecordFromMap(m map[string]interface{}) (model.LogRecord, error) {
+	record := model.LogRecord{
+		Attributes: getAttributeMap(),
 	}
 
 	// Parse mandatory fields: Time, Severity, Message
@@ -272,6 +717,9 @@ func (c *opcuaClient) parseLogRecordFromMap(m map[string]interface{}) (testdata.
 			if text, ok := localizedText["Text"].(string); ok {
 				record.Message = text
 			}
+			if locale, ok := localizedText["Locale"].(string); ok {
+				record.MessageLocale = locale
+			}
 		} else if text, ok := msgVal.(string); ok {
 			record.Message = text
 		}
@@ -299,6 +747,9 @@ func (c *opcuaClient) parseLogRecordFromMap(m map[string]interface{}) (testdata.
 		if spanID, ok := traceCtx["SpanId"].(string); ok {
 			record.SpanID = spanID
 		}
+		if parentSpanID, ok := traceCtx["ParentSpanId"].(string); ok {
+			record.ParentSpanID = parentSpanID
+		}
 		if flags, ok := traceCtx["TraceFlags"].(byte); ok {
 			record.TraceFlags = flags
 		}
@@ -331,10 +782,10 @@ func nodeIDComponents(nodeID *ua.NodeID) (namespace uint16, idType string, id st
 		id = nodeID.StringID()
 	case ua.NodeIDTypeGUID:
 		idType = "Guid"
-		id = nodeID.String() // full string representation for GUID
+		id = nodeID.StringID()
 	case ua.NodeIDTypeByteString:
 		idType = "Opaque"
-		id = fmt.Sprintf("%d", nodeID.IntID())
+		id = nodeID.StringID() // base64-encoded
 	default: // NodeIDTypeTwoByte, NodeIDTypeFourByte, NodeIDTypeNumeric
 		idType = "Numeric"
 		id = fmt.Sprintf("%d", nodeID.IntID())
@@ -344,20 +795,5 @@ func nodeIDComponents(nodeID *ua.NodeID) (namespace uint16, idType string, id st
 
 // getMinSeverityValue converts config severity string to numeric value
 func (c *opcuaClient) getMinSeverityValue() uint16 {
-	switch c.config.Filter.MinSeverity {
-	case "Trace":
-		return 51
-	case "Debug":
-		return 1
-	case "Info":
-		return 101
-	case "Warn", "Warning":
-		return 201
-	case "Error":
-		return 301
-	case "Fatal", "Emergency":
-		return 401
-	default:
-		return 101 // Default to Info
-	}
+	return c.config.Filter.minSeverityValue()
 }
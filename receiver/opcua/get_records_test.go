@@ -4,15 +4,45 @@
 package opcua
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gopcua/opcua/ua"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadatatest"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
 )
 
+// fixedTraceIDBytes returns the W3C TraceId bytes for "0102030405060708090a0b0c0d0e0f10".
+func fixedTraceIDBytes() [16]byte {
+	return [16]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+		0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+}
+
+// isVariantScalar reports whether AdditionalData values of this type are encoded as a Variant scalar
+func isVariantScalar(v any) bool {
+	switch v.(type) {
+	case string, bool, int8, uint8, int16, uint16, int32, uint32, int64, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
 func newTestClient() *opcuaClient {
 	return &opcuaClient{
 		config: &Config{
@@ -25,7 +55,7 @@ func newTestClient() *opcuaClient {
 func TestParseLogRecordFromExtensionObject_ValidLogRecord(t *testing.T) {
 	c := newTestClient()
 
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 		Severity:   300,
 		Message:    "Configuration loaded successfully",
@@ -33,7 +63,7 @@ func TestParseLogRecordFromExtensionObject_ValidLogRecord(t *testing.T) {
 	}
 
 	obj := &ua.ExtensionObject{
-		TypeID: &ua.ExpandedNodeID{NodeID: LogRecordExtObjTypeID},
+		TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
 		Value:  lr,
 	}
 
@@ -48,6 +78,91 @@ func TestParseLogRecordFromExtensionObject_ValidLogRecord(t *testing.T) {
 	assert.NotNil(t, record.Attributes)
 }
 
+func TestLogRecordExtObjToRecordSourceNode(t *testing.T) {
+	tests := []struct {
+		name         string
+		nodeID       *ua.NodeID
+		namespaceURI string
+		idType       string
+		id           string
+		node         string
+	}{
+		{
+			name:   "numeric",
+			nodeID: ua.NewNumericNodeID(2, 1002),
+			idType: "Numeric", id: "1002", node: "ns=2;i=1002",
+		},
+		{
+			name:   "guid",
+			nodeID: ua.NewGUIDNodeID(3, "72962B91-FA75-4AE6-8D28-B404DC7DAF63"),
+			idType: "Guid", id: "72962B91-FA75-4AE6-8D28-B404DC7DAF63", node: "ns=3;g=72962B91-FA75-4AE6-8D28-B404DC7DAF63",
+		},
+		{
+			name:   "byte string",
+			nodeID: ua.NewByteStringNodeID(4, []byte("abc")),
+			idType: "Opaque", id: "YWJj", node: "ns=4;b=YWJj",
+		},
+		{
+			name:         "namespace uri",
+			nodeID:       ua.NewStringNodeID(0, "Press3"),
+			namespaceURI: "http://vendor.com/UA/",
+			idType:       "String", id: "Press3", node: "nsu=http://vendor.com/UA/;s=Press3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			record := logRecordExtObjToRecord(&client.LogRecordExtObj{SourceNode: tt.nodeID, SourceNamespaceURI: tt.namespaceURI})
+			assert.Equal(t, tt.idType, record.SourceIDType)
+			assert.Equal(t, tt.id, record.SourceID)
+			assert.Equal(t, tt.namespaceURI, record.SourceNamespaceURI)
+			assert.Equal(t, tt.node, sourceNode(record))
+
+			parsed, err := ua.ParseNodeID(strings.TrimPrefix(tt.node, "nsu="+tt.namespaceURI+";"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.nodeID.Type(), parsed.Type())
+		})
+	}
+}
+
+func TestLogRecordExtObjToRecordEventType(t *testing.T) {
+	record := logRecordExtObjToRecord(&client.LogRecordExtObj{EventTypeNode: ua.NewNumericNodeID(0, 2041)})
+	assert.Equal(t, "i=2041", record.EventType)
+
+	// Binary records without an EventType decode it as the null NodeId
+	record = logRecordExtObjToRecord(&client.LogRecordExtObj{EventTypeNode: ua.NewTwoByteNodeID(0)})
+	assert.Empty(t, record.EventType)
+}
+
+func TestParseLogRecordFromExtensionObject_JSONBody(t *testing.T) {
+	c := newTestClient()
+
+	// The TypeID of the JSON encoding is not registered, so gopcua leaves the body raw
+	obj := &ua.ExtensionObject{
+		TypeID: &ua.ExpandedNodeID{NodeID: ua.NewNumericNodeID(2, 5003)},
+		Value: []byte(`{"Time": "2025-01-15T10:00:00Z", "Severity": 300, "SourceName": "SystemComponent",
+			"SourceNode": "ns=2;s=System", "Message": {"Text": "Configuration loaded successfully"},
+			"AdditionalData": [{"Name": "operator", "Value": {"Type": 12, "Body": "alice"}}]}`),
+	}
+
+	record, err := c.parseLogRecordFromExtensionObject(obj)
+	require.NoError(t, err)
+
+	assert.True(t, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC).Equal(record.Timestamp))
+	assert.Equal(t, uint16(300), record.Severity)
+	assert.Equal(t, "Configuration loaded successfully", record.Message)
+	assert.Equal(t, "SystemComponent", record.SourceName)
+	assert.Equal(t, "String", record.SourceIDType)
+	assert.Equal(t, "System", record.SourceID)
+	assert.Equal(t, "alice", record.Attributes["operator"])
+
+	// Malformed JSON bodies are invalid, not truncated
+	obj.Value = []byte(`{"Time": "yesterday"}`)
+	_, err = c.parseLogRecordFromExtensionObject(obj)
+	require.Error(t, err)
+	assert.Equal(t, decodeReasonInvalidBody, decodeReason(err))
+}
+
 func TestParseLogRecordFromExtensionObject_NilValue(t *testing.T) {
 	c := newTestClient()
 
@@ -59,6 +174,10 @@ func TestParseLogRecordFromExtensionObject_NilValue(t *testing.T) {
 	_, err := c.parseLogRecordFromExtensionObject(obj)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "ExtensionObject Value is nil")
+
+	var decodeErr *decodeError
+	require.ErrorAs(t, err, &decodeErr)
+	assert.Equal(t, "i=9999", decodeErr.TypeID)
 }
 
 func TestParseLogRecordFromExtensionObject_UnknownValueType(t *testing.T) {
@@ -79,8 +198,8 @@ func TestParseExtensionObjectArray(t *testing.T) {
 
 	objects := []*ua.ExtensionObject{
 		{
-			TypeID: &ua.ExpandedNodeID{NodeID: LogRecordExtObjTypeID},
-			Value: &LogRecordExtObj{
+			TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+			Value: &client.LogRecordExtObj{
 				Time:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 				Severity:   150,
 				Message:    "First record",
@@ -89,8 +208,8 @@ func TestParseExtensionObjectArray(t *testing.T) {
 		},
 		nil, // nil entries should be skipped
 		{
-			TypeID: &ua.ExpandedNodeID{NodeID: LogRecordExtObjTypeID},
-			Value: &LogRecordExtObj{
+			TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+			Value: &client.LogRecordExtObj{
 				Time:       time.Date(2025, 1, 15, 10, 1, 0, 0, time.UTC),
 				Severity:   700,
 				Message:    "Second record",
@@ -115,8 +234,8 @@ func TestParseExtensionObjectArray_WithFailedEntries(t *testing.T) {
 
 	objects := []*ua.ExtensionObject{
 		{
-			TypeID: &ua.ExpandedNodeID{NodeID: LogRecordExtObjTypeID},
-			Value: &LogRecordExtObj{
+			TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+			Value: &client.LogRecordExtObj{
 				Time:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 				Severity:   300,
 				Message:    "Good record",
@@ -136,10 +255,45 @@ func TestParseExtensionObjectArray_WithFailedEntries(t *testing.T) {
 	assert.Equal(t, "Good record", records[0].Message)
 }
 
+func TestParseExtensionObjectArray_CountsDroppedRecords(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(context.Background()))
+	}()
+	c := newTestClient()
+	c.telemetry = newTestTelemetryBuilder(t, tel.NewTelemetrySettings())
+
+	raw, err := (&client.LogRecordExtObj{Time: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), Severity: 300, Message: "cut off"}).Encode()
+	require.NoError(t, err)
+	unknownType := &ua.ExpandedNodeID{NodeID: ua.NewNumericNodeID(0, 9999)}
+	objects := []*ua.ExtensionObject{
+		{TypeID: unknownType, Value: nil},
+		{TypeID: unknownType, Value: raw[:len(raw)-4]},
+		{TypeID: unknownType, Value: int32(7)},
+		{TypeID: unknownType, Value: nil},
+	}
+
+	records, err := c.parseExtensionObjectArray(objects)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	// A result that is not an array of records counts as one
+	_, err = c.parseLogRecordsDataType(ua.MustVariant(int32(1)))
+	require.NoError(t, err)
+
+	metadatatest.AssertEqualOpcuaRecordsDropped(t, tel,
+		[]metricdata.DataPoint[int64]{
+			{Value: 2, Attributes: attribute.NewSet(attribute.String("opcua.decode_reason", "unknown_type_id"))},
+			{Value: 1, Attributes: attribute.NewSet(attribute.String("opcua.decode_reason", "truncated_body"))},
+			{Value: 2, Attributes: attribute.NewSet(attribute.String("opcua.decode_reason", "bad_variant_type"))},
+		},
+		metricdatatest.IgnoreTimestamp())
+}
+
 func TestParseLogRecordsDataType_ExtensionObjects(t *testing.T) {
 	c := newTestClient()
 
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:       time.Date(2025, 1, 15, 10, 5, 0, 0, time.UTC),
 		Severity:   500,
 		Message:    "High memory usage",
@@ -148,7 +302,7 @@ func TestParseLogRecordsDataType_ExtensionObjects(t *testing.T) {
 
 	extObjs := []*ua.ExtensionObject{
 		{
-			TypeID: &ua.ExpandedNodeID{NodeID: LogRecordExtObjTypeID},
+			TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
 			Value:  lr,
 		},
 	}
@@ -246,10 +400,35 @@ func TestGetMinSeverityValue(t *testing.T) {
 	}
 }
 
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"EOF", io.EOF, true},
+		{"wrapped EOF", fmt.Errorf("Call service failed: %w", io.ErrUnexpectedEOF), true},
+		{"closed connection", net.ErrClosed, true},
+		{"network error", &net.OpError{Op: "read", Err: errors.New("connection reset")}, true},
+		{"connection closed", ua.StatusBadConnectionClosed, true},
+		{"secure channel closed", fmt.Errorf("Call service failed: %w", ua.StatusBadSecureChannelClosed), true},
+		{"session invalid", ua.StatusBadSessionIDInvalid, true},
+		{"too many operations", ua.StatusBadTooManyOperations, false},
+		{"continuation point invalid", errContinuationPointInvalid, false},
+		{"timeout", context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isConnectionError(tt.err))
+		})
+	}
+}
+
 // --- logRecordExtObjToRecord ---
 
 func TestLogRecordExtObjToRecord_BasicFields(t *testing.T) {
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 		Severity:   300,
 		Message:    "Test message",
@@ -270,7 +449,7 @@ func TestLogRecordExtObjToRecord_BasicFields(t *testing.T) {
 }
 
 func TestLogRecordExtObjToRecord_WithTraceContext(t *testing.T) {
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:         time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 		Severity:     150,
 		Message:      "Traced record",
@@ -288,7 +467,7 @@ func TestLogRecordExtObjToRecord_WithTraceContext(t *testing.T) {
 
 func TestLogRecordExtObjToRecord_NoTraceContext(t *testing.T) {
 	// SpanID == 0 means no trace context
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 		Severity: 150,
 		Message:  "No trace",
@@ -303,7 +482,7 @@ func TestLogRecordExtObjToRecord_NoTraceContext(t *testing.T) {
 }
 
 func TestLogRecordExtObjToRecord_WithAdditionalData(t *testing.T) {
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 		Severity: 150,
 		Message:  "Data record",
@@ -324,7 +503,7 @@ func TestLogRecordExtObjToRecord_WithAdditionalData(t *testing.T) {
 func TestParseLogRecordFromExtensionObject_WithTraceContext(t *testing.T) {
 	c := newTestClient()
 
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:         time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
 		Severity:     700,
 		Message:      "Connection timeout",
@@ -339,7 +518,7 @@ func TestParseLogRecordFromExtensionObject_WithTraceContext(t *testing.T) {
 	}
 
 	obj := &ua.ExtensionObject{
-		TypeID: &ua.ExpandedNodeID{NodeID: LogRecordExtObjTypeID},
+		TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
 		Value:  lr,
 	}
 
@@ -363,7 +542,7 @@ func TestParseLogRecordFromExtensionObject_BinaryFallback(t *testing.T) {
 
 	// Build a LogRecordExtObj, encode it to raw bytes, and wrap in ExtensionObject
 	// with a different TypeID to trigger the binary-fallback path.
-	lr := &LogRecordExtObj{
+	lr := &client.LogRecordExtObj{
 		Time:         time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
 		Severity:     300,
 		Message:      "Fallback test",
@@ -390,3 +569,141 @@ func TestParseLogRecordFromExtensionObject_BinaryFallback(t *testing.T) {
 	assert.Equal(t, "0102030405060708", record.SpanID)
 	assert.Equal(t, byte(0x01), record.TraceFlags)
 }
+
+// extObjEncoder encodes mock server records as LogRecord ExtensionObjects. With
+// binary set the body is left as raw bytes, as gopcua delivers it when the
+// type is not registered, to exercise the manual decoding fallback.
+func extObjEncoder(binary bool) testdata.RecordEncoder {
+	return func(record testdata.OPCUALogRecord) (*ua.ExtensionObject, error) {
+		lr := recordToLogRecordExtObj(record)
+		obj := &ua.ExtensionObject{
+			EncodingMask: ua.ExtensionObjectBinary,
+			TypeID:       &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+			Value:        lr,
+		}
+		if binary {
+			body, err := lr.Encode()
+			if err != nil {
+				return nil, err
+			}
+			obj.Value = body
+		}
+		return obj, nil
+	}
+}
+
+func TestMockServerExtensionObjectResponses(t *testing.T) {
+	for _, binary := range []bool{false, true} {
+		t.Run(fmt.Sprintf("binary=%t", binary), func(t *testing.T) {
+			mockServer := testdata.NewMockServer("", zap.NewNop())
+			mockServer.SetRecordEncoder(extObjEncoder(binary))
+			expected := wireRecords(5)
+			mockServer.AddLogRecords(expected)
+			mockServer.InjectFault(testdata.Fault{Kind: testdata.FaultMalformedRecord, Call: 2})
+
+			call := func() []testdata.OPCUALogRecord {
+				result, err := mockServer.Call(context.Background(), &ua.CallMethodRequest{
+					ObjectID: testdata.DefaultLogObjectID,
+					MethodID: testdata.DefaultGetRecordsMethodID,
+					InputArguments: []*ua.Variant{
+						ua.MustVariant(time.Time{}),
+						ua.MustVariant(time.Now()),
+						ua.MustVariant(uint32(100)),
+						ua.MustVariant(uint16(1)),
+						ua.MustVariant(uint32(0x1F)),
+						ua.MustVariant([]byte(nil)),
+					},
+				})
+				require.NoError(t, err)
+				require.Equal(t, ua.StatusOK, result.StatusCode)
+				require.IsType(t, []*ua.ExtensionObject{}, result.OutputArguments[0].Value())
+
+				records, err := newTestClient().parseLogRecordsDataType(result.OutputArguments[0])
+				require.NoError(t, err)
+				return records
+			}
+
+			records := call()
+			require.Len(t, records, len(expected))
+			for i, r := range records {
+				assert.True(t, expected[i].Timestamp.Equal(r.Timestamp))
+				assert.Equal(t, expected[i].Severity, r.Severity)
+				assert.Equal(t, expected[i].Message, r.Message)
+				assert.Equal(t, expected[i].SourceName, r.SourceName)
+				assert.Equal(t, expected[i].SourceID, r.SourceID)
+				assert.Equal(t, expected[i].TraceID, r.TraceID)
+				assert.Equal(t, expected[i].SpanID, r.SpanID)
+				assert.Equal(t, "pump", r.Attributes["component"])
+			}
+
+			// A malformed body is dropped without failing the page
+			assert.Len(t, call(), len(expected)-1)
+		})
+	}
+}
+
+func TestMockClientRecordDecoder(t *testing.T) {
+	mockServer := testdata.NewMockServer("", zap.NewNop())
+	require.NoError(t, mockServer.Start(context.Background()))
+	defer func() {
+		require.NoError(t, mockServer.Stop(context.Background()))
+	}()
+	mockServer.SetRecordEncoder(extObjEncoder(true))
+	mockServer.AddLogRecords(wireRecords(3))
+
+	mockClient := testdata.NewMockClient(mockServer, zap.NewNop())
+	require.NoError(t, mockClient.Connect(context.Background()))
+
+	_, _, err := mockClient.GetRecords(context.Background(), time.Time{}, time.Now(), 100, nil)
+	require.Error(t, err)
+
+	mockClient.SetRecordDecoder(newTestClient().parseLogRecordFromExtensionObject)
+	records, _, err := mockClient.GetRecords(context.Background(), time.Time{}, time.Now(), 100, nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func TestReadPositionAdvance(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	position := readPosition{Start: t0}
+	for _, ts := range []time.Time{t0, t0, t0.Add(time.Second), t0.Add(time.Second), t0} {
+		position = position.advance(ts)
+	}
+	// The older record a server sorting its records would not return is ignored
+	assert.Equal(t, readPosition{Start: t0.Add(time.Second), Skip: 2}, position)
+}
+
+func TestSkipRead(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	page := func(offsets ...int) []model.LogRecord {
+		records := getRecordSlice()
+		for _, offset := range offsets {
+			records = append(records, model.LogRecord{Timestamp: t0.Add(time.Duration(offset) * time.Second)})
+		}
+		return records
+	}
+
+	tests := []struct {
+		name     string
+		page     []model.LogRecord
+		skip     int
+		wantLen  int
+		wantSkip int
+	}{
+		{name: "skips records at the start", page: page(0, 0, 1), skip: 2, wantLen: 1},
+		{name: "page ends while skipping", page: page(0, 0), skip: 3, wantSkip: 1},
+		{name: "newer record ends skipping", page: page(0, 1, 1), skip: 3, wantLen: 2},
+		{name: "no record at the start", page: page(1, 2), skip: 2, wantLen: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, skip := skipRead(tt.page, t0, tt.skip)
+			assert.Len(t, records, tt.wantLen)
+			assert.Equal(t, tt.wantSkip, skip)
+			for _, record := range records {
+				assert.True(t, record.Timestamp.After(t0))
+			}
+		})
+	}
+}
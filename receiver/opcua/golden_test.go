@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/golden"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// goldenRecords is a fixed set of records covering numeric and string source
+// NodeIds, trace context, and the attribute types handled by putAttribute.
+func goldenRecords() []testdata.OPCUALogRecord {
+	return []testdata.OPCUALogRecord{
+		{
+			Timestamp:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+			Severity:        150,
+			Message:         "System startup initiated",
+			SourceName:      "SystemComponent",
+			SourceNamespace: 1,
+			SourceIDType:    "Numeric",
+			SourceID:        "100",
+			TraceID:         "0102030405060708090a0b0c0d0e0f10",
+			SpanID:          "0102030405060708",
+			TraceFlags:      1,
+			Attributes: map[string]interface{}{
+				"component": "system",
+				"version":   "1.0.0",
+			},
+		},
+		{
+			Timestamp:       time.Date(2025, 1, 15, 10, 2, 0, 0, time.UTC),
+			Severity:        300,
+			Message:         "Connection established to database",
+			SourceName:      "NetworkModule",
+			SourceNamespace: 1,
+			SourceIDType:    "Numeric",
+			SourceID:        "102",
+			Attributes:      map[string]interface{}{},
+		},
+		{
+			Timestamp:       time.Date(2025, 1, 15, 10, 7, 0, 0, time.UTC),
+			Severity:        700,
+			Message:         "Connection timeout to external service",
+			SourceName:      "NetworkModule",
+			SourceNamespace: 2,
+			SourceIDType:    "String",
+			SourceID:        "Device.Network",
+			Attributes: map[string]interface{}{
+				"retry_count": 3,
+				"latency_ms":  12.5,
+				"critical":    true,
+			},
+		},
+	}
+}
+
+func TestGoldenTransformLogs(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	actual := transformer.TransformLogs(goldenRecords())
+
+	expectedFile := filepath.Join("testdata", "golden", "transform_logs.yaml")
+	// golden.WriteLogs(t, expectedFile, actual)
+	expected, err := golden.ReadLogs(expectedFile)
+	require.NoError(t, err)
+
+	require.NoError(t, plogtest.CompareLogs(expected, actual, plogtest.IgnoreObservedTimestamp()))
+}
+
+func TestGoldenDecodeAndTransform(t *testing.T) {
+	c := newTestClient()
+
+	lr := &client.LogRecordExtObj{
+		Time:         time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:     150,
+		Message:      "System startup initiated",
+		SourceNode:   ua.NewNumericNodeID(1, 100),
+		SourceName:   "SystemComponent",
+		TraceIDBytes: fixedTraceIDBytes(),
+		SpanID:       0x0102030405060708,
+		AdditionalData: map[string]interface{}{
+			"component":   "system",
+			"retry_count": 3,
+		},
+	}
+	encoded, err := lr.Encode()
+	require.NoError(t, err)
+
+	obj := &ua.ExtensionObject{
+		TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+		Value:  encoded,
+	}
+	record, err := c.parseLogRecordFromExtensionObject(obj)
+	require.NoError(t, err)
+
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	actual := transformer.TransformLogs([]testdata.OPCUALogRecord{record})
+
+	expectedFile := filepath.Join("testdata", "golden", "decode_and_transform.yaml")
+	// golden.WriteLogs(t, expectedFile, actual)
+	expected, err := golden.ReadLogs(expectedFile)
+	require.NoError(t, err)
+
+	require.NoError(t, plogtest.CompareLogs(expected, actual, plogtest.IgnoreObservedTimestamp()))
+}
+
+func TestGoldenTransformLogsEmpty(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	actual := transformer.TransformLogs(nil)
+
+	require.NoError(t, plogtest.CompareLogs(plog.NewLogs(), actual))
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"sync"
+	"time"
+)
+
+// healthTracker computes the scrape error rate over a sliding window and
+// decides whether the receiver is degraded. Occasional failures keep the rate
+// below the threshold; only a systemic failure degrades the receiver. The
+// zero value tracks nothing.
+type healthTracker struct {
+	window     time.Duration
+	threshold  float64
+	minScrapes int
+
+	mu       sync.Mutex
+	outcomes []scrapeOutcome
+	degraded bool
+}
+
+// scrapeOutcome is the result of one scrape in the window
+type scrapeOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+// healthState is the error rate of the window and the resulting state
+type healthState struct {
+	errorRate float64
+	scrapes   int
+	degraded  bool
+}
+
+// enabled reports whether health tracking is configured
+func (h *healthTracker) enabled() bool {
+	return h.threshold > 0 && h.window > 0
+}
+
+// record adds the outcome of a scrape at now and returns the new state and
+// whether the degraded state changed
+func (h *healthTracker) record(now time.Time, failed bool) (healthState, bool) {
+	if !h.enabled() {
+		return healthState{}, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.outcomes = append(h.outcomes, scrapeOutcome{at: now, failed: failed})
+	h.expire(now)
+
+	state := h.stateLocked()
+	degraded := state.scrapes >= h.minScrapes && state.errorRate >= h.threshold
+	changed := degraded != h.degraded
+	h.degraded = degraded
+	state.degraded = degraded
+	return state, changed
+}
+
+// state returns the state of the last recorded scrape
+func (h *healthTracker) state() healthState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	state := h.stateLocked()
+	state.degraded = h.degraded
+	return state
+}
+
+// expire drops the outcomes that fell out of the window
+func (h *healthTracker) expire(now time.Time) {
+	cutoff := now.Add(-h.window)
+	i := 0
+	for i < len(h.outcomes) && !h.outcomes[i].at.After(cutoff) {
+		i++
+	}
+	h.outcomes = h.outcomes[i:]
+}
+
+func (h *healthTracker) stateLocked() healthState {
+	state := healthState{scrapes: len(h.outcomes)}
+	if state.scrapes == 0 {
+		return state
+	}
+	failed := 0
+	for _, outcome := range h.outcomes {
+		if outcome.failed {
+			failed++
+		}
+	}
+	state.errorRate = float64(failed) / float64(state.scrapes)
+	return state
+}
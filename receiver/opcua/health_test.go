@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestHealthTracker(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		outcomes     []bool
+		step         time.Duration
+		wantRate     float64
+		wantDegraded bool
+	}{
+		{name: "healthy", outcomes: []bool{false, false, false}, step: time.Minute},
+		{name: "occasional blip", outcomes: []bool{false, true, false, false}, step: time.Minute, wantRate: 0.25},
+		{name: "too few scrapes", outcomes: []bool{true, true}, step: time.Minute, wantRate: 1},
+		{name: "systemic failure", outcomes: []bool{false, true, true}, step: time.Minute, wantRate: 2.0 / 3, wantDegraded: true},
+		{name: "failures expire", outcomes: []bool{true, true, true, false, false, false}, step: 4 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &healthTracker{window: 10 * time.Minute, threshold: 0.5, minScrapes: 3}
+			var state healthState
+			for i, failed := range tt.outcomes {
+				state, _ = h.record(t0.Add(time.Duration(i)*tt.step), failed)
+			}
+			assert.InDelta(t, tt.wantRate, state.errorRate, 1e-9)
+			assert.Equal(t, tt.wantDegraded, state.degraded)
+			assert.Equal(t, state, h.state())
+		})
+	}
+}
+
+func TestHealthTrackerTransitions(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	h := &healthTracker{window: 10 * time.Minute, threshold: 0.5, minScrapes: 2}
+
+	var changes []bool
+	for i, failed := range []bool{true, true, true, false, false, false, false} {
+		state, changed := h.record(t0.Add(time.Duration(i)*time.Minute), failed)
+		if changed {
+			changes = append(changes, state.degraded)
+		}
+	}
+	// Degraded once at the second failure, recovered once below the threshold
+	assert.Equal(t, []bool{true, false}, changes)
+}
+
+func TestHealthTrackerDisabled(t *testing.T) {
+	var h healthTracker
+	state, changed := h.record(time.Now(), true)
+	assert.False(t, changed)
+	assert.Equal(t, healthState{}, state)
+	assert.False(t, h.enabled())
+}
+
+// statusHost is a host recording the component status events reported to it
+type statusHost struct {
+	component.Host
+	events []*componentstatus.Event
+}
+
+func (h *statusHost) Report(event *componentstatus.Event) {
+	h.events = append(h.events, event)
+}
+
+func TestScraperHealthStatus(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	client := &windowClient{}
+	host := &statusHost{Host: componenttest.NewNopHost()}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Client = client
+	cfg.Health = HealthConfig{ErrorRateWindow: 10 * time.Minute, ErrorRateThreshold: 0.5, MinScrapes: 2}
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, host))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	scrape := func(failure error) {
+		t.Helper()
+		client.err = failure
+		clk.Advance(time.Minute)
+		_, err := scr.scrape(ctx)
+		if failure != nil {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+	}
+
+	// A single failure does not degrade the receiver
+	scrape(errors.New("BadTimeout"))
+	assert.Empty(t, host.events)
+
+	// Degraded at the threshold, reported once
+	scrape(errors.New("BadTimeout"))
+	scrape(errors.New("BadTimeout"))
+	require.Len(t, host.events, 1)
+	assert.Equal(t, componentstatus.StatusRecoverableError, host.events[0].Status())
+	require.ErrorContains(t, host.events[0].Err(), "BadTimeout")
+
+	// Recovered once the rate drops below the threshold, at 3 of 7
+	for range 4 {
+		scrape(nil)
+	}
+	require.Len(t, host.events, 2)
+	assert.Equal(t, componentstatus.StatusOK, host.events[1].Status())
+	assert.NoError(t, host.events[1].Err())
+}
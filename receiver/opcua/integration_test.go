@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// The scenarios below run the receiver the factory creates against the
+// in-process gopcua server of wire_server_test.go, so the secure channel,
+// Browse, Call encoding and LogRecord decoding are covered end to end, from
+// the configuration to the logs consumer.
+
+// startWireReceiver starts a logs receiver for cfg polling every second and
+// returns the sink of its logs
+func startWireReceiver(t *testing.T, cfg *Config) *consumertest.LogsSink {
+	t.Helper()
+	ctx := context.Background()
+	cfg.InitialDelay = 0
+	cfg.CollectionInterval = time.Second
+	cfg.Timeout = cfg.CollectionInterval
+
+	sink := new(consumertest.LogsSink)
+	logs, err := NewFactory().CreateLogs(ctx, receivertest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, logs.Start(ctx, componenttest.NewNopHost()))
+	t.Cleanup(func() {
+		assert.NoError(t, logs.Shutdown(ctx))
+	})
+	return sink
+}
+
+// sinkTimestamps returns the timestamps of the records in sink, in the order
+// they were delivered
+func sinkTimestamps(sink *consumertest.LogsSink) []time.Time {
+	var timestamps []time.Time
+	for _, logs := range sink.AllLogs() {
+		timestamps = append(timestamps, logTimestamps(logs)...)
+	}
+	return timestamps
+}
+
+func TestIntegrationWireBrowsePathToLogs(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddBrowsePath("2:DeviceSet/3:PLC1/2:DeviceLog", ws.logObjectID)
+	ws.AddLogRecords(wireRecords(3))
+
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{"Objects/2:DeviceSet/3:PLC1/2:DeviceLog"}
+	sink := startWireReceiver(t, cfg)
+
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+	// Every field travelled through the Call response and was decoded
+	resourceLogs := sink.AllLogs()[0].ResourceLogs().At(0)
+	endpoint, ok := resourceLogs.Resource().Attributes().Get("opcua.server.endpoint")
+	require.True(t, ok)
+	assert.Equal(t, ws.endpoint, endpoint.Str())
+	record := resourceLogs.ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "wire record", record.Body().Str())
+	assert.Equal(t, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), record.Timestamp().AsTime())
+	assert.Equal(t, pcommon.TraceID(fixedTraceIDBytes()), record.TraceID())
+	assert.Equal(t, pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8}, record.SpanID())
+}
+
+func TestIntegrationWireNewRecordsEachPoll(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(2))
+	sink := startWireReceiver(t, ws.newWireConfig())
+
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// Records logged after a poll arrive with the next one, exactly once
+	now := time.Now()
+	ws.AddLogRecords([]testdata.OPCUALogRecord{
+		{Timestamp: now, Severity: 500, Message: "new", Attributes: map[string]interface{}{}},
+		{Timestamp: now.Add(time.Millisecond), Severity: 500, Message: "newer", Attributes: map[string]interface{}{}},
+	})
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 4 }, 5*time.Second, 10*time.Millisecond)
+
+	// Further polls find nothing new
+	time.Sleep(1500 * time.Millisecond)
+	assert.Equal(t, 4, sink.LogRecordCount())
+}
+
+func TestIntegrationWireInterruptedPaginationRetried(t *testing.T) {
+	ws := startWireServer(t)
+	ws.SetMaxPageSize(2)
+	ws.AddLogRecords(wireRecords(5))
+	// The second page of the first poll fails, which fails the poll
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, Call: 2, Status: ua.StatusBadInternalError})
+	sink := startWireReceiver(t, ws.newWireConfig())
+
+	// The next poll reads the window again, without losing or repeating records
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 5 }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(1500 * time.Millisecond)
+	timestamps := sinkTimestamps(sink)
+	require.Len(t, timestamps, 5)
+	for i := 1; i < len(timestamps); i++ {
+		assert.True(t, timestamps[i-1].Before(timestamps[i]), "record %d delivered twice or out of order", i)
+	}
+}
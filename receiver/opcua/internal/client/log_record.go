@@ -1,9 +1,13 @@
 // Copyright The OpenTelemetry Authors
 // SPDX-License-Identifier: Apache-2.0
 
-package opcua
+// Package client holds the OPC UA side of the receiver that does not depend
+// on the receiver configuration, starting with the binary codec of Part 26
+// LogRecords.
+package client // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
 
 import (
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
@@ -40,11 +44,21 @@ type LogRecordExtObj struct {
 	Severity uint16
 	Message  string
 
+	// MessageLocale is the locale of the Message LocalizedText, empty if the
+	// server sent none
+	MessageLocale string
+
 	// Optional fields (bit 0–2)
 	EventTypeNode *ua.NodeID
 	SourceNode    *ua.NodeID
 	SourceName    string
 
+	// Expanded NodeId parts of SourceNode, set by servers that encode it with
+	// the namespace URI (flag 0x80) or server index (flag 0x40) bits. Those of
+	// EventTypeNode are discarded.
+	SourceNamespaceURI string
+	SourceServerIndex  uint32
+
 	// TraceContext (bit 3); SpanID == 0 means no trace context
 	TraceIDBytes     [16]byte // raw W3C byte order (same as Guid wire bytes)
 	SpanID           uint64   // big-endian uint64 value of W3C SpanId
@@ -70,7 +84,13 @@ const unixToOpcuaTicksOffset int64 = 116444736000000000
 
 // Decode implements the gopcua codec interface for binary deserialization.
 // Field order matches OPC UA Part 26 §5.4 with all optional fields present (mask=0x1F).
+// JSON-encoded bodies are passed on to DecodeJSON, so records are decoded
+// regardless of the encoding the server chose.
 func (l *LogRecordExtObj) Decode(b []byte) (int, error) {
+	if isJSON(b) {
+		return len(b), l.DecodeJSON(b)
+	}
+
 	buf := ua.NewBuffer(b)
 
 	// 1. DateTime: Int64 (100ns ticks since 1601-01-01)
@@ -84,10 +104,20 @@ func (l *LogRecordExtObj) Decode(b []byte) (int, error) {
 	l.Severity = buf.ReadUint16()
 
 	// 3. NodeId: EventType (OPC UA binary NodeId encoding)
-	l.EventTypeNode = readNodeIDFromBuffer(buf)
+	eventType, err := readNodeIDFromBuffer(buf)
+	if err != nil {
+		return buf.Pos(), fmt.Errorf("EventType: %w", err)
+	}
+	l.EventTypeNode = eventType.NodeID
 
 	// 4. NodeId: SourceNode (OPC UA binary NodeId encoding)
-	l.SourceNode = readNodeIDFromBuffer(buf)
+	sourceNode, err := readNodeIDFromBuffer(buf)
+	if err != nil {
+		return buf.Pos(), fmt.Errorf("SourceNode: %w", err)
+	}
+	l.SourceNode = sourceNode.NodeID
+	l.SourceNamespaceURI = sourceNode.NamespaceURI
+	l.SourceServerIndex = sourceNode.ServerIndex
 
 	// 5. String: SourceName
 	l.SourceName = buf.ReadString()
@@ -99,7 +129,7 @@ func (l *LogRecordExtObj) Decode(b []byte) (int, error) {
 	//   If bit 1: String (text)
 	encodingMask := buf.ReadByte()
 	if encodingMask&0x01 != 0 {
-		_ = buf.ReadString() // locale – discard
+		l.MessageLocale = buf.ReadString()
 	}
 	if encodingMask&0x02 != 0 {
 		l.Message = buf.ReadString()
@@ -125,10 +155,11 @@ func (l *LogRecordExtObj) Decode(b []byte) (int, error) {
 
 	// 8. AdditionalData: NameValuePair[]
 	//    Int32 count (encoded as UInt32, -1 = null array interpreted as 0)
+	//    Stop at the first read error so a corrupt count cannot spin the loop.
 	count := int32(buf.ReadUint32()) //nolint:gosec
-	if count > 0 {
-		l.AdditionalData = make(map[string]interface{}, count)
-		for i := int32(0); i < count; i++ {
+	if count > 0 && buf.Error() == nil {
+		l.AdditionalData = make(map[string]interface{})
+		for i := int32(0); i < count && buf.Error() == nil; i++ {
 			name := buf.ReadString()
 			value := readVariantValue(buf)
 			if name != "" {
@@ -142,7 +173,13 @@ func (l *LogRecordExtObj) Decode(b []byte) (int, error) {
 
 // Encode implements the gopcua codec interface for binary serialization.
 func (l *LogRecordExtObj) Encode() ([]byte, error) {
-	buf := ua.NewBuffer(nil)
+	return l.AppendEncode(nil)
+}
+
+// AppendEncode appends the binary encoding of the record to dst, so callers
+// can encode into a reused buffer
+func (l *LogRecordExtObj) AppendEncode(dst []byte) ([]byte, error) {
+	buf := ua.NewBuffer(dst)
 
 	// 1. DateTime
 	ticks := l.Time.UnixNano()/100 + unixToOpcuaTicksOffset
@@ -152,16 +189,21 @@ func (l *LogRecordExtObj) Encode() ([]byte, error) {
 	buf.WriteUint16(l.Severity)
 
 	// 3. NodeId: EventType
-	writeNodeIDToBuffer(buf, l.EventTypeNode)
+	writeNodeIDToBuffer(buf, l.EventTypeNode, "", 0)
 
 	// 4. NodeId: SourceNode
-	writeNodeIDToBuffer(buf, l.SourceNode)
+	writeNodeIDToBuffer(buf, l.SourceNode, l.SourceNamespaceURI, l.SourceServerIndex)
 
 	// 5. String: SourceName
 	buf.WriteString(l.SourceName)
 
-	// 6. LocalizedText: Message (text only, no locale)
-	buf.WriteByte(0x02) // encoding mask: has text only
+	// 6. LocalizedText: Message, with the locale if set
+	if l.MessageLocale != "" {
+		buf.WriteByte(0x03) // encoding mask: has locale and text
+		buf.WriteString(l.MessageLocale)
+	} else {
+		buf.WriteByte(0x02) // encoding mask: has text only
+	}
 	buf.WriteString(l.Message)
 
 	// 7. TraceContext: Guid + UInt64 + UInt64 + String
@@ -218,141 +260,152 @@ func (l *LogRecordExtObj) SpanIDHex() string {
 	return fmt.Sprintf("%016x", l.SpanID)
 }
 
+// ParentSpanIDHex returns the ParentSpanId as a 16-character lowercase hex
+// string (W3C format). Returns an empty string for a root span or when no
+// trace context is present.
+func (l *LogRecordExtObj) ParentSpanIDHex() string {
+	if l.SpanID == 0 || l.ParentSpanID == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%016x", l.ParentSpanID)
+}
+
 // --- NodeId binary helpers ---
 
-// readNodeIDFromBuffer decodes an OPC UA binary-encoded NodeId from buf.
+// NodeId encoding byte flags of the expanded NodeId parts, which follow the
+// identifier in this order
+const (
+	nodeIDNamespaceURIFlag = 0x80
+	nodeIDServerIndexFlag  = 0x40
+)
+
+// readNodeIDFromBuffer decodes an OPC UA binary-encoded NodeId from buf,
+// including the namespace URI and server index of an ExpandedNodeId.
 // Encoding byte format (low nibble):
 //
 //	0x00 TwoByte  – 1 additional byte (Byte identifier, ns=0)
 //	0x01 FourByte – 1 byte namespace (Byte) + 2 byte identifier (UInt16)
 //	0x02 Numeric  – 2 byte namespace (UInt16) + 4 byte identifier (UInt32)
 //	0x03 String   – 2 byte namespace + OPC UA String
-func readNodeIDFromBuffer(buf *ua.Buffer) *ua.NodeID {
+//	0x04 Guid     – 2 byte namespace + Guid (16 bytes)
+//	0x05 Opaque   – 2 byte namespace + OPC UA ByteString
+//
+// Bit 7 adds an OPC UA String namespace URI, bit 6 a UInt32 server index.
+// Other encodings are an error, as the length of their identifier is unknown.
+func readNodeIDFromBuffer(buf *ua.Buffer) (*ua.ExpandedNodeID, error) {
 	encodingByte := buf.ReadByte()
-	encodingType := encodingByte & 0x0F
-	switch encodingType {
+	expanded := &ua.ExpandedNodeID{}
+	switch encodingByte & 0x0F {
 	case 0x00: // TwoByte
 		id := uint32(buf.ReadByte())
-		return ua.NewNumericNodeID(0, id)
+		expanded.NodeID = ua.NewNumericNodeID(0, id)
 	case 0x01: // FourByte
 		ns := uint16(buf.ReadByte())
 		id := uint32(buf.ReadUint16())
-		return ua.NewNumericNodeID(ns, id)
+		expanded.NodeID = ua.NewNumericNodeID(ns, id)
 	case 0x02: // Numeric
 		ns := buf.ReadUint16()
 		id := buf.ReadUint32()
-		return ua.NewNumericNodeID(ns, id)
+		expanded.NodeID = ua.NewNumericNodeID(ns, id)
 	case 0x03: // String
 		ns := buf.ReadUint16()
 		s := buf.ReadString()
-		return ua.NewStringNodeID(ns, s)
+		expanded.NodeID = ua.NewStringNodeID(ns, s)
+	case 0x04: // Guid
+		ns := buf.ReadUint16()
+		g := &ua.GUID{
+			Data1: buf.ReadUint32(),
+			Data2: buf.ReadUint16(),
+			Data3: buf.ReadUint16(),
+			Data4: append([]byte(nil), buf.ReadN(8)...),
+		}
+		if buf.Error() != nil {
+			return nil, buf.Error()
+		}
+		expanded.NodeID = ua.NewGUIDNodeID(ns, g.String())
+	case 0x05: // ByteString
+		ns := buf.ReadUint16()
+		b := buf.ReadBytes()
+		expanded.NodeID = ua.NewByteStringNodeID(ns, append([]byte(nil), b...))
 	default:
-		// For GUID (0x04) and ByteString (0x05) we return null – unexpected in test data
-		return ua.NewNumericNodeID(0, 0)
+		if err := buf.Error(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unknown NodeId encoding 0x%02x", encodingByte)
+	}
+
+	if encodingByte&nodeIDNamespaceURIFlag != 0 {
+		expanded.NamespaceURI = buf.ReadString()
 	}
+	if encodingByte&nodeIDServerIndexFlag != 0 {
+		expanded.ServerIndex = buf.ReadUint32()
+	}
+	return expanded, buf.Error()
 }
 
-// writeNodeIDToBuffer encodes a NodeId in OPC UA binary format to buf.
-// Null or nil NodeIds are written as TwoByte with identifier 0.
-func writeNodeIDToBuffer(buf *ua.Buffer, nodeID *ua.NodeID) {
-	if nodeID == nil || (nodeID.Namespace() == 0 && nodeID.IntID() == 0) {
-		// Null NodeId: TwoByte encoding, id=0
+// writeNodeIDToBuffer encodes a NodeId in OPC UA binary format to buf, as an
+// ExpandedNodeId if namespaceURI or serverIndex are set.
+// Nil NodeIds are written as the null NodeId: TwoByte with identifier 0.
+func writeNodeIDToBuffer(buf *ua.Buffer, nodeID *ua.NodeID, namespaceURI string, serverIndex uint32) {
+	if nodeID == nil {
 		buf.WriteByte(0x00)
 		buf.WriteByte(0x00)
 		return
 	}
+
+	var flags byte
+	if namespaceURI != "" {
+		flags |= nodeIDNamespaceURIFlag
+	}
+	if serverIndex != 0 {
+		flags |= nodeIDServerIndexFlag
+	}
+
 	switch nodeID.Type() {
 	case ua.NodeIDTypeString:
-		buf.WriteByte(0x03)
+		buf.WriteByte(0x03 | flags)
 		buf.WriteUint16(nodeID.Namespace())
 		buf.WriteString(nodeID.StringID())
+	case ua.NodeIDTypeGUID:
+		g := ua.NewGUID(nodeID.StringID())
+		if g == nil || len(g.Data4) != 8 {
+			g = &ua.GUID{Data4: make([]byte, 8)}
+		}
+		buf.WriteByte(0x04 | flags)
+		buf.WriteUint16(nodeID.Namespace())
+		buf.WriteUint32(g.Data1)
+		buf.WriteUint16(g.Data2)
+		buf.WriteUint16(g.Data3)
+		for _, b := range g.Data4 {
+			buf.WriteByte(b)
+		}
+	case ua.NodeIDTypeByteString:
+		// StringID returns the identifier base64-encoded
+		b, _ := base64.StdEncoding.DecodeString(nodeID.StringID())
+		buf.WriteByte(0x05 | flags)
+		buf.WriteUint16(nodeID.Namespace())
+		buf.WriteByteString(b)
 	default: // Numeric (TwoByte, FourByte, Numeric)
 		ns := nodeID.Namespace()
 		id := nodeID.IntID()
 		if ns == 0 && id <= 0xFF {
-			buf.WriteByte(0x00) // TwoByte
+			buf.WriteByte(0x00 | flags) // TwoByte
 			buf.WriteByte(byte(id))
 		} else if ns <= 0xFF && id <= 0xFFFF {
-			buf.WriteByte(0x01) // FourByte
+			buf.WriteByte(0x01 | flags) // FourByte
 			buf.WriteByte(byte(ns))
 			buf.WriteUint16(uint16(id))
 		} else {
-			buf.WriteByte(0x02) // Numeric
+			buf.WriteByte(0x02 | flags) // Numeric
 			buf.WriteUint16(ns)
 			buf.WriteUint32(id)
 		}
 	}
-}
 
-// --- Variant helpers for AdditionalData ---
-
-// readVariantValue reads a single OPC UA Variant scalar value from buf.
-// Supports the types used in test AdditionalData (String, integers, float64).
-// Returns nil for unsupported or null types.
-func readVariantValue(buf *ua.Buffer) interface{} {
-	typeByte := buf.ReadByte()
-	typeID := typeByte & 0x3F // low 6 bits = built-in type ID
-	switch typeID {
-	case 1: // Boolean
-		return buf.ReadByte() != 0
-	case 2: // SByte
-		return int8(buf.ReadByte()) //nolint:gosec
-	case 3: // Byte
-		return buf.ReadByte()
-	case 4: // Int16
-		return int16(buf.ReadUint16()) //nolint:gosec
-	case 5: // UInt16
-		return buf.ReadUint16()
-	case 6: // Int32
-		return int32(buf.ReadUint32()) //nolint:gosec
-	case 7: // UInt32
-		return buf.ReadUint32()
-	case 8: // Int64
-		return buf.ReadInt64()
-	case 9: // UInt64
-		return uint64(buf.ReadInt64()) //nolint:gosec
-	case 10: // Float
-		return buf.ReadFloat32()
-	case 11: // Double
-		return buf.ReadFloat64()
-	case 12: // String
-		return buf.ReadString()
-	default:
-		return nil
+	if namespaceURI != "" {
+		buf.WriteString(namespaceURI)
 	}
-}
-
-// writeVariantValue writes a single OPC UA Variant scalar value to buf.
-// Supports string, bool, integer and float types.
-func writeVariantValue(buf *ua.Buffer, value interface{}) {
-	switch v := value.(type) {
-	case string:
-		buf.WriteByte(12)
-		buf.WriteString(v)
-	case bool:
-		buf.WriteByte(1)
-		if v {
-			buf.WriteByte(1)
-		} else {
-			buf.WriteByte(0)
-		}
-	case int:
-		buf.WriteByte(6) // Int32
-		buf.WriteUint32(uint32(v))
-	case int32:
-		buf.WriteByte(6)
-		buf.WriteUint32(uint32(v))
-	case int64:
-		buf.WriteByte(8)
-		buf.WriteInt64(v)
-	case uint32:
-		buf.WriteByte(7)
-		buf.WriteUint32(v)
-	case float64:
-		buf.WriteByte(11) // Double
-		buf.WriteFloat64(v)
-	default:
-		// Fallback: write as null (type 0)
-		buf.WriteByte(0)
+	if serverIndex != 0 {
+		buf.WriteUint32(serverIndex)
 	}
 }
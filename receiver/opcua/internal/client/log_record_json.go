@@ -0,0 +1,442 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// jsonLogRecord is the OPC UA JSON encoding of a Part 26 LogRecord
+// (OPC UA Part 6 §5.4). Fields that are absent keep their zero value.
+type jsonLogRecord struct {
+	Time           string            `json:"Time"`
+	Severity       uint16            `json:"Severity"`
+	EventType      json.RawMessage   `json:"EventType"`
+	SourceNode     json.RawMessage   `json:"SourceNode"`
+	SourceName     string            `json:"SourceName"`
+	Message        json.RawMessage   `json:"Message"`
+	TraceContext   *jsonTraceContext `json:"TraceContext"`
+	AdditionalData []jsonNameValue   `json:"AdditionalData"`
+}
+
+// jsonTraceContext is the JSON encoding of a TraceContextDataType. UInt64
+// values are encoded as decimal strings, some encoders write numbers.
+type jsonTraceContext struct {
+	TraceID          string          `json:"TraceId"`
+	SpanID           json.RawMessage `json:"SpanId"`
+	ParentSpanID     json.RawMessage `json:"ParentSpanId"`
+	ParentIdentifier string          `json:"ParentIdentifier"`
+}
+
+// jsonNameValue is the JSON encoding of a NameValuePair
+type jsonNameValue struct {
+	Name  string          `json:"Name"`
+	Value json.RawMessage `json:"Value"`
+}
+
+// jsonExtensionObject is the JSON encoding of an ExtensionObject wrapping a
+// LogRecord: Body in the 1.04 encoding, UaBody in the 1.05 encoding
+type jsonExtensionObject struct {
+	Body   json.RawMessage `json:"Body"`
+	UaBody json.RawMessage `json:"UaBody"`
+}
+
+// isJSON reports whether b is a JSON-encoded body rather than a binary one.
+// A binary LogRecord starts with a DateTime, which cannot make the whole
+// body a valid JSON object.
+func isJSON(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{' && json.Valid(b)
+}
+
+// DecodeJSON decodes the OPC UA JSON encoding of a LogRecord, either the
+// record itself or an ExtensionObject with the record as its body. Both the
+// reversible and the non-reversible encoding are accepted.
+func (l *LogRecordExtObj) DecodeJSON(b []byte) error {
+	var wrapper jsonExtensionObject
+	if err := json.Unmarshal(b, &wrapper); err != nil {
+		return fmt.Errorf("invalid JSON LogRecord: %w", err)
+	}
+	switch {
+	case len(wrapper.UaBody) > 0:
+		b = wrapper.UaBody
+	case len(wrapper.Body) > 0:
+		b = wrapper.Body
+	}
+
+	var record jsonLogRecord
+	if err := json.Unmarshal(b, &record); err != nil {
+		return fmt.Errorf("invalid JSON LogRecord: %w", err)
+	}
+
+	*l = LogRecordExtObj{
+		Severity:   record.Severity,
+		SourceName: record.SourceName,
+	}
+	l.Message, l.MessageLocale = jsonLocalizedText(record.Message)
+	if record.Time != "" {
+		t, err := time.Parse(time.RFC3339Nano, record.Time)
+		if err != nil {
+			return fmt.Errorf("invalid LogRecord Time: %w", err)
+		}
+		l.Time = t.UTC()
+	}
+
+	var err error
+	if l.EventTypeNode, err = jsonNodeID(record.EventType); err != nil {
+		return fmt.Errorf("invalid LogRecord EventType: %w", err)
+	}
+	if l.SourceNode, err = jsonNodeID(record.SourceNode); err != nil {
+		return fmt.Errorf("invalid LogRecord SourceNode: %w", err)
+	}
+
+	if tc := record.TraceContext; tc != nil {
+		if err := l.decodeJSONTraceContext(tc); err != nil {
+			return fmt.Errorf("invalid LogRecord TraceContext: %w", err)
+		}
+	}
+
+	for _, pair := range record.AdditionalData {
+		if pair.Name == "" {
+			continue
+		}
+		if l.AdditionalData == nil {
+			l.AdditionalData = make(map[string]interface{}, len(record.AdditionalData))
+		}
+		l.AdditionalData[pair.Name] = jsonVariantValue(pair.Value)
+	}
+	return nil
+}
+
+// decodeJSONTraceContext sets the trace context fields from tc. The TraceId
+// Guid is stored in the byte order of its binary encoding.
+func (l *LogRecordExtObj) decodeJSONTraceContext(tc *jsonTraceContext) error {
+	if tc.TraceID != "" {
+		g := ua.NewGUID(tc.TraceID)
+		if g == nil || len(g.Data4) != 8 {
+			return fmt.Errorf("TraceId %q is not a Guid", tc.TraceID)
+		}
+		binary.LittleEndian.PutUint32(l.TraceIDBytes[0:4], g.Data1)
+		binary.LittleEndian.PutUint16(l.TraceIDBytes[4:6], g.Data2)
+		binary.LittleEndian.PutUint16(l.TraceIDBytes[6:8], g.Data3)
+		copy(l.TraceIDBytes[8:], g.Data4)
+	}
+
+	var err error
+	if l.SpanID, err = jsonUint64(tc.SpanID); err != nil {
+		return fmt.Errorf("SpanId: %w", err)
+	}
+	if l.ParentSpanID, err = jsonUint64(tc.ParentSpanID); err != nil {
+		return fmt.Errorf("ParentSpanId: %w", err)
+	}
+	l.ParentIdentifier = tc.ParentIdentifier
+	return nil
+}
+
+// jsonUint64 decodes a UInt64, which is a decimal string or a number; an
+// absent value is 0
+func jsonUint64(raw json.RawMessage) (uint64, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		s = string(raw)
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// jsonLocalizedText returns the text and locale of a LocalizedText, which is
+// an object with Locale and Text or, non-reversibly encoded, the text itself
+// without a locale
+func jsonLocalizedText(raw json.RawMessage) (text, locale string) {
+	if len(raw) == 0 {
+		return "", ""
+	}
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, ""
+	}
+	var lt struct {
+		Locale string `json:"Locale"`
+		Text   string `json:"Text"`
+	}
+	_ = json.Unmarshal(raw, &lt)
+	return lt.Text, lt.Locale
+}
+
+// jsonNodeID decodes a NodeId, which is a string in NodeId notation (1.05)
+// or an object with IdType, Id and Namespace (1.04). An absent or null
+// NodeId is nil.
+func jsonNodeID(raw json.RawMessage) (*ua.NodeID, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return ua.ParseNodeID(s)
+	}
+
+	var obj struct {
+		IDType    int             `json:"IdType"`
+		ID        json.RawMessage `json:"Id"`
+		Namespace uint16          `json:"Namespace"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, err
+	}
+	if obj.IDType == 0 {
+		id, err := jsonUint64(obj.ID)
+		if err != nil || id > math.MaxUint32 {
+			return nil, fmt.Errorf("numeric identifier %s is not a UInt32", obj.ID)
+		}
+		return ua.NewNumericNodeID(obj.Namespace, uint32(id)), nil
+	}
+
+	var id string
+	if err := json.Unmarshal(obj.ID, &id); err != nil {
+		return nil, fmt.Errorf("identifier %s is not a string", obj.ID)
+	}
+	prefix := [...]string{"", "s", "g", "b"}
+	if obj.IDType < 0 || obj.IDType >= len(prefix) {
+		return nil, fmt.Errorf("unknown IdType %d", obj.IDType)
+	}
+	return ua.ParseNodeID(fmt.Sprintf("ns=%d;%s=%s", obj.Namespace, prefix[obj.IDType], id))
+}
+
+// jsonVariantValue decodes a Variant to the types readVariantValue returns.
+// Reversibly encoded Variants carry their built-in type, as Type and Body
+// (1.04) or UaType and Value (1.05), and optionally the Dimensions of a
+// multi-dimensional array; other values are mapped by their JSON type (see
+// jsonPlainValue). Returns nil for unsupported or null values.
+func jsonVariantValue(raw json.RawMessage) interface{} {
+	var variant struct {
+		Type       *byte           `json:"Type"`
+		Body       json.RawMessage `json:"Body"`
+		UaType     *byte           `json:"UaType"`
+		Value      json.RawMessage `json:"Value"`
+		Dimensions []int32         `json:"Dimensions"`
+	}
+	if err := json.Unmarshal(raw, &variant); err == nil {
+		switch {
+		case variant.UaType != nil:
+			return jsonTypedArray(*variant.UaType, variant.Value, variant.Dimensions)
+		case variant.Type != nil:
+			return jsonTypedArray(*variant.Type, variant.Body, variant.Dimensions)
+		}
+	}
+	return jsonPlainValue(raw)
+}
+
+// jsonTypedArray decodes the body of a reversibly encoded Variant, which is
+// a JSON array for array Variants
+func jsonTypedArray(typeID byte, raw json.RawMessage, dims []int32) interface{} {
+	var elements []json.RawMessage
+	if err := json.Unmarshal(raw, &elements); err != nil || typeID == 0 {
+		return jsonTypedValue(typeID, raw)
+	}
+	values := make([]interface{}, len(elements))
+	for i, element := range elements {
+		values[i] = jsonTypedValue(typeID, element)
+	}
+	if len(dims) > 1 {
+		if matrix, ok := reshape(values, dims); ok {
+			return matrix
+		}
+	}
+	return values
+}
+
+// jsonPlainValue maps a non-reversibly encoded value by its JSON type:
+// numbers to int64 if they are integral and float64 otherwise, arrays to
+// []interface{} and objects to map[string]interface{}
+func jsonPlainValue(raw json.RawMessage) interface{} {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil
+	}
+	return plainValue(value)
+}
+
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string, bool:
+		return v
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case []interface{}:
+		for i, element := range v {
+			v[i] = plainValue(element)
+		}
+		return v
+	case map[string]interface{}:
+		for name, field := range v {
+			v[name] = plainValue(field)
+		}
+		return v
+	default:
+		return nil
+	}
+}
+
+// jsonTypedValue decodes the body of a Variant of built-in type typeID
+func jsonTypedValue(typeID byte, raw json.RawMessage) interface{} {
+	switch typeID {
+	case 1: // Boolean
+		var v bool
+		if json.Unmarshal(raw, &v) == nil {
+			return v
+		}
+	case 2, 4, 6, 8: // SByte, Int16, Int32, Int64
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw)
+		}
+		bits := map[byte]int{2: 8, 4: 16, 6: 32, 8: 64}[typeID]
+		v, err := strconv.ParseInt(s, 10, bits)
+		if err != nil {
+			return nil
+		}
+		switch typeID {
+		case 2:
+			return int8(v)
+		case 4:
+			return int16(v)
+		case 6:
+			return int32(v)
+		default:
+			return v
+		}
+	case 3, 5, 7, 9: // Byte, UInt16, UInt32, UInt64
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			s = string(raw)
+		}
+		bits := map[byte]int{3: 8, 5: 16, 7: 32, 9: 64}[typeID]
+		v, err := strconv.ParseUint(s, 10, bits)
+		if err != nil {
+			return nil
+		}
+		switch typeID {
+		case 3:
+			return uint8(v)
+		case 5:
+			return uint16(v)
+		case 7:
+			return uint32(v)
+		default:
+			return v
+		}
+	case 10, 11: // Float, Double
+		f, ok := jsonFloat(raw)
+		if !ok {
+			return nil
+		}
+		if typeID == 10 {
+			return float32(f)
+		}
+		return f
+	case 12, 14, 16: // String, Guid, XmlElement
+		var v string
+		if json.Unmarshal(raw, &v) == nil {
+			return v
+		}
+	case 13: // DateTime
+		var v string
+		if json.Unmarshal(raw, &v) != nil {
+			return nil
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UTC()
+		}
+	case 15: // ByteString, base64 encoded
+		var v []byte
+		if json.Unmarshal(raw, &v) == nil {
+			return v
+		}
+	case 17, 18: // NodeId, ExpandedNodeId
+		if nodeID, err := jsonNodeID(raw); err == nil && nodeID != nil {
+			return nodeID.String()
+		}
+		// ExpandedNodeIds with a namespace URI are kept in the notation the server sent
+		var v string
+		if json.Unmarshal(raw, &v) == nil {
+			return v
+		}
+	case 19: // StatusCode, a number or an object with Code
+		var code struct {
+			Code uint32 `json:"Code"`
+		}
+		if json.Unmarshal(raw, &code.Code) == nil || json.Unmarshal(raw, &code) == nil {
+			return code.Code
+		}
+	case 20: // QualifiedName, "ns:name" or an object with Name and Uri
+		var name struct {
+			Name string          `json:"Name"`
+			URI  json.RawMessage `json:"Uri"`
+		}
+		var v string
+		if json.Unmarshal(raw, &v) == nil {
+			return v
+		}
+		if json.Unmarshal(raw, &name) != nil {
+			return nil
+		}
+		if ns, err := jsonUint64(name.URI); err == nil && ns != 0 {
+			return fmt.Sprintf("%d:%s", ns, name.Name)
+		}
+		return name.Name
+	case 21: // LocalizedText
+		text, _ := jsonLocalizedText(raw)
+		return text
+	case 22: // ExtensionObject
+		return jsonPlainValue(raw)
+	case 23: // DataValue
+		var dataValue struct {
+			Value json.RawMessage `json:"Value"`
+		}
+		if json.Unmarshal(raw, &dataValue) == nil && len(dataValue.Value) > 0 {
+			return jsonVariantValue(dataValue.Value)
+		}
+	case 24: // Variant
+		return jsonVariantValue(raw)
+	}
+	return nil
+}
+
+// jsonFloat decodes a Float or Double, which is a number or one of the
+// strings "NaN", "Infinity" and "-Infinity"
+func jsonFloat(raw json.RawMessage) (float64, bool) {
+	var f float64
+	if err := json.Unmarshal(raw, &f); err == nil {
+		return f, true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0, false
+	}
+	switch s {
+	case "NaN":
+		return math.NaN(), true
+	case "Infinity":
+		return math.Inf(1), true
+	case "-Infinity":
+		return math.Inf(-1), true
+	}
+	return 0, false
+}
@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRecordExtObjDecodeJSON_Reversible(t *testing.T) {
+	// OPC UA 1.04 reversible encoding
+	body := `{
+		"Time": "2025-01-15T10:00:00.125Z",
+		"Severity": 300,
+		"EventType": {"Id": 2041},
+		"SourceNode": {"IdType": 1, "Id": "Line1.Press3", "Namespace": 2},
+		"SourceName": "Press3",
+		"Message": {"Locale": "en-US", "Text": "Pressure high"},
+		"TraceContext": {
+			"TraceId": "04030201-0605-0807-090a-0b0c0d0e0f10",
+			"SpanId": "1234605616436508552",
+			"ParentSpanId": "0",
+			"ParentIdentifier": "plc-1"
+		},
+		"AdditionalData": [
+			{"Name": "bar", "Value": {"Type": 11, "Body": 4.25}},
+			{"Name": "count", "Value": {"Type": 6, "Body": 42}},
+			{"Name": "total", "Value": {"Type": 8, "Body": "9007199254740993"}},
+			{"Name": "ok", "Value": {"Type": 1, "Body": true}},
+			{"Name": "operator", "Value": {"Type": 12, "Body": "alice"}},
+			{"Name": "ratio", "Value": {"Type": 10, "Body": "NaN"}},
+			{"Name": "", "Value": {"Type": 12, "Body": "unnamed"}}
+		]
+	}`
+
+	lr := &LogRecordExtObj{}
+	require.NoError(t, lr.DecodeJSON([]byte(body)))
+
+	assert.Equal(t, time.Date(2025, 1, 15, 10, 0, 0, 125000000, time.UTC), lr.Time)
+	assert.Equal(t, uint16(300), lr.Severity)
+	assert.Equal(t, "Pressure high", lr.Message)
+	assert.Equal(t, "en-US", lr.MessageLocale)
+	assert.Equal(t, "Press3", lr.SourceName)
+	assert.Equal(t, "i=2041", lr.EventTypeNode.String())
+	assert.Equal(t, "ns=2;s=Line1.Press3", lr.SourceNode.String())
+	assert.Equal(t, "0102030405060708090a0b0c0d0e0f10", lr.TraceIDHex())
+	assert.Equal(t, "1122334455667788", lr.SpanIDHex())
+	assert.Equal(t, uint64(0), lr.ParentSpanID)
+	assert.Equal(t, "plc-1", lr.ParentIdentifier)
+
+	require.Len(t, lr.AdditionalData, 6)
+	assert.Equal(t, 4.25, lr.AdditionalData["bar"])
+	assert.Equal(t, int32(42), lr.AdditionalData["count"])
+	assert.Equal(t, int64(9007199254740993), lr.AdditionalData["total"])
+	assert.Equal(t, true, lr.AdditionalData["ok"])
+	assert.Equal(t, "alice", lr.AdditionalData["operator"])
+	ratio, ok := lr.AdditionalData["ratio"].(float32)
+	require.True(t, ok)
+	assert.True(t, math.IsNaN(float64(ratio)))
+}
+
+func TestLogRecordExtObjDecodeJSON_NonReversible(t *testing.T) {
+	// OPC UA 1.05 notation inside an ExtensionObject, non-reversible values
+	body := `{
+		"UaTypeId": "i=5001",
+		"UaBody": {
+			"Time": "2025-01-15T10:00:00Z",
+			"Severity": 700,
+			"SourceNode": "ns=3;i=1002",
+			"Message": "Motor overheated",
+			"AdditionalData": [
+				{"Name": "temperature", "Value": 96.5},
+				{"Name": "motor", "Value": 7},
+				{"Name": "unit", "Value": "C"},
+				{"Name": "typed", "Value": {"UaType": 5, "Value": 8}},
+				{"Name": "limits", "Value": [90, 100]}
+			]
+		}
+	}`
+
+	lr := &LogRecordExtObj{}
+	require.NoError(t, lr.DecodeJSON([]byte(body)))
+
+	assert.Equal(t, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), lr.Time)
+	assert.Equal(t, uint16(700), lr.Severity)
+	assert.Equal(t, "Motor overheated", lr.Message)
+	assert.Empty(t, lr.MessageLocale)
+	assert.Equal(t, "ns=3;i=1002", lr.SourceNode.String())
+	assert.Nil(t, lr.EventTypeNode)
+	assert.Empty(t, lr.TraceIDHex())
+
+	assert.Equal(t, 96.5, lr.AdditionalData["temperature"])
+	assert.Equal(t, int64(7), lr.AdditionalData["motor"])
+	assert.Equal(t, "C", lr.AdditionalData["unit"])
+	assert.Equal(t, uint16(8), lr.AdditionalData["typed"])
+	assert.Equal(t, []interface{}{int64(90), int64(100)}, lr.AdditionalData["limits"])
+}
+
+func TestLogRecordExtObjDecodeJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "not JSON", body: `{"Time": `, wantErr: "invalid JSON LogRecord"},
+		{name: "bad time", body: `{"Time": "yesterday"}`, wantErr: "invalid LogRecord Time"},
+		{name: "bad source node", body: `{"SourceNode": {"IdType": 7, "Id": "x"}}`, wantErr: "unknown IdType 7"},
+		{name: "bad trace id", body: `{"TraceContext": {"TraceId": "abc"}}`, wantErr: "is not a Guid"},
+		{name: "bad span id", body: `{"TraceContext": {"SpanId": "-1"}}`, wantErr: "SpanId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&LogRecordExtObj{}).DecodeJSON([]byte(tt.body))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestLogRecordExtObjDecodeDetectsEncoding(t *testing.T) {
+	binary, err := (&LogRecordExtObj{
+		Time:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity: 150,
+		Message:  "binary",
+	}).Encode()
+	require.NoError(t, err)
+	json := []byte(` {"Time": "2025-01-15T10:00:00Z", "Severity": 150, "Message": {"Text": "json"}}`)
+
+	for _, body := range [][]byte{binary, json} {
+		lr := &LogRecordExtObj{}
+		n, err := lr.Decode(body)
+		require.NoError(t, err)
+		assert.Equal(t, len(body), n)
+		assert.Equal(t, uint16(150), lr.Severity)
+		assert.True(t, lr.Time.Equal(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)))
+	}
+}
+
+func TestJSONNodeID(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected *ua.NodeID
+	}{
+		{raw: `null`},
+		{raw: `"i=2041"`, expected: ua.NewNumericNodeID(0, 2041)},
+		{raw: `"ns=2;s=Press"`, expected: ua.NewStringNodeID(2, "Press")},
+		{raw: `{"Id": 85}`, expected: ua.NewNumericNodeID(0, 85)},
+		{raw: `{"IdType": 0, "Id": 70000, "Namespace": 4}`, expected: ua.NewNumericNodeID(4, 70000)},
+		{raw: `{"IdType": 3, "Id": "AQID", "Namespace": 1}`, expected: ua.NewByteStringNodeID(1, []byte{1, 2, 3})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			nodeID, err := jsonNodeID([]byte(tt.raw))
+			require.NoError(t, err)
+			if tt.expected == nil {
+				assert.Nil(t, nodeID)
+				return
+			}
+			assert.Equal(t, tt.expected.String(), nodeID.String())
+		})
+	}
+}
@@ -0,0 +1,813 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// fixedTraceIDBytes returns the W3C TraceId bytes for "0102030405060708090a0b0c0d0e0f10".
+func fixedTraceIDBytes() [16]byte {
+	return [16]byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+		0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f, 0x10}
+}
+
+func TestLogRecordExtObjRoundTrip(t *testing.T) {
+	original := &LogRecordExtObj{
+		Time:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:   300,
+		Message:    "Test message",
+		SourceName: "TestSource",
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+	require.NotEmpty(t, encoded)
+
+	decoded := &LogRecordExtObj{}
+	n, err := decoded.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, len(encoded), n)
+
+	assert.True(t, original.Time.Equal(decoded.Time), "timestamps should match: want %v, got %v", original.Time, decoded.Time)
+	assert.Equal(t, original.Severity, decoded.Severity)
+	assert.Equal(t, original.Message, decoded.Message)
+	assert.Equal(t, original.SourceName, decoded.SourceName)
+}
+
+func TestLogRecordExtObjDecodeFixedRecords(t *testing.T) {
+	// Test all 10 fixed records from the C# test server
+	records := []struct {
+		time     time.Time
+		severity uint16
+		message  string
+		source   string
+	}{
+		{time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), 150, "System startup initiated", "SystemComponent"},
+		{time.Date(2025, 1, 15, 10, 1, 0, 0, time.UTC), 300, "Configuration loaded successfully", "SystemComponent"},
+		{time.Date(2025, 1, 15, 10, 2, 0, 0, time.UTC), 300, "Connection established to database", "NetworkModule"},
+		{time.Date(2025, 1, 15, 10, 3, 0, 0, time.UTC), 150, "Data processing pipeline started", "DataLogger"},
+		{time.Date(2025, 1, 15, 10, 4, 0, 0, time.UTC), 300, "Sensor reading collected: temperature=22.5", "DataLogger"},
+		{time.Date(2025, 1, 15, 10, 5, 0, 0, time.UTC), 500, "High memory usage detected: 85%", "SystemComponent"},
+		{time.Date(2025, 1, 15, 10, 6, 0, 0, time.UTC), 300, "Security scan completed", "SecurityModule"},
+		{time.Date(2025, 1, 15, 10, 7, 0, 0, time.UTC), 700, "Connection timeout to external service", "NetworkModule"},
+		{time.Date(2025, 1, 15, 10, 8, 0, 0, time.UTC), 300, "Backup process completed", "DataLogger"},
+		{time.Date(2025, 1, 15, 10, 9, 0, 0, time.UTC), 150, "Garbage collection cycle completed", "SystemComponent"},
+	}
+
+	for _, rec := range records {
+		t.Run(rec.message, func(t *testing.T) {
+			original := &LogRecordExtObj{
+				Time:       rec.time,
+				Severity:   rec.severity,
+				Message:    rec.message,
+				SourceName: rec.source,
+			}
+
+			encoded, err := original.Encode()
+			require.NoError(t, err)
+
+			decoded := &LogRecordExtObj{}
+			_, err = decoded.Decode(encoded)
+			require.NoError(t, err)
+
+			assert.True(t, rec.time.Equal(decoded.Time))
+			assert.Equal(t, rec.severity, decoded.Severity)
+			assert.Equal(t, rec.message, decoded.Message)
+			assert.Equal(t, rec.source, decoded.SourceName)
+		})
+	}
+}
+
+func TestLogRecordExtObjDecodeEmptyFields(t *testing.T) {
+	original := &LogRecordExtObj{
+		Time:       time.Time{},
+		Severity:   0,
+		Message:    "",
+		SourceName: "",
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+
+	decoded := &LogRecordExtObj{}
+	_, err = decoded.Decode(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint16(0), decoded.Severity)
+	assert.Equal(t, "", decoded.Message)
+	assert.Equal(t, "", decoded.SourceName)
+}
+
+func TestLogRecordExtObjDecodeUnicodeMessage(t *testing.T) {
+	original := &LogRecordExtObj{
+		Time:       time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC),
+		Severity:   300,
+		Message:    "Temperatur überschritten: Wärmetauscher α-7",
+		SourceName: "Gerät-ÜW",
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+
+	decoded := &LogRecordExtObj{}
+	_, err = decoded.Decode(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.Message, decoded.Message)
+	assert.Equal(t, original.SourceName, decoded.SourceName)
+}
+
+func TestLogRecordExtObjTypeRegistered(t *testing.T) {
+	// Verify that the init() function registered our type with gopcua
+	typeID := ua.NewNumericNodeID(0, 5001)
+	obj := &ua.ExtensionObject{
+		TypeID: &ua.ExpandedNodeID{NodeID: typeID},
+	}
+
+	// The TypeID should match what we registered
+	assert.Equal(t, "i=5001", obj.TypeID.NodeID.String())
+	assert.Equal(t, "i=5001", LogRecordExtObjTypeID.String())
+}
+
+func TestLogRecordExtObjString(t *testing.T) {
+	lr := &LogRecordExtObj{
+		Time:       time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:   300,
+		Message:    "Test",
+		SourceName: "Src",
+	}
+
+	s := lr.String()
+	assert.Contains(t, s, "2025-01-15")
+	assert.Contains(t, s, "300")
+	assert.Contains(t, s, "Test")
+	assert.Contains(t, s, "Src")
+}
+
+// --- New field coverage ---
+
+func TestLogRecordExtObjRoundTrip_FullFields(t *testing.T) {
+	original := &LogRecordExtObj{
+		Time:          time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:      300,
+		Message:       "Full round-trip test",
+		SourceName:    "SystemComponent",
+		SourceNode:    ua.NewNumericNodeID(1, 100),
+		EventTypeNode: ua.NewNumericNodeID(0, 2041),
+		TraceIDBytes:  fixedTraceIDBytes(),
+		SpanID:        0x0102030405060708,
+		ParentSpanID:  0x0000000000000000, // root span
+		AdditionalData: map[string]interface{}{
+			"component": "test",
+			"version":   "1.0.0",
+		},
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+	require.NotEmpty(t, encoded)
+
+	decoded := &LogRecordExtObj{}
+	n, err := decoded.Decode(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, len(encoded), n)
+
+	// Mandatory fields
+	assert.True(t, original.Time.Equal(decoded.Time))
+	assert.Equal(t, original.Severity, decoded.Severity)
+	assert.Equal(t, original.Message, decoded.Message)
+
+	// Optional fields (bit 0–2)
+	assert.Equal(t, original.SourceName, decoded.SourceName)
+	require.NotNil(t, decoded.SourceNode)
+	assert.Equal(t, uint16(1), decoded.SourceNode.Namespace())
+	assert.Equal(t, uint32(100), decoded.SourceNode.IntID())
+	require.NotNil(t, decoded.EventTypeNode)
+	assert.Equal(t, uint16(0), decoded.EventTypeNode.Namespace())
+	assert.Equal(t, uint32(2041), decoded.EventTypeNode.IntID())
+
+	// TraceContext
+	assert.Equal(t, original.TraceIDBytes, decoded.TraceIDBytes)
+	assert.Equal(t, original.SpanID, decoded.SpanID)
+	assert.Equal(t, original.ParentSpanID, decoded.ParentSpanID)
+
+	// AdditionalData
+	require.NotNil(t, decoded.AdditionalData)
+	assert.Equal(t, "test", decoded.AdditionalData["component"])
+	assert.Equal(t, "1.0.0", decoded.AdditionalData["version"])
+}
+
+func TestLogRecordExtObjRoundTrip_NullSourceNode(t *testing.T) {
+	original := &LogRecordExtObj{
+		Time:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity: 150,
+		Message:  "No source node",
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+
+	decoded := &LogRecordExtObj{}
+	_, err = decoded.Decode(encoded)
+	require.NoError(t, err)
+
+	// Null NodeId round-trips to a NodeId with ns=0, id=0
+	assert.Equal(t, uint16(0), decoded.SourceNode.Namespace())
+	assert.Equal(t, uint32(0), decoded.SourceNode.IntID())
+	assert.Equal(t, uint32(0), decoded.EventTypeNode.IntID())
+}
+
+func TestLogRecordExtObjRoundTrip_TraceContext(t *testing.T) {
+	original := &LogRecordExtObj{
+		Time:             time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:         300,
+		Message:          "Trace test",
+		TraceIDBytes:     fixedTraceIDBytes(),
+		SpanID:           0x0102030405060708,
+		ParentSpanID:     0xdeadbeefcafe0000,
+		ParentIdentifier: "urn:example:server",
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+
+	decoded := &LogRecordExtObj{}
+	_, err = decoded.Decode(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.TraceIDBytes, decoded.TraceIDBytes)
+	assert.Equal(t, original.SpanID, decoded.SpanID)
+	assert.Equal(t, original.ParentSpanID, decoded.ParentSpanID)
+	assert.Equal(t, original.ParentIdentifier, decoded.ParentIdentifier)
+}
+
+func TestLogRecordExtObjTraceIDHex(t *testing.T) {
+	tests := []struct {
+		name     string
+		spanID   uint64
+		expected string
+	}{
+		{
+			name:     "absent (SpanID == 0)",
+			spanID:   0,
+			expected: "",
+		},
+		{
+			name:     "present (SpanID != 0)",
+			spanID:   0x0102030405060708,
+			expected: "0102030405060708090a0b0c0d0e0f10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := &LogRecordExtObj{
+				TraceIDBytes: fixedTraceIDBytes(),
+				SpanID:       tt.spanID,
+			}
+			assert.Equal(t, tt.expected, lr.TraceIDHex())
+		})
+	}
+}
+
+func TestLogRecordExtObjSpanIDHex(t *testing.T) {
+	tests := []struct {
+		name     string
+		spanID   uint64
+		expected string
+	}{
+		{"zero returns empty", 0, ""},
+		{"sequential bytes", 0x0102030405060708, "0102030405060708"},
+		{"all-f", 0xffffffffffffffff, "ffffffffffffffff"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := &LogRecordExtObj{SpanID: tt.spanID}
+			assert.Equal(t, tt.expected, lr.SpanIDHex())
+		})
+	}
+}
+
+func TestLogRecordExtObjParentSpanIDHex(t *testing.T) {
+	tests := []struct {
+		name         string
+		spanID       uint64
+		parentSpanID uint64
+		expected     string
+	}{
+		{"root span returns empty", 0x0102030405060708, 0, ""},
+		{"no trace context returns empty", 0, 0x1112131415161718, ""},
+		{"sequential bytes", 0x0102030405060708, 0x1112131415161718, "1112131415161718"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := &LogRecordExtObj{SpanID: tt.spanID, ParentSpanID: tt.parentSpanID}
+			assert.Equal(t, tt.expected, lr.ParentSpanIDHex())
+		})
+	}
+}
+
+func TestNodeIDRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		nodeID *ua.NodeID
+	}{
+		{"nil", nil},
+		{"TwoByte ns=0 i=1", ua.NewNumericNodeID(0, 1)},
+		{"TwoByte ns=0 i=255", ua.NewNumericNodeID(0, 255)},
+		{"FourByte ns=1 i=1001", ua.NewNumericNodeID(1, 1001)},
+		{"FourByte ns=0 i=2041 (BaseEventType)", ua.NewNumericNodeID(0, 2041)},
+		{"Numeric ns=2 i=70000", ua.NewNumericNodeID(2, 70000)},
+		{"String ns=1", ua.NewStringNodeID(1, "MyDevice")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Encode into a temporary buffer via a LogRecordExtObj
+			lr := &LogRecordExtObj{
+				Time:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Severity:   100,
+				Message:    "node id test",
+				SourceNode: tt.nodeID,
+			}
+
+			encoded, err := lr.Encode()
+			require.NoError(t, err)
+
+			decoded := &LogRecordExtObj{}
+			_, err = decoded.Decode(encoded)
+			require.NoError(t, err)
+
+			if tt.nodeID == nil {
+				// nil encodes to the null NodeId (ns=0, id=0)
+				assert.Equal(t, uint32(0), decoded.SourceNode.IntID())
+				assert.Equal(t, uint16(0), decoded.SourceNode.Namespace())
+				return
+			}
+			require.NotNil(t, decoded.SourceNode)
+			assert.Equal(t, tt.nodeID.Namespace(), decoded.SourceNode.Namespace())
+			assert.Equal(t, tt.nodeID.IntID(), decoded.SourceNode.IntID())
+			if tt.nodeID.Type() == ua.NodeIDTypeString {
+				assert.Equal(t, tt.nodeID.StringID(), decoded.SourceNode.StringID())
+			}
+		})
+	}
+}
+
+func TestLogRecordExtObjRoundTrip_AdditionalData(t *testing.T) {
+	tests := []struct {
+		name           string
+		additionalData map[string]interface{}
+	}{
+		{
+			name:           "nil / empty",
+			additionalData: nil,
+		},
+		{
+			name: "string values",
+			additionalData: map[string]interface{}{
+				"sensor_id": "temp-01",
+				"unit":      "Celsius",
+			},
+		},
+		{
+			name: "bool value",
+			additionalData: map[string]interface{}{
+				"active": true,
+			},
+		},
+		{
+			name: "int32 value",
+			additionalData: map[string]interface{}{
+				"count": int32(42),
+			},
+		},
+		{
+			name: "float64 value",
+			additionalData: map[string]interface{}{
+				"temperature": 22.5,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := &LogRecordExtObj{
+				Time:           time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+				Severity:       150,
+				Message:        "data test",
+				AdditionalData: tt.additionalData,
+			}
+
+			encoded, err := original.Encode()
+			require.NoError(t, err)
+
+			decoded := &LogRecordExtObj{}
+			_, err = decoded.Decode(encoded)
+			require.NoError(t, err)
+
+			if len(tt.additionalData) == 0 {
+				assert.Empty(t, decoded.AdditionalData)
+				return
+			}
+			require.NotNil(t, decoded.AdditionalData)
+			for k, v := range tt.additionalData {
+				assert.Equal(t, v, decoded.AdditionalData[k], "key %q mismatch", k)
+			}
+		})
+	}
+}
+
+// propertyIterations is the number of random records checked by the codec property tests.
+const propertyIterations = 500
+
+// randomCodecString returns a string that is empty, ASCII, multi-plane Unicode,
+// invalid UTF-8 or very long, so that length prefixes are exercised as well as content.
+func randomCodecString(r *rand.Rand) string {
+	switch r.Intn(6) {
+	case 0:
+		return ""
+	case 1:
+		return string([]rune("äöü ß € 日本語 中文 한국어 العربية עברית 🚀🔥👍🏽")[r.Intn(10):])
+	case 2:
+		runes := make([]rune, r.Intn(64))
+		for i := range runes {
+			switch r.Intn(3) {
+			case 0:
+				runes[i] = rune(0x20 + r.Intn(0x5F))
+			case 1:
+				runes[i] = rune(0x0400 + r.Intn(0xD000-0x0400)) // Cyrillic to Hangul
+			default:
+				runes[i] = rune(0x1F300 + r.Intn(0x300)) // emoji
+			}
+		}
+		return string(runes)
+	case 3:
+		b := make([]byte, r.Intn(32))
+		r.Read(b)
+		return string(b)
+	case 4:
+		return strings.Repeat("x", 64*1024+r.Intn(1024))
+	default:
+		return strings.Repeat("ü", r.Intn(4096))
+	}
+}
+
+// randomCodecNodeID returns nil or a NodeId of every binary encoding: TwoByte,
+// FourByte, Numeric, String, Guid and ByteString.
+func randomCodecNodeID(r *rand.Rand) *ua.NodeID {
+	switch r.Intn(7) {
+	case 0:
+		return nil
+	case 1:
+		return ua.NewNumericNodeID(0, uint32(r.Intn(256)))
+	case 2:
+		return ua.NewNumericNodeID(uint16(r.Intn(256)), uint32(r.Intn(65536)))
+	case 3:
+		return ua.NewNumericNodeID(uint16(r.Intn(65536)), r.Uint32())
+	case 4:
+		return ua.NewStringNodeID(uint16(r.Intn(65536)), randomCodecString(r))
+	case 5:
+		g := &ua.GUID{Data1: r.Uint32(), Data2: uint16(r.Uint32()), Data3: uint16(r.Uint32()), Data4: make([]byte, 8)}
+		r.Read(g.Data4)
+		return ua.NewGUIDNodeID(uint16(r.Intn(65536)), g.String())
+	default:
+		b := make([]byte, r.Intn(128))
+		r.Read(b)
+		return ua.NewByteStringNodeID(uint16(r.Intn(65536)), b)
+	}
+}
+
+// randomCodecVariant returns a value of one of the Variant types the codec supports,
+// in the Go type Decode produces for it.
+func randomCodecVariant(r *rand.Rand) interface{} {
+	switch r.Intn(12) {
+	case 0:
+		return r.Intn(2) == 1
+	case 1:
+		return int8(r.Uint32())
+	case 2:
+		return uint8(r.Uint32())
+	case 3:
+		return int16(r.Uint32())
+	case 4:
+		return uint16(r.Uint32())
+	case 5:
+		return int32(r.Uint32())
+	case 6:
+		return r.Uint32()
+	case 7:
+		return int64(r.Uint64())
+	case 8:
+		return r.Uint64()
+	case 9:
+		return float32(r.NormFloat64() * math.MaxFloat32 / 8)
+	case 10:
+		return []float64{0, math.MaxFloat64, -math.SmallestNonzeroFloat64, math.Inf(1), r.NormFloat64() * 1e300}[r.Intn(5)]
+	default:
+		return randomCodecString(r)
+	}
+}
+
+// randomCodecRecord returns a LogRecordExtObj with every field randomised.
+// Times are whole 100ns ticks between 1970 and 2200, the range the DateTime encoding preserves.
+func randomCodecRecord(r *rand.Rand) *LogRecordExtObj {
+	minTicks := int64(0)
+	maxTicks := time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() / 100
+	l := &LogRecordExtObj{
+		Time:             time.Unix(0, (minTicks+r.Int63n(maxTicks-minTicks))*100).UTC(),
+		Severity:         uint16(r.Intn(65536)),
+		Message:          randomCodecString(r),
+		EventTypeNode:    randomCodecNodeID(r),
+		SourceNode:       randomCodecNodeID(r),
+		SourceName:       randomCodecString(r),
+		SpanID:           r.Uint64(),
+		ParentSpanID:     r.Uint64(),
+		ParentIdentifier: randomCodecString(r),
+	}
+	r.Read(l.TraceIDBytes[:])
+	if l.SourceNode != nil && r.Intn(4) == 0 {
+		l.SourceNamespaceURI = "http://vendor.com/UA/" + randomCodecString(r)
+		l.SourceServerIndex = uint32(r.Intn(3))
+	}
+	if r.Intn(2) == 0 {
+		l.MessageLocale = randomCodecString(r)
+	}
+
+	n := r.Intn(8)
+	if r.Intn(20) == 0 {
+		n = 1000
+	}
+	if n > 0 {
+		l.AdditionalData = make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			name := randomCodecString(r)
+			if name == "" {
+				name = "empty"
+			}
+			l.AdditionalData[name] = randomCodecVariant(r)
+		}
+	}
+	return l
+}
+
+// assertCodecRecordEqual compares records field by field; nil NodeIds decode to the null NodeId i=0.
+func assertCodecRecordEqual(t *testing.T, want, got *LogRecordExtObj) {
+	t.Helper()
+	nodeIDString := func(n *ua.NodeID) string {
+		if n == nil {
+			return ua.NewNumericNodeID(0, 0).String()
+		}
+		return n.String()
+	}
+	assert.True(t, want.Time.Equal(got.Time), "time %s != %s", want.Time, got.Time)
+	assert.Equal(t, want.Severity, got.Severity)
+	assert.Equal(t, want.Message, got.Message)
+	assert.Equal(t, want.MessageLocale, got.MessageLocale)
+	assert.Equal(t, nodeIDString(want.EventTypeNode), nodeIDString(got.EventTypeNode))
+	assert.Equal(t, nodeIDString(want.SourceNode), nodeIDString(got.SourceNode))
+	assert.Equal(t, want.SourceNamespaceURI, got.SourceNamespaceURI)
+	assert.Equal(t, want.SourceServerIndex, got.SourceServerIndex)
+	assert.Equal(t, want.SourceName, got.SourceName)
+	assert.Equal(t, want.TraceIDBytes, got.TraceIDBytes)
+	assert.Equal(t, want.SpanID, got.SpanID)
+	assert.Equal(t, want.ParentSpanID, got.ParentSpanID)
+	assert.Equal(t, want.ParentIdentifier, got.ParentIdentifier)
+	require.Len(t, got.AdditionalData, len(want.AdditionalData))
+	for k, v := range want.AdditionalData {
+		assert.Equal(t, v, got.AdditionalData[k], "key %q mismatch", k)
+	}
+}
+
+func TestLogRecordExtObjPropertyRoundTrip(t *testing.T) {
+	seed := time.Now().UnixNano()
+	t.Logf("seed %d", seed)
+	r := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < propertyIterations; i++ {
+		original := randomCodecRecord(r)
+
+		encoded, err := original.Encode()
+		require.NoError(t, err)
+
+		decoded := &LogRecordExtObj{}
+		n, err := decoded.Decode(encoded)
+		require.NoError(t, err, "iteration %d", i)
+		require.Equal(t, len(encoded), n, "iteration %d: decoder must consume the whole record", i)
+		assertCodecRecordEqual(t, original, decoded)
+		if t.Failed() {
+			t.Fatalf("iteration %d failed for %s", i, original)
+		}
+	}
+}
+
+func TestLogRecordExtObjPropertyNodeIDTypes(t *testing.T) {
+	g := &ua.GUID{Data1: 0x72962B91, Data2: 0xFA75, Data3: 0x4AE6, Data4: []byte{0x8D, 0x28, 0xB4, 0x04, 0xDC, 0x7D, 0xAF, 0x63}}
+	tests := []struct {
+		name   string
+		nodeID *ua.NodeID
+	}{
+		{"String ns=0", ua.NewStringNodeID(0, "Server")},
+		{"String empty", ua.NewStringNodeID(3, "")},
+		{"Guid", ua.NewGUIDNodeID(2, g.String())},
+		{"ByteString", ua.NewByteStringNodeID(4, []byte{0x00, 0xFF, 0x10})},
+		{"ByteString empty", ua.NewByteStringNodeID(1, nil)},
+		{"Numeric max", ua.NewNumericNodeID(math.MaxUint16, math.MaxUint32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := &LogRecordExtObj{EventTypeNode: tt.nodeID, SourceNode: tt.nodeID}
+
+			encoded, err := lr.Encode()
+			require.NoError(t, err)
+
+			decoded := &LogRecordExtObj{}
+			_, err = decoded.Decode(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.nodeID.Type(), decoded.SourceNode.Type())
+			assert.Equal(t, tt.nodeID.String(), decoded.SourceNode.String())
+			assert.Equal(t, tt.nodeID.String(), decoded.EventTypeNode.String())
+		})
+	}
+}
+
+func TestLogRecordExtObjExpandedSourceNode(t *testing.T) {
+	g := &ua.GUID{Data1: 0x72962B91, Data2: 0xFA75, Data3: 0x4AE6, Data4: []byte{0x8D, 0x28, 0xB4, 0x04, 0xDC, 0x7D, 0xAF, 0x63}}
+	tests := []struct {
+		name         string
+		nodeID       *ua.NodeID
+		namespaceURI string
+		serverIndex  uint32
+	}{
+		{"TwoByte with namespace URI", ua.NewNumericNodeID(0, 5), "http://vendor.com/UA/", 0},
+		{"Numeric with server index", ua.NewNumericNodeID(3, 70000), "", 2},
+		{"Guid with both", ua.NewGUIDNodeID(0, g.String()), "http://vendor.com/UA/", 1},
+		{"ByteString with namespace URI", ua.NewByteStringNodeID(0, []byte{0x01, 0x02}), "urn:plant", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := &LogRecordExtObj{SourceNode: tt.nodeID, SourceNamespaceURI: tt.namespaceURI, SourceServerIndex: tt.serverIndex}
+
+			encoded, err := lr.Encode()
+			require.NoError(t, err)
+
+			decoded := &LogRecordExtObj{}
+			n, err := decoded.Decode(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, len(encoded), n)
+			assert.Equal(t, tt.nodeID.String(), decoded.SourceNode.String())
+			assert.Equal(t, tt.namespaceURI, decoded.SourceNamespaceURI)
+			assert.Equal(t, tt.serverIndex, decoded.SourceServerIndex)
+		})
+	}
+}
+
+func TestLogRecordExtObjDecodeUnknownNodeIDEncoding(t *testing.T) {
+	encoded, err := (&LogRecordExtObj{SourceNode: ua.NewNumericNodeID(0, 1)}).Encode()
+	require.NoError(t, err)
+
+	// DateTime and Severity precede the EventType NodeId, then SourceNode
+	// starts with its encoding byte after the two TwoByte bytes of EventType
+	encoded[8+2+2] = 0x07
+
+	_, err = (&LogRecordExtObj{}).Decode(encoded)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SourceNode: unknown NodeId encoding 0x07")
+}
+
+func TestLogRecordExtObjPropertyDecodeTruncated(t *testing.T) {
+	seed := time.Now().UnixNano()
+	t.Logf("seed %d", seed)
+	r := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < propertyIterations/10; i++ {
+		encoded, err := randomCodecRecord(r).Encode()
+		require.NoError(t, err)
+
+		// Every strict prefix must fail cleanly and never read past the input
+		for _, cut := range []int{0, 1, len(encoded) / 2, len(encoded) - 1, r.Intn(len(encoded))} {
+			decoded := &LogRecordExtObj{}
+			var n int
+			require.NotPanics(t, func() { n, err = decoded.Decode(encoded[:cut]) })
+			assert.Error(t, err, "iteration %d: prefix %d/%d decoded without error", i, cut, len(encoded))
+			assert.LessOrEqual(t, n, cut)
+		}
+	}
+}
+
+func TestLogRecordExtObjPropertyDecodeRandomBytes(t *testing.T) {
+	seed := time.Now().UnixNano()
+	t.Logf("seed %d", seed)
+	r := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < propertyIterations*4; i++ {
+		var b []byte
+		if i%2 == 0 {
+			b = make([]byte, r.Intn(256))
+			r.Read(b)
+		} else {
+			// Mutate a valid encoding so length prefixes and type bytes get corrupted
+			encoded, err := randomCodecRecord(r).Encode()
+			require.NoError(t, err)
+			b = encoded
+			for j := 0; j < 1+r.Intn(4); j++ {
+				b[r.Intn(len(b))] = byte(r.Uint32())
+			}
+		}
+
+		decoded := &LogRecordExtObj{}
+		var n int
+		require.NotPanics(t, func() { n, _ = decoded.Decode(b) }, "iteration %d", i)
+		assert.LessOrEqual(t, n, len(b))
+	}
+}
+
+func TestLogRecordExtObjDecodeHugeAdditionalDataCount(t *testing.T) {
+	encoded, err := (&LogRecordExtObj{Message: "count"}).Encode()
+	require.NoError(t, err)
+
+	// Overwrite the trailing NameValuePair count with MaxInt32 and supply no elements
+	encoded[len(encoded)-4], encoded[len(encoded)-3], encoded[len(encoded)-2], encoded[len(encoded)-1] = 0xFF, 0xFF, 0xFF, 0x7F
+
+	decoded := &LogRecordExtObj{}
+	_, err = decoded.Decode(encoded)
+	require.Error(t, err)
+	assert.Equal(t, "count", decoded.Message)
+}
+
+func FuzzLogRecordExtObjDecode(f *testing.F) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 16; i++ {
+		encoded, err := randomCodecRecord(r).Encode()
+		require.NoError(f, err)
+		f.Add(encoded)
+	}
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		decoded := &LogRecordExtObj{}
+		n, _ := decoded.Decode(b)
+		assert.LessOrEqual(t, n, len(b))
+	})
+}
+
+// isVariantScalar reports whether AdditionalData values of this type are encoded as a Variant scalar
+func isVariantScalar(v interface{}) bool {
+	switch v.(type) {
+	case string, bool, int8, uint8, int16, uint16, int32, uint32, int64, uint64, float32, float64:
+		return true
+	}
+	return false
+}
+
+func TestLogRecordExtObjRoundTrip_VendorAdditionalData(t *testing.T) {
+	gen := testdata.NewSeededGenerator(34, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+
+	for _, profile := range testdata.VendorProfiles {
+		t.Run(profile.String(), func(t *testing.T) {
+			additionalData := gen.VendorAttributes(profile)
+			original := &LogRecordExtObj{
+				Time:           time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+				Severity:       500,
+				Message:        "vendor payload",
+				AdditionalData: additionalData,
+			}
+
+			encoded, err := original.Encode()
+			require.NoError(t, err)
+
+			decoded := &LogRecordExtObj{}
+			n, err := decoded.Decode(encoded)
+			require.NoError(t, err)
+			assert.Equal(t, len(encoded), n)
+			assert.Equal(t, "vendor payload", decoded.Message)
+
+			// Every key survives; arrays, structures, byte strings and
+			// timestamps must not corrupt the pairs that follow them
+			require.Len(t, decoded.AdditionalData, len(additionalData))
+			for k, v := range additionalData {
+				got, ok := decoded.AdditionalData[k]
+				require.True(t, ok, "key %q missing", k)
+				if isVariantScalar(v) {
+					assert.Equal(t, v, got, "key %q mismatch", k)
+				}
+			}
+		})
+	}
+}
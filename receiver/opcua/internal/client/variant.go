@@ -0,0 +1,498 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// Variant encoding mask bits (OPC UA Part 6 §5.2.2.16)
+const (
+	variantTypeMask       = 0x3F
+	variantDimensionsFlag = 0x40
+	variantArrayFlag      = 0x80
+)
+
+// maxVariantDepth bounds the nesting of Variants, DataValues and
+// DiagnosticInfos, so a corrupt body cannot recurse without limit
+const maxVariantDepth = 32
+
+// maxArrayPrealloc bounds the capacity allocated up front for an array, as
+// its length prefix cannot be trusted before the elements are read
+const maxArrayPrealloc = 1024
+
+// readVariantValue reads an OPC UA Variant from buf. Scalars of the built-in
+// types 1–12 keep their Go type; the other types are mapped to values that
+// attributes can hold:
+//
+//	DateTime                   time.Time (zero for the null DateTime)
+//	Guid, XmlElement           string
+//	ByteString                 []byte
+//	NodeId, ExpandedNodeId     string in NodeId notation
+//	StatusCode                 uint32
+//	QualifiedName              string, "ns:name" outside namespace 0
+//	LocalizedText              string, the text
+//	ExtensionObject            map[string]interface{}, see readExtensionObject
+//	DataValue, Variant         the value they hold
+//
+// Arrays are []interface{}; multi-dimensional arrays are nested
+// []interface{} in the row-major order of their encoding. Returns nil for
+// null values and DiagnosticInfos.
+func readVariantValue(buf *ua.Buffer) interface{} {
+	return readVariant(buf, 0)
+}
+
+func readVariant(buf *ua.Buffer, depth int) interface{} {
+	mask := buf.ReadByte()
+	typeID := mask & variantTypeMask
+	if mask&variantArrayFlag == 0 {
+		return readVariantScalar(buf, typeID, depth)
+	}
+
+	// Int32 length, -1 for a null array. Null elements have no encoding, so
+	// their arrays are not read element by element.
+	n := int32(buf.ReadUint32()) //nolint:gosec
+	if n <= 0 || typeID == 0 || buf.Error() != nil {
+		if mask&variantDimensionsFlag != 0 {
+			readDimensions(buf)
+		}
+		return nil
+	}
+	values := make([]interface{}, 0, min(int(n), maxArrayPrealloc))
+	for i := int32(0); i < n && buf.Error() == nil; i++ {
+		values = append(values, readVariantScalar(buf, typeID, depth))
+	}
+	if buf.Error() != nil {
+		return nil
+	}
+
+	if mask&variantDimensionsFlag != 0 {
+		if dims := readDimensions(buf); len(dims) > 1 {
+			if matrix, ok := reshape(values, dims); ok {
+				return matrix
+			}
+		}
+	}
+	return values
+}
+
+// readDimensions reads the ArrayDimensions of a Variant
+func readDimensions(buf *ua.Buffer) []int32 {
+	n := int32(buf.ReadUint32()) //nolint:gosec
+	var dims []int32
+	for i := int32(0); i < n && buf.Error() == nil; i++ {
+		dims = append(dims, int32(buf.ReadUint32())) //nolint:gosec
+	}
+	return dims
+}
+
+// reshape nests the flat values of a multi-dimensional array by dims, the
+// last dimension varying fastest. ok is false if the dimensions do not match
+// the number of values.
+func reshape(values []interface{}, dims []int32) ([]interface{}, bool) {
+	total := 1
+	for _, d := range dims {
+		if d <= 0 || total*int(d) > len(values) {
+			return nil, false
+		}
+		total *= int(d)
+	}
+	if total != len(values) {
+		return nil, false
+	}
+
+	for i := len(dims) - 1; i > 0; i-- {
+		size := int(dims[i])
+		rows := make([]interface{}, 0, len(values)/size)
+		for start := 0; start < len(values); start += size {
+			rows = append(rows, values[start:start+size])
+		}
+		values = rows
+	}
+	return values, true
+}
+
+// readVariantScalar reads a single value of built-in type typeID
+func readVariantScalar(buf *ua.Buffer, typeID byte, depth int) interface{} {
+	switch typeID {
+	case 1: // Boolean
+		return buf.ReadByte() != 0
+	case 2: // SByte
+		return int8(buf.ReadByte()) //nolint:gosec
+	case 3: // Byte
+		return buf.ReadByte()
+	case 4: // Int16
+		return int16(buf.ReadUint16()) //nolint:gosec
+	case 5: // UInt16
+		return buf.ReadUint16()
+	case 6: // Int32
+		return int32(buf.ReadUint32()) //nolint:gosec
+	case 7: // UInt32
+		return buf.ReadUint32()
+	case 8: // Int64
+		return buf.ReadInt64()
+	case 9: // UInt64
+		return uint64(buf.ReadInt64()) //nolint:gosec
+	case 10: // Float
+		return buf.ReadFloat32()
+	case 11: // Double
+		return buf.ReadFloat64()
+	case 12, 16: // String, XmlElement
+		return buf.ReadString()
+	case 13: // DateTime
+		return readDateTime(buf)
+	case 14: // Guid
+		g := &ua.GUID{
+			Data1: buf.ReadUint32(),
+			Data2: buf.ReadUint16(),
+			Data3: buf.ReadUint16(),
+			Data4: append([]byte(nil), buf.ReadN(8)...),
+		}
+		if buf.Error() != nil {
+			return nil
+		}
+		return g.String()
+	case 15: // ByteString
+		b := buf.ReadBytes()
+		if b == nil {
+			return nil
+		}
+		return append([]byte(nil), b...)
+	case 17, 18: // NodeId, ExpandedNodeId
+		nodeID, err := readNodeIDFromBuffer(buf)
+		if err != nil {
+			return nil
+		}
+		return expandedNodeIDString(nodeID)
+	case 19: // StatusCode
+		return buf.ReadUint32()
+	case 20: // QualifiedName
+		ns := buf.ReadUint16()
+		name := buf.ReadString()
+		if ns == 0 {
+			return name
+		}
+		return fmt.Sprintf("%d:%s", ns, name)
+	case 21: // LocalizedText
+		return readLocalizedText(buf)
+	case 22: // ExtensionObject
+		return readExtensionObject(buf)
+	case 23: // DataValue
+		if depth >= maxVariantDepth {
+			return nil
+		}
+		return readDataValue(buf, depth+1)
+	case 24: // Variant
+		if depth >= maxVariantDepth {
+			return nil
+		}
+		return readVariant(buf, depth+1)
+	case 25: // DiagnosticInfo
+		skipDiagnosticInfo(buf, depth)
+		return nil
+	default:
+		return nil
+	}
+}
+
+// readDateTime reads a DateTime, 100ns ticks since 1601-01-01
+func readDateTime(buf *ua.Buffer) time.Time {
+	ticks := buf.ReadInt64()
+	if ticks <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, (ticks-unixToOpcuaTicksOffset)*100).UTC()
+}
+
+// readLocalizedText reads a LocalizedText and returns its text
+func readLocalizedText(buf *ua.Buffer) string {
+	mask := buf.ReadByte()
+	if mask&0x01 != 0 {
+		_ = buf.ReadString() // locale
+	}
+	if mask&0x02 != 0 {
+		return buf.ReadString()
+	}
+	return ""
+}
+
+// expandedNodeIDString returns a NodeId in NodeId notation, with the
+// namespace URI and server index of an ExpandedNodeId
+func expandedNodeIDString(nodeID *ua.ExpandedNodeID) string {
+	s := nodeID.NodeID.String()
+	if nodeID.NamespaceURI != "" {
+		// The identifier follows the namespace index, if there is one
+		identifier := s
+		if i := strings.IndexByte(s, ';'); i >= 0 {
+			identifier = s[i+1:]
+		}
+		s = "nsu=" + nodeID.NamespaceURI + ";" + identifier
+	}
+	if nodeID.ServerIndex != 0 {
+		s = fmt.Sprintf("svr=%d;%s", nodeID.ServerIndex, s)
+	}
+	return s
+}
+
+// readExtensionObject reads an ExtensionObject. Bodies of types registered
+// with gopcua are decoded and their exported fields returned by name; other
+// bodies are returned as TypeId and Body, the raw ByteString or XML.
+func readExtensionObject(buf *ua.Buffer) interface{} {
+	typeID, err := readNodeIDFromBuffer(buf)
+	if err != nil {
+		return nil
+	}
+	result := map[string]interface{}{"TypeId": expandedNodeIDString(typeID)}
+
+	switch buf.ReadByte() {
+	case 0x01: // ByteString body
+		body := append([]byte(nil), buf.ReadBytes()...)
+		if buf.Error() != nil {
+			return nil
+		}
+		if fields, ok := decodeRegistered(typeID.NodeID, body); ok {
+			return fields
+		}
+		result["Body"] = body
+	case 0x02: // XmlElement body
+		result["Body"] = buf.ReadString()
+	}
+	return result
+}
+
+// decodeRegistered decodes body if its type is registered with gopcua
+func decodeRegistered(typeID *ua.NodeID, body []byte) (map[string]interface{}, bool) {
+	wire := ua.NewBuffer(nil)
+	writeNodeIDToBuffer(wire, typeID, "", 0)
+	wire.WriteByte(ua.ExtensionObjectBinary)
+	wire.WriteByteString(body)
+
+	obj := new(ua.ExtensionObject)
+	if _, err := obj.Decode(wire.Bytes()); err != nil || obj.Value == nil {
+		return nil, false
+	}
+	if _, raw := obj.Value.([]byte); raw {
+		return nil, false
+	}
+	fields, ok := attributeValue(reflect.ValueOf(obj.Value), 0).(map[string]interface{})
+	return fields, ok
+}
+
+// readDataValue reads a DataValue and returns its value
+func readDataValue(buf *ua.Buffer, depth int) interface{} {
+	mask := buf.ReadByte()
+	var value interface{}
+	if mask&0x01 != 0 {
+		value = readVariant(buf, depth)
+	}
+	if mask&0x02 != 0 {
+		_ = buf.ReadUint32() // StatusCode
+	}
+	if mask&0x04 != 0 {
+		_ = buf.ReadInt64() // SourceTimestamp
+	}
+	if mask&0x08 != 0 {
+		_ = buf.ReadInt64() // ServerTimestamp
+	}
+	if mask&0x10 != 0 {
+		_ = buf.ReadUint16() // SourcePicoseconds
+	}
+	if mask&0x20 != 0 {
+		_ = buf.ReadUint16() // ServerPicoseconds
+	}
+	return value
+}
+
+// skipDiagnosticInfo reads past a DiagnosticInfo
+func skipDiagnosticInfo(buf *ua.Buffer, depth int) {
+	mask := buf.ReadByte()
+	for _, bit := range []byte{0x01, 0x02, 0x04, 0x08} {
+		if mask&bit != 0 {
+			_ = buf.ReadUint32() // SymbolicId, NamespaceUri, LocalizedText, Locale
+		}
+	}
+	if mask&0x10 != 0 {
+		_ = buf.ReadString() // AdditionalInfo
+	}
+	if mask&0x20 != 0 {
+		_ = buf.ReadUint32() // InnerStatusCode
+	}
+	if mask&0x40 != 0 && depth < maxVariantDepth && buf.Error() == nil {
+		skipDiagnosticInfo(buf, depth+1)
+	}
+}
+
+// attributeValue converts a value decoded by gopcua to the types
+// readVariantValue returns; structs become maps of their exported fields
+func attributeValue(v reflect.Value, depth int) interface{} {
+	if !v.IsValid() || depth > maxVariantDepth {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		if value, ok := uaValue(v.Interface()); ok {
+			return value
+		}
+		return attributeValue(v.Elem(), depth+1)
+	}
+	if value, ok := uaValue(v.Interface()); ok {
+		return value
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				fields[field.Name] = attributeValue(v.Field(i), depth+1)
+			}
+		}
+		return fields
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return b
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = attributeValue(v.Index(i), depth+1)
+		}
+		return values
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		values := make(map[string]interface{}, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			values[iter.Key().String()] = attributeValue(iter.Value(), depth+1)
+		}
+		return values
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return v.Interface()
+	default:
+		return nil
+	}
+}
+
+// uaValue converts the gopcua types that readVariantValue maps to scalars
+func uaValue(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, true
+	case *ua.NodeID:
+		return v.String(), true
+	case *ua.ExpandedNodeID:
+		return expandedNodeIDString(v), true
+	case *ua.GUID:
+		return v.String(), true
+	case *ua.LocalizedText:
+		return v.Text, true
+	case *ua.QualifiedName:
+		if v.NamespaceIndex == 0 {
+			return v.Name, true
+		}
+		return fmt.Sprintf("%d:%s", v.NamespaceIndex, v.Name), true
+	case ua.StatusCode:
+		return uint32(v), true
+	case *ua.Variant:
+		return attributeValue(reflect.ValueOf(v.Value()), 1), true
+	case *ua.DataValue:
+		if v.Value == nil {
+			return nil, true
+		}
+		return attributeValue(reflect.ValueOf(v.Value.Value()), 1), true
+	}
+	return nil, false
+}
+
+// writeVariantValue writes an OPC UA Variant to buf. Supports the scalars
+// readVariantValue returns for the built-in types 1–13 and 15, and slices of
+// them, which are written as arrays of Variants; int is written as Int32.
+// Other values, including maps, are written as null.
+func writeVariantValue(buf *ua.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		buf.WriteByte(12)
+		buf.WriteString(v)
+	case bool:
+		buf.WriteByte(1)
+		if v {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	case int8:
+		buf.WriteByte(2) // SByte
+		buf.WriteByte(byte(v))
+	case uint8:
+		buf.WriteByte(3) // Byte
+		buf.WriteByte(v)
+	case int16:
+		buf.WriteByte(4)
+		buf.WriteUint16(uint16(v))
+	case uint16:
+		buf.WriteByte(5)
+		buf.WriteUint16(v)
+	case int:
+		buf.WriteByte(6) // Int32
+		buf.WriteUint32(uint32(v))
+	case int32:
+		buf.WriteByte(6)
+		buf.WriteUint32(uint32(v))
+	case int64:
+		buf.WriteByte(8)
+		buf.WriteInt64(v)
+	case uint32:
+		buf.WriteByte(7)
+		buf.WriteUint32(v)
+	case uint64:
+		buf.WriteByte(9)
+		buf.WriteInt64(int64(v)) //nolint:gosec
+	case float32:
+		buf.WriteByte(10) // Float
+		buf.WriteFloat32(v)
+	case float64:
+		buf.WriteByte(11) // Double
+		buf.WriteFloat64(v)
+	case time.Time:
+		buf.WriteByte(13) // DateTime
+		if v.IsZero() {
+			buf.WriteInt64(0)
+		} else {
+			buf.WriteInt64(v.UnixNano()/100 + unixToOpcuaTicksOffset)
+		}
+	case []byte:
+		buf.WriteByte(15) // ByteString
+		buf.WriteByteString(v)
+	case []interface{}:
+		buf.WriteByte(24 | variantArrayFlag) // Variant[]
+		buf.WriteUint32(uint32(len(v)))      //nolint:gosec
+		for _, element := range v {
+			writeVariantValue(buf, element)
+		}
+	default:
+		// Typed arrays are written like []interface{}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+			elements := make([]interface{}, rv.Len())
+			for i := range elements {
+				elements[i] = rv.Index(i).Interface()
+			}
+			writeVariantValue(buf, elements)
+			return
+		}
+		// Fallback: write as null (type 0)
+		buf.WriteByte(0)
+	}
+}
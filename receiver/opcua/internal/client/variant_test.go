@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package client
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// variantBytes returns the encoding of a Variant written by write
+func variantBytes(write func(buf *ua.Buffer)) []byte {
+	buf := ua.NewBuffer(nil)
+	write(buf)
+	return buf.Bytes()
+}
+
+func TestReadVariantValue(t *testing.T) {
+	date := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		write    func(buf *ua.Buffer)
+		expected interface{}
+	}{
+		{
+			name: "Int32 array",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(6 | variantArrayFlag)
+				buf.WriteUint32(3)
+				for _, v := range []uint32{1, 2, 3} {
+					buf.WriteUint32(v)
+				}
+			},
+			expected: []interface{}{int32(1), int32(2), int32(3)},
+		},
+		{
+			name: "Double matrix",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(11 | variantArrayFlag | variantDimensionsFlag)
+				buf.WriteUint32(6)
+				for _, v := range []float64{1, 2, 3, 4, 5, 6} {
+					buf.WriteFloat64(v)
+				}
+				buf.WriteUint32(2) // dimensions: 2 rows of 3
+				buf.WriteUint32(2)
+				buf.WriteUint32(3)
+			},
+			expected: []interface{}{[]interface{}{1.0, 2.0, 3.0}, []interface{}{4.0, 5.0, 6.0}},
+		},
+		{
+			name: "matrix with mismatched dimensions stays flat",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(1 | variantArrayFlag | variantDimensionsFlag)
+				buf.WriteUint32(2)
+				buf.WriteByte(1)
+				buf.WriteByte(0)
+				buf.WriteUint32(2)
+				buf.WriteUint32(2)
+				buf.WriteUint32(2)
+			},
+			expected: []interface{}{true, false},
+		},
+		{
+			name: "null array",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(12 | variantArrayFlag)
+				buf.WriteUint32(0xFFFFFFFF)
+			},
+			expected: nil,
+		},
+		{
+			name: "LocalizedText",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(21)
+				buf.WriteByte(0x03)
+				buf.WriteString("de-DE")
+				buf.WriteString("Druck zu hoch")
+			},
+			expected: "Druck zu hoch",
+		},
+		{
+			name: "DateTime",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(13)
+				buf.WriteInt64(date.UnixNano()/100 + unixToOpcuaTicksOffset)
+			},
+			expected: date,
+		},
+		{
+			name: "QualifiedName",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(20)
+				buf.WriteUint16(2)
+				buf.WriteString("Temperature")
+			},
+			expected: "2:Temperature",
+		},
+		{
+			name: "NodeId",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(17)
+				writeNodeIDToBuffer(buf, ua.NewStringNodeID(3, "Line1"), "", 0)
+			},
+			expected: "ns=3;s=Line1",
+		},
+		{
+			name: "ExpandedNodeId with namespace URI",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(18)
+				writeNodeIDToBuffer(buf, ua.NewNumericNodeID(0, 42), "http://vendor.com/UA/", 0)
+			},
+			expected: "nsu=http://vendor.com/UA/;i=42",
+		},
+		{
+			name: "DataValue",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(23)
+				buf.WriteByte(0x01 | 0x04) // Value, SourceTimestamp
+				buf.WriteByte(7)
+				buf.WriteUint32(99)
+				buf.WriteInt64(date.UnixNano()/100 + unixToOpcuaTicksOffset)
+			},
+			expected: uint32(99),
+		},
+		{
+			name: "Variant array with nested array",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(24 | variantArrayFlag)
+				buf.WriteUint32(2)
+				buf.WriteByte(12)
+				buf.WriteString("QA")
+				buf.WriteByte(5 | variantArrayFlag)
+				buf.WriteUint32(2)
+				buf.WriteUint16(10)
+				buf.WriteUint16(20)
+			},
+			expected: []interface{}{"QA", []interface{}{uint16(10), uint16(20)}},
+		},
+		{
+			name: "unregistered ExtensionObject",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(22)
+				writeNodeIDToBuffer(buf, ua.NewNumericNodeID(4, 3001), "", 0)
+				buf.WriteByte(0x01)
+				buf.WriteByteString([]byte{0xCA, 0xFE})
+			},
+			expected: map[string]interface{}{"TypeId": "ns=4;i=3001", "Body": []byte{0xCA, 0xFE}},
+		},
+		{
+			name: "registered ExtensionObject",
+			write: func(buf *ua.Buffer) {
+				body := ua.NewBuffer(nil)
+				body.WriteFloat64(0)
+				body.WriteFloat64(120)
+				buf.WriteByte(22)
+				writeNodeIDToBuffer(buf, ua.NewNumericNodeID(0, id.Range_Encoding_DefaultBinary), "", 0)
+				buf.WriteByte(0x01)
+				buf.WriteByteString(body.Bytes())
+			},
+			expected: map[string]interface{}{"Low": 0.0, "High": 120.0},
+		},
+		{
+			name: "DiagnosticInfo",
+			write: func(buf *ua.Buffer) {
+				buf.WriteByte(25)
+				buf.WriteByte(0x10 | 0x40)
+				buf.WriteString("outer")
+				buf.WriteByte(0x20)
+				buf.WriteUint32(0x80000000)
+			},
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := variantBytes(tt.write)
+			buf := ua.NewBuffer(b)
+			assert.Equal(t, tt.expected, readVariantValue(buf))
+			require.NoError(t, buf.Error())
+			assert.Equal(t, len(b), buf.Pos(), "the whole Variant must be consumed")
+		})
+	}
+}
+
+func TestReadVariantValueBoundsNesting(t *testing.T) {
+	// Variants nested deeper than maxVariantDepth are dropped without recursing further
+	b := variantBytes(func(buf *ua.Buffer) {
+		for i := 0; i < maxVariantDepth*4; i++ {
+			buf.WriteByte(24)
+		}
+		buf.WriteByte(6)
+		buf.WriteUint32(1)
+	})
+	assert.NotPanics(t, func() { readVariantValue(ua.NewBuffer(b)) })
+
+	// A huge length prefix of a null element array is not looped over
+	b = variantBytes(func(buf *ua.Buffer) {
+		buf.WriteByte(variantArrayFlag)
+		buf.WriteUint32(0x7FFFFFFF)
+	})
+	assert.Nil(t, readVariantValue(ua.NewBuffer(b)))
+}
+
+func TestLogRecordExtObjRoundTrip_CompositeAdditionalData(t *testing.T) {
+	started := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+	original := &LogRecordExtObj{
+		Time:     time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity: 300,
+		Message:  "composite",
+		AdditionalData: map[string]interface{}{
+			"Limits":    []float64{0, 25.5, 50},
+			"Approvals": []interface{}{"QA", int32(2), true},
+			"Started":   started,
+			"Raw":       []byte{0x01, 0x02},
+			"Nested":    []interface{}{[]interface{}{int32(1), int32(2)}, []interface{}{int32(3), int32(4)}},
+		},
+	}
+
+	encoded, err := original.Encode()
+	require.NoError(t, err)
+
+	decoded := &LogRecordExtObj{}
+	_, err = decoded.Decode(encoded)
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{0.0, 25.5, 50.0}, decoded.AdditionalData["Limits"])
+	assert.Equal(t, []interface{}{"QA", int32(2), true}, decoded.AdditionalData["Approvals"])
+	assert.Equal(t, started, decoded.AdditionalData["Started"])
+	assert.Equal(t, []byte{0x01, 0x02}, decoded.AdditionalData["Raw"])
+	assert.Equal(t, original.AdditionalData["Nested"], decoded.AdditionalData["Nested"])
+}
+
+func TestJSONVariantValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected interface{}
+	}{
+		{name: "reversible array", raw: `{"Type": 6, "Body": [1, 2, 3]}`, expected: []interface{}{int32(1), int32(2), int32(3)}},
+		{
+			name:     "reversible matrix",
+			raw:      `{"UaType": 3, "Value": [1, 2, 3, 4], "Dimensions": [2, 2]}`,
+			expected: []interface{}{[]interface{}{uint8(1), uint8(2)}, []interface{}{uint8(3), uint8(4)}},
+		},
+		{name: "LocalizedText", raw: `{"Type": 21, "Body": {"Locale": "de", "Text": "Druck"}}`, expected: "Druck"},
+		{name: "DateTime", raw: `{"Type": 13, "Body": "2025-01-15T10:00:00Z"}`, expected: time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)},
+		{name: "ByteString", raw: `{"Type": 15, "Body": "AQI="}`, expected: []byte{0x01, 0x02}},
+		{name: "QualifiedName", raw: `{"Type": 20, "Body": {"Name": "Temperature", "Uri": 2}}`, expected: "2:Temperature"},
+		{name: "DataValue", raw: `{"Type": 23, "Body": {"Value": {"Type": 12, "Body": "ok"}}}`, expected: "ok"},
+		{
+			name:     "ExtensionObject",
+			raw:      `{"Type": 22, "Body": {"TypeId": {"Id": 886}, "Body": {"Low": 0, "High": 120.5}}}`,
+			expected: map[string]interface{}{"TypeId": map[string]interface{}{"Id": int64(886)}, "Body": map[string]interface{}{"Low": int64(0), "High": 120.5}},
+		},
+		{
+			name:     "non-reversible object",
+			raw:      `{"Order": {"Quantity": 5, "Operations": [{"Step": 10}]}}`,
+			expected: map[string]interface{}{"Order": map[string]interface{}{"Quantity": int64(5), "Operations": []interface{}{map[string]interface{}{"Step": int64(10)}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, jsonVariantValue(json.RawMessage(tt.raw)))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package dryrun connects cmd/opcuadryrun to the dry run of the receiver
+// without making it part of the receiver's public API.
+package dryrun // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/dryrun"
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// DefaultSampleSize is the number of records read per LogObject when no
+// sample size is given
+const DefaultSampleSize = 10
+
+// Run connects to the server of cfg, an *opcua.Config, and prints what the
+// receiver would read from it. It is set by the opcua package on init, so
+// callers must import it.
+var Run func(ctx context.Context, cfg component.Config, out io.Writer, sampleSize int, logger *zap.Logger) error
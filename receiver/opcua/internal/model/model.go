@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package model defines the log record model shared by the OPC UA client,
+// scraper and transformer.
+package model // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+
+import "time"
+
+// LogRecord is a log record read from an OPC UA LogObject (Part 26 §5.4)
+type LogRecord struct {
+	Timestamp          time.Time
+	Severity           uint16
+	Message            string
+	MessageLocale      string // opcua.message.locale: locale of the Message LocalizedText
+	SourceName         string // opcua.source.name: human-readable name of the log source
+	SourceNamespace    uint16 // opcua.source.namespace: NodeId namespace index
+	SourceIDType       string // opcua.source.id_type: NodeId identifier type ("Numeric", "String", "Guid", "Opaque")
+	SourceID           string // opcua.source.id: NodeId identifier value
+	SourceNamespaceURI string // opcua.source.namespace_uri: namespace URI of an ExpandedNodeId SourceNode
+	EventType          string // NodeId of the record's event type, empty if the server sent none
+	TraceID            string // 32-character hex string
+	SpanID             string // 16-character hex string
+	ParentSpanID       string // 16-character hex string, empty for a root span
+	TraceFlags         byte
+	LogObjectID        string // NodeId of the LogObject the record was read from
+	Attributes         map[string]interface{}
+}
+
+// TraceContext is the trace context carried by a LogRecord
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Flags   byte
+}
@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package recording stores GetRecords calls exchanged with a live server so
+// that they can be replayed against the receiver in tests.
+package recording // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/recording"
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// Call is one recorded GetRecords call. Request and Result hold the OPC UA
+// binary encoding of the CallMethodRequest and CallMethodResult; the other
+// fields are informational so that recordings can be inspected by hand.
+// When the Call service itself failed, Result is empty and ServiceStatus or
+// Error describe the failure.
+type Call struct {
+	Time          time.Time `json:"time"`
+	Endpoint      string    `json:"endpoint,omitempty"`
+	ObjectID      string    `json:"object_id"`
+	MethodID      string    `json:"method_id"`
+	Status        string    `json:"status,omitempty"`
+	ServiceStatus uint32    `json:"service_status,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	Request       []byte    `json:"request"`
+	Result        []byte    `json:"result,omitempty"`
+}
+
+// NewCall records a Call service round trip. callErr is the error returned by
+// the Call service, in which case result is ignored.
+func NewCall(endpoint string, req *ua.CallMethodRequest, result *ua.CallMethodResult, callErr error) (Call, error) {
+	call := Call{
+		Time:     time.Now().UTC(),
+		Endpoint: endpoint,
+		ObjectID: req.ObjectID.String(),
+		MethodID: req.MethodID.String(),
+	}
+
+	var err error
+	if call.Request, err = ua.Encode(req); err != nil {
+		return Call{}, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	if callErr != nil {
+		call.Error = callErr.Error()
+		var status ua.StatusCode
+		if errors.As(callErr, &status) {
+			call.ServiceStatus = uint32(status)
+		}
+		return call, nil
+	}
+
+	call.Status = result.StatusCode.Error()
+	if call.Result, err = ua.Encode(result); err != nil {
+		return Call{}, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return call, nil
+}
+
+// DecodeRequest decodes the recorded CallMethodRequest
+func (c Call) DecodeRequest() (*ua.CallMethodRequest, error) {
+	req := new(ua.CallMethodRequest)
+	if _, err := ua.Decode(c.Request, req); err != nil {
+		return nil, fmt.Errorf("failed to decode request: %w", err)
+	}
+	return req, nil
+}
+
+// DecodeResult decodes the recorded CallMethodResult. It returns nil and no
+// error for calls whose Call service failed.
+func (c Call) DecodeResult() (*ua.CallMethodResult, error) {
+	if len(c.Result) == 0 {
+		return nil, nil
+	}
+	result := new(ua.CallMethodResult)
+	if _, err := ua.Decode(c.Result, result); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	return result, nil
+}
+
+// Writer appends recorded calls to a JSON Lines file
+type Writer struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	enc  *json.Encoder
+}
+
+// Create opens a new recording file in dir, creating dir if needed. The file
+// is named after the current time.
+func Create(dir string) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create capture directory: %w", err)
+	}
+	path := filepath.Join(dir, "getrecords-"+time.Now().UTC().Format("20060102T150405.000000000Z")+".jsonl")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600) //nolint:gosec // path is built from configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to create capture file: %w", err)
+	}
+	return &Writer{path: path, file: file, enc: json.NewEncoder(file)}, nil
+}
+
+// Path returns the path of the recording file
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Write appends a call to the recording
+func (w *Writer) Write(call Call) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.enc.Encode(call); err != nil {
+		return fmt.Errorf("failed to write capture: %w", err)
+	}
+	return nil
+}
+
+// Close closes the recording file
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.file.Close()
+}
+
+// Read reads the calls of a recording in the order they were made
+func Read(r io.Reader) ([]Call, error) {
+	var calls []Call
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var call Call
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		calls = append(calls, call)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return calls, nil
+}
+
+// ReadFile reads the calls of a recording file
+func ReadFile(path string) ([]Call, error) {
+	f, err := os.Open(path) //nolint:gosec // path is supplied by the test
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
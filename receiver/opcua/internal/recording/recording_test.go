@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package recording
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRequest() *ua.CallMethodRequest {
+	return &ua.CallMethodRequest{
+		ObjectID: ua.NewNumericNodeID(2, 1000),
+		MethodID: ua.NewNumericNodeID(2, 1001),
+		InputArguments: []*ua.Variant{
+			ua.MustVariant(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)),
+			ua.MustVariant(time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC)),
+			ua.MustVariant(uint32(100)),
+			ua.MustVariant(uint16(1)),
+			ua.MustVariant(uint32(0x1F)),
+			ua.MustVariant([]byte(nil)),
+		},
+	}
+}
+
+func TestWriteAndReadRecording(t *testing.T) {
+	result := &ua.CallMethodResult{
+		StatusCode: ua.StatusOK,
+		OutputArguments: []*ua.Variant{
+			ua.MustVariant([]string{"a", "b"}),
+			ua.MustVariant([]byte("cp-1")),
+		},
+	}
+
+	ok, err := NewCall("opc.tcp://plc:4840", testRequest(), result, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ns=2;i=1000", ok.ObjectID)
+	assert.Equal(t, "ns=2;i=1001", ok.MethodID)
+	assert.Equal(t, ua.StatusOK.Error(), ok.Status)
+
+	failed, err := NewCall("opc.tcp://plc:4840", testRequest(), nil, fmt.Errorf("Call service failed: %w", ua.StatusBadTooManyOperations))
+	require.NoError(t, err)
+	assert.Empty(t, failed.Result)
+	assert.Equal(t, uint32(ua.StatusBadTooManyOperations), failed.ServiceStatus)
+
+	w, err := Create(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, w.Write(ok))
+	require.NoError(t, w.Write(failed))
+	require.NoError(t, w.Close())
+
+	calls, err := ReadFile(w.Path())
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+
+	req, err := calls[0].DecodeRequest()
+	require.NoError(t, err)
+	assert.Equal(t, testRequest().ObjectID.String(), req.ObjectID.String())
+	assert.Equal(t, uint32(100), req.InputArguments[2].Value())
+
+	got, err := calls[0].DecodeResult()
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, ua.StatusOK, got.StatusCode)
+	assert.Equal(t, []string{"a", "b"}, got.OutputArguments[0].Value())
+	assert.Equal(t, []byte("cp-1"), got.OutputArguments[1].Value())
+
+	got, err = calls[1].DecodeResult()
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, uint32(ua.StatusBadTooManyOperations), calls[1].ServiceStatus)
+}
+
+func TestReadRecordingErrors(t *testing.T) {
+	_, err := Read(strings.NewReader("{\"object_id\":\"i=1\"}\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2")
+
+	calls, err := Read(strings.NewReader("\n"))
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+
+	_, err = Call{Request: []byte{0x01}}.DecodeRequest()
+	assert.Error(t, err)
+}
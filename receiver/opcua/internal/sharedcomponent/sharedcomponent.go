@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package sharedcomponent exposes functionality for components to register
+// against a shared key, such as a configuration object, in order to be reused
+// across signal types (logs, metrics, traces).
+package sharedcomponent // import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/sharedcomponent"
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+)
+
+// Map keeps reference of all created instances for a given shared key such as a component configuration.
+type Map[K comparable, V component.Component] struct {
+	lock       sync.Mutex
+	components map[K]*Component[V]
+}
+
+// NewMap creates a new shared components map.
+func NewMap[K comparable, V component.Component]() *Map[K, V] {
+	return &Map[K, V]{
+		components: map[K]*Component[V]{},
+	}
+}
+
+// LoadOrStore returns the already created instance if exists, otherwise creates a new instance
+// and adds it to the map of references.
+func (m *Map[K, V]) LoadOrStore(key K, create func() (V, error)) (*Component[V], error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if c, ok := m.components[key]; ok {
+		return c, nil
+	}
+	comp, err := create()
+	if err != nil {
+		return nil, err
+	}
+	newComp := &Component[V]{
+		component: comp,
+		removeFunc: func() {
+			m.lock.Lock()
+			defer m.lock.Unlock()
+			delete(m.components, key)
+		},
+	}
+	m.components[key] = newComp
+	return newComp, nil
+}
+
+// Len returns the number of components currently held in the map.
+func (m *Map[K, V]) Len() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return len(m.components)
+}
+
+// Component ensures that the wrapped component is started and stopped only once.
+// When stopped it is removed from the Map.
+type Component[V component.Component] struct {
+	component V
+
+	startOnce  sync.Once
+	stopOnce   sync.Once
+	removeFunc func()
+}
+
+// Unwrap returns the original component.
+func (c *Component[V]) Unwrap() V {
+	return c.component
+}
+
+// Start starts the underlying component if it never started before.
+func (c *Component[V]) Start(ctx context.Context, host component.Host) error {
+	var err error
+	c.startOnce.Do(func() {
+		err = c.component.Start(ctx, host)
+	})
+	return err
+}
+
+// Shutdown shuts down the underlying component.
+func (c *Component[V]) Shutdown(ctx context.Context) error {
+	var err error
+	c.stopOnce.Do(func() {
+		err = c.component.Shutdown(ctx)
+		c.removeFunc()
+	})
+	return err
+}
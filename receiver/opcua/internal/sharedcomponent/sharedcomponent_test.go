@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sharedcomponent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+type countingComponent struct {
+	starts    int
+	shutdowns int
+}
+
+func (c *countingComponent) Start(context.Context, component.Host) error {
+	c.starts++
+	return nil
+}
+
+func (c *countingComponent) Shutdown(context.Context) error {
+	c.shutdowns++
+	return nil
+}
+
+func TestLoadOrStore(t *testing.T) {
+	m := NewMap[string, *countingComponent]()
+	created := 0
+	create := func() (*countingComponent, error) {
+		created++
+		return &countingComponent{}, nil
+	}
+
+	first, err := m.LoadOrStore("key", create)
+	require.NoError(t, err)
+	second, err := m.LoadOrStore("key", create)
+	require.NoError(t, err)
+
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestLoadOrStoreError(t *testing.T) {
+	m := NewMap[string, *countingComponent]()
+	_, err := m.LoadOrStore("key", func() (*countingComponent, error) {
+		return nil, errors.New("boom")
+	})
+	require.Error(t, err)
+	assert.Equal(t, 0, m.Len())
+}
+
+func TestStartShutdownOnce(t *testing.T) {
+	m := NewMap[string, *countingComponent]()
+	comp, err := m.LoadOrStore("key", func() (*countingComponent, error) {
+		return &countingComponent{}, nil
+	})
+	require.NoError(t, err)
+
+	host := componenttest.NewNopHost()
+	require.NoError(t, comp.Start(context.Background(), host))
+	require.NoError(t, comp.Start(context.Background(), host))
+	require.NoError(t, comp.Shutdown(context.Background()))
+	require.NoError(t, comp.Shutdown(context.Background()))
+
+	assert.Equal(t, 1, comp.Unwrap().starts)
+	assert.Equal(t, 1, comp.Unwrap().shutdowns)
+	assert.Equal(t, 0, m.Len(), "shutdown should remove the component from the map")
+}
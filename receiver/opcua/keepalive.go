@@ -0,0 +1,185 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+)
+
+// errServerNotRunning is returned by a keepalive read of a server that
+// reports a state other than Running
+var errServerNotRunning = errors.New("server is not running")
+
+// sessionWatchdog reads the server state every keepalive.interval between
+// scrapes. A session whose reads fail max_failures times in a row is
+// half-open or belongs to a server that stopped; the watchdog rebuilds it
+// right away instead of leaving it to fail the next scrape.
+type sessionWatchdog struct {
+	cfg    KeepAliveConfig
+	logger *zap.Logger
+
+	// probe reads the server state and fails unless the server is running
+	probe func(context.Context) error
+	// rebuild replaces the session
+	rebuild func(context.Context) error
+	// idle reports whether there is no session to watch, such as while it
+	// is recovered in the background
+	idle func() bool
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newSessionWatchdog creates a watchdog, which does nothing until it is started
+func newSessionWatchdog(cfg KeepAliveConfig, logger *zap.Logger, probe, rebuild func(context.Context) error, idle func() bool) *sessionWatchdog {
+	return &sessionWatchdog{
+		cfg:     cfg,
+		logger:  logger,
+		probe:   probe,
+		rebuild: rebuild,
+		idle:    idle,
+	}
+}
+
+// start starts the keepalive reads unless they are disabled or run already
+func (w *sessionWatchdog) start() {
+	if w.cfg.Interval <= 0 {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.run(ctx, w.done)
+}
+
+// run reads the server state every interval until ctx is cancelled and
+// rebuilds the session after max_failures failed reads in a row
+func (w *sessionWatchdog) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if w.idle() {
+			failures = 0
+			continue
+		}
+
+		err := w.check(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			if failures > 0 {
+				w.logger.Info("OPC UA keepalive succeeded again", zap.Int("failures", failures))
+			}
+			failures = 0
+			continue
+		}
+
+		failures++
+		if failures < w.cfg.MaxFailures {
+			w.logger.Warn("OPC UA keepalive failed",
+				zap.Int("failures", failures),
+				zap.Int("max_failures", w.cfg.MaxFailures),
+				zap.Error(err))
+			continue
+		}
+
+		w.logger.Warn("OPC UA session unresponsive, rebuilding it before the next collection",
+			zap.Int("failures", failures),
+			zap.Error(err))
+		failures = 0
+		if err := w.rebuild(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Warn("Failed to rebuild OPC UA session", zap.Error(err))
+		}
+	}
+}
+
+// check runs one keepalive read bounded by keepalive.timeout
+func (w *sessionWatchdog) check(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, w.cfg.Timeout)
+	defer cancel()
+	return w.probe(ctx)
+}
+
+// stop ends the keepalive reads. Like reconnectManager.stop, it must not be
+// called while holding the client's lock, which a rebuild takes.
+func (w *sessionWatchdog) stop() {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel, w.done = nil, nil
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// readServerState reads Server/ServerStatus/State and returns an error
+// unless the server reports Running
+func (c *opcuaClient) readServerState(ctx context.Context) error {
+	session, err := c.session()
+	if err != nil {
+		return err
+	}
+
+	resp, err := session.Read(ctx, &ua.ReadRequest{
+		TimestampsToReturn: ua.TimestampsToReturnNeither,
+		NodesToRead: []*ua.ReadValueID{
+			{NodeID: ua.NewNumericNodeID(0, id.Server_ServerStatus_State), AttributeID: ua.AttributeIDValue},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read server state: %w", err)
+	}
+	if len(resp.Results) == 0 {
+		return errors.New("failed to read server state: no results returned")
+	}
+	result := resp.Results[0]
+	if result.Status != ua.StatusOK {
+		return fmt.Errorf("failed to read server state: %w", result.Status)
+	}
+	if result.Value == nil {
+		return errors.New("failed to read server state: no value returned")
+	}
+
+	state, ok := result.Value.Value().(int32)
+	if !ok {
+		return fmt.Errorf("unexpected server state type %T", result.Value.Value())
+	}
+	if ua.ServerState(state) != ua.ServerStateRunning {
+		return fmt.Errorf("%w: state %d", errServerNotRunning, state)
+	}
+	return nil
+}
+
+// keepAliveIdle reports whether the watchdog has no session to watch because
+// the reconnect manager recovers it or gave up
+func (c *opcuaClient) keepAliveIdle() bool {
+	return c.reconnect.currentState() != sessionConnected
+}
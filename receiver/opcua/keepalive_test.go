@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestKeepAliveConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "disabled by default",
+			modify: func(*Config) {},
+		},
+		{
+			name:   "enabled",
+			modify: func(cfg *Config) { cfg.KeepAlive.Interval = 10 * time.Second },
+		},
+		{
+			name:    "negative interval",
+			modify:  func(cfg *Config) { cfg.KeepAlive.Interval = -time.Second },
+			wantErr: "interval must not be negative",
+		},
+		{
+			name:    "interval below one second",
+			modify:  func(cfg *Config) { cfg.KeepAlive.Interval = 500 * time.Millisecond },
+			wantErr: "interval must be at least 1 second",
+		},
+		{
+			name: "timeout above interval",
+			modify: func(cfg *Config) {
+				cfg.KeepAlive.Interval = 2 * time.Second
+				cfg.KeepAlive.Timeout = 5 * time.Second
+			},
+			wantErr: "timeout must be positive and at most interval",
+		},
+		{
+			name: "no failures allowed",
+			modify: func(cfg *Config) {
+				cfg.KeepAlive.Interval = 10 * time.Second
+				cfg.KeepAlive.MaxFailures = 0
+			},
+			wantErr: "max_failures must be at least 1",
+		},
+		{
+			name: "pubsub mode",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Address = "tcp://broker:1883"
+				cfg.PubSub.Topic = "opcua/#"
+				cfg.KeepAlive.Interval = 10 * time.Second
+			},
+			wantErr: "keepalive is not available in pubsub mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// fakeKeepAlive is a server whose state reads fail while failing is set
+type fakeKeepAlive struct {
+	mu       sync.Mutex
+	failing  bool
+	idle     bool
+	probes   int
+	rebuilds int
+}
+
+func (f *fakeKeepAlive) probe(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.probes++
+	if f.failing {
+		return errServerNotRunning
+	}
+	return nil
+}
+
+func (f *fakeKeepAlive) rebuild(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.rebuilds++
+	f.failing = false
+	return nil
+}
+
+func (f *fakeKeepAlive) isIdle() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.idle
+}
+
+func (f *fakeKeepAlive) counts() (probes, rebuilds int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.probes, f.rebuilds
+}
+
+func newTestWatchdog(f *fakeKeepAlive, maxFailures int) (*sessionWatchdog, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg := KeepAliveConfig{Interval: time.Millisecond, Timeout: time.Second, MaxFailures: maxFailures}
+	return newSessionWatchdog(cfg, zap.New(core), f.probe, f.rebuild, f.isIdle), logs
+}
+
+func TestSessionWatchdogRebuildsUnresponsiveSession(t *testing.T) {
+	f := &fakeKeepAlive{failing: true}
+	w, logs := newTestWatchdog(f, 3)
+	w.start()
+	defer w.stop()
+
+	require.Eventually(t, func() bool {
+		_, rebuilds := f.counts()
+		return rebuilds == 1
+	}, 5*time.Second, time.Millisecond)
+	w.stop()
+
+	// The session is rebuilt after max_failures reads and not again once they succeed
+	probes, rebuilds := f.counts()
+	assert.GreaterOrEqual(t, probes, 3)
+	assert.Equal(t, 1, rebuilds)
+	assert.Equal(t, 2, logs.FilterMessage("OPC UA keepalive failed").Len())
+	assert.Equal(t, 1, logs.FilterMessage("OPC UA session unresponsive, rebuilding it before the next collection").Len())
+}
+
+func TestSessionWatchdogSkipsIdleSession(t *testing.T) {
+	f := &fakeKeepAlive{failing: true, idle: true}
+	w, _ := newTestWatchdog(f, 1)
+	w.start()
+	time.Sleep(20 * time.Millisecond)
+	w.stop()
+
+	probes, rebuilds := f.counts()
+	assert.Zero(t, probes)
+	assert.Zero(t, rebuilds)
+}
+
+func TestSessionWatchdogDisabled(t *testing.T) {
+	f := &fakeKeepAlive{}
+	w := newSessionWatchdog(KeepAliveConfig{}, zap.NewNop(), f.probe, f.rebuild, f.isIdle)
+	w.start()
+	w.stop()
+
+	assert.Nil(t, w.done)
+	probes, _ := f.counts()
+	assert.Zero(t, probes)
+}
+
+func TestSessionWatchdogTimeout(t *testing.T) {
+	w := newSessionWatchdog(KeepAliveConfig{Interval: time.Second, Timeout: time.Millisecond, MaxFailures: 1}, zap.NewNop(),
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, nil, nil)
+
+	require.ErrorIs(t, w.check(context.Background()), context.DeadlineExceeded)
+}
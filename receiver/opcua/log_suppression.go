@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxSuppressedKeys bounds the keys a warningLimiter remembers; expired keys
+// are dropped once it is exceeded
+const maxSuppressedKeys = 1024
+
+// warningLimiter logs a recurring warning once per key and interval. Repeats
+// within the interval are counted and reported with the next warning after
+// it, so a permanently failing LogObject logs one "still failing" summary per
+// interval instead of one warning per page. The zero value suppresses nothing.
+type warningLimiter struct {
+	interval time.Duration
+	// now returns the current time, time.Now if nil
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*suppressedWarning
+}
+
+// suppressedWarning is the suppression state of one key
+type suppressedWarning struct {
+	next       time.Time
+	suppressed int
+}
+
+// Warn logs msg at warn level unless key was logged within the interval
+func (l *warningLimiter) Warn(logger *zap.Logger, key, msg string, fields ...zap.Field) {
+	l.log(logger, zapcore.WarnLevel, key, msg, fields)
+}
+
+// Error logs msg at error level unless key was logged within the interval
+func (l *warningLimiter) Error(logger *zap.Logger, key, msg string, fields ...zap.Field) {
+	l.log(logger, zapcore.ErrorLevel, key, msg, fields)
+}
+
+func (l *warningLimiter) log(logger *zap.Logger, level zapcore.Level, key, msg string, fields []zap.Field) {
+	if l.interval <= 0 {
+		logger.Log(level, msg, fields...)
+		return
+	}
+
+	l.mu.Lock()
+	now := l.clock()
+	entry, ok := l.entries[key]
+	if ok && now.Before(entry.next) {
+		entry.suppressed++
+		l.mu.Unlock()
+		return
+	}
+	suppressed := 0
+	if ok {
+		suppressed = entry.suppressed
+	}
+	if l.entries == nil {
+		l.entries = make(map[string]*suppressedWarning)
+	}
+	if !ok && len(l.entries) >= maxSuppressedKeys {
+		l.prune(now)
+	}
+	l.entries[key] = &suppressedWarning{next: now.Add(l.interval)}
+	l.mu.Unlock()
+
+	if suppressed > 0 {
+		msg = fmt.Sprintf("%s (still failing, suppressed %d times)", msg, suppressed)
+		fields = append(fields, zap.Int("suppressed", suppressed))
+	}
+	logger.Log(level, msg, fields...)
+}
+
+// Clear forgets key after the condition it reports went away, so that its
+// next occurrence is logged right away. Suppressed repeats are reported.
+func (l *warningLimiter) Clear(logger *zap.Logger, key string) {
+	l.mu.Lock()
+	entry, ok := l.entries[key]
+	delete(l.entries, key)
+	l.mu.Unlock()
+
+	if ok && entry.suppressed > 0 {
+		logger.Info("Suppressed warning no longer occurring",
+			zap.String("key", key),
+			zap.Int("suppressed", entry.suppressed))
+	}
+}
+
+// prune drops the keys whose interval is over. Their suppressed repeats are lost.
+func (l *warningLimiter) prune(now time.Time) {
+	for key, entry := range l.entries {
+		if !now.Before(entry.next) {
+			delete(l.entries, key)
+		}
+	}
+}
+
+func (l *warningLimiter) clock() time.Time {
+	if l.now == nil {
+		return time.Now()
+	}
+	return l.now()
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestWarningLimiter(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := zap.New(core)
+	clk := newFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	l := warningLimiter{interval: time.Minute, now: clk.Now}
+
+	for i := 0; i < 5; i++ {
+		l.Warn(logger, "a", "LogObject failing", zap.Int("attempt", i))
+	}
+	l.Warn(logger, "b", "other failure")
+	require.Equal(t, 2, logs.Len())
+	assert.Equal(t, "LogObject failing", logs.All()[0].Message)
+	assert.Equal(t, int64(0), logs.All()[0].ContextMap()["attempt"])
+
+	clk.Advance(time.Minute)
+	l.Warn(logger, "a", "LogObject failing")
+	entry := logs.All()[2]
+	assert.Equal(t, "LogObject failing (still failing, suppressed 4 times)", entry.Message)
+	assert.Equal(t, int64(4), entry.ContextMap()["suppressed"])
+
+	// A cleared key reports its suppressed repeats and logs the next occurrence
+	l.Warn(logger, "a", "LogObject failing")
+	l.Clear(logger, "a")
+	assert.Equal(t, "Suppressed warning no longer occurring", logs.All()[3].Message)
+	l.Warn(logger, "a", "LogObject failing")
+	assert.Equal(t, "LogObject failing", logs.All()[4].Message)
+
+	// Clearing a key without suppressed repeats is silent
+	l.Clear(logger, "b")
+	assert.Equal(t, 5, logs.Len())
+}
+
+func TestWarningLimiterDisabled(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	var l warningLimiter
+	for i := 0; i < 3; i++ {
+		l.Error(zap.New(core), "a", "failure")
+	}
+	assert.Equal(t, 3, logs.Len())
+}
+
+func TestWarningLimiterPrunesExpiredKeys(t *testing.T) {
+	clk := newFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	l := warningLimiter{interval: time.Minute, now: clk.Now}
+	for i := 0; i < maxSuppressedKeys; i++ {
+		l.Warn(zap.NewNop(), fmt.Sprintf("key-%d", i), "failure")
+	}
+	require.Len(t, l.entries, maxSuppressedKeys)
+
+	clk.Advance(time.Minute)
+	l.Warn(zap.NewNop(), "new", "failure")
+	assert.Len(t, l.entries, 1)
+}
+
+func TestClientWireSuppressesRepeatedWarnings(t *testing.T) {
+	ctx := context.Background()
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(3))
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, Status: ua.StatusBadInternalError})
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	clk := newFakeClock(time.Now())
+	c := newOPCUAClient(ws.newWireConfig(), zap.New(core))
+	c.warnings = warningLimiter{interval: time.Minute, now: clk.Now}
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+
+	for i := 0; i < 5; i++ {
+		_, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+		require.NoError(t, err)
+	}
+	failures := logs.FilterMessageSnippet("Failed to call GetRecords method on LogObject")
+	require.Equal(t, 1, failures.Len())
+	assert.Equal(t, "method_call", failures.All()[0].ContextMap()["error_class"])
+
+	clk.Advance(time.Minute)
+	_, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+	require.NoError(t, err)
+	failures = logs.FilterMessageSnippet("Failed to call GetRecords method on LogObject")
+	require.Equal(t, 2, failures.Len())
+	assert.Contains(t, failures.All()[1].Message, "still failing, suppressed 4 times")
+
+	ws.ClearFaults()
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 100)
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+	assert.Equal(t, 1, logs.FilterMessage("Suppressed warning no longer occurring").Len())
+}
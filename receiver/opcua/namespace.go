@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// namespaceURIPrefix starts a NodeID naming its namespace by URI, such as
+// "nsu=http://vendor.com/UA/;i=5001", instead of by index
+const namespaceURIPrefix = "nsu="
+
+// hasNamespaceURI reports whether s is a NodeID with a namespace URI
+func hasNamespaceURI(s string) bool {
+	return strings.HasPrefix(s, namespaceURIPrefix)
+}
+
+// resolveNodeID parses a NodeID. A namespace URI is mapped to its index in
+// namespaces, the NamespaceArray of the server.
+func resolveNodeID(s string, namespaces []string) (*ua.NodeID, error) {
+	if !hasNamespaceURI(s) {
+		return ua.ParseNodeID(s)
+	}
+	expanded, err := ua.ParseExpandedNodeID(s, namespaces)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", s, err)
+	}
+	// The expanded NodeID carries the namespace URI flag in its encoding
+	// mask, re-parse with the index to get a plain NodeID for requests
+	_, identifier, _ := strings.Cut(s, ";")
+	return ua.ParseNodeID(fmt.Sprintf("ns=%d;%s", expanded.NodeID.Namespace(), identifier))
+}
+
+// validateNodeID checks the syntax of a NodeID, with a namespace index or
+// URI, without resolving the URI
+func validateNodeID(s string) error {
+	if !isNodeID(s) {
+		return fmt.Errorf("%s is not a NodeID", s)
+	}
+	if !hasNamespaceURI(s) {
+		_, err := ua.ParseNodeID(s)
+		return err
+	}
+	uri, identifier, _ := strings.Cut(strings.TrimPrefix(s, namespaceURIPrefix), ";")
+	if uri == "" || identifier == "" {
+		return fmt.Errorf("%s needs a namespace URI and an identifier", s)
+	}
+	_, err := ua.ParseExpandedNodeID(s, []string{"", uri})
+	return err
+}
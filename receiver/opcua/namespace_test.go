@@ -0,0 +1,145 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestResolveNodeID(t *testing.T) {
+	namespaces := []string{"http://opcfoundation.org/UA/", "urn:server", "http://vendor.com/UA/"}
+
+	tests := []struct {
+		s        string
+		expected *ua.NodeID
+		wantErr  string
+	}{
+		{s: "ns=2;i=5001", expected: ua.NewNumericNodeID(2, 5001)},
+		{s: "i=2042", expected: ua.NewNumericNodeID(0, 2042)},
+		{s: "nsu=http://vendor.com/UA/;i=5001", expected: ua.NewNumericNodeID(2, 5001)},
+		{s: "nsu=http://vendor.com/UA/;s=PLC1.DeviceLog", expected: ua.NewStringNodeID(2, "PLC1.DeviceLog")},
+		{s: "nsu=http://opcfoundation.org/UA/;i=2042", expected: ua.NewNumericNodeID(0, 2042)},
+		{s: "nsu=http://other.com/UA/;i=5001", wantErr: "not found in the server NamespaceArray"},
+		{s: "nsu=http://vendor.com/UA/;i=abc", wantErr: "failed to resolve"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			nodeID, err := resolveNodeID(tt.s, namespaces)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(nodeID), "got %s", nodeID)
+			assert.False(t, nodeID.URIFlag(), "resolved NodeID must encode without a namespace URI")
+		})
+	}
+}
+
+func TestNamespaceURIConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name: "log object path and type id with namespace URIs",
+			modify: func(cfg *Config) {
+				cfg.LogObjectPaths = []string{"nsu=http://vendor.com/UA/;i=1000", "Objects/ServerLog"}
+				cfg.LogRecordTypeID = "nsu=http://vendor.com/UA/;i=5001"
+			},
+		},
+		{
+			name:    "log object path without identifier",
+			modify:  func(cfg *Config) { cfg.LogObjectPaths = []string{"nsu=http://vendor.com/UA/"} },
+			wantErr: "invalid log_object_paths[0]",
+		},
+		{
+			name:    "empty namespace URI",
+			modify:  func(cfg *Config) { cfg.LogRecordTypeID = "nsu=;i=5001" },
+			wantErr: "invalid log_record_type_id",
+		},
+		{
+			name:    "malformed type id",
+			modify:  func(cfg *Config) { cfg.LogRecordTypeID = "5001" },
+			wantErr: "invalid log_record_type_id",
+		},
+		{
+			name: "metric node with namespace URI",
+			modify: func(cfg *Config) {
+				cfg.Metrics = []VariableMetricConfig{{Node: "nsu=http://vendor.com/UA/;i=abc", Name: "machine.temperature"}}
+			},
+			wantErr: "invalid metrics[0]: invalid node",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestClientWireNamespaceURI(t *testing.T) {
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(3))
+
+	tests := []struct {
+		name       string
+		typeID     string
+		records    int
+		connectErr string
+	}{
+		{name: "any type", records: 3},
+		{name: "matching type", typeID: "nsu=http://opcfoundation.org/UA/;i=5001", records: 3},
+		{name: "other namespace", typeID: "nsu=" + wireApplicationURI + ";i=5001", records: 0},
+		{name: "unknown namespace", typeID: "nsu=http://vendor.com/UA/;i=5001", connectErr: "failed to resolve log_record_type_id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := ws.newWireConfig()
+			cfg.LogObjectPaths = []string{"nsu=" + wireApplicationURI + ";i=1000"}
+			cfg.LogRecordTypeID = tt.typeID
+			require.NoError(t, cfg.Validate())
+
+			c := newOPCUAClient(cfg, zap.NewNop())
+			err := c.Connect(ctx)
+			defer func() {
+				assert.NoError(t, c.Disconnect(ctx))
+			}()
+			if tt.connectErr != "" {
+				require.ErrorContains(t, err, tt.connectErr)
+				assert.Equal(t, "discovery", errorClass(err))
+				return
+			}
+			require.NoError(t, err)
+
+			// The URI maps to the index the server uses for it
+			require.Len(t, c.logObjectIDs, 1)
+			assert.True(t, ws.logObjectID.Equal(c.logObjectIDs[0]))
+
+			records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+			require.NoError(t, err)
+			assert.Len(t, records, tt.records)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"sync"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// Record slices, attribute maps and codec buffers are reused between scrapes
+// so that short collection intervals on small edge gateways do not allocate
+// the same memory again on every scrape. Oversized values are left to the
+// garbage collector so a single large scrape does not pin its memory.
+const (
+	// maxPooledRecords is the largest record slice capacity kept for reuse
+	maxPooledRecords = 16 * 1024
+	// maxPooledAttributes is the largest attribute map kept for reuse
+	maxPooledAttributes = 64
+	// maxPooledBuffer is the largest codec buffer capacity kept for reuse
+	maxPooledBuffer = 64 * 1024
+)
+
+var (
+	recordSlicePool = sync.Pool{New: func() interface{} {
+		records := make([]model.LogRecord, 0, 64)
+		return &records
+	}}
+	attributeMapPool = sync.Pool{New: func() interface{} {
+		return make(map[string]interface{})
+	}}
+	codecBufferPool = sync.Pool{New: func() interface{} {
+		buf := make([]byte, 0, 512)
+		return &buf
+	}}
+)
+
+// getRecordSlice returns an empty record slice, reused if one is available
+func getRecordSlice() []model.LogRecord {
+	return (*recordSlicePool.Get().(*[]model.LogRecord))[:0]
+}
+
+// putRecordSlice returns the backing array of records for reuse. The records
+// are zeroed; their attribute maps stay with whoever copied the records.
+func putRecordSlice(records []model.LogRecord) {
+	if cap(records) == 0 || cap(records) > maxPooledRecords {
+		return
+	}
+	clear(records)
+	records = records[:0]
+	recordSlicePool.Put(&records)
+}
+
+// releaseRecords returns records and their attribute maps for reuse once
+// they were transformed and nothing refers to them any more
+func releaseRecords(records []model.LogRecord) {
+	for i := range records {
+		putAttributeMap(records[i].Attributes)
+	}
+	putRecordSlice(records)
+}
+
+// getAttributeMap returns an empty attribute map, reused if one is available
+func getAttributeMap() map[string]interface{} {
+	return attributeMapPool.Get().(map[string]interface{})
+}
+
+// putAttributeMap returns an attribute map for reuse
+func putAttributeMap(m map[string]interface{}) {
+	if m == nil || len(m) > maxPooledAttributes {
+		return
+	}
+	clear(m)
+	attributeMapPool.Put(m)
+}
+
+// getCodecBuffer returns an empty buffer for binary encoding
+func getCodecBuffer() *[]byte {
+	buf := codecBufferPool.Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// putCodecBuffer returns a buffer for reuse; its contents must not be used afterwards
+func putCodecBuffer(buf *[]byte) {
+	if cap(*buf) > maxPooledBuffer {
+		return
+	}
+	codecBufferPool.Put(buf)
+}
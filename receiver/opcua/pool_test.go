@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+func TestReleaseRecords(t *testing.T) {
+	attrs := getAttributeMap()
+	attrs["component"] = "pump"
+	records := append(getRecordSlice(), model.LogRecord{Message: "first", Attributes: attrs})
+
+	releaseRecords(records)
+
+	assert.Empty(t, attrs, "released attribute maps are cleared")
+	assert.Equal(t, model.LogRecord{}, records[0], "released records are zeroed")
+	assert.Empty(t, getRecordSlice())
+	assert.Empty(t, getAttributeMap())
+}
+
+func TestPutAttributeMapKeepsOversizedMaps(t *testing.T) {
+	attrs := make(map[string]interface{}, maxPooledAttributes+1)
+	for i := 0; i <= maxPooledAttributes; i++ {
+		attrs[string(rune('a'+i%26))+string(rune('a'+i/26))] = i
+	}
+	putAttributeMap(attrs)
+	assert.Len(t, attrs, maxPooledAttributes+1)
+}
+
+func TestAppendEncode(t *testing.T) {
+	record := &client.LogRecordExtObj{
+		Time:           time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC),
+		Severity:       300,
+		Message:        "pump started",
+		AdditionalData: map[string]interface{}{"component": "pump"},
+	}
+	want, err := record.Encode()
+	require.NoError(t, err)
+
+	buf := getCodecBuffer()
+	defer putCodecBuffer(buf)
+	*buf = append(*buf, 0xff)
+	got, err := record.AppendEncode(*buf)
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{0xff}, want...), got)
+}
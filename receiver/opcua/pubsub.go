@@ -0,0 +1,535 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+const (
+	// collectionModePubSub receives DataSets published with OPC UA PubSub
+	// and opens no session
+	collectionModePubSub = "pubsub"
+
+	pubsubTransportMQTT = "mqtt"
+	pubsubTransportUDP  = "udp"
+
+	pubsubEncodingJSON = "json"
+	pubsubEncodingUADP = "uadp"
+
+	// udpAddressPrefix is the scheme of PubSub UDP addresses, which may be
+	// left out
+	udpAddressPrefix = "opc.udp://"
+
+	// maxDatagramSize is the largest UDP datagram
+	maxDatagramSize = 65535
+
+	// mqttDisconnectQuiesce is the time in milliseconds MQTT waits for
+	// outstanding work when disconnecting
+	mqttDisconnectQuiesce = 250
+)
+
+// pubsubMessage is a NetworkMessage with the DataSetMessages it carries
+type pubsubMessage struct {
+	publisherID string
+	timestamp   time.Time
+	dataSets    []dataSetMessage
+}
+
+// dataSetMessage is a DataSetMessage. fields is nil for messages without a
+// complete set of fields, such as delta frames and keep alives.
+type dataSetMessage struct {
+	writerID  uint16
+	timestamp time.Time
+	fields    map[string]interface{}
+}
+
+// pubsubSubscriber receives the records of DataSets published with OPC UA
+// PubSub, for devices whose firewall allows no client/server session. It
+// implements eventSubscriber, so a broken connection is retried like a
+// broken subscription.
+type pubsubSubscriber struct {
+	config      *PubSubConfig
+	minSeverity uint16
+	logger      *zap.Logger
+	warnings    warningLimiter
+	// now returns the time of records without timestamp, time.Now if nil
+	now func() time.Time
+}
+
+func newPubSubSubscriber(config *Config, logger *zap.Logger) *pubsubSubscriber {
+	p := &pubsubSubscriber{
+		config:      &config.PubSub,
+		minSeverity: config.Filter.minSeverityValue(),
+		logger:      logger,
+	}
+	p.warnings.interval = config.LogSuppressionInterval
+	return p
+}
+
+// subscribeEvents connects to the broker or listens for datagrams and
+// delivers the records of the received messages
+func (p *pubsubSubscriber) subscribeEvents(ctx context.Context, _ SubscriptionConfig, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	if p.config.Transport == pubsubTransportUDP {
+		conn, err := listenUDP(p.config.Address)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		p.logger.Info("Listening for PubSub datagrams", zap.String("address", conn.LocalAddr().String()))
+		if err := onSubscribed(ctx); err != nil {
+			return err
+		}
+		return p.readUDP(ctx, conn, deliver)
+	}
+	return p.subscribeMQTT(ctx, onSubscribed, deliver)
+}
+
+// listenUDP listens on a UDP address and joins its group if it is multicast
+func listenUDP(address string) (*net.UDPConn, error) {
+	addr, err := net.ResolveUDPAddr("udp", strings.TrimPrefix(address, udpAddressPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PubSub address: %w", err)
+	}
+	var conn *net.UDPConn
+	if addr.IP.IsMulticast() {
+		conn, err = net.ListenMulticastUDP("udp", nil, addr)
+	} else {
+		conn, err = net.ListenUDP("udp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for PubSub datagrams: %w", err)
+	}
+	return conn, nil
+}
+
+// readUDP delivers the records of the datagrams read from conn until ctx is
+// done
+func (p *pubsubSubscriber) readUDP(ctx context.Context, conn net.PacketConn, deliver func([]model.LogRecord)) error {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read PubSub datagram: %w", err)
+		}
+		// Decoded ByteStrings refer to the payload, which must outlive buf
+		p.handle(bytes.Clone(buf[:n]), deliver)
+	}
+}
+
+// subscribeMQTT subscribes to the topic at the broker and delivers the
+// records of the published messages until ctx is done or the connection is
+// lost
+func (p *pubsubSubscriber) subscribeMQTT(ctx context.Context, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	messages := make(chan []byte, eventNotificationBuffer)
+	lost := make(chan error, 1)
+
+	opts := mqtt.NewClientOptions().
+		AddBroker(p.config.Address).
+		SetClientID(p.config.ClientID).
+		SetUsername(p.config.Username).
+		SetPassword(string(p.config.Password)).
+		SetAutoReconnect(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			select {
+			case lost <- err:
+			default:
+			}
+		})
+	tlsConfig, err := p.config.TLS.LoadTLSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load PubSub TLS configuration: %w", err)
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
+	client := mqtt.NewClient(opts)
+	if err := waitMQTT(ctx, client.Connect()); err != nil {
+		return fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+	defer client.Disconnect(mqttDisconnectQuiesce)
+
+	// Messages are handed to the calling goroutine, which delivers them
+	handler := func(_ mqtt.Client, msg mqtt.Message) {
+		select {
+		case messages <- msg.Payload():
+		case <-ctx.Done():
+		}
+	}
+	if err := waitMQTT(ctx, client.Subscribe(p.config.Topic, p.config.QoS, handler)); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", p.config.Topic, err)
+	}
+	p.logger.Info("Subscribed to PubSub topic",
+		zap.String("broker", p.config.Address),
+		zap.String("topic", p.config.Topic))
+
+	if err := onSubscribed(ctx); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-lost:
+			return fmt.Errorf("MQTT connection lost: %w", err)
+		case payload := <-messages:
+			p.handle(payload, deliver)
+		}
+	}
+}
+
+// waitMQTT waits for an MQTT operation to complete or ctx to be done
+func waitMQTT(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handle delivers the records of a message. Messages that cannot be decoded
+// are dropped with a warning.
+func (p *pubsubSubscriber) handle(payload []byte, deliver func([]model.LogRecord)) {
+	records, err := p.records(payload)
+	if err != nil {
+		p.warnings.Warn(p.logger, "decode_pubsub_message", "Dropping PubSub message that cannot be decoded", zap.Error(err))
+		return
+	}
+	if len(records) > 0 {
+		deliver(records)
+	}
+}
+
+// records decodes a message and converts its DataSetMessages to log records.
+// DataSetMessages of other publishers and writers than configured, and
+// those below min_severity, are dropped.
+func (p *pubsubSubscriber) records(payload []byte) ([]model.LogRecord, error) {
+	var msg *pubsubMessage
+	var err error
+	if p.config.encoding() == pubsubEncodingUADP {
+		msg, err = decodeUADP(payload, p.config.DataSet.Fields)
+	} else {
+		msg, err = decodeJSONMessage(payload)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.config.DataSet.PublisherID != "" && msg.publisherID != p.config.DataSet.PublisherID {
+		return nil, nil
+	}
+
+	records := getRecordSlice()
+	for _, dataSet := range msg.dataSets {
+		if dataSet.fields == nil {
+			continue
+		}
+		if len(p.config.DataSet.WriterIDs) > 0 && !slices.Contains(p.config.DataSet.WriterIDs, dataSet.writerID) {
+			continue
+		}
+		record := dataSetRecord(dataSet.fields)
+		if _, ok := dataSet.fields["Severity"]; ok && record.Severity < p.minSeverity {
+			putAttributeMap(record.Attributes)
+			continue
+		}
+		if record.Timestamp.IsZero() {
+			record.Timestamp = p.timestamp(dataSet.timestamp, msg.timestamp)
+		}
+		record.LogObjectID = msg.publisherID + "/" + strconv.FormatUint(uint64(dataSet.writerID), 10)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// timestamp returns the first of the message timestamps that is set, or the
+// current time
+func (p *pubsubSubscriber) timestamp(timestamps ...time.Time) time.Time {
+	for _, t := range timestamps {
+		if !t.IsZero() {
+			return t
+		}
+	}
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// dataSetRecord converts the fields of a DataSetMessage to a log record.
+// Fields named like the BaseEventType fields fill the record, the others
+// become attributes.
+func dataSetRecord(fields map[string]interface{}) model.LogRecord {
+	record := model.LogRecord{Attributes: getAttributeMap()}
+	for name, value := range fields {
+		if value == nil {
+			continue
+		}
+		switch name {
+		case "Time":
+			record.Timestamp = fieldTime(value)
+		case "Severity":
+			record.Severity = fieldSeverity(value)
+		case "Message":
+			record.Message = fieldText(value)
+			record.MessageLocale = fieldLocale(value)
+		case "SourceName":
+			record.SourceName = fieldText(value)
+		case "SourceNode":
+			if nodeID := fieldNodeID(value); nodeID != nil {
+				record.SourceNamespace, record.SourceIDType, record.SourceID = nodeIDComponents(nodeID)
+			}
+		default:
+			record.Attributes[name] = fieldAttribute(value)
+		}
+	}
+	return record
+}
+
+// fieldTime returns a DateTime field, which JSON encodes as an RFC 3339 string
+func fieldTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case string:
+		t, _ := time.Parse(time.RFC3339Nano, v)
+		return t
+	}
+	return time.Time{}
+}
+
+// fieldSeverity returns a Severity field, which JSON encodes as a number
+func fieldSeverity(value interface{}) uint16 {
+	switch v := value.(type) {
+	case uint16:
+		return v
+	case float64:
+		if v >= 0 && v <= math.MaxUint16 {
+			return uint16(v)
+		}
+	}
+	return 0
+}
+
+// fieldText returns a String or LocalizedText field
+func fieldText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case *ua.LocalizedText:
+		if v != nil {
+			return v.Text
+		}
+	case map[string]interface{}:
+		if text, ok := v["Text"].(string); ok {
+			return text
+		}
+	}
+	return ""
+}
+
+// fieldLocale returns the locale of a LocalizedText field, empty for a String
+func fieldLocale(value interface{}) string {
+	switch v := value.(type) {
+	case *ua.LocalizedText:
+		if v != nil {
+			return v.Locale
+		}
+	case map[string]interface{}:
+		if locale, ok := v["Locale"].(string); ok {
+			return locale
+		}
+	}
+	return ""
+}
+
+// fieldNodeID returns a NodeId field, which JSON encodes as a string or as
+// an object with IdType, Id and Namespace
+func fieldNodeID(value interface{}) *ua.NodeID {
+	switch v := value.(type) {
+	case *ua.NodeID:
+		return v
+	case string:
+		nodeID, err := ua.ParseNodeID(v)
+		if err == nil {
+			return nodeID
+		}
+	case map[string]interface{}:
+		namespace, _ := v["Namespace"].(float64)
+		idType, _ := v["IdType"].(float64)
+		prefix := [...]string{"i", "s", "g", "b"}
+		if idType < 0 || int(idType) >= len(prefix) {
+			return nil
+		}
+		nodeID, err := ua.ParseNodeID(fmt.Sprintf("ns=%d;%s=%v", int(namespace), prefix[int(idType)], v["Id"]))
+		if err == nil {
+			return nodeID
+		}
+	}
+	return nil
+}
+
+// fieldAttribute converts a field to an attribute value
+func fieldAttribute(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *ua.LocalizedText:
+		return fieldText(v)
+	case *ua.NodeID:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case float32:
+		return float64(v)
+	}
+	return value
+}
+
+// jsonNetworkMessage is a JSON NetworkMessage, see OPC UA Part 14, 7.2.5.
+// Messages decodes lazily as the DataSetMessages may be objects or an array.
+type jsonNetworkMessage struct {
+	MessageType string          `json:"MessageType"`
+	PublisherID json.RawMessage `json:"PublisherId"`
+	Messages    json.RawMessage `json:"Messages"`
+}
+
+// jsonDataSetMessage is a JSON DataSetMessage. Without DataSetMessage header
+// the object is the payload itself.
+type jsonDataSetMessage struct {
+	DataSetWriterID uint16                 `json:"DataSetWriterId"`
+	Timestamp       string                 `json:"Timestamp"`
+	MessageType     string                 `json:"MessageType"`
+	Payload         map[string]interface{} `json:"Payload"`
+}
+
+// decodeJSONMessage decodes a JSON NetworkMessage, a single DataSetMessage
+// or an array of them, as publishers may leave out the headers
+func decodeJSONMessage(b []byte) (*pubsubMessage, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.New("empty message")
+	}
+
+	msg := &pubsubMessage{}
+	messages := json.RawMessage(b)
+	if b[0] == '{' {
+		var network jsonNetworkMessage
+		if err := json.Unmarshal(b, &network); err != nil {
+			return nil, fmt.Errorf("invalid JSON NetworkMessage: %w", err)
+		}
+		if network.MessageType != "" && network.MessageType != "ua-data" {
+			return nil, fmt.Errorf("unsupported NetworkMessage type %s", network.MessageType)
+		}
+		msg.publisherID = jsonPublisherID(network.PublisherID)
+		if len(network.Messages) > 0 {
+			messages = network.Messages
+		}
+	}
+
+	var raw []json.RawMessage
+	if bytes.HasPrefix(bytes.TrimSpace(messages), []byte("[")) {
+		if err := json.Unmarshal(messages, &raw); err != nil {
+			return nil, fmt.Errorf("invalid JSON DataSetMessages: %w", err)
+		}
+	} else {
+		raw = []json.RawMessage{messages}
+	}
+
+	for _, m := range raw {
+		dataSet, err := decodeJSONDataSet(m)
+		if err != nil {
+			return nil, err
+		}
+		msg.dataSets = append(msg.dataSets, dataSet)
+	}
+	return msg, nil
+}
+
+// decodeJSONDataSet decodes a JSON DataSetMessage. Delta frames and keep
+// alives are returned without fields.
+func decodeJSONDataSet(b json.RawMessage) (dataSetMessage, error) {
+	var dataSet dataSetMessage
+	var header jsonDataSetMessage
+	if err := json.Unmarshal(b, &header); err != nil {
+		return dataSet, fmt.Errorf("invalid JSON DataSetMessage: %w", err)
+	}
+	dataSet.writerID = header.DataSetWriterID
+	dataSet.timestamp = fieldTime(header.Timestamp)
+
+	switch header.MessageType {
+	case "", "ua-keyframe", "ua-event":
+	default:
+		return dataSet, nil
+	}
+
+	payload := header.Payload
+	if payload == nil {
+		if err := json.Unmarshal(b, &payload); err != nil {
+			return dataSet, fmt.Errorf("invalid JSON DataSetMessage: %w", err)
+		}
+	}
+	dataSet.fields = make(map[string]interface{}, len(payload))
+	for name, value := range payload {
+		dataSet.fields[name] = jsonFieldValue(value)
+	}
+	return dataSet, nil
+}
+
+// jsonFieldValue unwraps the reversible Variant ({"Type", "Body"} or
+// {"UaType", "Value"}) and DataValue ({"Value", ...}) encodings of a field
+func jsonFieldValue(value interface{}) interface{} {
+	m, ok := value.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	if body, ok := m["Body"]; ok {
+		return jsonFieldValue(body)
+	}
+	if v, ok := m["Value"]; ok {
+		return jsonFieldValue(v)
+	}
+	return value
+}
+
+// jsonPublisherID returns a PublisherId, which is a JSON string or number
+func jsonPublisherID(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(bytes.TrimSpace(raw))
+}
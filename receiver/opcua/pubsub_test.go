@@ -0,0 +1,355 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+func TestPubSubConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "pubsub ignored when polling",
+			modify: func(cfg *Config) { cfg.PubSub = PubSubConfig{} },
+		},
+		{
+			name: "mqtt",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Address = "tcp://broker:1883"
+				cfg.PubSub.Topic = "opcua/json/data/plc1/#"
+			},
+		},
+		{
+			name: "udp without endpoint",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.Endpoint = ""
+				cfg.PubSub.Transport = pubsubTransportUDP
+				cfg.PubSub.Address = "opc.udp://239.0.0.1:4840"
+			},
+		},
+		{
+			name: "missing topic",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Address = "tcp://broker:1883"
+			},
+			wantErr: "pubsub topic must be specified",
+		},
+		{
+			name: "missing address",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Topic = "opcua/json/data/plc1/#"
+			},
+			wantErr: "pubsub address must be specified",
+		},
+		{
+			name: "unknown transport",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Transport = "amqp"
+			},
+			wantErr: "invalid transport: amqp",
+		},
+		{
+			name: "json over udp",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Transport = pubsubTransportUDP
+				cfg.PubSub.Encoding = pubsubEncodingJSON
+			},
+			wantErr: "udp transport requires uadp encoding",
+		},
+		{
+			name: "qos 2",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.QoS = 2
+			},
+			wantErr: "qos must be 0 or 1",
+		},
+		{
+			name: "variable metrics",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Address = "tcp://broker:1883"
+				cfg.PubSub.Topic = "opcua/json/data/plc1/#"
+				cfg.Metrics = []VariableMetricConfig{{Node: "ns=2;s=Temperature", Name: "temperature"}}
+			},
+			wantErr: "not available in pubsub mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// uadpDataSet is a DataSetMessage encoded by encodeUADP
+type uadpDataSet struct {
+	writerID  uint16
+	timestamp time.Time
+	event     bool
+	fields    []*ua.Variant
+}
+
+// encodeUADP encodes a UADP NetworkMessage with a UInt16 PublisherId, a
+// payload header and Variant fields
+func encodeUADP(t *testing.T, publisherID uint16, timestamp time.Time, dataSets ...uadpDataSet) []byte {
+	t.Helper()
+	messages := make([][]byte, len(dataSets))
+	for i, dataSet := range dataSets {
+		buf := ua.NewBuffer(nil)
+		buf.WriteByte(dataSetMessageValid | dataSetFlags2Enabled | dataSetSequenceNumberEnabled)
+		flags2 := byte(dataSetMessageTypeKeyFrame)
+		if dataSet.event {
+			flags2 = dataSetMessageTypeEvent
+		}
+		if !dataSet.timestamp.IsZero() {
+			flags2 |= dataSetTimestampEnabled
+		}
+		buf.WriteByte(flags2)
+		buf.WriteUint16(uint16(i))
+		if !dataSet.timestamp.IsZero() {
+			buf.WriteTime(dataSet.timestamp)
+		}
+		buf.WriteUint16(uint16(len(dataSet.fields)))
+		for _, field := range dataSet.fields {
+			buf.WriteStruct(field)
+		}
+		require.NoError(t, buf.Error())
+		messages[i] = buf.Bytes()
+	}
+
+	buf := ua.NewBuffer(nil)
+	buf.WriteByte(uadpVersion | uadpPublisherIDEnabled | uadpGroupHeaderEnabled | uadpPayloadHeaderEnabled | uadpExtendedFlags1Enabled)
+	extended1 := byte(1) // UInt16 PublisherId
+	if !timestamp.IsZero() {
+		extended1 |= uadpTimestampEnabled
+	}
+	buf.WriteByte(extended1)
+	buf.WriteUint16(publisherID)
+	buf.WriteByte(uadpWriterGroupIDEnabled | uadpSequenceNumberEnabled)
+	buf.WriteUint16(100)
+	buf.WriteUint16(7)
+	buf.WriteByte(byte(len(dataSets)))
+	for _, dataSet := range dataSets {
+		buf.WriteUint16(dataSet.writerID)
+	}
+	if !timestamp.IsZero() {
+		buf.WriteTime(timestamp)
+	}
+	if len(messages) > 1 {
+		for _, message := range messages {
+			buf.WriteUint16(uint16(len(message)))
+		}
+	}
+	for _, message := range messages {
+		buf.Write(message)
+	}
+	require.NoError(t, buf.Error())
+	return buf.Bytes()
+}
+
+// logEventVariants returns the fields of a log event DataSet in the order
+// of logEventFields
+func logEventVariants(t0 time.Time, severity uint16, message string) []*ua.Variant {
+	return []*ua.Variant{
+		ua.MustVariant(t0),
+		ua.MustVariant(severity),
+		ua.MustVariant(ua.NewLocalizedText(message)),
+		ua.MustVariant("Axis1"),
+		ua.MustVariant(ua.NewStringNodeID(2, "Axis1")),
+	}
+}
+
+func TestDecodeUADP(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	names := []string{"Time", "Severity", "Message", "SourceName", "SourceNode"}
+
+	data := encodeUADP(t, 42, t0,
+		uadpDataSet{writerID: 1, event: true, fields: logEventVariants(t0, 300, "Overtemperature")},
+		uadpDataSet{writerID: 2, timestamp: t0.Add(time.Second), fields: append(logEventVariants(t0, 150, "Started"), ua.MustVariant(int32(7)))},
+	)
+	msg, err := decodeUADP(data, names)
+	require.NoError(t, err)
+
+	assert.Equal(t, "42", msg.publisherID)
+	assert.Equal(t, t0, msg.timestamp)
+	require.Len(t, msg.dataSets, 2)
+
+	assert.Equal(t, uint16(1), msg.dataSets[0].writerID)
+	assert.Equal(t, uint16(300), msg.dataSets[0].fields["Severity"])
+	assert.Equal(t, ua.NewLocalizedText("Overtemperature"), msg.dataSets[0].fields["Message"])
+
+	// Fields beyond the configured names are named by position
+	assert.Equal(t, uint16(2), msg.dataSets[1].writerID)
+	assert.Equal(t, t0.Add(time.Second), msg.dataSets[1].timestamp)
+	assert.Equal(t, int32(7), msg.dataSets[1].fields["Field5"])
+
+	// Secured and truncated messages are rejected
+	secured := append([]byte(nil), data...)
+	secured[1] |= uadpSecurityEnabled
+	_, err = decodeUADP(secured, names)
+	require.ErrorContains(t, err, "secured UADP messages are not supported")
+	_, err = decodeUADP(data[:len(data)-3], names)
+	require.Error(t, err)
+	_, err = decodeUADP([]byte{0x02}, names)
+	require.ErrorContains(t, err, "unsupported UADP version 2")
+}
+
+func TestDecodeJSONMessage(t *testing.T) {
+	network := `{
+		"MessageId": "32235546-05d9-4fd7-97df-ea3ff3408574",
+		"MessageType": "ua-data",
+		"PublisherId": "plc-1",
+		"Messages": [
+			{
+				"DataSetWriterId": 3,
+				"Timestamp": "2025-01-15T10:00:00Z",
+				"MessageType": "ua-event",
+				"Payload": {
+					"Time": "2025-01-15T09:59:59.5Z",
+					"Severity": {"Type": 5, "Body": 300},
+					"Message": {"Type": 21, "Body": {"Locale": "en", "Text": "Overtemperature"}},
+					"SourceNode": {"Type": 17, "Body": {"IdType": 1, "Id": "Axis1", "Namespace": 2}},
+					"Line": "L1"
+				}
+			},
+			{"DataSetWriterId": 3, "MessageType": "ua-keepalive"}
+		]
+	}`
+	msg, err := decodeJSONMessage([]byte(network))
+	require.NoError(t, err)
+	assert.Equal(t, "plc-1", msg.publisherID)
+	require.Len(t, msg.dataSets, 2)
+	assert.Equal(t, uint16(3), msg.dataSets[0].writerID)
+	assert.Equal(t, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC), msg.dataSets[0].timestamp)
+	assert.Nil(t, msg.dataSets[1].fields)
+
+	record := dataSetRecord(msg.dataSets[0].fields)
+	assert.Equal(t, time.Date(2025, 1, 15, 9, 59, 59, 500_000_000, time.UTC), record.Timestamp)
+	assert.Equal(t, uint16(300), record.Severity)
+	assert.Equal(t, "Overtemperature", record.Message)
+	assert.Equal(t, uint16(2), record.SourceNamespace)
+	assert.Equal(t, "String", record.SourceIDType)
+	assert.Equal(t, "Axis1", record.SourceID)
+	assert.Equal(t, map[string]interface{}{"Line": "L1"}, record.Attributes)
+
+	// Without headers the message is the payload of a single DataSetMessage
+	msg, err = decodeJSONMessage([]byte(`{"Severity": 150, "Message": "Started"}`))
+	require.NoError(t, err)
+	require.Len(t, msg.dataSets, 1)
+	assert.Equal(t, map[string]interface{}{"Severity": float64(150), "Message": "Started"}, msg.dataSets[0].fields)
+
+	_, err = decodeJSONMessage([]byte(`{"MessageType": "ua-metadata"}`))
+	require.ErrorContains(t, err, "unsupported NetworkMessage type ua-metadata")
+	_, err = decodeJSONMessage([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestPubSubRecords(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	cfg := createDefaultConfig().(*Config)
+	cfg.PubSub.Encoding = pubsubEncodingUADP
+	p := newPubSubSubscriber(cfg, zap.NewNop())
+	p.now = func() time.Time { return t0.Add(time.Hour) }
+
+	withoutTime := logEventVariants(t0, 300, "No time")
+	withoutTime[0] = ua.MustVariant(time.Time{})
+	data := encodeUADP(t, 42, t0,
+		uadpDataSet{writerID: 1, event: true, fields: logEventVariants(t0, 300, "Overtemperature")},
+		uadpDataSet{writerID: 1, event: true, fields: logEventVariants(t0, 50, "Below min_severity")},
+		uadpDataSet{writerID: 2, event: true, fields: withoutTime},
+	)
+
+	records, err := p.records(data)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Overtemperature", records[0].Message)
+	assert.Equal(t, "42/1", records[0].LogObjectID)
+	// A record without time takes the time of its message
+	assert.Equal(t, t0, records[1].Timestamp)
+	assert.Equal(t, "42/2", records[1].LogObjectID)
+	releaseRecords(records)
+
+	// Other writers and publishers are dropped
+	p.config.DataSet.WriterIDs = []uint16{2}
+	records, err = p.records(data)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "No time", records[0].Message)
+
+	p.config.DataSet.PublisherID = "43"
+	records, err = p.records(data)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+func TestPubSubUDP(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	cfg := createDefaultConfig().(*Config)
+	cfg.PubSub.Transport = pubsubTransportUDP
+	p := newPubSubSubscriber(cfg, zap.NewNop())
+
+	conn, err := listenUDP("opc.udp://127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	delivered := make(chan []model.LogRecord, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- p.readUDP(ctx, conn, func(records []model.LogRecord) { delivered <- records })
+	}()
+
+	sender, err := net.Dial("udp", conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer sender.Close()
+	_, err = sender.Write([]byte("not UADP"))
+	require.NoError(t, err)
+	_, err = sender.Write(encodeUADP(t, 42, t0,
+		uadpDataSet{writerID: 1, event: true, fields: logEventVariants(t0, 300, "Overtemperature")}))
+	require.NoError(t, err)
+
+	select {
+	case records := <-delivered:
+		require.Len(t, records, 1)
+		assert.Equal(t, "Overtemperature", records[0].Message)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no records delivered")
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("readUDP did not return after cancel")
+	}
+}
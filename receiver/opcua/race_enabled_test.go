@@ -0,0 +1,10 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build race
+
+package opcua
+
+// raceEnabled reports whether the tests run with the race detector, which
+// makes allocation counts unreliable
+const raceEnabled = true
@@ -6,82 +6,242 @@ package opcua
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/collector/scraper"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.opentelemetry.io/collector/scraper/scraperhelper"
 	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
 )
 
-// logsReceiver implements the logs receiver
-type logsReceiver struct {
-	config       *Config
-	settings     receiver.Settings
-	nextConsumer consumer.Logs
-	scraper      *scraper
-	cancel       context.CancelFunc
-	done         chan struct{}
-}
+// opcuaReceiver implements the logs, metrics and traces receivers. A single
+// instance is shared between all pipelines configured with the same receiver
+// config, so every signal is fed from one OPC UA connection and scrape loop.
+type opcuaReceiver struct {
+	config      *Config
+	settings    receiver.Settings
+	nextLogs    consumer.Logs
+	nextMetrics consumer.Metrics
+	nextTraces  consumer.Traces
+	scraper     *scraper
+	spool       *logSpool
+	checkpoints *checkpointStore
 
-// newLogsReceiver creates a new logs receiver
-func newLogsReceiver(
-	config *Config,
-	settings receiver.Settings,
-	nextConsumer consumer.Logs,
-) (receiver.Logs, error) {
-	if nextConsumer == nil {
-		return nil, fmt.Errorf("nil nextConsumer")
-	}
+	// controller runs the scrapes of poll mode on the ticks of ticker
+	controller receiver.Logs
+	ticker     *gridTicker
+
+	// discovery runs a receiver per server found at the Local Discovery
+	// Server if discovery.endpoint is set, instead of collecting itself
+	discovery *serverDiscovery
+
+	// cancel stops the goroutine of discovery, pubsub and subscribe mode,
+	// done is closed once it stopped. Both are nil in poll mode.
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// overrunLog suppresses the overrun warning of a permanently slow server
+	overrunLog warningLimiter
+}
 
+// newOPCUAReceiver creates a new receiver without any consumers attached
+func newOPCUAReceiver(config *Config, settings receiver.Settings) (*opcuaReceiver, error) {
 	if err := config.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	scraper := newScraper(config, settings.TelemetrySettings)
+	scraper, err := newScraper(config, settings.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	scraper.buildInfo = settings.BuildInfo
+
+	r := &opcuaReceiver{
+		config:   config,
+		settings: settings,
+		scraper:  scraper,
+	}
+	r.overrunLog.interval = config.LogSuppressionInterval
 
-	return &logsReceiver{
-		config:       config,
-		settings:     settings,
-		nextConsumer: nextConsumer,
-		scraper:      scraper,
-		done:         make(chan struct{}),
-	}, nil
+	if config.Discovery.Endpoint != "" {
+		if r.discovery, err = newServerDiscovery(r); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
 }
 
 // Start starts the receiver
-func (r *logsReceiver) Start(ctx context.Context, host component.Host) error {
-	ctx, r.cancel = context.WithCancel(ctx)
+func (r *opcuaReceiver) Start(ctx context.Context, host component.Host) error {
+	// The receivers of the discovered servers collect, each with a spool and
+	// checkpoint of its own
+	if r.discovery != nil {
+		r.runInBackground(func(ctx context.Context) {
+			r.discovery.run(ctx, host)
+		})
+		r.settings.Logger.Info("OPC UA receiver started",
+			zap.String("discovery_endpoint", r.config.Discovery.Endpoint),
+			zap.Duration("discovery_interval", r.config.Discovery.Interval))
+		return nil
+	}
 
-	// Start the scraper
-	if err := r.scraper.start(ctx, host); err != nil {
-		return fmt.Errorf("failed to start scraper: %w", err)
+	// Open the persistent log spool before the first scrape so batches left
+	// over from a previous run are delivered first
+	if r.config.StorageID != nil && r.nextLogs != nil {
+		spool, err := newLogSpool(ctx, host, *r.config.StorageID, r.settings.ID, r.config.MaxSpooledBatches, r.scraper.telemetry, r.settings.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to open log spool: %w", err)
+		}
+		r.spool = spool
+	}
+
+	// Continue with the collection window after the one a previous run
+	// collected last
+	if r.config.StorageID != nil {
+		if err := r.openCheckpoint(ctx, host); err != nil {
+			return err
+		}
 	}
 
-	// Start periodic collection
-	go r.runCollection(ctx)
+	if r.config.Mode == collectionModePubSub {
+		// PubSub messages arrive without a session to the server
+		subscriber := newPubSubSubscriber(r.config, r.settings.Logger)
+		r.runInBackground(func(ctx context.Context) {
+			r.collectEvents(ctx, subscriber)
+		})
+	} else {
+		r.streamBatches()
+
+		// Start the scraper
+		if err := r.scraper.start(ctx, host); err != nil {
+			return fmt.Errorf("failed to start scraper: %w", err)
+		}
+
+		if subscriber, ok := r.eventSubscriber(); ok {
+			r.runInBackground(func(ctx context.Context) {
+				r.collectEvents(ctx, subscriber)
+			})
+		} else if err := r.startController(ctx, host); err != nil {
+			return err
+		}
+	}
 
 	r.settings.Logger.Info("OPC UA receiver started",
 		zap.String("endpoint", r.config.Endpoint),
+		zap.String("mode", r.config.Mode),
 		zap.Duration("collection_interval", r.config.CollectionInterval))
 
 	return nil
 }
 
+// runInBackground runs fn in a goroutine until Shutdown cancels its context.
+// The context is not derived from the one passed to Start, which only
+// covers starting.
+func (r *opcuaReceiver) runInBackground(fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		fn(ctx)
+	}()
+}
+
+// eventSubscriber returns the client's event subscriptions in subscribe
+// and alarms mode; ok is false when records are polled
+func (r *opcuaReceiver) eventSubscriber() (eventSubscriber, bool) {
+	switch r.config.Mode {
+	case collectionModeSubscribe:
+		subscriber, ok := r.scraper.client.(eventSubscriber)
+		if !ok {
+			r.settings.Logger.Warn("OPC UA client does not support event subscriptions, polling instead")
+		}
+		return subscriber, ok
+	case collectionModeAlarms:
+		subscriber, ok := r.scraper.client.(alarmSubscriber)
+		if !ok {
+			r.settings.Logger.Warn("OPC UA client does not support alarm subscriptions, polling instead")
+			return nil, false
+		}
+		return alarmEvents{subscriber: subscriber, notifiers: r.config.Alarms.Notifiers}, true
+	default:
+		return nil, false
+	}
+}
+
+// startController starts polling with a scraperhelper controller, which
+// scrapes after initial_delay and then on the ticks of a gridTicker, bounds
+// each scrape by timeout and reports the standard scraper and receiver
+// telemetry. The logs it returns reach deliverLogs, which spools them for the
+// logs consumer.
+func (r *opcuaReceiver) startController(ctx context.Context, host component.Host) error {
+	logsScraper, err := scraper.NewLogs(r.scrapeLogs)
+	if err != nil {
+		return fmt.Errorf("failed to create scraper: %w", err)
+	}
+	next, err := consumer.NewLogs(r.deliverLogs)
+	if err != nil {
+		return fmt.Errorf("failed to create logs consumer: %w", err)
+	}
+	// The grid starts with the first scrape, after initial_delay
+	ticker := newGridTicker(time.Now().Add(r.config.InitialDelay), r.config.CollectionInterval, func(skipped int) {
+		r.reportOverruns(context.Background(), skipped)
+	})
+	controller, err := scraperhelper.NewLogsController(&r.config.ControllerConfig, r.settings, next,
+		scraperhelper.AddLogsScraper(metadata.Type, logsScraper),
+		scraperhelper.WithTickerChannel(ticker.c))
+	if err != nil {
+		ticker.stop()
+		return fmt.Errorf("failed to create scraper controller: %w", err)
+	}
+	r.controller = controller
+	r.ticker = ticker
+
+	r.settings.Logger.Info("Starting periodic log collection",
+		zap.Duration("interval", r.config.CollectionInterval),
+		zap.Duration("initial_delay", r.config.InitialDelay))
+	return controller.Start(ctx, host)
+}
+
 // Shutdown stops the receiver
-func (r *logsReceiver) Shutdown(ctx context.Context) error {
+func (r *opcuaReceiver) Shutdown(ctx context.Context) error {
+	// The controller returns once the running scrape finished
+	if r.controller != nil {
+		if err := r.controller.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown scraper controller: %w", err)
+		}
+	}
+	if r.ticker != nil {
+		r.ticker.stop()
+		r.ticker = nil
+	}
+
 	if r.cancel != nil {
 		r.cancel()
+
+		// Wait for collection goroutine to finish or timeout
+		select {
+		case <-r.done:
+			r.settings.Logger.Info("Collection goroutine finished")
+		case <-ctx.Done():
+			r.settings.Logger.Warn("Shutdown context timeout, forcing stop")
+		case <-time.After(5 * time.Second):
+			r.settings.Logger.Warn("Collection goroutine did not finish within timeout")
+		}
 	}
 
-	// Wait for collection goroutine to finish or timeout
-	select {
-	case <-r.done:
-		r.settings.Logger.Info("Collection goroutine finished")
-	case <-ctx.Done():
-		r.settings.Logger.Warn("Shutdown context timeout, forcing stop")
-	case <-time.After(5 * time.Second):
-		r.settings.Logger.Warn("Collection goroutine did not finish within timeout")
+	if r.discovery != nil {
+		if err := r.discovery.shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shutdown discovered receivers: %w", err)
+		}
 	}
 
 	// Shutdown the scraper
@@ -89,49 +249,225 @@ func (r *logsReceiver) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("failed to shutdown scraper: %w", err)
 	}
 
+	if r.spool != nil {
+		if err := r.spool.close(ctx); err != nil {
+			return fmt.Errorf("failed to close log spool: %w", err)
+		}
+	}
+
+	if r.checkpoints != nil {
+		if err := r.checkpoints.close(ctx); err != nil {
+			return fmt.Errorf("failed to close checkpoint storage: %w", err)
+		}
+	}
+
 	r.settings.Logger.Info("OPC UA receiver shut down")
 	return nil
 }
 
-// runCollection runs the periodic log collection
-func (r *logsReceiver) runCollection(ctx context.Context) {
-	defer close(r.done)
+// collectEvents collects logs from event notifications until ctx is done,
+// polling the variable metrics alongside
+func (r *opcuaReceiver) collectEvents(ctx context.Context, subscriber eventSubscriber) {
+	var wg sync.WaitGroup
+	if r.nextMetrics != nil && len(r.config.Metrics) > 0 {
+		wg.Go(func() { r.pollVariables(ctx) })
+	}
+	r.runSubscription(ctx, subscriber)
+	wg.Wait()
+}
+
+// scrapeLogs is the scrape function of poll mode. It collects records once,
+// hands them to the metrics and traces consumers, reads the variable metrics
+// and returns the logs for deliverLogs.
+func (r *opcuaReceiver) scrapeLogs(ctx context.Context) (plog.Logs, error) {
+	logs, err := r.collectLogs(ctx)
+	r.consumeVariableMetrics(ctx)
+	return logs, err
+}
+
+// reportOverruns counts the collections skipped because the previous scrape
+// still ran at their start
+func (r *opcuaReceiver) reportOverruns(ctx context.Context, skipped int) {
+	if r.scraper.telemetry != nil {
+		r.scraper.telemetry.OpcuaScrapeOverruns.Add(ctx, int64(skipped))
+	}
+	r.overrunLog.Warn(r.settings.Logger, "overrun",
+		"Scrape took longer than collection_interval, skipping collections",
+		zap.Duration("interval", r.config.CollectionInterval),
+		zap.Int("skipped", skipped))
+}
+
+// collectAndConsume collects log records once and fans them out to every
+// attached consumer, like a scrape of the controller. It returns the error of
+// a failed scrape, which is logged.
+func (r *opcuaReceiver) collectAndConsume(ctx context.Context) error {
+	logs, err := r.collectLogs(ctx)
+	switch {
+	case scrapererror.IsPartialScrapeError(err):
+		r.settings.Logger.Warn("Scraped logs partially", zap.Error(err))
+	case err != nil:
+		r.settings.Logger.Error("Failed to scrape logs", zap.Error(err))
+		return err
+	}
+	// Delivery failures are logged and left to the spool
+	_ = r.deliverLogs(ctx, logs)
+	return nil
+}
+
+// collectLogs collects log records once, hands them to the metrics and
+// traces consumers and returns them as logs. A PartialScrapeError is
+// returned along with the logs of the LogObjects that were read.
+func (r *opcuaReceiver) collectLogs(ctx context.Context) (plog.Logs, error) {
+	windowStart := r.scraper.lastCollectTime
+	records, err := r.scraper.scrapeRecords(ctx)
+	if err != nil && !scrapererror.IsPartialScrapeError(err) {
+		return plog.NewLogs(), err
+	}
+	// The transformers copy what they need, so the records are reused afterwards
+	defer releaseRecords(records)
 
-	ticker := time.NewTicker(r.config.CollectionInterval)
-	defer ticker.Stop()
+	if len(records) == 0 {
+		r.settings.Logger.Debug("No logs collected")
+		return plog.NewLogs(), err
+	}
+	return r.consumeRecords(ctx, records, windowStart, r.scraper.lastCollectTime), err
+}
 
-	r.settings.Logger.Info("Starting periodic log collection",
-		zap.Duration("interval", r.config.CollectionInterval))
+// consumeRecords hands records collected between windowStart and windowEnd
+// to the metrics and traces consumers and returns them as logs, which are
+// empty when no logs consumer is attached
+func (r *opcuaReceiver) consumeRecords(ctx context.Context, records []model.LogRecord, windowStart, windowEnd time.Time) plog.Logs {
+	transformer := r.scraper.transformer
 
-	// Do an initial collection immediately
-	r.collectAndConsume(ctx)
+	if r.nextMetrics != nil {
+		metrics := transformer.TransformMetrics(records, windowStart, windowEnd)
+		if err := r.nextMetrics.ConsumeMetrics(ctx, metrics); err != nil {
+			r.settings.Logger.Error("Failed to consume metrics", zap.Error(err))
+		}
+	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			r.settings.Logger.Info("Collection context cancelled, stopping")
-			return
-		case <-ticker.C:
-			r.collectAndConsume(ctx)
+	if r.nextTraces != nil {
+		if traces := transformer.TransformTraces(records); traces.SpanCount() > 0 {
+			if err := r.nextTraces.ConsumeTraces(ctx, traces); err != nil {
+				r.settings.Logger.Error("Failed to consume traces", zap.Error(err))
+			}
 		}
 	}
+
+	if r.nextLogs == nil {
+		return plog.NewLogs()
+	}
+	return transformer.TransformLogs(records)
 }
 
-// collectAndConsume collects logs and sends them to the next consumer
-func (r *logsReceiver) collectAndConsume(ctx context.Context) {
-	logs, err := r.scraper.scrape(ctx)
-	if err != nil {
-		r.settings.Logger.Error("Failed to scrape logs", zap.Error(err))
+// streamBatches lets the scraper hand the records of a window to the logs
+// consumer in batches of flush_size while it pages through the window. The
+// metrics and spans are built from all records of a window, so the records
+// are kept until the window is read if those signals are consumed too.
+func (r *opcuaReceiver) streamBatches() {
+	if r.config.FlushSize <= 0 || r.nextLogs == nil {
 		return
 	}
+	if r.nextMetrics != nil || r.nextTraces != nil {
+		r.settings.Logger.Info("Ignoring flush_size, metrics and traces are built from whole collection windows",
+			zap.Int("flush_size", r.config.FlushSize))
+		return
+	}
+	r.scraper.onBatch = r.consumeBatch
+}
 
+// consumeBatch transforms a batch of a running scrape and hands it to the
+// logs consumer. A batch that cannot be delivered is left to the spool like
+// the logs of a whole scrape. The checkpoint is saved with the read positions
+// past the batch, so a restart does not read it again either.
+func (r *opcuaReceiver) consumeBatch(ctx context.Context, records []model.LogRecord) {
+	_ = r.consumeLogs(ctx, r.scraper.transformer.TransformLogs(records))
+	r.saveCheckpoint(ctx)
+}
+
+// deliverLogs hands logs to the logs consumer and saves the scrape position
+// once they were handed over. Without logs, it retries the batches a previous
+// collection could not deliver.
+func (r *opcuaReceiver) deliverLogs(ctx context.Context, logs plog.Logs) error {
+	// The window is done once its records were handed over
+	defer r.saveCheckpoint(ctx)
+
+	if r.nextLogs == nil {
+		return nil
+	}
 	if logs.LogRecordCount() == 0 {
-		r.settings.Logger.Debug("No logs collected")
+		if r.spool != nil {
+			r.drainSpool(ctx)
+		}
+		return nil
+	}
+	return r.consumeLogs(ctx, logs)
+}
+
+// openCheckpoint opens the checkpoint storage and restores the scrape
+// position of a previous run
+func (r *opcuaReceiver) openCheckpoint(ctx context.Context, host component.Host) error {
+	store, err := newCheckpointStore(ctx, host, *r.config.StorageID, r.settings.ID)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint storage: %w", err)
+	}
+	r.checkpoints = store
+
+	cp, ok, err := store.load(ctx)
+	if err != nil {
+		// Collecting from start_at again beats not starting at all
+		r.settings.Logger.Warn("Ignoring unreadable checkpoint, collecting from start_at",
+			zap.String("start_at", r.config.StartAt),
+			zap.Error(err))
+		return nil
+	}
+	if ok {
+		r.scraper.restoreCheckpoint(cp)
+		r.settings.Logger.Info("Resuming collection from checkpoint",
+			zap.Time("window_start", cp.LastCollectTime))
+	}
+	return nil
+}
+
+// saveCheckpoint persists the scrape position after a collection
+func (r *opcuaReceiver) saveCheckpoint(ctx context.Context) {
+	if r.checkpoints == nil {
 		return
 	}
+	if err := r.checkpoints.save(ctx, r.scraper.checkpoint()); err != nil {
+		r.settings.Logger.Error("Failed to save checkpoint", zap.Error(err))
+	}
+}
+
+// consumeLogs sends logs to the next consumer, going through the persistent
+// spool when one is configured. It returns the error of a batch that was
+// neither spooled nor accepted.
+func (r *opcuaReceiver) consumeLogs(ctx context.Context, logs plog.Logs) error {
+	if r.spool == nil {
+		if err := r.nextLogs.ConsumeLogs(ctx, logs); err != nil {
+			r.settings.Logger.Error("Failed to consume logs", zap.Error(err))
+			return err
+		}
+		return nil
+	}
+
+	if err := r.spool.push(ctx, logs); err != nil {
+		// Fall back to direct delivery rather than losing the batch
+		r.settings.Logger.Error("Failed to spool logs, sending without persistence", zap.Error(err))
+		if err := r.nextLogs.ConsumeLogs(ctx, logs); err != nil {
+			r.settings.Logger.Error("Failed to consume logs", zap.Error(err))
+			return err
+		}
+	}
+	r.drainSpool(ctx)
+	return nil
+}
 
-	// Send logs to next consumer in pipeline
-	if err := r.nextConsumer.ConsumeLogs(ctx, logs); err != nil {
-		r.settings.Logger.Error("Failed to consume logs", zap.Error(err))
+// drainSpool delivers all spooled batches to the next consumer
+func (r *opcuaReceiver) drainSpool(ctx context.Context) {
+	if err := r.spool.drain(ctx, r.nextLogs.ConsumeLogs); err != nil {
+		r.settings.Logger.Error("Failed to consume spooled logs, retrying on next collection",
+			zap.Uint64("pending_batches", r.spool.pending()),
+			zap.Error(err))
 	}
 }
@@ -0,0 +1,280 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// errReconnecting is wrapped in a connectionError while the session is
+	// being recovered in the background
+	errReconnecting = errors.New("session lost, reconnecting in the background")
+
+	// errReconnectGaveUp is wrapped in a connectionError once reconnect.max_retries
+	// attempts failed
+	errReconnectGaveUp = errors.New("gave up reconnecting after reconnect.max_retries attempts")
+)
+
+// Defaults of ReconnectConfig, also used for zero values of a Config built in code
+const (
+	defaultReconnectInitialInterval     = time.Second
+	defaultReconnectMaxInterval         = 2 * time.Minute
+	defaultReconnectMultiplier          = 2.0
+	defaultReconnectRandomizationFactor = 0.5
+)
+
+// sessionState is the state of the session as tracked by the reconnect manager
+type sessionState int
+
+const (
+	// sessionConnected is a session in use, or one not known to be lost
+	sessionConnected sessionState = iota
+	// sessionReconnecting is a lost session recovered in the background
+	sessionReconnecting
+	// sessionFailed is a session that could not be recovered within max_retries
+	sessionFailed
+)
+
+func (s sessionState) String() string {
+	switch s {
+	case sessionConnected:
+		return "connected"
+	case sessionReconnecting:
+		return "reconnecting"
+	case sessionFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("sessionState(%d)", int(s))
+	}
+}
+
+// sessionRecoverer is implemented by clients that recover a lost session on
+// their own instead of being reconnected by the scraper
+type sessionRecoverer interface {
+	// recoverSession reconnects a lost session or reports the progress of
+	// the recovery. It returns nil once the session is back.
+	recoverSession(ctx context.Context) error
+}
+
+// reconnectManager recovers a lost session. The first attempt is made right
+// away by the caller; if it fails, the manager retries in the background,
+// waiting an exponentially growing, jittered interval between attempts,
+// while callers fail fast.
+type reconnectManager struct {
+	cfg       ReconnectConfig
+	logger    *zap.Logger
+	connect   func(context.Context) error
+	connected func() bool
+
+	// random returns a number in [0, 1) for the jitter, replaced in tests
+	random func() float64
+
+	mu       sync.Mutex
+	state    sessionState
+	attempts int
+	lastErr  error
+	lostAt   time.Time
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// newReconnectManager creates a reconnect manager, filling in the defaults
+// for unset settings
+func newReconnectManager(cfg ReconnectConfig, logger *zap.Logger, connect func(context.Context) error, connected func() bool) *reconnectManager {
+	if cfg.InitialInterval <= 0 {
+		cfg.InitialInterval = defaultReconnectInitialInterval
+	}
+	if cfg.MaxInterval <= 0 {
+		cfg.MaxInterval = defaultReconnectMaxInterval
+	}
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = defaultReconnectMultiplier
+	}
+	return &reconnectManager{
+		cfg:       cfg,
+		logger:    logger,
+		connect:   connect,
+		connected: connected,
+		random:    rand.Float64,
+	}
+}
+
+// backoff returns the wait before the given background attempt, counted
+// from 1: InitialInterval grown by Multiplier per attempt up to MaxInterval,
+// spread by RandomizationFactor in both directions
+func (m *reconnectManager) backoff(attempt int) time.Duration {
+	interval := float64(m.cfg.InitialInterval) * math.Pow(m.cfg.Multiplier, float64(attempt-1))
+	interval = min(interval, float64(m.cfg.MaxInterval))
+	if f := m.cfg.RandomizationFactor; f > 0 {
+		interval *= 1 + f*(2*m.random()-1)
+	}
+	return time.Duration(interval)
+}
+
+// recover handles a lost session. In the connected state it reconnects once
+// and, if that fails, starts the background recovery. While the recovery
+// runs or after it gave up, it returns an error without reconnecting.
+func (m *reconnectManager) recover(ctx context.Context) error {
+	m.mu.Lock()
+	switch m.state {
+	case sessionReconnecting:
+		err := m.lastErr
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %w", errReconnecting, err)
+	case sessionFailed:
+		err := m.lastErr
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %w", errReconnectGaveUp, err)
+	}
+	m.mu.Unlock()
+
+	err := m.connect(ctx)
+	if err == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.state != sessionConnected {
+		// Another caller started the recovery meanwhile
+		return err
+	}
+	m.attempts = 1
+	m.lastErr = err
+	m.lostAt = time.Now()
+	if m.cfg.MaxRetries == 1 {
+		m.setState(sessionFailed, zap.Int("attempts", m.attempts), zap.Error(err))
+		return err
+	}
+	delay := m.backoff(m.attempts)
+	m.setState(sessionReconnecting,
+		zap.Duration("next_attempt_in", delay),
+		zap.Error(err))
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(runCtx, delay, m.done)
+	return err
+}
+
+// run retries the connection until it succeeds, max_retries is reached or
+// ctx is cancelled. delay is the wait before the first retry.
+func (m *reconnectManager) run(ctx context.Context, delay time.Duration, done chan struct{}) {
+	defer close(done)
+	for {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// A session established by someone else ends the recovery as well
+		var err error
+		if !m.connected() {
+			err = m.connect(ctx)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			m.mu.Lock()
+			m.setState(sessionConnected,
+				zap.Int("attempts", m.attempts+1),
+				zap.Duration("downtime", time.Since(m.lostAt)))
+			m.attempts = 0
+			m.lastErr = nil
+			m.mu.Unlock()
+			return
+		}
+
+		m.mu.Lock()
+		m.attempts++
+		m.lastErr = err
+		if m.cfg.MaxRetries > 0 && m.attempts >= m.cfg.MaxRetries {
+			m.setState(sessionFailed,
+				zap.Int("attempts", m.attempts),
+				zap.Duration("downtime", time.Since(m.lostAt)),
+				zap.Error(err))
+			m.mu.Unlock()
+			return
+		}
+		delay = m.backoff(m.attempts)
+		m.mu.Unlock()
+		m.logger.Warn("OPC UA reconnect attempt failed",
+			zap.Int("attempt", m.attempts),
+			zap.Duration("next_attempt_in", delay),
+			zap.Error(err))
+	}
+}
+
+// setState changes the state and logs the transition. m.mu must be held.
+func (m *reconnectManager) setState(state sessionState, fields ...zap.Field) {
+	previous := m.state
+	m.state = state
+	fields = append([]zap.Field{
+		zap.Stringer("state", state),
+		zap.Stringer("previous_state", previous),
+	}, fields...)
+
+	switch state {
+	case sessionReconnecting:
+		m.logger.Warn("OPC UA session lost, reconnecting in the background", fields...)
+	case sessionFailed:
+		m.logger.Error("Giving up reconnecting to OPC UA server, restart the collector to try again", fields...)
+	default:
+		m.logger.Info("OPC UA session recovered", fields...)
+	}
+}
+
+// currentState returns the state of the session
+func (m *reconnectManager) currentState() sessionState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.state
+}
+
+// stop ends the background recovery and resets the manager. It must not be
+// called while holding the client's lock, which the recovery's connect takes.
+func (m *reconnectManager) stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+
+	m.mu.Lock()
+	m.state = sessionConnected
+	m.attempts = 0
+	m.lastErr = nil
+	m.mu.Unlock()
+}
+
+// recoverSession reconnects a lost session, see reconnectManager
+func (c *opcuaClient) recoverSession(ctx context.Context) error {
+	err := c.reconnect.recover(ctx)
+	if err == nil {
+		return nil
+	}
+	var connErr *connectionError
+	if errors.As(err, &connErr) {
+		return err
+	}
+	return newConnectionError(c.config.Endpoint, err)
+}
@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestReconnectConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *ReconnectConfig)
+		wantErr string
+	}{
+		{
+			name:   "defaults",
+			modify: func(*ReconnectConfig) {},
+		},
+		{
+			name:   "zero values",
+			modify: func(cfg *ReconnectConfig) { *cfg = ReconnectConfig{} },
+		},
+		{
+			name:    "negative initial interval",
+			modify:  func(cfg *ReconnectConfig) { cfg.InitialInterval = -time.Second },
+			wantErr: "initial_interval must not be negative",
+		},
+		{
+			name: "max below initial interval",
+			modify: func(cfg *ReconnectConfig) {
+				cfg.InitialInterval = time.Minute
+				cfg.MaxInterval = time.Second
+			},
+			wantErr: "max_interval must be at least initial_interval",
+		},
+		{
+			name:    "shrinking multiplier",
+			modify:  func(cfg *ReconnectConfig) { cfg.Multiplier = 0.5 },
+			wantErr: "multiplier must be at least 1",
+		},
+		{
+			name:    "randomization factor above 1",
+			modify:  func(cfg *ReconnectConfig) { cfg.RandomizationFactor = 1.5 },
+			wantErr: "randomization_factor must be between 0 and 1",
+		},
+		{
+			name:    "negative max retries",
+			modify:  func(cfg *ReconnectConfig) { cfg.MaxRetries = -1 },
+			wantErr: "max_retries must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(&cfg.Reconnect)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReconnectBackoff(t *testing.T) {
+	m := newReconnectManager(ReconnectConfig{
+		InitialInterval:     time.Second,
+		MaxInterval:         10 * time.Second,
+		Multiplier:          2,
+		RandomizationFactor: 0.5,
+	}, zap.NewNop(), nil, nil)
+
+	// The middle of the random range leaves the interval as it is
+	m.random = func() float64 { return 0.5 }
+	assert.Equal(t, time.Second, m.backoff(1))
+	assert.Equal(t, 2*time.Second, m.backoff(2))
+	assert.Equal(t, 8*time.Second, m.backoff(4))
+	assert.Equal(t, 10*time.Second, m.backoff(5))
+	assert.Equal(t, 10*time.Second, m.backoff(50))
+
+	// The jitter spreads by the randomization factor in both directions
+	m.random = func() float64 { return 0 }
+	assert.Equal(t, 2*time.Second, m.backoff(3))
+	m.random = func() float64 { return 1 }
+	assert.Equal(t, 6*time.Second, m.backoff(3))
+}
+
+// fakeSession is a session whose connects fail until ok is set
+type fakeSession struct {
+	mu        sync.Mutex
+	ok        bool
+	connected bool
+	connects  int
+}
+
+func (s *fakeSession) connect(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connects++
+	if !s.ok {
+		return errors.New("connection refused")
+	}
+	s.connected = true
+	return nil
+}
+
+func (s *fakeSession) isConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
+
+func (s *fakeSession) setOK(ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ok = ok
+}
+
+func (s *fakeSession) connectCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connects
+}
+
+func newTestReconnectManager(cfg ReconnectConfig, session *fakeSession) (*reconnectManager, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg.InitialInterval = time.Millisecond
+	cfg.MaxInterval = 5 * time.Millisecond
+	m := newReconnectManager(cfg, zap.New(core), session.connect, session.isConnected)
+	return m, logs
+}
+
+func TestReconnectManagerRecovers(t *testing.T) {
+	session := &fakeSession{}
+	m, logs := newTestReconnectManager(ReconnectConfig{}, session)
+	defer m.stop()
+
+	// The immediate attempt fails and leaves the retries to the background
+	err := m.recover(context.Background())
+	require.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, sessionReconnecting, m.currentState())
+
+	// Callers fail fast while the recovery runs
+	err = m.recover(context.Background())
+	require.ErrorIs(t, err, errReconnecting)
+
+	session.setOK(true)
+	require.Eventually(t, func() bool {
+		return m.currentState() == sessionConnected
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, m.recover(context.Background()))
+
+	lost := logs.FilterMessage("OPC UA session lost, reconnecting in the background").All()
+	require.Len(t, lost, 1)
+	assert.Equal(t, "reconnecting", lost[0].ContextMap()["state"])
+	assert.Equal(t, "connected", lost[0].ContextMap()["previous_state"])
+	recovered := logs.FilterMessage("OPC UA session recovered").All()
+	require.Len(t, recovered, 1)
+	assert.Equal(t, "connected", recovered[0].ContextMap()["state"])
+	assert.Equal(t, "reconnecting", recovered[0].ContextMap()["previous_state"])
+}
+
+func TestReconnectManagerGivesUp(t *testing.T) {
+	session := &fakeSession{}
+	m, logs := newTestReconnectManager(ReconnectConfig{MaxRetries: 3}, session)
+	defer m.stop()
+
+	require.Error(t, m.recover(context.Background()))
+	require.Eventually(t, func() bool {
+		return m.currentState() == sessionFailed
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, 3, session.connectCount())
+
+	err := m.recover(context.Background())
+	require.ErrorIs(t, err, errReconnectGaveUp)
+	assert.Equal(t, 3, session.connectCount())
+	assert.Equal(t, 1, logs.FilterLevelExact(zapcore.ErrorLevel).Len())
+
+	// Stopping resets the manager, the next loss reconnects again
+	m.stop()
+	session.setOK(true)
+	require.NoError(t, m.recover(context.Background()))
+	assert.Equal(t, sessionConnected, m.currentState())
+}
+
+func TestReconnectManagerSingleAttempt(t *testing.T) {
+	session := &fakeSession{}
+	m, _ := newTestReconnectManager(ReconnectConfig{MaxRetries: 1}, session)
+	defer m.stop()
+
+	require.Error(t, m.recover(context.Background()))
+	assert.Equal(t, sessionFailed, m.currentState())
+	assert.Equal(t, 1, session.connectCount())
+}
+
+func TestReconnectManagerStop(t *testing.T) {
+	session := &fakeSession{}
+	m, _ := newTestReconnectManager(ReconnectConfig{}, session)
+	m.cfg.InitialInterval = time.Hour
+	m.cfg.MaxInterval = time.Hour
+
+	require.Error(t, m.recover(context.Background()))
+	assert.Equal(t, sessionReconnecting, m.currentState())
+
+	m.stop()
+	assert.Equal(t, sessionConnected, m.currentState())
+	assert.Equal(t, 1, session.connectCount())
+}
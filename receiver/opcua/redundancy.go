@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"go.uber.org/zap"
+)
+
+// endpoints returns the endpoint URLs of the logical server in the order
+// they are tried: endpoint, then the backup endpoints of redundancy
+func (cfg *Config) endpoints() []string {
+	return append([]string{cfg.Endpoint}, cfg.Redundancy.BackupEndpoints...)
+}
+
+// failbackMonitor returns the session to the primary endpoint once it can be
+// reached again while the receiver collects from a backup. The scrape
+// position belongs to the scraper, so collection continues with the same
+// window on the primary.
+type failbackMonitor struct {
+	interval time.Duration
+	logger   *zap.Logger
+
+	// onBackup reports whether the current session is with a backup endpoint
+	onBackup func() bool
+	// primaryReachable fails unless the primary endpoint answers
+	primaryReachable func(context.Context) error
+	// connect reconnects, trying the primary endpoint first
+	connect func(context.Context) error
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// newFailbackMonitor creates a failback monitor, which does nothing until it is started
+func newFailbackMonitor(interval time.Duration, logger *zap.Logger, onBackup func() bool, primaryReachable, connect func(context.Context) error) *failbackMonitor {
+	return &failbackMonitor{
+		interval:         interval,
+		logger:           logger,
+		onBackup:         onBackup,
+		primaryReachable: primaryReachable,
+		connect:          connect,
+	}
+}
+
+// start starts checking the primary endpoint unless it runs already
+func (m *failbackMonitor) start() {
+	if m.interval <= 0 {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(ctx, m.done)
+}
+
+// run checks the primary endpoint every interval while a backup is used
+// and reconnects once it answers, until ctx is cancelled
+func (m *failbackMonitor) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !m.onBackup() {
+			continue
+		}
+		if err := m.primaryReachable(ctx); err != nil {
+			m.logger.Debug("Primary OPC UA server still unreachable", zap.Error(err))
+			continue
+		}
+
+		m.logger.Info("Primary OPC UA server reachable again, failing back")
+		if err := m.connect(ctx); err != nil && ctx.Err() == nil {
+			m.logger.Warn("Failed to fail back to the primary OPC UA server", zap.Error(err))
+			continue
+		}
+		if !m.onBackup() {
+			m.logger.Info("Failed back to the primary OPC UA server")
+		}
+	}
+}
+
+// stop ends the checks. It must not be called while holding the client's
+// lock, which a failback takes.
+func (m *failbackMonitor) stop() {
+	m.mu.Lock()
+	cancel, done := m.cancel, m.done
+	m.cancel, m.done = nil, nil
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// onBackup reports whether the current session is with a backup endpoint
+func (c *opcuaClient) onBackup() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.client != nil && c.activeEndpoint != "" && c.activeEndpoint != c.config.Endpoint
+}
+
+// primaryReachable queries the endpoints of the primary server, which needs
+// no session, within connection_timeout
+func (c *opcuaClient) primaryReachable(ctx context.Context) error {
+	dialer, err := newDialer(c.config.Dialer)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.config.ConnectionTimeout)
+	defer cancel()
+	_, err = opcua.GetEndpoints(ctx, c.config.Endpoint, opcua.Dialer(dialer))
+	return err
+}
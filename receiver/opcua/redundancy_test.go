@@ -0,0 +1,176 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestRedundancyConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "no backups",
+			modify: func(*Config) {},
+		},
+		{
+			name: "backups",
+			modify: func(cfg *Config) {
+				cfg.Redundancy.BackupEndpoints = []string{"opc.tcp://backup-1:4840", "opc.tcp://backup-2:4840"}
+			},
+		},
+		{
+			name:    "backup without opc.tcp scheme",
+			modify:  func(cfg *Config) { cfg.Redundancy.BackupEndpoints = []string{"http://backup:4840"} },
+			wantErr: "backup_endpoints[0] must start with opc.tcp://",
+		},
+		{
+			name:    "backup equal to endpoint",
+			modify:  func(cfg *Config) { cfg.Redundancy.BackupEndpoints = []string{cfg.Endpoint} },
+			wantErr: "backup_endpoints[0] duplicates another endpoint",
+		},
+		{
+			name: "duplicate backups",
+			modify: func(cfg *Config) {
+				cfg.Redundancy.BackupEndpoints = []string{"opc.tcp://backup:4840", "opc.tcp://backup:4840"}
+			},
+			wantErr: "backup_endpoints[1] duplicates another endpoint",
+		},
+		{
+			name: "failback interval below one second",
+			modify: func(cfg *Config) {
+				cfg.Redundancy.BackupEndpoints = []string{"opc.tcp://backup:4840"}
+				cfg.Redundancy.FailbackInterval = 100 * time.Millisecond
+			},
+			wantErr: "failback_interval must be at least 1 second",
+		},
+		{
+			name: "reverse connect",
+			modify: func(cfg *Config) {
+				cfg.Redundancy.BackupEndpoints = []string{"opc.tcp://backup:4840"}
+				cfg.ReverseConnect.ListenAddress = "0.0.0.0:4843"
+			},
+			wantErr: "redundancy is not available with reverse_connect",
+		},
+		{
+			name: "pubsub mode",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Address = "tcp://broker:1883"
+				cfg.PubSub.Topic = "opcua/#"
+				cfg.Redundancy.BackupEndpoints = []string{"opc.tcp://backup:4840"}
+			},
+			wantErr: "redundancy is not available in pubsub mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Endpoint = "opc.tcp://primary:4840"
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestConfigEndpoints(t *testing.T) {
+	cfg := &Config{
+		Endpoint:   "opc.tcp://primary:4840",
+		Redundancy: RedundancyConfig{BackupEndpoints: []string{"opc.tcp://backup-1:4840", "opc.tcp://backup-2:4840"}},
+	}
+	assert.Equal(t, []string{"opc.tcp://primary:4840", "opc.tcp://backup-1:4840", "opc.tcp://backup-2:4840"}, cfg.endpoints())
+
+	cfg.Redundancy.BackupEndpoints = nil
+	assert.Equal(t, []string{"opc.tcp://primary:4840"}, cfg.endpoints())
+}
+
+// fakeRedundantPair is a server pair whose primary answers while primaryUp is set
+type fakeRedundantPair struct {
+	mu        sync.Mutex
+	onBackup  bool
+	primaryUp bool
+	connects  int
+}
+
+func (f *fakeRedundantPair) isOnBackup() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.onBackup
+}
+
+func (f *fakeRedundantPair) primaryReachable(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.primaryUp {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (f *fakeRedundantPair) connect(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connects++
+	f.onBackup = !f.primaryUp
+	return nil
+}
+
+func (f *fakeRedundantPair) setPrimaryUp() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.primaryUp = true
+}
+
+func (f *fakeRedundantPair) connectCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connects
+}
+
+func TestFailbackMonitorReturnsToPrimary(t *testing.T) {
+	f := &fakeRedundantPair{onBackup: true}
+	m := newFailbackMonitor(time.Millisecond, zap.NewNop(), f.isOnBackup, f.primaryReachable, f.connect)
+	m.start()
+	defer m.stop()
+
+	// The backup is kept while the primary is down
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, f.connectCount())
+
+	f.setPrimaryUp()
+	require.Eventually(t, func() bool { return !f.isOnBackup() }, 5*time.Second, time.Millisecond)
+	m.stop()
+
+	// Once back on the primary, the monitor does not reconnect again
+	assert.Equal(t, 1, f.connectCount())
+}
+
+func TestFailbackMonitorStartIsIdempotent(t *testing.T) {
+	f := &fakeRedundantPair{}
+	m := newFailbackMonitor(time.Hour, zap.NewNop(), f.isOnBackup, f.primaryReachable, f.connect)
+	m.start()
+	done := m.done
+	m.start()
+	assert.Equal(t, done, m.done)
+	m.stop()
+	assert.Nil(t, m.done)
+}
@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"github.com/gopcua/opcua"
+)
+
+const (
+	// resourceProfileDefault leaves the configuration as is
+	resourceProfileDefault = "default"
+	// resourceProfileMinimal bounds the memory of a receiver on a constrained
+	// edge gateway, see the README for the resulting ceilings
+	resourceProfileMinimal = "minimal"
+)
+
+// Limits of the minimal resource profile. A page of minimalMaxRecordsPerScrape
+// records of 1 KiB fits into minimalMaxMessageSize; a server sending larger
+// records answers BadResponseTooLarge and the client halves its page size.
+const (
+	// minimalMaxRecordsPerScrape caps max_records_per_call, which bounds the
+	// records held by a scrape and the record slices kept in the pools
+	minimalMaxRecordsPerScrape = 200
+	// minimalMaxMessageSize is the largest response message the client accepts
+	minimalMaxMessageSize = 256 * 1024
+	// minimalBufferSize is the size of a message chunk in either direction,
+	// close to the 8 KiB minimum of Part 6
+	minimalBufferSize = 16 * 1024
+	// minimalMaxChunkCount is the number of chunks of a response message
+	minimalMaxChunkCount = minimalMaxMessageSize / minimalBufferSize
+)
+
+// maxRecordsPerScrape returns the record budget of a scrape across all
+// LogObjects: max_records_per_call, capped by filter.max_log_records and the
+// resource profile
+func (cfg *Config) maxRecordsPerScrape() int {
+	budget := cfg.MaxRecordsPerCall
+	if cfg.Filter.MaxLogRecords > 0 {
+		budget = min(budget, cfg.Filter.MaxLogRecords)
+	}
+	if cfg.ResourceProfile == resourceProfileMinimal {
+		return min(budget, minimalMaxRecordsPerScrape)
+	}
+	return budget
+}
+
+// concurrentReads returns max_concurrent_reads; the minimal profile reads one
+// LogObject at a time, so a scrape holds the pages of a single read
+func (cfg *Config) concurrentReads() int {
+	if cfg.ResourceProfile == resourceProfileMinimal {
+		return 1
+	}
+	return max(cfg.MaxConcurrentReads, 1)
+}
+
+// transportOptions returns the message and buffer sizes the client negotiates
+// in the UACP handshake. The default profile leaves them to the server.
+func (cfg *Config) transportOptions() []opcua.Option {
+	if cfg.ResourceProfile != resourceProfileMinimal {
+		return nil
+	}
+	return []opcua.Option{
+		opcua.MaxMessageSize(minimalMaxMessageSize),
+		opcua.MaxChunkCount(minimalMaxChunkCount),
+		opcua.ReceiveBufferSize(minimalBufferSize),
+		opcua.SendBufferSize(minimalBufferSize),
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// Memory ceilings of the minimal resource profile documented in the README,
+// for records with a 1 KiB message and every optional field populated
+const (
+	// minimalAllocatedPerScrape is the memory a scrape allocates, from the
+	// decoded records to the transformed logs
+	minimalAllocatedPerScrape = 2 << 20
+	// minimalRetainedGrowth is the growth of the live heap over many scrapes
+	minimalRetainedGrowth = 1 << 20
+)
+
+func TestResourceProfileValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{name: "default", modify: func(*Config) {}},
+		{name: "empty", modify: func(cfg *Config) { cfg.ResourceProfile = "" }},
+		{name: "minimal", modify: func(cfg *Config) { cfg.ResourceProfile = "minimal" }},
+		{
+			name:    "unknown",
+			modify:  func(cfg *Config) { cfg.ResourceProfile = "tiny" },
+			wantErr: "invalid resource_profile: tiny",
+		},
+		{
+			name: "minimal with debug dump",
+			modify: func(cfg *Config) {
+				cfg.ResourceProfile = "minimal"
+				cfg.DebugDump.Enabled = true
+			},
+			wantErr: "debug_dump is not available with resource_profile minimal",
+		},
+		{
+			name: "minimal with capture",
+			modify: func(cfg *Config) {
+				cfg.ResourceProfile = "minimal"
+				cfg.Capture.Directory = t.TempDir()
+			},
+			wantErr: "capture is not available with resource_profile minimal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestResourceProfileLimits(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Equal(t, 1000, cfg.maxRecordsPerScrape())
+	assert.Empty(t, cfg.transportOptions())
+
+	cfg.ResourceProfile = resourceProfileMinimal
+	assert.Equal(t, minimalMaxRecordsPerScrape, cfg.maxRecordsPerScrape())
+	assert.Len(t, cfg.transportOptions(), 4)
+
+	// Smaller configured values are kept
+	cfg.MaxRecordsPerCall = 50
+	assert.Equal(t, 50, cfg.maxRecordsPerScrape())
+	cfg.Filter.MaxLogRecords = 20
+	assert.Equal(t, 20, cfg.maxRecordsPerScrape())
+}
+
+func TestScraperWireMinimalProfile(t *testing.T) {
+	ws := startWireServer(t)
+	records := wireRecords(300)
+	for i := range records {
+		records[i].Message = strings.Repeat("m", 512)
+	}
+	ws.AddLogRecords(records)
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.ResourceProfile = resourceProfileMinimal
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// The session negotiated with the small buffers carries a full page, and
+	// the scrape stops at the record cap of the profile
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, minimalMaxRecordsPerScrape, logs.LogRecordCount())
+}
+
+// pagedClient decodes at most maxRecords of a fixed set of encoded records
+// per GetRecords call, like a server holding more records than a scrape reads
+type pagedClient struct {
+	decoder *opcuaClient
+	objects []*ua.ExtensionObject
+}
+
+func (c *pagedClient) Connect(context.Context) error { return nil }
+
+func (c *pagedClient) Disconnect(context.Context) error { return nil }
+
+func (c *pagedClient) IsConnected() bool { return true }
+
+func (c *pagedClient) GetRecords(_ context.Context, _, _ time.Time, maxRecords int) ([]model.LogRecord, error) {
+	return c.decoder.parseExtensionObjectArray(c.objects[:min(maxRecords, len(c.objects))])
+}
+
+// kibRecordObjects returns n encoded LogRecords with a 1 KiB message and
+// every optional field populated
+func kibRecordObjects(t *testing.T, n int) []*ua.ExtensionObject {
+	t.Helper()
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	message := strings.Repeat("m", 1000)
+	objects := make([]*ua.ExtensionObject, n)
+	for i := range objects {
+		lr := &client.LogRecordExtObj{
+			Time:         base.Add(time.Duration(i) * time.Millisecond),
+			Severity:     uint16(1 + i%1000), //nolint:gosec
+			Message:      fmt.Sprintf("%s %d", message, i),
+			SourceNode:   ua.NewNumericNodeID(1, uint32(100+i%5)), //nolint:gosec
+			SourceName:   "Pump",
+			TraceIDBytes: fixedTraceIDBytes(),
+			SpanID:       uint64(i + 1), //nolint:gosec
+			AdditionalData: map[string]interface{}{
+				"component": "pump",
+				"index":     int32(i), //nolint:gosec
+			},
+		}
+		encoded, err := lr.Encode()
+		require.NoError(t, err)
+		objects[i] = &ua.ExtensionObject{
+			TypeID: &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+			Value:  encoded,
+		}
+	}
+	return objects
+}
+
+func TestResourceProfileMemoryCeiling(t *testing.T) {
+	if raceEnabled {
+		t.Skip("memory statistics are unreliable with the race detector")
+	}
+	objects := kibRecordObjects(t, 2000)
+
+	// measure returns the bytes allocated per scrape and the growth of the
+	// live heap over the scrapes of a profile
+	measure := func(profile string) (allocated uint64, retained int64) {
+		cfg := createDefaultConfig().(*Config)
+		cfg.ResourceProfile = profile
+		scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+		require.NoError(t, err)
+		scr.client = &pagedClient{decoder: newOPCUAClient(cfg, zap.NewNop()), objects: objects}
+		ctx := context.Background()
+
+		// The first scrape fills the pools
+		_, err = scr.scrape(ctx)
+		require.NoError(t, err)
+
+		const scrapes = 20
+		var before, after runtime.MemStats
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		for range scrapes {
+			_, err := scr.scrape(ctx)
+			require.NoError(t, err)
+		}
+		// Two collections also free the pools' victim caches
+		runtime.GC()
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+		return (after.TotalAlloc - before.TotalAlloc) / scrapes, int64(after.HeapAlloc) - int64(before.HeapAlloc) //nolint:gosec
+	}
+
+	minimalAllocated, minimalRetained := measure(resourceProfileMinimal)
+	defaultAllocated, _ := measure(resourceProfileDefault)
+	t.Logf("allocated per scrape: minimal %d B, default %d B; minimal retained %d B",
+		minimalAllocated, defaultAllocated, minimalRetained)
+
+	assert.LessOrEqual(t, minimalAllocated, uint64(minimalAllocatedPerScrape), "bytes allocated per scrape")
+	assert.LessOrEqual(t, minimalRetained, int64(minimalRetainedGrowth), "live heap growth")
+	assert.Less(t, 2*minimalAllocated, defaultAllocated, "minimal profile allocates less than the default profile")
+}
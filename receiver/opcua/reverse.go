@@ -0,0 +1,285 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/uacp"
+	"go.uber.org/zap"
+)
+
+const (
+	// uacpHeaderSize is the size of the OPC UA Connection Protocol message
+	// header: message type, chunk type and message size
+	uacpHeaderSize = 8
+
+	// maxHandshakeSize bounds the ReverseHello and Hello messages read by
+	// the reverse connector
+	maxHandshakeSize = 8192
+
+	// reverseConnQueue is the number of announced server connections kept
+	// until the client dials
+	reverseConnQueue = 4
+)
+
+// reverseConnector implements reverse connect (OPC UA Part 6, 7.1.3) for
+// servers that cannot be connected to: the server opens the TCP connection
+// to the receiver and announces itself with a ReverseHello. gopcua can only
+// dial, so the client dials a loopback listener instead of the server and
+// each dial is bridged to the next announced connection.
+type reverseConnector struct {
+	endpoint  string
+	serverURI string
+	timeout   time.Duration
+	logger    *zap.Logger
+
+	// listener accepts the connections of the server
+	listener net.Listener
+	// local is the loopback listener the client dials
+	local net.Listener
+	// conns are the announced connections waiting for a dial
+	conns chan *reverseConn
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// reverseConn is a server connection announced with a ReverseHello
+type reverseConn struct {
+	net.Conn
+	endpointURL string
+}
+
+// newReverseConnector listens for the connections of the server on the
+// configured address and for the client on a loopback port
+func newReverseConnector(cfg *Config, logger *zap.Logger) (*reverseConnector, error) {
+	listener, err := net.Listen("tcp", cfg.ReverseConnect.ListenAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for reverse connections: %w", err)
+	}
+	local, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to listen for the client: %w", err)
+	}
+
+	r := &reverseConnector{
+		endpoint:  cfg.Endpoint,
+		serverURI: cfg.ReverseConnect.ServerURI,
+		timeout:   cfg.ConnectionTimeout,
+		logger:    logger,
+		listener:  listener,
+		local:     local,
+		conns:     make(chan *reverseConn, reverseConnQueue),
+		done:      make(chan struct{}),
+	}
+	go r.acceptServers()
+	go r.acceptClients()
+
+	logger.Info("Listening for reverse connections",
+		zap.String("address", listener.Addr().String()))
+	return r, nil
+}
+
+// localEndpoint returns the endpoint URL the client dials
+func (r *reverseConnector) localEndpoint() string {
+	return "opc.tcp://" + r.local.Addr().String()
+}
+
+// close stops listening and drops the connections waiting for a dial.
+// Bridged connections end with the session that uses them.
+func (r *reverseConnector) close() {
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.listener.Close()
+		r.local.Close()
+		for {
+			select {
+			case conn := <-r.conns:
+				conn.Close()
+			default:
+				return
+			}
+		}
+	})
+}
+
+// acceptServers accepts the connections of servers and queues those whose
+// ReverseHello announces the configured server
+func (r *reverseConnector) acceptServers() {
+	for {
+		conn, err := r.listener.Accept()
+		if err != nil {
+			return
+		}
+		go r.announce(conn)
+	}
+}
+
+// announce reads the ReverseHello of a server connection and queues it
+func (r *reverseConnector) announce(conn net.Conn) {
+	rhe, err := r.readReverseHello(conn)
+	if err != nil {
+		r.logger.Warn("Rejected reverse connection",
+			zap.String("remote_address", conn.RemoteAddr().String()),
+			zap.Error(err))
+		conn.Close()
+		return
+	}
+	r.logger.Debug("Server announced reverse connection",
+		zap.String("server_uri", rhe.ServerURI),
+		zap.String("endpoint_url", rhe.EndpointURL),
+		zap.String("remote_address", conn.RemoteAddr().String()))
+
+	select {
+	case r.conns <- &reverseConn{Conn: conn, endpointURL: rhe.EndpointURL}:
+	case <-r.done:
+		conn.Close()
+	default:
+		// The server opens new connections while the client needs them
+		r.logger.Debug("Reverse connection queue is full, dropping connection")
+		conn.Close()
+	}
+}
+
+// readReverseHello reads the ReverseHello a server starts its connection
+// with and checks that it announces the configured server: by ServerUri if
+// server_uri is set, by EndpointUrl otherwise
+func (r *reverseConnector) readReverseHello(conn net.Conn) (*uacp.ReverseHello, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return nil, err
+	}
+	msgType, body, err := readUACPMessage(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ReverseHello: %w", err)
+	}
+	if msgType != uacp.MessageTypeReverseHello {
+		return nil, fmt.Errorf("expected ReverseHello, got %s", msgType)
+	}
+	rhe := new(uacp.ReverseHello)
+	if _, err := rhe.Decode(body); err != nil {
+		return nil, fmt.Errorf("invalid ReverseHello: %w", err)
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	if r.serverURI != "" {
+		if rhe.ServerURI != r.serverURI {
+			return nil, fmt.Errorf("unexpected ServerUri %s", rhe.ServerURI)
+		}
+	} else if rhe.EndpointURL != r.endpoint {
+		return nil, fmt.Errorf("unexpected EndpointUrl %s", rhe.EndpointURL)
+	}
+	return rhe, nil
+}
+
+// acceptClients bridges each dial of the client to an announced connection
+func (r *reverseConnector) acceptClients() {
+	for {
+		conn, err := r.local.Accept()
+		if err != nil {
+			return
+		}
+		go r.bridge(conn)
+	}
+}
+
+// bridge waits for an announced connection and relays the client's dial to
+// it. The Hello of the client carries the loopback URL it dialed, so it is
+// sent with the EndpointUrl of the ReverseHello instead, as the protocol
+// requires.
+func (r *reverseConnector) bridge(client net.Conn) {
+	defer client.Close()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	var server *reverseConn
+	select {
+	case server = <-r.conns:
+	case <-timer.C:
+		r.logger.Warn("No reverse connection from the server",
+			zap.String("endpoint", r.endpoint),
+			zap.Duration("waited", r.timeout))
+		return
+	case <-r.done:
+		return
+	}
+	defer server.Close()
+
+	if err := relayHello(client, server); err != nil {
+		r.logger.Warn("Failed to relay Hello over reverse connection", zap.Error(err))
+		return
+	}
+
+	go func() {
+		_, _ = io.Copy(server, client)
+		server.Close()
+	}()
+	_, _ = io.Copy(client, server)
+}
+
+// relayHello reads the Hello of the client and sends it to the server with
+// the EndpointUrl the server announced
+func relayHello(client net.Conn, server *reverseConn) error {
+	msgType, body, err := readUACPMessage(client)
+	if err != nil {
+		return err
+	}
+	if msgType != uacp.MessageTypeHello {
+		return fmt.Errorf("expected Hello, got %s", msgType)
+	}
+	hello := new(uacp.Hello)
+	if _, err := hello.Decode(body); err != nil {
+		return fmt.Errorf("invalid Hello: %w", err)
+	}
+	hello.EndpointURL = server.endpointURL
+	return writeUACPMessage(server, uacp.MessageTypeHello, hello)
+}
+
+// readUACPMessage reads a final chunk of the OPC UA Connection Protocol and
+// returns its message type and body
+func readUACPMessage(conn net.Conn) (string, []byte, error) {
+	header := make([]byte, uacpHeaderSize)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return "", nil, err
+	}
+	size := binary.LittleEndian.Uint32(header[4:])
+	if size < uacpHeaderSize || size > maxHandshakeSize {
+		return "", nil, fmt.Errorf("invalid message size %d", size)
+	}
+	if header[3] != uacp.ChunkTypeFinal {
+		return "", nil, errors.New("handshake message is not a final chunk")
+	}
+	body := make([]byte, size-uacpHeaderSize)
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return "", nil, err
+	}
+	return string(header[:3]), body, nil
+}
+
+// writeUACPMessage writes msg as a final chunk of the given message type
+func writeUACPMessage(conn net.Conn, msgType string, msg interface{ Encode() ([]byte, error) }) error {
+	body, err := msg.Encode()
+	if err != nil {
+		return err
+	}
+	header, err := (&uacp.Header{
+		MessageType: msgType,
+		ChunkType:   uacp.ChunkTypeFinal,
+		MessageSize: uint32(uacpHeaderSize + len(body)), //nolint:gosec
+	}).Encode()
+	if err != nil {
+		return err
+	}
+	_, err = conn.Write(append(header, body...))
+	return err
+}
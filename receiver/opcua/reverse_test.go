@@ -0,0 +1,178 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/uacp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReverseConnectConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "disabled",
+			modify: func(*Config) {},
+		},
+		{
+			name:   "all interfaces",
+			modify: func(cfg *Config) { cfg.ReverseConnect.ListenAddress = ":4843" },
+		},
+		{
+			name: "server URI",
+			modify: func(cfg *Config) {
+				cfg.ReverseConnect.ListenAddress = "0.0.0.0:4843"
+				cfg.ReverseConnect.ServerURI = "urn:plc1:opcua"
+			},
+		},
+		{
+			name:    "missing port",
+			modify:  func(cfg *Config) { cfg.ReverseConnect.ListenAddress = "0.0.0.0" },
+			wantErr: "listen_address must be host:port",
+		},
+		{
+			name: "pubsub mode",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModePubSub
+				cfg.PubSub.Address = "tcp://broker:1883"
+				cfg.PubSub.Topic = "plant/line1"
+				cfg.ReverseConnect.ListenAddress = ":4843"
+			},
+			wantErr: "reverse_connect is not available in pubsub mode",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Endpoint = "opc.tcp://plc1:4840"
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+// reverseServer plays the server side of reverse connect in front of a wire
+// server: it keeps one announced connection open to the receiver and relays
+// it to the wire server once the receiver starts using it
+func reverseServer(ctx context.Context, t *testing.T, listenAddress, endpointURL string) {
+	serverAddress := strings.TrimPrefix(endpointURL, "opc.tcp://")
+	for ctx.Err() == nil {
+		conn, err := net.Dial("tcp", listenAddress)
+		if err != nil {
+			// The receiver listens from its first connect on
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		err = writeUACPMessage(conn, uacp.MessageTypeReverseHello, &uacp.ReverseHello{
+			ServerURI:   "urn:wire-server",
+			EndpointURL: endpointURL,
+		})
+		first := make([]byte, 1)
+		if err == nil {
+			_, err = conn.Read(first)
+		}
+		if err != nil {
+			conn.Close()
+			continue
+		}
+		server, err := net.Dial("tcp", serverAddress)
+		if !assert.NoError(t, err) {
+			conn.Close()
+			return
+		}
+		_, _ = server.Write(first)
+		go func() {
+			defer conn.Close()
+			defer server.Close()
+			go func() { _, _ = io.Copy(server, conn) }()
+			_, _ = io.Copy(conn, server)
+		}()
+	}
+}
+
+func TestClientWireReverseConnect(t *testing.T) {
+	ws := startWireServer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cfg := ws.newWireConfig()
+	cfg.ReverseConnect.ListenAddress = fmt.Sprintf("127.0.0.1:%d", freePort(t))
+	go reverseServer(ctx, t, cfg.ReverseConnect.ListenAddress, ws.endpoint)
+
+	// Discovery and the session both run over connections of the server
+	c := newOPCUAClient(cfg, zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(context.Background()))
+	}()
+
+	assert.True(t, c.IsConnected())
+	require.Len(t, c.logObjectIDs, 1)
+	assert.True(t, ws.logObjectID.Equal(c.logObjectIDs[0]))
+
+	methodID, err := c.findGetRecordsMethod(ctx, c.logObjectIDs[0])
+	require.NoError(t, err)
+	assert.True(t, ws.methodID.Equal(methodID))
+}
+
+func TestReverseConnectorRejectsUnknownServer(t *testing.T) {
+	tests := []struct {
+		name      string
+		serverURI string
+		hello     uacp.ReverseHello
+	}{
+		{
+			name:  "endpoint URL",
+			hello: uacp.ReverseHello{ServerURI: "urn:plc1", EndpointURL: "opc.tcp://plc2:4840"},
+		},
+		{
+			name:      "server URI",
+			serverURI: "urn:plc1",
+			hello:     uacp.ReverseHello{ServerURI: "urn:plc2", EndpointURL: "opc.tcp://plc1:4840"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Endpoint = "opc.tcp://plc1:4840"
+			cfg.ReverseConnect.ListenAddress = "127.0.0.1:0"
+			cfg.ReverseConnect.ServerURI = tt.serverURI
+			r, err := newReverseConnector(cfg, zap.NewNop())
+			require.NoError(t, err)
+			defer r.close()
+
+			conn, err := net.Dial("tcp", r.listener.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+			require.NoError(t, writeUACPMessage(conn, uacp.MessageTypeReverseHello, &tt.hello))
+
+			// The receiver closes the connection instead of queuing it
+			require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			_, err = conn.Read(make([]byte, 1))
+			assert.ErrorIs(t, err, io.EOF)
+			assert.Empty(t, r.conns)
+		})
+	}
+}
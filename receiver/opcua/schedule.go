@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import "time"
+
+// gridTicker ticks the scraperhelper controller at multiples of interval
+// after start. Unlike time.Ticker it keeps no tick for a controller that is
+// still scraping: a tick passing while the previous scrape runs is skipped
+// and reported to onOverrun, so the next collection starts on the grid
+// instead of right after the slow scrape.
+type gridTicker struct {
+	c        chan time.Time
+	stopping chan struct{}
+	done     chan struct{}
+}
+
+// newGridTicker starts a gridTicker whose first tick is one interval after
+// start
+func newGridTicker(start time.Time, interval time.Duration, onOverrun func(skipped int)) *gridTicker {
+	t := &gridTicker{
+		c:        make(chan time.Time),
+		stopping: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go t.run(start.Add(interval), interval, onOverrun)
+	return t
+}
+
+func (t *gridTicker) run(tick time.Time, interval time.Duration, onOverrun func(skipped int)) {
+	defer close(t.done)
+	for {
+		timer := time.NewTimer(time.Until(tick))
+		select {
+		case <-t.stopping:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// The channel is unbuffered, so the tick only reaches a controller
+		// waiting for it
+		skipped := 0
+		select {
+		case t.c <- tick:
+		default:
+			skipped = 1
+		}
+		var missed int
+		tick, missed = nextTick(tick, time.Now(), interval)
+		if skipped += missed; skipped > 0 {
+			onOverrun(skipped)
+		}
+	}
+}
+
+// stop stops the ticks and waits for the ticker to return
+func (t *gridTicker) stop() {
+	close(t.stopping)
+	<-t.done
+}
+
+// nextTick returns the tick following tick on the grid of interval that is
+// still ahead of now, and the number of ticks that passed before it
+func nextTick(tick, now time.Time, interval time.Duration) (next time.Time, missed int) {
+	intervals := max(now.Sub(tick)/interval, 0)
+	return tick.Add((intervals + 1) * interval), int(intervals)
+}
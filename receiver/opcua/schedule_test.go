@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextTick(t *testing.T) {
+	tick := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	interval := 30 * time.Second
+
+	tests := []struct {
+		name       string
+		elapsed    time.Duration
+		wantNext   time.Duration
+		wantMissed int
+	}{
+		{name: "on time", elapsed: 0, wantNext: 30 * time.Second},
+		{name: "late", elapsed: 2 * time.Second, wantNext: 30 * time.Second},
+		{name: "at the next tick", elapsed: 30 * time.Second, wantNext: time.Minute, wantMissed: 1},
+		{name: "several ticks passed", elapsed: 95 * time.Second, wantNext: 2 * time.Minute, wantMissed: 3},
+		{name: "clock stepped back", elapsed: -5 * time.Second, wantNext: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, missed := nextTick(tick, tick.Add(tt.elapsed), interval)
+			assert.Equal(t, tick.Add(tt.wantNext), next)
+			assert.Equal(t, tt.wantMissed, missed)
+		})
+	}
+}
+
+func TestGridTicker(t *testing.T) {
+	interval := 50 * time.Millisecond
+	var skipped atomic.Int64
+	start := time.Now()
+	ticker := newGridTicker(start, interval, func(n int) { skipped.Add(int64(n)) })
+	defer ticker.stop()
+
+	assert.Equal(t, start.Add(interval), <-ticker.c)
+
+	// The ticks passing during a slow scrape are skipped, not delivered
+	// late, and the next one is on the grid
+	time.Sleep(120 * time.Millisecond)
+	assert.Equal(t, start.Add(4*interval), <-ticker.c)
+	assert.Equal(t, int64(2), skipped.Load())
+}
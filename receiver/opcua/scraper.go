@@ -5,48 +5,151 @@ package opcua
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
-	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
 )
 
 // scraper handles log collection from OPC UA servers
 type scraper struct {
 	config          *Config
 	settings        component.TelemetrySettings
-	transformer     *Transformer
+	transformer     *recordTransformer
 	client          OPCUAClient
+	clientFactory   ClientFactory
+	buildInfo       component.BuildInfo
+	telemetry       *metadata.TelemetryBuilder
+	clock           clock
 	lastCollectTime time.Time
+
+	// resumeEnd is the end of the window the record budget of the last
+	// scrape ran out in; the next scrape queries it again to read the rest
+	resumeEnd time.Time
+
+	// positions holds how far each LogObject read the window up to
+	// resumeEnd, for clients implementing positionReader. The window
+	// continues from them once its continuation points are gone. LogObjects
+	// skipped because their read failed keep theirs across windows.
+	positions map[string]readPosition
+
+	// catchingUp is set while the windows are bounded by
+	// max_catchup_duration and the window end lags behind the current time
+	catchingUp bool
+
+	// skew is the clock skew of the server in nanoseconds last measured with
+	// correct_clock_skew, observed by the skew gauge once skewMeasured
+	skew         atomic.Int64
+	skewMeasured atomic.Bool
+
+	// errorLog suppresses the collection error repeated on every scrape
+	errorLog warningLimiter
+
+	// health tracks the scrape error rate to report the receiver degraded
+	health healthTracker
+
+	// host receives the component status events of health changes
+	host component.Host
+
+	// variablesStart is the start time of the sums read from variables
+	variablesStart time.Time
+
+	// statusWritten is the collection time last written to the status node
+	statusWritten time.Time
+
+	// onBatch receives the batches of flush_size records of a scrape while
+	// the client pages through the window, if set; see stream.go. flushed
+	// counts the records of the running scrape handed over that way.
+	onBatch func(ctx context.Context, records []model.LogRecord)
+	flushed flushedRecords
+
+	// LogObject reads of the running scrape, reported by the client
+	readsMu sync.Mutex
+	reads   []logObjectRead
+
+	// last successful scrape and LogObject reads, observed by the staleness gauges
+	successMu        sync.Mutex
+	lastSuccess      time.Time
+	logObjectSuccess map[string]time.Time
+
+	// gds manages the application certificate if gds is set, see gds.go
+	gds *gdsProvider
 }
 
-// OPCUAClient defines the interface for OPC UA client operations
-// This interface allows for easier testing with mock implementations
-type OPCUAClient interface {
-	Connect(ctx context.Context) error
-	Disconnect(ctx context.Context) error
-	IsConnected() bool
-	GetRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int) ([]testdata.OPCUALogRecord, error)
+// clock provides the current time for the collection window and scrape
+// duration, so tests can control time
+type clock interface {
+	Now() time.Time
+}
+
+// systemClock is the wall clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
 }
 
 // newScraper creates a new scraper
-func newScraper(config *Config, settings component.TelemetrySettings) *scraper {
-	return &scraper{
+func newScraper(config *Config, settings component.TelemetrySettings) (*scraper, error) {
+	telemetry, err := metadata.NewTelemetryBuilder(settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create telemetry builder: %w", err)
+	}
+
+	s := &scraper{
 		config:          config,
 		settings:        settings,
-		transformer:     NewTransformer(config.Endpoint, config.Resource.ServiceName, config.Resource.ServiceNamespace),
-		lastCollectTime: time.Time{}, // Zero time: first scrape fetches all available records
+		transformer:     newTransformerFromConfig(config),
+		telemetry:       telemetry,
+		clock:           systemClock{},
+		lastCollectTime: config.startTime(time.Now()), // Zero time: first scrape fetches all available records
+	}
+	s.errorLog.interval = config.LogSuppressionInterval
+	s.health.window = config.Health.ErrorRateWindow
+	s.health.threshold = config.Health.ErrorRateThreshold
+	s.health.minScrapes = config.Health.MinScrapes
+	if err := s.registerStalenessCallbacks(); err != nil {
+		return nil, fmt.Errorf("failed to register staleness callbacks: %w", err)
+	}
+	if err := s.registerHealthCallbacks(); err != nil {
+		return nil, fmt.Errorf("failed to register health callbacks: %w", err)
 	}
+	if err := s.registerClockSkewCallback(); err != nil {
+		return nil, fmt.Errorf("failed to register clock skew callback: %w", err)
+	}
+	return s, nil
 }
 
 // start initializes the scraper
 func (s *scraper) start(ctx context.Context, host component.Host) error {
-	// Create OPC UA client
-	s.client = newOPCUAClient(s.config, s.settings.Logger)
+	s.host = host
+	switch {
+	case s.config.Client != nil:
+		s.client = s.config.Client
+	case s.clientFactory != nil:
+		client, err := s.clientFactory(host, s.config, s.settings)
+		if err != nil {
+			return fmt.Errorf("failed to create OPC UA client: %w", err)
+		}
+		s.client = client
+	default:
+		if err := s.createClient(ctx, host); err != nil {
+			return err
+		}
+	}
 
 	// Connect to OPC UA server
 	if err := s.client.Connect(ctx); err != nil {
@@ -58,12 +161,56 @@ func (s *scraper) start(ctx context.Context, host component.Host) error {
 
 	s.settings.Logger.Info("Successfully connected to OPC UA server",
 		zap.String("endpoint", s.config.Endpoint))
+	s.loadSeverityDictionary(ctx)
+	if provider, ok := s.client.(serverInfoProvider); ok {
+		s.transformer.serverInfo = provider.serverInfo
+	}
 
 	return nil
 }
 
+// createClient creates the built-in OPC UA client
+func (s *scraper) createClient(ctx context.Context, host component.Host) error {
+	client := newOPCUAClient(s.config, s.settings.Logger)
+	client.identity = newClientIdentity(s.config.ClientIdentity, s.buildInfo.Version)
+	if err := ensureGeneratedCertificate(&s.config.TLS, client.identity.applicationName, time.Now(), s.settings.Logger); err != nil {
+		return fmt.Errorf("failed to generate application certificate: %w", err)
+	}
+	switch {
+	case s.config.TLS.CertificateProvider != nil:
+		provider, err := getCertificateProvider(host, *s.config.TLS.CertificateProvider)
+		if err != nil {
+			return err
+		}
+		client.certProvider = provider
+	case s.config.GDS.Endpoint != "":
+		provider := newGDSProvider(s.config, client.identity, s.settings.Logger)
+		// A renewed certificate or trust list takes effect with a new session
+		provider.renewed = func(ctx context.Context) {
+			if err := client.Connect(ctx); err != nil {
+				s.settings.Logger.Warn("Failed to reconnect with renewed credentials", zap.Error(err))
+			}
+		}
+		if err := provider.Start(ctx, host); err != nil {
+			return fmt.Errorf("failed to start gds: %w", err)
+		}
+		client.certProvider = provider
+		s.gds = provider
+	}
+	client.telemetry = s.telemetry
+	client.onLogObjectRead = s.logObjectRead
+	s.client = client
+	return nil
+}
+
 // shutdown stops the scraper
 func (s *scraper) shutdown(ctx context.Context) error {
+	if s.telemetry != nil {
+		s.telemetry.Shutdown()
+	}
+	if s.gds != nil {
+		_ = s.gds.Shutdown(ctx)
+	}
 	if s.client != nil {
 		if err := s.client.Disconnect(ctx); err != nil {
 			s.settings.Logger.Error("Failed to disconnect from OPC UA server", zap.Error(err))
@@ -73,45 +220,508 @@ func (s *scraper) shutdown(ctx context.Context) error {
 	return nil
 }
 
-// scrape collects logs from the OPC UA server
+// scrape collects logs from the OPC UA server and transforms them to plog.Logs
 func (s *scraper) scrape(ctx context.Context) (plog.Logs, error) {
-	// Check if client is connected
-	if s.client == nil || !s.client.IsConnected() {
-		// Try to reconnect
-		if s.client != nil {
-			s.settings.Logger.Info("Attempting to reconnect to OPC UA server")
-			if err := s.client.Connect(ctx); err != nil {
-				return plog.NewLogs(), fmt.Errorf("failed to reconnect: %w", err)
+	records, err := s.scrapeRecords(ctx)
+	if err != nil && !scrapererror.IsPartialScrapeError(err) {
+		return plog.NewLogs(), err
+	}
+
+	// Transform OPC UA records to OpenTelemetry logs
+	logs := s.transformer.TransformLogs(records)
+	releaseRecords(records)
+	return logs, err
+}
+
+// scrapeRecords collects log records from the OPC UA server, records the
+// scrape telemetry and logs a summary of the scrape. If LogObjects were
+// skipped because their GetRecords call failed, the records of the others
+// are returned with a PartialScrapeError.
+func (s *scraper) scrapeRecords(ctx context.Context) ([]model.LogRecord, error) {
+	windowStart := s.lastCollectTime
+	// A resumed window continues after the records already read, which
+	// would look like a gap
+	resumed := !s.resumeEnd.IsZero()
+	s.flushed = flushedRecords{}
+	start := s.now()
+	records, err := s.collect(ctx)
+	duration := s.now().Sub(start)
+	endpoint := attribute.String("opcua.endpoint", s.config.Endpoint)
+	s.telemetry.OpcuaScrapeDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(endpoint))
+
+	reads := s.takeReads()
+	var failures []logObjectRead
+	for _, read := range reads {
+		if read.err == nil {
+			continue
+		}
+		failures = append(failures, read)
+		s.telemetry.OpcuaLogObjectErrors.Add(ctx, 1, metric.WithAttributes(
+			append(failureAttributes(read.err), attribute.String("opcua.log_object", read.logObjectID))...))
+	}
+	s.logSummary(windowStart, duration, s.flushed.count+len(records), reads, err)
+	s.recordHealth(err)
+
+	if err != nil {
+		s.telemetry.OpcuaScrapeErrors.Add(ctx, 1, metric.WithAttributes(append(failureAttributes(err), endpoint)...))
+		return nil, err
+	}
+
+	s.recordScraped(ctx, records)
+	now := s.now()
+	s.recordSuccess(now, reads)
+	s.writeStatus(ctx, now)
+	if s.config.Diagnostics.GapRecords && !resumed {
+		records = append(records, s.gapRecords(ctx, windowStart, addEarliest(s.flushed.earliest, records))...)
+	}
+	if s.config.Diagnostics.FailureRecords {
+		records = append(records, s.failureRecords(failures)...)
+	}
+	return records, partialScrapeError(failures)
+}
+
+// partialScrapeError reports the LogObjects skipped by a scrape, nil if there
+// are none. The records a skipped LogObject would have returned are unknown,
+// so each of them counts as one failed record.
+func partialScrapeError(failures []logObjectRead) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	errs := make([]error, 0, len(failures))
+	for _, read := range failures {
+		errs = append(errs, fmt.Errorf("log object %s: %w", read.logObjectID, read.err))
+	}
+	return scrapererror.NewPartialScrapeError(errors.Join(errs...), len(failures))
+}
+
+// recordScraped adds the records of a scrape per LogObject to the
+// otelcol_opcua_records_scraped counter. A scrape without records adds zero,
+// so the series exists before the first record arrives.
+func (s *scraper) recordScraped(ctx context.Context, records []model.LogRecord) {
+	endpoint := attribute.String("opcua.endpoint", s.config.Endpoint)
+	if len(records) == 0 {
+		s.telemetry.OpcuaRecordsScraped.Add(ctx, 0, metric.WithAttributes(endpoint))
+		return
+	}
+
+	counts := make(map[string]int64)
+	for _, record := range records {
+		counts[record.LogObjectID]++
+	}
+	for logObjectID, count := range counts {
+		attrs := []attribute.KeyValue{endpoint}
+		// Clients other than the built-in one may not report the LogObject
+		if logObjectID != "" {
+			attrs = append(attrs, attribute.String("opcua.log_object", logObjectID))
+		}
+		s.telemetry.OpcuaRecordsScraped.Add(ctx, count, metric.WithAttributes(attrs...))
+	}
+}
+
+// recordSuccess stores the time of a successful scrape and of its successful
+// LogObject reads for the staleness gauges
+func (s *scraper) recordSuccess(now time.Time, reads []logObjectRead) {
+	s.successMu.Lock()
+	defer s.successMu.Unlock()
+	s.lastSuccess = now
+	for _, read := range reads {
+		if read.err != nil {
+			continue
+		}
+		if s.logObjectSuccess == nil {
+			s.logObjectSuccess = make(map[string]time.Time)
+		}
+		s.logObjectSuccess[read.logObjectID] = now
+	}
+}
+
+// registerStalenessCallbacks reports the last successful scrape and
+// LogObject reads as Unix timestamps, so that alerts can fire on logs that
+// stopped arriving while the receiver keeps retrying
+func (s *scraper) registerStalenessCallbacks() error {
+	endpoint := attribute.String("opcua.endpoint", s.config.Endpoint)
+	return errors.Join(
+		s.telemetry.RegisterOpcuaLastSuccessfulScrapeTimestampCallback(func(_ context.Context, o metric.Float64Observer) error {
+			s.successMu.Lock()
+			defer s.successMu.Unlock()
+			if !s.lastSuccess.IsZero() {
+				o.Observe(unixSeconds(s.lastSuccess), metric.WithAttributes(endpoint))
 			}
-		} else {
-			return plog.NewLogs(), fmt.Errorf("client not initialized")
+			return nil
+		}),
+		s.telemetry.RegisterOpcuaLogObjectLastSuccessTimestampCallback(func(_ context.Context, o metric.Float64Observer) error {
+			s.successMu.Lock()
+			defer s.successMu.Unlock()
+			for logObjectID, t := range s.logObjectSuccess {
+				o.Observe(unixSeconds(t), metric.WithAttributes(endpoint, attribute.String("opcua.log_object", logObjectID)))
+			}
+			return nil
+		}),
+	)
+}
+
+// recordHealth adds the outcome of a scrape to the error rate. When the
+// receiver becomes degraded or recovers, it logs and reports a recoverable
+// error or OK status event to the host.
+func (s *scraper) recordHealth(err error) {
+	state, changed := s.health.record(s.now(), err != nil)
+	if !changed {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("endpoint", s.config.Endpoint),
+		zap.Float64("error_rate", state.errorRate),
+		zap.Int("scrapes", state.scrapes),
+		zap.Duration("window", s.health.window),
+	}
+	if state.degraded {
+		s.settings.Logger.Error("OPC UA receiver degraded: scrape error rate reached health.error_rate_threshold",
+			append(fields, zap.Float64("threshold", s.health.threshold), zap.Error(err))...)
+		statusErr := fmt.Errorf("scrape error rate %.2f reached health.error_rate_threshold", state.errorRate)
+		if err != nil {
+			statusErr = fmt.Errorf("%w: %w", statusErr, err)
 		}
+		s.reportStatus(componentstatus.NewRecoverableErrorEvent(statusErr))
+		return
+	}
+	s.settings.Logger.Info("OPC UA receiver recovered: scrape error rate below health.error_rate_threshold", fields...)
+	s.reportStatus(componentstatus.NewEvent(componentstatus.StatusOK))
+}
+
+// reportStatus reports a component status event to the host, if started
+func (s *scraper) reportStatus(event *componentstatus.Event) {
+	if s.host != nil {
+		componentstatus.ReportStatus(s.host, event)
 	}
+}
 
-	// Calculate time range for this collection
-	endTime := time.Now()
+// registerHealthCallbacks reports the scrape error rate and the degraded
+// state while health tracking is enabled
+func (s *scraper) registerHealthCallbacks() error {
+	if !s.health.enabled() {
+		return nil
+	}
+	endpoint := metric.WithAttributes(attribute.String("opcua.endpoint", s.config.Endpoint))
+	return errors.Join(
+		s.telemetry.RegisterOpcuaScrapeErrorRatioCallback(func(_ context.Context, o metric.Float64Observer) error {
+			if state := s.health.state(); state.scrapes > 0 {
+				o.Observe(state.errorRate, endpoint)
+			}
+			return nil
+		}),
+		s.telemetry.RegisterOpcuaDegradedCallback(func(_ context.Context, o metric.Int64Observer) error {
+			var degraded int64
+			if s.health.state().degraded {
+				degraded = 1
+			}
+			o.Observe(degraded, endpoint)
+			return nil
+		}),
+	)
+}
+
+// unixSeconds converts t to fractional seconds since the Unix epoch
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / float64(time.Second)
+}
+
+// logSummary logs one info line per scrape with its window, duration, the
+// records and pages read per LogObject and the errors
+func (s *scraper) logSummary(windowStart time.Time, duration time.Duration, records int, reads []logObjectRead, err error) {
+	pages, failed := 0, 0
+	for _, read := range reads {
+		pages += read.pages
+		if read.err != nil {
+			failed++
+		}
+	}
+
+	fields := []zap.Field{
+		zap.Time("window_start", windowStart),
+		zap.Duration("duration", duration),
+		zap.Int("records", records),
+		zap.Int("pages", pages),
+		zap.Objects("log_objects", reads),
+		zap.Int("failed_log_objects", failed),
+	}
+	if err != nil {
+		s.settings.Logger.Info("Scrape failed", append(fields,
+			zap.String("error_class", errorClass(err)),
+			zap.Error(err))...)
+		return
+	}
+	if !s.resumeEnd.IsZero() {
+		s.settings.Logger.Info("Scrape completed, record budget spent", append(fields, zap.Time("resume_window_end", s.resumeEnd))...)
+		return
+	}
+	s.settings.Logger.Info("Scrape completed", append(fields, zap.Time("window_end", s.lastCollectTime))...)
+}
+
+// logObjectRead records a LogObject read of the running scrape
+func (s *scraper) logObjectRead(read logObjectRead) {
+	s.readsMu.Lock()
+	defer s.readsMu.Unlock()
+	s.reads = append(s.reads, read)
+}
+
+// takeReads returns and resets the LogObject reads of the running scrape
+func (s *scraper) takeReads() []logObjectRead {
+	s.readsMu.Lock()
+	defer s.readsMu.Unlock()
+	reads := s.reads
+	s.reads = nil
+	return reads
+}
+
+// failureAttributes describes err by its error class and, if it has one, its status code
+func failureAttributes(err error) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("opcua.error_class", errorClass(err))}
+	if status := failureStatus(err); status != "" {
+		attrs = append(attrs, attribute.String("opcua.status_code", status))
+	}
+	return attrs
+}
+
+// failureSeverity is the Part 26 severity of diagnostic failure records (Error)
+const failureSeverity = 250
+
+// failureRecords creates a diagnostic log record for each skipped LogObject
+func (s *scraper) failureRecords(failures []logObjectRead) []model.LogRecord {
+	records := make([]model.LogRecord, 0, len(failures))
+	for _, f := range failures {
+		attrs := map[string]interface{}{
+			"opcua.log_object":  f.logObjectID,
+			"opcua.error_class": errorClass(f.err),
+		}
+		if status := failureStatus(f.err); status != "" {
+			attrs["opcua.status_code"] = status
+		}
+		records = append(records, model.LogRecord{
+			Timestamp:   s.now(),
+			Severity:    failureSeverity,
+			Message:     fmt.Sprintf("GetRecords failed on LogObject %s: %v", f.logObjectID, f.err),
+			SourceName:  "opcua receiver",
+			LogObjectID: f.logObjectID,
+			Attributes:  attrs,
+		})
+	}
+	return records
+}
+
+// ensureConnected reconnects a lost session. Clients recovering sessions on
+// their own are left to do so, others are reconnected right away.
+func (s *scraper) ensureConnected(ctx context.Context) error {
+	if s.client.IsConnected() {
+		return nil
+	}
+	if recoverer, ok := s.client.(sessionRecoverer); ok {
+		if err := recoverer.recoverSession(ctx); err != nil {
+			return fmt.Errorf("failed to reconnect: %w", err)
+		}
+		return nil
+	}
+
+	s.settings.Logger.Info("Attempting to reconnect to OPC UA server")
+	if err := s.client.Connect(ctx); err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+	return nil
+}
+
+// collect retrieves log records from the OPC UA server
+func (s *scraper) collect(ctx context.Context) ([]model.LogRecord, error) {
+	if s.client == nil {
+		return nil, fmt.Errorf("client not initialized")
+	}
+	if err := s.ensureConnected(ctx); err != nil {
+		return nil, err
+	}
+
+	// Calculate time range for this collection. With correct_clock_skew the
+	// window ends at the server's current time, so records of a server clock
+	// running ahead are not left behind the window.
+	endTime := s.now()
 	startTime := s.lastCollectTime
+	if !s.resumeEnd.IsZero() {
+		endTime = s.resumeEnd
+	} else {
+		if s.config.CorrectClockSkew {
+			endTime = endTime.Add(s.clockSkew(ctx))
+			// A server clock set back does not move the window backwards
+			if endTime.Before(startTime) {
+				endTime = startTime
+			}
+		}
+		endTime = s.boundCatchUp(startTime, endTime)
+	}
 
 	// Collect log records
 	s.settings.Logger.Debug("Collecting OPC UA logs",
 		zap.Time("start_time", startTime),
 		zap.Time("end_time", endTime),
-		zap.Int("max_records", s.config.MaxRecordsPerCall))
+		zap.Int("max_records", s.config.maxRecordsPerScrape()))
 
-	records, err := s.client.GetRecords(ctx, startTime, endTime, s.config.MaxRecordsPerCall)
+	if reader, ok := s.client.(positionReader); ok {
+		reader.setReadPositions(maps.Clone(s.positions))
+	}
+	records, err := s.getRecords(ctx, startTime, endTime)
 	if err != nil {
-		s.settings.Logger.Error("Failed to get records from OPC UA server", zap.Error(err))
-		return plog.NewLogs(), fmt.Errorf("failed to get records: %w", err)
+		s.errorLog.Error(s.settings.Logger, "get_records", "Failed to get records from OPC UA server",
+			zap.String("error_class", errorClass(err)),
+			zap.Error(err))
+		return nil, fmt.Errorf("failed to get records: %w", err)
+	}
+	s.errorLog.Clear(s.settings.Logger, "get_records")
+	records = s.truncateRecords(ctx, records)
+
+	if s.config.DerivedMetrics.Enabled {
+		s.recordSeverityMetrics(ctx, records)
 	}
 
-	s.settings.Logger.Info("Collected OPC UA log records",
-		zap.Int("record_count", len(records)))
+	// Records left in the window are read by the next scrape before the
+	// window advances
+	if resumer, ok := s.client.(queryResumer); ok && resumer.unfinishedQuery() {
+		s.resumeEnd = endTime
+		s.advancePositions(records)
+		s.finishPositions()
+		return records, nil
+	}
 
-	// Update last collect time
+	// Update last collect time. LogObjects skipped because their read failed
+	// keep their position, so the next windows read them from where they
+	// stopped instead of losing their records.
+	s.finishPositions()
+	maps.DeleteFunc(s.positions, func(_ string, position readPosition) bool { return position.Done })
+	if len(s.positions) == 0 {
+		s.positions = nil
+	}
+	s.resumeEnd = time.Time{}
 	s.lastCollectTime = endTime
 
-	// Transform OPC UA records to OpenTelemetry logs
-	logs := s.transformer.TransformLogs(records)
+	return records, nil
+}
 
-	return logs, nil
+// advancePositions moves the read positions of the LogObjects in the window
+// past the records handed over
+func (s *scraper) advancePositions(records []model.LogRecord) {
+	if _, ok := s.client.(positionReader); !ok {
+		return
+	}
+	for _, record := range records {
+		position, ok := s.positions[record.LogObjectID]
+		if !ok {
+			position = readPosition{Start: s.lastCollectTime}
+		}
+		if s.positions == nil {
+			s.positions = make(map[string]readPosition)
+		}
+		s.positions[record.LogObjectID] = position.advance(record.Timestamp)
+	}
+}
+
+// finishPositions marks the LogObjects whose read of the running scrape
+// reached the window end done, and keeps a position for those skipped
+// because their read failed
+func (s *scraper) finishPositions() {
+	if _, ok := s.client.(positionReader); !ok {
+		return
+	}
+	s.readsMu.Lock()
+	defer s.readsMu.Unlock()
+	for _, read := range s.reads {
+		if !read.finished && read.err == nil {
+			continue
+		}
+		position, ok := s.positions[read.logObjectID]
+		if !ok {
+			position = readPosition{Start: s.lastCollectTime}
+		}
+		position.Done = read.finished
+		if s.positions == nil {
+			s.positions = make(map[string]readPosition)
+		}
+		s.positions[read.logObjectID] = position
+	}
+}
+
+// truncateRecords enforces filter.max_log_records on the records of a scrape,
+// including the batches already handed over. The built-in client starts no
+// read once the budget is spent and leaves the rest of the window, including
+// the LogObjects it did not read, to the next scrape; records another client
+// or a server returns beyond it are dropped and counted.
+func (s *scraper) truncateRecords(ctx context.Context, records []model.LogRecord) []model.LogRecord {
+	limit := s.config.Filter.MaxLogRecords
+	over := min(s.flushed.count+len(records)-limit, len(records))
+	if limit <= 0 || over <= 0 {
+		s.errorLog.Clear(s.settings.Logger, "max_log_records")
+		return records
+	}
+
+	kept := len(records) - over
+	for i := kept; i < len(records); i++ {
+		putAttributeMap(records[i].Attributes)
+	}
+	clear(records[kept:])
+	s.telemetry.OpcuaRecordsTruncated.Add(ctx, int64(over),
+		metric.WithAttributes(attribute.String("opcua.endpoint", s.config.Endpoint)))
+	s.errorLog.Warn(s.settings.Logger, "max_log_records", "Scrape returned more records than filter.max_log_records, dropping the rest",
+		zap.Int("max_log_records", limit),
+		zap.Int("truncated", over))
+	return records[:kept]
+}
+
+// boundCatchUp returns the end of a window from startTime to endTime that
+// spans at most max_catchup_duration. After downtime, the backlog is thus
+// read in bounded windows, one per scrape, instead of a single query over
+// days. A window from the beginning of the history is not bounded, as its
+// start is unknown.
+func (s *scraper) boundCatchUp(startTime, endTime time.Time) time.Time {
+	maxWindow := s.config.MaxCatchupDuration
+	if maxWindow <= 0 || startTime.IsZero() || endTime.Sub(startTime) <= maxWindow {
+		if s.catchingUp {
+			s.settings.Logger.Info("Caught up with the server log", zap.Time("window_end", endTime))
+		}
+		s.catchingUp = false
+		return endTime
+	}
+
+	if !s.catchingUp {
+		s.settings.Logger.Info("Catching up with the server log in bounded windows",
+			zap.Time("window_start", startTime),
+			zap.Duration("backlog", endTime.Sub(startTime)),
+			zap.Duration("max_catchup_duration", maxWindow))
+	}
+	s.catchingUp = true
+	return startTime.Add(maxWindow)
+}
+
+// now returns the current time of the scraper's clock, the wall clock if none is set
+func (s *scraper) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock.Now()
+}
+
+// severityBandKey identifies a severity band on a single LogObject
+type severityBandKey struct {
+	logObjectID string
+	band        string
+}
+
+// recordSeverityMetrics counts records per Part 26 severity band and LogObject
+// and adds them to the otelcol_opcua_records_by_severity counter
+func (s *scraper) recordSeverityMetrics(ctx context.Context, records []model.LogRecord) {
+	counts := make(map[severityBandKey]int64)
+	for _, record := range records {
+		counts[severityBandKey{logObjectID: record.LogObjectID, band: severityToText(record.Severity)}]++
+	}
+
+	for key, count := range counts {
+		s.telemetry.OpcuaRecordsBySeverity.Add(ctx, count, metric.WithAttributes(
+			attribute.String("opcua.log_object", key.logObjectID),
+			attribute.String("opcua.severity_band", key.band),
+		))
+	}
 }
@@ -5,14 +5,31 @@ package opcua
 
 import (
 	"context"
+	"errors"
+	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/gopcua/opcua/ua"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/collector/scraper/scrapererror"
+	"go.opentelemetry.io/collector/scraper/scraperhelper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadatatest"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
 	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
 )
 
@@ -69,9 +86,9 @@ func TestScraperIntegration(t *testing.T) {
 
 	// Create configuration
 	config := &Config{
-		Endpoint:           mockServer.Endpoint(),
-		CollectionInterval: 30 * time.Second,
-		MaxRecordsPerCall:  100,
+		Endpoint:          mockServer.Endpoint(),
+		ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+		MaxRecordsPerCall: 100,
 		Filter: FilterConfig{
 			MinSeverity:   "Info",
 			MaxLogRecords: 1000,
@@ -91,13 +108,14 @@ func TestScraperIntegration(t *testing.T) {
 	}()
 
 	// Create scraper with mock client
-	transformer := NewTransformer(mockServer.Endpoint(), "opcua-server", "")
+	transformer := newRecordTransformer(mockServer.Endpoint(), "opcua-server", "")
 	settings := componenttest.NewNopTelemetrySettings()
 	scr := &scraper{
 		config:      config,
 		settings:    settings,
 		transformer: transformer,
 		client:      &mockClientAdapter{mockClient: mockClient, config: config},
+		telemetry:   newTestTelemetryBuilder(t, settings),
 	}
 
 	// Run scraper
@@ -106,30 +124,7 @@ func TestScraperIntegration(t *testing.T) {
 	require.NotNil(t, logs)
 
 	// Verify results
-	assert.Equal(t, 1, logs.ResourceLogs().Len(), "Should have 1 resource log")
-
-	resourceLogs := logs.ResourceLogs().At(0)
-	assert.Equal(t, 1, resourceLogs.ScopeLogs().Len(), "Should have 1 scope log")
-
-	scopeLogs := resourceLogs.ScopeLogs().At(0)
-	logRecords := scopeLogs.LogRecords()
-
-	assert.Equal(t, 3, logRecords.Len(), "Should have 3 log records")
-
-	// Verify first log record
-	logRecord := logRecords.At(0)
-	assert.Equal(t, "Test log message 1", logRecord.Body().AsString())
-	assert.NotZero(t, logRecord.Timestamp())
-
-	// Verify second log record with trace context
-	logRecord2 := logRecords.At(1)
-	assert.Equal(t, "Test log message 2", logRecord2.Body().AsString())
-	assert.False(t, logRecord2.TraceID().IsEmpty(), "Should have trace ID")
-	assert.False(t, logRecord2.SpanID().IsEmpty(), "Should have span ID")
-
-	// Verify third log record
-	logRecord3 := logRecords.At(2)
-	assert.Equal(t, "Test log message 3", logRecord3.Body().AsString())
+	requireLogs(t, sampleRecords, logs, withExpectedEndpoint(mockServer.Endpoint()))
 
 	t.Log("Integration test completed successfully")
 }
@@ -166,9 +161,9 @@ func TestScraperIntegrationPagination(t *testing.T) {
 
 	// Create configuration with small batch size
 	config := &Config{
-		Endpoint:           mockServer.Endpoint(),
-		CollectionInterval: 30 * time.Second,
-		MaxRecordsPerCall:  50, // Small batch to trigger pagination
+		Endpoint:          mockServer.Endpoint(),
+		ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+		MaxRecordsPerCall: 50, // Small batch to trigger pagination
 		Filter: FilterConfig{
 			MinSeverity:   "Info",
 			MaxLogRecords: 1000,
@@ -187,13 +182,14 @@ func TestScraperIntegrationPagination(t *testing.T) {
 	}()
 
 	// Create scraper
-	transformer := NewTransformer(mockServer.Endpoint(), "opcua-server", "")
+	transformer := newRecordTransformer(mockServer.Endpoint(), "opcua-server", "")
 	settings := componenttest.NewNopTelemetrySettings()
 	scr := &scraper{
 		config:      config,
 		settings:    settings,
 		transformer: transformer,
 		client:      &mockClientAdapter{mockClient: mockClient, config: config},
+		telemetry:   newTestTelemetryBuilder(t, settings),
 	}
 
 	// Run scraper
@@ -243,9 +239,9 @@ func TestScraperIntegrationFiltering(t *testing.T) {
 
 	// Test with Warning minimum severity
 	config := &Config{
-		Endpoint:           mockServer.Endpoint(),
-		CollectionInterval: 30 * time.Second,
-		MaxRecordsPerCall:  100,
+		Endpoint:          mockServer.Endpoint(),
+		ControllerConfig:  scraperhelper.ControllerConfig{CollectionInterval: 30 * time.Second},
+		MaxRecordsPerCall: 100,
 		Filter: FilterConfig{
 			MinSeverity:   "Warning",
 			MaxLogRecords: 1000,
@@ -262,13 +258,14 @@ func TestScraperIntegrationFiltering(t *testing.T) {
 		}
 	}()
 
-	transformer := NewTransformer(mockServer.Endpoint(), "opcua-server", "")
+	transformer := newRecordTransformer(mockServer.Endpoint(), "opcua-server", "")
 	settings := componenttest.NewNopTelemetrySettings()
 	scr := &scraper{
 		config:      config,
 		settings:    settings,
 		transformer: transformer,
 		client:      &mockClientAdapter{mockClient: mockClient, config: config},
+		telemetry:   newTestTelemetryBuilder(t, settings),
 	}
 
 	logs, err := scr.scrape(ctx)
@@ -289,6 +286,1028 @@ func TestScraperIntegrationFiltering(t *testing.T) {
 	t.Log("Filtering test completed successfully")
 }
 
+// TestScraperTelemetry verifies that scrapes report records, errors, and duration
+// through the generated TelemetryBuilder
+func TestScraperTelemetry(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	logger := zap.NewNop()
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54843", logger)
+	require.NoError(t, mockServer.Start(ctx))
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{
+		{Timestamp: time.Now(), Severity: 150, Message: "first", Attributes: make(map[string]interface{})},
+		{Timestamp: time.Now(), Severity: 250, Message: "second", Attributes: make(map[string]interface{})},
+	})
+
+	config := &Config{
+		Endpoint:          mockServer.Endpoint(),
+		MaxRecordsPerCall: 100,
+		Filter:            FilterConfig{MinSeverity: "Info"},
+	}
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(ctx))
+
+	settings := tel.NewTelemetrySettings()
+	scr := &scraper{
+		config:      config,
+		settings:    settings,
+		transformer: newRecordTransformer(mockServer.Endpoint(), "opcua-server", ""),
+		client:      &mockClientAdapter{mockClient: mockClient, config: config},
+		telemetry:   newTestTelemetryBuilder(t, settings),
+	}
+
+	_, err := scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A stopped server makes the next scrape fail
+	require.NoError(t, mockServer.Stop(ctx))
+	require.NoError(t, mockClient.Disconnect(ctx))
+	_, err = scr.scrape(ctx)
+	require.Error(t, err)
+
+	// The mock client does not report LogObjects, its records only carry the endpoint
+	endpoint := attribute.String("opcua.endpoint", mockServer.Endpoint())
+	metadatatest.AssertEqualOpcuaRecordsScraped(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 2, Attributes: attribute.NewSet(endpoint)}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualOpcuaScrapeErrors(t, tel,
+		[]metricdata.DataPoint[int64]{{
+			Value:      1,
+			Attributes: attribute.NewSet(endpoint, attribute.String("opcua.error_class", "other")),
+		}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualOpcuaScrapeDuration(t, tel,
+		[]metricdata.HistogramDataPoint[float64]{{Attributes: attribute.NewSet(endpoint)}},
+		metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+}
+
+// TestScraperLatencyTelemetry verifies the connect, Call and discovery
+// round-trip histograms reported by the client
+func TestScraperLatencyTelemetry(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(5))
+	ws.SetMaxPageSize(2)
+
+	scr, err := newScraper(ws.newWireConfig(), tel.NewTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	metadatatest.AssertEqualOpcuaConnectDuration(t, tel,
+		[]metricdata.HistogramDataPoint[float64]{{}},
+		metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+	metadatatest.AssertEqualOpcuaCallDuration(t, tel,
+		[]metricdata.HistogramDataPoint[float64]{{
+			Attributes: attribute.NewSet(attribute.String("opcua.log_object", ws.logObjectID.String())),
+		}},
+		metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+	metadatatest.AssertEqualOpcuaBrowseDuration(t, tel,
+		[]metricdata.HistogramDataPoint[float64]{
+			{Attributes: attribute.NewSet(attribute.String("opcua.service", "Read"))},
+			{Attributes: attribute.NewSet(attribute.String("opcua.service", "Browse"))},
+		},
+		metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+
+	// One Call per page
+	got, err := tel.GetMetric("otelcol_opcua_call_duration")
+	require.NoError(t, err)
+	calls := got.Data.(metricdata.Histogram[float64]).DataPoints
+	require.Len(t, calls, 1)
+	assert.Equal(t, uint64(3), calls[0].Count)
+}
+
+// TestScraperStalenessTelemetry verifies that the last successful scrape and
+// LogObject reads are reported and kept while later scrapes fail
+func TestScraperStalenessTelemetry(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	failingObjectID := ua.NewNumericNodeID(1, 2000)
+	ws := startWireServer(t)
+	require.NoError(t, ws.AddLogObject(failingObjectID, ua.NewNumericNodeID(1, 2001)))
+	ws.AddLogRecords(wireRecords(3))
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, LogObject: failingObjectID, Status: ua.StatusBadInternalError})
+
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{ws.logObjectID.String(), failingObjectID.String()}
+	scr, err := newScraper(cfg, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+	clk := newFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// Nothing is reported before the first successful scrape
+	_, err = tel.GetMetric("otelcol_opcua_last_successful_scrape_timestamp")
+	require.Error(t, err)
+
+	// Skipping the failing LogObject fails the scrape only partially
+	_, err = scr.scrape(ctx)
+	require.True(t, scrapererror.IsPartialScrapeError(err))
+
+	endpoint := attribute.String("opcua.endpoint", cfg.Endpoint)
+	want := float64(clk.Now().Unix())
+	assertStaleness := func() {
+		metadatatest.AssertEqualOpcuaLastSuccessfulScrapeTimestamp(t, tel,
+			[]metricdata.DataPoint[float64]{{Value: want, Attributes: attribute.NewSet(endpoint)}},
+			metricdatatest.IgnoreTimestamp())
+		metadatatest.AssertEqualOpcuaLogObjectLastSuccessTimestamp(t, tel,
+			[]metricdata.DataPoint[float64]{{
+				Value:      want,
+				Attributes: attribute.NewSet(endpoint, attribute.String("opcua.log_object", ws.logObjectID.String())),
+			}},
+			metricdatatest.IgnoreTimestamp())
+	}
+	assertStaleness()
+
+	// A failing scrape keeps the timestamps, so their age grows
+	clk.Advance(10 * time.Minute)
+	ws.srv.Close()
+	_, err = scr.scrape(ctx)
+	require.Error(t, err)
+	assertStaleness()
+}
+
+// TestScraperHealth verifies that a scrape error rate at the threshold
+// degrades the receiver and that it recovers once the rate drops
+func TestScraperHealth(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Health = HealthConfig{ErrorRateWindow: 10 * time.Minute, ErrorRateThreshold: 0.5, MinScrapes: 3}
+	core, observed := observer.New(zapcore.InfoLevel)
+	settings := tel.NewTelemetrySettings()
+	settings.Logger = zap.New(core)
+	scr, err := newScraper(cfg, settings)
+	require.NoError(t, err)
+	clk := newFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC))
+	scr.clock = clk
+	client := &windowClient{}
+	scr.client = client
+
+	endpoint := attribute.NewSet(attribute.String("opcua.endpoint", cfg.Endpoint))
+	scrape := func(failing bool) {
+		client.err = nil
+		if failing {
+			client.err = errors.New("connection reset")
+		}
+		_, _ = scr.scrape(ctx)
+		clk.Advance(time.Minute)
+	}
+
+	// One failure in two scrapes is a blip below min_scrapes
+	scrape(false)
+	scrape(true)
+	assert.Empty(t, observed.FilterMessageSnippet("degraded").All())
+	metadatatest.AssertEqualOpcuaDegraded(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 0, Attributes: endpoint}},
+		metricdatatest.IgnoreTimestamp())
+
+	scrape(true)
+	degraded := observed.FilterMessageSnippet("degraded").All()
+	require.Len(t, degraded, 1)
+	assert.Equal(t, zapcore.ErrorLevel, degraded[0].Level)
+	assert.Equal(t, "connection reset", degraded[0].ContextMap()["error"])
+	metadatatest.AssertEqualOpcuaDegraded(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 1, Attributes: endpoint}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualOpcuaScrapeErrorRatio(t, tel,
+		[]metricdata.DataPoint[float64]{{Value: 2.0 / 3, Attributes: endpoint}},
+		metricdatatest.IgnoreTimestamp())
+
+	// Further failures do not log again
+	scrape(true)
+	assert.Len(t, observed.FilterMessageSnippet("degraded").All(), 1)
+
+	for i := 0; i < 4; i++ {
+		scrape(false)
+	}
+	require.Len(t, observed.FilterMessageSnippet("recovered").All(), 1)
+	metadatatest.AssertEqualOpcuaDegraded(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 0, Attributes: endpoint}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+// TestScraperLogObjectFailures verifies that a LogObject skipped because its
+// GetRecords call failed is counted with its status code and, with
+// diagnostics.failure_records, reported as a log record
+func TestScraperLogObjectFailures(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	failingObjectID := ua.NewNumericNodeID(1, 2000)
+	ws := startWireServer(t)
+	require.NoError(t, ws.AddLogObject(failingObjectID, ua.NewNumericNodeID(1, 2001)))
+	ws.AddLogRecords(wireRecords(3))
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, LogObject: failingObjectID, Status: ua.StatusBadInternalError})
+
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{ws.logObjectID.String(), failingObjectID.String()}
+	cfg.Diagnostics.FailureRecords = true
+	scr, err := newScraper(cfg, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	logs, err := scr.scrape(ctx)
+	var partialErr scrapererror.PartialScrapeError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, 1, partialErr.Failed)
+	assert.ErrorContains(t, err, "log object ns=1;i=2000")
+	assert.Equal(t, "method_call", errorClass(err))
+
+	metadatatest.AssertEqualOpcuaLogObjectErrors(t, tel,
+		[]metricdata.DataPoint[int64]{{
+			Value: 1,
+			Attributes: attribute.NewSet(
+				attribute.String("opcua.log_object", "ns=1;i=2000"),
+				attribute.String("opcua.error_class", "method_call"),
+				attribute.String("opcua.status_code", "BadInternalError")),
+		}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualOpcuaRecordsScraped(t, tel,
+		[]metricdata.DataPoint[int64]{{
+			Value: 3,
+			Attributes: attribute.NewSet(
+				attribute.String("opcua.endpoint", ws.endpoint),
+				attribute.String("opcua.log_object", ws.logObjectID.String())),
+		}},
+		metricdatatest.IgnoreTimestamp())
+
+	// The records of the healthy LogObject are followed by the failure record
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 4, lrs.Len())
+	failure := lrs.At(3)
+	assert.Equal(t, "Error", failure.SeverityText())
+	assert.Contains(t, failure.Body().Str(), "GetRecords failed on LogObject ns=1;i=2000")
+	status, ok := failure.Attributes().Get("opcua.status_code")
+	require.True(t, ok)
+	assert.Equal(t, "BadInternalError", status.Str())
+	class, ok := failure.Attributes().Get("opcua.error_class")
+	require.True(t, ok)
+	assert.Equal(t, "method_call", class.Str())
+}
+
+// TestScraperLogObjectFailureRecovers verifies that the records of a
+// LogObject skipped by a partial scrape are read once it recovers, while the
+// window moved on for the others
+func TestScraperLogObjectFailureRecovers(t *testing.T) {
+	ctx := context.Background()
+	failingObjectID := ua.NewNumericNodeID(1, 2000)
+	ws := startWireServer(t)
+	require.NoError(t, ws.AddLogObject(failingObjectID, ua.NewNumericNodeID(1, 2001)))
+	ws.AddLogRecords(wireRecords(3))
+	require.NoError(t, ws.AddLogRecordsTo(failingObjectID, wireRecords(2)...))
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, LogObject: failingObjectID, Status: ua.StatusBadInternalError})
+
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{ws.logObjectID.String(), failingObjectID.String()}
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	clk := newFakeClock(time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC))
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	countByLogObject := func(records []model.LogRecord) map[string]int {
+		counts := make(map[string]int)
+		for _, record := range records {
+			counts[record.LogObjectID]++
+		}
+		return counts
+	}
+
+	records, err := scr.scrapeRecords(ctx)
+	require.True(t, scrapererror.IsPartialScrapeError(err))
+	assert.Equal(t, map[string]int{ws.logObjectID.String(): 3}, countByLogObject(records))
+	assert.Equal(t, clk.Now(), scr.lastCollectTime)
+	assert.Contains(t, scr.positions, failingObjectID.String())
+
+	// Still failing, the LogObject keeps its position
+	clk.Advance(time.Minute)
+	records, err = scr.scrapeRecords(ctx)
+	require.True(t, scrapererror.IsPartialScrapeError(err))
+	assert.Empty(t, records)
+
+	// Once it recovers, the records of the windows it failed in arrive with
+	// the new records of the others
+	ws.ClearFaults()
+	ws.AddLogRecords([]testdata.OPCUALogRecord{{Timestamp: clk.Now().Add(30 * time.Second), Severity: 500, Message: "new", Attributes: map[string]interface{}{}}})
+	clk.Advance(time.Minute)
+	records, err = scr.scrapeRecords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{ws.logObjectID.String(): 1, failingObjectID.String(): 2}, countByLogObject(records))
+	assert.Empty(t, scr.positions)
+
+	clk.Advance(time.Minute)
+	records, err = scr.scrapeRecords(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+}
+
+// TestReceiverWirePartialScrape verifies that the logs of a partially failed
+// scrape are delivered with a PartialScrapeError
+func TestReceiverWirePartialScrape(t *testing.T) {
+	ctx := context.Background()
+	failingObjectID := ua.NewNumericNodeID(1, 2000)
+	ws := startWireServer(t)
+	require.NoError(t, ws.AddLogObject(failingObjectID, ua.NewNumericNodeID(1, 2001)))
+	ws.AddLogRecords(wireRecords(3))
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, LogObject: failingObjectID, Status: ua.StatusBadInternalError})
+
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{ws.logObjectID.String(), failingObjectID.String()}
+	r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	sink := new(consumertest.LogsSink)
+	r.nextLogs = sink
+	require.NoError(t, r.scraper.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.scraper.shutdown(ctx))
+	}()
+
+	// Catching up goes on past a partial scrape
+	require.NoError(t, r.collectAndConsume(ctx))
+	assert.Equal(t, 3, sink.LogRecordCount())
+
+	// The controller counts the skipped LogObject, even without records
+	logs, err := r.scrapeLogs(ctx)
+	var partialErr scrapererror.PartialScrapeError
+	require.ErrorAs(t, err, &partialErr)
+	assert.Equal(t, 1, partialErr.Failed)
+	assert.Equal(t, 0, logs.LogRecordCount())
+}
+
+// TestScraperGapRecords verifies the warning record emitted when the log
+// buffer of a LogObject overflowed between two scrapes
+func TestScraperGapRecords(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(base.Add(10 * time.Second))
+	ws := startWireServer(t)
+	require.NoError(t, ws.SetLogBufferCapacity(ws.logObjectID, 5))
+	ws.AddLogRecords(testdata.NewSeededGenerator(1, base).SteadyInfo(5, time.Second))
+
+	cfg := ws.newWireConfig()
+	cfg.Diagnostics.GapRecords = true
+	cfg.Diagnostics.GapThreshold = 5 * time.Second
+	scr, err := newScraper(cfg, tel.NewTelemetrySettings())
+	require.NoError(t, err)
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	// The first collection reads the whole buffer
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, logs.LogRecordCount())
+
+	// Ten records between scrapes overflow the buffer, the oldest one left
+	// was logged 15s after the window start
+	ws.AddLogRecords(testdata.NewSeededGenerator(2, base.Add(20*time.Second)).SteadyInfo(10, time.Second))
+	clk.Advance(30 * time.Second)
+	logs, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 6, lrs.Len())
+	gap := lrs.At(5)
+	assert.Equal(t, "Warning", gap.SeverityText())
+	assert.Contains(t, gap.Body().Str(), "No records from LogObject ns=1;i=1000")
+	gapStart, ok := gap.Attributes().Get("opcua.gap.start")
+	require.True(t, ok)
+	assert.Equal(t, "2025-01-15T10:00:10Z", gapStart.Str())
+	gapEnd, ok := gap.Attributes().Get("opcua.gap.end")
+	require.True(t, ok)
+	assert.Equal(t, "2025-01-15T10:00:25Z", gapEnd.Str())
+
+	metadatatest.AssertEqualOpcuaRecordGaps(t, tel,
+		[]metricdata.DataPoint[int64]{{
+			Value:      1,
+			Attributes: attribute.NewSet(attribute.String("opcua.log_object", "ns=1;i=1000")),
+		}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+// TestScraperSummaryLog verifies the info line logged after every scrape
+func TestScraperSummaryLog(t *testing.T) {
+	ctx := context.Background()
+	failingObjectID := ua.NewNumericNodeID(1, 2000)
+	ws := startWireServer(t)
+	require.NoError(t, ws.AddLogObject(failingObjectID, ua.NewNumericNodeID(1, 2001)))
+	ws.AddLogRecords(wireRecords(5))
+	ws.SetMaxPageSize(2)
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, LogObject: failingObjectID, Status: ua.StatusBadNotReadable})
+
+	cfg := ws.newWireConfig()
+	cfg.LogObjectPaths = []string{ws.logObjectID.String(), failingObjectID.String()}
+	cfg.RequestTimeout = time.Second
+	core, observed := observer.New(zapcore.InfoLevel)
+	settings := componenttest.NewNopTelemetrySettings()
+	settings.Logger = zap.New(core)
+	scr, err := newScraper(cfg, settings)
+	require.NoError(t, err)
+	clk := newFakeClock(time.Now())
+	scr.clock = clk
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	_, err = scr.scrape(ctx)
+	require.True(t, scrapererror.IsPartialScrapeError(err))
+
+	summaries := observed.FilterMessage("Scrape completed").All()
+	require.Len(t, summaries, 1)
+	fields := summaries[0].ContextMap()
+	assert.Equal(t, int64(5), fields["records"])
+	assert.Equal(t, int64(3), fields["pages"])
+	assert.Equal(t, int64(1), fields["failed_log_objects"])
+	assert.Equal(t, time.Time{}, fields["window_start"])
+	assert.Equal(t, clk.Now(), fields["window_end"])
+	logObjects, ok := fields["log_objects"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, logObjects, 2)
+	assert.Equal(t, map[string]interface{}{"node_id": "ns=1;i=1000", "records": int64(5), "pages": int64(3)}, logObjects[0])
+	skipped := logObjects[1].(map[string]interface{})
+	assert.Equal(t, "ns=1;i=2000", skipped["node_id"])
+	assert.Equal(t, "method_call", skipped["error_class"])
+	assert.Contains(t, skipped["error"], "BadNotReadable")
+
+	// A failed scrape is summarized with its error
+	require.NoError(t, ws.srv.Close())
+	_, err = scr.scrape(ctx)
+	require.Error(t, err)
+	failed := observed.FilterMessage("Scrape failed").All()
+	require.Len(t, failed, 1)
+	assert.NotEmpty(t, failed[0].ContextMap()["error_class"])
+	assert.NotContains(t, failed[0].ContextMap(), "window_end")
+}
+
+// TestScraperDerivedMetrics verifies the per-severity-band record counts
+func TestScraperDerivedMetrics(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	logger := zap.NewNop()
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54844", logger)
+	require.NoError(t, mockServer.Start(ctx))
+	defer func() {
+		assert.NoError(t, mockServer.Stop(ctx))
+	}()
+	now := time.Now()
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{
+		{Timestamp: now, Severity: 120, Message: "notice 1", Attributes: make(map[string]interface{})},
+		{Timestamp: now, Severity: 130, Message: "notice 2", Attributes: make(map[string]interface{})},
+		{Timestamp: now, Severity: 275, Message: "critical", Attributes: make(map[string]interface{})},
+	})
+
+	config := &Config{
+		Endpoint:          mockServer.Endpoint(),
+		MaxRecordsPerCall: 100,
+		Filter:            FilterConfig{MinSeverity: "Info"},
+		DerivedMetrics:    DerivedMetricsConfig{Enabled: true},
+	}
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(ctx))
+
+	settings := tel.NewTelemetrySettings()
+	scr := &scraper{
+		config:      config,
+		settings:    settings,
+		transformer: newRecordTransformer(mockServer.Endpoint(), "opcua-server", ""),
+		client:      &mockClientAdapter{mockClient: mockClient, config: config},
+		telemetry:   newTestTelemetryBuilder(t, settings),
+	}
+
+	_, err := scr.scrape(ctx)
+	require.NoError(t, err)
+
+	metadatatest.AssertEqualOpcuaRecordsBySeverity(t, tel,
+		[]metricdata.DataPoint[int64]{
+			{
+				Value: 2,
+				Attributes: attribute.NewSet(
+					attribute.String("opcua.log_object", "i=2042"),
+					attribute.String("opcua.severity_band", "Notice")),
+			},
+			{
+				Value: 1,
+				Attributes: attribute.NewSet(
+					attribute.String("opcua.log_object", "i=2042"),
+					attribute.String("opcua.severity_band", "Critical")),
+			},
+		},
+		metricdatatest.IgnoreTimestamp())
+}
+
+// TestReceiverWireSeverityCounts verifies that the per-severity-band counts
+// reach the metrics pipeline as opcua.log.records, matching the internal
+// telemetry of derived_metrics
+func TestReceiverWireSeverityCounts(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	ws := startWireServer(t)
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	ws.AddLogRecords([]testdata.OPCUALogRecord{
+		{Timestamp: base, Severity: 120, Message: "notice 1", Attributes: make(map[string]interface{})},
+		{Timestamp: base, Severity: 130, Message: "notice 2", Attributes: make(map[string]interface{})},
+		{Timestamp: base, Severity: 275, Message: "critical", Attributes: make(map[string]interface{})},
+	})
+
+	cfg := ws.newWireConfig()
+	cfg.DerivedMetrics.Enabled = true
+	set := receivertest.NewNopSettings(metadata.Type)
+	set.TelemetrySettings = tel.NewTelemetrySettings()
+	r, err := newOPCUAReceiver(cfg, set)
+	require.NoError(t, err)
+	sink := new(consumertest.MetricsSink)
+	r.nextMetrics = sink
+	require.NoError(t, r.scraper.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.scraper.shutdown(ctx))
+	}()
+
+	require.NoError(t, r.collectAndConsume(ctx))
+
+	require.Len(t, sink.AllMetrics(), 1)
+	ms := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, ms.Len())
+	assert.Equal(t, "opcua.log.records", ms.At(0).Name())
+	counts := map[string]int64{}
+	dps := ms.At(0).Sum().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		logObject, _ := dps.At(i).Attributes().Get("opcua.log_object")
+		band, _ := dps.At(i).Attributes().Get("opcua.severity_band")
+		counts[logObject.Str()+" "+band.Str()] = dps.At(i).IntValue()
+	}
+	assert.Equal(t, map[string]int64{"ns=1;i=1000 Notice": 2, "ns=1;i=1000 Critical": 1}, counts)
+
+	metadatatest.AssertEqualOpcuaRecordsBySeverity(t, tel,
+		[]metricdata.DataPoint[int64]{
+			{
+				Value: 2,
+				Attributes: attribute.NewSet(
+					attribute.String("opcua.log_object", "ns=1;i=1000"),
+					attribute.String("opcua.severity_band", "Notice")),
+			},
+			{
+				Value: 1,
+				Attributes: attribute.NewSet(
+					attribute.String("opcua.log_object", "ns=1;i=1000"),
+					attribute.String("opcua.severity_band", "Critical")),
+			},
+		},
+		metricdatatest.IgnoreTimestamp())
+}
+
+// TestScraperMockServerFaults verifies that injected faults surface as scrape errors
+// and that the scraper recovers once the fault has passed
+func TestScraperMockServerFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault testdata.Fault
+	}{
+		{name: "too many operations", fault: testdata.Fault{Kind: testdata.FaultTooManyOperations, Call: 1}},
+		{name: "secure channel closed", fault: testdata.Fault{Kind: testdata.FaultSecureChannelClosed, Call: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			mockServer := testdata.NewMockServer("opc.tcp://localhost:54845", logger)
+			require.NoError(t, mockServer.Start(context.Background()))
+			defer func() {
+				require.NoError(t, mockServer.Stop(context.Background()))
+			}()
+			mockServer.AddLogRecords(testdata.GenerateSampleLogRecords(5))
+			mockServer.InjectFault(tt.fault)
+
+			config := createDefaultConfig().(*Config)
+			config.Filter.MinSeverity = "Debug"
+			settings := componenttest.NewNopTelemetrySettings()
+			scr := &scraper{
+				config:      config,
+				settings:    settings,
+				telemetry:   newTestTelemetryBuilder(t, settings),
+				transformer: newRecordTransformer(config.Endpoint, config.Resource.ServiceName, ""),
+				client: &mockClientAdapter{
+					mockClient: testdata.NewMockClient(mockServer, logger),
+					config:     config,
+				},
+			}
+			ctx := context.Background()
+			require.NoError(t, scr.client.Connect(ctx))
+
+			_, err := scr.scrape(ctx)
+			require.Error(t, err)
+
+			logs, err := scr.scrape(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, 5, logs.LogRecordCount())
+			assert.Equal(t, 2, mockServer.CallCount())
+		})
+	}
+}
+
+// TestScraperMockServerMalformedRecord verifies that a corrupt record is dropped
+// without failing the rest of the page
+func TestScraperMockServerMalformedRecord(t *testing.T) {
+	logger := zap.NewNop()
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54846", logger)
+	require.NoError(t, mockServer.Start(context.Background()))
+	defer func() {
+		require.NoError(t, mockServer.Stop(context.Background()))
+	}()
+	mockServer.AddLogRecords(testdata.GenerateSampleLogRecords(5))
+	mockServer.InjectFault(testdata.Fault{Kind: testdata.FaultMalformedRecord, Call: 1})
+
+	config := createDefaultConfig().(*Config)
+	config.Filter.MinSeverity = "Debug"
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(context.Background()))
+
+	records, _, err := mockClient.GetRecords(context.Background(), time.Time{}, time.Now(), 100, nil)
+	require.NoError(t, err)
+	assert.Len(t, records, 4)
+}
+
+// TestMockServerRecordStream verifies that a lazily produced backlog is paged
+// through completely and that the time window is applied to the stream
+func TestMockServerRecordStream(t *testing.T) {
+	const count = 200_000
+	base := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	stream := testdata.SyntheticStream{Seed: 1, Start: base, Interval: 100 * time.Millisecond, Count: count, ErrorRatio: 0.1}
+	assert.Equal(t, stream.At(1234), stream.At(1234))
+
+	tests := []struct {
+		name        string
+		start, end  time.Time
+		minSeverity uint16
+		expected    int
+	}{
+		{name: "full backlog", end: base.Add(24 * time.Hour), expected: count},
+		{name: "window", start: base.Add(time.Minute), end: base.Add(2*time.Minute - time.Millisecond), expected: 600},
+		{name: "errors only", end: base.Add(24 * time.Hour), minSeverity: 201, expected: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			mockServer := testdata.NewMockServer("opc.tcp://localhost:54848", logger)
+			require.NoError(t, mockServer.Start(context.Background()))
+			defer func() {
+				require.NoError(t, mockServer.Stop(context.Background()))
+			}()
+			require.NoError(t, mockServer.SetRecordStream(testdata.DefaultLogObjectID, stream))
+			assert.Equal(t, count, mockServer.GetLogRecordsCount())
+
+			mockClient := testdata.NewMockClient(mockServer, logger)
+			require.NoError(t, mockClient.Connect(context.Background()))
+
+			total, pages := 0, 0
+			var last time.Time
+			var continuationPoint []byte
+			for {
+				records, next, err := mockClient.GetRecordsWithSeverity(
+					context.Background(), tt.start, tt.end, 10000, tt.minSeverity, continuationPoint)
+				require.NoError(t, err)
+				for _, r := range records {
+					require.False(t, r.Timestamp.Before(last))
+					require.GreaterOrEqual(t, r.Severity, tt.minSeverity)
+					last = r.Timestamp
+				}
+				total += len(records)
+				pages++
+				if len(next) == 0 {
+					break
+				}
+				continuationPoint = next
+			}
+
+			if tt.expected >= 0 {
+				assert.Equal(t, tt.expected, total)
+			} else {
+				assert.InDelta(t, count/10, total, count/100)
+			}
+			assert.Equal(t, (total+9999)/10000, pages)
+		})
+	}
+}
+
+// TestMockServerEventSubscription verifies that pushed notifications expose
+// lost events as sequence gaps and that GetRecords recovers them
+func TestMockServerEventSubscription(t *testing.T) {
+	logger := zap.NewNop()
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54849", logger)
+	require.NoError(t, mockServer.Start(context.Background()))
+
+	sub, err := mockServer.SubscribeEvents(testdata.DefaultLogObjectID, 10)
+	require.NoError(t, err)
+
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	records := testdata.NewSeededGenerator(1, base).SteadyInfo(5, time.Second)
+	mockServer.DropNextEvents(2)
+	require.NoError(t, mockServer.PublishEvents(testdata.DefaultLogObjectID, records...))
+
+	var sequences []uint32
+	for range 3 {
+		n := <-sub.C()
+		sequences = append(sequences, n.SequenceNumber)
+	}
+	assert.Equal(t, []uint32{3, 4, 5}, sequences)
+
+	// Gap-fill: the dropped notifications are still available via GetRecords
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(context.Background()))
+	filled, _, err := mockClient.GetRecords(context.Background(), base, base.Add(time.Second), 100, nil)
+	require.NoError(t, err)
+	assert.Len(t, filled, 2)
+
+	// Scheduled publishing
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockServer.PublishEvery(ctx, testdata.DefaultLogObjectID, 10*time.Millisecond, func(tick int) []testdata.OPCUALogRecord {
+		return []testdata.OPCUALogRecord{testdata.GenerateSampleLogRecord(tick)}
+	})
+	for want := uint32(6); want <= 8; want++ {
+		select {
+		case n := <-sub.C():
+			assert.Equal(t, want, n.SequenceNumber)
+		case <-time.After(time.Second):
+			t.Fatal("no scheduled notification")
+		}
+	}
+	cancel()
+
+	require.NoError(t, mockServer.Stop(context.Background()))
+	for range sub.C() {
+	}
+	_, open := <-sub.C()
+	assert.False(t, open)
+}
+
+// TestMockServerEventQueueOverflow verifies that a full subscription queue drops notifications
+func TestMockServerEventQueueOverflow(t *testing.T) {
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54850", zap.NewNop())
+	sub, err := mockServer.SubscribeEvents(testdata.DefaultLogObjectID, 2)
+	require.NoError(t, err)
+	defer sub.Close()
+
+	require.NoError(t, mockServer.PublishEvents(testdata.DefaultLogObjectID, testdata.GenerateSampleLogRecords(4)...))
+	assert.Equal(t, uint32(1), (<-sub.C()).SequenceNumber)
+	assert.Equal(t, uint32(2), (<-sub.C()).SequenceNumber)
+
+	require.NoError(t, mockServer.PublishEvents(testdata.DefaultLogObjectID, testdata.GenerateSampleLogRecord(4)))
+	assert.Equal(t, uint32(5), (<-sub.C()).SequenceNumber)
+	assert.Equal(t, 5, mockServer.GetLogRecordsCount())
+
+	_, err = mockServer.SubscribeEvents(ua.NewNumericNodeID(1, 9999), 1)
+	require.Error(t, err)
+}
+
+// TestMockServerRingBuffer verifies that a bounded log buffer evicts the oldest
+// records silently, including records a paginated read has not reached yet
+func TestMockServerRingBuffer(t *testing.T) {
+	logger := zap.NewNop()
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54851", logger)
+	require.NoError(t, mockServer.Start(context.Background()))
+	defer func() {
+		require.NoError(t, mockServer.Stop(context.Background()))
+	}()
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	gen := testdata.NewSeededGenerator(1, base)
+
+	require.NoError(t, mockServer.SetLogBufferCapacity(testdata.DefaultLogObjectID, 5))
+	mockServer.AddLogRecords(gen.SteadyInfo(10, time.Second))
+	assert.Equal(t, 5, mockServer.GetLogRecordsCount())
+	assert.Equal(t, 5, mockServer.EvictedCount(testdata.DefaultLogObjectID))
+
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(context.Background()))
+	end := base.Add(time.Hour)
+
+	first, cp, err := mockClient.GetRecords(context.Background(), time.Time{}, end, 2, nil)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	assert.Equal(t, base.Add(5*time.Second), first[0].Timestamp)
+	require.NotEmpty(t, cp)
+
+	// Overflow while the read is paused: three more records evict 5, 6 and 7,
+	// so the read resumes at 8 and record 7 is lost
+	mockServer.AddLogRecords(testdata.NewSeededGenerator(2, base.Add(10*time.Second)).SteadyInfo(3, time.Second))
+	assert.Equal(t, 8, mockServer.EvictedCount(testdata.DefaultLogObjectID))
+
+	second, _, err := mockClient.GetRecords(context.Background(), time.Time{}, end, 2, cp)
+	require.NoError(t, err)
+	require.Len(t, second, 2)
+	assert.Equal(t, base.Add(8*time.Second), second[0].Timestamp)
+	assert.Equal(t, base.Add(9*time.Second), second[1].Timestamp)
+
+	require.NoError(t, mockServer.SetLogBufferCapacity(testdata.DefaultLogObjectID, 0))
+	require.Error(t, mockServer.SetLogBufferCapacity(testdata.DefaultLogObjectID, -1))
+}
+
+// TestScraperMockServerScenario drives the scraper through a scripted scenario
+func TestScraperMockServerScenario(t *testing.T) {
+	sc, err := testdata.LoadScenario(filepath.Join("testdata", "scenarios", "channel_drop_with_backlog.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "channel drop with backlog", sc.Name)
+
+	logger := zap.NewNop()
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54852", logger)
+	require.NoError(t, mockServer.Start(context.Background()))
+	defer func() {
+		require.NoError(t, mockServer.Stop(context.Background()))
+	}()
+	require.NoError(t, sc.Apply(mockServer))
+	assert.Equal(t, 12, mockServer.GetLogRecordsCount())
+
+	config := createDefaultConfig().(*Config)
+	config.Filter.MinSeverity = "Debug"
+	config.MaxRecordsPerCall = 5
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:      config,
+		settings:    settings,
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		transformer: newRecordTransformer(config.Endpoint, config.Resource.ServiceName, ""),
+		client: &mockClientAdapter{
+			mockClient: testdata.NewMockClient(mockServer, logger),
+			config:     config,
+		},
+	}
+	ctx := context.Background()
+	require.NoError(t, scr.client.Connect(ctx))
+
+	// Call 2 loses the secure channel mid-pagination
+	_, err = scr.scrape(ctx)
+	require.Error(t, err)
+
+	// Call 3 adds three errors before the retry reads the whole buffer
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 15, logs.LogRecordCount())
+	assert.Equal(t, 5, mockServer.CallCount())
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		scenario string
+	}{
+		{name: "unknown fault", scenario: "steps:\n  - call: 1\n    fault: Meltdown\n"},
+		{name: "unknown distribution", scenario: "records:\n  - count: 1\n    distribution: zipf\n"},
+		{name: "records without call", scenario: "steps:\n  - records:\n      - count: 1\n"},
+		{name: "invalid log object", scenario: "log_objects:\n  - id: not-a-node\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := testdata.ParseScenario([]byte(tt.scenario))
+			require.NoError(t, err)
+			assert.Error(t, sc.Apply(testdata.NewMockServer("", zap.NewNop())))
+		})
+	}
+}
+
+// TestScraperMockServerNetworkConditions verifies that simulated latency and
+// throughput limits slow down scrapes and trip the scrape deadline
+func TestScraperMockServerNetworkConditions(t *testing.T) {
+	records := testdata.GenerateSampleLogRecords(10)
+	bytes := 0
+	for _, r := range records {
+		bytes += testdata.EstimateRecordSize(r)
+	}
+
+	tests := []struct {
+		name       string
+		conditions testdata.NetworkConditions
+		timeout    time.Duration
+		minElapsed time.Duration
+		wantErr    bool
+	}{
+		{
+			name:       "latency per page",
+			conditions: testdata.NetworkConditions{Latency: 30 * time.Millisecond},
+			minElapsed: 90 * time.Millisecond,
+		},
+		{
+			name:       "throughput limit",
+			conditions: testdata.NetworkConditions{BytesPerSecond: bytes * 5},
+			minElapsed: 200 * time.Millisecond,
+		},
+		{
+			name:       "scrape timeout",
+			conditions: testdata.NetworkConditions{Latency: time.Second},
+			timeout:    50 * time.Millisecond,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zap.NewNop()
+			mockServer := testdata.NewMockServer("opc.tcp://localhost:54847", logger)
+			require.NoError(t, mockServer.Start(context.Background()))
+			defer func() {
+				require.NoError(t, mockServer.Stop(context.Background()))
+			}()
+			mockServer.AddLogRecords(records)
+			mockServer.SetNetworkConditions(tt.conditions)
+
+			config := createDefaultConfig().(*Config)
+			config.Filter.MinSeverity = "Debug"
+			config.MaxRecordsPerCall = 4
+			settings := componenttest.NewNopTelemetrySettings()
+			scr := &scraper{
+				config:      config,
+				settings:    settings,
+				telemetry:   newTestTelemetryBuilder(t, settings),
+				transformer: newRecordTransformer(config.Endpoint, config.Resource.ServiceName, ""),
+				client: &mockClientAdapter{
+					mockClient: testdata.NewMockClient(mockServer, logger),
+					config:     config,
+				},
+			}
+			require.NoError(t, scr.client.Connect(context.Background()))
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			logs, err := scr.scrape(ctx)
+			elapsed := time.Since(start)
+			if tt.wantErr {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				assert.Less(t, elapsed, tt.conditions.Latency)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, 10, logs.LogRecordCount())
+			assert.GreaterOrEqual(t, elapsed, tt.minElapsed)
+		})
+	}
+}
+
+// newTestTelemetryBuilder creates a TelemetryBuilder for scrapers built directly in tests
+func newTestTelemetryBuilder(tb testing.TB, settings component.TelemetrySettings) *metadata.TelemetryBuilder {
+	tb.Helper()
+	telemetry, err := metadata.NewTelemetryBuilder(settings)
+	require.NoError(tb, err)
+	tb.Cleanup(telemetry.Shutdown)
+	return telemetry
+}
+
 // mockClientAdapter adapts testdata.MockClient to OPCUAClient interface
 type mockClientAdapter struct {
 	mockClient *testdata.MockClient
@@ -326,6 +1345,10 @@ func (m *mockClientAdapter) GetRecords(
 			return nil, err
 		}
 
+		// Stamp the LogObject like the real client does for the default ServerLog node
+		for i := range records {
+			records[i].LogObjectID = "i=2042"
+		}
 		allRecords = append(allRecords, records...)
 
 		// If no continuation point, we're done
@@ -359,3 +1382,456 @@ func getMinSeverityValueFromConfig(minSeverity string) uint16 {
 		return 101 // Default to Info
 	}
 }
+
+// fakeClock is a clock that only moves when advanced
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// timeWindow is the time range of a GetRecords call
+type timeWindow struct {
+	start, end time.Time
+}
+
+// windowClient is an OPCUAClient that records the requested time windows
+// and fails GetRecords while err is set. The next unfinished calls leave
+// records to be resumed.
+type windowClient struct {
+	windows    []timeWindow
+	err        error
+	unfinished int
+	resuming   bool
+}
+
+func (c *windowClient) Connect(context.Context) error    { return nil }
+func (c *windowClient) Disconnect(context.Context) error { return nil }
+func (c *windowClient) IsConnected() bool                { return true }
+
+func (c *windowClient) GetRecords(_ context.Context, startTime, endTime time.Time, _ int) ([]testdata.OPCUALogRecord, error) {
+	c.windows = append(c.windows, timeWindow{start: startTime, end: endTime})
+	c.resuming = c.err == nil && c.unfinished > 0
+	if c.resuming {
+		c.unfinished--
+	}
+	return nil, c.err
+}
+
+func (c *windowClient) unfinishedQuery() bool {
+	return c.resuming
+}
+
+func TestScraperTimeWindow(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	client := &windowClient{}
+
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:      &Config{MaxRecordsPerCall: 100},
+		settings:    settings,
+		transformer: newRecordTransformer("opc.tcp://test:4840", "opcua-server", ""),
+		client:      client,
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		clock:       clk,
+	}
+
+	// The first scrape reads everything up to now
+	_, err := scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// Each scrape continues where the previous one ended
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A scrape at the same instant reads an empty window
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A failed scrape does not advance the window, the next one covers both intervals
+	client.err = errors.New("server unavailable")
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.Error(t, err)
+
+	client.err = nil
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []timeWindow{
+		{start: time.Time{}, end: t0},
+		{start: t0, end: t0.Add(30 * time.Second)},
+		{start: t0.Add(30 * time.Second), end: t0.Add(30 * time.Second)},
+		{start: t0.Add(30 * time.Second), end: t0.Add(60 * time.Second)},
+		{start: t0.Add(30 * time.Second), end: t0.Add(90 * time.Second)},
+	}, client.windows)
+	assert.Equal(t, t0.Add(90*time.Second), scr.lastCollectTime)
+}
+
+func TestScraperResumeWindow(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	client := &windowClient{}
+
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:      &Config{MaxRecordsPerCall: 100},
+		settings:    settings,
+		transformer: newRecordTransformer("opc.tcp://test:4840", "opcua-server", ""),
+		client:      client,
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		clock:       clk,
+	}
+
+	_, err := scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A window the record budget ran out in is queried again, not advanced
+	client.unfinished = 2
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// A failed resume keeps the window
+	client.err = errors.New("server unavailable")
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.Error(t, err)
+	client.err = nil
+
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	// Once the window is finished, the next one starts at its end
+	clk.Advance(30 * time.Second)
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, []timeWindow{
+		{start: time.Time{}, end: t0},
+		{start: t0, end: t0.Add(30 * time.Second)},
+		{start: t0, end: t0.Add(30 * time.Second)},
+		{start: t0, end: t0.Add(30 * time.Second)},
+		{start: t0, end: t0.Add(30 * time.Second)},
+		{start: t0.Add(30 * time.Second), end: t0.Add(150 * time.Second)},
+	}, client.windows)
+	assert.Equal(t, t0.Add(150*time.Second), scr.lastCollectTime)
+	assert.True(t, scr.resumeEnd.IsZero())
+}
+
+func TestScraperCatchUpWindows(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	client := &windowClient{}
+
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:          &Config{MaxRecordsPerCall: 100, MaxCatchupDuration: time.Hour},
+		settings:        settings,
+		transformer:     newRecordTransformer("opc.tcp://test:4840", "opcua-server", ""),
+		client:          client,
+		telemetry:       newTestTelemetryBuilder(t, settings),
+		clock:           clk,
+		lastCollectTime: t0.Add(-150 * time.Minute),
+	}
+
+	// A backlog of two and a half hours is read in windows of an hour
+	for i := 0; i < 3; i++ {
+		_, err := scr.scrape(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, i < 2, scr.catchingUp)
+		clk.Advance(30 * time.Second)
+	}
+
+	// A failed window is read again with the same bound
+	client.err = errors.New("server unavailable")
+	scr.lastCollectTime = clk.Now().Add(-2 * time.Hour)
+	_, err := scr.scrape(ctx)
+	require.Error(t, err)
+	client.err = nil
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+
+	t1 := t0.Add(90 * time.Second)
+	assert.Equal(t, []timeWindow{
+		{start: t0.Add(-150 * time.Minute), end: t0.Add(-90 * time.Minute)},
+		{start: t0.Add(-90 * time.Minute), end: t0.Add(-30 * time.Minute)},
+		{start: t0.Add(-30 * time.Minute), end: t0.Add(time.Minute)},
+		{start: t1.Add(-2 * time.Hour), end: t1.Add(-time.Hour)},
+		{start: t1.Add(-2 * time.Hour), end: t1.Add(-time.Hour)},
+	}, client.windows)
+}
+
+// overflowClient is a windowClient that returns records records whatever
+// the budget
+type overflowClient struct {
+	windowClient
+	records int
+}
+
+func (c *overflowClient) GetRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int) ([]testdata.OPCUALogRecord, error) {
+	_, _ = c.windowClient.GetRecords(ctx, startTime, endTime, maxRecords)
+	records := make([]testdata.OPCUALogRecord, c.records)
+	for i := range records {
+		records[i] = testdata.OPCUALogRecord{Timestamp: startTime, Severity: 100, Message: "overflow"}
+	}
+	return records, nil
+}
+
+func TestScraperMaxLogRecordsTruncated(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+
+	client := &overflowClient{records: 8}
+	settings := tel.NewTelemetrySettings()
+	scr := &scraper{
+		config:      &Config{Endpoint: "opc.tcp://test:4840", MaxRecordsPerCall: 100, Filter: FilterConfig{MaxLogRecords: 5}},
+		settings:    settings,
+		transformer: newRecordTransformer("opc.tcp://test:4840", "opcua-server", ""),
+		client:      client,
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		clock:       newFakeClock(time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)),
+	}
+
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, logs.LogRecordCount())
+
+	// Within the cap nothing is dropped
+	client.records = 5
+	logs, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 5, logs.LogRecordCount())
+
+	metadatatest.AssertEqualOpcuaRecordsTruncated(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 3, Attributes: attribute.NewSet(attribute.String("opcua.endpoint", "opc.tcp://test:4840"))}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+func TestScraperConfigClient(t *testing.T) {
+	ctx := context.Background()
+	client := &windowClient{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Client = client
+
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	// The supplied client is used instead of connecting to the endpoint
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	assert.Same(t, client, scr.client)
+
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Len(t, client.windows, 1)
+
+	client.err = errors.New("simulated failure")
+	_, err = scr.scrape(ctx)
+	require.ErrorContains(t, err, "simulated failure")
+
+	require.NoError(t, scr.shutdown(ctx))
+}
+
+func TestScraperTimeWindowRecords(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54853", logger)
+	require.NoError(t, mockServer.Start(ctx))
+	defer func() {
+		assert.NoError(t, mockServer.Stop(ctx))
+	}()
+	record := func(ts time.Time, message string) testdata.OPCUALogRecord {
+		return testdata.OPCUALogRecord{Timestamp: ts, Severity: 150, Message: message, Attributes: map[string]interface{}{}}
+	}
+
+	config := &Config{MaxRecordsPerCall: 100, Filter: FilterConfig{MinSeverity: "Info"}}
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(ctx))
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:      config,
+		settings:    settings,
+		transformer: newRecordTransformer(mockServer.Endpoint(), "opcua-server", ""),
+		client:      &mockClientAdapter{mockClient: mockClient, config: config},
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		clock:       clk,
+	}
+	scrapeMessages := func() []string {
+		t.Helper()
+		logs, err := scr.scrape(ctx)
+		require.NoError(t, err)
+		var messages []string
+		if logs.ResourceLogs().Len() == 0 {
+			return messages
+		}
+		lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < lrs.Len(); i++ {
+			messages = append(messages, lrs.At(i).Body().Str())
+		}
+		return messages
+	}
+
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{record(t0.Add(-time.Hour), "backlog")})
+	assert.Equal(t, []string{"backlog"}, scrapeMessages())
+
+	// A record written ahead of the collector clock is not read before the
+	// clock has passed its timestamp, and then exactly once
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{
+		record(t0.Add(10*time.Second), "current"),
+		record(t0.Add(45*time.Second), "future"),
+	})
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, []string{"current"}, scrapeMessages())
+
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, []string{"future"}, scrapeMessages())
+
+	clk.Advance(30 * time.Second)
+	assert.Empty(t, scrapeMessages())
+}
+
+// skewMockClient is a mockClientAdapter whose server clock runs skew ahead of
+// clk.
+type skewMockClient struct {
+	*mockClientAdapter
+	clk  *fakeClock
+	skew time.Duration
+}
+
+func (c *skewMockClient) serverTime(context.Context) (time.Time, error) {
+	return c.clk.Now().Add(c.skew), nil
+}
+
+// newRecordsScraper returns a scraper reading from a mock server with clk and
+// a function scraping the messages of the next window.
+func newRecordsScraper(t *testing.T, config *Config, clk *fakeClock, skew time.Duration) (*testdata.MockServer, *scraper, func() []string) {
+	t.Helper()
+	ctx := context.Background()
+	logger := zap.NewNop()
+
+	mockServer := testdata.NewMockServer("opc.tcp://localhost:54853", logger)
+	require.NoError(t, mockServer.Start(ctx))
+	t.Cleanup(func() {
+		assert.NoError(t, mockServer.Stop(ctx))
+	})
+
+	config.MaxRecordsPerCall = 100
+	config.Filter = FilterConfig{MinSeverity: "Info"}
+	mockClient := testdata.NewMockClient(mockServer, logger)
+	require.NoError(t, mockClient.Connect(ctx))
+	settings := componenttest.NewNopTelemetrySettings()
+	scr := &scraper{
+		config:      config,
+		settings:    settings,
+		transformer: newRecordTransformer(mockServer.Endpoint(), "opcua-server", ""),
+		client:      &skewMockClient{mockClientAdapter: &mockClientAdapter{mockClient: mockClient, config: config}, clk: clk, skew: skew},
+		telemetry:   newTestTelemetryBuilder(t, settings),
+		clock:       clk,
+	}
+	scrapeMessages := func() []string {
+		t.Helper()
+		logs, err := scr.scrape(ctx)
+		require.NoError(t, err)
+		var messages []string
+		if logs.ResourceLogs().Len() == 0 {
+			return messages
+		}
+		lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < lrs.Len(); i++ {
+			messages = append(messages, lrs.At(i).Body().Str())
+		}
+		return messages
+	}
+	return mockServer, scr, scrapeMessages
+}
+
+func windowRecord(ts time.Time, message string) testdata.OPCUALogRecord {
+	return testdata.OPCUALogRecord{Timestamp: ts, Severity: 150, Message: message, Attributes: map[string]interface{}{}}
+}
+
+// TestScraperCatchUpRecords checks the records read while catching up: each
+// window spans at most max_catchup_duration and every record of the backlog
+// is read exactly once. TestScraperCatchUpWindows covers the windows alone.
+func TestScraperCatchUpRecords(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	mockServer, scr, scrapeMessages := newRecordsScraper(t, &Config{MaxCatchupDuration: time.Hour}, clk, 0)
+	scr.lastCollectTime = t0.Add(-150 * time.Minute)
+
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{
+		windowRecord(t0.Add(-140*time.Minute), "a"),
+		windowRecord(t0.Add(-100*time.Minute), "b"),
+		windowRecord(t0.Add(-50*time.Minute), "c"),
+		windowRecord(t0.Add(-10*time.Minute), "d"),
+	})
+
+	// [-150m, -90m]
+	assert.Equal(t, []string{"a", "b"}, scrapeMessages())
+	// [-90m, -30m]
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, []string{"c"}, scrapeMessages())
+	// [-30m, now], caught up
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, []string{"d"}, scrapeMessages())
+	assert.Equal(t, t0.Add(time.Minute), scr.lastCollectTime)
+
+	clk.Advance(30 * time.Second)
+	assert.Empty(t, scrapeMessages())
+}
+
+// TestScraperClockSkewRecords checks the records read with correct_clock_skew
+// from a server clock running ahead: records stamped ahead of the collector
+// clock are read by the first window ending after them, and exactly once.
+// TestScraperClockSkew covers the windows and the skew gauge.
+func TestScraperClockSkewRecords(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(t0)
+	mockServer, _, scrapeMessages := newRecordsScraper(t, &Config{CorrectClockSkew: true}, clk, 90*time.Second)
+
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{
+		windowRecord(t0.Add(10*time.Second), "current"),
+		windowRecord(t0.Add(60*time.Second), "ahead"),
+	})
+	// [zero, t0+90s]
+	assert.Equal(t, []string{"current", "ahead"}, scrapeMessages())
+
+	mockServer.AddLogRecords([]testdata.OPCUALogRecord{windowRecord(t0.Add(100*time.Second), "late")})
+	// [t0+90s, t0+120s]
+	clk.Advance(30 * time.Second)
+	assert.Equal(t, []string{"late"}, scrapeMessages())
+
+	clk.Advance(30 * time.Second)
+	assert.Empty(t, scrapeMessages())
+}
@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// selfSignedCertificate creates a self-signed application instance
+// certificate for key. It carries the application URI, as Part 6, 6.2.2
+// requires, and hosts as DNS names or IP addresses.
+func selfSignedCertificate(key *rsa.PrivateKey, commonName, applicationURI string, hosts []string, notBefore, notAfter time.Time) ([]byte, error) {
+	uri, err := url.Parse(applicationURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid application_uri: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment |
+			x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		URIs:                  []*url.URL{uri},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create self-signed certificate: %w", err)
+	}
+	return cert, nil
+}
+
+// localHosts returns the host name of the machine, or nothing if it is unknown
+func localHosts() []string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil
+	}
+	return []string{hostname}
+}
+
+// defaultApplicationURI returns the application URI of generated
+// certificates without generate_certificate.application_uri
+func defaultApplicationURI() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "localhost"
+	}
+	return "urn:" + hostname + ":otelcol-opcua"
+}
+
+// ensureGeneratedCertificate creates the self-signed application certificate
+// of generate_certificate at cert_file and key_file, unless a certificate
+// within its validity period is stored there. commonName names the
+// certificate's subject.
+func ensureGeneratedCertificate(cfg *TLSConfig, commonName string, now time.Time, logger *zap.Logger) error {
+	gen := cfg.GenerateCertificate
+	if !gen.Enabled {
+		return nil
+	}
+
+	stored, err := storedCertificate(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+	if stored != nil {
+		if now.Before(stored.NotAfter) {
+			return nil
+		}
+		logger.Warn("Generated application certificate has expired, creating a new one; servers must trust it again",
+			zap.String("cert_file", cfg.CertFile),
+			zap.Time("not_after", stored.NotAfter))
+	}
+
+	applicationURI := gen.ApplicationURI
+	if applicationURI == "" {
+		applicationURI = defaultApplicationURI()
+	}
+	hosts := gen.Hosts
+	if len(hosts) == 0 {
+		hosts = localHosts()
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, gen.KeySize)
+	if err != nil {
+		return fmt.Errorf("failed to generate key: %w", err)
+	}
+	cert, err := selfSignedCertificate(key, commonName, applicationURI, hosts, now.Add(-time.Hour), now.Add(gen.Validity))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{cfg.CertFile, cfg.KeyFile} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return fmt.Errorf("failed to create directory of %s: %w", path, err)
+		}
+	}
+	if err := writeKeyFile(cfg.KeyFile, key); err != nil {
+		return err
+	}
+	if isPEMFile(cfg.CertFile) {
+		cert = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert})
+	}
+	if err := writeFileAtomic(cfg.CertFile, cert); err != nil {
+		return err
+	}
+
+	logger.Info("Generated self-signed application certificate",
+		zap.String("cert_file", cfg.CertFile),
+		zap.String("application_uri", applicationURI),
+		zap.Strings("hosts", hosts),
+		zap.Duration("validity", gen.Validity))
+	return nil
+}
+
+// storedCertificate loads the certificate of cert_file if it and key_file
+// exist
+func storedCertificate(certFile, keyFile string) (*x509.Certificate, error) {
+	for _, path := range []string{certFile, keyFile} {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+	}
+	der, _, err := loadCertificateFiles(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return x509.ParseCertificate(der)
+}
+
+// isPEMFile reports whether a certificate file is PEM encoded by its extension
+func isPEMFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pem", ".crt":
+		return true
+	}
+	return false
+}
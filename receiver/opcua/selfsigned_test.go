@@ -0,0 +1,165 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+func TestGenerateCertificateConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "disabled",
+			modify: func(cfg *Config) { cfg.TLS.GenerateCertificate.Enabled = false },
+		},
+		{
+			name:   "enabled",
+			modify: func(*Config) {},
+		},
+		{
+			name: "all settings",
+			modify: func(cfg *Config) {
+				cfg.TLS.GenerateCertificate.ApplicationURI = "urn:collector-1:otelcol-opcua"
+				cfg.TLS.GenerateCertificate.Hosts = []string{"collector-1.plant.local", "10.0.0.5"}
+				cfg.TLS.GenerateCertificate.KeySize = 4096
+				cfg.TLS.GenerateCertificate.Validity = 5 * 365 * 24 * time.Hour
+			},
+		},
+		{
+			name:    "no cert file",
+			modify:  func(cfg *Config) { cfg.TLS.CertFile = "" },
+			wantErr: "tls cert_file and key_file are required to store the generated certificate",
+		},
+		{
+			name:    "relative application URI",
+			modify:  func(cfg *Config) { cfg.TLS.GenerateCertificate.ApplicationURI = "otelcol-opcua" },
+			wantErr: "application_uri must be an absolute URI",
+		},
+		{
+			name:    "key size",
+			modify:  func(cfg *Config) { cfg.TLS.GenerateCertificate.KeySize = 1024 },
+			wantErr: "invalid key_size: 1024",
+		},
+		{
+			name:    "short validity",
+			modify:  func(cfg *Config) { cfg.TLS.GenerateCertificate.Validity = time.Hour },
+			wantErr: "validity must be at least 24h",
+		},
+		{
+			name: "certificate provider",
+			modify: func(cfg *Config) {
+				id := component.MustNewID("opcua_pki")
+				cfg.TLS.CertificateProvider = &id
+			},
+			wantErr: "tls generate_certificate and certificate_provider are mutually exclusive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.TLS.GenerateCertificate.Enabled = true
+			cfg.TLS.CertFile = "pki/own/certs/client.der"
+			cfg.TLS.KeyFile = "pki/own/private/client.pem"
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestEnsureGeneratedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config).TLS
+	cfg.CertFile = filepath.Join(dir, "own", "certs", "client.der")
+	cfg.KeyFile = filepath.Join(dir, "own", "private", "client.pem")
+	cfg.GenerateCertificate.Enabled = true
+	cfg.GenerateCertificate.ApplicationURI = "urn:collector-1:otelcol-opcua"
+	cfg.GenerateCertificate.Hosts = []string{"collector-1.plant.local", "10.0.0.5"}
+	now := time.Now()
+
+	require.NoError(t, ensureGeneratedCertificate(&cfg, "otelcol-opcua", now, zap.NewNop()))
+	der, key, err := cfg.applicationCertificate()
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	assert.Equal(t, "otelcol-opcua", cert.Subject.CommonName)
+	assert.Equal(t, cert.Subject.String(), cert.Issuer.String())
+	require.Len(t, cert.URIs, 1)
+	assert.Equal(t, "urn:collector-1:otelcol-opcua", cert.URIs[0].String())
+	assert.Equal(t, []string{"collector-1.plant.local"}, cert.DNSNames)
+	require.Len(t, cert.IPAddresses, 1)
+	assert.True(t, net.ParseIP("10.0.0.5").Equal(cert.IPAddresses[0]))
+	assert.Equal(t, 2048, key.N.BitLen())
+	assert.WithinDuration(t, now.Add(365*24*time.Hour), cert.NotAfter, time.Minute)
+	for _, path := range []string{cfg.CertFile, cfg.KeyFile} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), path)
+	}
+
+	// A restart keeps the stored certificate
+	require.NoError(t, ensureGeneratedCertificate(&cfg, "otelcol-opcua", now.Add(time.Hour), zap.NewNop()))
+	stored, _, err := cfg.applicationCertificate()
+	require.NoError(t, err)
+	assert.Equal(t, der, stored)
+
+	// An expired certificate is replaced
+	require.NoError(t, ensureGeneratedCertificate(&cfg, "otelcol-opcua", now.Add(366*24*time.Hour), zap.NewNop()))
+	renewed, renewedKey, err := cfg.applicationCertificate()
+	require.NoError(t, err)
+	assert.NotEqual(t, der, renewed)
+	assert.False(t, key.Equal(renewedKey))
+}
+
+func TestEnsureGeneratedCertificatePEM(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*Config).TLS
+	cfg.CertFile = filepath.Join(dir, "client.pem")
+	cfg.KeyFile = filepath.Join(dir, "client_key.pem")
+	cfg.GenerateCertificate.Enabled = true
+
+	require.NoError(t, ensureGeneratedCertificate(&cfg, "otelcol-opcua", time.Now(), zap.NewNop()))
+	data, err := os.ReadFile(cfg.CertFile)
+	require.NoError(t, err)
+	block, _ := pem.Decode(data)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	// Without settings, the certificate is issued for the host
+	require.Len(t, cert.URIs, 1)
+	assert.Equal(t, defaultApplicationURI(), cert.URIs[0].String())
+	assert.Equal(t, localHosts(), cert.DNSNames)
+}
+
+func TestEnsureGeneratedCertificateDisabled(t *testing.T) {
+	cfg := createDefaultConfig().(*Config).TLS
+	cfg.CertFile = filepath.Join(t.TempDir(), "client.der")
+	cfg.KeyFile = filepath.Join(t.TempDir(), "client.pem")
+
+	require.NoError(t, ensureGeneratedCertificate(&cfg, "otelcol-opcua", time.Now(), zap.NewNop()))
+	assert.NoFileExists(t, cfg.CertFile)
+}
@@ -0,0 +1,143 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+)
+
+// serverInfo describes the server the session is established with. It is
+// read on every connect, so a server that was updated or replaced by its
+// redundant partner is described correctly after the reconnect.
+type serverInfo struct {
+	// From Server/ServerStatus/BuildInfo
+	productName      string
+	manufacturerName string
+	productURI       string
+	softwareVersion  string
+	buildNumber      string
+
+	// From the ApplicationDescription of the endpoint
+	applicationURI  string
+	applicationName string
+}
+
+// serverInfoProvider is implemented by clients that describe the server
+// they are connected to
+type serverInfoProvider interface {
+	// serverInfo returns the description read on the last connect, ok is
+	// false before the first one
+	serverInfo() (serverInfo, bool)
+}
+
+// buildInfoNodes are the BuildInfo variables read on connect, in the order
+// readServerInfo assigns their values
+var buildInfoNodes = []uint32{
+	id.Server_ServerStatus_BuildInfo_ProductName,
+	id.Server_ServerStatus_BuildInfo_ManufacturerName,
+	id.Server_ServerStatus_BuildInfo_ProductUri,
+	id.Server_ServerStatus_BuildInfo_SoftwareVersion,
+	id.Server_ServerStatus_BuildInfo_BuildNumber,
+}
+
+// readServerInfo describes the server of client, connected through ep. A
+// failed BuildInfo read leaves its fields empty, as servers are not required
+// to expose it to every user.
+func readServerInfo(ctx context.Context, client *opcua.Client, ep *ua.EndpointDescription, timeout time.Duration, logger *zap.Logger) serverInfo {
+	var info serverInfo
+	if ep != nil && ep.Server != nil {
+		info.applicationURI = ep.Server.ApplicationURI
+		if ep.Server.ApplicationName != nil {
+			info.applicationName = ep.Server.ApplicationName.Text
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	values, err := readBuildInfo(ctx, client)
+	if err != nil {
+		logger.Debug("Failed to read server BuildInfo", zap.Error(err))
+		return info
+	}
+	info.productName = values[0]
+	info.manufacturerName = values[1]
+	info.productURI = values[2]
+	info.softwareVersion = values[3]
+	info.buildNumber = values[4]
+	return info
+}
+
+// readBuildInfo reads the buildInfoNodes as strings; values that cannot be
+// read are empty
+func readBuildInfo(ctx context.Context, client *opcua.Client) ([]string, error) {
+	req := &ua.ReadRequest{TimestampsToReturn: ua.TimestampsToReturnNeither}
+	for _, node := range buildInfoNodes {
+		req.NodesToRead = append(req.NodesToRead, &ua.ReadValueID{
+			NodeID:      ua.NewNumericNodeID(0, node),
+			AttributeID: ua.AttributeIDValue,
+		})
+	}
+
+	resp, err := client.Read(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Results) != len(buildInfoNodes) {
+		return nil, fmt.Errorf("expected %d results, got %d", len(buildInfoNodes), len(resp.Results))
+	}
+
+	values := make([]string, len(buildInfoNodes))
+	for i, result := range resp.Results {
+		if result.Status != ua.StatusOK || result.Value == nil {
+			continue
+		}
+		if s, ok := result.Value.Value().(string); ok {
+			values[i] = s
+		}
+	}
+	return values, nil
+}
+
+// serverInfo returns the description of the server read on the last connect
+func (c *opcuaClient) serverInfo() (serverInfo, bool) {
+	info := c.info.Load()
+	if info == nil {
+		return serverInfo{}, false
+	}
+	return *info, true
+}
+
+// setResourceAttributes sets the resource attributes of the fields that are known
+func (info serverInfo) setResourceAttributes(rb *metadata.ResourceBuilder) {
+	if info.productName != "" {
+		rb.SetOpcuaServerProductName(info.productName)
+	}
+	if info.manufacturerName != "" {
+		rb.SetOpcuaServerManufacturerName(info.manufacturerName)
+	}
+	if info.productURI != "" {
+		rb.SetOpcuaServerProductURI(info.productURI)
+	}
+	if info.softwareVersion != "" {
+		rb.SetServiceVersion(info.softwareVersion)
+	}
+	if info.buildNumber != "" {
+		rb.SetOpcuaServerBuildNumber(info.buildNumber)
+	}
+	if info.applicationURI != "" {
+		rb.SetOpcuaServerApplicationURI(info.applicationURI)
+	}
+	if info.applicationName != "" {
+		rb.SetOpcuaServerApplicationName(info.applicationName)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestTransformLogsServerInfo(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ResourceAttributes.OpcuaServerBuildNumber.Enabled = true
+	transformer := newTransformerFromConfig(cfg)
+	records := []testdata.OPCUALogRecord{{Timestamp: time.Now(), Severity: 100, Message: "started"}}
+
+	// Before the first connect the server is not described
+	attrs := transformer.TransformLogs(records).ResourceLogs().At(0).Resource().Attributes()
+	_, ok := attrs.Get("opcua.server.product_name")
+	assert.False(t, ok)
+	_, ok = attrs.Get("service.version")
+	assert.False(t, ok)
+
+	transformer.serverInfo = func() (serverInfo, bool) {
+		return serverInfo{
+			productName:      "Press Controller",
+			manufacturerName: "ACME",
+			softwareVersion:  "4.2.1",
+			buildNumber:      "1187",
+			applicationURI:   "urn:plant:line-1",
+			applicationName:  "Line 1",
+		}, true
+	}
+
+	attrs = transformer.TransformLogs(records).ResourceLogs().At(0).Resource().Attributes()
+	expected := map[string]string{
+		"opcua.server.product_name":      "Press Controller",
+		"opcua.server.manufacturer_name": "ACME",
+		"service.version":                "4.2.1",
+		"opcua.server.build_number":      "1187",
+		"opcua.server.application_uri":   "urn:plant:line-1",
+	}
+	for name, value := range expected {
+		got, ok := attrs.Get(name)
+		require.True(t, ok, name)
+		assert.Equal(t, value, got.Str(), name)
+	}
+
+	// Disabled by default, and empty fields are left out
+	_, ok = attrs.Get("opcua.server.application_name")
+	assert.False(t, ok)
+	_, ok = attrs.Get("opcua.server.product_uri")
+	assert.False(t, ok)
+}
+
+func TestClientWireServerInfo(t *testing.T) {
+	ws := startWireServer(t)
+	buildInfo := map[uint32]string{
+		id.Server_ServerStatus_BuildInfo_ProductName:      "Wire Test Server",
+		id.Server_ServerStatus_BuildInfo_ManufacturerName: "opcua-receiver",
+		id.Server_ServerStatus_BuildInfo_SoftwareVersion:  "1.0.3",
+	}
+	for node, value := range buildInfo {
+		ws.SetVariable(ua.NewNumericNodeID(0, node), "", &ua.DataValue{
+			EncodingMask: ua.DataValueValue,
+			Value:        ua.MustVariant(value),
+		})
+	}
+
+	c := newOPCUAClient(ws.newWireConfig(), zap.NewNop())
+	_, ok := c.serverInfo()
+	assert.False(t, ok)
+
+	require.NoError(t, c.Connect(context.Background()))
+	defer func() { _ = c.Disconnect(context.Background()) }()
+
+	info, ok := c.serverInfo()
+	require.True(t, ok)
+	assert.Equal(t, "Wire Test Server", info.productName)
+	assert.Equal(t, "opcua-receiver", info.manufacturerName)
+	assert.Equal(t, "1.0.3", info.softwareVersion)
+	// Not exposed by the server
+	assert.Empty(t, info.productURI)
+	assert.Empty(t, info.buildNumber)
+}
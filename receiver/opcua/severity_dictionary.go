@@ -0,0 +1,205 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// severityEntry is the severity text and number a server-specific severity
+// value maps to. An empty text or unspecified number falls back to the
+// Part 26 ranges.
+type severityEntry struct {
+	text   string
+	number plog.SeverityNumber
+}
+
+// severityDictionary maps the severity values of a server with a proprietary
+// severity scale
+type severityDictionary map[uint16]severityEntry
+
+// severityNumbersByText are the SeverityNumbers of the severity names
+// servers commonly use, matched case-insensitively
+var severityNumbersByText = map[string]plog.SeverityNumber{
+	"trace":         plog.SeverityNumberTrace,
+	"verbose":       plog.SeverityNumberTrace,
+	"debug":         plog.SeverityNumberDebug,
+	"info":          plog.SeverityNumberInfo,
+	"information":   plog.SeverityNumberInfo,
+	"informational": plog.SeverityNumberInfo,
+	"notice":        plog.SeverityNumberInfo4,
+	"warn":          plog.SeverityNumberWarn,
+	"warning":       plog.SeverityNumberWarn,
+	"err":           plog.SeverityNumberError,
+	"error":         plog.SeverityNumberError,
+	"crit":          plog.SeverityNumberError2,
+	"critical":      plog.SeverityNumberError2,
+	"alert":         plog.SeverityNumberError3,
+	"emerg":         plog.SeverityNumberFatal,
+	"emergency":     plog.SeverityNumberFatal,
+	"fatal":         plog.SeverityNumberFatal,
+}
+
+// severityNumberForText returns the SeverityNumber of a well-known severity
+// name, or SeverityNumberUnspecified
+func severityNumberForText(text string) plog.SeverityNumber {
+	return severityNumbersByText[strings.ToLower(strings.TrimSpace(text))]
+}
+
+// newSeverityDictionary creates a dictionary from configured entries
+func newSeverityDictionary(entries []SeverityDictionaryEntry) severityDictionary {
+	if len(entries) == 0 {
+		return nil
+	}
+	dict := make(severityDictionary, len(entries))
+	for _, e := range entries {
+		number := plog.SeverityNumber(e.Number) //nolint:gosec
+		if number == plog.SeverityNumberUnspecified {
+			number = severityNumberForText(e.Text)
+		}
+		dict[e.Severity] = severityEntry{text: e.Text, number: number}
+	}
+	return dict
+}
+
+// merge returns a dictionary with the entries of d, replaced by the entries
+// of override for the same severity values
+func (d severityDictionary) merge(override severityDictionary) severityDictionary {
+	if len(d) == 0 {
+		return override
+	}
+	merged := make(severityDictionary, len(d)+len(override))
+	for severity, entry := range d {
+		merged[severity] = entry
+	}
+	for severity, entry := range override {
+		merged[severity] = entry
+	}
+	return merged
+}
+
+// parseSeverityDictionary converts the value of a server's dictionary node.
+// It accepts the EnumValues (EnumValueType array) and EnumStrings
+// (LocalizedText array, indexed by value) forms the server uses to describe
+// an enumeration. The number of each entry is derived from its name.
+func parseSeverityDictionary(value interface{}) (severityDictionary, error) {
+	dict := make(severityDictionary)
+	add := func(severity int64, text string) {
+		if severity < 0 || severity > math.MaxUint16 || text == "" {
+			return
+		}
+		dict[uint16(severity)] = severityEntry{text: text, number: severityNumberForText(text)}
+	}
+
+	switch v := value.(type) {
+	case []*ua.ExtensionObject:
+		for _, obj := range v {
+			if obj == nil {
+				continue
+			}
+			enumValue, ok := obj.Value.(*ua.EnumValueType)
+			if !ok {
+				return nil, fmt.Errorf("unexpected dictionary entry type %T", obj.Value)
+			}
+			if enumValue.DisplayName != nil {
+				add(enumValue.Value, enumValue.DisplayName.Text)
+			}
+		}
+	case []*ua.LocalizedText:
+		for i, text := range v {
+			if text != nil {
+				add(int64(i), text.Text)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected dictionary value type %T", value)
+	}
+
+	if len(dict) == 0 {
+		return nil, fmt.Errorf("dictionary has no entries")
+	}
+	return dict, nil
+}
+
+// severityDictionaryReader is implemented by clients that can read a
+// severity dictionary from the server
+type severityDictionaryReader interface {
+	readSeverityDictionary(ctx context.Context, nodeID string) (severityDictionary, error)
+}
+
+// readSeverityDictionary reads the severity dictionary from the variable nodeID
+func (c *opcuaClient) readSeverityDictionary(ctx context.Context, nodeID string) (severityDictionary, error) {
+	id, err := ua.ParseNodeID(nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity dictionary node %s: %w", nodeID, err)
+	}
+
+	session, err := c.session()
+	if err != nil {
+		return nil, err
+	}
+
+	req := &ua.ReadRequest{
+		TimestampsToReturn: ua.TimestampsToReturnNeither,
+		NodesToRead: []*ua.ReadValueID{
+			{NodeID: id, AttributeID: ua.AttributeIDValue},
+		},
+	}
+	resp, err := session.Read(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read severity dictionary node %s: %w", nodeID, err)
+	}
+	if len(resp.Results) == 0 {
+		return nil, fmt.Errorf("failed to read severity dictionary node %s: no results returned", nodeID)
+	}
+	result := resp.Results[0]
+	if result.Status != ua.StatusOK {
+		return nil, fmt.Errorf("failed to read severity dictionary node %s: %w", nodeID, result.Status)
+	}
+	if result.Value == nil {
+		return nil, fmt.Errorf("severity dictionary node %s has no value", nodeID)
+	}
+
+	dict, err := parseSeverityDictionary(result.Value.Value())
+	if err != nil {
+		return nil, fmt.Errorf("invalid severity dictionary node %s: %w", nodeID, err)
+	}
+	return dict, nil
+}
+
+// loadSeverityDictionary reads the severity dictionary of the server, if
+// configured, and hands it to the transformer together with the configured
+// entries, which take precedence. If the dictionary cannot be read, the
+// configured entries are used alone.
+func (s *scraper) loadSeverityDictionary(ctx context.Context) {
+	cfg := s.config.SeverityDictionary
+	if cfg.NodeID == "" {
+		return
+	}
+
+	reader, ok := s.client.(severityDictionaryReader)
+	if !ok {
+		s.settings.Logger.Warn("OPC UA client does not support reading the severity dictionary",
+			zap.String("node_id", cfg.NodeID))
+		return
+	}
+	server, err := reader.readSeverityDictionary(ctx, cfg.NodeID)
+	if err != nil {
+		s.settings.Logger.Warn("Failed to read the severity dictionary from the server, using the configured entries",
+			zap.String("node_id", cfg.NodeID),
+			zap.Error(err))
+		return
+	}
+	s.transformer.severities = server.merge(newSeverityDictionary(cfg.Entries))
+	s.settings.Logger.Info("Read severity dictionary from the server",
+		zap.String("node_id", cfg.NodeID),
+		zap.Int("entries", len(server)))
+}
@@ -0,0 +1,140 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"testing"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestParseSeverityDictionary(t *testing.T) {
+	enumValue := func(value int64, text string) *ua.ExtensionObject {
+		return ua.NewExtensionObject(&ua.EnumValueType{
+			Value:       value,
+			DisplayName: ua.NewLocalizedText(text),
+		})
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected severityDictionary
+		wantErr  string
+	}{
+		{
+			name:  "enum values",
+			value: []*ua.ExtensionObject{enumValue(10, "Info"), enumValue(20, "Warnung"), enumValue(30, "Fatal"), nil},
+			expected: severityDictionary{
+				10: {text: "Info", number: plog.SeverityNumberInfo},
+				20: {text: "Warnung"},
+				30: {text: "Fatal", number: plog.SeverityNumberFatal},
+			},
+		},
+		{
+			name:  "enum strings",
+			value: []*ua.LocalizedText{ua.NewLocalizedText("Debug"), nil, ua.NewLocalizedText(""), ua.NewLocalizedText("Error")},
+			expected: severityDictionary{
+				0: {text: "Debug", number: plog.SeverityNumberDebug},
+				3: {text: "Error", number: plog.SeverityNumberError},
+			},
+		},
+		{
+			name:    "out of range values",
+			value:   []*ua.ExtensionObject{enumValue(-1, "Info"), enumValue(70000, "Error")},
+			wantErr: "dictionary has no entries",
+		},
+		{
+			name:    "unexpected entry type",
+			value:   []*ua.ExtensionObject{ua.NewExtensionObject(&ua.Range{Low: 1, High: 2})},
+			wantErr: "unexpected dictionary entry type",
+		},
+		{
+			name:    "unexpected value type",
+			value:   []string{"Info"},
+			wantErr: "unexpected dictionary value type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dict, err := parseSeverityDictionary(tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, dict)
+		})
+	}
+}
+
+func TestNewSeverityDictionary(t *testing.T) {
+	assert.Nil(t, newSeverityDictionary(nil))
+
+	dict := newSeverityDictionary([]SeverityDictionaryEntry{
+		{Severity: 1, Text: "warning"},
+		{Severity: 2, Text: "Störung", Number: 17},
+		{Severity: 3, Number: 21},
+		{Severity: 4, Text: "Hinweis"},
+	})
+	assert.Equal(t, severityDictionary{
+		1: {text: "warning", number: plog.SeverityNumberWarn},
+		2: {text: "Störung", number: plog.SeverityNumberError},
+		3: {number: plog.SeverityNumberFatal},
+		4: {text: "Hinweis"},
+	}, dict)
+}
+
+func TestSeverityDictionaryMerge(t *testing.T) {
+	server := severityDictionary{
+		1: {text: "Info", number: plog.SeverityNumberInfo},
+		2: {text: "Warning", number: plog.SeverityNumberWarn},
+	}
+	configured := severityDictionary{
+		2: {text: "Alarm", number: plog.SeverityNumberError},
+		3: {text: "Fatal", number: plog.SeverityNumberFatal},
+	}
+
+	assert.Equal(t, severityDictionary{
+		1: {text: "Info", number: plog.SeverityNumberInfo},
+		2: {text: "Alarm", number: plog.SeverityNumberError},
+		3: {text: "Fatal", number: plog.SeverityNumberFatal},
+	}, server.merge(configured))
+	assert.Equal(t, configured, severityDictionary(nil).merge(configured))
+	assert.Equal(t, server, server.merge(nil))
+
+	// The inputs are not modified
+	assert.Len(t, server, 2)
+}
+
+func TestTransformerSeverityDictionary(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer.severities = severityDictionary{
+		10: {text: "Info", number: plog.SeverityNumberInfo},
+		20: {text: "Warnung"},
+		30: {number: plog.SeverityNumberFatal},
+	}
+
+	tests := []struct {
+		severity       uint16
+		expectedNumber plog.SeverityNumber
+		expectedText   string
+	}{
+		{severity: 10, expectedNumber: plog.SeverityNumberInfo, expectedText: "Info"},
+		{severity: 20, expectedNumber: plog.SeverityNumberDebug, expectedText: "Warnung"},
+		{severity: 30, expectedNumber: plog.SeverityNumberFatal, expectedText: "Debug"},
+		{severity: 220, expectedNumber: plog.SeverityNumberError, expectedText: "Error"},
+	}
+
+	for _, tt := range tests {
+		number, text := transformer.severity(tt.severity)
+		assert.Equal(t, tt.expectedNumber, number, "severity %d", tt.severity)
+		assert.Equal(t, tt.expectedText, text, "severity %d", tt.severity)
+	}
+}
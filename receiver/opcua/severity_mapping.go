@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"slices"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// severityRange is a range of severity values mapped by severity_mapping
+type severityRange struct {
+	min, max uint16
+	number   plog.SeverityNumber
+	text     string
+}
+
+// severityRanges replaces the Part 26 ranges. It is sorted by min and
+// covers 1–1000, which the config validation ensures.
+type severityRanges []severityRange
+
+// newSeverityRanges creates the ranges of severity_mapping, nil if none are configured
+func newSeverityRanges(ranges []SeverityRangeConfig) severityRanges {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	result := make(severityRanges, 0, len(ranges))
+	for _, r := range ranges {
+		result = append(result, severityRange{
+			min:    r.Min,
+			max:    r.Max,
+			number: plog.SeverityNumber(r.Number), //nolint:gosec
+			text:   r.Text,
+		})
+	}
+	slices.SortFunc(result, func(a, b severityRange) int { return int(a.min) - int(b.min) })
+	return result
+}
+
+// lookup returns the range of severity, ok is false if none contains it
+func (r severityRanges) lookup(severity uint16) (severityRange, bool) {
+	i := sort.Search(len(r), func(i int) bool { return r[i].max >= severity })
+	if i == len(r) || r[i].min > severity {
+		return severityRange{}, false
+	}
+	return r[i], true
+}
@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestValidateSeverityMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		ranges  []SeverityRangeConfig
+		wantErr string
+	}{
+		{name: "none"},
+		{
+			name: "unsorted ranges covering 1-1000",
+			ranges: []SeverityRangeConfig{
+				{Min: 501, Max: 1000, Number: 17},
+				{Min: 1, Max: 499, Number: 9},
+				{Min: 500, Max: 500, Number: 9, Text: "Information"},
+			},
+		},
+		{
+			name:   "single range",
+			ranges: []SeverityRangeConfig{{Min: 1, Max: 1000, Number: 9}},
+		},
+		{
+			name:    "min above max",
+			ranges:  []SeverityRangeConfig{{Min: 500, Max: 1, Number: 9}},
+			wantErr: "range 500–1 must lie within 1–1000",
+		},
+		{
+			name:    "beyond 1000",
+			ranges:  []SeverityRangeConfig{{Min: 1, Max: 1001, Number: 9}},
+			wantErr: "range 1–1001 must lie within 1–1000",
+		},
+		{
+			name:    "number out of range",
+			ranges:  []SeverityRangeConfig{{Min: 1, Max: 1000, Number: 25}},
+			wantErr: "number of range 1–1000 must be between 1 and 24",
+		},
+		{
+			name:    "overlap",
+			ranges:  []SeverityRangeConfig{{Min: 1, Max: 500, Number: 9}, {Min: 500, Max: 1000, Number: 17}},
+			wantErr: "range 500–1000 overlaps another range",
+		},
+		{
+			name:    "gap",
+			ranges:  []SeverityRangeConfig{{Min: 1, Max: 400, Number: 9}, {Min: 501, Max: 1000, Number: 17}},
+			wantErr: "severity values 401–500 are not mapped",
+		},
+		{
+			name:    "not starting at 1",
+			ranges:  []SeverityRangeConfig{{Min: 100, Max: 1000, Number: 9}},
+			wantErr: "severity values 1–99 are not mapped",
+		},
+		{
+			name:    "not reaching 1000",
+			ranges:  []SeverityRangeConfig{{Min: 1, Max: 999, Number: 9}},
+			wantErr: "severity values 1000–1000 are not mapped",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSeverityMapping(tt.ranges)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTransformerSeverityMapping(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.SeverityMapping = []SeverityRangeConfig{
+		{Min: 501, Max: 1000, Number: 17},
+		{Min: 1, Max: 499, Number: 5},
+		{Min: 500, Max: 500, Number: 9, Text: "Information"},
+	}
+	require.NoError(t, cfg.Validate())
+	transformer := newTransformerFromConfig(cfg)
+	transformer.severities = severityDictionary{600: {text: "Störung", number: plog.SeverityNumberWarn}}
+
+	tests := []struct {
+		severity       uint16
+		expectedNumber plog.SeverityNumber
+		expectedText   string
+	}{
+		{severity: 1, expectedNumber: plog.SeverityNumberDebug, expectedText: "Debug"},
+		{severity: 499, expectedNumber: plog.SeverityNumberDebug, expectedText: "Emergency"},
+		{severity: 500, expectedNumber: plog.SeverityNumberInfo, expectedText: "Information"},
+		{severity: 501, expectedNumber: plog.SeverityNumberError, expectedText: "Emergency"},
+		{severity: 600, expectedNumber: plog.SeverityNumberWarn, expectedText: "Störung"},
+		{severity: 0, expectedNumber: plog.SeverityNumberUnspecified, expectedText: "Unspecified"},
+	}
+
+	for _, tt := range tests {
+		number, text := transformer.severity(tt.severity)
+		assert.Equal(t, tt.expectedNumber, number, "severity %d", tt.severity)
+		assert.Equal(t, tt.expectedText, text, "severity %d", tt.severity)
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+)
+
+const (
+	spoolHeadKey   = "spool.head"
+	spoolTailKey   = "spool.tail"
+	spoolBatchKeyf = "spool.batch.%d"
+)
+
+// Reasons a spooled batch is dropped without being delivered
+const (
+	// spoolDropReasonPermanentError is a batch the logs consumer rejected
+	// with a permanent error, which retrying cannot resolve
+	spoolDropReasonPermanentError = "permanent_error"
+	// spoolDropReasonSpoolFull is the oldest batch of a spool that reached
+	// max_spooled_batches
+	spoolDropReasonSpoolFull = "spool_full"
+)
+
+// logSpool persists transformed log batches in a storage extension until the
+// next consumer accepts them, so records already fetched from the server
+// survive a collector restart between scrape and export.
+//
+// Batches are stored under increasing sequence numbers; head is the oldest
+// batch not yet delivered and tail is the sequence number of the next batch.
+type logSpool struct {
+	client      storage.Client
+	logger      *zap.Logger
+	telemetry   *metadata.TelemetryBuilder
+	marshaler   plog.ProtoMarshaler
+	unmarshaler plog.ProtoUnmarshaler
+	head        uint64
+	tail        uint64
+
+	// maxBatches bounds the undelivered batches, 0 if unbounded
+	maxBatches uint64
+}
+
+// newLogSpool opens the storage client of the given extension and restores the
+// spool position left by a previous run. It keeps at most maxBatches
+// undelivered batches, all of them if maxBatches is 0.
+func newLogSpool(ctx context.Context, host component.Host, storageID component.ID, receiverID component.ID, maxBatches int, telemetry *metadata.TelemetryBuilder, logger *zap.Logger) (*logSpool, error) {
+	client, err := getStorageClient(ctx, host, storageID, receiverID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &logSpool{client: client, logger: logger, telemetry: telemetry, maxBatches: uint64(max(maxBatches, 0))}
+	if s.head, err = s.getIndex(ctx, spoolHeadKey); err != nil {
+		return nil, errors.Join(err, client.Close(ctx))
+	}
+	if s.tail, err = s.getIndex(ctx, spoolTailKey); err != nil {
+		return nil, errors.Join(err, client.Close(ctx))
+	}
+
+	if pending := s.pending(); pending > 0 {
+		logger.Info("Found spooled log batches from a previous run", zap.Uint64("batches", pending))
+	}
+	return s, nil
+}
+
+// getStorageClient opens the storage client name of the receiver in the given
+// storage extension
+func getStorageClient(ctx context.Context, host component.Host, storageID component.ID, receiverID component.ID, name string) (storage.Client, error) {
+	ext, ok := host.GetExtensions()[storageID]
+	if !ok {
+		return nil, fmt.Errorf("storage extension %q not found", storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return nil, fmt.Errorf("extension %q is not a storage extension", storageID)
+	}
+
+	client, err := storageExt.GetClient(ctx, component.KindReceiver, receiverID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage client: %w", err)
+	}
+	return client, nil
+}
+
+// pending returns the number of batches not yet delivered
+func (s *logSpool) pending() uint64 {
+	return s.tail - s.head
+}
+
+// push appends a batch to the spool
+func (s *logSpool) push(ctx context.Context, logs plog.Logs) error {
+	data, err := s.marshaler.MarshalLogs(logs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal logs: %w", err)
+	}
+
+	if err := s.client.Batch(ctx,
+		storage.SetOperation(batchKey(s.tail), data),
+		storage.SetOperation(spoolTailKey, encodeIndex(s.tail+1)),
+	); err != nil {
+		return fmt.Errorf("failed to spool logs: %w", err)
+	}
+	s.tail++
+
+	// A downstream that keeps failing must not fill the storage
+	for s.maxBatches > 0 && s.pending() > s.maxBatches {
+		s.logger.Warn("Spool full, dropping the oldest undelivered log batch",
+			zap.String("key", batchKey(s.head)),
+			zap.Uint64("max_spooled_batches", s.maxBatches))
+		if err := s.removeHead(ctx); err != nil {
+			return err
+		}
+		s.countDropped(ctx, spoolDropReasonSpoolFull)
+	}
+	return nil
+}
+
+// drain delivers spooled batches in order until the spool is empty or consume
+// fails. A batch is only removed once consume has accepted it, or rejected it
+// with a permanent error, which retrying would only repeat.
+func (s *logSpool) drain(ctx context.Context, consume func(context.Context, plog.Logs) error) error {
+	for s.head < s.tail {
+		data, err := s.client.Get(ctx, batchKey(s.head))
+		if err != nil {
+			return fmt.Errorf("failed to read spooled logs: %w", err)
+		}
+
+		if data != nil {
+			logs, err := s.unmarshaler.UnmarshalLogs(data)
+			if err != nil {
+				// A corrupt batch can never be delivered; drop it rather than blocking the spool
+				s.logger.Error("Dropping unreadable spooled log batch",
+					zap.String("key", batchKey(s.head)), zap.Error(err))
+			} else if err := consume(ctx, logs); err != nil {
+				if !consumererror.IsPermanent(err) {
+					return err
+				}
+				s.logger.Error("Dropping spooled log batch rejected permanently by the logs consumer",
+					zap.String("key", batchKey(s.head)), zap.Error(err))
+				s.countDropped(ctx, spoolDropReasonPermanentError)
+			}
+		}
+
+		if err := s.removeHead(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeHead removes the oldest batch from the spool
+func (s *logSpool) removeHead(ctx context.Context) error {
+	if err := s.client.Batch(ctx,
+		storage.DeleteOperation(batchKey(s.head)),
+		storage.SetOperation(spoolHeadKey, encodeIndex(s.head+1)),
+	); err != nil {
+		return fmt.Errorf("failed to remove logs from spool: %w", err)
+	}
+	s.head++
+	return nil
+}
+
+// countDropped counts a batch dropped without being delivered
+func (s *logSpool) countDropped(ctx context.Context, reason string) {
+	s.telemetry.OpcuaSpoolBatchesDropped.Add(ctx, 1,
+		metric.WithAttributes(attribute.String("opcua.spool.drop_reason", reason)))
+}
+
+// close closes the storage client
+func (s *logSpool) close(ctx context.Context) error {
+	return s.client.Close(ctx)
+}
+
+// getIndex reads a spool index, returning zero if it has never been written
+func (s *logSpool) getIndex(ctx context.Context, key string) (uint64, error) {
+	data, err := s.client.Get(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if data == nil {
+		return 0, nil
+	}
+	if len(data) != 8 {
+		return 0, fmt.Errorf("invalid %s value of length %d", key, len(data))
+	}
+	return binary.BigEndian.Uint64(data), nil
+}
+
+// batchKey returns the storage key of the batch with the given sequence number
+func batchKey(seq uint64) string {
+	return fmt.Sprintf(spoolBatchKeyf, seq)
+}
+
+// encodeIndex encodes a spool index for storage
+func encodeIndex(index uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, index)
+}
@@ -0,0 +1,250 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/extension/xextension/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadatatest"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// newNopTelemetryBuilder returns a telemetry builder that records nothing
+func newNopTelemetryBuilder(tb testing.TB) *metadata.TelemetryBuilder {
+	return newTestTelemetryBuilder(tb, componenttest.NewNopTelemetrySettings())
+}
+
+// memoryStorage is an in-memory storage extension whose data outlives its clients,
+// simulating a file storage surviving a collector restart
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+	data map[string][]byte
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{data: map[string][]byte{}}
+}
+
+func (m *memoryStorage) GetClient(context.Context, component.Kind, component.ID, string) (storage.Client, error) {
+	return &memoryClient{data: m.data}, nil
+}
+
+type memoryClient struct {
+	data map[string][]byte
+}
+
+func (c *memoryClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.data[key], nil
+}
+
+func (c *memoryClient) Set(_ context.Context, key string, value []byte) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *memoryClient) Delete(_ context.Context, key string) error {
+	delete(c.data, key)
+	return nil
+}
+
+func (c *memoryClient) Batch(_ context.Context, ops ...*storage.Operation) error {
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.data[op.Key]
+		case storage.Set:
+			c.data[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.data, op.Key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error { return nil }
+
+type extensionHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h *extensionHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+var testStorageID = component.MustNewID("file_storage")
+
+func newStorageHost(ext component.Component) component.Host {
+	return &extensionHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[component.ID]component.Component{testStorageID: ext},
+	}
+}
+
+func spoolTestLogs(message string) plog.Logs {
+	transformer := newRecordTransformer("opc.tcp://localhost:4840", "opcua-server", "")
+	return transformer.TransformLogs([]testdata.OPCUALogRecord{
+		{Timestamp: time.Now(), Severity: 100, Message: message},
+	})
+}
+
+func TestLogSpoolDeliversInOrder(t *testing.T) {
+	ctx := context.Background()
+	host := newStorageHost(newMemoryStorage())
+	spool, err := newLogSpool(ctx, host, testStorageID, component.MustNewID("opcua"), 0, newNopTelemetryBuilder(t), zap.NewNop())
+	require.NoError(t, err)
+
+	var delivered []string
+	consume := func(_ context.Context, logs plog.Logs) error {
+		delivered = append(delivered, logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+		return nil
+	}
+
+	require.NoError(t, spool.push(ctx, spoolTestLogs("first")))
+	require.NoError(t, spool.push(ctx, spoolTestLogs("second")))
+	assert.Equal(t, uint64(2), spool.pending())
+
+	require.NoError(t, spool.drain(ctx, consume))
+	assert.Equal(t, []string{"first", "second"}, delivered)
+	assert.Equal(t, uint64(0), spool.pending())
+}
+
+func TestLogSpoolKeepsBatchesOnConsumerError(t *testing.T) {
+	ctx := context.Background()
+	host := newStorageHost(newMemoryStorage())
+	spool, err := newLogSpool(ctx, host, testStorageID, component.MustNewID("opcua"), 0, newNopTelemetryBuilder(t), zap.NewNop())
+	require.NoError(t, err)
+
+	require.NoError(t, spool.push(ctx, spoolTestLogs("first")))
+	err = spool.drain(ctx, func(context.Context, plog.Logs) error {
+		return errors.New("exporter unavailable")
+	})
+	require.Error(t, err)
+	assert.Equal(t, uint64(1), spool.pending())
+
+	count := 0
+	require.NoError(t, spool.drain(ctx, func(context.Context, plog.Logs) error {
+		count++
+		return nil
+	}))
+	assert.Equal(t, 1, count)
+}
+
+func TestLogSpoolDropsPermanentlyRejectedBatches(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+	host := newStorageHost(newMemoryStorage())
+	spool, err := newLogSpool(ctx, host, testStorageID, component.MustNewID("opcua"), 0, newTestTelemetryBuilder(t, tel.NewTelemetrySettings()), zap.NewNop())
+	require.NoError(t, err)
+
+	// A bad batch does not block the ones behind it
+	require.NoError(t, spool.push(ctx, spoolTestLogs("bad")))
+	require.NoError(t, spool.push(ctx, spoolTestLogs("good")))
+	var delivered []string
+	require.NoError(t, spool.drain(ctx, func(_ context.Context, logs plog.Logs) error {
+		body := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str()
+		if body == "bad" {
+			return consumererror.NewPermanent(errors.New("invalid batch"))
+		}
+		delivered = append(delivered, body)
+		return nil
+	}))
+	assert.Equal(t, []string{"good"}, delivered)
+	assert.Equal(t, uint64(0), spool.pending())
+
+	metadatatest.AssertEqualOpcuaSpoolBatchesDropped(t, tel,
+		[]metricdata.DataPoint[int64]{{
+			Value:      1,
+			Attributes: attribute.NewSet(attribute.String("opcua.spool.drop_reason", "permanent_error")),
+		}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+func TestLogSpoolMaxBatches(t *testing.T) {
+	ctx := context.Background()
+	tel := componenttest.NewTelemetry()
+	defer func() {
+		require.NoError(t, tel.Shutdown(ctx))
+	}()
+	host := newStorageHost(newMemoryStorage())
+	core, observed := observer.New(zap.WarnLevel)
+	spool, err := newLogSpool(ctx, host, testStorageID, component.MustNewID("opcua"), 2, newTestTelemetryBuilder(t, tel.NewTelemetrySettings()), zap.New(core))
+	require.NoError(t, err)
+
+	// While the consumer is down, the spool keeps the newest batches only
+	for _, message := range []string{"first", "second", "third", "fourth"} {
+		require.NoError(t, spool.push(ctx, spoolTestLogs(message)))
+	}
+	assert.Equal(t, uint64(2), spool.pending())
+	assert.Equal(t, 2, observed.FilterMessage("Spool full, dropping the oldest undelivered log batch").Len())
+
+	var delivered []string
+	require.NoError(t, spool.drain(ctx, func(_ context.Context, logs plog.Logs) error {
+		delivered = append(delivered, logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+		return nil
+	}))
+	assert.Equal(t, []string{"third", "fourth"}, delivered)
+
+	metadatatest.AssertEqualOpcuaSpoolBatchesDropped(t, tel,
+		[]metricdata.DataPoint[int64]{{
+			Value:      2,
+			Attributes: attribute.NewSet(attribute.String("opcua.spool.drop_reason", "spool_full")),
+		}},
+		metricdatatest.IgnoreTimestamp())
+}
+
+func TestLogSpoolSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	host := newStorageHost(newMemoryStorage())
+	id := component.MustNewID("opcua")
+
+	spool, err := newLogSpool(ctx, host, testStorageID, id, 0, newNopTelemetryBuilder(t), zap.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, spool.push(ctx, spoolTestLogs("before crash")))
+	require.NoError(t, spool.close(ctx))
+
+	restarted, err := newLogSpool(ctx, host, testStorageID, id, 0, newNopTelemetryBuilder(t), zap.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), restarted.pending())
+
+	var delivered []string
+	require.NoError(t, restarted.drain(ctx, func(_ context.Context, logs plog.Logs) error {
+		delivered = append(delivered, logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+		return nil
+	}))
+	assert.Equal(t, []string{"before crash"}, delivered)
+}
+
+func TestLogSpoolMissingExtension(t *testing.T) {
+	_, err := newLogSpool(context.Background(), componenttest.NewNopHost(), testStorageID, component.MustNewID("opcua"), 0, newNopTelemetryBuilder(t), zap.NewNop())
+	assert.ErrorContains(t, err, "not found")
+
+	host := newStorageHost(&struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{})
+	_, err = newLogSpool(context.Background(), host, testStorageID, component.MustNewID("opcua"), 0, newNopTelemetryBuilder(t), zap.NewNop())
+	assert.ErrorContains(t, err, "not a storage extension")
+}
@@ -0,0 +1,44 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// startAtBeginning collects the whole log history of the server
+	startAtBeginning = "beginning"
+
+	// startAtEnd skips the history and collects the records logged from the
+	// start on
+	startAtEnd = "end"
+)
+
+// parseStartAt returns how far back the first collection reaches: zero for
+// the records logged from the start on, a negative value for the whole history
+func parseStartAt(startAt string) (time.Duration, error) {
+	switch startAt {
+	case "", startAtBeginning:
+		return -1, nil
+	case startAtEnd:
+		return 0, nil
+	}
+	backfill, err := time.ParseDuration(startAt)
+	if err != nil || backfill <= 0 {
+		return 0, fmt.Errorf("invalid start_at: %s, must be beginning, end or a positive duration", startAt)
+	}
+	return backfill, nil
+}
+
+// startTime returns the start of the first collection window at now, the
+// zero time for the whole history
+func (cfg *Config) startTime(now time.Time) time.Time {
+	backfill, err := parseStartAt(cfg.StartAt)
+	if err != nil || backfill < 0 {
+		return time.Time{}
+	}
+	return now.Add(-backfill)
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+func TestConfigStartTime(t *testing.T) {
+	now := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		startAt  string
+		expected time.Time
+		wantErr  bool
+	}{
+		{startAt: "", expected: time.Time{}},
+		{startAt: "beginning", expected: time.Time{}},
+		{startAt: "end", expected: now},
+		{startAt: "24h", expected: now.Add(-24 * time.Hour)},
+		{startAt: "90m", expected: now.Add(-90 * time.Minute)},
+		{startAt: "0s", wantErr: true},
+		{startAt: "-1h", wantErr: true},
+		{startAt: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.startAt, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.StartAt = tt.startAt
+			err := cfg.Validate()
+			if tt.wantErr {
+				require.ErrorContains(t, err, "invalid start_at")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.startTime(now))
+		})
+	}
+}
+
+func TestScraperStartAt(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.StartAt = "1h"
+
+	before := time.Now()
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+
+	// The first window backfills an hour
+	assert.False(t, scr.lastCollectTime.Before(before.Add(-time.Hour)))
+	assert.False(t, scr.lastCollectTime.After(time.Now().Add(-time.Hour)))
+
+	// A checkpoint takes precedence
+	checkpointed := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	scr.restoreCheckpoint(checkpoint{LastCollectTime: checkpointed})
+	assert.Equal(t, checkpointed, scr.lastCollectTime)
+}
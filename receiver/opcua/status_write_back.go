@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+)
+
+// statusWriter is implemented by clients that can write the time of the last
+// successful collection back to the server. Clients supplied through
+// Config.Client or a ClientFactory may implement it to support write-back.
+type statusWriter interface {
+	WriteCollectionStatus(ctx context.Context, nodeID string, lastSuccess time.Time) error
+}
+
+// WriteCollectionStatus writes lastSuccess to the DateTime variable nodeID
+func (c *opcuaClient) WriteCollectionStatus(ctx context.Context, nodeID string, lastSuccess time.Time) error {
+	id, err := ua.ParseNodeID(nodeID)
+	if err != nil {
+		return fmt.Errorf("invalid status node %s: %w", nodeID, err)
+	}
+
+	session, err := c.session()
+	if err != nil {
+		return err
+	}
+
+	req := &ua.WriteRequest{
+		NodesToWrite: []*ua.WriteValue{
+			{
+				NodeID:      id,
+				AttributeID: ua.AttributeIDValue,
+				Value: &ua.DataValue{
+					EncodingMask: ua.DataValueValue,
+					Value:        ua.MustVariant(lastSuccess.UTC()),
+				},
+			},
+		},
+	}
+
+	resp, err := session.Write(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to write status node %s: %w", nodeID, err)
+	}
+	if len(resp.Results) == 0 {
+		return fmt.Errorf("failed to write status node %s: no results returned", nodeID)
+	}
+	if resp.Results[0] != ua.StatusOK {
+		return fmt.Errorf("failed to write status node %s: %w", nodeID, resp.Results[0])
+	}
+	return nil
+}
+
+// writeStatus writes the time of a successful collection to the configured
+// status node, at most once per status_write_back.interval. A failed write is
+// logged and retried after the next successful collection; it does not fail
+// the scrape.
+func (s *scraper) writeStatus(ctx context.Context, lastSuccess time.Time) {
+	cfg := s.config.StatusWriteBack
+	if cfg.NodeID == "" {
+		return
+	}
+	if !s.statusWritten.IsZero() && lastSuccess.Sub(s.statusWritten) < cfg.Interval {
+		return
+	}
+
+	writer, ok := s.client.(statusWriter)
+	if !ok {
+		s.errorLog.Warn(s.settings.Logger, "status_write_back",
+			"OPC UA client does not support status write-back",
+			zap.String("node_id", cfg.NodeID))
+		return
+	}
+
+	if err := writer.WriteCollectionStatus(ctx, cfg.NodeID, lastSuccess); err != nil {
+		s.errorLog.Warn(s.settings.Logger, "status_write_back",
+			"Failed to write collection status to the server",
+			zap.String("node_id", cfg.NodeID),
+			zap.Error(err))
+		return
+	}
+	s.statusWritten = lastSuccess
+}
@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+// recordStreamer is implemented by clients that can hand the records of a
+// GetRecords query over page by page instead of returning them at once
+type recordStreamer interface {
+	// streamRecords reads the records like GetRecords and passes every page
+	// to emit, which takes ownership of it. emit may be called by several
+	// LogObject reads at a time.
+	streamRecords(ctx context.Context, startTime, endTime time.Time, maxRecords int, emit func([]model.LogRecord)) error
+}
+
+// flushedRecords are the records of the running scrape that were already
+// handed over in batches of flush_size
+type flushedRecords struct {
+	count    int
+	earliest map[string]time.Time
+}
+
+// recordBatcher collects the pages of a streamed query into batches of size
+// records and passes each full batch to flush
+type recordBatcher struct {
+	mu      sync.Mutex
+	size    int
+	records []model.LogRecord
+	flush   func([]model.LogRecord)
+}
+
+// add appends a page and flushes the batch once it is full. The flush runs
+// under the lock, so the reads wait for a slow consumer instead of piling up
+// pages in memory.
+func (b *recordBatcher) add(page []model.LogRecord) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.records = append(b.records, page...)
+	putRecordSlice(page)
+	for len(b.records) >= b.size {
+		batch := getRecordSlice()
+		batch = append(batch, b.records[:b.size]...)
+		rest := copy(b.records, b.records[b.size:])
+		clear(b.records[rest:])
+		b.records = b.records[:rest]
+		b.flush(batch)
+	}
+}
+
+// getRecords reads the records of a collection window. With flush_size set
+// and a batch consumer attached, the pages are handed over in batches while
+// the client pages through the window, and only the records of the last,
+// partial batch are returned.
+func (s *scraper) getRecords(ctx context.Context, startTime, endTime time.Time) ([]model.LogRecord, error) {
+	maxRecords := s.config.maxRecordsPerScrape()
+	streamer, ok := s.client.(recordStreamer)
+	if !ok || s.onBatch == nil || s.config.FlushSize <= 0 {
+		return s.client.GetRecords(ctx, startTime, endTime, maxRecords)
+	}
+
+	batcher := &recordBatcher{
+		size:    s.config.FlushSize,
+		records: getRecordSlice(),
+		flush:   func(batch []model.LogRecord) { s.flushBatch(ctx, batch) },
+	}
+	if err := streamer.streamRecords(ctx, startTime, endTime, maxRecords, batcher.add); err != nil {
+		releaseRecords(batcher.records)
+		return nil, err
+	}
+	return batcher.records, nil
+}
+
+// flushBatch counts a batch of the running scrape, moves the read positions
+// past it and hands it to onBatch. If the scrape fails later, the next one
+// continues each LogObject after its records handed over instead of
+// reading them again. The records are released afterwards.
+func (s *scraper) flushBatch(ctx context.Context, batch []model.LogRecord) {
+	defer releaseRecords(batch)
+	s.flushed.count += len(batch)
+	if s.config.Diagnostics.GapRecords {
+		s.flushed.earliest = addEarliest(s.flushed.earliest, batch)
+	}
+	if s.config.DerivedMetrics.Enabled {
+		s.recordSeverityMetrics(ctx, batch)
+	}
+	s.recordScraped(ctx, batch)
+	s.advancePositions(batch)
+	s.onBatch(ctx, batch)
+}
@@ -0,0 +1,175 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+func TestReceiverWireFlushSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		metrics  bool
+		expected []int
+	}{
+		{
+			// Pages of 3 records fill batches of 4, the rest is delivered
+			// with the end of the scrape
+			name:     "logs only",
+			expected: []int{4, 4, 2},
+		},
+		{
+			name:     "metrics built from the whole window",
+			metrics:  true,
+			expected: []int{10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws := startWireServer(t)
+			ws.SetMaxPageSize(3)
+			ws.AddLogRecords(wireRecords(10))
+			ctx := context.Background()
+
+			cfg := ws.newWireConfig()
+			cfg.FlushSize = 4
+			r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+			require.NoError(t, err)
+			sink := new(consumertest.LogsSink)
+			r.nextLogs = sink
+			if tt.metrics {
+				r.nextMetrics = new(consumertest.MetricsSink)
+			}
+			r.streamBatches()
+			require.NoError(t, r.scraper.start(ctx, componenttest.NewNopHost()))
+			defer func() {
+				assert.NoError(t, r.scraper.shutdown(ctx))
+			}()
+
+			require.NoError(t, r.collectAndConsume(ctx))
+
+			var sizes []int
+			var timestamps []time.Time
+			for _, logs := range sink.AllLogs() {
+				sizes = append(sizes, logs.LogRecordCount())
+				timestamps = append(timestamps, logTimestamps(logs)...)
+			}
+			assert.Equal(t, tt.expected, sizes)
+
+			// The batches keep the order of the records
+			require.Len(t, timestamps, 10)
+			for i := 1; i < len(timestamps); i++ {
+				assert.True(t, timestamps[i-1].Before(timestamps[i]))
+			}
+		})
+	}
+}
+
+func TestReceiverWireFlushSizeRetry(t *testing.T) {
+	ws := startWireServer(t)
+	ws.SetMaxPageSize(2)
+	ws.AddLogRecords(wireRecords(7))
+	// The third page fails the scrape after two batches were handed over
+	ws.InjectFault(testdata.Fault{Kind: testdata.FaultStatus, Call: 3, Status: ua.StatusBadInternalError})
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.FlushSize = 2
+	r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	sink := new(consumertest.LogsSink)
+	r.nextLogs = sink
+	r.streamBatches()
+	require.NoError(t, r.scraper.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.scraper.shutdown(ctx))
+	}()
+
+	require.Error(t, r.collectAndConsume(ctx))
+	assert.Equal(t, 4, sink.LogRecordCount())
+
+	// The retry continues after the records handed over
+	require.NoError(t, r.collectAndConsume(ctx))
+	var timestamps []time.Time
+	for _, logs := range sink.AllLogs() {
+		timestamps = append(timestamps, logTimestamps(logs)...)
+	}
+	require.Len(t, timestamps, 7)
+	for i := 1; i < len(timestamps); i++ {
+		assert.True(t, timestamps[i-1].Before(timestamps[i]), "record %d delivered twice or out of order", i)
+	}
+	assert.Empty(t, r.scraper.positions)
+}
+
+func TestScraperWireFlushSizeGapRecords(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	clk := newFakeClock(base.Add(10 * time.Second))
+	ws := startWireServer(t)
+	require.NoError(t, ws.SetLogBufferCapacity(ws.logObjectID, 5))
+	ws.AddLogRecords(testdata.NewSeededGenerator(1, base).SteadyInfo(5, time.Second))
+
+	cfg := ws.newWireConfig()
+	cfg.FlushSize = 5
+	cfg.Diagnostics.GapRecords = true
+	cfg.Diagnostics.GapThreshold = 5 * time.Second
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	scr.clock = clk
+	var batches []int
+	scr.onBatch = func(_ context.Context, records []model.LogRecord) {
+		batches = append(batches, len(records))
+	}
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, scr.shutdown(ctx))
+	}()
+
+	logs, err := scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.LogRecordCount())
+
+	// The gap before the records of a flushed batch is still detected
+	ws.AddLogRecords(testdata.NewSeededGenerator(2, base.Add(20*time.Second)).SteadyInfo(10, time.Second))
+	clk.Advance(30 * time.Second)
+	logs, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 5}, batches)
+
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, lrs.Len())
+	gapEnd, ok := lrs.At(0).Attributes().Get("opcua.gap.end")
+	require.True(t, ok)
+	assert.Equal(t, "2025-01-15T10:00:25Z", gapEnd.Str())
+}
+
+// logTimestamps returns the timestamps of all log records in logs
+func logTimestamps(logs plog.Logs) []time.Time {
+	var timestamps []time.Time
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		scopeLogs := logs.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				timestamps = append(timestamps, records.At(k).Timestamp().AsTime())
+			}
+		}
+	}
+	return timestamps
+}
@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+)
+
+// stressDuration is how long the stress tests hammer the client. Run them with
+// -race to detect unsynchronized access to the session.
+const stressDuration = 2 * time.Second
+
+// runStress runs each worker in its own goroutine until stressDuration has
+// passed and fails the test with all goroutine stacks if a worker does not
+// return in time, which indicates a deadlock
+func runStress(t *testing.T, workers ...func(ctx context.Context)) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), stressDuration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, worker := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				worker(ctx)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(stressDuration + 30*time.Second):
+		buf := make([]byte, 1<<20)
+		n := runtime.Stack(buf, true)
+		t.Fatalf("workers did not stop, possible deadlock:\n%s", buf[:n])
+	}
+}
+
+func TestClientReconnectStress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("stress test")
+	}
+
+	const recordCount = 50
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(recordCount))
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.RequestTimeout = time.Second
+	c := newOPCUAClient(cfg, zap.NewNop())
+	require.NoError(t, c.Connect(ctx))
+
+	var reads, fullReads, connects atomic.Int64
+	getRecords := func(ctx context.Context) {
+		records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+		if err != nil {
+			return
+		}
+		reads.Add(1)
+		// A session closed mid-read may cut the result short, but a read must
+		// never return more records than the server holds
+		assert.LessOrEqual(t, len(records), recordCount)
+		if len(records) == recordCount {
+			fullReads.Add(1)
+		}
+	}
+	connect := func(ctx context.Context) {
+		if err := c.Connect(ctx); err == nil {
+			connects.Add(1)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	runStress(t,
+		getRecords,
+		getRecords,
+		getRecords,
+		getRecords,
+		connect,
+		connect,
+		func(ctx context.Context) {
+			_ = c.Disconnect(ctx)
+			time.Sleep(10 * time.Millisecond)
+		},
+		func(context.Context) {
+			_ = c.IsConnected()
+		},
+	)
+
+	t.Logf("%d reads (%d complete), %d connects", reads.Load(), fullReads.Load(), connects.Load())
+	assert.Positive(t, connects.Load())
+
+	// The client must be usable after the storm
+	require.NoError(t, c.Connect(ctx))
+	defer func() {
+		assert.NoError(t, c.Disconnect(ctx))
+	}()
+	assert.True(t, c.IsConnected())
+	records, err := c.GetRecords(ctx, time.Time{}, time.Now(), 1000)
+	require.NoError(t, err)
+	assert.Len(t, records, recordCount)
+}
+
+func TestScraperReconnectStress(t *testing.T) {
+	if testing.Short() {
+		t.Skip("stress test")
+	}
+
+	ws := startWireServer(t)
+	ws.AddLogRecords(wireRecords(20))
+	ctx := context.Background()
+
+	cfg := ws.newWireConfig()
+	cfg.RequestTimeout = time.Second
+	scr, err := newScraper(cfg, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	require.NoError(t, scr.start(ctx, componenttest.NewNopHost()))
+	client := scr.client.(*opcuaClient)
+
+	var scrapes, failures atomic.Int64
+	runStress(t,
+		// The scraper helper never overlaps scrapes, so a single worker scrapes
+		func(ctx context.Context) {
+			if _, err := scr.scrape(ctx); err != nil {
+				failures.Add(1)
+			}
+			scrapes.Add(1)
+		},
+		func(ctx context.Context) {
+			_ = client.Disconnect(ctx)
+			time.Sleep(20 * time.Millisecond)
+		},
+		func(ctx context.Context) {
+			_ = client.Connect(ctx)
+			time.Sleep(15 * time.Millisecond)
+		},
+	)
+	t.Logf("%d scrapes, %d failed", scrapes.Load(), failures.Load())
+
+	// The next scrape reconnects on its own and shutdown closes the session
+	require.NoError(t, client.Disconnect(ctx))
+	_, err = scr.scrape(ctx)
+	require.NoError(t, err)
+	assert.True(t, client.IsConnected())
+	require.NoError(t, scr.shutdown(ctx))
+	assert.False(t, client.IsConnected())
+}
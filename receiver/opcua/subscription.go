@@ -0,0 +1,365 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+const (
+	// collectionModePoll calls GetRecords every collection_interval
+	collectionModePoll = "poll"
+
+	// collectionModeSubscribe receives records as events of the LogObjects
+	collectionModeSubscribe = "subscribe"
+
+	// collectionModeAlarms receives Alarm & Condition events instead of
+	// LogObject records
+	collectionModeAlarms = "alarms"
+
+	// eventNotificationBuffer is the number of publish notifications gopcua
+	// may queue while a batch of records is consumed
+	eventNotificationBuffer = 64
+
+	// sessionCheckInterval is the least interval the session of a running
+	// subscription is checked at
+	sessionCheckInterval = time.Second
+)
+
+// logEventFields are the BaseEventType fields selected from LogObject
+// events, in the order of the event field lists
+var logEventFields = []string{"Time", "Severity", "Message", "SourceName", "SourceNode"}
+
+// eventSubscriber is implemented by clients that can receive the records of
+// the LogObjects as event notifications
+type eventSubscriber interface {
+	// subscribeEvents subscribes to the events of every LogObject and calls
+	// onSubscribed once the monitored items exist, then deliver with the
+	// records of each notification. Both are called on the calling goroutine.
+	// It returns nil when ctx is done and an error when the subscription
+	// cannot be created, onSubscribed fails or the subscription breaks.
+	subscribeEvents(ctx context.Context, cfg SubscriptionConfig, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error
+}
+
+// logEventFilter selects the log record fields of the events. A browse
+// path on BaseEventType is evaluated regardless of the event type, so the
+// fields of LogObject event subtypes are found as well.
+func logEventFilter() *ua.EventFilter {
+	clauses := make([]*ua.SimpleAttributeOperand, len(logEventFields))
+	for i, field := range logEventFields {
+		clauses[i] = &ua.SimpleAttributeOperand{
+			TypeDefinitionID: ua.NewNumericNodeID(0, id.BaseEventType),
+			BrowsePath:       []*ua.QualifiedName{{Name: field}},
+			AttributeID:      ua.AttributeIDValue,
+		}
+	}
+	return &ua.EventFilter{SelectClauses: clauses}
+}
+
+// subscribeEvents subscribes to the events of all LogObject nodes
+func (c *opcuaClient) subscribeEvents(ctx context.Context, cfg SubscriptionConfig, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	c.mu.Lock()
+	logObjectIDs := c.logObjectIDs
+	c.mu.Unlock()
+	if len(logObjectIDs) == 0 {
+		return newDiscoveryError("", fmt.Errorf("no LogObject nodes configured"))
+	}
+
+	return c.monitorEvents(ctx, cfg, eventMonitor{
+		kind:      "LogObject",
+		notifiers: logObjectIDs,
+		filter:    logEventFilter(),
+		records:   eventRecords,
+	}, onSubscribed, deliver)
+}
+
+// eventMonitor describes the event monitored items of a subscription
+type eventMonitor struct {
+	// kind names the notifiers in errors and logs
+	kind string
+
+	// notifiers are the nodes whose EventNotifier is monitored. The client
+	// handle of each monitored item is the index of its notifier.
+	notifiers []*ua.NodeID
+
+	filter *ua.EventFilter
+
+	// records converts the events of a notification to log records
+	records func(list *ua.EventNotificationList, notifiers []*ua.NodeID, minSeverity uint16) []model.LogRecord
+
+	// monitored, if set, is called once the monitored items exist and
+	// before onSubscribed
+	monitored func(ctx context.Context, session *opcua.Client, sub *opcua.Subscription) error
+}
+
+// monitorEvents creates a subscription with the monitored items of m and
+// delivers the records of its event notifications until ctx is done or the
+// subscription breaks
+func (c *opcuaClient) monitorEvents(ctx context.Context, cfg SubscriptionConfig, m eventMonitor, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	session, err := c.session()
+	if err != nil {
+		return err
+	}
+
+	notifications := make(chan *opcua.PublishNotificationData, eventNotificationBuffer)
+	sub, err := session.Subscribe(ctx, &opcua.SubscriptionParameters{Interval: cfg.PublishingInterval}, notifications)
+	if err != nil {
+		return fmt.Errorf("failed to create subscription: %w", err)
+	}
+	defer func() {
+		if err := sub.Cancel(context.WithoutCancel(ctx)); err != nil {
+			c.logger.Debug("Failed to delete subscription", zap.Error(err))
+		}
+	}()
+
+	filter := ua.NewExtensionObject(m.filter)
+	items := make([]*ua.MonitoredItemCreateRequest, len(m.notifiers))
+	for i, notifier := range m.notifiers {
+		item := opcua.NewMonitoredItemCreateRequestWithDefaults(notifier, ua.AttributeIDEventNotifier, uint32(i)) //nolint:gosec
+		item.RequestedParameters.Filter = filter
+		item.RequestedParameters.QueueSize = cfg.QueueSize
+		items[i] = item
+	}
+	resp, err := sub.Monitor(ctx, ua.TimestampsToReturnNeither, items...)
+	if err != nil {
+		return fmt.Errorf("failed to monitor %s events: %w", m.kind, err)
+	}
+	if len(resp.Results) != len(items) {
+		return fmt.Errorf("failed to monitor %s events: %d results for %d items", m.kind, len(resp.Results), len(items))
+	}
+	for i, result := range resp.Results {
+		if result.StatusCode != ua.StatusOK {
+			return newDiscoveryError(m.notifiers[i].String(), fmt.Errorf("%s does not deliver events: %w", m.kind, result.StatusCode))
+		}
+	}
+	c.logger.Info("Subscribed to events",
+		zap.String("kind", m.kind),
+		zap.Int("notifiers", len(m.notifiers)),
+		zap.Duration("publishing_interval", cfg.PublishingInterval))
+
+	if m.monitored != nil {
+		if err := m.monitored(ctx, session, sub); err != nil {
+			return err
+		}
+	}
+	if err := onSubscribed(ctx); err != nil {
+		return err
+	}
+
+	// Without gopcua's auto-reconnect a lost session does not always fail
+	// the subscription, so the session is checked as well
+	check := time.NewTicker(max(cfg.PublishingInterval, sessionCheckInterval))
+	defer check.Stop()
+
+	minSeverity := c.getMinSeverityValue()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-check.C:
+			if current, err := c.session(); err != nil || current != session || current.State() != opcua.Connected {
+				return newConnectionError(c.config.Endpoint, errors.New("session lost"))
+			}
+		case n := <-notifications:
+			if n.Error != nil {
+				return fmt.Errorf("subscription failed: %w", n.Error)
+			}
+			switch v := n.Value.(type) {
+			case *ua.EventNotificationList:
+				if records := m.records(v, m.notifiers, minSeverity); len(records) > 0 {
+					deliver(records)
+				}
+			case *ua.StatusChangeNotification:
+				if v.Status != ua.StatusOK {
+					return fmt.Errorf("subscription status changed: %w", v.Status)
+				}
+			}
+		}
+	}
+}
+
+// eventRecords converts the events of a notification to log records. The
+// client handle of an event is the index of its LogObject. Events below
+// minSeverity are dropped, as GetRecords would not have returned them.
+func eventRecords(list *ua.EventNotificationList, logObjectIDs []*ua.NodeID, minSeverity uint16) []model.LogRecord {
+	records := getRecordSlice()
+	for _, event := range list.Events {
+		if event == nil || int(event.ClientHandle) >= len(logObjectIDs) {
+			continue
+		}
+		record := eventRecord(event.EventFields)
+		if record.Severity < minSeverity {
+			putAttributeMap(record.Attributes)
+			continue
+		}
+		record.LogObjectID = logObjectIDs[event.ClientHandle].String()
+		records = append(records, record)
+	}
+	return records
+}
+
+// eventRecord converts the fields selected by logEventFilter to a log
+// record. Fields the event does not have are null and left empty.
+func eventRecord(fields []*ua.Variant) model.LogRecord {
+	record := model.LogRecord{Attributes: getAttributeMap()}
+	value := func(i int) interface{} {
+		if i >= len(fields) || fields[i] == nil {
+			return nil
+		}
+		return fields[i].Value()
+	}
+
+	if t, ok := value(0).(time.Time); ok {
+		record.Timestamp = t
+	}
+	if severity, ok := value(1).(uint16); ok {
+		record.Severity = severity
+	}
+	if text, ok := value(2).(*ua.LocalizedText); ok && text != nil {
+		record.Message = text.Text
+		record.MessageLocale = text.Locale
+	}
+	if name, ok := value(3).(string); ok {
+		record.SourceName = name
+	}
+	if nodeID, ok := value(4).(*ua.NodeID); ok {
+		record.SourceNamespace, record.SourceIDType, record.SourceID = nodeIDComponents(nodeID)
+	}
+	return record
+}
+
+// runSubscription collects the records pushed as events instead of polling.
+// Each time the subscription is created, the records logged since the last
+// collection are read with GetRecords first, so the start and every
+// resubscription after a failure leave no gap.
+func (r *opcuaReceiver) runSubscription(ctx context.Context, subscriber eventSubscriber) {
+	r.settings.Logger.Info("Starting event subscription",
+		zap.Duration("publishing_interval", r.config.Subscription.PublishingInterval))
+
+	for {
+		var caughtUp time.Time
+		err := r.subscribe(ctx, subscriber, &caughtUp)
+		if ctx.Err() != nil {
+			r.settings.Logger.Info("Collection context cancelled, stopping")
+			return
+		}
+		r.settings.Logger.Warn("Event subscription failed, subscribing again",
+			zap.Duration("retry_in", r.config.CollectionInterval),
+			zap.Error(err))
+
+		timer := time.NewTimer(r.config.CollectionInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			r.settings.Logger.Info("Collection context cancelled, stopping")
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// subscribe connects if needed and runs one subscription until it fails or
+// ctx is done. caughtUp receives the end of the catch-up window; events up
+// to it were already read with GetRecords and are dropped.
+func (r *opcuaReceiver) subscribe(ctx context.Context, subscriber eventSubscriber, caughtUp *time.Time) error {
+	if r.config.Mode != collectionModePubSub {
+		if err := r.scraper.ensureConnected(ctx); err != nil {
+			return err
+		}
+	}
+
+	catchUp := func(ctx context.Context) error {
+		// Events are only accepted after the backlog, so the windows
+		// bounded by max_catchup_duration are read right after another
+		for {
+			if err := r.collectAndConsume(ctx); err != nil {
+				return fmt.Errorf("failed to catch up with GetRecords: %w", err)
+			}
+			if !r.scraper.catchingUp {
+				break
+			}
+		}
+		*caughtUp = r.scraper.lastCollectTime
+		return nil
+	}
+	switch r.config.Mode {
+	case collectionModeAlarms:
+		// Conditions have no log to catch up with, the client refreshes
+		// the retained conditions instead
+		catchUp = func(context.Context) error { return nil }
+	case collectionModePubSub:
+		// Published DataSets cannot be read again
+		catchUp = func(context.Context) error { return nil }
+	}
+
+	return subscriber.subscribeEvents(ctx, r.config.Subscription, catchUp,
+		func(records []model.LogRecord) {
+			r.consumeEvents(ctx, records, *caughtUp)
+		})
+}
+
+// consumeEvents hands the records of an event notification to the consumers
+// and moves the collection window past them
+func (r *opcuaReceiver) consumeEvents(ctx context.Context, records []model.LogRecord, caughtUp time.Time) {
+	defer releaseRecords(records)
+
+	windowStart := r.scraper.lastCollectTime
+	records = r.scraper.acceptEvents(ctx, records, caughtUp)
+	if len(records) == 0 {
+		return
+	}
+	// Delivery failures are logged and left to the spool
+	_ = r.deliverLogs(ctx, r.consumeRecords(ctx, records, windowStart, r.scraper.lastCollectTime))
+}
+
+// acceptEvents drops the event records up to caughtUp, counts the rest like
+// scraped records and advances the collection window to the newest of them,
+// so that a resubscription catches up from there
+func (s *scraper) acceptEvents(ctx context.Context, records []model.LogRecord, caughtUp time.Time) []model.LogRecord {
+	accepted := records[:0]
+	for _, record := range records {
+		if !record.Timestamp.After(caughtUp) {
+			putAttributeMap(record.Attributes)
+			continue
+		}
+		accepted = append(accepted, record)
+	}
+	clear(records[len(accepted):])
+	if len(accepted) == 0 {
+		return nil
+	}
+
+	s.recordScraped(ctx, accepted)
+	if s.config.DerivedMetrics.Enabled {
+		s.recordSeverityMetrics(ctx, accepted)
+	}
+	reads := make(map[string]*logObjectRead)
+	for _, record := range accepted {
+		if record.Timestamp.After(s.lastCollectTime) {
+			s.lastCollectTime = record.Timestamp
+		}
+		read, ok := reads[record.LogObjectID]
+		if !ok {
+			read = &logObjectRead{logObjectID: record.LogObjectID}
+			reads[record.LogObjectID] = read
+		}
+		read.records++
+	}
+	succeeded := make([]logObjectRead, 0, len(reads))
+	for _, read := range reads {
+		succeeded = append(succeeded, *read)
+	}
+	s.recordSuccess(s.now(), succeeded)
+	return accepted
+}
@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
+)
+
+func TestSubscriptionConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string
+	}{
+		{
+			name:   "poll",
+			modify: func(*Config) {},
+		},
+		{
+			name:   "subscribe",
+			modify: func(cfg *Config) { cfg.Mode = collectionModeSubscribe },
+		},
+		{
+			name:    "unknown mode",
+			modify:  func(cfg *Config) { cfg.Mode = "push" },
+			wantErr: "invalid mode: push",
+		},
+		{
+			name: "zero publishing interval",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModeSubscribe
+				cfg.Subscription.PublishingInterval = 0
+			},
+			wantErr: "publishing_interval must be positive",
+		},
+		{
+			name: "zero queue size",
+			modify: func(cfg *Config) {
+				cfg.Mode = collectionModeSubscribe
+				cfg.Subscription.QueueSize = 0
+			},
+			wantErr: "queue_size must be at least 1",
+		},
+		{
+			name: "subscription ignored when polling",
+			modify: func(cfg *Config) {
+				cfg.Subscription = SubscriptionConfig{}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestLogEventFilter(t *testing.T) {
+	filter := logEventFilter()
+	require.Len(t, filter.SelectClauses, len(logEventFields))
+	for i, clause := range filter.SelectClauses {
+		assert.Equal(t, ua.NewNumericNodeID(0, 2041), clause.TypeDefinitionID)
+		require.Len(t, clause.BrowsePath, 1)
+		assert.Equal(t, logEventFields[i], clause.BrowsePath[0].Name)
+		assert.Equal(t, ua.AttributeIDValue, clause.AttributeID)
+	}
+	assert.Nil(t, filter.WhereClause)
+}
+
+func TestEventRecords(t *testing.T) {
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	logObjectIDs := []*ua.NodeID{ua.NewNumericNodeID(0, 2042), ua.NewNumericNodeID(1, 1000)}
+	fields := func(severity uint16, message string) []*ua.Variant {
+		return []*ua.Variant{
+			ua.MustVariant(t0),
+			ua.MustVariant(severity),
+			ua.MustVariant(&ua.LocalizedText{EncodingMask: ua.LocalizedTextLocale | ua.LocalizedTextText, Locale: "en", Text: message}),
+			ua.MustVariant("Axis1"),
+			ua.MustVariant(ua.NewStringNodeID(2, "Axis1")),
+		}
+	}
+
+	list := &ua.EventNotificationList{Events: []*ua.EventFieldList{
+		{ClientHandle: 1, EventFields: fields(300, "Overtemperature")},
+		{ClientHandle: 0, EventFields: fields(50, "Below min_severity")},
+		{ClientHandle: 2, EventFields: fields(300, "Unknown handle")},
+		{ClientHandle: 0, EventFields: []*ua.Variant{ua.MustVariant(t0), ua.MustVariant(uint16(150)), nil}},
+		nil,
+	}}
+
+	records := eventRecords(list, logObjectIDs, 101)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, t0, records[0].Timestamp)
+	assert.Equal(t, uint16(300), records[0].Severity)
+	assert.Equal(t, "Overtemperature", records[0].Message)
+	assert.Equal(t, "en", records[0].MessageLocale)
+	assert.Equal(t, "Axis1", records[0].SourceName)
+	assert.Equal(t, uint16(2), records[0].SourceNamespace)
+	assert.Equal(t, "String", records[0].SourceIDType)
+	assert.Equal(t, "Axis1", records[0].SourceID)
+	assert.Equal(t, "ns=1;i=1000", records[0].LogObjectID)
+
+	// Fields the event does not have stay empty
+	assert.Equal(t, uint16(150), records[1].Severity)
+	assert.Empty(t, records[1].Message)
+	assert.Empty(t, records[1].MessageLocale)
+	assert.Empty(t, records[1].SourceIDType)
+	assert.Equal(t, "i=2042", records[1].LogObjectID)
+}
+
+// eventClient is a windowClient whose subscription delivers scripted
+// notifications and then fails with err
+type eventClient struct {
+	windowClient
+	notifications [][]model.LogRecord
+	subscribeErr  error
+}
+
+func (c *eventClient) subscribeEvents(ctx context.Context, _ SubscriptionConfig, onSubscribed func(context.Context) error, deliver func([]model.LogRecord)) error {
+	if err := onSubscribed(ctx); err != nil {
+		return err
+	}
+	for _, records := range c.notifications {
+		deliver(records)
+	}
+	c.notifications = nil
+	return c.subscribeErr
+}
+
+func TestReceiverSubscribe(t *testing.T) {
+	ctx := context.Background()
+	t0 := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	event := func(offset time.Duration, message string) model.LogRecord {
+		return model.LogRecord{Timestamp: t0.Add(offset), Severity: 150, Message: message, LogObjectID: "i=2042"}
+	}
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Mode = collectionModeSubscribe
+	client := &eventClient{subscribeErr: errors.New("session lost")}
+	cfg.Client = client
+	r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	sink := new(consumertest.LogsSink)
+	r.nextLogs = sink
+	r.scraper.client = client
+	clk := newFakeClock(t0)
+	r.scraper.clock = clk
+
+	// The first subscription catches up from the start, then drops the
+	// events the catch-up already read
+	client.notifications = [][]model.LogRecord{
+		{event(-time.Second, "read by the catch-up"), event(time.Second, "first event")},
+		{event(2*time.Second, "second event")},
+	}
+	var caughtUp time.Time
+	err = r.subscribe(ctx, client, &caughtUp)
+	require.ErrorContains(t, err, "session lost")
+	assert.Equal(t, t0, caughtUp)
+	require.Len(t, client.windows, 1)
+	assert.True(t, client.windows[0].start.IsZero())
+
+	require.Equal(t, 2, sink.LogRecordCount())
+	var messages []string
+	for _, logs := range sink.AllLogs() {
+		lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := range lrs.Len() {
+			messages = append(messages, lrs.At(i).Body().AsString())
+		}
+	}
+	assert.Equal(t, []string{"first event", "second event"}, messages)
+	assert.Equal(t, t0.Add(2*time.Second), r.scraper.lastCollectTime)
+
+	// Subscribing again catches up from the newest event
+	clk.Advance(time.Minute)
+	client.subscribeErr = nil
+	require.NoError(t, r.subscribe(ctx, client, &caughtUp))
+	require.Len(t, client.windows, 2)
+	assert.Equal(t, t0.Add(2*time.Second), client.windows[1].start)
+	assert.Equal(t, t0.Add(time.Minute), client.windows[1].end)
+
+	// A failed catch-up fails the subscription before any event is delivered
+	client.err = errors.New("server unavailable")
+	client.notifications = [][]model.LogRecord{{event(2*time.Minute, "not delivered")}}
+	err = r.subscribe(ctx, client, &caughtUp)
+	require.ErrorContains(t, err, "failed to catch up with GetRecords")
+	assert.Equal(t, 2, sink.LogRecordCount())
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"bytes"
+	"crypto/x509"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// Identity is the user identity a client presents when it activates a
+// session. It is anonymous when neither Username nor Certificate is set.
+type Identity struct {
+	Username string
+	Password string
+
+	// DER-encoded X.509 certificate of an X509IdentityToken
+	Certificate []byte
+}
+
+// authConfig holds the identities the mock server accepts. The zero value
+// accepts anonymous sessions only.
+type authConfig struct {
+	rejectAnonymous bool
+	users           map[string]string
+	certificates    [][]byte
+	attempts        []AuthAttempt
+}
+
+// AuthAttempt is a recorded session activation and its result
+type AuthAttempt struct {
+	Identity Identity
+	Status   ua.StatusCode
+}
+
+// AddUser accepts UserNameIdentityTokens with the given credentials
+func (s *MockServer) AddUser(username, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.auth.users == nil {
+		s.auth.users = make(map[string]string)
+	}
+	s.auth.users[username] = password
+}
+
+// TrustUserCertificate accepts X509IdentityTokens carrying the given DER-encoded certificate
+func (s *MockServer) TrustUserCertificate(cert []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth.certificates = append(s.auth.certificates, bytes.Clone(cert))
+}
+
+// SetAnonymousAccess sets whether anonymous sessions are accepted, which they are by default
+func (s *MockServer) SetAnonymousAccess(allowed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.auth.rejectAnonymous = !allowed
+}
+
+// Authenticate validates the identity of a session activation as a server
+// does and records the attempt. It returns
+//
+//   - BadIdentityTokenRejected for a token type the server does not accept:
+//     anonymous access is disabled, no users or certificates are configured,
+//     or the certificate is not trusted
+//   - BadIdentityTokenInvalid for a certificate that does not parse
+//   - BadUserAccessDenied for an unknown user or a wrong password
+//
+// MockClient authenticates on Connect; servers that handle ActivateSession on
+// their own call it with the decoded identity token.
+func (s *MockServer) Authenticate(identity Identity) ua.StatusCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.auth.validate(identity)
+	s.auth.attempts = append(s.auth.attempts, AuthAttempt{Identity: identity, Status: status})
+	return status
+}
+
+// AuthAttempts returns every session activation in arrival order
+func (s *MockServer) AuthAttempts() []AuthAttempt {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]AuthAttempt(nil), s.auth.attempts...)
+}
+
+func (a *authConfig) validate(identity Identity) ua.StatusCode {
+	switch {
+	case len(identity.Certificate) > 0:
+		if _, err := x509.ParseCertificate(identity.Certificate); err != nil {
+			return ua.StatusBadIdentityTokenInvalid
+		}
+		for _, trusted := range a.certificates {
+			if bytes.Equal(trusted, identity.Certificate) {
+				return ua.StatusOK
+			}
+		}
+		return ua.StatusBadIdentityTokenRejected
+
+	case identity.Username != "":
+		if len(a.users) == 0 {
+			return ua.StatusBadIdentityTokenRejected
+		}
+		if password, ok := a.users[identity.Username]; !ok || password != identity.Password {
+			return ua.StatusBadUserAccessDenied
+		}
+		return ua.StatusOK
+
+	default:
+		if a.rejectAnonymous {
+			return ua.StatusBadIdentityTokenRejected
+		}
+		return ua.StatusOK
+	}
+}
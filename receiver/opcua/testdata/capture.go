@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// CapturedRequest is a GetRecords call as received by a mock server.
+// Arguments of unexpected type are left at their zero value.
+type CapturedRequest struct {
+	ObjectID          *ua.NodeID
+	MethodID          *ua.NodeID
+	StartTime         time.Time
+	EndTime           time.Time
+	MaxRecords        uint32
+	MinSeverity       uint16
+	LogRecordMask     uint32
+	ContinuationPoint []byte
+}
+
+// CaptureRequest records a GetRecords call so tests can assert on the exact
+// arguments the client sent. MockServer captures its calls itself; servers
+// that handle the Call service on their own call it for every method call.
+func (s *MockServer) CaptureRequest(req *ua.CallMethodRequest) {
+	captured := CapturedRequest{
+		ObjectID: req.ObjectID,
+		MethodID: req.MethodID,
+	}
+	args := req.InputArguments
+	if len(args) > 0 {
+		captured.StartTime, _ = args[0].Value().(time.Time)
+	}
+	if len(args) > 1 {
+		captured.EndTime, _ = args[1].Value().(time.Time)
+	}
+	if len(args) > 2 {
+		captured.MaxRecords, _ = args[2].Value().(uint32)
+	}
+	if len(args) > 3 {
+		captured.MinSeverity, _ = args[3].Value().(uint16)
+	}
+	if len(args) > 4 {
+		captured.LogRecordMask, _ = args[4].Value().(uint32)
+	}
+	if len(args) > 5 {
+		if cp, _ := args[5].Value().([]byte); len(cp) > 0 {
+			captured.ContinuationPoint = append([]byte(nil), cp...)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, captured)
+}
+
+// Requests returns every captured GetRecords call in arrival order
+func (s *MockServer) Requests() []CapturedRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]CapturedRequest(nil), s.requests...)
+}
+
+// RequestsFor returns the captured GetRecords calls on a LogObject in arrival order
+func (s *MockServer) RequestsFor(objectID *ua.NodeID) []CapturedRequest {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var requests []CapturedRequest
+	for _, req := range s.requests {
+		if req.ObjectID != nil && req.ObjectID.Equal(objectID) {
+			requests = append(requests, req)
+		}
+	}
+	return requests
+}
+
+// LastRequest returns the most recently captured GetRecords call
+func (s *MockServer) LastRequest() (CapturedRequest, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.requests) == 0 {
+		return CapturedRequest{}, false
+	}
+	return s.requests[len(s.requests)-1], true
+}
+
+// ClearRequests forgets all captured GetRecords calls
+func (s *MockServer) ClearRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
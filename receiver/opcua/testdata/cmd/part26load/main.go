@@ -0,0 +1,361 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Command part26load drives a Part 26 server's GetRecords method with
+// configurable concurrency, time windows and request masks, and reports latency
+// and error distributions. It is intended to qualify a vendor's LogObject
+// implementation before rolling the receiver out to production.
+//
+// Usage:
+//
+//	go run ./testdata/cmd/part26load -endpoint opc.tcp://plc:4840 -log-object ns=2;i=1000 \
+//	    -concurrency 8 -duration 5m -windows 1m,1h,24h -masks 0x00,0x1F
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+)
+
+// options holds the command line flags
+type options struct {
+	endpoint           string
+	logObject          string
+	method             string
+	concurrency        int
+	duration           time.Duration
+	requests           int
+	windows            []time.Duration
+	masks              []uint32
+	maxRecords         uint
+	minSeverity        uint
+	followContinuation bool
+	requestTimeout     time.Duration
+}
+
+// result is the outcome of a single GetRecords call sequence
+type result struct {
+	latency time.Duration
+	records int
+	pages   int
+	status  string
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	if err := run(ctx, opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// parseFlags parses the command line flags
+func parseFlags(args []string) (*options, error) {
+	fs := flag.NewFlagSet("part26load", flag.ContinueOnError)
+	opts := &options{}
+	var windows, masks string
+
+	fs.StringVar(&opts.endpoint, "endpoint", "opc.tcp://localhost:4840", "OPC UA server endpoint")
+	fs.StringVar(&opts.logObject, "log-object", "i=2042", "NodeId of the LogObject")
+	fs.StringVar(&opts.method, "method", "", "NodeId of the GetRecords method (default: browse the LogObject)")
+	fs.IntVar(&opts.concurrency, "concurrency", 4, "number of concurrent sessions")
+	fs.DurationVar(&opts.duration, "duration", time.Minute, "test duration (ignored when -requests is set)")
+	fs.IntVar(&opts.requests, "requests", 0, "total number of requests to send (0: run for -duration)")
+	fs.StringVar(&windows, "windows", "1h", "comma separated StartTime windows before now, rotated per request (0 for unbounded)")
+	fs.StringVar(&masks, "masks", "0x1F", "comma separated LogRecordMask values, rotated per request")
+	fs.UintVar(&opts.maxRecords, "max-records", 1000, "MaxReturnRecords per call")
+	fs.UintVar(&opts.minSeverity, "min-severity", 1, "MinimumSeverity per call")
+	fs.BoolVar(&opts.followContinuation, "follow-continuation", true, "follow ContinuationPoints until the result set is exhausted")
+	fs.DurationVar(&opts.requestTimeout, "request-timeout", 10*time.Second, "timeout of each Call request")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if opts.concurrency < 1 {
+		return nil, errors.New("-concurrency must be at least 1")
+	}
+	if opts.minSeverity > 1000 {
+		return nil, errors.New("-min-severity must be between 1 and 1000")
+	}
+
+	for _, w := range strings.Split(windows, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(w))
+		if err != nil {
+			return nil, fmt.Errorf("invalid -windows entry %q: %w", w, err)
+		}
+		opts.windows = append(opts.windows, d)
+	}
+
+	for _, m := range strings.Split(masks, ",") {
+		v, err := strconv.ParseUint(strings.TrimSpace(m), 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -masks entry %q: %w", m, err)
+		}
+		opts.masks = append(opts.masks, uint32(v))
+	}
+
+	return opts, nil
+}
+
+// run opens one session per worker and issues GetRecords calls until the
+// duration or request budget is exhausted, then prints a report
+func run(ctx context.Context, opts *options) error {
+	logObjectID, err := ua.ParseNodeID(opts.logObject)
+	if err != nil {
+		return fmt.Errorf("invalid -log-object: %w", err)
+	}
+
+	if opts.requests == 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.duration)
+		defer cancel()
+	}
+
+	var (
+		issued  atomic.Int64
+		mu      sync.Mutex
+		results []result
+		wg      sync.WaitGroup
+	)
+
+	next := func() (int64, bool) {
+		n := issued.Add(1) - 1
+		if opts.requests > 0 && n >= int64(opts.requests) {
+			return n, false
+		}
+		return n, ctx.Err() == nil
+	}
+
+	start := time.Now()
+	errs := make(chan error, opts.concurrency)
+	for w := 0; w < opts.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			local, err := worker(ctx, opts, logObjectID, next)
+			if err != nil {
+				errs <- err
+			}
+			mu.Lock()
+			results = append(results, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	elapsed := time.Since(start)
+
+	for err := range errs {
+		fmt.Fprintln(os.Stderr, "worker failed:", err)
+	}
+
+	printReport(opts, results, elapsed)
+	return nil
+}
+
+// worker runs GetRecords calls on its own session
+func worker(ctx context.Context, opts *options, logObjectID *ua.NodeID, next func() (int64, bool)) ([]result, error) {
+	client, err := opcua.NewClient(opts.endpoint,
+		opcua.SecurityMode(ua.MessageSecurityModeNone),
+		opcua.RequestTimeout(opts.requestTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %w", err)
+	}
+	if err := client.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer client.Close(context.Background())
+
+	methodID, err := resolveMethod(ctx, client, logObjectID, opts.method)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []result
+	for {
+		n, ok := next()
+		if !ok {
+			return results, nil
+		}
+		window := opts.windows[n%int64(len(opts.windows))]
+		mask := opts.masks[n%int64(len(opts.masks))]
+		results = append(results, getRecords(ctx, client, opts, logObjectID, methodID, window, mask))
+	}
+}
+
+// resolveMethod returns the configured GetRecords method or browses the LogObject for it
+func resolveMethod(ctx context.Context, client *opcua.Client, logObjectID *ua.NodeID, method string) (*ua.NodeID, error) {
+	if method != "" {
+		id, err := ua.ParseNodeID(method)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -method: %w", err)
+		}
+		return id, nil
+	}
+
+	resp, err := client.Browse(ctx, &ua.BrowseRequest{
+		NodesToBrowse: []*ua.BrowseDescription{{
+			NodeID:          logObjectID,
+			BrowseDirection: ua.BrowseDirectionForward,
+			ReferenceTypeID: ua.NewNumericNodeID(0, 47), // HasComponent
+			IncludeSubtypes: true,
+			NodeClassMask:   uint32(ua.NodeClassMethod),
+			ResultMask:      uint32(ua.BrowseResultMaskBrowseName),
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to browse LogObject: %w", err)
+	}
+	if len(resp.Results) > 0 && resp.Results[0].StatusCode == ua.StatusOK {
+		for _, ref := range resp.Results[0].References {
+			if ref.BrowseName.Name == "GetRecords" {
+				return ref.NodeID.NodeID, nil
+			}
+		}
+	}
+
+	// Fall back to the standard GetRecords method of the LogObjectType
+	return ua.NewNumericNodeID(0, 11550), nil
+}
+
+// getRecords performs one GetRecords call, following continuation points if requested
+func getRecords(ctx context.Context, client *opcua.Client, opts *options, logObjectID, methodID *ua.NodeID, window time.Duration, mask uint32) result {
+	end := time.Now()
+	var startTime time.Time
+	if window > 0 {
+		startTime = end.Add(-window)
+	}
+
+	res := result{status: ua.StatusOK.Error()}
+	begin := time.Now()
+	var continuationPoint []byte
+	for {
+		req := &ua.CallMethodRequest{
+			ObjectID: logObjectID,
+			MethodID: methodID,
+			InputArguments: []*ua.Variant{
+				ua.MustVariant(startTime),
+				ua.MustVariant(end),
+				ua.MustVariant(uint32(opts.maxRecords)),  //nolint:gosec
+				ua.MustVariant(uint16(opts.minSeverity)), //nolint:gosec
+				ua.MustVariant(mask),
+				ua.MustVariant(continuationPoint),
+			},
+		}
+
+		out, err := client.Call(ctx, req)
+		if err != nil {
+			res.status = callErrorStatus(err)
+			break
+		}
+		if out.StatusCode != ua.StatusOK {
+			res.status = out.StatusCode.Error()
+			break
+		}
+		res.pages++
+		if len(out.OutputArguments) < 2 {
+			res.status = "malformed output arguments"
+			break
+		}
+		if objects, ok := out.OutputArguments[0].Value().([]*ua.ExtensionObject); ok {
+			res.records += len(objects)
+		}
+
+		cp, _ := out.OutputArguments[1].Value().([]byte)
+		if !opts.followContinuation || len(cp) == 0 {
+			break
+		}
+		continuationPoint = cp
+	}
+	res.latency = time.Since(begin)
+	return res
+}
+
+// callErrorStatus returns the label an error is counted under in the report
+func callErrorStatus(err error) string {
+	var status ua.StatusCode
+	if errors.As(err, &status) {
+		return status.Error()
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return "cancelled"
+	}
+	return "transport error"
+}
+
+// printReport prints the request, latency and error distributions
+func printReport(opts *options, results []result, elapsed time.Duration) {
+	statuses := make(map[string]int)
+	latencies := make([]time.Duration, 0, len(results))
+	records, pages := 0, 0
+	for _, r := range results {
+		statuses[r.status]++
+		latencies = append(latencies, r.latency)
+		records += r.records
+		pages += r.pages
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	fmt.Printf("endpoint:     %s\n", opts.endpoint)
+	fmt.Printf("log object:   %s\n", opts.logObject)
+	fmt.Printf("concurrency:  %d\n", opts.concurrency)
+	fmt.Printf("elapsed:      %s\n", elapsed.Round(time.Millisecond))
+	fmt.Printf("requests:     %d (%.1f/s)\n", len(results), float64(len(results))/elapsed.Seconds())
+	fmt.Printf("pages:        %d\n", pages)
+	fmt.Printf("records:      %d (%.1f/s)\n", records, float64(records)/elapsed.Seconds())
+
+	if len(latencies) > 0 {
+		fmt.Println("latency:")
+		for _, p := range []float64{0.5, 0.9, 0.99, 1} {
+			fmt.Printf("  p%-5s %s\n", strconv.FormatFloat(p*100, 'f', -1, 64), percentile(latencies, p).Round(time.Microsecond))
+		}
+	}
+
+	fmt.Println("status:")
+	names := make([]string, 0, len(statuses))
+	for name := range statuses {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-40s %d\n", name, statuses[name])
+	}
+}
+
+// percentile returns the p-quantile of sorted latencies using the nearest-rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// ContinuationPointExpiry configures when continuation points issued by the
+// mock server become invalid, so clients see BadContinuationPointInvalid
+type ContinuationPointExpiry struct {
+	// MaxCalls is the number of GetRecords calls, counted from the call that
+	// issued it, during which a continuation point stays valid. With MaxCalls
+	// set to 1 only the immediately following call may use it. Zero disables
+	// the limit.
+	MaxCalls int `yaml:"max_calls"`
+
+	// TTL is how long a continuation point stays valid after it was issued.
+	// Zero disables the limit.
+	TTL time.Duration `yaml:"ttl"`
+}
+
+// continuationPoint records for which LogObject and when a continuation point was issued
+type continuationPoint struct {
+	objectKey  string
+	issuedCall int
+	issuedAt   time.Time
+}
+
+// SetContinuationPointExpiry sets when issued continuation points become invalid
+func (s *MockServer) SetContinuationPointExpiry(expiry ContinuationPointExpiry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cpExpiry = expiry
+}
+
+// SetMaxPageSize caps the number of records per GetRecords response below the
+// requested MaxReturnRecords, as servers with small message size limits do.
+// The rest of the result is served through continuation points. Zero removes
+// the cap.
+func (s *MockServer) SetMaxPageSize(n uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxPageSize = n
+}
+
+// SetMaxResponseRecords rejects GetRecords calls whose response would hold
+// more than n records with BadResponseTooLarge, as servers do whose encoded
+// response exceeds their message size limit. The continuation point of a
+// rejected call stays valid. Zero removes the limit.
+func (s *MockServer) SetMaxResponseRecords(n uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxResponseRecords = n
+}
+
+// InvalidateContinuationPoints releases all outstanding continuation points,
+// as a server does when it runs out of resources or restarts
+func (s *MockServer) InvalidateContinuationPoints() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.continuationPoints = make(map[string]continuationPoint)
+}
+
+// issueContinuationPoint registers a continuation point for the given record
+// offset of a LogObject. A sequence number keeps points for the same offset
+// distinct. The caller must hold s.mu.
+func (s *MockServer) issueContinuationPoint(objectKey string, offset int) []byte {
+	s.cpSequence++
+	cp := make([]byte, 8)
+	binary.LittleEndian.PutUint32(cp[0:4], uint32(offset))       //nolint:gosec
+	binary.LittleEndian.PutUint32(cp[4:8], uint32(s.cpSequence)) //nolint:gosec
+	s.continuationPoints[string(cp)] = continuationPoint{
+		objectKey:  objectKey,
+		issuedCall: s.calls,
+		issuedAt:   time.Now(),
+	}
+	return cp
+}
+
+// consumeContinuationPoint validates a continuation point for a LogObject and
+// releases it, returning the record offset it points to. The caller must hold s.mu.
+func (s *MockServer) consumeContinuationPoint(objectKey string, cp []byte) (int, bool) {
+	state, ok := s.continuationPoints[string(cp)]
+	if !ok || len(cp) < 4 || state.objectKey != objectKey {
+		return 0, false
+	}
+	delete(s.continuationPoints, string(cp))
+
+	if s.cpExpiry.MaxCalls > 0 && s.calls-state.issuedCall > s.cpExpiry.MaxCalls {
+		return 0, false
+	}
+	if s.cpExpiry.TTL > 0 && time.Since(state.issuedAt) > s.cpExpiry.TTL {
+		return 0, false
+	}
+	return int(binary.LittleEndian.Uint32(cp[0:4])), true
+}
@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"go.uber.org/zap"
+)
+
+// EventNotification is a LogRecordEventType notification pushed to a subscriber.
+// Sequence numbers are consecutive per subscription; a jump means notifications
+// were lost and must be recovered with GetRecords.
+type EventNotification struct {
+	SequenceNumber uint32
+	Record         OPCUALogRecord
+}
+
+// EventSubscription receives the event notifications of one LogObject
+type EventSubscription struct {
+	server    *MockServer
+	objectKey string
+	ch        chan EventNotification
+	sequence  uint32
+	closeOnce sync.Once
+}
+
+// C returns the channel notifications are delivered on. It is closed when the
+// subscription or the server is closed.
+func (sub *EventSubscription) C() <-chan EventNotification {
+	return sub.ch
+}
+
+// Close removes the subscription from the server
+func (sub *EventSubscription) Close() {
+	sub.server.mu.Lock()
+	defer sub.server.mu.Unlock()
+	sub.server.removeSubscription(sub)
+}
+
+// SubscribeEvents subscribes to the event notifications of a LogObject. Up to
+// queueSize notifications are buffered; further notifications are dropped
+// while the queue is full, like a monitored item queue overflowing.
+func (s *MockServer) SubscribeEvents(objectID *ua.NodeID, queueSize int) (*EventSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return nil, fmt.Errorf("unknown log object %s", objectID)
+	}
+	sub := &EventSubscription{
+		server:    s,
+		objectKey: obj.ObjectID.String(),
+		ch:        make(chan EventNotification, queueSize),
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+	return sub, nil
+}
+
+// DropNextEvents makes the server lose the next n notifications of every
+// subscription. The records are still stored, so gap-fill via GetRecords can
+// recover them.
+func (s *MockServer) DropNextEvents(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropEvents = n
+}
+
+// PublishEvents stores records on a LogObject and pushes them to its subscribers
+func (s *MockServer) PublishEvents(objectID *ua.NodeID, records ...OPCUALogRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return fmt.Errorf("unknown log object %s", objectID)
+	}
+	if obj.stream != nil {
+		return fmt.Errorf("log object %s is served from a record stream", objectID)
+	}
+	obj.append(records...)
+
+	objectKey := obj.ObjectID.String()
+	for _, record := range records {
+		drop := s.dropEvents > 0
+		if drop {
+			s.dropEvents--
+		}
+		for _, sub := range s.subscriptions {
+			if sub.objectKey != objectKey {
+				continue
+			}
+			sub.sequence++
+			if drop {
+				continue
+			}
+			select {
+			case sub.ch <- EventNotification{SequenceNumber: sub.sequence, Record: record}:
+			default:
+			}
+		}
+	}
+	return nil
+}
+
+// PublishEvery publishes the records returned by produce on a LogObject every
+// interval until ctx is done or the server is stopped. tick counts from zero.
+func (s *MockServer) PublishEvery(ctx context.Context, objectID *ua.NodeID, interval time.Duration, produce func(tick int) []OPCUALogRecord) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for tick := 0; ; tick++ {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if !s.IsRunning() {
+				return
+			}
+			if err := s.PublishEvents(objectID, produce(tick)...); err != nil {
+				s.logger.Warn("Mock server failed to publish events", zap.Error(err))
+				return
+			}
+		}
+	}()
+}
+
+// removeSubscription closes a subscription and forgets it. The caller must hold s.mu.
+func (s *MockServer) removeSubscription(sub *EventSubscription) {
+	for i, existing := range s.subscriptions {
+		if existing == sub {
+			s.subscriptions = append(s.subscriptions[:i], s.subscriptions[i+1:]...)
+			break
+		}
+	}
+	sub.closeOnce.Do(func() { close(sub.ch) })
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// RecordEncoder encodes a record as a LogRecord ExtensionObject. The codec
+// lives in the receiver package, which this package cannot import, so tests
+// supply it.
+type RecordEncoder func(OPCUALogRecord) (*ua.ExtensionObject, error)
+
+// RecordDecoder decodes a LogRecord ExtensionObject returned by the server
+type RecordDecoder func(*ua.ExtensionObject) (OPCUALogRecord, error)
+
+// SetRecordEncoder makes GetRecords return an array of ExtensionObjects built
+// by enc, as a real Part 26 server does, instead of an array of maps. Passing
+// nil restores the map format.
+func (s *MockServer) SetRecordEncoder(enc RecordEncoder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.encoder = enc
+}
+
+// Call executes a CallMethodRequest the way the Call service would, so the
+// receiver's response parsing can be driven without a socket
+func (s *MockServer) Call(ctx context.Context, req *ua.CallMethodRequest) (*ua.CallMethodResult, error) {
+	return s.callHandler(ctx, req)
+}
+
+// SetRecordDecoder lets the client read ExtensionObject responses produced by
+// a server with a record encoder
+func (c *MockClient) SetRecordDecoder(dec RecordDecoder) {
+	c.decoder = dec
+}
+
+// encodeRecords builds the ExtensionObject array for a GetRecords response
+func encodeRecords(enc RecordEncoder, records []OPCUALogRecord) (*ua.Variant, error) {
+	objects := make([]*ua.ExtensionObject, len(records))
+	for i, record := range records {
+		obj, err := enc(record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode record %d: %w", i, err)
+		}
+		objects[i] = obj
+	}
+	return ua.MustVariant(objects), nil
+}
+
+// decodeRecords decodes an ExtensionObject array, skipping records that fail
+// to decode like the receiver does
+func decodeRecords(dec RecordDecoder, objects []*ua.ExtensionObject) []OPCUALogRecord {
+	var records []OPCUALogRecord
+	for _, obj := range objects {
+		if obj == nil {
+			continue
+		}
+		record, err := dec(obj)
+		if err != nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// FaultKind selects the failure a mock server injects into a GetRecords call
+type FaultKind int
+
+const (
+	// FaultTimeout holds the response until the request context is done or
+	// Fault.Delay has passed, whichever comes first
+	FaultTimeout FaultKind = iota + 1
+	// FaultTooManyOperations rejects the call with BadTooManyOperations
+	FaultTooManyOperations
+	// FaultSecureChannelClosed rejects the call with BadSecureChannelClosed, as
+	// seen when the channel drops in the middle of a paginated read
+	FaultSecureChannelClosed
+	// FaultMalformedRecord corrupts the body of the first returned record
+	FaultMalformedRecord
+	// FaultSlowResponse delays an otherwise normal response by Fault.Delay
+	FaultSlowResponse
+	// FaultStatus fails the call with Fault.Status
+	FaultStatus
+	// FaultConnectionDrop closes the client's TCP connection instead of
+	// answering. Servers without a connection to drop, such as MockServer,
+	// fail the Call service with BadConnectionClosed.
+	FaultConnectionDrop
+)
+
+// String returns the name of the fault kind
+func (k FaultKind) String() string {
+	switch k {
+	case FaultTimeout:
+		return "Timeout"
+	case FaultTooManyOperations:
+		return "TooManyOperations"
+	case FaultSecureChannelClosed:
+		return "SecureChannelClosed"
+	case FaultMalformedRecord:
+		return "MalformedRecord"
+	case FaultSlowResponse:
+		return "SlowResponse"
+	case FaultStatus:
+		return "Status"
+	case FaultConnectionDrop:
+		return "ConnectionDrop"
+	default:
+		return "None"
+	}
+}
+
+// ParseFaultKind returns the fault kind with the given name, as returned by String
+func ParseFaultKind(name string) (FaultKind, error) {
+	for k := FaultTimeout; k <= FaultConnectionDrop; k++ {
+		if k.String() == name {
+			return k, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown fault kind %q", name)
+}
+
+// Fault describes a failure injected into GetRecords calls
+type Fault struct {
+	Kind FaultKind
+
+	// Call is the 1-based number of the GetRecords call the fault applies to.
+	// Zero applies the fault to every call.
+	Call int
+
+	// LogObject restricts the fault to calls on the given LogObject. Nil
+	// applies the fault to calls on any LogObject.
+	LogObject *ua.NodeID
+
+	// Delay is the response delay for FaultSlowResponse and the maximum hold
+	// time for FaultTimeout. Servers without a request context (such as a
+	// wire-protocol server) must set it above the client's request timeout.
+	Delay time.Duration
+
+	// Status is the result of calls failed by FaultStatus
+	Status ua.StatusCode
+}
+
+// Wait blocks for the duration the fault holds the response. It returns the
+// context error if ctx is done first.
+func (f Fault) Wait(ctx context.Context) error {
+	var timer <-chan time.Time
+	switch f.Kind {
+	case FaultSlowResponse:
+		if f.Delay <= 0 {
+			return nil
+		}
+		timer = time.After(f.Delay)
+	case FaultTimeout:
+		if f.Delay > 0 {
+			timer = time.After(f.Delay)
+		}
+	default:
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer:
+		return nil
+	}
+}
+
+// InjectFault schedules a fault for GetRecords calls
+func (s *MockServer) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, f)
+}
+
+// ClearFaults removes all scheduled faults
+func (s *MockServer) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// CallCount returns the number of GetRecords calls handled so far
+func (s *MockServer) CallCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.calls
+}
+
+// NextFault counts a GetRecords call on a LogObject, adds the record batches
+// scheduled for it and returns the fault to inject into it. Faults scheduled
+// for a specific call take precedence over faults for every call.
+func (s *MockServer) NextFault(objectID *ua.NodeID) (Fault, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	s.addScheduledRecords(s.calls)
+
+	var always *Fault
+	for i := range s.faults {
+		f := s.faults[i]
+		if f.LogObject != nil && !f.LogObject.Equal(objectID) {
+			continue
+		}
+		if f.Call == s.calls {
+			return f, true
+		}
+		if f.Call == 0 && always == nil {
+			always = &s.faults[i]
+		}
+	}
+	if always != nil {
+		return *always, true
+	}
+	return Fault{}, false
+}
@@ -38,36 +38,119 @@ var messages = []string{
 	"Critical: system overload",
 }
 
-// GenerateSampleLogRecord creates a random log record for testing
-func GenerateSampleLogRecord(seed int) OPCUALogRecord {
-	r := rand.New(rand.NewSource(time.Now().UnixNano() + int64(seed)))
+// Severities and messages split into the Part 26 informational and error
+// ranges used by the distribution generators
+var (
+	infoSeverities  = []uint16{75, 125, 175}
+	errorSeverities = []uint16{225, 275, 350, 500}
+	infoMessages    = messages[:5]
+	errorMessages   = messages[5:]
+)
+
+// Generator produces reproducible log records from an explicit random source.
+// Timestamps are relative to a fixed base time so the same seed and base yield
+// identical records. A Generator is not safe for concurrent use.
+type Generator struct {
+	rand *rand.Rand
+	base time.Time
+}
+
+// NewGenerator returns a Generator drawing from src with timestamps relative to base
+func NewGenerator(src rand.Source, base time.Time) *Generator {
+	return &Generator{rand: rand.New(src), base: base}
+}
+
+// NewSeededGenerator returns a Generator seeded with seed and timestamps relative to base
+func NewSeededGenerator(seed int64, base time.Time) *Generator {
+	return NewGenerator(rand.NewSource(seed), base)
+}
+
+// Record creates a random record within the hour before the base time
+func (g *Generator) Record(index int) OPCUALogRecord {
+	severity := severities[g.rand.Intn(len(severities))]
+	timestamp := g.base.Add(-time.Duration(g.rand.Intn(3600)) * time.Second)
+	return g.record(index, timestamp, severity, messages[g.rand.Intn(len(messages))])
+}
+
+// Records creates count random records within the hour before the base time
+func (g *Generator) Records(count int) []OPCUALogRecord {
+	records := make([]OPCUALogRecord, count)
+	for i := range records {
+		records[i] = g.Record(i)
+	}
+	return records
+}
+
+// SteadyInfo creates count informational records spaced interval apart,
+// starting at the base time, like the background noise of a healthy server
+func (g *Generator) SteadyInfo(count int, interval time.Duration) []OPCUALogRecord {
+	records := make([]OPCUALogRecord, count)
+	for i := range records {
+		severity := infoSeverities[g.rand.Intn(len(infoSeverities))]
+		message := infoMessages[g.rand.Intn(len(infoMessages))]
+		records[i] = g.record(i, g.base.Add(time.Duration(i)*interval), severity, message)
+	}
+	return records
+}
 
-	severity := severities[r.Intn(len(severities))]
+// BurstyErrors creates count records spaced interval apart, starting at the
+// base time. Each record starts a burst of burstLen consecutive error records
+// with probability burstProbability; all other records are informational.
+func (g *Generator) BurstyErrors(count int, interval time.Duration, burstProbability float64, burstLen int) []OPCUALogRecord {
+	records := make([]OPCUALogRecord, count)
+	remaining := 0
+	for i := range records {
+		if remaining == 0 && g.rand.Float64() < burstProbability {
+			remaining = burstLen
+		}
+
+		timestamp := g.base.Add(time.Duration(i) * interval)
+		if remaining > 0 {
+			remaining--
+			severity := errorSeverities[g.rand.Intn(len(errorSeverities))]
+			message := errorMessages[g.rand.Intn(len(errorMessages))]
+			records[i] = g.record(i, timestamp, severity, message)
+			continue
+		}
+		severity := infoSeverities[g.rand.Intn(len(infoSeverities))]
+		records[i] = g.record(i, timestamp, severity, infoMessages[g.rand.Intn(len(infoMessages))])
+	}
+	return records
+}
 
-	src := sourceNodes[r.Intn(len(sourceNodes))]
+// record fills in the source, trace context and attributes of a record
+func (g *Generator) record(index int, timestamp time.Time, severity uint16, message string) OPCUALogRecord {
+	src := sourceNodes[g.rand.Intn(len(sourceNodes))]
 	return OPCUALogRecord{
-		Timestamp:       time.Now().Add(-time.Duration(r.Intn(3600)) * time.Second),
+		Timestamp:       timestamp,
 		Severity:        severity,
-		Message:         messages[r.Intn(len(messages))],
+		Message:         message,
 		SourceName:      src.Name,
 		SourceNamespace: src.Namespace,
 		SourceIDType:    "Numeric",
 		SourceID:        fmt.Sprintf("%d", src.ID),
-		TraceID:         fmt.Sprintf("%032x", r.Int63()),
-		SpanID:          fmt.Sprintf("%016x", r.Int63()),
-		TraceFlags:      byte(r.Intn(2)), // 0 or 1
+		TraceID:         fmt.Sprintf("%032x", g.rand.Int63()),
+		SpanID:          fmt.Sprintf("%016x", g.rand.Int63()),
+		TraceFlags:      byte(g.rand.Intn(2)), // 0 or 1
 		Attributes: map[string]interface{}{
 			"component": "test",
 			"version":   "1.0.0",
-			"index":     seed,
+			"index":     index,
 		},
 	}
 }
 
+// GenerateSampleLogRecord creates a random log record for testing. The record
+// content is derived from seed only; the timestamp lies in the hour before now.
+func GenerateSampleLogRecord(seed int) OPCUALogRecord {
+	return NewSeededGenerator(int64(seed), time.Now()).Record(seed)
+}
+
 // GenerateLogRecordWithDetails creates a log record with specific values.
 // sourceName is used as opcua.source.name; namespace and numeric id default to 1/100.
+// The trace context is derived from the timestamp.
 func GenerateLogRecordWithDetails(timestamp time.Time, severity uint16, message, sourceName string) OPCUALogRecord {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	r := rand.New(rand.NewSource(timestamp.UnixNano()))
 
 	return OPCUALogRecord{
 		Timestamp:       timestamp,
@@ -0,0 +1,124 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"fmt"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+var (
+	// DefaultLogObjectID is the standard ServerLog object every MockServer hosts
+	DefaultLogObjectID = ua.NewNumericNodeID(0, 2042)
+
+	// DefaultGetRecordsMethodID is the GetRecords method of the default ServerLog object
+	DefaultGetRecordsMethodID = ua.NewNumericNodeID(0, 11550)
+)
+
+// LogObject describes a LogObject node hosted by a MockServer
+type LogObject struct {
+	ObjectID *ua.NodeID
+	MethodID *ua.NodeID
+}
+
+// logObject is a LogObject node with its own record store, or a lazily
+// produced stream of records, see stream.go
+type logObject struct {
+	LogObject
+	records []OPCUALogRecord
+	stream  RecordStream
+
+	// Ring buffer bound, see ringbuffer.go. evicted counts the records dropped
+	// so continuation points survive evictions.
+	capacity int
+	evicted  int
+}
+
+// source returns the records of the LogObject and whether they are known to
+// be in timestamp order
+func (o *logObject) source() (RecordStream, bool) {
+	if o.stream != nil {
+		return o.stream, true
+	}
+	return sliceStream(o.records), false
+}
+
+// AddLogObject adds a LogObject node with its own record store and GetRecords method
+func (s *MockServer) AddLogObject(objectID, methodID *ua.NodeID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findLogObject(objectID) != nil {
+		return fmt.Errorf("log object %s already exists", objectID)
+	}
+	s.logObjects = append(s.logObjects, &logObject{
+		LogObject: LogObject{ObjectID: objectID, MethodID: methodID},
+		records:   make([]OPCUALogRecord, 0),
+	})
+	return nil
+}
+
+// SetDefaultLogObject moves the default ServerLog, together with its records,
+// to the given NodeIds. Servers that host their LogObject in a vendor
+// namespace use it so AddLogRecord and friends keep working unchanged.
+func (s *MockServer) SetDefaultLogObject(objectID, methodID *ua.NodeID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logObjects[0].ObjectID = objectID
+	s.logObjects[0].MethodID = methodID
+}
+
+// LogObjects returns the LogObject nodes hosted by the server, starting with the default ServerLog
+func (s *MockServer) LogObjects() []LogObject {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := make([]LogObject, len(s.logObjects))
+	for i, obj := range s.logObjects {
+		objects[i] = obj.LogObject
+	}
+	return objects
+}
+
+// AddLogRecordsTo adds log records to the store of the given LogObject
+func (s *MockServer) AddLogRecordsTo(objectID *ua.NodeID, records ...OPCUALogRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return fmt.Errorf("unknown log object %s", objectID)
+	}
+	if obj.stream != nil {
+		return fmt.Errorf("log object %s is served from a record stream", objectID)
+	}
+	obj.append(records...)
+	return nil
+}
+
+// LogRecordsCountOf returns the number of records stored for the given LogObject
+func (s *MockServer) LogRecordsCountOf(objectID *ua.NodeID) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if obj := s.findLogObject(objectID); obj != nil {
+		src, _ := obj.source()
+		return src.Len()
+	}
+	return 0
+}
+
+// findLogObject returns the LogObject with the given NodeId. The caller must hold s.mu.
+func (s *MockServer) findLogObject(objectID *ua.NodeID) *logObject {
+	if objectID == nil {
+		return nil
+	}
+	for _, obj := range s.logObjects {
+		if obj.ObjectID.Equal(objectID) {
+			return obj
+		}
+	}
+	return nil
+}
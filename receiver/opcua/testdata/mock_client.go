@@ -18,6 +18,12 @@ type MockClient struct {
 	server *MockServer
 	logger *zap.Logger
 
+	// Decodes ExtensionObject responses, see extobj.go
+	decoder RecordDecoder
+
+	// Identity presented on Connect, anonymous by default
+	identity Identity
+
 	connected bool
 }
 
@@ -43,12 +49,21 @@ func (c *MockClient) Connect(ctx context.Context) error {
 		return fmt.Errorf("server is not running")
 	}
 
+	if status := c.server.Authenticate(c.identity); status != ua.StatusOK {
+		return fmt.Errorf("failed to activate session: %w", status)
+	}
+
 	c.connected = true
 	c.logger.Debug("Mock client connected", zap.String("endpoint", c.server.Endpoint()))
 
 	return nil
 }
 
+// SetIdentity sets the user identity presented to the server on Connect
+func (c *MockClient) SetIdentity(identity Identity) {
+	c.identity = identity
+}
+
 // Disconnect simulates disconnecting from the server
 func (c *MockClient) Disconnect(ctx context.Context) error {
 	if !c.connected {
@@ -78,12 +93,26 @@ func (c *MockClient) GetRecordsWithSeverity(
 	maxRecords int,
 	minSeverity uint16,
 	continuationPoint []byte,
+) ([]OPCUALogRecord, []byte, error) {
+	return c.GetRecordsFrom(ctx, LogObject{ObjectID: DefaultLogObjectID, MethodID: DefaultGetRecordsMethodID},
+		startTime, endTime, maxRecords, minSeverity, continuationPoint)
+}
+
+// GetRecordsFrom simulates calling the GetRecords method of a specific LogObject
+func (c *MockClient) GetRecordsFrom(
+	ctx context.Context,
+	logObject LogObject,
+	startTime, endTime time.Time,
+	maxRecords int,
+	minSeverity uint16,
+	continuationPoint []byte,
 ) ([]OPCUALogRecord, []byte, error) {
 	if !c.connected {
 		return nil, nil, fmt.Errorf("not connected to server")
 	}
 
 	c.logger.Debug("Mock client GetRecords called",
+		zap.Stringer("log_object", logObject.ObjectID),
 		zap.Time("start_time", startTime),
 		zap.Time("end_time", endTime),
 		zap.Int("max_records", maxRecords),
@@ -91,8 +120,8 @@ func (c *MockClient) GetRecordsWithSeverity(
 
 	// Create a CallMethodRequest
 	req := &ua.CallMethodRequest{
-		ObjectID: ua.NewNumericNodeID(0, 2042),  // Server object
-		MethodID: ua.NewNumericNodeID(0, 11550), // GetRecords method
+		ObjectID: logObject.ObjectID,
+		MethodID: logObject.MethodID,
 		InputArguments: []*ua.Variant{
 			ua.MustVariant(startTime),
 			ua.MustVariant(endTime),
@@ -122,13 +151,19 @@ func (c *MockClient) GetRecordsWithSeverity(
 	recordsValue := result.OutputArguments[0].Value()
 	var records []OPCUALogRecord
 
-	if recordMaps, ok := recordsValue.([]interface{}); ok {
-		for _, recordMap := range recordMaps {
+	switch v := recordsValue.(type) {
+	case []interface{}:
+		for _, recordMap := range v {
 			if m, ok := recordMap.(map[string]interface{}); ok {
 				record := parseRecordMap(m)
 				records = append(records, record)
 			}
 		}
+	case []*ua.ExtensionObject:
+		if c.decoder == nil {
+			return nil, nil, fmt.Errorf("received %d ExtensionObject records without a record decoder", len(v))
+		}
+		records = decodeRecords(c.decoder, v)
 	}
 
 	// Extract continuation point from second output argument
@@ -175,6 +210,9 @@ func parseRecordMap(m map[string]interface{}) OPCUALogRecord {
 		if spanID, ok := traceCtx["SpanId"].(string); ok {
 			record.SpanID = spanID
 		}
+		if parentSpanID, ok := traceCtx["ParentSpanId"].(string); ok {
+			record.ParentSpanID = parentSpanID
+		}
 		if flags, ok := traceCtx["TraceFlags"].(byte); ok {
 			record.TraceFlags = flags
 		}
@@ -6,6 +6,7 @@ package testdata
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -20,9 +21,44 @@ type MockServer struct {
 	logger   *zap.Logger
 
 	mu      sync.RWMutex
-	records []OPCUALogRecord
 	running bool
 
+	// Hosted LogObject nodes, see logobjects.go. The first is the default ServerLog.
+	logObjects []*logObject
+
+	// Simulated link to the client, see network.go
+	network NetworkConditions
+
+	// Fault injection, see faults.go
+	faults []Fault
+	calls  int
+
+	// Issued continuation points, see continuation.go
+	continuationPoints map[string]continuationPoint
+	cpSequence         int
+	cpExpiry           ContinuationPointExpiry
+	maxPageSize        uint32
+	maxResponseRecords uint32
+
+	// Record batches added before the numbered call, see scenario.go
+	scheduledRecords map[int][]scheduledBatch
+
+	// Encodes records as ExtensionObjects when set, see extobj.go
+	encoder RecordEncoder
+
+	// Captured GetRecords calls, see capture.go
+	requests []CapturedRequest
+
+	// Recorded results served per LogObject, see replay.go
+	replay map[string][]ReplayedCall
+
+	// Accepted user identities, see auth.go
+	auth authConfig
+
+	// Event subscriptions, see events.go
+	subscriptions []*EventSubscription
+	dropEvents    int
+
 	// For simulation
 	callHandler func(ctx context.Context, req *ua.CallMethodRequest) (*ua.CallMethodResult, error)
 }
@@ -39,7 +75,12 @@ func NewMockServer(endpoint string, logger *zap.Logger) *MockServer {
 	srv := &MockServer{
 		endpoint: endpoint,
 		logger:   logger,
-		records:  make([]OPCUALogRecord, 0),
+		logObjects: []*logObject{{
+			LogObject: LogObject{ObjectID: DefaultLogObjectID, MethodID: DefaultGetRecordsMethodID},
+			records:   make([]OPCUALogRecord, 0),
+		}},
+
+		continuationPoints: make(map[string]continuationPoint),
 	}
 
 	// Set up the default call handler for GetRecords method
@@ -73,51 +114,87 @@ func (s *MockServer) Stop(ctx context.Context) error {
 	}
 
 	s.running = false
+	for len(s.subscriptions) > 0 {
+		s.removeSubscription(s.subscriptions[0])
+	}
 	s.logger.Info("Mock OPC UA server stopped")
 
 	return nil
 }
 
-// AddLogRecord adds a log record to the server's storage
+// AddLogRecord adds a log record to the default ServerLog's storage
 func (s *MockServer) AddLogRecord(record OPCUALogRecord) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.records = append(s.records, record)
+	s.logObjects[0].append(record)
 }
 
-// AddLogRecords adds multiple log records
+// AddLogRecords adds multiple log records to the default ServerLog
 func (s *MockServer) AddLogRecords(records []OPCUALogRecord) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.records = append(s.records, records...)
+	s.logObjects[0].append(records...)
 }
 
-// ClearLogRecords clears all stored log records
+// ClearLogRecords clears the stored log records and record streams of every LogObject
 func (s *MockServer) ClearLogRecords() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.records = make([]OPCUALogRecord, 0)
+	for _, obj := range s.logObjects {
+		obj.records = make([]OPCUALogRecord, 0)
+		obj.stream = nil
+		obj.evicted = 0
+	}
 }
 
-// GetLogRecordsCount returns the number of stored log records
+// GetLogRecordsCount returns the number of log records stored for the default ServerLog
 func (s *MockServer) GetLogRecordsCount() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return len(s.records)
+	src, _ := s.logObjects[0].source()
+	return src.Len()
 }
 
 // defaultCallHandler handles OPC UA Call method requests
 func (s *MockServer) defaultCallHandler(ctx context.Context, req *ua.CallMethodRequest) (*ua.CallMethodResult, error) {
 	s.logger.Debug("Mock server handling Call request",
-		zap.String("method_id", req.MethodID.String()))
+		zap.Stringer("object_id", req.ObjectID),
+		zap.Stringer("method_id", req.MethodID))
+
+	s.CaptureRequest(req)
+
+	if replayed, ok := s.NextReplay(req.ObjectID); ok {
+		if replayed.Result == nil {
+			return nil, replayed.ServiceStatus
+		}
+		return replayed.Result, nil
+	}
 
-	// Check if this is a GetRecords call (method ID 11550)
-	if req.MethodID.IntID() != 11550 {
+	// Check that this is the GetRecords method of a hosted LogObject
+	if status := s.CheckMethod(req.ObjectID, req.MethodID); status != ua.StatusOK {
 		return &ua.CallMethodResult{
-			StatusCode: ua.StatusBadMethodInvalid,
+			StatusCode: status,
 		}, nil
 	}
 
+	fault, faulty := s.NextFault(req.ObjectID)
+	if faulty {
+		s.logger.Debug("Mock server injecting fault", zap.Stringer("fault", fault.Kind))
+		if err := fault.Wait(ctx); err != nil {
+			return nil, err
+		}
+		switch fault.Kind {
+		case FaultTooManyOperations:
+			return &ua.CallMethodResult{StatusCode: ua.StatusBadTooManyOperations}, nil
+		case FaultSecureChannelClosed:
+			return &ua.CallMethodResult{StatusCode: ua.StatusBadSecureChannelClosed}, nil
+		case FaultStatus:
+			return &ua.CallMethodResult{StatusCode: fault.Status}, nil
+		case FaultConnectionDrop:
+			return nil, ua.StatusBadConnectionClosed
+		}
+	}
+
 	// Parse input arguments
 	if len(req.InputArguments) < 6 {
 		return &ua.CallMethodResult{
@@ -140,14 +217,37 @@ func (s *MockServer) defaultCallHandler(ctx context.Context, req *ua.CallMethodR
 	}
 
 	// Get filtered records
-	filtered, nextCP := s.getFilteredRecords(startTime, endTime, maxRecords, minSeverity, continuationPoint)
+	filtered, nextCP, status := s.getFilteredRecords(req.ObjectID, startTime, endTime, maxRecords, minSeverity, continuationPoint)
+	if status != ua.StatusOK {
+		return &ua.CallMethodResult{StatusCode: status}, nil
+	}
 
 	s.logger.Debug("Mock server returning records",
 		zap.Int("count", len(filtered)),
 		zap.Bool("has_continuation", len(nextCP) > 0))
 
-	// Convert records to OPC UA format (simplified)
-	recordsVariant := s.convertRecordsToVariant(filtered)
+	if err := s.SimulateTransfer(ctx, filtered); err != nil {
+		return nil, err
+	}
+
+	// Convert records to OPC UA format: ExtensionObjects when an encoder is
+	// set, maps otherwise (simplified)
+	s.mu.RLock()
+	enc := s.encoder
+	s.mu.RUnlock()
+	var recordsVariant *ua.Variant
+	if enc == nil {
+		recordsVariant = s.convertRecordsToVariant(filtered)
+	} else {
+		var err error
+		if recordsVariant, err = encodeRecords(enc, filtered); err != nil {
+			s.logger.Warn("Mock server failed to encode records", zap.Error(err))
+			return &ua.CallMethodResult{StatusCode: ua.StatusBadEncodingError}, nil
+		}
+	}
+	if faulty && fault.Kind == FaultMalformedRecord {
+		recordsVariant = s.corruptFirstRecord(recordsVariant)
+	}
 
 	return &ua.CallMethodResult{
 		StatusCode: ua.StatusOK,
@@ -158,58 +258,109 @@ func (s *MockServer) defaultCallHandler(ctx context.Context, req *ua.CallMethodR
 	}, nil
 }
 
-// getFilteredRecords filters records based on criteria
+// CheckMethod returns StatusOK if methodID is the GetRecords method of the
+// LogObject objectID hosted by the server
+func (s *MockServer) CheckMethod(objectID, methodID *ua.NodeID) ua.StatusCode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return ua.StatusBadNodeIDUnknown
+	}
+	if methodID == nil || !obj.MethodID.Equal(methodID) {
+		return ua.StatusBadMethodInvalid
+	}
+	return ua.StatusOK
+}
+
+// Query returns the records of a LogObject matching a GetRecords request and
+// the continuation point for the next page, for servers that handle the Call
+// service themselves (e.g. a wire-protocol server in tests). The status is
+// BadContinuationPointInvalid if the continuation point is unknown or expired.
+func (s *MockServer) Query(
+	objectID *ua.NodeID,
+	startTime, endTime time.Time,
+	maxRecords uint32,
+	minSeverity uint16,
+	continuationPoint []byte,
+) ([]OPCUALogRecord, []byte, ua.StatusCode) {
+	return s.getFilteredRecords(objectID, startTime, endTime, maxRecords, minSeverity, continuationPoint)
+}
+
+// getFilteredRecords filters the records of a LogObject based on criteria
 func (s *MockServer) getFilteredRecords(
+	objectID *ua.NodeID,
 	startTime, endTime time.Time,
 	maxRecords uint32,
 	minSeverity uint16,
 	continuationPoint []byte,
-) ([]OPCUALogRecord, []byte) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+) ([]OPCUALogRecord, []byte, ua.StatusCode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	// Filter by time and severity
-	var filtered []OPCUALogRecord
-	for _, record := range s.records {
-		if record.Timestamp.Before(startTime) || record.Timestamp.After(endTime) {
-			continue
-		}
-		if record.Severity < minSeverity {
-			continue
-		}
-		filtered = append(filtered, record)
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return nil, nil, ua.StatusBadNodeIDUnknown
 	}
+	objectKey := obj.ObjectID.String()
 
-	// Handle continuation point
+	// Continuation points hold the index of the next record to examine,
+	// counted from the first record ever stored so they survive evictions.
+	// Without one, ordered streams skip straight to the start of the window.
+	src, ordered := obj.source()
 	startIndex := 0
-	if len(continuationPoint) > 0 && len(continuationPoint) >= 4 {
-		startIndex = int(uint32(continuationPoint[0]) |
-			uint32(continuationPoint[1])<<8 |
-			uint32(continuationPoint[2])<<16 |
-			uint32(continuationPoint[3])<<24)
+	consumed, hadContinuationPoint := s.continuationPoints[string(continuationPoint)]
+	if len(continuationPoint) > 0 {
+		offset, ok := s.consumeContinuationPoint(objectKey, continuationPoint)
+		if !ok {
+			return nil, nil, ua.StatusBadContinuationPointInvalid
+		}
+		startIndex = max(offset-obj.evicted, 0)
+	} else if ordered {
+		startIndex = sort.Search(src.Len(), func(i int) bool {
+			return !src.At(i).Timestamp.Before(startTime)
+		})
 	}
 
-	if startIndex >= len(filtered) {
-		return []OPCUALogRecord{}, nil
+	pageSize := maxRecords
+	if s.maxPageSize > 0 && (pageSize == 0 || pageSize > s.maxPageSize) {
+		pageSize = s.maxPageSize
 	}
-	filtered = filtered[startIndex:]
 
-	// Apply max records limit
+	// Filter by time and severity, stopping at the first match beyond the page
+	filtered := make([]OPCUALogRecord, 0)
 	var nextContinuationPoint []byte
-	if maxRecords > 0 && len(filtered) > int(maxRecords) {
-		filtered = filtered[:maxRecords]
-
-		// Create continuation point
-		nextOffset := startIndex + int(maxRecords)
-		nextContinuationPoint = []byte{
-			byte(nextOffset),
-			byte(nextOffset >> 8),
-			byte(nextOffset >> 16),
-			byte(nextOffset >> 24),
+	for i := startIndex; i < src.Len(); i++ {
+		record := src.At(i)
+		if record.Timestamp.After(endTime) {
+			if ordered {
+				break
+			}
+			continue
+		}
+		if record.Timestamp.Before(startTime) || record.Severity < minSeverity {
+			continue
+		}
+		if pageSize > 0 && len(filtered) == int(pageSize) {
+			nextContinuationPoint = s.issueContinuationPoint(objectKey, obj.evicted+i)
+			break
 		}
+		filtered = append(filtered, record)
 	}
 
-	return filtered, nextContinuationPoint
+	// A response that is never sent leaves the continuation points as they were
+	if s.maxResponseRecords > 0 && len(filtered) > int(s.maxResponseRecords) {
+		if nextContinuationPoint != nil {
+			delete(s.continuationPoints, string(nextContinuationPoint))
+		}
+		if hadContinuationPoint {
+			s.continuationPoints[string(continuationPoint)] = consumed
+		}
+		return nil, nil, ua.StatusBadResponseTooLarge
+	}
+
+	return filtered, nextContinuationPoint, ua.StatusOK
 }
 
 // convertRecordsToVariant converts log records to OPC UA Variant format
@@ -230,9 +381,10 @@ func (s *MockServer) convertRecordsToVariant(records []OPCUALogRecord) *ua.Varia
 
 		if record.TraceID != "" || record.SpanID != "" {
 			recordMap["TraceContext"] = map[string]interface{}{
-				"TraceId":    record.TraceID,
-				"SpanId":     record.SpanID,
-				"TraceFlags": record.TraceFlags,
+				"TraceId":      record.TraceID,
+				"SpanId":       record.SpanID,
+				"ParentSpanId": record.ParentSpanID,
+				"TraceFlags":   record.TraceFlags,
 			}
 		}
 
@@ -253,6 +405,25 @@ func (s *MockServer) convertRecordsToVariant(records []OPCUALogRecord) *ua.Varia
 	return ua.MustVariant(recordMaps)
 }
 
+// corruptFirstRecord replaces the first record with a value that is not a LogRecord
+func (s *MockServer) corruptFirstRecord(v *ua.Variant) *ua.Variant {
+	if objects, ok := v.Value().([]*ua.ExtensionObject); ok && len(objects) > 0 {
+		objects[0] = &ua.ExtensionObject{
+			EncodingMask: ua.ExtensionObjectBinary,
+			TypeID:       objects[0].TypeID,
+			Value:        []byte{0xde, 0xad},
+		}
+		return ua.MustVariant(objects)
+	}
+
+	recordMaps, ok := v.Value().([]interface{})
+	if !ok || len(recordMaps) == 0 {
+		return v
+	}
+	recordMaps[0] = []byte{0xde, 0xad, 0xbe, 0xef}
+	return ua.MustVariant(recordMaps)
+}
+
 // IsRunning returns whether the server is running
 func (s *MockServer) IsRunning() bool {
 	s.mu.RLock()
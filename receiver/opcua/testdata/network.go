@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"context"
+	"time"
+)
+
+// NetworkConditions simulates the link between a client and a mock server so
+// interval overruns and scrape timeouts can be tested without real hardware
+type NetworkConditions struct {
+	// Latency is added to every GetRecords call
+	Latency time.Duration `yaml:"latency"`
+
+	// BytesPerSecond limits the throughput of GetRecords responses. The response
+	// size is estimated from the returned records. Zero means unlimited.
+	BytesPerSecond int `yaml:"bytes_per_second"`
+}
+
+// recordOverhead approximates the fixed encoded size of a LogRecord: time,
+// severity, source NodeId, trace context and the string length prefixes
+const recordOverhead = 8 + 2 + 7 + 16 + 8 + 1 + 3*4
+
+// SetNetworkConditions applies simulated latency and throughput limits to
+// subsequent GetRecords calls
+func (s *MockServer) SetNetworkConditions(nc NetworkConditions) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.network = nc
+}
+
+// TransferDelay returns how long a GetRecords response carrying records takes
+// under the current network conditions
+func (s *MockServer) TransferDelay(records []OPCUALogRecord) time.Duration {
+	s.mu.RLock()
+	nc := s.network
+	s.mu.RUnlock()
+
+	delay := nc.Latency
+	if nc.BytesPerSecond > 0 {
+		size := 0
+		for _, r := range records {
+			size += EstimateRecordSize(r)
+		}
+		delay += time.Duration(size) * time.Second / time.Duration(nc.BytesPerSecond)
+	}
+	return delay
+}
+
+// SimulateTransfer blocks for the transfer delay of a GetRecords response. It
+// returns the context error if ctx is done first.
+func (s *MockServer) SimulateTransfer(ctx context.Context, records []OPCUALogRecord) error {
+	delay := s.TransferDelay(records)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// EstimateRecordSize approximates the binary encoded size of a record in bytes
+func EstimateRecordSize(r OPCUALogRecord) int {
+	size := recordOverhead + len(r.Message) + len(r.SourceName) + len(r.SourceID)
+	for k, v := range r.Attributes {
+		size += 4 + len(k) + 1
+		switch val := v.(type) {
+		case string:
+			size += 4 + len(val)
+		case []byte:
+			size += 4 + len(val)
+		default:
+			size += 8
+		}
+	}
+	return size
+}
@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"fmt"
+
+	"github.com/gopcua/opcua/ua"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/recording"
+)
+
+// ReplayedCall is a recorded outcome of a GetRecords call. Result is nil when
+// the Call service itself failed with ServiceStatus.
+type ReplayedCall struct {
+	Result        *ua.CallMethodResult
+	ServiceStatus ua.StatusCode
+}
+
+// LoadRecording replays the GetRecords calls captured by the receiver's
+// capture.directory option, see Replay
+func (s *MockServer) LoadRecording(path string) error {
+	calls, err := recording.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load recording: %w", err)
+	}
+	return s.Replay(calls)
+}
+
+// Replay makes the server answer GetRecords calls with recorded results
+// instead of its own records. Each LogObject serves its recorded results in
+// order, so a client repeating the recorded sequence of calls receives the
+// exact responses of the original server. LogObjects in the recording that
+// are not hosted yet are added. Once a LogObject's recorded calls are used up
+// it answers from its record store again.
+//
+// ExtensionObjects in the results are decoded with the types registered in
+// the test binary; the receiver package registers the LogRecord type.
+func (s *MockServer) Replay(calls []recording.Call) error {
+	replay := make(map[string][]ReplayedCall)
+	var objects []LogObject
+	for i, call := range calls {
+		req, err := call.DecodeRequest()
+		if err != nil {
+			return fmt.Errorf("call %d: %w", i, err)
+		}
+		result, err := call.DecodeResult()
+		if err != nil {
+			return fmt.Errorf("call %d: %w", i, err)
+		}
+
+		replayed := ReplayedCall{Result: result}
+		if result == nil {
+			replayed.ServiceStatus = ua.StatusCode(call.ServiceStatus)
+			if replayed.ServiceStatus == ua.StatusOK {
+				replayed.ServiceStatus = ua.StatusBadUnexpectedError
+			}
+		}
+
+		key := req.ObjectID.String()
+		if _, seen := replay[key]; !seen {
+			objects = append(objects, LogObject{ObjectID: req.ObjectID, MethodID: req.MethodID})
+		}
+		replay[key] = append(replay[key], replayed)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, obj := range objects {
+		if s.findLogObject(obj.ObjectID) == nil {
+			s.logObjects = append(s.logObjects, &logObject{LogObject: obj, records: make([]OPCUALogRecord, 0)})
+		}
+	}
+	s.replay = replay
+	return nil
+}
+
+// NextReplay returns the next recorded result for a GetRecords call on
+// objectID. Servers that handle the Call service on their own answer with it
+// instead of querying their records.
+func (s *MockServer) NextReplay(objectID *ua.NodeID) (ReplayedCall, bool) {
+	if objectID == nil {
+		return ReplayedCall{}, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := objectID.String()
+	queue := s.replay[key]
+	if len(queue) == 0 {
+		return ReplayedCall{}, false
+	}
+	s.replay[key] = queue[1:]
+	return queue[0], true
+}
+
+// ReplayRemaining returns the number of recorded calls not replayed yet
+func (s *MockServer) ReplayRemaining() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, queue := range s.replay {
+		n += len(queue)
+	}
+	return n
+}
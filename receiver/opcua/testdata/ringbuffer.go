@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"fmt"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// SetLogBufferCapacity bounds the record store of a LogObject to the last
+// capacity records, like a server with a fixed-size ring buffer. Older records
+// are evicted silently, including records a paginated read has not reached
+// yet; such a read resumes at the oldest record still held. Zero removes the
+// bound.
+func (s *MockServer) SetLogBufferCapacity(objectID *ua.NodeID, capacity int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return fmt.Errorf("unknown log object %s", objectID)
+	}
+	if obj.stream != nil {
+		return fmt.Errorf("log object %s is served from a record stream", objectID)
+	}
+	if capacity < 0 {
+		return fmt.Errorf("capacity must not be negative, got %d", capacity)
+	}
+	obj.capacity = capacity
+	obj.evict()
+	return nil
+}
+
+// EvictedCount returns the number of records evicted from the buffer of a LogObject
+func (s *MockServer) EvictedCount(objectID *ua.NodeID) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if obj := s.findLogObject(objectID); obj != nil {
+		return obj.evicted
+	}
+	return 0
+}
+
+// append adds records to the store, evicting the oldest records beyond the
+// capacity. The caller must hold s.mu.
+func (o *logObject) append(records ...OPCUALogRecord) {
+	o.records = append(o.records, records...)
+	o.evict()
+}
+
+// evict drops the oldest records beyond the capacity. The caller must hold s.mu.
+func (o *logObject) evict() {
+	if o.capacity == 0 || len(o.records) <= o.capacity {
+		return
+	}
+	drop := len(o.records) - o.capacity
+	o.records = append(make([]OPCUALogRecord, 0, o.capacity), o.records[drop:]...)
+	o.evicted += drop
+}
@@ -0,0 +1,246 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is a declarative script of mock server behavior: LogObjects,
+// initial records, network conditions and per-call steps. Scenarios are
+// usually loaded from YAML with LoadScenario and applied with Apply.
+type Scenario struct {
+	Name string `yaml:"name"`
+
+	// BaseTime is the reference time record offsets are relative to
+	BaseTime time.Time `yaml:"base_time"`
+
+	// Seed seeds the record generator so scenarios are reproducible
+	Seed int64 `yaml:"seed"`
+
+	LogObjects              []ScenarioLogObject     `yaml:"log_objects"`
+	Network                 NetworkConditions       `yaml:"network"`
+	ContinuationPointExpiry ContinuationPointExpiry `yaml:"continuation_point_expiry"`
+
+	// Records are stored before the first call
+	Records []RecordBatch `yaml:"records"`
+
+	// Steps script individual GetRecords calls
+	Steps []ScenarioStep `yaml:"steps"`
+}
+
+// ScenarioLogObject declares an additional LogObject or configures an existing one
+type ScenarioLogObject struct {
+	ID     string `yaml:"id"`
+	Method string `yaml:"method"`
+
+	// Capacity bounds the record buffer, see SetLogBufferCapacity
+	Capacity int `yaml:"capacity"`
+}
+
+// RecordBatch describes generated records added to a LogObject
+type RecordBatch struct {
+	// LogObject is the NodeId of the target LogObject; empty means the default ServerLog
+	LogObject string `yaml:"log_object"`
+
+	// Distribution is one of steady_info (default), bursty_errors or random
+	Distribution string `yaml:"distribution"`
+
+	Count    int           `yaml:"count"`
+	Start    time.Duration `yaml:"start"` // offset of the first record from the base time
+	Interval time.Duration `yaml:"interval"`
+
+	// BurstProbability and BurstLength configure bursty_errors
+	BurstProbability float64 `yaml:"burst_probability"`
+	BurstLength      int     `yaml:"burst_length"`
+
+	// Severity and Message override the generated values when set
+	Severity uint16 `yaml:"severity"`
+	Message  string `yaml:"message"`
+}
+
+// ScenarioStep scripts the behavior of one GetRecords call
+type ScenarioStep struct {
+	// Call is the 1-based number of the call; zero applies the fault to every call
+	Call int `yaml:"call"`
+
+	// Fault is the name of a FaultKind to inject, e.g. SecureChannelClosed
+	Fault     string        `yaml:"fault"`
+	Delay     time.Duration `yaml:"delay"`
+	Status    uint32        `yaml:"status"` // status code for the Status fault
+	LogObject string        `yaml:"log_object"`
+
+	// Records are added right before the call is answered
+	Records []RecordBatch `yaml:"records"`
+}
+
+// scheduledBatch is a batch of records waiting for its call
+type scheduledBatch struct {
+	objectID *ua.NodeID
+	records  []OPCUALogRecord
+}
+
+// LoadScenario reads a scenario from a YAML file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	return ParseScenario(data)
+}
+
+// ParseScenario parses a YAML scenario
+func ParseScenario(data []byte) (*Scenario, error) {
+	var sc Scenario
+	if err := yaml.Unmarshal(data, &sc); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if sc.BaseTime.IsZero() {
+		sc.BaseTime = time.Now().Add(-time.Hour)
+	}
+	return &sc, nil
+}
+
+// Apply configures the server as described by the scenario
+func (sc *Scenario) Apply(s *MockServer) error {
+	for _, lo := range sc.LogObjects {
+		objectID, err := ua.ParseNodeID(lo.ID)
+		if err != nil {
+			return fmt.Errorf("invalid log object id %q: %w", lo.ID, err)
+		}
+		if lo.Method != "" {
+			methodID, err := ua.ParseNodeID(lo.Method)
+			if err != nil {
+				return fmt.Errorf("invalid method id %q: %w", lo.Method, err)
+			}
+			if err := s.AddLogObject(objectID, methodID); err != nil {
+				return err
+			}
+		}
+		if lo.Capacity > 0 {
+			if err := s.SetLogBufferCapacity(objectID, lo.Capacity); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.SetNetworkConditions(sc.Network)
+	s.SetContinuationPointExpiry(sc.ContinuationPointExpiry)
+
+	gen := NewSeededGenerator(sc.Seed, sc.BaseTime)
+	for _, batch := range sc.Records {
+		objectID, records, err := sc.generate(gen, batch)
+		if err != nil {
+			return err
+		}
+		if err := s.AddLogRecordsTo(objectID, records...); err != nil {
+			return err
+		}
+	}
+
+	for _, step := range sc.Steps {
+		if err := sc.applyStep(s, gen, step); err != nil {
+			return fmt.Errorf("step for call %d: %w", step.Call, err)
+		}
+	}
+	return nil
+}
+
+// applyStep schedules the fault and record batches of a step
+func (sc *Scenario) applyStep(s *MockServer, gen *Generator, step ScenarioStep) error {
+	if step.Fault != "" {
+		kind, err := ParseFaultKind(step.Fault)
+		if err != nil {
+			return err
+		}
+		fault := Fault{Kind: kind, Call: step.Call, Delay: step.Delay, Status: ua.StatusCode(step.Status)}
+		if step.LogObject != "" {
+			if fault.LogObject, err = ua.ParseNodeID(step.LogObject); err != nil {
+				return fmt.Errorf("invalid log object id %q: %w", step.LogObject, err)
+			}
+		}
+		s.InjectFault(fault)
+	}
+
+	if len(step.Records) > 0 && step.Call < 1 {
+		return errors.New("records require a call number")
+	}
+	for _, batch := range step.Records {
+		objectID, records, err := sc.generate(gen, batch)
+		if err != nil {
+			return err
+		}
+		s.ScheduleRecords(step.Call, objectID, records...)
+	}
+	return nil
+}
+
+// generate produces the records of a batch
+func (sc *Scenario) generate(gen *Generator, batch RecordBatch) (*ua.NodeID, []OPCUALogRecord, error) {
+	objectID := DefaultLogObjectID
+	if batch.LogObject != "" {
+		var err error
+		if objectID, err = ua.ParseNodeID(batch.LogObject); err != nil {
+			return nil, nil, fmt.Errorf("invalid log object id %q: %w", batch.LogObject, err)
+		}
+	}
+
+	interval := batch.Interval
+	if interval == 0 {
+		interval = time.Second
+	}
+	g := &Generator{rand: gen.rand, base: sc.BaseTime.Add(batch.Start)}
+
+	var records []OPCUALogRecord
+	switch batch.Distribution {
+	case "", "steady_info":
+		records = g.SteadyInfo(batch.Count, interval)
+	case "bursty_errors":
+		records = g.BurstyErrors(batch.Count, interval, batch.BurstProbability, batch.BurstLength)
+	case "random":
+		records = g.Records(batch.Count)
+	default:
+		return nil, nil, fmt.Errorf("unknown distribution %q", batch.Distribution)
+	}
+
+	for i := range records {
+		if batch.Severity != 0 {
+			records[i].Severity = batch.Severity
+		}
+		if batch.Message != "" {
+			records[i].Message = batch.Message
+		}
+	}
+	return objectID, records, nil
+}
+
+// ScheduleRecords adds records to a LogObject right before the given
+// 1-based GetRecords call is answered
+func (s *MockServer) ScheduleRecords(call int, objectID *ua.NodeID, records ...OPCUALogRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scheduledRecords == nil {
+		s.scheduledRecords = make(map[int][]scheduledBatch)
+	}
+	s.scheduledRecords[call] = append(s.scheduledRecords[call], scheduledBatch{objectID: objectID, records: records})
+}
+
+// addScheduledRecords adds the record batches scheduled for a call. The caller must hold s.mu.
+func (s *MockServer) addScheduledRecords(call int) {
+	for _, batch := range s.scheduledRecords[call] {
+		obj := s.findLogObject(batch.objectID)
+		if obj == nil || obj.stream != nil {
+			s.logger.Warn("Mock server dropped scheduled records for unknown or streamed log object")
+			continue
+		}
+		obj.append(batch.records...)
+	}
+	delete(s.scheduledRecords, call)
+}
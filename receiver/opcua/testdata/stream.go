@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// RecordStream is a lazily produced sequence of records in timestamp order.
+// A MockServer serving a stream only produces the records a GetRecords page
+// touches, so backlogs of millions of records cost no memory up front.
+type RecordStream interface {
+	// Len returns the number of records in the stream
+	Len() int
+	// At returns record i; it must be cheap and return the same record on every call
+	At(i int) OPCUALogRecord
+}
+
+// SyntheticStream produces Count records spaced Interval apart from Start.
+// Record i is derived from Seed and i only, so any page can be produced on
+// demand without materialising the stream.
+type SyntheticStream struct {
+	Seed     int64
+	Start    time.Time
+	Interval time.Duration
+	Count    int
+
+	// ErrorRatio is the share of records in the error severity range; the
+	// remaining records are informational
+	ErrorRatio float64
+}
+
+// Len returns the number of records in the stream
+func (s SyntheticStream) Len() int {
+	return s.Count
+}
+
+// At returns record i of the stream
+func (s SyntheticStream) At(i int) OPCUALogRecord {
+	h := uint64(s.Seed) + uint64(i)*0x9e3779b97f4a7c15 //nolint:gosec
+	next := func() uint64 {
+		h = splitmix64(h)
+		return h
+	}
+
+	severity := infoSeverities[next()%uint64(len(infoSeverities))]
+	message := infoMessages[next()%uint64(len(infoMessages))]
+	if float64(next()>>11)/(1<<53) < s.ErrorRatio {
+		severity = errorSeverities[next()%uint64(len(errorSeverities))]
+		message = errorMessages[next()%uint64(len(errorMessages))]
+	}
+	src := sourceNodes[next()%uint64(len(sourceNodes))]
+
+	return OPCUALogRecord{
+		Timestamp:       s.Start.Add(time.Duration(i) * s.Interval),
+		Severity:        severity,
+		Message:         message,
+		SourceName:      src.Name,
+		SourceNamespace: src.Namespace,
+		SourceIDType:    "Numeric",
+		SourceID:        fmt.Sprintf("%d", src.ID),
+		TraceID:         fmt.Sprintf("%016x%016x", next(), next()),
+		SpanID:          fmt.Sprintf("%016x", next()),
+		TraceFlags:      byte(next() & 1),
+		Attributes: map[string]interface{}{
+			"component": "stream",
+			"index":     i,
+		},
+	}
+}
+
+// splitmix64 is the SplitMix64 mixing function, used to derive record fields
+// from the stream seed and record index without a stateful random source
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	z := x
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// sliceStream serves the stored records of a LogObject as a RecordStream
+type sliceStream []OPCUALogRecord
+
+func (s sliceStream) Len() int                { return len(s) }
+func (s sliceStream) At(i int) OPCUALogRecord { return s[i] }
+
+// SetRecordStream serves the records of a LogObject from stream instead of
+// its store. Stored records are discarded and AddLogRecordsTo fails until
+// ClearLogRecords removes the stream again.
+func (s *MockServer) SetRecordStream(objectID *ua.NodeID, stream RecordStream) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	obj := s.findLogObject(objectID)
+	if obj == nil {
+		return fmt.Errorf("unknown log object %s", objectID)
+	}
+	if uint64(stream.Len()) > math.MaxUint32 {
+		return fmt.Errorf("stream of %d records exceeds the continuation point range", stream.Len())
+	}
+	obj.records = make([]OPCUALogRecord, 0)
+	obj.stream = stream
+	obj.capacity, obj.evicted = 0, 0
+	return nil
+}
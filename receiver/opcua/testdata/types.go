@@ -4,27 +4,11 @@
 // Package testserver provides test utilities for the OPC UA receiver
 package testdata
 
-import "time"
+import "github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
 
-// OPCUALogRecord represents a log record from an OPC UA server
-// This is a simplified representation for testing purposes
-type OPCUALogRecord struct {
-	Timestamp       time.Time
-	Severity        uint16
-	Message         string
-	SourceName      string // opcua.source.name: human-readable name of the log source
-	SourceNamespace uint16 // opcua.source.namespace: NodeId namespace index
-	SourceIDType    string // opcua.source.id_type: NodeId identifier type ("Numeric", "String", "Guid", "Opaque")
-	SourceID        string // opcua.source.id: NodeId identifier value
-	TraceID         string // 32-character hex string
-	SpanID          string // 16-character hex string
-	TraceFlags      byte
-	Attributes      map[string]interface{}
-}
+// OPCUALogRecord is the receiver's log record model, aliased so the mocks and
+// generators can be used with the production client, scraper and transformer
+type OPCUALogRecord = model.LogRecord
 
 // TraceContext represents trace context from OPC UA
-type TraceContext struct {
-	TraceID string
-	SpanID  string
-	Flags   byte
-}
+type TraceContext = model.TraceContext
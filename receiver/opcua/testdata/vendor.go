@@ -0,0 +1,188 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package testdata
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// VendorProfile is the shape of the AdditionalData a family of servers attaches to its log records
+type VendorProfile int
+
+const (
+	// VendorPLC mimics controller diagnostics: address-style keys with
+	// status words, counters and setpoints of every integer and float width,
+	// flags and a raw diagnostic buffer
+	VendorPLC VendorProfile = iota
+	// VendorSCADA mimics alarm servers: arrays of alarm groups, limits, ids
+	// and acknowledgement flags under long hierarchical tag paths
+	VendorSCADA
+	// VendorMES mimics manufacturing execution systems: nested order,
+	// material and batch structures with timestamps
+	VendorMES
+	// VendorGateway mimics protocol gateways: raw frames, keys of several
+	// hundred characters, non-ASCII keys, empty and nil values and extreme numbers
+	VendorGateway
+)
+
+// VendorProfiles lists all vendor profiles
+var VendorProfiles = []VendorProfile{VendorPLC, VendorSCADA, VendorMES, VendorGateway}
+
+// String returns the name of the profile
+func (p VendorProfile) String() string {
+	switch p {
+	case VendorPLC:
+		return "PLC"
+	case VendorSCADA:
+		return "SCADA"
+	case VendorMES:
+		return "MES"
+	case VendorGateway:
+		return "Gateway"
+	default:
+		return fmt.Sprintf("VendorProfile(%d)", int(p))
+	}
+}
+
+// scadaTagRoot is the start of the hierarchical tag paths of VendorSCADA
+const scadaTagRoot = "ns=3;s=Plant/Area_01/Line_03/Station_07"
+
+// VendorRecord creates a random record like Record whose AdditionalData is
+// shaped like a payload of the given profile
+func (g *Generator) VendorRecord(index int, profile VendorProfile) OPCUALogRecord {
+	record := g.Record(index)
+	record.Attributes = g.VendorAttributes(profile)
+	return record
+}
+
+// VendorRecords creates count random records cycling through all vendor profiles
+func (g *Generator) VendorRecords(count int) []OPCUALogRecord {
+	records := make([]OPCUALogRecord, count)
+	for i := range records {
+		records[i] = g.VendorRecord(i, VendorProfiles[i%len(VendorProfiles)])
+	}
+	return records
+}
+
+// VendorAttributes creates AdditionalData shaped like a payload of the given profile
+func (g *Generator) VendorAttributes(profile VendorProfile) map[string]interface{} {
+	switch profile {
+	case VendorPLC:
+		return g.plcAttributes()
+	case VendorSCADA:
+		return g.scadaAttributes()
+	case VendorMES:
+		return g.mesAttributes()
+	case VendorGateway:
+		return g.gatewayAttributes()
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+func (g *Generator) plcAttributes() map[string]interface{} {
+	return map[string]interface{}{
+		"DB100.DBX0.0":      g.rand.Intn(2) == 1,
+		"DB100.DBB1":        uint8(g.rand.Intn(256)),                 //nolint:gosec
+		"DB100.DBW2":        uint16(g.rand.Intn(math.MaxUint16 + 1)), //nolint:gosec
+		"DB100.DBW4":        int16(g.rand.Intn(2001) - 1000),         //nolint:gosec
+		"DB100.DBD6":        g.rand.Uint32(),
+		"DB100.DBD10":       float32(g.rand.Float64() * 100),
+		"CycleCounter":      g.rand.Int63(),
+		"TotalRuntimeMs":    g.rand.Uint64(),
+		"TemperatureOffset": int8(g.rand.Intn(256) - 128), //nolint:gosec
+		"Setpoint":          g.rand.Float64() * 1000,
+		"ErrorCode":         int32(g.rand.Intn(1 << 16)), //nolint:gosec
+		"CPU":               "CPU 1516-3 PN/DP",
+		"DiagnosticBuffer":  g.bytes(16 + g.rand.Intn(48)),
+	}
+}
+
+func (g *Generator) scadaAttributes() map[string]interface{} {
+	limits := make([]float64, 4)
+	for i := range limits {
+		limits[i] = float64(i*25) + g.rand.Float64()
+	}
+	ids := make([]uint32, 1+g.rand.Intn(8))
+	acknowledged := make([]bool, len(ids))
+	for i := range ids {
+		ids[i] = g.rand.Uint32()
+		acknowledged[i] = g.rand.Intn(2) == 1
+	}
+
+	return map[string]interface{}{
+		scadaTagRoot + "/Robot_02/Axis_4/Motor/Temperature":    g.rand.Float64() * 120,
+		scadaTagRoot + "/Robot_02/Axis_4/Motor/AlarmState":     int32(g.rand.Intn(4)), //nolint:gosec
+		scadaTagRoot + "/Conveyor_01/Drive/Diagnostics/Status": "Running",
+		"AlarmGroups":  []string{"Process", "Safety", "Maintenance"}[:1+g.rand.Intn(3)],
+		"Limits":       limits,
+		"ActiveAlarms": ids,
+		"Acknowledged": acknowledged,
+		"Operators":    []string{},
+		"Priority":     uint16(1 + g.rand.Intn(1000)), //nolint:gosec
+	}
+}
+
+func (g *Generator) mesAttributes() map[string]interface{} {
+	started := g.base.Add(-time.Duration(g.rand.Int63n(int64(24 * time.Hour))))
+	return map[string]interface{}{
+		"Order": map[string]interface{}{
+			"Number":   fmt.Sprintf("PO-%08d", g.rand.Intn(1e8)),
+			"Quantity": int32(1 + g.rand.Intn(10000)), //nolint:gosec
+			"Material": map[string]interface{}{
+				"Id":          fmt.Sprintf("MAT-%05d", g.rand.Intn(1e5)),
+				"Description": "Housing, aluminium, anodized",
+				"Revision":    uint8(g.rand.Intn(26)), //nolint:gosec
+			},
+			"Operations": []interface{}{
+				map[string]interface{}{"Step": int32(10), "Name": "Milling"},
+				map[string]interface{}{"Step": int32(20), "Name": "Deburring"},
+			},
+		},
+		"Batch": map[string]interface{}{
+			"Id":        g.rand.Int63(),
+			"Started":   started,
+			"Samples":   []float32{float32(g.rand.Float64()), float32(g.rand.Float64()), float32(g.rand.Float64())},
+			"Approvals": []interface{}{"QA", int32(2), true},
+		},
+		"StartTime":  started,
+		"ShiftCodes": []string{"A", "B", "C"},
+		"Workcenter": "WC-4711",
+	}
+}
+
+func (g *Generator) gatewayAttributes() map[string]interface{} {
+	segment := []string{"modbus", "tcp", "unit", "holding_register", "block"}
+	var longKey strings.Builder
+	for longKey.Len() < 300 {
+		longKey.WriteString(segment[g.rand.Intn(len(segment))])
+		longKey.WriteByte('.')
+	}
+
+	return map[string]interface{}{
+		"RawFrame":                g.bytes(1 + g.rand.Intn(4096)),
+		"EmptyFrame":              []byte{},
+		longKey.String():          g.rand.Int63(),
+		strings.Repeat("k", 1024): "key of 1024 characters",
+		"Temperatur °C":           g.rand.Float64() * 80,
+		"温度センサー":                  "Sensor 温度",
+		"Empty":                   "",
+		"Missing":                 nil,
+		"MaxUInt64":               uint64(math.MaxUint64),
+		"MinInt64":                int64(math.MinInt64),
+		"MaxFloat":                math.MaxFloat64,
+		"SmallestFloat32":         float32(math.SmallestNonzeroFloat32),
+		"MultiLine":               "line 1\nline 2\r\n\ttabbed",
+	}
+}
+
+// bytes returns n random bytes
+func (g *Generator) bytes(n int) []byte {
+	b := make([]byte, n)
+	_, _ = g.rand.Read(b)
+	return b
+}
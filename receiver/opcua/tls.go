@@ -0,0 +1,194 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha1" //nolint:gosec // OPC UA thumbprints are SHA-1
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// hasApplicationCertificate reports whether a certificate and key are
+// configured, as files or inline PEM
+func (cfg *TLSConfig) hasApplicationCertificate() bool {
+	return cfg.CertFile != "" && cfg.KeyFile != "" || cfg.CertPem != "" && cfg.KeyPem != ""
+}
+
+// applicationCertificate loads the DER-encoded application certificate and
+// its RSA private key. Files may be PEM or DER encoded, unlike for TLS.
+func (cfg *TLSConfig) applicationCertificate() ([]byte, *rsa.PrivateKey, error) {
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		return loadCertificateFiles(cfg.CertFile, cfg.KeyFile)
+	}
+
+	pair, err := tls.X509KeyPair([]byte(cfg.CertPem), []byte(cfg.KeyPem))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load cert_pem and key_pem: %w", err)
+	}
+	key, ok := pair.PrivateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, errors.New("key_pem is not an RSA key")
+	}
+	return pair.Certificate[0], key, nil
+}
+
+// serverRoots loads the CAs the server certificate is verified against. It
+// returns nil if no CA is configured or insecure_skip_verify is set. The CAs
+// are loaded on every connect, so a replaced ca_file takes effect with the
+// next session.
+func (cfg *TLSConfig) serverRoots(ctx context.Context) (*x509.CertPool, error) {
+	if cfg.InsecureSkipVerify || cfg.CAFile == "" && cfg.CAPem == "" {
+		return nil, nil
+	}
+
+	// Only the CAs are loaded by configtls, which rejects DER encoded
+	// application certificates
+	caOnly := cfg.ClientConfig
+	caOnly.CertFile, caOnly.KeyFile = "", ""
+	caOnly.CertPem, caOnly.KeyPem = "", ""
+	tlsCfg, err := caOnly.LoadTLSConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA: %w", err)
+	}
+	return tlsCfg.RootCAs, nil
+}
+
+// trustedDirectoryCertificates loads the certificates in trusted_directory,
+// each file PEM or DER encoded. Subdirectories, such as the crl folder of an
+// OPC UA PKI, are skipped.
+func (cfg *TLSConfig) trustedDirectoryCertificates() ([]*x509.Certificate, error) {
+	if cfg.TrustedDirectory == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(cfg.TrustedDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted_directory: %w", err)
+	}
+
+	var certs []*x509.Certificate
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(cfg.TrustedDirectory, entry.Name())
+		parsed, err := readCertificates(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load trusted certificate %s: %w", path, err)
+		}
+		certs = append(certs, parsed...)
+	}
+	return certs, nil
+}
+
+// readCertificates parses all certificates of a PEM file, or of a file of
+// DER encoded certificates
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // path is supplied by configuration
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	rest := data
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) > 0 {
+		return certs, nil
+	}
+	return x509.ParseCertificates(data)
+}
+
+// verifyServerCA checks the DER-encoded server certificate against the CAs
+// and trusted_directory of the tls section. The certificate is accepted if it
+// is in trusted_directory or chains up to a CA of either. With server_name
+// set, a chained certificate must also be issued for that host name. Without
+// a CA or trusted certificate, any certificate within its validity period is
+// accepted.
+func verifyServerCA(ctx context.Context, cfg TLSConfig, serverCert []byte) error {
+	if cfg.InsecureSkipVerify {
+		return nil
+	}
+	roots, err := cfg.serverRoots(ctx)
+	if err != nil {
+		return err
+	}
+	trusted, err := cfg.trustedDirectoryCertificates()
+	if err != nil {
+		return err
+	}
+
+	cert, err := parseServerCertificate(serverCert, time.Now())
+	if err != nil {
+		return err
+	}
+	if roots == nil && len(trusted) == 0 {
+		return nil
+	}
+
+	if roots == nil {
+		roots = x509.NewCertPool()
+	}
+	for _, t := range trusted {
+		if t.Equal(cert) {
+			return nil
+		}
+		roots.AddCert(t)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		DNSName:   cfg.ServerName,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}); err != nil {
+		return fmt.Errorf("server certificate is not trusted by the tls CAs: %s: %w", describeCertificate(cert), err)
+	}
+	return nil
+}
+
+// parseServerCertificate parses the DER-encoded server certificate and
+// rejects it outside its validity period
+func parseServerCertificate(serverCert []byte, now time.Time) (*x509.Certificate, error) {
+	if len(serverCert) == 0 {
+		return nil, errors.New("server did not present a certificate")
+	}
+
+	cert, err := x509.ParseCertificate(serverCert)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse server certificate: %w", err)
+	}
+	if now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("server certificate has expired: %s, valid until %s", describeCertificate(cert), cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return nil, fmt.Errorf("server certificate is not yet valid: %s, valid from %s", describeCertificate(cert), cert.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return cert, nil
+}
+
+// describeCertificate names a certificate by subject and by the SHA-1
+// thumbprint OPC UA servers show in their certificate stores
+func describeCertificate(cert *x509.Certificate) string {
+	thumbprint := sha1.Sum(cert.Raw) //nolint:gosec // OPC UA thumbprints are SHA-1
+	return fmt.Sprintf("%q (thumbprint %X)", cert.Subject.String(), thumbprint)
+}
@@ -0,0 +1,244 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.uber.org/zap"
+)
+
+// writeCAFile writes certificates as a PEM file for ca_file
+func writeCAFile(t *testing.T, certs ...*x509.Certificate) string {
+	t.Helper()
+	var data []byte
+	for _, cert := range certs {
+		data = append(data, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+// writeTrustedDirectory writes each certificate DER encoded to a directory
+// for trusted_directory
+func writeTrustedDirectory(t *testing.T, certs ...*x509.Certificate) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i, cert := range certs {
+		path := filepath.Join(dir, fmt.Sprintf("server-%d.der", i))
+		require.NoError(t, os.WriteFile(path, cert.Raw, 0o600))
+	}
+	return dir
+}
+
+func TestTLSConfigApplicationCertificate(t *testing.T) {
+	cert, key := newTestCertificate(t, "opcua-receiver", false, nil, nil)
+	certFile, keyFile := writeCertificateFiles(t, cert, key)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	files := TLSConfig{}
+	files.CertFile, files.KeyFile = certFile, keyFile
+	require.True(t, files.hasApplicationCertificate())
+	der, got, err := files.applicationCertificate()
+	require.NoError(t, err)
+	assert.Equal(t, cert.Raw, der)
+	assert.True(t, key.Equal(got))
+
+	inline := TLSConfig{}
+	inline.CertPem = configopaque.String(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	inline.KeyPem = configopaque.String(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	require.True(t, inline.hasApplicationCertificate())
+	der, got, err = inline.applicationCertificate()
+	require.NoError(t, err)
+	assert.Equal(t, cert.Raw, der)
+	assert.True(t, key.Equal(got))
+
+	certOnly := TLSConfig{}
+	certOnly.CertFile = certFile
+	assert.False(t, certOnly.hasApplicationCertificate())
+}
+
+func TestVerifyServerCA(t *testing.T) {
+	ca, caKey := newTestCertificate(t, "Plant CA", true, nil, nil)
+	signed, _ := newTestCertificate(t, "PLC-1", false, ca, caKey)
+	stranger, _ := newTestCertificate(t, "Unknown", false, nil, nil)
+	pinned, _ := newTestCertificate(t, "PLC-2", false, nil, nil)
+	expired := newExpiredCertificate(t, "PLC-3")
+	caFile := writeCAFile(t, ca)
+	trustedDir := writeTrustedDirectory(t, ca, pinned, expired)
+
+	// The crl folder of an OPC UA PKI is not read
+	require.NoError(t, os.Mkdir(filepath.Join(trustedDir, "crl"), 0o700))
+
+	invalidDir := writeTrustedDirectory(t)
+	require.NoError(t, os.WriteFile(filepath.Join(invalidDir, "README.txt"), []byte("trusted servers"), 0o600))
+
+	tests := []struct {
+		name       string
+		configure  func(cfg *TLSConfig)
+		serverCert []byte
+		wantErr    string
+	}{
+		{
+			name:       "no CA accepts any",
+			configure:  func(*TLSConfig) {},
+			serverCert: stranger.Raw,
+		},
+		{
+			name:       "signed by CA file",
+			configure:  func(cfg *TLSConfig) { cfg.CAFile = caFile },
+			serverCert: signed.Raw,
+		},
+		{
+			name: "signed by inline CA",
+			configure: func(cfg *TLSConfig) {
+				cfg.CAPem = configopaque.String(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Raw}))
+			},
+			serverCert: signed.Raw,
+		},
+		{
+			name:       "untrusted",
+			configure:  func(cfg *TLSConfig) { cfg.CAFile = caFile },
+			serverCert: stranger.Raw,
+			wantErr:    "server certificate is not trusted",
+		},
+		{
+			name: "insecure_skip_verify",
+			configure: func(cfg *TLSConfig) {
+				cfg.CAFile = caFile
+				cfg.InsecureSkipVerify = true
+			},
+			serverCert: stranger.Raw,
+		},
+		{
+			name: "server name not in certificate",
+			configure: func(cfg *TLSConfig) {
+				cfg.CAFile = caFile
+				cfg.ServerName = "plc-1.plant.local"
+			},
+			serverCert: signed.Raw,
+			wantErr:    "server certificate is not trusted",
+		},
+		{
+			name:       "missing server certificate",
+			configure:  func(cfg *TLSConfig) { cfg.CAFile = caFile },
+			serverCert: nil,
+			wantErr:    "server did not present a certificate",
+		},
+		{
+			name:       "no CA rejects expired",
+			configure:  func(*TLSConfig) {},
+			serverCert: expired.Raw,
+			wantErr:    "server certificate has expired: \"CN=PLC-3\"",
+		},
+		{
+			name:       "in trusted directory",
+			configure:  func(cfg *TLSConfig) { cfg.TrustedDirectory = trustedDir },
+			serverCert: pinned.Raw,
+		},
+		{
+			name:       "signed by CA in trusted directory",
+			configure:  func(cfg *TLSConfig) { cfg.TrustedDirectory = trustedDir },
+			serverCert: signed.Raw,
+		},
+		{
+			name:       "not in trusted directory",
+			configure:  func(cfg *TLSConfig) { cfg.TrustedDirectory = trustedDir },
+			serverCert: stranger.Raw,
+			wantErr:    "server certificate is not trusted by the tls CAs: \"CN=Unknown\" (thumbprint ",
+		},
+		{
+			name:       "expired in trusted directory",
+			configure:  func(cfg *TLSConfig) { cfg.TrustedDirectory = trustedDir },
+			serverCert: expired.Raw,
+			wantErr:    "server certificate has expired",
+		},
+		{
+			name:       "missing trusted directory",
+			configure:  func(cfg *TLSConfig) { cfg.TrustedDirectory = filepath.Join(t.TempDir(), "missing") },
+			serverCert: pinned.Raw,
+			wantErr:    "failed to read trusted_directory",
+		},
+		{
+			name:       "invalid file in trusted directory",
+			configure:  func(cfg *TLSConfig) { cfg.TrustedDirectory = invalidDir },
+			serverCert: pinned.Raw,
+			wantErr:    "failed to load trusted certificate " + filepath.Join(invalidDir, "README.txt"),
+		},
+		{
+			name:       "missing CA file",
+			configure:  func(cfg *TLSConfig) { cfg.CAFile = filepath.Join(t.TempDir(), "missing.pem") },
+			serverCert: signed.Raw,
+			wantErr:    "failed to load CA",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config).TLS
+			tt.configure(&cfg)
+			err := verifyServerCA(context.Background(), cfg, tt.serverCert)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestClientWireServerCA(t *testing.T) {
+	ws := startWireServer(t, withSecureEndpoints(), withUserAuthentication())
+	ws.AddLogRecords(wireRecords(1))
+
+	client, clientKey := newTestCertificate(t, "opcua-receiver", false, nil, nil)
+	stranger, _ := newTestCertificate(t, "stranger", false, nil, nil)
+	ws.TrustUserCertificate(client.Raw)
+	certFile, keyFile := writeCertificateFiles(t, client, clientKey)
+
+	tests := []struct {
+		name    string
+		ca      *x509.Certificate
+		wantErr string
+	}{
+		{name: "server certificate in ca_file", ca: ws.cert},
+		{name: "server certificate not in ca_file", ca: stranger, wantErr: "server certificate is not trusted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cfg := ws.newWireConfig()
+			cfg.SecurityPolicy = "Basic256Sha256"
+			cfg.SecurityMode = "SignAndEncrypt"
+			cfg.Auth.Type = "certificate"
+			cfg.TLS.CertFile = certFile
+			cfg.TLS.KeyFile = keyFile
+			cfg.TLS.CAFile = writeCAFile(t, tt.ca)
+			require.NoError(t, cfg.Validate())
+
+			c := newOPCUAClient(cfg, zap.NewNop())
+			err := c.Connect(ctx)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				assert.Equal(t, "connection", errorClass(err))
+				assert.False(t, c.IsConnected())
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, c.Disconnect(ctx))
+		})
+	}
+}
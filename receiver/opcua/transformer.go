@@ -6,55 +6,121 @@ package opcua
 import (
 	"encoding/hex"
 	"fmt"
+	"math"
 	"net"
 	"net/url"
+	"reflect"
 	"strconv"
 	"time"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
-	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/model"
 )
 
-// Transformer converts OPC UA log records to OpenTelemetry format
-type Transformer struct {
-	serverEndpoint   string
-	serviceName      string
-	serviceNamespace string
+const (
+	// bodyFormatString emits the message as the log body and AdditionalData
+	// as attributes
+	bodyFormatString = "string"
+
+	// bodyFormatMap emits a map of the message, source, event type and
+	// AdditionalData as the log body
+	bodyFormatMap = "map"
+)
+
+const (
+	// parentSpanParent sets the ParentSpanId of a record as the parent of
+	// its span
+	parentSpanParent = "parent"
+
+	// parentSpanLink adds a span link to the ParentSpanId of a record
+	parentSpanLink = "link"
+
+	// parentSpanNone ignores the ParentSpanId of a record
+	parentSpanNone = "none"
+)
+
+// recordTransformer converts OPC UA log records to OpenTelemetry format
+type recordTransformer struct {
+	serverEndpoint     string
+	serviceName        string
+	serviceNamespace   string
+	resourceAttributes metadata.ResourceAttributesConfig
+	migrator           attributeMigrator
+
+	// attributeMappings renames AdditionalData entries, see attribute_mappings
+	attributeMappings map[string]string
+
+	// resourcePerSource groups log records into one resource per source
+	resourcePerSource bool
+
+	// mapBody emits structured log bodies, see body_format
+	mapBody bool
+
+	// parentSpan relates spans to the ParentSpanId of their records and
+	// omitSpanEvents leaves the records out of the spans, see traces
+	parentSpan     string
+	omitSpanEvents bool
+
+	// serverInfo returns the description of the server read on the last
+	// connect, if the client provides one
+	serverInfo func() (serverInfo, bool)
+
+	// severities maps the proprietary severity values of the server, if any
+	severities severityDictionary
+
+	// severityRanges replaces the Part 26 ranges if severity_mapping is set
+	severityRanges severityRanges
 }
 
-// NewTransformer creates a new transformer
-func NewTransformer(serverEndpoint, serviceName, serviceNamespace string) *Transformer {
+// newRecordTransformer creates a new transformer with the default resource attribute settings
+func newRecordTransformer(serverEndpoint, serviceName, serviceNamespace string) *recordTransformer {
 	if serviceName == "" {
 		serviceName = "opcua-server"
 	}
-	return &Transformer{
-		serverEndpoint:   serverEndpoint,
-		serviceName:      serviceName,
-		serviceNamespace: serviceNamespace,
+	return &recordTransformer{
+		serverEndpoint:     serverEndpoint,
+		serviceName:        serviceName,
+		serviceNamespace:   serviceNamespace,
+		resourceAttributes: metadata.DefaultResourceAttributesConfig(),
+		migrator:           newAttributeMigrator(attributeMigrations),
 	}
 }
 
+// newTransformerFromConfig creates a transformer from the receiver configuration,
+// honouring the per-attribute enable flags in resource_attributes.
+func newTransformerFromConfig(cfg *Config) *recordTransformer {
+	t := newRecordTransformer(cfg.Endpoint, cfg.Resource.ServiceName, cfg.Resource.ServiceNamespace)
+	t.resourceAttributes = cfg.ResourceAttributes
+	t.severities = newSeverityDictionary(cfg.SeverityDictionary.Entries)
+	t.severityRanges = newSeverityRanges(cfg.SeverityMapping)
+	t.attributeMappings = cfg.AttributeMappings
+	t.resourcePerSource = cfg.ResourcePerSource
+	t.mapBody = cfg.BodyFormat == bodyFormatMap
+	t.parentSpan = cfg.Traces.ParentSpan
+	t.omitSpanEvents = !cfg.Traces.SpanEvents
+	return t
+}
+
 // TransformLogs converts OPC UA log records to OpenTelemetry plog.Logs
-func (t *Transformer) TransformLogs(opcuaRecords []testdata.OPCUALogRecord) plog.Logs {
+func (t *recordTransformer) TransformLogs(opcuaRecords []model.LogRecord) plog.Logs {
 	logs := plog.NewLogs()
 
 	if len(opcuaRecords) == 0 {
 		return logs
 	}
 
-	// Create resource logs
-	resourceLogs := logs.ResourceLogs().AppendEmpty()
-
-	// Set resource attributes
-	resource := resourceLogs.Resource()
-	t.setResourceAttributes(resource.Attributes())
+	if t.resourcePerSource {
+		t.transformLogsPerSource(opcuaRecords, logs)
+		return logs
+	}
 
-	// Create scope logs
-	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
-	scopeLogs.Scope().SetName("github.com/bruegth/opentelemetry-collector-opcua-receiver")
-	scopeLogs.Scope().SetVersion("0.1.0")
+	// Create resource logs with the resource attributes of the server
+	scopeLogs := appendScopeLogs(logs, t.buildResource())
 
 	// Transform each OPC UA log record
 	for _, opcuaRecord := range opcuaRecords {
@@ -65,13 +131,276 @@ func (t *Transformer) TransformLogs(opcuaRecords []testdata.OPCUALogRecord) plog
 	return logs
 }
 
-// setResourceAttributes sets resource-level attributes.
+// transformLogsPerSource adds the records to logs with one resource per
+// SourceNode, or per SourceName for records without a SourceNode, in the
+// order the sources first appear. The resources carry the attributes of the
+// server and of their source; records with neither share the server's resource.
+func (t *recordTransformer) transformLogsPerSource(opcuaRecords []model.LogRecord, logs plog.Logs) {
+	type sourceKey struct {
+		node string
+		name string
+	}
+	sources := make(map[sourceKey]plog.ScopeLogs)
+
+	for _, opcuaRecord := range opcuaRecords {
+		key := sourceKey{node: sourceNode(opcuaRecord)}
+		if key.node == "" {
+			key.name = opcuaRecord.SourceName
+		}
+
+		scopeLogs, ok := sources[key]
+		if !ok {
+			resource := t.buildResource()
+			rb := metadata.NewResourceBuilder(t.resourceAttributes)
+			if key.node != "" {
+				rb.SetOpcuaSourceNode(key.node)
+			}
+			if opcuaRecord.SourceName != "" {
+				rb.SetOpcuaSourceName(opcuaRecord.SourceName)
+			}
+			rb.Emit().Attributes().CopyTo(resource.Attributes())
+			scopeLogs = appendScopeLogs(logs, resource)
+			sources[key] = scopeLogs
+		}
+
+		t.transformLogRecord(opcuaRecord, scopeLogs.LogRecords().AppendEmpty())
+	}
+}
+
+// appendScopeLogs appends resource logs with resource to logs and returns
+// their scope logs
+func appendScopeLogs(logs plog.Logs, resource pcommon.Resource) plog.ScopeLogs {
+	resourceLogs := logs.ResourceLogs().AppendEmpty()
+	resource.MoveTo(resourceLogs.Resource())
+
+	scopeLogs := resourceLogs.ScopeLogs().AppendEmpty()
+	scopeLogs.Scope().SetName("github.com/bruegth/opentelemetry-collector-opcua-receiver")
+	scopeLogs.Scope().SetVersion("0.1.0")
+	return scopeLogs
+}
+
+// sourceNode returns the SourceNode of a record in NodeId string notation,
+// or "" if the record has none
+func sourceNode(opcuaRecord model.LogRecord) string {
+	var identifier string
+	switch opcuaRecord.SourceIDType {
+	case "":
+		return ""
+	case "Numeric":
+		identifier = "i"
+	case "String":
+		identifier = "s"
+	case "Guid":
+		identifier = "g"
+	default:
+		identifier = "b"
+	}
+	if opcuaRecord.SourceNamespaceURI != "" {
+		return fmt.Sprintf("nsu=%s;%s=%s", opcuaRecord.SourceNamespaceURI, identifier, opcuaRecord.SourceID)
+	}
+	return fmt.Sprintf("ns=%d;%s=%s", opcuaRecord.SourceNamespace, identifier, opcuaRecord.SourceID)
+}
+
+// TransformMetrics converts OPC UA log records to a delta sum counting records per
+// LogObject and severity band over the collection window [start, end]
+func (t *recordTransformer) TransformMetrics(opcuaRecords []model.LogRecord, start, end time.Time) pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+
+	if len(opcuaRecords) == 0 {
+		return metrics
+	}
+
+	resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+	t.buildResource().MoveTo(resourceMetrics.Resource())
+
+	scopeMetrics := resourceMetrics.ScopeMetrics().AppendEmpty()
+	scopeMetrics.Scope().SetName("github.com/bruegth/opentelemetry-collector-opcua-receiver")
+	scopeMetrics.Scope().SetVersion("0.1.0")
+
+	metric := scopeMetrics.Metrics().AppendEmpty()
+	metric.SetName("opcua.log.records")
+	metric.SetDescription("Number of log records read from the OPC UA server.")
+	metric.SetUnit("{records}")
+	sum := metric.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	sum.SetIsMonotonic(true)
+
+	// Count records per LogObject and severity band, keeping first-seen order
+	// so the output is deterministic
+	counts := make(map[severityBandKey]int64)
+	var keys []severityBandKey
+	for _, record := range opcuaRecords {
+		key := severityBandKey{logObjectID: record.LogObjectID, band: severityToText(record.Severity)}
+		if _, ok := counts[key]; !ok {
+			keys = append(keys, key)
+		}
+		counts[key]++
+	}
+
+	startTimestamp := pcommon.NewTimestampFromTime(start)
+	endTimestamp := pcommon.NewTimestampFromTime(end)
+	for _, key := range keys {
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(startTimestamp)
+		dp.SetTimestamp(endTimestamp)
+		dp.SetIntValue(counts[key])
+		if key.logObjectID != "" {
+			dp.Attributes().PutStr("opcua.log_object", key.logObjectID)
+		}
+		dp.Attributes().PutStr("opcua.severity_band", key.band)
+	}
+
+	return metrics
+}
+
+// TransformTraces reconstructs spans from OPC UA log records carrying trace context.
+// Records sharing a TraceID and SpanID form one span that covers their timestamps;
+// each record is added to it as a span event unless span events are disabled. The
+// first ParentSpanID of a span's records becomes its parent span or a span link.
+// Records without trace context are skipped.
+func (t *recordTransformer) TransformTraces(opcuaRecords []model.LogRecord) ptrace.Traces {
+	traces := ptrace.NewTraces()
+
+	type spanKey struct {
+		traceID string
+		spanID  string
+	}
+	spans := make(map[spanKey]ptrace.Span)
+	var scopeSpans ptrace.ScopeSpans
+
+	for _, opcuaRecord := range opcuaRecords {
+		traceID, spanID, ok := parseTraceContext(opcuaRecord.TraceID, opcuaRecord.SpanID)
+		if !ok {
+			continue
+		}
+
+		if traces.ResourceSpans().Len() == 0 {
+			resourceSpans := traces.ResourceSpans().AppendEmpty()
+			t.buildResource().MoveTo(resourceSpans.Resource())
+			scopeSpans = resourceSpans.ScopeSpans().AppendEmpty()
+			scopeSpans.Scope().SetName("github.com/bruegth/opentelemetry-collector-opcua-receiver")
+			scopeSpans.Scope().SetVersion("0.1.0")
+		}
+
+		timestamp := pcommon.NewTimestampFromTime(opcuaRecord.Timestamp)
+		key := spanKey{traceID: opcuaRecord.TraceID, spanID: opcuaRecord.SpanID}
+		span, exists := spans[key]
+		if !exists {
+			span = scopeSpans.Spans().AppendEmpty()
+			span.SetTraceID(traceID)
+			span.SetSpanID(spanID)
+			span.SetName(spanName(opcuaRecord))
+			span.SetKind(ptrace.SpanKindInternal)
+			span.SetStartTimestamp(timestamp)
+			span.SetEndTimestamp(timestamp)
+			if opcuaRecord.SourceName != "" {
+				span.Attributes().PutStr("opcua.source.name", opcuaRecord.SourceName)
+			}
+			spans[key] = span
+		}
+
+		if timestamp < span.StartTimestamp() {
+			span.SetStartTimestamp(timestamp)
+		}
+		if timestamp > span.EndTimestamp() {
+			span.SetEndTimestamp(timestamp)
+		}
+		if opcuaRecord.Severity > 200 {
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+		t.setParentSpan(span, traceID, opcuaRecord.ParentSpanID)
+
+		if t.omitSpanEvents {
+			continue
+		}
+		event := span.Events().AppendEmpty()
+		event.SetTimestamp(timestamp)
+		event.SetName(opcuaRecord.Message)
+		event.Attributes().PutStr("opcua.severity_band", severityToText(opcuaRecord.Severity))
+		for k, value := range opcuaRecord.Attributes {
+			t.putAttribute(event.Attributes(), t.attributeName(k), value)
+		}
+		t.migrator.apply(event.Attributes())
+	}
+
+	return traces
+}
+
+// setParentSpan relates span to the span of the hex encoded parentSpanID
+// according to traces.parent_span, unless it was related to a parent before
+func (t *recordTransformer) setParentSpan(span ptrace.Span, traceID pcommon.TraceID, parentSpanID string) {
+	if t.parentSpan == parentSpanNone || parentSpanID == "" {
+		return
+	}
+	parent, ok := parseSpanID(parentSpanID)
+	if !ok {
+		return
+	}
+
+	if t.parentSpan == parentSpanLink {
+		if span.Links().Len() > 0 {
+			return
+		}
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(traceID)
+		link.SetSpanID(parent)
+		return
+	}
+	if span.ParentSpanID().IsEmpty() {
+		span.SetParentSpanID(parent)
+	}
+}
+
+// spanName returns the name of a span reconstructed from a log record
+func spanName(opcuaRecord model.LogRecord) string {
+	if opcuaRecord.SourceName != "" {
+		return opcuaRecord.SourceName
+	}
+	return "opcua.log"
+}
+
+// parseTraceContext decodes hex encoded trace and span IDs, reporting false if
+// either is missing or malformed
+func parseTraceContext(traceID, spanID string) (pcommon.TraceID, pcommon.SpanID, bool) {
+	traceIDBytes, err := hex.DecodeString(traceID)
+	if err != nil || len(traceIDBytes) != 16 {
+		return pcommon.TraceID{}, pcommon.SpanID{}, false
+	}
+	spanIDValue, ok := parseSpanID(spanID)
+	if !ok {
+		return pcommon.TraceID{}, pcommon.SpanID{}, false
+	}
+	var traceIDArray [16]byte
+	copy(traceIDArray[:], traceIDBytes)
+	return pcommon.TraceID(traceIDArray), spanIDValue, true
+}
+
+// parseSpanID decodes a hex encoded span ID, reporting false if it is
+// missing or malformed
+func parseSpanID(spanID string) (pcommon.SpanID, bool) {
+	spanIDBytes, err := hex.DecodeString(spanID)
+	if err != nil || len(spanIDBytes) != 8 {
+		return pcommon.SpanID{}, false
+	}
+	var spanIDArray [8]byte
+	copy(spanIDArray[:], spanIDBytes)
+	return pcommon.SpanID(spanIDArray), true
+}
+
+// buildResource builds the resource emitted with every batch of log records.
 // server.address and server.port are the OTel semantic conventions for describing
 // the remote server being connected to (not the local host running the collector).
-func (t *Transformer) setResourceAttributes(attrs pcommon.Map) {
-	attrs.PutStr("service.name", t.serviceName)
+func (t *recordTransformer) buildResource() pcommon.Resource {
+	rb := metadata.NewResourceBuilder(t.resourceAttributes)
+	rb.SetServiceName(t.serviceName)
 	if t.serviceNamespace != "" {
-		attrs.PutStr("service.namespace", t.serviceNamespace)
+		rb.SetServiceNamespace(t.serviceNamespace)
+	}
+	rb.SetOpcuaServerEndpoint(t.serverEndpoint)
+	if t.serverInfo != nil {
+		if info, ok := t.serverInfo(); ok {
+			info.setResourceAttributes(rb)
+		}
 	}
 
 	// Parse the OPC UA endpoint URI (e.g. "opc.tcp://hostname:4840/path")
@@ -79,30 +408,36 @@ func (t *Transformer) setResourceAttributes(attrs pcommon.Map) {
 	if u, err := url.Parse(t.serverEndpoint); err == nil && u.Host != "" {
 		host, portStr, err := net.SplitHostPort(u.Host)
 		if err == nil {
-			attrs.PutStr("server.address", host)
+			rb.SetServerAddress(host)
 			if port, err := strconv.ParseInt(portStr, 10, 64); err == nil {
-				attrs.PutInt("server.port", port)
+				rb.SetServerPort(port)
 			}
 		} else {
 			// No port in the host (unusual for OPC UA, but handle gracefully)
-			attrs.PutStr("server.address", u.Host)
+			rb.SetServerAddress(u.Host)
 		}
 	}
+
+	return rb.Emit()
 }
 
 // transformLogRecord converts a single OPC UA log record to OTEL format
-func (t *Transformer) transformLogRecord(opcuaRecord testdata.OPCUALogRecord, logRecord plog.LogRecord) {
+func (t *recordTransformer) transformLogRecord(opcuaRecord model.LogRecord, logRecord plog.LogRecord) {
 	// Set timestamp
 	logRecord.SetTimestamp(pcommon.NewTimestampFromTime(opcuaRecord.Timestamp))
 	logRecord.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
 
 	// Map severity
-	severityNumber := t.mapSeverity(opcuaRecord.Severity)
+	severityNumber, severityText := t.severity(opcuaRecord.Severity)
 	logRecord.SetSeverityNumber(severityNumber)
-	logRecord.SetSeverityText(severityToText(opcuaRecord.Severity))
+	logRecord.SetSeverityText(severityText)
 
 	// Set log body
-	logRecord.Body().SetStr(opcuaRecord.Message)
+	if t.mapBody {
+		t.setMapBody(opcuaRecord, logRecord.Body().SetEmptyMap())
+	} else {
+		logRecord.Body().SetStr(opcuaRecord.Message)
+	}
 
 	// Set attributes
 	attrs := logRecord.Attributes()
@@ -115,20 +450,85 @@ func (t *Transformer) transformLogRecord(opcuaRecord testdata.OPCUALogRecord, lo
 		attrs.PutStr("opcua.source.id_type", opcuaRecord.SourceIDType)
 		attrs.PutStr("opcua.source.id", opcuaRecord.SourceID)
 	}
+	if opcuaRecord.SourceNamespaceURI != "" {
+		attrs.PutStr("opcua.source.namespace_uri", opcuaRecord.SourceNamespaceURI)
+	}
+	if opcuaRecord.MessageLocale != "" {
+		attrs.PutStr("opcua.message.locale", opcuaRecord.MessageLocale)
+	}
 
-	// Add custom attributes from OPC UA log
-	for key, value := range opcuaRecord.Attributes {
-		t.putAttribute(attrs, key, value)
+	// Add custom attributes from OPC UA log under their mapped names, unless
+	// the body carries them
+	if !t.mapBody {
+		for key, value := range opcuaRecord.Attributes {
+			t.putAttribute(attrs, t.attributeName(key), value)
+		}
 	}
 
+	// Apply feature-gated attribute renames
+	t.migrator.apply(attrs)
+
 	// Set trace context if available
 	if opcuaRecord.TraceID != "" && opcuaRecord.SpanID != "" {
 		t.setTraceContext(logRecord, opcuaRecord.TraceID, opcuaRecord.SpanID, opcuaRecord.TraceFlags)
 	}
 }
 
+// setMapBody fills body with the message, source, event type and
+// AdditionalData of a record. Absent fields are omitted.
+func (t *recordTransformer) setMapBody(opcuaRecord model.LogRecord, body pcommon.Map) {
+	body.PutStr("message", opcuaRecord.Message)
+	if opcuaRecord.MessageLocale != "" {
+		body.PutStr("locale", opcuaRecord.MessageLocale)
+	}
+
+	node := sourceNode(opcuaRecord)
+	if node != "" || opcuaRecord.SourceName != "" {
+		source := body.PutEmptyMap("source")
+		if opcuaRecord.SourceName != "" {
+			source.PutStr("name", opcuaRecord.SourceName)
+		}
+		if node != "" {
+			source.PutStr("node", node)
+		}
+	}
+
+	if opcuaRecord.EventType != "" {
+		body.PutStr("event_type", opcuaRecord.EventType)
+	}
+
+	if len(opcuaRecord.Attributes) > 0 {
+		data := body.PutEmptyMap("additional_data")
+		data.EnsureCapacity(len(opcuaRecord.Attributes))
+		for key, value := range opcuaRecord.Attributes {
+			t.putAttribute(data, t.attributeName(key), value)
+		}
+	}
+}
+
+// severity returns the SeverityNumber and text of an OPC UA severity value,
+// taken from the server's severity dictionary if it has an entry for the value
+// and derived from severity_mapping or the Part 26 ranges otherwise
+func (t *recordTransformer) severity(opcuaSeverity uint16) (plog.SeverityNumber, string) {
+	number := t.mapSeverity(opcuaSeverity)
+	text := severityToText(opcuaSeverity)
+	if r, ok := t.severityRanges.lookup(opcuaSeverity); ok && r.text != "" {
+		text = r.text
+	}
+	if entry, ok := t.severities[opcuaSeverity]; ok {
+		if entry.number != plog.SeverityNumberUnspecified {
+			number = entry.number
+		}
+		if entry.text != "" {
+			text = entry.text
+		}
+	}
+	return number, text
+}
+
 // mapSeverity maps an OPC UA Part 26 §5.4 severity value to an OpenTelemetry SeverityNumber.
 // Severity text is not transmitted over OPC UA; it is derived separately by severityToText.
+// The ranges of severity_mapping, if configured, replace the table below.
 //
 // Part 26 §5.4 Table 5 → OTel mapping:
 //
@@ -140,7 +540,14 @@ func (t *Transformer) transformLogRecord(opcuaRecord testdata.OPCUALogRecord, lo
 //	251–300: Critical    → SeverityNumberError2
 //	301–400: Alert       → SeverityNumberError3
 //	401–1000: Emergency  → SeverityNumberFatal
-func (t *Transformer) mapSeverity(opcuaSeverity uint16) plog.SeverityNumber {
+func (t *recordTransformer) mapSeverity(opcuaSeverity uint16) plog.SeverityNumber {
+	if t.severityRanges != nil {
+		if r, ok := t.severityRanges.lookup(opcuaSeverity); ok {
+			return r.number
+		}
+		return plog.SeverityNumberUnspecified
+	}
+
 	switch {
 	case opcuaSeverity >= 1 && opcuaSeverity <= 50:
 		return plog.SeverityNumberDebug
@@ -200,7 +607,7 @@ func severityToText(severity uint16) string {
 }
 
 // setTraceContext sets the trace context from OPC UA
-func (t *Transformer) setTraceContext(logRecord plog.LogRecord, traceID, spanID string, flags byte) {
+func (t *recordTransformer) setTraceContext(logRecord plog.LogRecord, traceID, spanID string, flags byte) {
 	// Parse TraceID (32-character hex string to 16 bytes)
 	traceIDBytes, err := hex.DecodeString(traceID)
 	if err == nil && len(traceIDBytes) == 16 {
@@ -225,20 +632,87 @@ func (t *Transformer) setTraceContext(logRecord plog.LogRecord, traceID, spanID
 	logRecord.SetFlags(logFlags)
 }
 
+// attributeName returns the name the AdditionalData entry key is emitted
+// under, which is key unless attribute_mappings renames it
+func (t *recordTransformer) attributeName(key string) string {
+	if name, ok := t.attributeMappings[key]; ok {
+		return name
+	}
+	return key
+}
+
 // putAttribute adds an attribute with type detection
-func (t *Transformer) putAttribute(attrs pcommon.Map, key string, value interface{}) {
+func (t *recordTransformer) putAttribute(attrs pcommon.Map, key string, value interface{}) {
+	setAttributeValue(attrs.PutEmpty(key), value)
+}
+
+// setAttributeValue sets dest with type detection. Arrays and matrices of
+// AdditionalData become slices and ExtensionObjects maps; their elements are
+// widened to int64 and float64 first, so numeric arrays stay numeric.
+func setAttributeValue(dest pcommon.Value, value interface{}) {
 	switch v := value.(type) {
 	case string:
-		attrs.PutStr(key, v)
+		dest.SetStr(v)
 	case int:
-		attrs.PutInt(key, int64(v))
+		dest.SetInt(int64(v))
 	case int64:
-		attrs.PutInt(key, v)
+		dest.SetInt(v)
 	case float64:
-		attrs.PutDouble(key, v)
+		dest.SetDouble(v)
 	case bool:
-		attrs.PutBool(key, v)
+		dest.SetBool(v)
+	case time.Time:
+		dest.SetStr(v.Format(time.RFC3339Nano))
+	case []byte:
+		dest.SetEmptyBytes().FromRaw(v)
+	case []interface{}:
+		slice := dest.SetEmptySlice()
+		slice.EnsureCapacity(len(v))
+		for _, element := range v {
+			setAttributeValue(slice.AppendEmpty(), widenNumber(element))
+		}
+	case map[string]interface{}:
+		fields := dest.SetEmptyMap()
+		fields.EnsureCapacity(len(v))
+		for name, field := range v {
+			setAttributeValue(fields.PutEmpty(name), widenNumber(field))
+		}
 	default:
-		attrs.PutStr(key, fmt.Sprintf("%v", v))
+		// Typed arrays, such as those gopcua decodes for event fields
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+			elements := make([]interface{}, rv.Len())
+			for i := range elements {
+				elements[i] = rv.Index(i).Interface()
+			}
+			setAttributeValue(dest, elements)
+			return
+		}
+		dest.SetStr(fmt.Sprintf("%v", v))
+	}
+}
+
+// widenNumber converts the integer and floating point types of the Variant
+// decoding to int64 and float64. UInt64 values beyond int64 are kept.
+func widenNumber(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		if v <= math.MaxInt64 {
+			return int64(v)
+		}
+	case float32:
+		return float64(v)
 	}
+	return value
 }
@@ -9,13 +9,16 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
 )
 
 func TestTransformLogs(t *testing.T) {
-	transformer := NewTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
 
 	timestamp := time.Now()
 	opcuaRecords := []testdata.OPCUALogRecord{
@@ -55,68 +58,7 @@ func TestTransformLogs(t *testing.T) {
 	}
 
 	logs := transformer.TransformLogs(opcuaRecords)
-
-	require.Equal(t, 1, logs.ResourceLogs().Len())
-
-	resourceLogs := logs.ResourceLogs().At(0)
-
-	// Check resource attributes
-	resource := resourceLogs.Resource()
-	serverAddrAttr, ok := resource.Attributes().Get("server.address")
-	require.True(t, ok)
-	assert.Equal(t, "test", serverAddrAttr.Str())
-
-	serverPortAttr, ok := resource.Attributes().Get("server.port")
-	require.True(t, ok)
-	assert.Equal(t, int64(4840), serverPortAttr.Int())
-
-	serviceNameAttr, ok := resource.Attributes().Get("service.name")
-	require.True(t, ok)
-	assert.Equal(t, "opcua-server", serviceNameAttr.Str())
-
-	// Check scope logs
-	require.Equal(t, 1, resourceLogs.ScopeLogs().Len())
-	scopeLogs := resourceLogs.ScopeLogs().At(0)
-	assert.Equal(t, "github.com/bruegth/opentelemetry-collector-opcua-receiver", scopeLogs.Scope().Name())
-
-	// Check log records
-	require.Equal(t, 2, scopeLogs.LogRecords().Len())
-
-	// First log record
-	logRecord1 := scopeLogs.LogRecords().At(0)
-	assert.Equal(t, plog.SeverityNumberError2, logRecord1.SeverityNumber())
-	assert.Equal(t, "Critical", logRecord1.SeverityText())
-	assert.Equal(t, "Test message 1", logRecord1.Body().Str())
-
-	sourceNameAttr, ok := logRecord1.Attributes().Get("opcua.source.name")
-	require.True(t, ok)
-	assert.Equal(t, "TestSource", sourceNameAttr.Str())
-
-	sourceNsAttr, ok := logRecord1.Attributes().Get("opcua.source.namespace")
-	require.True(t, ok)
-	assert.Equal(t, int64(1), sourceNsAttr.Int())
-
-	sourceIDTypeAttr, ok := logRecord1.Attributes().Get("opcua.source.id_type")
-	require.True(t, ok)
-	assert.Equal(t, "Numeric", sourceIDTypeAttr.Str())
-
-	sourceIDAttr, ok := logRecord1.Attributes().Get("opcua.source.id")
-	require.True(t, ok)
-	assert.Equal(t, "100", sourceIDAttr.Str())
-
-	key1Attr, ok := logRecord1.Attributes().Get("key1")
-	require.True(t, ok)
-	assert.Equal(t, "value1", key1Attr.Str())
-
-	key2Attr, ok := logRecord1.Attributes().Get("key2")
-	require.True(t, ok)
-	assert.Equal(t, int64(42), key2Attr.Int())
-
-	// Second log record
-	logRecord2 := scopeLogs.LogRecords().At(1)
-	assert.Equal(t, plog.SeverityNumberFatal, logRecord2.SeverityNumber())
-	assert.Equal(t, "Emergency", logRecord2.SeverityText())
-	assert.Equal(t, "Test error message", logRecord2.Body().Str())
+	requireLogs(t, opcuaRecords, logs)
 }
 
 func TestTransformLogsResourceConfig(t *testing.T) {
@@ -152,7 +94,7 @@ func TestTransformLogsResourceConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			transformer := NewTransformer("opc.tcp://test:4840", tt.serviceName, tt.serviceNamespace)
+			transformer := newRecordTransformer("opc.tcp://test:4840", tt.serviceName, tt.serviceNamespace)
 			logs := transformer.TransformLogs([]testdata.OPCUALogRecord{
 				{Timestamp: time.Now(), Severity: 150, Message: "probe"},
 			})
@@ -174,8 +116,28 @@ func TestTransformLogsResourceConfig(t *testing.T) {
 	}
 }
 
+func TestTransformLogsResourceAttributesConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = "opc.tcp://test:4840"
+	cfg.Resource.ServiceNamespace = "production"
+	cfg.ResourceAttributes.ServerPort.Enabled = false
+	cfg.ResourceAttributes.ServiceNamespace.Enabled = false
+	cfg.ResourceAttributes.OpcuaServerEndpoint.Enabled = true
+
+	records := []testdata.OPCUALogRecord{{Timestamp: time.Now(), Severity: 150, Message: "probe"}}
+	logs := newTransformerFromConfig(cfg).TransformLogs(records)
+
+	// server.port and service.namespace are disabled, opcua.server.endpoint is enabled
+	requireLogs(t, records, logs,
+		withExpectedService("opcua-server", "production"),
+		withExpectedResourceAttributes(cfg.ResourceAttributes))
+	attrs := logs.ResourceLogs().At(0).Resource().Attributes()
+	_, ok := attrs.Get("opcua.server.endpoint")
+	assert.True(t, ok)
+}
+
 func TestTransformLogsEmpty(t *testing.T) {
-	transformer := NewTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
 
 	logs := transformer.TransformLogs([]testdata.OPCUALogRecord{})
 
@@ -183,7 +145,7 @@ func TestTransformLogsEmpty(t *testing.T) {
 }
 
 func TestMapSeverity(t *testing.T) {
-	transformer := NewTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
 
 	// OPC UA Part 26 §5.4 Table 5 → OTel SeverityNumber mapping
 	tests := []struct {
@@ -233,7 +195,7 @@ func TestMapSeverity(t *testing.T) {
 }
 
 func TestSetTraceContext(t *testing.T) {
-	transformer := NewTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
 
 	opcuaRecord := testdata.OPCUALogRecord{
 		Timestamp: time.Now(),
@@ -264,7 +226,7 @@ func TestSetTraceContext(t *testing.T) {
 }
 
 func TestPutAttribute(t *testing.T) {
-	transformer := NewTransformer("opc.tcp://test:4840", "opcua-server", "")
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
 
 	opcuaRecord := testdata.OPCUALogRecord{
 		Timestamp: time.Now(),
@@ -311,6 +273,250 @@ func TestPutAttribute(t *testing.T) {
 	assert.Equal(t, true, val.Bool())
 }
 
+func TestTransformLogsCompositeAttributes(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+	started := time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)
+
+	logs := transformer.TransformLogs([]testdata.OPCUALogRecord{{
+		Timestamp: time.Now(),
+		Severity:  300,
+		Message:   "composite",
+		Attributes: map[string]interface{}{
+			"Limits":  []interface{}{float32(1.5), 25.0},
+			"Matrix":  []interface{}{[]interface{}{int32(1), uint16(2)}, []interface{}{int8(3), uint64(4)}},
+			"Order":   map[string]interface{}{"Quantity": int32(5), "Started": started, "Tags": []string{"A", "B"}},
+			"Raw":     []byte{0xCA, 0xFE},
+			"Started": started,
+		},
+	}})
+	attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+
+	expected := map[string]interface{}{
+		"Limits": []interface{}{1.5, 25.0},
+		"Matrix": []interface{}{[]interface{}{int64(1), int64(2)}, []interface{}{int64(3), int64(4)}},
+		"Order": map[string]interface{}{
+			"Quantity": int64(5),
+			"Started":  "2025-01-15T09:30:00Z",
+			"Tags":     []interface{}{"A", "B"},
+		},
+		"Raw":     []byte{0xCA, 0xFE},
+		"Started": "2025-01-15T09:30:00Z",
+	}
+	for name, value := range expected {
+		got, ok := attrs.Get(name)
+		require.True(t, ok, name)
+		assert.Equal(t, value, got.AsRaw(), name)
+	}
+}
+
+func TestTransformLogsMessageLocale(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+
+	logs := transformer.TransformLogs([]testdata.OPCUALogRecord{
+		{Timestamp: time.Now(), Severity: 300, Message: "Druck zu hoch", MessageLocale: "de-DE"},
+		{Timestamp: time.Now(), Severity: 300, Message: "no locale"},
+	})
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+
+	locale, ok := lrs.At(0).Attributes().Get("opcua.message.locale")
+	require.True(t, ok)
+	assert.Equal(t, "de-DE", locale.Str())
+	_, ok = lrs.At(1).Attributes().Get("opcua.message.locale")
+	assert.False(t, ok)
+}
+
+func TestTransformLogsMapBody(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.BodyFormat = bodyFormatMap
+	cfg.AttributeMappings = map[string]string{"LineNo": "production.line"}
+	transformer := newTransformerFromConfig(cfg)
+
+	logs := transformer.TransformLogs([]testdata.OPCUALogRecord{
+		{
+			Timestamp:       time.Now(),
+			Severity:        700,
+			Message:         "Motor überhitzt",
+			MessageLocale:   "de-DE",
+			SourceName:      "Press3",
+			SourceNamespace: 2,
+			SourceIDType:    "String",
+			SourceID:        "Line1.Press3",
+			EventType:       "i=2041",
+			Attributes:      map[string]interface{}{"LineNo": int32(1), "limits": []interface{}{90.0, 100.0}},
+		},
+		{Timestamp: time.Now(), Severity: 100, Message: "bare"},
+	})
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+
+	assert.Equal(t, map[string]interface{}{
+		"message":    "Motor überhitzt",
+		"locale":     "de-DE",
+		"source":     map[string]interface{}{"name": "Press3", "node": "ns=2;s=Line1.Press3"},
+		"event_type": "i=2041",
+		"additional_data": map[string]interface{}{
+			"production.line": int64(1),
+			"limits":          []interface{}{90.0, 100.0},
+		},
+	}, lrs.At(0).Body().AsRaw())
+
+	// AdditionalData move to the body, the receiver's attributes stay
+	attrs := lrs.At(0).Attributes()
+	_, ok := attrs.Get("production.line")
+	assert.False(t, ok)
+	name, ok := attrs.Get("opcua.source.name")
+	require.True(t, ok)
+	assert.Equal(t, "Press3", name.Str())
+
+	assert.Equal(t, map[string]interface{}{"message": "bare"}, lrs.At(1).Body().AsRaw())
+}
+
+func TestTransformLogsVendorAttributes(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+	records := testdata.NewSeededGenerator(34, time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)).VendorRecords(8)
+
+	logs := transformer.TransformLogs(records)
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, len(records), lrs.Len())
+
+	for i, record := range records {
+		attrs := lrs.At(i).Attributes()
+		for k, v := range record.Attributes {
+			val, ok := attrs.Get(k)
+			require.True(t, ok, "record %d: key %q missing", i, k)
+			switch v := v.(type) {
+			case string:
+				assert.Equal(t, v, val.Str())
+			case bool:
+				assert.Equal(t, v, val.Bool())
+			case int64:
+				assert.Equal(t, v, val.Int())
+			case float64:
+				assert.Equal(t, v, val.Double())
+			}
+		}
+	}
+}
+
+func TestTransformLogsAttributeMappings(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AttributeMappings = map[string]string{"sensor_id": "device.id"}
+	transformer := newTransformerFromConfig(cfg)
+
+	records := []testdata.OPCUALogRecord{{
+		Timestamp: time.Now(),
+		Severity:  100,
+		Message:   "Temperature high",
+		TraceID:   "0123456789abcdef0123456789abcdef",
+		SpanID:    "0123456789abcdef",
+		Attributes: map[string]interface{}{
+			"sensor_id": "TT-101",
+			"unit":      "degC",
+		},
+	}}
+
+	logs := transformer.TransformLogs(records)
+	attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	deviceID, ok := attrs.Get("device.id")
+	require.True(t, ok)
+	assert.Equal(t, "TT-101", deviceID.Str())
+	_, ok = attrs.Get("sensor_id")
+	assert.False(t, ok, "mapped names are not emitted")
+	unit, ok := attrs.Get("unit")
+	require.True(t, ok, "names without a mapping are kept")
+	assert.Equal(t, "degC", unit.Str())
+
+	traces := transformer.TransformTraces(records)
+	eventAttrs := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Events().At(0).Attributes()
+	_, ok = eventAttrs.Get("device.id")
+	assert.True(t, ok, "span events use the mapped names as well")
+}
+
+func TestTransformLogsResourcePerSource(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ResourcePerSource = true
+	transformer := newTransformerFromConfig(cfg)
+
+	now := time.Now()
+	records := []testdata.OPCUALogRecord{
+		{Timestamp: now, Severity: 100, Message: "press 1", SourceName: "Press1", SourceNamespace: 2, SourceIDType: "String", SourceID: "Line1.Press1"},
+		{Timestamp: now, Severity: 100, Message: "press 2", SourceName: "Press2", SourceNamespace: 2, SourceIDType: "Numeric", SourceID: "1002"},
+		{Timestamp: now, Severity: 200, Message: "press 1 again", SourceName: "Press1", SourceNamespace: 2, SourceIDType: "String", SourceID: "Line1.Press1"},
+		{Timestamp: now, Severity: 100, Message: "named only", SourceName: "Conveyor"},
+		{Timestamp: now, Severity: 100, Message: "no source"},
+	}
+
+	logs := transformer.TransformLogs(records)
+	require.Equal(t, 4, logs.ResourceLogs().Len())
+	assert.Equal(t, len(records), logs.LogRecordCount())
+
+	tests := []struct {
+		node     string
+		name     string
+		messages []string
+	}{
+		{node: "ns=2;s=Line1.Press1", name: "Press1", messages: []string{"press 1", "press 1 again"}},
+		{node: "ns=2;i=1002", name: "Press2", messages: []string{"press 2"}},
+		{name: "Conveyor", messages: []string{"named only"}},
+		{messages: []string{"no source"}},
+	}
+	for i, tt := range tests {
+		resourceLogs := logs.ResourceLogs().At(i)
+		attrs := resourceLogs.Resource().Attributes()
+
+		// Every resource carries the server attributes
+		serviceName, ok := attrs.Get("service.name")
+		require.True(t, ok, "resource %d", i)
+		assert.Equal(t, "opcua-server", serviceName.Str())
+
+		node, ok := attrs.Get("opcua.source.node")
+		assert.Equal(t, tt.node != "", ok, "resource %d", i)
+		if ok {
+			assert.Equal(t, tt.node, node.Str())
+		}
+		name, ok := attrs.Get("opcua.source.name")
+		assert.Equal(t, tt.name != "", ok, "resource %d", i)
+		if ok {
+			assert.Equal(t, tt.name, name.Str())
+		}
+
+		lrs := resourceLogs.ScopeLogs().At(0).LogRecords()
+		require.Equal(t, len(tt.messages), lrs.Len(), "resource %d", i)
+		for j, message := range tt.messages {
+			assert.Equal(t, message, lrs.At(j).Body().Str())
+		}
+	}
+}
+
+func TestGeneratorVendorRecords(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	records := testdata.NewSeededGenerator(34, base).VendorRecords(8)
+	require.Len(t, records, 8)
+	assert.Equal(t, records, testdata.NewSeededGenerator(34, base).VendorRecords(8))
+
+	shapes := map[string]bool{}
+	for _, record := range records {
+		for k, v := range record.Attributes {
+			if len(k) > 256 {
+				shapes["long key"] = true
+			}
+			switch v.(type) {
+			case []byte:
+				shapes["byte string"] = true
+			case []string, []float64, []uint32, []bool:
+				shapes["array"] = true
+			case map[string]interface{}:
+				shapes["structure"] = true
+			case time.Time:
+				shapes["timestamp"] = true
+			case nil:
+				shapes["nil"] = true
+			}
+		}
+	}
+	assert.Len(t, shapes, 6, "shapes found: %v", shapes)
+}
+
 func TestGenerateSampleLogRecord(t *testing.T) {
 	record := testdata.GenerateSampleLogRecord(1)
 
@@ -322,6 +528,46 @@ func TestGenerateSampleLogRecord(t *testing.T) {
 	assert.NotNil(t, record.Attributes)
 }
 
+func TestGeneratorDeterministic(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	first := testdata.NewSeededGenerator(42, base).Records(20)
+	second := testdata.NewSeededGenerator(42, base).Records(20)
+	assert.Equal(t, first, second)
+
+	other := testdata.NewSeededGenerator(43, base).Records(20)
+	assert.NotEqual(t, first, other)
+
+	assert.Equal(t, testdata.GenerateSampleLogRecord(7).TraceID, testdata.GenerateSampleLogRecord(7).TraceID)
+}
+
+func TestGeneratorDistributions(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	steady := testdata.NewSeededGenerator(1, base).SteadyInfo(100, time.Second)
+	require.Len(t, steady, 100)
+	for i, r := range steady {
+		assert.Equal(t, base.Add(time.Duration(i)*time.Second), r.Timestamp)
+		assert.LessOrEqual(t, r.Severity, uint16(200))
+	}
+
+	bursty := testdata.NewSeededGenerator(1, base).BurstyErrors(1000, time.Second, 0.05, 10)
+	require.Len(t, bursty, 1000)
+	errorCount, run, longest := 0, 0, 0
+	for _, r := range bursty {
+		if r.Severity > 200 {
+			errorCount++
+			run++
+			longest = max(longest, run)
+			continue
+		}
+		run = 0
+	}
+	assert.Positive(t, errorCount)
+	assert.Less(t, errorCount, 1000)
+	assert.GreaterOrEqual(t, longest, 10)
+}
+
 func TestGenerateLogRecordWithDetails(t *testing.T) {
 	timestamp := time.Now()
 	record := testdata.GenerateLogRecordWithDetails(timestamp, 500, "Custom message", "CustomSource")
@@ -331,3 +577,154 @@ func TestGenerateLogRecordWithDetails(t *testing.T) {
 	assert.Equal(t, "Custom message", record.Message)
 	assert.Equal(t, "CustomSource", record.SourceName)
 }
+
+func TestTransformMetrics(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	end := start.Add(30 * time.Second)
+	records := []testdata.OPCUALogRecord{
+		{Timestamp: start, Severity: 60, Message: "a", LogObjectID: "i=2042"},
+		{Timestamp: start, Severity: 70, Message: "b", LogObjectID: "i=2042"},
+		{Timestamp: start, Severity: 220, Message: "c", LogObjectID: "i=2042"},
+		{Timestamp: start, Severity: 60, Message: "d", LogObjectID: "ns=2;i=1000"},
+	}
+
+	metrics := transformer.TransformMetrics(records, start, end)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	rm := metrics.ResourceMetrics().At(0)
+	serviceName, ok := rm.Resource().Attributes().Get("service.name")
+	require.True(t, ok)
+	assert.Equal(t, "opcua-server", serviceName.Str())
+
+	require.Equal(t, 1, rm.ScopeMetrics().At(0).Metrics().Len())
+	metric := rm.ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "opcua.log.records", metric.Name())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, metric.Sum().AggregationTemporality())
+	assert.True(t, metric.Sum().IsMonotonic())
+
+	dps := metric.Sum().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	expected := []struct {
+		logObject string
+		band      string
+		count     int64
+	}{
+		{"i=2042", "Information", 2},
+		{"i=2042", "Error", 1},
+		{"ns=2;i=1000", "Information", 1},
+	}
+	for i, e := range expected {
+		dp := dps.At(i)
+		logObject, _ := dp.Attributes().Get("opcua.log_object")
+		band, _ := dp.Attributes().Get("opcua.severity_band")
+		assert.Equal(t, e.logObject, logObject.Str())
+		assert.Equal(t, e.band, band.Str())
+		assert.Equal(t, e.count, dp.IntValue())
+		assert.Equal(t, start, dp.StartTimestamp().AsTime())
+		assert.Equal(t, end, dp.Timestamp().AsTime())
+	}
+}
+
+func TestTransformMetricsEmpty(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+
+	metrics := transformer.TransformMetrics(nil, time.Now(), time.Now())
+
+	assert.Equal(t, 0, metrics.ResourceMetrics().Len())
+}
+
+func TestTransformTraces(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	traceID := "0102030405060708090a0b0c0d0e0f10"
+	records := []testdata.OPCUALogRecord{
+		{Timestamp: base.Add(2 * time.Second), Severity: 60, Message: "step 2", SourceName: "Pump", TraceID: traceID, SpanID: "0102030405060708"},
+		{Timestamp: base, Severity: 60, Message: "step 1", SourceName: "Pump", TraceID: traceID, SpanID: "0102030405060708"},
+		{Timestamp: base.Add(time.Second), Severity: 220, Message: "failure", SourceName: "Valve", TraceID: traceID, SpanID: "1112131415161718"},
+		{Timestamp: base, Severity: 60, Message: "no trace context"},
+	}
+
+	traces := transformer.TransformTraces(records)
+	require.Equal(t, 2, traces.SpanCount())
+
+	spans := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+
+	pump := spans.At(0)
+	assert.Equal(t, "Pump", pump.Name())
+	assert.Equal(t, base, pump.StartTimestamp().AsTime())
+	assert.Equal(t, base.Add(2*time.Second), pump.EndTimestamp().AsTime())
+	require.Equal(t, 2, pump.Events().Len())
+	assert.Equal(t, "step 2", pump.Events().At(0).Name())
+	assert.Equal(t, "step 1", pump.Events().At(1).Name())
+	assert.Equal(t, ptrace.StatusCodeUnset, pump.Status().Code())
+
+	valve := spans.At(1)
+	assert.Equal(t, "Valve", valve.Name())
+	assert.Equal(t, ptrace.StatusCodeError, valve.Status().Code())
+	band, ok := valve.Events().At(0).Attributes().Get("opcua.severity_band")
+	require.True(t, ok)
+	assert.Equal(t, "Error", band.Str())
+}
+
+func TestTransformTracesParentSpan(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	traceID := "0102030405060708090a0b0c0d0e0f10"
+	records := []testdata.OPCUALogRecord{
+		{Timestamp: base, Severity: 60, Message: "started", SourceName: "Pump", TraceID: traceID, SpanID: "0102030405060708", ParentSpanID: "1112131415161718"},
+		{Timestamp: base.Add(time.Second), Severity: 60, Message: "done", SourceName: "Pump", TraceID: traceID, SpanID: "0102030405060708", ParentSpanID: "2122232425262728"},
+		{Timestamp: base, Severity: 60, Message: "root", SourceName: "PLC", TraceID: traceID, SpanID: "1112131415161718"},
+	}
+	parent := pcommon.SpanID{0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17, 0x18}
+
+	tests := []struct {
+		name       string
+		parentSpan string
+		spanEvents bool
+		wantParent pcommon.SpanID
+		wantLinks  int
+		wantEvents int
+	}{
+		{name: "parent", parentSpan: parentSpanParent, spanEvents: true, wantParent: parent, wantEvents: 2},
+		{name: "link", parentSpan: parentSpanLink, spanEvents: true, wantLinks: 1, wantEvents: 2},
+		{name: "none", parentSpan: parentSpanNone, spanEvents: true, wantEvents: 2},
+		{name: "without span events", parentSpan: parentSpanParent, wantParent: parent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Traces = TracesConfig{ParentSpan: tt.parentSpan, SpanEvents: tt.spanEvents}
+			traces := newTransformerFromConfig(cfg).TransformTraces(records)
+			require.Equal(t, 2, traces.SpanCount())
+
+			spans := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+			pump := spans.At(0)
+			// The first ParentSpanId of the span's records wins
+			assert.Equal(t, tt.wantParent, pump.ParentSpanID())
+			require.Equal(t, tt.wantLinks, pump.Links().Len())
+			if tt.wantLinks > 0 {
+				assert.Equal(t, parent, pump.Links().At(0).SpanID())
+				assert.Equal(t, pump.TraceID(), pump.Links().At(0).TraceID())
+			}
+			assert.Equal(t, tt.wantEvents, pump.Events().Len())
+			assert.Equal(t, base.Add(time.Second), pump.EndTimestamp().AsTime())
+
+			// A root span has no parent
+			assert.True(t, spans.At(1).ParentSpanID().IsEmpty())
+			assert.Equal(t, 0, spans.At(1).Links().Len())
+		})
+	}
+}
+
+func TestTransformTracesWithoutTraceContext(t *testing.T) {
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+
+	traces := transformer.TransformTraces([]testdata.OPCUALogRecord{
+		{Timestamp: time.Now(), Severity: 60, Message: "plain"},
+		{Timestamp: time.Now(), Severity: 60, Message: "bad ids", TraceID: "zz", SpanID: "01"},
+	})
+
+	assert.Equal(t, 0, traces.ResourceSpans().Len())
+}
@@ -0,0 +1,249 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gopcua/opcua/ua"
+)
+
+// UADP NetworkMessage header flags, see OPC UA Part 14, 7.2.4.4.2
+const (
+	uadpVersion               = 1
+	uadpPublisherIDEnabled    = 1 << 4
+	uadpGroupHeaderEnabled    = 1 << 5
+	uadpPayloadHeaderEnabled  = 1 << 6
+	uadpExtendedFlags1Enabled = 1 << 7
+
+	uadpDataSetClassIDEnabled = 1 << 3
+	uadpSecurityEnabled       = 1 << 4
+	uadpTimestampEnabled      = 1 << 5
+	uadpPicoSecondsEnabled    = 1 << 6
+	uadpExtendedFlags2Enabled = 1 << 7
+
+	uadpChunkMessage          = 1 << 0
+	uadpPromotedFieldsEnabled = 1 << 1
+
+	uadpWriterGroupIDEnabled        = 1 << 0
+	uadpGroupVersionEnabled         = 1 << 1
+	uadpNetworkMessageNumberEnabled = 1 << 2
+	uadpSequenceNumberEnabled       = 1 << 3
+
+	uadpNetworkMessageTypeDataSet = 0
+)
+
+// UADP DataSetMessage header flags, see OPC UA Part 14, 7.2.4.5.4
+const (
+	dataSetMessageValid          = 1 << 0
+	dataSetSequenceNumberEnabled = 1 << 3
+	dataSetStatusEnabled         = 1 << 4
+	dataSetMajorVersionEnabled   = 1 << 5
+	dataSetMinorVersionEnabled   = 1 << 6
+	dataSetFlags2Enabled         = 1 << 7
+
+	dataSetTimestampEnabled   = 1 << 4
+	dataSetPicoSecondsEnabled = 1 << 5
+
+	dataSetFieldEncodingVariant   = 0
+	dataSetFieldEncodingRawData   = 1
+	dataSetFieldEncodingDataValue = 2
+
+	dataSetMessageTypeKeyFrame = 0
+	dataSetMessageTypeEvent    = 2
+)
+
+// decodeUADP decodes a UADP NetworkMessage. The fields of its DataSetMessages
+// are named by position from names. Secured and chunked messages are not
+// supported.
+func decodeUADP(b []byte, names []string) (*pubsubMessage, error) {
+	buf := ua.NewBuffer(b)
+	msg := &pubsubMessage{}
+
+	flags := buf.ReadByte()
+	if flags&0x0F != uadpVersion {
+		return nil, fmt.Errorf("unsupported UADP version %d", flags&0x0F)
+	}
+	var extended1, extended2 byte
+	if flags&uadpExtendedFlags1Enabled != 0 {
+		extended1 = buf.ReadByte()
+	}
+	if extended1&uadpExtendedFlags2Enabled != 0 {
+		extended2 = buf.ReadByte()
+	}
+	if extended1&uadpSecurityEnabled != 0 {
+		return nil, errors.New("secured UADP messages are not supported")
+	}
+	if extended2&uadpChunkMessage != 0 {
+		return nil, errors.New("chunked UADP messages are not supported")
+	}
+	if messageType := extended2 >> 2 & 0x07; messageType != uadpNetworkMessageTypeDataSet {
+		return nil, fmt.Errorf("UADP discovery messages are not supported, type %d", messageType)
+	}
+
+	if flags&uadpPublisherIDEnabled != 0 {
+		switch extended1 & 0x07 {
+		case 0:
+			msg.publisherID = strconv.FormatUint(uint64(buf.ReadByte()), 10)
+		case 1:
+			msg.publisherID = strconv.FormatUint(uint64(buf.ReadUint16()), 10)
+		case 2:
+			msg.publisherID = strconv.FormatUint(uint64(buf.ReadUint32()), 10)
+		case 3:
+			msg.publisherID = strconv.FormatUint(buf.ReadUint64(), 10)
+		case 4:
+			msg.publisherID = buf.ReadString()
+		default:
+			return nil, fmt.Errorf("invalid PublisherId type %d", extended1&0x07)
+		}
+	}
+	if extended1&uadpDataSetClassIDEnabled != 0 {
+		buf.ReadN(16)
+	}
+
+	if flags&uadpGroupHeaderEnabled != 0 {
+		groupFlags := buf.ReadByte()
+		if groupFlags&uadpWriterGroupIDEnabled != 0 {
+			buf.ReadUint16()
+		}
+		if groupFlags&uadpGroupVersionEnabled != 0 {
+			buf.ReadUint32()
+		}
+		if groupFlags&uadpNetworkMessageNumberEnabled != 0 {
+			buf.ReadUint16()
+		}
+		if groupFlags&uadpSequenceNumberEnabled != 0 {
+			buf.ReadUint16()
+		}
+	}
+
+	// Without a payload header the message holds one DataSetMessage of an
+	// unknown writer
+	writerIDs := []uint16{0}
+	if flags&uadpPayloadHeaderEnabled != 0 {
+		writerIDs = make([]uint16, buf.ReadByte())
+		for i := range writerIDs {
+			writerIDs[i] = buf.ReadUint16()
+		}
+	}
+
+	if extended1&uadpTimestampEnabled != 0 {
+		msg.timestamp = buf.ReadTime()
+	}
+	if extended1&uadpPicoSecondsEnabled != 0 {
+		buf.ReadUint16()
+	}
+	if extended2&uadpPromotedFieldsEnabled != 0 {
+		buf.ReadN(int(buf.ReadUint16()))
+	}
+
+	sizes := make([]int, len(writerIDs))
+	if len(writerIDs) > 1 {
+		for i := range sizes {
+			sizes[i] = int(buf.ReadUint16())
+		}
+	}
+	if err := buf.Error(); err != nil {
+		return nil, fmt.Errorf("invalid UADP header: %w", err)
+	}
+
+	payload := b[buf.Pos():]
+	for i, writerID := range writerIDs {
+		data := payload
+		if sizes[i] > 0 {
+			if sizes[i] > len(payload) {
+				return nil, fmt.Errorf("DataSetMessage of writer %d exceeds the message", writerID)
+			}
+			data, payload = payload[:sizes[i]], payload[sizes[i]:]
+		}
+		dataSet, err := decodeUADPDataSet(data, names)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DataSetMessage of writer %d: %w", writerID, err)
+		}
+		dataSet.writerID = writerID
+		msg.dataSets = append(msg.dataSets, dataSet)
+	}
+	return msg, nil
+}
+
+// decodeUADPDataSet decodes a UADP DataSetMessage. Only key frames and
+// events carry all fields; other messages are returned without fields.
+func decodeUADPDataSet(b []byte, names []string) (dataSetMessage, error) {
+	buf := ua.NewBuffer(b)
+	var dataSet dataSetMessage
+
+	flags1 := buf.ReadByte()
+	var flags2 byte
+	if flags1&dataSetFlags2Enabled != 0 {
+		flags2 = buf.ReadByte()
+	}
+	if flags1&dataSetSequenceNumberEnabled != 0 {
+		buf.ReadUint16()
+	}
+	if flags2&dataSetTimestampEnabled != 0 {
+		dataSet.timestamp = buf.ReadTime()
+	}
+	if flags2&dataSetPicoSecondsEnabled != 0 {
+		buf.ReadUint16()
+	}
+	if flags1&dataSetStatusEnabled != 0 {
+		buf.ReadUint16()
+	}
+	if flags1&dataSetMajorVersionEnabled != 0 {
+		buf.ReadUint32()
+	}
+	if flags1&dataSetMinorVersionEnabled != 0 {
+		buf.ReadUint32()
+	}
+	if err := buf.Error(); err != nil {
+		return dataSet, err
+	}
+
+	messageType := flags2 & 0x0F
+	if flags1&dataSetMessageValid == 0 || (messageType != dataSetMessageTypeKeyFrame && messageType != dataSetMessageTypeEvent) {
+		return dataSet, nil
+	}
+	encoding := flags1 >> 1 & 0x03
+	if messageType == dataSetMessageTypeEvent {
+		encoding = dataSetFieldEncodingVariant
+	}
+	if encoding == dataSetFieldEncodingRawData {
+		return dataSet, errors.New("RawData field encoding is not supported")
+	}
+
+	count := int(buf.ReadUint16())
+	dataSet.fields = make(map[string]interface{}, count)
+	for i := range count {
+		var value interface{}
+		switch encoding {
+		case dataSetFieldEncodingVariant:
+			v := new(ua.Variant)
+			buf.ReadStruct(v)
+			value = v.Value()
+		case dataSetFieldEncodingDataValue:
+			dv := new(ua.DataValue)
+			buf.ReadStruct(dv)
+			if dv.Value != nil {
+				value = dv.Value.Value()
+			}
+		default:
+			return dataSet, fmt.Errorf("invalid field encoding %d", encoding)
+		}
+		if err := buf.Error(); err != nil {
+			return dataSet, fmt.Errorf("invalid field %d: %w", i, err)
+		}
+		dataSet.fields[dataSetFieldName(names, i)] = value
+	}
+	return dataSet, nil
+}
+
+// dataSetFieldName returns the name of field i of a DataSet
+func dataSetFieldName(names []string, i int) string {
+	if i < len(names) {
+		return names[i]
+	}
+	return "Field" + strconv.Itoa(i)
+}
@@ -0,0 +1,281 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gopcua/opcua"
+	"github.com/gopcua/opcua/ua"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+const (
+	// metricTypeGauge emits the sampled value of a variable
+	metricTypeGauge = "gauge"
+
+	// metricTypeSum emits a variable counting up as a cumulative monotonic sum
+	metricTypeSum = "sum"
+
+	// valueTypeInt and valueTypeDouble convert the value of a variable
+	valueTypeInt    = "int"
+	valueTypeDouble = "double"
+)
+
+// errNoVariableReader is returned when metrics are configured for a client
+// that cannot read variables
+var errNoVariableReader = errors.New("OPC UA client does not support reading variables")
+
+// variableReader is implemented by clients that can read variable nodes
+type variableReader interface {
+	// readVariables reads the values of nodes, each a NodeID or browse path.
+	// The result holds one DataValue per node, with a bad status for nodes
+	// that cannot be resolved or read.
+	readVariables(ctx context.Context, nodes []string) ([]*ua.DataValue, error)
+}
+
+// resolveVariable returns the NodeID of a variable given as NodeID or as
+// browse path, translating browse paths once per session
+func (c *opcuaClient) resolveVariable(ctx context.Context, session *opcua.Client, node string) (*ua.NodeID, error) {
+	if isNodeID(node) {
+		return resolveNodeID(node, session.Namespaces())
+	}
+
+	c.variableMu.Lock()
+	nodeID, ok := c.variableIDs[node]
+	c.variableMu.Unlock()
+	if ok {
+		return nodeID, nil
+	}
+
+	nodeID, err := translateBrowsePath(ctx, session, node, c.recordBrowseDuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate browse path %s: %w", node, err)
+	}
+
+	c.variableMu.Lock()
+	if c.variableIDs == nil {
+		c.variableIDs = make(map[string]*ua.NodeID)
+	}
+	c.variableIDs[node] = nodeID
+	c.variableMu.Unlock()
+	return nodeID, nil
+}
+
+// readVariables reads the values of the variable nodes in a single Read call
+func (c *opcuaClient) readVariables(ctx context.Context, nodes []string) ([]*ua.DataValue, error) {
+	session, err := c.session()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]*ua.DataValue, len(nodes))
+	var toRead []*ua.ReadValueID
+	var indexes []int
+	for i, node := range nodes {
+		nodeID, err := c.resolveVariable(ctx, session, node)
+		if err != nil {
+			c.warnings.Warn(c.logger, "resolve_variable/"+node, "Failed to resolve variable",
+				zap.String("node", node),
+				zap.Error(err))
+			values[i] = &ua.DataValue{EncodingMask: ua.DataValueStatusCode, Status: ua.StatusBadNodeIDUnknown}
+			continue
+		}
+		c.warnings.Clear(c.logger, "resolve_variable/"+node)
+		toRead = append(toRead, &ua.ReadValueID{NodeID: nodeID, AttributeID: ua.AttributeIDValue})
+		indexes = append(indexes, i)
+	}
+	if len(toRead) == 0 {
+		return values, nil
+	}
+
+	resp, err := session.Read(ctx, &ua.ReadRequest{
+		TimestampsToReturn: ua.TimestampsToReturnBoth,
+		NodesToRead:        toRead,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variables: %w", err)
+	}
+	if len(resp.Results) != len(toRead) {
+		return nil, fmt.Errorf("failed to read variables: %d results for %d nodes", len(resp.Results), len(toRead))
+	}
+	for j, result := range resp.Results {
+		values[indexes[j]] = result
+	}
+	return values, nil
+}
+
+// numberValue converts the value of a variable to an int or a double.
+// Booleans are 0 or 1; other non-numeric values are not converted.
+func numberValue(value interface{}, valueType string) (intValue int64, doubleValue float64, isInt bool, ok bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			intValue = 1
+		}
+		isInt = true
+	case int8:
+		intValue, isInt = int64(v), true
+	case int16:
+		intValue, isInt = int64(v), true
+	case int32:
+		intValue, isInt = int64(v), true
+	case int64:
+		intValue, isInt = v, true
+	case uint8:
+		intValue, isInt = int64(v), true
+	case uint16:
+		intValue, isInt = int64(v), true
+	case uint32:
+		intValue, isInt = int64(v), true
+	case uint64:
+		intValue, isInt = int64(min(v, math.MaxInt64)), true //nolint:gosec
+	case float32:
+		doubleValue = float64(v)
+	case float64:
+		doubleValue = v
+	default:
+		return 0, 0, false, false
+	}
+
+	switch {
+	case valueType == valueTypeDouble && isInt:
+		return 0, float64(intValue), false, true
+	case valueType == valueTypeInt && !isInt:
+		if math.IsNaN(doubleValue) {
+			return 0, 0, false, false
+		}
+		return int64(doubleValue), 0, true, true
+	}
+	return intValue, doubleValue, isInt, true
+}
+
+// transformVariables converts the values read for the configured metrics to
+// one data point each. Values with a bad or uncertain status and values that
+// are not numbers are skipped. Sums start at start; data points carry the
+// source timestamp of the value, or now if the server sent none.
+func (t *recordTransformer) transformVariables(cfgs []VariableMetricConfig, values []*ua.DataValue, start, now time.Time) pmetric.Metrics {
+	metrics := pmetric.NewMetrics()
+	var scopeMetrics pmetric.ScopeMetrics
+
+	for i, cfg := range cfgs {
+		if i >= len(values) {
+			break
+		}
+		dv := values[i]
+		if dv == nil || dv.Value == nil || uint32(dv.Status)&0xC0000000 != 0 {
+			continue
+		}
+		intValue, doubleValue, isInt, ok := numberValue(dv.Value.Value(), cfg.ValueType)
+		if !ok {
+			continue
+		}
+
+		if metrics.ResourceMetrics().Len() == 0 {
+			resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+			t.buildResource().MoveTo(resourceMetrics.Resource())
+			scopeMetrics = resourceMetrics.ScopeMetrics().AppendEmpty()
+			scopeMetrics.Scope().SetName("github.com/bruegth/opentelemetry-collector-opcua-receiver")
+			scopeMetrics.Scope().SetVersion("0.1.0")
+		}
+
+		metric := scopeMetrics.Metrics().AppendEmpty()
+		metric.SetName(cfg.Name)
+		metric.SetDescription(cfg.Description)
+		metric.SetUnit(cfg.Unit)
+
+		var dp pmetric.NumberDataPoint
+		if cfg.Type == metricTypeSum {
+			sum := metric.SetEmptySum()
+			sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+			sum.SetIsMonotonic(true)
+			dp = sum.DataPoints().AppendEmpty()
+			dp.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+		} else {
+			dp = metric.SetEmptyGauge().DataPoints().AppendEmpty()
+		}
+
+		timestamp := now
+		switch {
+		case !dv.SourceTimestamp.IsZero():
+			timestamp = dv.SourceTimestamp
+		case !dv.ServerTimestamp.IsZero():
+			timestamp = dv.ServerTimestamp
+		}
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		if isInt {
+			dp.SetIntValue(intValue)
+		} else {
+			dp.SetDoubleValue(doubleValue)
+		}
+		dp.Attributes().PutStr("opcua.node", cfg.Node)
+	}
+
+	return metrics
+}
+
+// scrapeVariables reads the configured variables and converts them to metrics
+func (s *scraper) scrapeVariables(ctx context.Context) (pmetric.Metrics, error) {
+	reader, ok := s.client.(variableReader)
+	if !ok {
+		return pmetric.NewMetrics(), errNoVariableReader
+	}
+
+	nodes := make([]string, len(s.config.Metrics))
+	for i, metric := range s.config.Metrics {
+		nodes[i] = metric.Node
+	}
+	values, err := reader.readVariables(ctx, nodes)
+	if err != nil {
+		return pmetric.NewMetrics(), err
+	}
+
+	now := s.now()
+	if s.variablesStart.IsZero() {
+		s.variablesStart = now
+	}
+	return s.transformer.transformVariables(s.config.Metrics, values, s.variablesStart, now), nil
+}
+
+// consumeVariableMetrics reads the configured variables and sends them to
+// the metrics consumer
+func (r *opcuaReceiver) consumeVariableMetrics(ctx context.Context) {
+	if r.nextMetrics == nil || len(r.config.Metrics) == 0 {
+		return
+	}
+
+	metrics, err := r.scraper.scrapeVariables(ctx)
+	if err != nil {
+		r.scraper.errorLog.Error(r.settings.Logger, "read_variables", "Failed to read variables for metrics", zap.Error(err))
+		return
+	}
+	r.scraper.errorLog.Clear(r.settings.Logger, "read_variables")
+	if metrics.DataPointCount() == 0 {
+		return
+	}
+	if err := r.nextMetrics.ConsumeMetrics(ctx, metrics); err != nil {
+		r.settings.Logger.Error("Failed to consume metrics", zap.Error(err))
+	}
+}
+
+// pollVariables reads the variables every collection_interval until ctx is
+// done. It runs beside the event subscription, which does not poll.
+func (r *opcuaReceiver) pollVariables(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+	for {
+		r.consumeVariableMetrics(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
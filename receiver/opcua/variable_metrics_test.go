@@ -0,0 +1,213 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/ua"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/metadata"
+)
+
+func TestVariableMetricConfigValidate(t *testing.T) {
+	valid := VariableMetricConfig{Node: "ns=2;s=Temperature", Name: "machine.temperature", Unit: "Cel"}
+
+	tests := []struct {
+		name    string
+		metrics []VariableMetricConfig
+		wantErr string
+	}{
+		{
+			name:    "gauge",
+			metrics: []VariableMetricConfig{valid},
+		},
+		{
+			name: "sum with value type",
+			metrics: []VariableMetricConfig{
+				{Node: "Objects/2:Machine/2:PartsProduced", Name: "machine.parts", Type: "sum", ValueType: "int"},
+			},
+		},
+		{
+			name:    "missing node",
+			metrics: []VariableMetricConfig{{Name: "machine.temperature"}},
+			wantErr: "invalid metrics[0]: node must be specified",
+		},
+		{
+			name:    "missing name",
+			metrics: []VariableMetricConfig{{Node: "ns=2;s=Temperature"}},
+			wantErr: "invalid metrics[0]: name must be specified",
+		},
+		{
+			name:    "unknown type",
+			metrics: []VariableMetricConfig{{Node: "ns=2;s=Temperature", Name: "t", Type: "histogram"}},
+			wantErr: "invalid type: histogram",
+		},
+		{
+			name:    "unknown value type",
+			metrics: []VariableMetricConfig{{Node: "ns=2;s=Temperature", Name: "t", ValueType: "string"}},
+			wantErr: "invalid value_type: string",
+		},
+		{
+			name:    "duplicate name",
+			metrics: []VariableMetricConfig{valid, {Node: "ns=2;s=Other", Name: valid.Name}},
+			wantErr: "invalid metrics[1]: duplicate name machine.temperature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Metrics = tt.metrics
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestNumberValue(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       interface{}
+		valueType   string
+		expectedInt int64
+		expectedDbl float64
+		isInt       bool
+		ok          bool
+	}{
+		{name: "bool", value: true, expectedInt: 1, isInt: true, ok: true},
+		{name: "int16", value: int16(-5), expectedInt: -5, isInt: true, ok: true},
+		{name: "uint32", value: uint32(42), expectedInt: 42, isInt: true, ok: true},
+		{name: "uint64 beyond int64", value: uint64(math.MaxUint64), expectedInt: math.MaxInt64, isInt: true, ok: true},
+		{name: "float32", value: float32(1.5), expectedDbl: 1.5, ok: true},
+		{name: "double", value: 21.5, expectedDbl: 21.5, ok: true},
+		{name: "int as double", value: uint16(7), valueType: valueTypeDouble, expectedDbl: 7, ok: true},
+		{name: "double as int", value: 21.9, valueType: valueTypeInt, expectedInt: 21, isInt: true, ok: true},
+		{name: "NaN as int", value: math.NaN(), valueType: valueTypeInt},
+		{name: "string", value: "21.5"},
+		{name: "null", value: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intValue, doubleValue, isInt, ok := numberValue(tt.value, tt.valueType)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.isInt, isInt)
+			assert.Equal(t, tt.expectedInt, intValue)
+			assert.InDelta(t, tt.expectedDbl, doubleValue, 1e-9)
+		})
+	}
+}
+
+func TestTransformVariables(t *testing.T) {
+	start := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	now := start.Add(time.Minute)
+	sourceTime := now.Add(-time.Second)
+
+	cfgs := []VariableMetricConfig{
+		{Node: "ns=2;s=Temperature", Name: "machine.temperature", Description: "Spindle temperature", Unit: "Cel"},
+		{Node: "ns=2;s=Parts", Name: "machine.parts", Unit: "{parts}", Type: metricTypeSum},
+		{Node: "ns=2;s=Broken", Name: "machine.broken"},
+		{Node: "ns=2;s=Name", Name: "machine.name"},
+		{Node: "ns=2;s=Missing", Name: "machine.missing"},
+	}
+	values := []*ua.DataValue{
+		{Value: ua.MustVariant(21.5), SourceTimestamp: sourceTime},
+		{Value: ua.MustVariant(uint32(1200)), ServerTimestamp: now.Add(-2 * time.Second)},
+		{Value: ua.MustVariant(1.0), Status: ua.StatusBadSensorFailure},
+		{Value: ua.MustVariant("Mill 1")},
+	}
+
+	transformer := newRecordTransformer("opc.tcp://test:4840", "opcua-server", "")
+	metrics := transformer.transformVariables(cfgs, values, start, now)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	ms := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+
+	gauge := ms.At(0)
+	assert.Equal(t, "machine.temperature", gauge.Name())
+	assert.Equal(t, "Spindle temperature", gauge.Description())
+	assert.Equal(t, "Cel", gauge.Unit())
+	require.Equal(t, pmetric.MetricTypeGauge, gauge.Type())
+	dp := gauge.Gauge().DataPoints().At(0)
+	assert.InDelta(t, 21.5, dp.DoubleValue(), 1e-9)
+	assert.Equal(t, sourceTime, dp.Timestamp().AsTime())
+	node, _ := dp.Attributes().Get("opcua.node")
+	assert.Equal(t, "ns=2;s=Temperature", node.Str())
+
+	sum := ms.At(1)
+	assert.Equal(t, "machine.parts", sum.Name())
+	require.Equal(t, pmetric.MetricTypeSum, sum.Type())
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, sum.Sum().AggregationTemporality())
+	assert.True(t, sum.Sum().IsMonotonic())
+	dp = sum.Sum().DataPoints().At(0)
+	assert.Equal(t, int64(1200), dp.IntValue())
+	assert.Equal(t, start, dp.StartTimestamp().AsTime())
+	assert.Equal(t, now.Add(-2*time.Second), dp.Timestamp().AsTime())
+
+	// Nothing readable produces no resource
+	assert.Equal(t, 0, transformer.transformVariables(cfgs[2:], values[2:], start, now).ResourceMetrics().Len())
+}
+
+func TestReceiverWireVariableMetrics(t *testing.T) {
+	ws := startWireServer(t)
+	ctx := context.Background()
+	sourceTime := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	ws.SetVariable(ua.NewStringNodeID(2, "Temperature"), "", &ua.DataValue{
+		EncodingMask:    ua.DataValueValue | ua.DataValueSourceTimestamp,
+		Value:           ua.MustVariant(21.5),
+		SourceTimestamp: sourceTime,
+	})
+	ws.SetVariable(ua.NewNumericNodeID(2, 5001), "2:Machine/2:PartsProduced", &ua.DataValue{
+		EncodingMask: ua.DataValueValue,
+		Value:        ua.MustVariant(uint32(1200)),
+	})
+
+	cfg := ws.newWireConfig()
+	cfg.Metrics = []VariableMetricConfig{
+		{Node: "ns=2;s=Temperature", Name: "machine.temperature", Unit: "Cel"},
+		{Node: "Objects/2:Machine/2:PartsProduced", Name: "machine.parts", Unit: "{parts}", Type: metricTypeSum},
+		{Node: "Objects/2:Machine/2:Unknown", Name: "machine.unknown"},
+		{Node: "ns=2;s=Missing", Name: "machine.missing"},
+	}
+	r, err := newOPCUAReceiver(cfg, receivertest.NewNopSettings(metadata.Type))
+	require.NoError(t, err)
+	sink := new(consumertest.MetricsSink)
+	r.nextMetrics = sink
+	require.NoError(t, r.scraper.start(ctx, componenttest.NewNopHost()))
+	defer func() {
+		assert.NoError(t, r.scraper.shutdown(ctx))
+	}()
+
+	r.consumeVariableMetrics(ctx)
+	r.consumeVariableMetrics(ctx)
+
+	require.Len(t, sink.AllMetrics(), 2)
+	ms := sink.AllMetrics()[1].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, ms.Len())
+	assert.Equal(t, "machine.temperature", ms.At(0).Name())
+	assert.InDelta(t, 21.5, ms.At(0).Gauge().DataPoints().At(0).DoubleValue(), 1e-9)
+	assert.Equal(t, sourceTime, ms.At(0).Gauge().DataPoints().At(0).Timestamp().AsTime())
+	assert.Equal(t, "machine.parts", ms.At(1).Name())
+	assert.Equal(t, int64(1200), ms.At(1).Sum().DataPoints().At(0).IntValue())
+
+	// The sums of both reads share their start
+	first := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(1)
+	assert.Equal(t, first.Sum().DataPoints().At(0).StartTimestamp(), ms.At(1).Sum().DataPoints().At(0).StartTimestamp())
+}
@@ -0,0 +1,699 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package opcua
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gopcua/opcua/id"
+	"github.com/gopcua/opcua/server"
+	"github.com/gopcua/opcua/ua"
+	"github.com/gopcua/opcua/uapolicy"
+	"github.com/gopcua/opcua/uasc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/internal/client"
+	"github.com/bruegth/opentelemetry-collector-opcua-receiver/receiver/opcua/testdata"
+)
+
+// wireServer serves the OPC UA binary protocol on a local TCP port using the
+// gopcua server. Read, Browse and Call are answered for every LogObject hosted
+// by the embedded testdata.MockServer so the real opcuaClient can be exercised end to end without Docker; records are
+// stored in and filtered by a testdata.MockServer.
+type wireServer struct {
+	*testdata.MockServer
+
+	srv         *server.Server
+	endpoint    string
+	logObjectID *ua.NodeID
+	methodID    *ua.NodeID
+
+	// Server application certificate, only set with withSecureEndpoints
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+
+	// Proxy in front of the server, only set with withChaosProxy
+	proxy *chaosProxy
+
+	// DateTime variable accepting status write-back, and the values written to it
+	statusNodeID *ua.NodeID
+	statusMu     sync.Mutex
+	statusWrites []time.Time
+
+	// Variable holding the severity dictionary, unset until SetSeverityDictionary
+	dictionaryNodeID *ua.NodeID
+	dictionary       *ua.Variant
+
+	// Process variables added with SetVariable, by NodeID
+	variables map[string]*ua.DataValue
+
+	// Nodes by browse path, added with AddBrowsePath or SetVariable
+	browsePaths map[string]*ua.NodeID
+}
+
+// wireApplicationURI is the application URI of the wire server
+const wireApplicationURI = "urn:opcua-receiver:wire-test"
+
+// wireServerOption configures the wire server before it is started
+type wireServerOption func(t *testing.T, ws *wireServer) []server.Option
+
+// withSecureEndpoints adds Basic256Sha256 Sign and SignAndEncrypt endpoints
+// next to the None endpoint, using a freshly generated server certificate
+func withSecureEndpoints() wireServerOption {
+	return func(t *testing.T, ws *wireServer) []server.Option {
+		ws.cert, ws.key = newWireServerCertificate(t)
+		return []server.Option{
+			server.Certificate(ws.cert.Raw),
+			server.PrivateKey(ws.key),
+			server.EnableSecurity("Basic256Sha256", ua.MessageSecurityModeSign),
+			server.EnableSecurity("Basic256Sha256", ua.MessageSecurityModeSignAndEncrypt),
+		}
+	}
+}
+
+// withUserAuthentication offers UserName and X.509 certificate user tokens
+// next to anonymous ones. gopcua offers them on endpoints with a security
+// policy only, so it is used together with withSecureEndpoints. Tokens are
+// validated by MockServer.Authenticate.
+func withUserAuthentication() wireServerOption {
+	return func(*testing.T, *wireServer) []server.Option {
+		return []server.Option{
+			server.EnableAuthMode(ua.UserTokenTypeUserName),
+			server.EnableAuthMode(ua.UserTokenTypeCertificate),
+		}
+	}
+}
+
+// newWireServerCertificate creates a self-signed OPC UA application instance
+// certificate for the wire server
+func newWireServerCertificate(t *testing.T) (*x509.Certificate, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	appURI, err := url.Parse(wireApplicationURI)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "wire-test", Organization: []string{"opcua-receiver"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment |
+			x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		URIs:        []*url.URL{appURI},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:    []string{"localhost"},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// startWireServer starts a wire-protocol server on a free local port. The
+// default LogObject is ns=1;i=1000 with its GetRecords method at ns=1;i=1001;
+// further LogObjects can be added with AddLogObject. Without options only a
+// None endpoint is offered.
+func startWireServer(t *testing.T, options ...wireServerOption) *wireServer {
+	t.Helper()
+
+	port := freePort(t)
+	ws := &wireServer{
+		MockServer:       testdata.NewMockServer("", zap.NewNop()),
+		endpoint:         fmt.Sprintf("opc.tcp://127.0.0.1:%d", port),
+		logObjectID:      ua.NewNumericNodeID(1, 1000),
+		methodID:         ua.NewNumericNodeID(1, 1001),
+		statusNodeID:     ua.NewNumericNodeID(1, 2000),
+		dictionaryNodeID: ua.NewNumericNodeID(1, 3000),
+	}
+	ws.SetDefaultLogObject(ws.logObjectID, ws.methodID)
+
+	opts := []server.Option{
+		server.EndPoint("127.0.0.1", port),
+		server.EnableSecurity("None", ua.MessageSecurityModeNone),
+		server.EnableAuthMode(ua.UserTokenTypeAnonymous),
+	}
+	for _, option := range options {
+		opts = append(opts, option(t, ws)...)
+	}
+
+	ws.srv = server.New(opts...)
+	ws.srv.RegisterHandler(id.GetEndpointsRequest_Encoding_DefaultBinary, ws.handleGetEndpoints)
+	ws.srv.RegisterHandler(id.ActivateSessionRequest_Encoding_DefaultBinary, ws.handleActivateSession)
+	ws.srv.RegisterHandler(id.ReadRequest_Encoding_DefaultBinary, ws.handleRead)
+	ws.srv.RegisterHandler(id.BrowseRequest_Encoding_DefaultBinary, ws.handleBrowse)
+	ws.srv.RegisterHandler(id.CallRequest_Encoding_DefaultBinary, ws.handleCall)
+	ws.srv.RegisterHandler(id.WriteRequest_Encoding_DefaultBinary, ws.handleWrite)
+	ws.srv.RegisterHandler(id.TranslateBrowsePathsToNodeIDsRequest_Encoding_DefaultBinary, ws.handleTranslateBrowsePaths)
+
+	require.NoError(t, ws.srv.Start(context.Background()))
+	t.Cleanup(func() {
+		_ = ws.srv.Close()
+	})
+
+	return ws
+}
+
+// freePort returns a TCP port that is currently free on the loopback interface
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// newWireConfig returns a receiver config pointing at the wire server's LogObject
+func (ws *wireServer) newWireConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = ws.endpoint
+	cfg.LogObjectPaths = []string{ws.logObjectID.String()}
+	cfg.Filter.MinSeverity = "Debug"
+	cfg.ConnectionTimeout = 5 * time.Second
+	cfg.RequestTimeout = 5 * time.Second
+	return cfg
+}
+
+// responseHeader builds a successful response header for the given request
+func responseHeader(req *ua.RequestHeader) *ua.ResponseHeader {
+	return &ua.ResponseHeader{
+		Timestamp:          time.Now(),
+		RequestHandle:      req.RequestHandle,
+		ServiceResult:      ua.StatusOK,
+		ServiceDiagnostics: &ua.DiagnosticInfo{},
+		StringTable:        []string{},
+		AdditionalHeader:   ua.NewExtensionObject(nil),
+	}
+}
+
+// serviceFault builds a response rejecting the whole request with the given status
+func serviceFault(req *ua.RequestHeader, status ua.StatusCode) *ua.ServiceFault {
+	header := responseHeader(req)
+	header.ServiceResult = status
+	return &ua.ServiceFault{ResponseHeader: header}
+}
+
+// malformedBody encodes as a LogRecord ExtensionObject body that cannot be decoded
+type malformedBody struct{}
+
+func (malformedBody) Encode() ([]byte, error) {
+	return []byte{0xde, 0xad}, nil
+}
+
+// corruptFirstRecord replaces the body of the first returned record with garbage
+func corruptFirstRecord(result *ua.CallMethodResult) {
+	if result.StatusCode != ua.StatusOK || len(result.OutputArguments) == 0 {
+		return
+	}
+	objects, ok := result.OutputArguments[0].Value().([]*ua.ExtensionObject)
+	if !ok || len(objects) == 0 {
+		return
+	}
+	objects[0] = &ua.ExtensionObject{
+		EncodingMask: ua.ExtensionObjectBinary,
+		TypeID:       &ua.ExpandedNodeID{NodeID: client.LogRecordExtObjTypeID},
+		Value:        malformedBody{},
+	}
+	result.OutputArguments[0] = ua.MustVariant(objects)
+}
+
+// findLogObject returns the hosted LogObject with the given NodeId
+func (ws *wireServer) findLogObject(nodeID *ua.NodeID) (testdata.LogObject, bool) {
+	for _, obj := range ws.LogObjects() {
+		if obj.ObjectID.Equal(nodeID) {
+			return obj, true
+		}
+	}
+	return testdata.LogObject{}, false
+}
+
+// wireSessionNonceLength is the length of the server nonces issued by the wire server
+const wireSessionNonceLength = 32
+
+// handleActivateSession replaces the ActivateSession service of the gopcua
+// server to validate the user identity token with MockServer.Authenticate.
+// The client's session signature is not verified.
+// handleGetEndpoints returns all endpoints whatever URL the client used, as
+// most servers do. gopcua's default handler only returns those matching the
+// URL, which is the loopback URL of the reverse connector in reverse connect.
+func (ws *wireServer) handleGetEndpoints(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.GetEndpointsRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+	return &ua.GetEndpointsResponse{
+		ResponseHeader: responseHeader(req.RequestHeader),
+		Endpoints:      ws.srv.Endpoints(),
+	}, nil
+}
+
+func (ws *wireServer) handleActivateSession(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.ActivateSessionRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+	if ws.srv.Session(req.RequestHeader) == nil {
+		return serviceFault(req.RequestHeader, ua.StatusBadSessionIDInvalid), nil
+	}
+
+	identity, err := ws.decodeIdentity(req.UserIdentityToken)
+	if err != nil {
+		return serviceFault(req.RequestHeader, ua.StatusBadIdentityTokenInvalid), nil
+	}
+	if status := ws.Authenticate(identity); status != ua.StatusOK {
+		return serviceFault(req.RequestHeader, status), nil
+	}
+
+	nonce := make([]byte, wireSessionNonceLength)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &ua.ActivateSessionResponse{
+		ResponseHeader:  responseHeader(req.RequestHeader),
+		ServerNonce:     nonce,
+		Results:         []ua.StatusCode{},
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+// decodeIdentity extracts the identity from a user identity token
+func (ws *wireServer) decodeIdentity(token *ua.ExtensionObject) (testdata.Identity, error) {
+	if token == nil {
+		return testdata.Identity{}, nil
+	}
+	switch tok := token.Value.(type) {
+	case nil, *ua.AnonymousIdentityToken:
+		return testdata.Identity{}, nil
+	case *ua.UserNameIdentityToken:
+		password, err := ws.decryptPassword(tok)
+		if err != nil {
+			return testdata.Identity{}, err
+		}
+		return testdata.Identity{Username: tok.UserName, Password: password}, nil
+	case *ua.X509IdentityToken:
+		return testdata.Identity{Certificate: tok.CertificateData}, nil
+	default:
+		return testdata.Identity{}, fmt.Errorf("unsupported identity token %T", tok)
+	}
+}
+
+// decryptPassword decrypts the password of a UserNameIdentityToken. Encrypted
+// passwords hold the length of password and server nonce, the password and
+// the nonce; they are encrypted with the server certificate's key.
+func (ws *wireServer) decryptPassword(tok *ua.UserNameIdentityToken) (string, error) {
+	if tok.EncryptionAlgorithm == "" {
+		return string(tok.Password), nil
+	}
+	if ws.key == nil {
+		return "", fmt.Errorf("encrypted password without server key")
+	}
+
+	// withSecureEndpoints only enables Basic256Sha256
+	enc, err := uapolicy.Asymmetric(ua.SecurityPolicyURIBasic256Sha256, ws.key, nil)
+	if err != nil {
+		return "", err
+	}
+	secret, err := enc.Decrypt(tok.Password)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt password: %w", err)
+	}
+	if len(secret) < 4 {
+		return "", fmt.Errorf("encrypted password too short")
+	}
+	n := int(binary.LittleEndian.Uint32(secret))
+	if n < wireSessionNonceLength || 4+n > len(secret) {
+		return "", fmt.Errorf("invalid encrypted password length %d", n)
+	}
+	return string(secret[4 : 4+n-wireSessionNonceLength]), nil
+}
+
+// handleRead answers the NamespaceArray read performed on connect and the
+// NodeClass read used to verify each LogObject
+func (ws *wireServer) handleRead(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.ReadRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+
+	results := make([]*ua.DataValue, len(req.NodesToRead))
+	for i, n := range req.NodesToRead {
+		switch {
+		case n.NodeID.Namespace() == 0 && n.NodeID.IntID() == id.Server_NamespaceArray:
+			results[i] = &ua.DataValue{
+				EncodingMask: ua.DataValueValue,
+				Value:        ua.MustVariant([]string{"http://opcfoundation.org/UA/", wireApplicationURI}),
+			}
+		case n.AttributeID == ua.AttributeIDValue && n.NodeID.Equal(ws.dictionaryNodeID) && ws.severityDictionary() != nil:
+			results[i] = &ua.DataValue{
+				EncodingMask: ua.DataValueValue,
+				Value:        ws.severityDictionary(),
+			}
+		case n.AttributeID == ua.AttributeIDValue && ws.variable(n.NodeID) != nil:
+			results[i] = ws.variable(n.NodeID)
+		case n.AttributeID == ua.AttributeIDNodeClass && ws.isLogObject(n.NodeID):
+			results[i] = &ua.DataValue{
+				EncodingMask: ua.DataValueValue,
+				Value:        ua.MustVariant(int32(ua.NodeClassObject)),
+			}
+		default:
+			results[i] = &ua.DataValue{
+				EncodingMask: ua.DataValueStatusCode,
+				Status:       ua.StatusBadNodeIDUnknown,
+			}
+		}
+	}
+
+	return &ua.ReadResponse{
+		ResponseHeader:  responseHeader(req.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+// handleWrite accepts DateTime values written to the status node and
+// rejects writes to any other node
+func (ws *wireServer) handleWrite(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.WriteRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+
+	results := make([]ua.StatusCode, len(req.NodesToWrite))
+	for i, n := range req.NodesToWrite {
+		if !n.NodeID.Equal(ws.statusNodeID) || n.AttributeID != ua.AttributeIDValue {
+			results[i] = ua.StatusBadNodeIDUnknown
+			continue
+		}
+		if n.Value == nil || n.Value.Value == nil {
+			results[i] = ua.StatusBadTypeMismatch
+			continue
+		}
+		written, ok := n.Value.Value.Value().(time.Time)
+		if !ok {
+			results[i] = ua.StatusBadTypeMismatch
+			continue
+		}
+		ws.statusMu.Lock()
+		ws.statusWrites = append(ws.statusWrites, written)
+		ws.statusMu.Unlock()
+		results[i] = ua.StatusOK
+	}
+
+	return &ua.WriteResponse{
+		ResponseHeader:  responseHeader(req.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+// SetSeverityDictionary sets the value of the severity dictionary node
+func (ws *wireServer) SetSeverityDictionary(value *ua.Variant) {
+	ws.statusMu.Lock()
+	defer ws.statusMu.Unlock()
+	ws.dictionary = value
+}
+
+func (ws *wireServer) severityDictionary() *ua.Variant {
+	ws.statusMu.Lock()
+	defer ws.statusMu.Unlock()
+	return ws.dictionary
+}
+
+// SetVariable sets the value of a process variable. browsePath, if not
+// empty, is its path below the Objects folder in "ns:name/ns:name" form.
+func (ws *wireServer) SetVariable(nodeID *ua.NodeID, browsePath string, value *ua.DataValue) {
+	ws.statusMu.Lock()
+	defer ws.statusMu.Unlock()
+	if ws.variables == nil {
+		ws.variables = make(map[string]*ua.DataValue)
+	}
+	ws.variables[nodeID.String()] = value
+	if browsePath != "" {
+		ws.addBrowsePathLocked(browsePath, nodeID)
+	}
+}
+
+// AddBrowsePath makes TranslateBrowsePathsToNodeIDs resolve browsePath, in
+// "ns:name/ns:name" form below the Objects folder, to nodeID
+func (ws *wireServer) AddBrowsePath(browsePath string, nodeID *ua.NodeID) {
+	ws.statusMu.Lock()
+	defer ws.statusMu.Unlock()
+	ws.addBrowsePathLocked(browsePath, nodeID)
+}
+
+func (ws *wireServer) addBrowsePathLocked(browsePath string, nodeID *ua.NodeID) {
+	if ws.browsePaths == nil {
+		ws.browsePaths = make(map[string]*ua.NodeID)
+	}
+	ws.browsePaths[browsePath] = nodeID
+}
+
+func (ws *wireServer) variable(nodeID *ua.NodeID) *ua.DataValue {
+	ws.statusMu.Lock()
+	defer ws.statusMu.Unlock()
+	return ws.variables[nodeID.String()]
+}
+
+// handleTranslateBrowsePaths resolves the browse paths added with
+// AddBrowsePath or SetVariable starting at the Objects folder
+func (ws *wireServer) handleTranslateBrowsePaths(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.TranslateBrowsePathsToNodeIDsRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+
+	results := make([]*ua.BrowsePathResult, len(req.BrowsePaths))
+	for i, path := range req.BrowsePaths {
+		results[i] = &ua.BrowsePathResult{StatusCode: ua.StatusBadNoMatch}
+		if path.StartingNode.Namespace() != 0 || path.StartingNode.IntID() != id.ObjectsFolder || path.RelativePath == nil {
+			continue
+		}
+		elements := make([]string, len(path.RelativePath.Elements))
+		for j, element := range path.RelativePath.Elements {
+			elements[j] = fmt.Sprintf("%d:%s", element.TargetName.NamespaceIndex, element.TargetName.Name)
+		}
+		ws.statusMu.Lock()
+		nodeID, found := ws.browsePaths[strings.Join(elements, "/")]
+		ws.statusMu.Unlock()
+		if found {
+			results[i] = &ua.BrowsePathResult{
+				StatusCode: ua.StatusOK,
+				Targets: []*ua.BrowsePathTarget{
+					{TargetID: ua.NewExpandedNodeID(nodeID, "", 0), RemainingPathIndex: math.MaxUint32},
+				},
+			}
+		}
+	}
+
+	return &ua.TranslateBrowsePathsToNodeIDsResponse{
+		ResponseHeader:  responseHeader(req.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+// StatusWrites returns the values written to the status node
+func (ws *wireServer) StatusWrites() []time.Time {
+	ws.statusMu.Lock()
+	defer ws.statusMu.Unlock()
+	return append([]time.Time(nil), ws.statusWrites...)
+}
+
+// isLogObject reports whether nodeID is a hosted LogObject
+func (ws *wireServer) isLogObject(nodeID *ua.NodeID) bool {
+	_, ok := ws.findLogObject(nodeID)
+	return ok
+}
+
+// handleBrowse exposes each GetRecords method as a HasComponent child of its LogObject
+func (ws *wireServer) handleBrowse(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.BrowseRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+
+	results := make([]*ua.BrowseResult, len(req.NodesToBrowse))
+	for i, desc := range req.NodesToBrowse {
+		obj, found := ws.findLogObject(desc.NodeID)
+		if !found {
+			results[i] = &ua.BrowseResult{StatusCode: ua.StatusBadNodeIDUnknown}
+			continue
+		}
+		results[i] = &ua.BrowseResult{
+			StatusCode: ua.StatusOK,
+			References: []*ua.ReferenceDescription{{
+				ReferenceTypeID: ua.NewNumericNodeID(0, id.HasComponent),
+				IsForward:       true,
+				NodeID:          ua.NewExpandedNodeID(obj.MethodID, "", 0),
+				BrowseName:      &ua.QualifiedName{NamespaceIndex: 0, Name: "GetRecords"},
+				DisplayName:     ua.NewLocalizedText("GetRecords"),
+				NodeClass:       ua.NodeClassMethod,
+				TypeDefinition:  ua.NewExpandedNodeID(ua.NewTwoByteNodeID(0), "", 0),
+			}},
+		}
+	}
+
+	return &ua.BrowseResponse{
+		ResponseHeader:  responseHeader(req.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+// handleCall implements GetRecords, encoding records as binary LogRecord ExtensionObjects
+func (ws *wireServer) handleCall(_ *uasc.SecureChannel, r ua.Request, _ uint32) (ua.Response, error) {
+	req, ok := r.(*ua.CallRequest)
+	if !ok {
+		return nil, fmt.Errorf("unexpected request %T", r)
+	}
+
+	results := make([]*ua.CallMethodResult, len(req.MethodsToCall))
+	for i, call := range req.MethodsToCall {
+		ws.CaptureRequest(call)
+		if replayed, ok := ws.NextReplay(call.ObjectID); ok {
+			if replayed.Result == nil {
+				return serviceFault(req.RequestHeader, replayed.ServiceStatus), nil
+			}
+			results[i] = replayed.Result
+			continue
+		}
+
+		fault, faulty := ws.NextFault(call.ObjectID)
+		if faulty {
+			_ = fault.Wait(context.Background())
+			switch fault.Kind {
+			case testdata.FaultTooManyOperations:
+				return serviceFault(req.RequestHeader, ua.StatusBadTooManyOperations), nil
+			case testdata.FaultSecureChannelClosed:
+				return serviceFault(req.RequestHeader, ua.StatusBadSecureChannelClosed), nil
+			case testdata.FaultConnectionDrop:
+				// The response never reaches a client behind the proxy
+				if ws.proxy != nil {
+					ws.proxy.dropConnections()
+				}
+				return serviceFault(req.RequestHeader, ua.StatusBadConnectionClosed), nil
+			}
+		}
+
+		if faulty && fault.Kind == testdata.FaultStatus {
+			results[i] = &ua.CallMethodResult{StatusCode: fault.Status}
+			continue
+		}
+		results[i] = ws.getRecords(call)
+		if faulty && fault.Kind == testdata.FaultMalformedRecord {
+			corruptFirstRecord(results[i])
+		}
+	}
+
+	return &ua.CallResponse{
+		ResponseHeader:  responseHeader(req.RequestHeader),
+		Results:         results,
+		DiagnosticInfos: []*ua.DiagnosticInfo{},
+	}, nil
+}
+
+// getRecords executes a single GetRecords method call
+func (ws *wireServer) getRecords(call *ua.CallMethodRequest) *ua.CallMethodResult {
+	if status := ws.CheckMethod(call.ObjectID, call.MethodID); status != ua.StatusOK {
+		return &ua.CallMethodResult{StatusCode: status}
+	}
+	if len(call.InputArguments) < 6 {
+		return &ua.CallMethodResult{StatusCode: ua.StatusBadArgumentsMissing}
+	}
+
+	startTime, ok1 := call.InputArguments[0].Value().(time.Time)
+	endTime, ok2 := call.InputArguments[1].Value().(time.Time)
+	maxRecords, ok3 := call.InputArguments[2].Value().(uint32)
+	minSeverity, ok4 := call.InputArguments[3].Value().(uint16)
+	continuationPoint, _ := call.InputArguments[5].Value().([]byte)
+	if !ok1 || !ok2 || !ok3 || !ok4 || endTime.Before(startTime) {
+		return &ua.CallMethodResult{StatusCode: ua.StatusBadInvalidArgument}
+	}
+
+	records, next, status := ws.Query(call.ObjectID, startTime, endTime, maxRecords, minSeverity, continuationPoint)
+	if status != ua.StatusOK {
+		return &ua.CallMethodResult{StatusCode: status}
+	}
+	_ = ws.SimulateTransfer(context.Background(), records)
+
+	objects := make([]*ua.ExtensionObject, len(records))
+	for i, record := range records {
+		objects[i] = ua.NewExtensionObject(recordToLogRecordExtObj(record))
+	}
+
+	return &ua.CallMethodResult{
+		StatusCode:                   ua.StatusOK,
+		InputArgumentResults:         []ua.StatusCode{},
+		InputArgumentDiagnosticInfos: []*ua.DiagnosticInfo{},
+		OutputArguments: []*ua.Variant{
+			ua.MustVariant(objects),
+			ua.MustVariant(next),
+		},
+	}
+}
+
+// recordToLogRecordExtObj is the inverse of logRecordExtObjToRecord
+func recordToLogRecordExtObj(record testdata.OPCUALogRecord) *client.LogRecordExtObj {
+	lr := &client.LogRecordExtObj{
+		Time:           record.Timestamp,
+		Severity:       record.Severity,
+		Message:        record.Message,
+		SourceName:     record.SourceName,
+		AdditionalData: record.Attributes,
+	}
+
+	switch record.SourceIDType {
+	case "Numeric":
+		if v, err := strconv.ParseUint(record.SourceID, 10, 32); err == nil {
+			lr.SourceNode = ua.NewNumericNodeID(record.SourceNamespace, uint32(v))
+		}
+	case "String":
+		lr.SourceNode = ua.NewStringNodeID(record.SourceNamespace, record.SourceID)
+	case "Guid":
+		lr.SourceNode = ua.NewGUIDNodeID(record.SourceNamespace, record.SourceID)
+	case "Opaque":
+		if b, err := base64.StdEncoding.DecodeString(record.SourceID); err == nil {
+			lr.SourceNode = ua.NewByteStringNodeID(record.SourceNamespace, b)
+		}
+	}
+	if lr.SourceNode != nil {
+		lr.SourceNamespaceURI = record.SourceNamespaceURI
+	}
+	lr.MessageLocale = record.MessageLocale
+
+	if traceID, err := hex.DecodeString(record.TraceID); err == nil && len(traceID) == 16 {
+		copy(lr.TraceIDBytes[:], traceID)
+	}
+	if spanID, err := hex.DecodeString(record.SpanID); err == nil && len(spanID) == 8 {
+		lr.SpanID = binary.BigEndian.Uint64(spanID)
+	}
+	if parentSpanID, err := hex.DecodeString(record.ParentSpanID); err == nil && len(parentSpanID) == 8 {
+		lr.ParentSpanID = binary.BigEndian.Uint64(parentSpanID)
+	}
+
+	return lr
+}